@@ -4,27 +4,30 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/config"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 )
 
 // RefreshToken 		godoc
 // @Summary 			Refresh user access token.
-// @Description 		Refresh user access token.
+// @Description 		Exchanges a refresh token for a new access token and a rotated refresh token.
+// @Description 		Reusing an already rotated refresh token revokes every token of that login.
 // @Tags 				Auth
 // @Accept 				json
 // @Produce 			json
-// @Security			JWT
+// @Param               request body schemas.RefreshTokenRequest true "Refresh token"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
-// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			401 {object} errors.Error "Invalid, expired or reused refresh token"
 // @Success 			200 {object} schemas.TokenResponse "Ok"
 // @Router 				/auth/refresh/ [post]
 func (a *Api) RefreshToken(c echo.Context) error {
-	accessToken, _, authError := a.BllController.Auth.AccessTokenValidation(c)
-	if authError != nil {
-		return errors.HandleError(*authError, c)
+	var request schemas.RefreshTokenRequest
+	if err := c.Bind(&request); err != nil || request.RefreshToken == "" {
+		return errors.HandleError(errors.AuthenticationError.InvalidRefreshToken, c)
 	}
 
-	token, err := a.BllController.Auth.RefreshToken(accessToken)
+	token, err := a.BllController.Auth.RefreshToken(request.RefreshToken)
 	if err != nil {
 		return errors.HandleError(*err, c)
 	}
@@ -34,24 +37,56 @@ func (a *Api) RefreshToken(c echo.Context) error {
 
 // Logout 				godoc
 // @Summary 			User logout
-// @Description 		Logout user session (audit purposes)
+// @Description 		Logout user session, revoking the given refresh token and every token rotated from it
 // @Tags 				Auth
 // @Accept 				json
 // @Produce 			json
 // @Security			JWT
+// @Param               request body schemas.LogoutRequest false "Refresh token to revoke"
 // @Success 			200 {object} string "Logout successful"
-// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
 // @Router 				/auth/logout/ [post]
 func (a *Api) Logout(c echo.Context) error {
-	// Validate token for audit purposes (we want to know who logged out)
-	_, _, authError := a.BllController.Auth.AccessTokenValidation(c)
-	if authError != nil {
-		// Even if token is invalid, we'll return success for security reasons
-		// This prevents information disclosure about token validity
-		return c.JSON(http.StatusOK, map[string]string{"message": "Logout successful"})
+	var request schemas.LogoutRequest
+	if err := c.Bind(&request); err == nil && request.RefreshToken != "" {
+		// Errors are ignored so the response does not disclose whether the token was valid
+		if revokeErr := a.BllController.Auth.RevokeRefreshToken(request.RefreshToken); revokeErr != nil {
+			a.Logger.Debugf("Logout with unknown refresh token: %s", revokeErr.Message)
+		}
 	}
 
-	// The actual token invalidation is handled client-side
-	// This endpoint exists primarily for audit logging purposes
+	// Always return success for security reasons
+	// This prevents information disclosure about token validity
 	return c.JSON(http.StatusOK, map[string]string{"message": "Logout successful"})
 }
+
+// Gets the credentials of the caller for the ownership checks of the controllers. With
+// authentication disabled the requests without a token get nil credentials, which are trusted.
+func (a *Api) getCallerCredentials(c echo.Context) (*schemas.Credentials, *errors.Error) {
+	if c.Request().Header.Get(schemas.ApiKeyHeader) != "" {
+		serviceAccount, err := a.BllController.ServiceAccount.ApiKeyValidation(c)
+		if err != nil {
+			return nil, err
+		}
+		return a.BllController.ServiceAccount.GetCredentials(serviceAccount), nil
+	}
+
+	authDisabled := config.GetDevMode() || a.EnvSettings.DisableAuthForTests
+	if authDisabled && c.Request().Header.Get("Authorization") == "" {
+		return nil, nil
+	}
+
+	_, credentials, err := a.BllController.Auth.AccessTokenValidation(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// Name recorded as updatedBy: the service account that made the request, or the given fallback
+func (a *Api) getUpdatedBy(c echo.Context, fallback string) string {
+	if serviceAccount, ok := c.Get(schemas.ServiceAccountContextKey).(*schemas.ServiceAccount); ok {
+		return serviceAccount.Name
+	}
+	return fallback
+}
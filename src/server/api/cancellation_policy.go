@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// @Summary 			Get Cancellation Policy.
+// @Description 		Gets a cancellation policy given its id.
+// @Tags 				Cancellation Policy
+// @Accept 				json
+// @Produce 			json
+// @Param               policyId    path   string  true  "Cancellation Policy ID"
+// @Success 			200 {object} schemas.CancellationPolicy "OK"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/cancellation-policy/{policyId}/ [get]
+func (a *Api) GetCancellationPolicy(c echo.Context) error {
+	policyId, parseErr := uuid.Parse(c.Param("policyId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidCancellationPolicyId, c)
+	}
+
+	response, err := a.BllController.CancellationPolicy.GetCancellationPolicy(policyId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Fetch Cancellation Policies.
+// @Description 		Fetch all cancellation policies, filtered by community.
+// @Tags 				Cancellation Policy
+// @Accept 				json
+// @Produce 			json
+// @Param               communityIds query []string false "Community IDs"
+// @Success 			200 {object} schemas.CancellationPolicies "OK"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/cancellation-policy/ [get]
+func (a *Api) FetchCancellationPolicies(c echo.Context) error {
+	communityIdsString := c.QueryParam("communityIds")
+
+	communityIds := []string{}
+	if communityIdsString != "" {
+		communityIds = strings.Split(communityIdsString, ",")
+	}
+
+	response, err := a.BllController.CancellationPolicy.FetchCancellationPolicies(communityIds)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Get Session Cancellation Policy.
+// @Description 		Gets the cancellation policy that applies to a session, the one of its service or else the one of its community.
+// @Tags 				Cancellation Policy
+// @Accept 				json
+// @Produce 			json
+// @Param               sessionId    path   string  true  "Session ID"
+// @Success 			200 {object} schemas.CancellationPolicy "OK"
+// @Failure 			404 {object} errors.Error "Not Found - Session or policy not found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/session/{sessionId}/cancellation-policy/ [get]
+func (a *Api) GetSessionCancellationPolicy(c echo.Context) error {
+	sessionId, parseErr := uuid.Parse(c.Param("sessionId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidSessionId, c)
+	}
+
+	response, err := a.BllController.CancellationPolicy.GetSessionCancellationPolicy(sessionId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Create Cancellation Policy.
+// @Description 		Creates the cancellation policy of a community, or of one of its services when a service is given.
+// @Tags 				Cancellation Policy
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request body schemas.CreateCancellationPolicyRequest true "Create Cancellation Policy Request"
+// @Success 			201 {object} schemas.CancellationPolicy "Created"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden"
+// @Failure 			404 {object} errors.Error "Not Found - Community or service not found"
+// @Failure 			409 {object} errors.Error "Conflict - Policy already exists"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/cancellation-policy/ [post]
+func (a *Api) CreateCancellationPolicy(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	var request schemas.CreateCancellationPolicyRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.CancellationPolicy.CreateCancellationPolicy(request, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// @Summary 			Update Cancellation Policy.
+// @Description 		Updates the rules of a cancellation policy.
+// @Tags 				Cancellation Policy
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               policyId    path   string  true  "Cancellation Policy ID"
+// @Param               request body schemas.UpdateCancellationPolicyRequest true "Update Cancellation Policy Request"
+// @Success 			200 {object} schemas.CancellationPolicy "OK"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/cancellation-policy/{policyId}/ [patch]
+func (a *Api) UpdateCancellationPolicy(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	policyId, parseErr := uuid.Parse(c.Param("policyId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidCancellationPolicyId, c)
+	}
+
+	var request schemas.UpdateCancellationPolicyRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.CancellationPolicy.UpdateCancellationPolicy(
+		policyId,
+		request,
+		updatedBy,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Delete Cancellation Policy.
+// @Description 		Deletes a cancellation policy given its id.
+// @Tags 				Cancellation Policy
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               policyId    path   string  true  "Cancellation Policy ID"
+// @Success 			204 "No Content"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/cancellation-policy/{policyId}/ [delete]
+func (a *Api) DeleteCancellationPolicy(c echo.Context) error {
+	policyId, parseErr := uuid.Parse(c.Param("policyId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidCancellationPolicyId, c)
+	}
+
+	if err := a.BllController.CancellationPolicy.DeleteCancellationPolicy(policyId); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -119,7 +119,7 @@ func (a *Api) FetchCommunities(c echo.Context) error {
 // @Router 				/community/ [post]
 func (a *Api) CreateCommunity(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateCommunityRequest
 	if err := c.Bind(&request); err != nil {
@@ -155,6 +155,7 @@ func (a *Api) CreateCommunity(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request body schemas.BatchCreateCommunityRequest true "Bulk Create Communities Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Communities "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -163,7 +164,7 @@ func (a *Api) CreateCommunity(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/community/bulk-create/ [post]
 func (a *Api) BulkCreateCommunities(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.BatchCreateCommunityRequest
 
@@ -200,7 +201,7 @@ func (a *Api) BulkCreateCommunities(c echo.Context) error {
 // @Router 				/community/{communityId}/ [patch]
 func (a *Api) UpdateCommunity(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	communityId, parseErr := uuid.Parse(c.Param("communityId"))
 	if parseErr != nil {
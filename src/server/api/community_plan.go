@@ -25,7 +25,7 @@ import (
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/community-plan/ [post]
 func (a *Api) CreateCommunityPlan(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateCommunityPlanRequest
 	if err := c.Bind(&request); err != nil {
@@ -111,6 +111,7 @@ func (a *Api) DeleteCommunityPlan(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request body schemas.BatchCreateCommunityPlanRequest true "Bulk Create CommunityPlans Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.CommunityPlans "Created"
 // @Failure 			400 {object} errors.Error "Bad Request (e.g., invalid updatedBy)"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -120,7 +121,7 @@ func (a *Api) DeleteCommunityPlan(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/community-plan/bulk-create/ [post]
 func (a *Api) BulkCreateCommunityPlans(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.BatchCreateCommunityPlanRequest
 	if err := c.Bind(&request); err != nil {
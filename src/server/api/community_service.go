@@ -25,7 +25,7 @@ import (
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/community-service/ [post]
 func (a *Api) CreateCommunityService(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateCommunityServiceRequest
 	if err := c.Bind(&request); err != nil {
@@ -139,6 +139,7 @@ func (a *Api) DeleteCommunityService(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request body schemas.BatchCreateCommunityServiceRequest true "Bulk Create CommunityServices Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.CommunityServices "Created"
 // @Failure 			400 {object} errors.Error "Bad Request (e.g., invalid updatedBy)"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -148,7 +149,7 @@ func (a *Api) DeleteCommunityService(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/community-service/bulk-create/ [post]
 func (a *Api) BulkCreateCommunityServices(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.BatchCreateCommunityServiceRequest
 	if err := c.Bind(&request); err != nil {
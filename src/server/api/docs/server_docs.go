@@ -10782,9 +10782,6 @@ const docTemplateserver = `{
                 "onboarding": {
                     "$ref": "#/definitions/schemas.Onboarding"
                 },
-                "password": {
-                    "type": "string"
-                },
                 "rol": {
                     "$ref": "#/definitions/schemas.UserRol"
                 },
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// @Summary Verify Email
+// @Description Verifica el correo del usuario con el código enviado al registrarse. Tras varios intentos fallidos el código se bloquea.
+// @Tags EmailVerification
+// @Accept json
+// @Produce json
+// @Param request body schemas.VerifyEmailRequest true "Email y código de verificación"
+// @Success 200 {object} schemas.EmailVerificationResponse "Correo verificado"
+// @Failure 400 {object} errors.Error "Bad Request - Código inválido, expirado o correo ya verificado"
+// @Failure 422 {object} errors.Error "Unprocessable Entity - Formato incorrecto"
+// @Failure 429 {object} errors.Error "Too Many Requests - Demasiados intentos fallidos"
+// @Failure 500 {object} errors.Error "Internal Server Error"
+// @Router /verify-email/ [post]
+func (a *Api) VerifyEmail(c echo.Context) error {
+	var request schemas.VerifyEmailRequest
+
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	if request.Email == "" || request.Code == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.EmailVerification.VerifyEmail(request)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary Resend Email Verification
+// @Description Envía un nuevo código de verificación al correo del usuario, invalidando el anterior. La respuesta es la misma si el correo no está registrado o ya fue verificado
+// @Tags EmailVerification
+// @Accept json
+// @Produce json
+// @Param request body schemas.ResendEmailVerificationRequest true "Email del usuario"
+// @Success 200 {object} schemas.EmailVerificationResponse "Código enviado exitosamente"
+// @Failure 422 {object} errors.Error "Unprocessable Entity - Formato incorrecto"
+// @Failure 500 {object} errors.Error "Internal Server Error"
+// @Router /verify-email/resend/ [post]
+func (a *Api) ResendEmailVerification(c echo.Context) error {
+	var request schemas.ResendEmailVerificationRequest
+
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	if request.Email == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserEmail, c)
+	}
+
+	response, err := a.BllController.EmailVerification.ResendVerificationCode(request.Email)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
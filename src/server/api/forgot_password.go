@@ -45,3 +45,64 @@ func (a *Api) ForgotPassword(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response)
 }
+
+// @Summary Recovery Password by SMS
+// @Description Envía un código de recuperación (PIN) por SMS al teléfono registrado en el onboarding del usuario
+// @Tags ForgotPassword
+// @Accept json
+// @Produce json
+// @Param request body schemas.SendPINBySMSRequest true "Email del usuario"
+// @Success 200 {object} schemas.ForgotPasswordResponse "Código enviado exitosamente"
+// @Failure 400 {object} errors.Error "Bad Request - Error al enviar el código"
+// @Failure 422 {object} errors.Error "Unprocessable Entity - Formato incorrecto"
+// @Failure 500 {object} errors.Error "Internal Server Error"
+// @Router /forgot-password/sms/ [post]
+func (a *Api) SendResetPinBySMS(c echo.Context) error {
+	var request schemas.SendPINBySMSRequest
+
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	if request.Email == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserEmail, c)
+	}
+
+	response, err := a.BllController.ForgotPassword.SendResetPinBySMS(request.Email)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary Reset Password
+// @Description Verifica el código de recuperación y establece la nueva contraseña. Tras varios intentos fallidos el código se bloquea.
+// @Tags ForgotPassword
+// @Accept json
+// @Produce json
+// @Param request body schemas.ResetPasswordRequest true "Email, código y nueva contraseña"
+// @Success 200 {object} schemas.ForgotPasswordResponse "Contraseña actualizada"
+// @Failure 400 {object} errors.Error "Bad Request - Código inválido o expirado"
+// @Failure 422 {object} errors.Error "Unprocessable Entity - Formato incorrecto"
+// @Failure 429 {object} errors.Error "Too Many Requests - Demasiados intentos fallidos"
+// @Failure 500 {object} errors.Error "Internal Server Error"
+// @Router /forgot-password/reset/ [post]
+func (a *Api) ResetPassword(c echo.Context) error {
+	var request schemas.ResetPasswordRequest
+
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	if request.Email == "" || request.Pin == "" || len(request.NewPassword) < 6 {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.ForgotPassword.ResetPassword(request)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
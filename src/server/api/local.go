@@ -119,7 +119,7 @@ func (a *Api) FetchLocals(c echo.Context) error {
 // @Router 				/local/ [post]
 func (a *Api) CreateLocal(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateLocalRequest
 	if err := c.Bind(&request); err != nil {
@@ -154,6 +154,7 @@ func (a *Api) CreateLocal(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request body schemas.BatchCreateLocalRequest true "Bulk Create Locals Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Locals "Created"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -162,7 +163,7 @@ func (a *Api) CreateLocal(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/local/bulk-create/ [post]
 func (a *Api) BulkCreateLocals(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.BatchCreateLocalRequest
 	if err := c.Bind(&request); err != nil {
@@ -202,7 +203,7 @@ func (a *Api) BulkCreateLocals(c echo.Context) error {
 // @Router 				/local/{localId}/ [patch]
 func (a *Api) UpdateLocal(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	localId, parseErr := uuid.Parse(c.Param("localId"))
 	if parseErr != nil {
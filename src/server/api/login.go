@@ -33,6 +33,7 @@ func (a *Api) Login(c echo.Context) error {
 	if err != nil {
 		return errors.HandleError(*err, c)
 	}
+	a.BllController.LoginSession.RecordDevice(c, response.Tokens.SessionId)
 
 	return c.JSON(http.StatusOK, response)
 }
@@ -77,6 +78,7 @@ func (a *Api) Register(c echo.Context) error {
 	if err != nil {
 		return errors.HandleError(*err, c)
 	}
+	a.BllController.LoginSession.RecordDevice(c, response.Tokens.SessionId)
 
 	return c.JSON(http.StatusCreated, response)
 }
@@ -146,6 +148,54 @@ func (a *Api) GoogleLogin(c echo.Context) error {
 	if err != nil {
 		return errors.HandleError(*err, c)
 	}
+	a.BllController.LoginSession.RecordDevice(c, response.Tokens.SessionId)
 
 	return c.JSON(http.StatusOK, response)
 }
+
+// OidcLogin 		godoc
+// @Summary 			OpenID Connect Login
+// @Description 		Authenticate user using an ID token of a configured OpenID Connect provider, returns user info and tokens.
+// @Description 		Unknown identities are linked to the account with the same verified email, or to a new account.
+// @Tags 				Login
+// @Accept 				json
+// @Produce 			json
+// @Param               provider  path   string  true  "Provider name"
+// @Param               request  body   schemas.OidcLoginRequest  true  "ID token"
+// @Success 			200 {object} schemas.OidcLoginResponse "Login successful"
+// @Failure 			401 {object} errors.Error "Unauthorized - Invalid token"
+// @Failure 			403 {object} errors.Error "Forbidden - Email not verified by the provider"
+// @Failure 			404 {object} errors.Error "Not Found - Provider not configured"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/login/oidc/{provider}/ [post]
+func (a *Api) OidcLogin(c echo.Context) error {
+	var request schemas.OidcLoginRequest
+	if err := c.Bind(&request); err != nil || request.Token == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.Login.OidcLogin(
+		c.Request().Context(),
+		c.Param("provider"),
+		request.Token,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+	a.BllController.LoginSession.RecordDevice(c, response.Tokens.SessionId)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// FetchOidcProviders 	godoc
+// @Summary 			OpenID Connect Providers
+// @Description 		Names of the OpenID Connect providers that can be used to log in and link identities.
+// @Tags 				Login
+// @Accept 				json
+// @Produce 			json
+// @Success 			200 {object} schemas.OidcProviders "OK"
+// @Router 				/login/oidc/ [get]
+func (a *Api) FetchOidcProviders(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.BllController.UserIdentity.FetchProviders())
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+)
+
+// FetchCurrentUserSessions godoc
+// @Summary 			Fetch login sessions
+// @Description 		Returns the active login sessions of the current user with their device, address and last use.
+// @Tags 				LoginSession
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.LoginSessions "Active sessions"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/sessions/ [get]
+func (a *Api) FetchCurrentUserSessions(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	response, err := a.BllController.LoginSession.FetchUserSessions(
+		credentials.UserId,
+		credentials.SessionId,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RevokeCurrentUserSession godoc
+// @Summary 			Revoke a login session
+// @Description 		Ends a login session of the current user. Its tokens stop working right away.
+// @Tags 				LoginSession
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               sessionId    path   string  true  "Session ID"
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/sessions/{sessionId}/ [delete]
+func (a *Api) RevokeCurrentUserSession(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	sessionId, parseErr := uuid.Parse(c.Param("sessionId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidLoginSessionId, c)
+	}
+
+	if err := a.BllController.LoginSession.RevokeUserSession(
+		credentials.UserId,
+		sessionId,
+		credentials.UserEmail,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RevokeOtherCurrentUserSessions godoc
+// @Summary 			Revoke the other login sessions
+// @Description 		Ends every login session of the current user except the one of the request.
+// @Tags 				LoginSession
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/sessions/ [delete]
+func (a *Api) RevokeOtherCurrentUserSessions(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	if err := a.BllController.LoginSession.RevokeOtherUserSessions(
+		credentials.UserId,
+		credentials.SessionId,
+		credentials.UserEmail,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary 			Fetch User Sessions.
+// @Description 		Fetch the active login sessions of a user.
+// @Tags 				User
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Success 			200 {object} schemas.LoginSessions "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Admin role required"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/user/{userId}/sessions/ [get]
+func (a *Api) FetchUserSessions(c echo.Context) error {
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	response, err := a.BllController.LoginSession.FetchUserSessions(userId, nil)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Revoke User Session.
+// @Description 		Ends a login session of a user. Use force-logout to end all of them.
+// @Tags 				User
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Param               sessionId    path   string  true  "Session ID"
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Admin role required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/user/{userId}/sessions/{sessionId}/ [delete]
+func (a *Api) RevokeUserSession(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+	sessionId, parseErr := uuid.Parse(c.Param("sessionId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidLoginSessionId, c)
+	}
+
+	if err := a.BllController.LoginSession.RevokeUserSession(userId, sessionId, updatedBy); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -19,6 +19,7 @@ import (
 // @Success 			200 {object} schemas.Membership "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -29,6 +30,17 @@ func (a *Api) GetMembership(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidMembershipId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Membership.CheckMembershipAccess(
+		credentials,
+		membershipId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Membership.GetMembership(membershipId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -47,6 +59,7 @@ func (a *Api) GetMembership(c echo.Context) error {
 // @Success 			200 {object} schemas.Memberships "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -57,6 +70,18 @@ func (a *Api) GetMembershipsByUserId(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Membership.CheckUserMembershipsAccess(
+		credentials,
+		userId,
+		nil,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Membership.GetMembershipsByUserId(userId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -85,12 +110,20 @@ func (a *Api) GetMembershipsByCommunityId(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidCommunityId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+
 	response, err := a.BllController.Membership.GetMembershipsByCommunityId(communityId)
 	if err != nil {
 		return errors.HandleError(*err, c)
 	}
 
-	return c.JSON(http.StatusOK, response)
+	return c.JSON(
+		http.StatusOK,
+		a.BllController.Membership.FilterOwnedMemberships(credentials, response),
+	)
 }
 
 // @Summary 			Fetch Memberships.
@@ -107,12 +140,20 @@ func (a *Api) GetMembershipsByCommunityId(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/membership/ [get]
 func (a *Api) FetchMemberships(c echo.Context) error {
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+
 	response, err := a.BllController.Membership.FetchMemberships()
 	if err != nil {
 		return errors.HandleError(*err, c)
 	}
 
-	return c.JSON(http.StatusOK, response)
+	return c.JSON(
+		http.StatusOK,
+		a.BllController.Membership.FilterOwnedMemberships(credentials, response),
+	)
 }
 
 // @Summary 			Create Membership.
@@ -122,21 +163,35 @@ func (a *Api) FetchMemberships(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               createMembershipRequest    body   schemas.CreateMembershipRequest  true  "Create Membership Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Membership "Created"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/membership/ [post]
 func (a *Api) CreateMembership(c echo.Context) error {
-	updatedBy := "USER" // Podría ser obtenido del JWT token en producción
+	updatedBy := a.getUpdatedBy(c, "USER") // Podría ser obtenido del JWT token en producción
 
 	var request schemas.CreateMembershipRequest
 	if err := c.Bind(&request); err != nil {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Membership.CheckUserMembershipsAccess(
+		credentials,
+		request.UserId,
+		&request.CommunityId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Membership.CreateMembership(request, updatedBy)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -153,15 +208,17 @@ func (a *Api) CreateMembership(c echo.Context) error {
 // @Security			JWT
 // @Param               userId    path   string  true  "User ID"
 // @Param               createMembershipForUserRequest    body   schemas.CreateMembershipForUserRequest  true  "Create Membership For User Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Membership "Created"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/membership/user/{userId}/ [post]
 func (a *Api) CreateMembershipForUser(c echo.Context) error {
-	updatedBy := "USER" // Podría ser obtenido del JWT token en producción
+	updatedBy := a.getUpdatedBy(c, "USER") // Podría ser obtenido del JWT token en producción
 
 	userId, parseErr := uuid.Parse(c.Param("userId"))
 	if parseErr != nil {
@@ -173,6 +230,18 @@ func (a *Api) CreateMembershipForUser(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Membership.CheckUserMembershipsAccess(
+		credentials,
+		userId,
+		&request.CommunityId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Membership.CreateMembershipForUser(userId, request, updatedBy)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -192,12 +261,13 @@ func (a *Api) CreateMembershipForUser(c echo.Context) error {
 // @Success 			200 {object} schemas.Membership "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/membership/{membershipId}/ [patch]
 func (a *Api) UpdateMembership(c echo.Context) error {
-	updatedBy := "USER" // Podría ser obtenido del JWT token en producción
+	updatedBy := a.getUpdatedBy(c, "USER") // Podría ser obtenido del JWT token en producción
 
 	membershipId, parseErr := uuid.Parse(c.Param("membershipId"))
 	if parseErr != nil {
@@ -209,6 +279,18 @@ func (a *Api) UpdateMembership(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Membership.CheckUpdateMembershipAccess(
+		credentials,
+		membershipId,
+		request,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Membership.UpdateMembership(membershipId, request, updatedBy)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -227,6 +309,7 @@ func (a *Api) UpdateMembership(c echo.Context) error {
 // @Success 			204 {string} string "No Content"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -237,6 +320,17 @@ func (a *Api) DeleteMembership(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidMembershipId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Membership.CheckMembershipAccess(
+		credentials,
+		membershipId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	err := a.BllController.Membership.DeleteMembership(membershipId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -246,7 +340,7 @@ func (a *Api) DeleteMembership(c echo.Context) error {
 }
 
 // @Summary 			Get Users by Community ID.
-// @Description 		Gets all users who have active memberships in a specific community.
+// @Description 		Gets all users who have active memberships in a specific community. Only staff that can access the memberships of other users in the community can list them.
 // @Tags 				Membership
 // @Accept 				json
 // @Produce 			json
@@ -255,6 +349,7 @@ func (a *Api) DeleteMembership(c echo.Context) error {
 // @Success 			200 {object} schemas.Users "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - membership:any_user permission required"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -284,6 +379,7 @@ func (a *Api) GetUsersByCommunityId(c echo.Context) error {
 // @Success 			200 {object} schemas.Membership "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -299,6 +395,18 @@ func (a *Api) GetMembershipByUserAndCommunity(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidCommunityId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Membership.CheckUserMembershipsAccess(
+		credentials,
+		userId,
+		&communityId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Membership.GetMembershipByUserAndCommunity(userId, communityId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -66,7 +66,7 @@ func (a *Middleware) AuditMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 				UserId:    credentials.UserId,
 				UserEmail: credentials.UserEmail,
 				UserRole:  schemas.UserRol(credentials.UserRoles[0]), // Use first role
-				IPAddress: getClientIP(c),
+				IPAddress: c.RealIP(),
 				UserAgent: getUserAgent(c),
 			}
 			hasValidUser = true
@@ -74,6 +74,18 @@ func (a *Middleware) AuditMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		// Ejecuto la request
 		err := next(c)
 		code := c.Response().Status
+
+		// Requests of service accounts are attributed to the account authenticated by its API key
+		if serviceAccount, ok := c.Get(schemas.ServiceAccountContextKey).(*schemas.ServiceAccount); ok && !hasValidUser {
+			auditContext = schemas.AuditContext{
+				UserId:    serviceAccount.Id,
+				UserEmail: serviceAccount.Name,
+				UserRole:  schemas.UserRolServiceAccount,
+				IPAddress: c.RealIP(),
+				UserAgent: getUserAgent(c),
+			}
+			hasValidUser = true
+		}
 		// Skip health check, login, swagger, and audit log endpoints - but only for successful requests
 		shouldSkipForPath := shouldSkipAuditForPath(path, method, code, hasValidUser)
 
@@ -112,7 +124,7 @@ func (a *Middleware) AuditMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 					UserId:    uuid.Nil, // We don't have the user ID yet, but we have email
 					UserEmail: loginEmail,
 					UserRole:  userRole, // Use actual role instead of always GUEST
-					IPAddress: getClientIP(c),
+					IPAddress: c.RealIP(),
 					UserAgent: getUserAgent(c),
 				}
 				hasValidUser = true
@@ -125,7 +137,7 @@ func (a *Middleware) AuditMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 					UserId:    uuid.Nil, // Use nil UUID for unauthenticated requests
 					UserEmail: "sin autenticar",
 					UserRole:  schemas.UserRolGuest, // Use guest role for unauthenticated requests
-					IPAddress: getClientIP(c),
+					IPAddress: c.RealIP(),
 					UserAgent: getUserAgent(c),
 				}
 			}
@@ -360,26 +372,6 @@ func getQueryParamKeys(params map[string][]string) []string {
 	return keys
 }
 
-// getClientIP extracts the client IP address
-func getClientIP(c echo.Context) string {
-	// Check various headers for the real IP
-	ip := c.Request().Header.Get("X-Forwarded-For")
-	if ip != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		if idx := strings.Index(ip, ","); idx != -1 {
-			ip = ip[:idx]
-		}
-		return strings.TrimSpace(ip)
-	}
-
-	ip = c.Request().Header.Get("X-Real-IP")
-	if ip != "" {
-		return ip
-	}
-
-	return c.RealIP()
-}
-
 // getUserAgent extracts the user agent
 func getUserAgent(c echo.Context) *string {
 	userAgent := c.Request().Header.Get("User-Agent")
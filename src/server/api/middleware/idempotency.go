@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// IdempotencyMiddleware makes retries of a mutating endpoint safe. A request with an
+// Idempotency-Key header runs once per caller and key; retries with the same body get the stored
+// response back, and a retry with a different body is rejected. Server errors are not stored, so
+// their retries run again.
+func (a *Middleware) IdempotencyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(schemas.IdempotencyKeyHeader)
+		if key == "" {
+			return next(c)
+		}
+
+		requestHash, readErr := hashIdempotentRequest(c)
+		if readErr != nil {
+			return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+		}
+
+		scope := a.getIdempotencyScope(c)
+		stored, err := a.BllController.IdempotencyKey.Begin(scope, key, requestHash)
+		if err != nil {
+			if *err == errors.InternalServerError.DatabaseError {
+				a.Logger.Error("Idempotency key store failed, running request: ", err.Message)
+				return next(c)
+			}
+			return errors.HandleError(*err, c)
+		}
+		if stored != nil {
+			c.Response().Header().Set(schemas.IdempotencyReplayedHeader, "true")
+			return c.Blob(stored.StatusCode, stored.ContentType, stored.ResponseBody)
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+
+		handlerErr := next(c)
+		status := c.Response().Status
+		if handlerErr != nil || status >= http.StatusInternalServerError {
+			if err := a.BllController.IdempotencyKey.Release(scope, key); err != nil {
+				a.Logger.Error("Failed to release idempotency key: ", err.Message)
+			}
+			return handlerErr
+		}
+
+		if err := a.BllController.IdempotencyKey.Complete(
+			scope,
+			key,
+			status,
+			c.Response().Header().Get(echo.HeaderContentType),
+			recorder.body.Bytes(),
+		); err != nil {
+			a.Logger.Error("Failed to store idempotent response: ", err.Message)
+		}
+
+		return nil
+	}
+}
+
+// Hashes the method, path and body of a request, restoring the body for the handler
+func hashIdempotentRequest(c echo.Context) (string, error) {
+	var bodyBytes []byte
+	if c.Request().Body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(c.Request().Body)
+		c.Request().Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request().Method + " " + c.Request().URL.Path + "\n"))
+	hash.Write(bodyBytes)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Identifies the caller a key belongs to, so different callers never share keys
+func (a *Middleware) getIdempotencyScope(c echo.Context) string {
+	if serviceAccount, ok := c.Get(schemas.ServiceAccountContextKey).(*schemas.ServiceAccount); ok {
+		return "service_account:" + serviceAccount.Id.String()
+	}
+	if _, credentials, err := a.BllController.Auth.AccessTokenValidation(c); err == nil {
+		return "user:" + credentials.UserId.String()
+	}
+	return "anonymous"
+}
+
+// responseRecorder copies the body written to the client so it can be stored
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
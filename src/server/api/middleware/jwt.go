@@ -7,7 +7,8 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 )
 
-// JWTMiddleware validates JWT tokens for protected endpoints
+// JWTMiddleware validates JWT tokens for protected endpoints. Requests with an X-Api-Key header
+// are authenticated as a service account instead, see RequirePermission for their scopes.
 func (a *Middleware) JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		// Verificar si está en modo desarrollo
@@ -17,6 +18,13 @@ func (a *Middleware) JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			return next(c)
 		}
 
+		if c.Request().Header.Get(schemas.ApiKeyHeader) != "" {
+			if _, err := a.BllController.ServiceAccount.ApiKeyValidation(c); err != nil {
+				return errors.HandleError(*err, c)
+			}
+			return next(c)
+		}
+
 		// En modo producción, validar JWT token usando la lógica existente
 		_, _, authError := a.BllController.Auth.AccessTokenValidation(c)
 		if authError != nil {
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/config"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// RequirePermission validates that the user has the given permission. Grants limited to a
+// community only apply when every resource the request refers to belongs to that community: the
+// community, session, reservation, session series or cancellation policy of its path, or the
+// community, community service or session of its body. Service accounts need the permission among
+// the scopes of their API key.
+func (a *Middleware) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.GetDevMode() || a.EnvSettings.DisableAuthForTests {
+				a.Logger.Debugln("🔓 Modo desarrollo: Omitiendo validación de permiso", permission)
+				return next(c)
+			}
+
+			if c.Request().Header.Get(schemas.ApiKeyHeader) != "" {
+				serviceAccount, err := a.BllController.ServiceAccount.ApiKeyValidation(c)
+				if err != nil {
+					return errors.HandleError(*err, c)
+				}
+				if err := a.BllController.ServiceAccount.CheckScope(serviceAccount, permission); err != nil {
+					return errors.HandleError(*err, c)
+				}
+				return next(c)
+			}
+
+			_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+			if authError != nil {
+				return errors.HandleError(*authError, c)
+			}
+
+			if err := a.BllController.Role.CheckRequestPermission(
+				credentials.UserId,
+				permission,
+				getRequestResources(c),
+			); err != nil {
+				return errors.HandleError(*err, c)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Reads the resources a request refers to. Bodies are read and put back for the handler, and
+// values that can not be parsed are ignored since the handler rejects them.
+func getRequestResources(c echo.Context) schemas.PermissionResources {
+	var resources schemas.PermissionResources
+
+	request := c.Request()
+	if request.Body != nil && request.Method != http.MethodGet &&
+		strings.HasPrefix(request.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		bodyBytes, err := io.ReadAll(request.Body)
+		request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		if err == nil {
+			_ = json.Unmarshal(bodyBytes, &resources)
+		}
+	}
+
+	communityId := c.Param("communityId")
+	if communityId == "" {
+		communityId = c.QueryParam("communityId")
+	}
+	if id := parseResourceId(communityId); id != nil {
+		resources.CommunityId = id
+	}
+	if id := parseResourceId(c.Param("sessionId")); id != nil {
+		resources.SessionId = id
+	}
+	resources.SessionSeriesId = parseResourceId(c.Param("seriesId"))
+	resources.ReservationId = parseResourceId(c.Param("reservationId"))
+	resources.CancellationPolicyId = parseResourceId(c.Param("policyId"))
+
+	return resources
+}
+
+func parseResourceId(value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+)
+
+// RateLimitMiddleware throttles a public endpoint by client IP, as given by the IP extractor of the
+// server, and, when the request body carries one, by email. The scope keeps the counters of each endpoint apart.
+func (a *Middleware) RateLimitMiddleware(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !a.EnvSettings.RateLimitEnabled {
+				return next(c)
+			}
+
+			ipKey := scope + ":ip:" + c.RealIP()
+			if limited, err := a.checkRateLimit(c, ipKey, a.EnvSettings.RateLimitIpMaxRequests); limited {
+				return err
+			}
+
+			if email := extractEmailFromBody(c); email != "" {
+				emailKey := scope + ":email:" + email
+				if limited, err := a.checkRateLimit(c, emailKey, a.EnvSettings.RateLimitEmailMaxRequests); limited {
+					return err
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Counts a hit on the key and writes the 429 response when it is over the limit. Store failures
+// let the request through so an outage of the store does not block logins.
+func (a *Middleware) checkRateLimit(c echo.Context, key string, limit int) (bool, error) {
+	counter, err := a.BllController.RateLimit.Hit(key, limit)
+	if err == nil {
+		return false, nil
+	}
+
+	if *err != errors.TooManyRequestsError.RateLimitExceeded {
+		a.Logger.Error("Rate limit store failed, allowing request: ", err.Message)
+		return false, nil
+	}
+
+	retryAfter := int(math.Ceil(time.Until(counter.ExpiresAt).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	return true, errors.HandleError(*err, c)
+}
+
+// Reads the email of a JSON body, if any, and restores the body for the handler
+func extractEmailFromBody(c echo.Context) string {
+	if c.Request().Body == nil {
+		return ""
+	}
+
+	bodyBytes, err := io.ReadAll(c.Request().Body)
+	c.Request().Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return ""
+	}
+
+	var request struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(bodyBytes, &request); err != nil {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(request.Email))
+}
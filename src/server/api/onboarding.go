@@ -19,6 +19,7 @@ import (
 // @Success 			200 {object} schemas.Onboarding "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -29,6 +30,17 @@ func (a *Api) GetOnboarding(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidOnboardingId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Onboarding.CheckOnboardingAccess(
+		credentials,
+		onboardingId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Onboarding.GetOnboarding(onboardingId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -37,6 +49,37 @@ func (a *Api) GetOnboarding(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// @Summary 			Get Onboarding by Document.
+// @Description 		Gets the onboarding with a document type and number.
+// @Tags 				Onboarding
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request body schemas.OnboardingDocumentLookupRequest true "Onboarding Document Lookup Request"
+// @Success 			200 {object} schemas.Onboarding "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - user:read permission required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/onboarding/document/ [post]
+func (a *Api) GetOnboardingByDocument(c echo.Context) error {
+	var request schemas.OnboardingDocumentLookupRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.Onboarding.GetOnboardingByDocument(
+		request.DocumentType,
+		request.DocumentNumber,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
 // @Summary 			Get Onboarding by User ID.
 // @Description 		Gets an onboarding given its user id.
 // @Tags 				Onboarding
@@ -47,6 +90,7 @@ func (a *Api) GetOnboarding(c echo.Context) error {
 // @Success 			200 {object} schemas.Onboarding "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -57,6 +101,17 @@ func (a *Api) GetOnboardingByUserId(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Onboarding.CheckUserOnboardingAccess(
+		credentials,
+		userId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Onboarding.GetOnboardingByUserId(userId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -79,12 +134,20 @@ func (a *Api) GetOnboardingByUserId(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/onboarding/ [get]
 func (a *Api) FetchOnboardings(c echo.Context) error {
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+
 	response, err := a.BllController.Onboarding.FetchOnboardings()
 	if err != nil {
 		return errors.HandleError(*err, c)
 	}
 
-	return c.JSON(http.StatusOK, response)
+	return c.JSON(
+		http.StatusOK,
+		a.BllController.Onboarding.FilterOwnedOnboardings(credentials, response),
+	)
 }
 
 // @Summary 			Create Onboarding for User.
@@ -98,12 +161,13 @@ func (a *Api) FetchOnboardings(c echo.Context) error {
 // @Success 			201 {object} schemas.Onboarding "Created"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/onboarding/user/{userId}/ [post]
 func (a *Api) CreateOnboardingForUser(c echo.Context) error {
-	updatedBy := "USER" // Podría ser obtenido del JWT token en producción
+	updatedBy := a.getUpdatedBy(c, "USER") // Podría ser obtenido del JWT token en producción
 
 	userId, parseErr := uuid.Parse(c.Param("userId"))
 	if parseErr != nil {
@@ -115,6 +179,17 @@ func (a *Api) CreateOnboardingForUser(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Onboarding.CheckUserOnboardingAccess(
+		credentials,
+		userId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Onboarding.CreateOnboardingForUser(userId, request, updatedBy)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -134,12 +209,13 @@ func (a *Api) CreateOnboardingForUser(c echo.Context) error {
 // @Success 			200 {object} schemas.Onboarding "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/onboarding/{onboardingId}/ [patch]
 func (a *Api) UpdateOnboarding(c echo.Context) error {
-	updatedBy := "USER" // Podría ser obtenido del JWT token en producción
+	updatedBy := a.getUpdatedBy(c, "USER") // Podría ser obtenido del JWT token en producción
 
 	onboardingId, parseErr := uuid.Parse(c.Param("onboardingId"))
 	if parseErr != nil {
@@ -151,6 +227,17 @@ func (a *Api) UpdateOnboarding(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Onboarding.CheckOnboardingAccess(
+		credentials,
+		onboardingId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Onboarding.UpdateOnboarding(onboardingId, request, updatedBy)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -170,12 +257,13 @@ func (a *Api) UpdateOnboarding(c echo.Context) error {
 // @Success 			200 {object} schemas.Onboarding "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/onboarding/user/{userId}/ [patch]
 func (a *Api) UpdateOnboardingByUserId(c echo.Context) error {
-	updatedBy := "USER" // Podría ser obtenido del JWT token en producción
+	updatedBy := a.getUpdatedBy(c, "USER") // Podría ser obtenido del JWT token en producción
 
 	userId, parseErr := uuid.Parse(c.Param("userId"))
 	if parseErr != nil {
@@ -187,6 +275,17 @@ func (a *Api) UpdateOnboardingByUserId(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Onboarding.CheckUserOnboardingAccess(
+		credentials,
+		userId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Onboarding.UpdateOnboardingByUserId(userId, request, updatedBy)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -205,6 +304,7 @@ func (a *Api) UpdateOnboardingByUserId(c echo.Context) error {
 // @Success 			204 "No Content"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -215,6 +315,17 @@ func (a *Api) DeleteOnboarding(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidOnboardingId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Onboarding.CheckOnboardingAccess(
+		credentials,
+		onboardingId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	if err := a.BllController.Onboarding.DeleteOnboarding(onboardingId); err != nil {
 		return errors.HandleError(*err, c)
 	}
@@ -232,6 +343,7 @@ func (a *Api) DeleteOnboarding(c echo.Context) error {
 // @Success 			204 "No Content"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -242,6 +354,17 @@ func (a *Api) DeleteOnboardingByUserId(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Onboarding.CheckUserOnboardingAccess(
+		credentials,
+		userId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	if err := a.BllController.Onboarding.DeleteOnboardingByUserId(userId); err != nil {
 		return errors.HandleError(*err, c)
 	}
@@ -84,7 +84,7 @@ func (a *Api) FetchPlans(c echo.Context) error {
 // @Router 				/plan/ [post]
 func (a *Api) CreatePlan(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN" // Placeholder for actual user from token
+	updatedBy := a.getUpdatedBy(c, "ADMIN") // Placeholder for actual user from token
 
 	var request schemas.CreatePlanRequest
 	if err := c.Bind(&request); err != nil {
@@ -106,6 +106,7 @@ func (a *Api) CreatePlan(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request	body   schemas.BulkCreatePlanRequest true  "Bulk Create Plan Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Plans "Created"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -119,7 +120,7 @@ func (a *Api) BulkCreatePlans(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 	response, err := a.BllController.Plan.BulkCreatePlans(
 		request.Plans,
 		updateBy,
@@ -148,7 +149,7 @@ func (a *Api) BulkCreatePlans(c echo.Context) error {
 // @Router 				/plan/{planId}/ [patch]
 func (a *Api) UpdatePlan(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN" // Placeholder for actual user from token
+	updatedBy := a.getUpdatedBy(c, "ADMIN") // Placeholder for actual user from token
 
 	planId, parseErr := uuid.Parse(c.Param("planId"))
 	if parseErr != nil {
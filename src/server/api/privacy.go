@@ -0,0 +1,153 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// ExportCurrentUserData godoc
+// @Summary 			Export personal data
+// @Description 		Returns the personal data held about the current user: profile, onboarding, memberships, reservations, identities, sessions and audit log entries.
+// @Tags 				Privacy
+// @Accept 				json
+// @Produce 			json
+// @Produce 			application/zip
+// @Security			JWT
+// @Param               format    query   string  false  "json (default) or zip"
+// @Success 			200 {object} schemas.UserDataExport "Data export"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/data-export/ [get]
+func (a *Api) ExportCurrentUserData(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	return a.exportUserData(c, credentials.UserId, "USER")
+}
+
+// EraseCurrentUserData godoc
+// @Summary 			Erase personal data
+// @Description 		Anonymizes the current user and their onboarding and ends every session. Reservations and memberships are kept for reporting.
+// @Tags 				Privacy
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request body schemas.DataErasureRequest true "Password confirmation"
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			409 {object} errors.Error "Conflict"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/data-erasure/ [post]
+func (a *Api) EraseCurrentUserData(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	var request schemas.DataErasureRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	if err := a.BllController.Privacy.EraseCurrentUserData(credentials.UserId, request); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary 			Export User Data.
+// @Description 		Export the personal data held about a user, e.g. to answer an access request.
+// @Tags 				User
+// @Accept 				json
+// @Produce 			json
+// @Produce 			application/zip
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Param               format    query   string  false  "json (default) or zip"
+// @Success 			200 {object} schemas.UserDataExport "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Admin role required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/user/{userId}/data-export/ [get]
+func (a *Api) ExportUserData(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	return a.exportUserData(c, userId, updatedBy)
+}
+
+// @Summary 			Erase User Data.
+// @Description 		Anonymize a user and their onboarding, keeping reservations and memberships for reporting.
+// @Tags 				User
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Admin role required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/user/{userId}/erase/ [post]
+func (a *Api) EraseUserData(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	if err := a.BllController.Privacy.EraseUserData(userId, updatedBy); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Writes the data export of a user in the format of the query, as a download
+func (a *Api) exportUserData(c echo.Context, userId uuid.UUID, requestedBy string) error {
+	format := schemas.DataExportFormat(c.QueryParam("format"))
+	if format == "" {
+		format = schemas.DataExportFormatJson
+	}
+	if format != schemas.DataExportFormatJson && format != schemas.DataExportFormatZip {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidDataExportFormat, c)
+	}
+
+	export, err := a.BllController.Privacy.ExportUserData(userId, requestedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	fileName := fmt.Sprintf("data-export-%s.%s", export.GeneratedAt.Format("20060102"), format)
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+fileName+`"`)
+
+	if format == schemas.DataExportFormatJson {
+		return c.JSON(http.StatusOK, export)
+	}
+
+	archive, err := a.BllController.Privacy.BuildDataExportArchive(export)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
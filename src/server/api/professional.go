@@ -118,7 +118,7 @@ func (a *Api) FetchProfessionals(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/professional/ [post]
 func (a *Api) CreateProfessional(c echo.Context) error {
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateProfessionalRequest
 	if err := c.Bind(&request); err != nil {
@@ -163,7 +163,7 @@ func (a *Api) CreateProfessional(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/professional/{professionalId}/ [patch]
 func (a *Api) UpdateProfessional(c echo.Context) error {
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 
 	professionalId, parseErr := uuid.Parse(c.Param("professionalId"))
 	if parseErr != nil {
@@ -237,6 +237,7 @@ func (a *Api) DeleteProfessional(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request	body   schemas.BulkCreateProfessionalRequest true  "Bulk Create Professional Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Professionals "Created"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -250,7 +251,7 @@ func (a *Api) BulkCreateProfessionals(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 	response, err := a.BllController.Professional.BulkCreateProfessionals(
 		request.Professionals,
 		updateBy,
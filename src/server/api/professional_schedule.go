@@ -0,0 +1,207 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// @Summary 			Get Professional Working Hours.
+// @Description 		Gets the weekly working hours of a professional. Sessions of a professional with working hours can only be scheduled within them; without working hours they can be scheduled at any time.
+// @Tags 				Professional
+// @Accept 				json
+// @Produce 			json
+// @Param               professionalId    path   string  true  "Professional ID"
+// @Success 			200 {object} schemas.ProfessionalWorkingHours "OK"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/professional/{professionalId}/working-hours/ [get]
+func (a *Api) GetProfessionalWorkingHours(c echo.Context) error {
+	professionalId, parseErr := uuid.Parse(c.Param("professionalId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidProfessionalId, c)
+	}
+
+	response, err := a.BllController.ProfessionalSchedule.GetWorkingHours(professionalId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Update Professional Working Hours.
+// @Description 		Replaces the weekly working hours of a professional: ranges of a weekday (0 being Sunday) with HH:MM times in the booking time zone. An empty list lets the professional lead sessions at any time. Sessions already scheduled are kept.
+// @Tags 				Professional
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               professionalId    path   string  true  "Professional ID"
+// @Param               request body schemas.UpdateWorkingHoursRequest true "Update Working Hours Request"
+// @Success 			200 {object} schemas.ProfessionalWorkingHours "OK"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/professional/{professionalId}/working-hours/ [put]
+func (a *Api) UpdateProfessionalWorkingHours(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	professionalId, parseErr := uuid.Parse(c.Param("professionalId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidProfessionalId, c)
+	}
+
+	var request schemas.UpdateWorkingHoursRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.ProfessionalSchedule.UpdateWorkingHours(
+		professionalId,
+		request,
+		updatedBy,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Fetch Professional Time Off.
+// @Description 		Fetch the time off of a professional. Administrators can see the time off of every professional, professionals only their own.
+// @Tags 				Professional
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               professionalId    path   string  true  "Professional ID"
+// @Success 			200 {object} schemas.ProfessionalTimeOffList "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Time off of another professional"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/professional/{professionalId}/time-off/ [get]
+func (a *Api) FetchProfessionalTimeOff(c echo.Context) error {
+	professionalId, parseErr := uuid.Parse(c.Param("professionalId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidProfessionalId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.ProfessionalSchedule.CheckTimeOffAccess(
+		credentials,
+		professionalId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.ProfessionalSchedule.FetchTimeOff(professionalId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Create Professional Time Off.
+// @Description 		Adds a time off to a professional, in which no sessions can be scheduled for them. Sessions already scheduled within it are kept. Administrators can add time off to every professional, professionals only to themselves.
+// @Tags 				Professional
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               professionalId    path   string  true  "Professional ID"
+// @Param               request body schemas.CreateProfessionalTimeOffRequest true "Create Professional Time Off Request"
+// @Success 			201 {object} schemas.ProfessionalTimeOff "Created"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Time off of another professional"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/professional/{professionalId}/time-off/ [post]
+func (a *Api) CreateProfessionalTimeOff(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	professionalId, parseErr := uuid.Parse(c.Param("professionalId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidProfessionalId, c)
+	}
+
+	var request schemas.CreateProfessionalTimeOffRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.ProfessionalSchedule.CheckTimeOffAccess(
+		credentials,
+		professionalId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.ProfessionalSchedule.CreateTimeOff(professionalId, request, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// @Summary 			Delete Professional Time Off.
+// @Description 		Deletes a time off of a professional. Administrators can delete the time off of every professional, professionals only their own.
+// @Tags 				Professional
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               professionalId    path   string  true  "Professional ID"
+// @Param               timeOffId    path   string  true  "Time Off ID"
+// @Success 			204 "No Content"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Time off of another professional"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/professional/{professionalId}/time-off/{timeOffId}/ [delete]
+func (a *Api) DeleteProfessionalTimeOff(c echo.Context) error {
+	professionalId, parseErr := uuid.Parse(c.Param("professionalId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidProfessionalId, c)
+	}
+	timeOffId, parseErr := uuid.Parse(c.Param("timeOffId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidProfessionalTimeOffId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.ProfessionalSchedule.CheckTimeOffAccess(
+		credentials,
+		professionalId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	if err := a.BllController.ProfessionalSchedule.DeleteTimeOff(professionalId, timeOffId); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -20,6 +20,7 @@ import (
 // @Success 			200 {object} schemas.Reservation "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -30,6 +31,17 @@ func (a *Api) GetReservation(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidReservationId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckReservationAccess(
+		credentials,
+		reservationId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Reservation.GetReservation(reservationId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -50,6 +62,7 @@ func (a *Api) GetReservation(c echo.Context) error {
 // @Success 			200 {object} schemas.Reservations "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -74,6 +87,15 @@ func (a *Api) FetchReservations(c echo.Context) error {
 		states = strings.Split(statesString, ",")
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	userIds, restrictErr := a.BllController.Reservation.RestrictReservationUserIds(credentials, userIds)
+	if restrictErr != nil {
+		return errors.HandleError(*restrictErr, c)
+	}
+
 	response, err := a.BllController.Reservation.FetchReservations(userIds, sessionIds, states)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -83,27 +105,41 @@ func (a *Api) FetchReservations(c echo.Context) error {
 }
 
 // @Summary 			Create Reservation.
-// @Description 		Create a new reservation.
+// @Description 		Create a new reservation. With join_waitlist, a booking of a full session is put on its waitlist instead of being rejected. Users who missed too many sessions of a community with a no-show limit cannot book for a while.
 // @Tags 				Reservation
 // @Accept 				json
 // @Produce 			json
 // @Security			JWT
 // @Param               request body schemas.CreateReservationRequest true "Create Reservation Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Reservation "Created"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			409 {object} errors.Error "Conflict - Session full without joining the waitlist, or overlapping reservation"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/reservation/ [post]
 func (a *Api) CreateReservation(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateReservationRequest
 	if err := c.Bind(&request); err != nil {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckCreateReservationAccess(
+		credentials,
+		request,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, newErr := a.BllController.Reservation.CreateReservation(request, updatedBy)
 	if newErr != nil {
 		return errors.HandleError(*newErr, c)
@@ -113,7 +149,7 @@ func (a *Api) CreateReservation(c echo.Context) error {
 }
 
 // @Summary 			Update Reservation.
-// @Description 		Update an existing reservation.
+// @Description 		Update an existing reservation. Its state can only follow the declared transitions, some of them reserved to staff (e.g. DONE or NO_SHOW). Cancelling late or marking it as a no-show keeps the membership credit when the cancellation policy of the session says so. Confirming a cancelled reservation again checks its membership like a new booking and is refused while the session has a waitlist.
 // @Tags 				Reservation
 // @Accept 				json
 // @Produce 			json
@@ -123,13 +159,15 @@ func (a *Api) CreateReservation(c echo.Context) error {
 // @Success 			200 {object} schemas.Reservation "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict - Session full or with a waitlist"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/reservation/{reservationId}/ [patch]
 func (a *Api) UpdateReservation(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	reservationId, parseErr := uuid.Parse(c.Param("reservationId"))
 	if parseErr != nil {
@@ -141,6 +179,18 @@ func (a *Api) UpdateReservation(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckUpdateReservationAccess(
+		credentials,
+		reservationId,
+		request,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, newErr := a.BllController.Reservation.UpdateReservation(reservationId, request, updatedBy)
 	if newErr != nil {
 		return errors.HandleError(*newErr, c)
@@ -150,7 +200,7 @@ func (a *Api) UpdateReservation(c echo.Context) error {
 }
 
 // @Summary 			Delete Reservation.
-// @Description 		Delete a reservation given its id.
+// @Description 		Delete a reservation given its id. Deleting a confirmed reservation counts as cancelling it under the cancellation policy of its session.
 // @Tags 				Reservation
 // @Accept 				json
 // @Produce 			json
@@ -159,6 +209,7 @@ func (a *Api) UpdateReservation(c echo.Context) error {
 // @Success 			204 "No Content"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/reservation/{reservationId}/ [delete]
@@ -168,6 +219,17 @@ func (a *Api) DeleteReservation(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidReservationId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckReservationAccess(
+		credentials,
+		reservationId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	if err := a.BllController.Reservation.DeleteReservation(reservationId); err != nil {
 		return errors.HandleError(*err, c)
 	}
@@ -175,6 +237,247 @@ func (a *Api) DeleteReservation(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// @Summary 			Preview Reservation Cancellation.
+// @Description 		Tells what cancelling a reservation right now would cost under the cancellation policy of its session: until when it is free and whether the membership credit would be kept as a penalty.
+// @Tags 				Reservation
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               reservationId    path   string  true  "Reservation ID"
+// @Success 			200 {object} schemas.CancellationPreview "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/reservation/{reservationId}/cancellation/ [get]
+func (a *Api) PreviewReservationCancellation(c echo.Context) error {
+	reservationId, parseErr := uuid.Parse(c.Param("reservationId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidReservationId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckReservationAccess(
+		credentials,
+		reservationId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.Reservation.PreviewCancellation(reservationId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Get Reservation Timeline.
+// @Description 		Gets every change of state of a reservation, oldest first, with who made it and why.
+// @Tags 				Reservation
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               reservationId    path   string  true  "Reservation ID"
+// @Success 			200 {object} schemas.ReservationTimeline "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/reservation/{reservationId}/timeline/ [get]
+func (a *Api) GetReservationTimeline(c echo.Context) error {
+	reservationId, parseErr := uuid.Parse(c.Param("reservationId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidReservationId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckReservationAccess(
+		credentials,
+		reservationId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.Reservation.GetReservationTimeline(reservationId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Get Check-in QR Code.
+// @Description 		Renders the check-in code of a confirmed reservation as a QR code to show at the session. The code expires after a few minutes, so it should be fetched again when shown.
+// @Tags 				Reservation
+// @Produce 			image/png
+// @Produce 			image/svg+xml
+// @Security			JWT
+// @Param               reservationId    path   string  true  "Reservation ID"
+// @Param               format    query   string  false  "Image format, png (default) or svg"
+// @Success 			200 {file} binary "OK"
+// @Failure 			400 {object} errors.Error "Bad Request - Reservation not confirmed or check-in closed"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict - Already checked in"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/reservation/{reservationId}/check-in/qr/ [get]
+func (a *Api) GetReservationCheckInQrCode(c echo.Context) error {
+	reservationId, parseErr := uuid.Parse(c.Param("reservationId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidReservationId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckReservationAccess(
+		credentials,
+		reservationId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "png"
+	}
+
+	image, contentType, err := a.BllController.Reservation.GetCheckInQrCode(reservationId, format)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	// A new code is signed on every request
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.Blob(http.StatusOK, contentType, image)
+}
+
+// @Summary 			Check In Reservation.
+// @Description 		Checks the attendee of a reservation in with the token read from their QR code and marks the reservation as DONE.
+// @Tags 				Reservation
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request body schemas.CheckInRequest true "Check-in Request"
+// @Success 			200 {object} schemas.Reservation "OK"
+// @Failure 			400 {object} errors.Error "Bad Request - Invalid or expired code, reservation not confirmed or check-in closed"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Missing permission"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict - Already checked in"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/reservation/check-in/ [post]
+func (a *Api) CheckInReservation(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	var request schemas.CheckInRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.Reservation.CheckIn(request.Token, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Get Waitlist Position.
+// @Description 		Gets the place of a waitlisted reservation in the waitlist of its session.
+// @Tags 				Reservation
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               reservationId    path   string  true  "Reservation ID"
+// @Success 			200 {object} schemas.WaitlistPosition "OK"
+// @Failure 			400 {object} errors.Error "Bad Request - Reservation not waitlisted"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/reservation/{reservationId}/waitlist/ [get]
+func (a *Api) GetWaitlistPosition(c echo.Context) error {
+	reservationId, parseErr := uuid.Parse(c.Param("reservationId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidReservationId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckReservationAccess(
+		credentials,
+		reservationId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.Reservation.GetWaitlistPosition(reservationId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Leave Waitlist.
+// @Description 		Takes a reservation off the waitlist of its session, cancelling it.
+// @Tags 				Reservation
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               reservationId    path   string  true  "Reservation ID"
+// @Success 			200 {object} schemas.Reservation "OK"
+// @Failure 			400 {object} errors.Error "Bad Request - Reservation not waitlisted"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/reservation/{reservationId}/waitlist/ [delete]
+func (a *Api) LeaveWaitlist(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	reservationId, parseErr := uuid.Parse(c.Param("reservationId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidReservationId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckReservationAccess(
+		credentials,
+		reservationId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.Reservation.LeaveWaitlist(reservationId, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
 // @Summary 			Bulk Delete Reservations.
 // @Description 		Bulk delete reservations given their ids.
 // @Tags 				Reservation
@@ -185,6 +488,7 @@ func (a *Api) DeleteReservation(c echo.Context) error {
 // @Success 			204 "No Content"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/reservation/bulk-delete/ [delete]
@@ -194,6 +498,17 @@ func (a *Api) BulkDeleteReservations(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckBulkDeleteReservationsAccess(
+		credentials,
+		request,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	if err := a.BllController.Reservation.BulkDeleteReservations(request); err != nil {
 		return errors.HandleError(*err, c)
 	}
@@ -212,6 +527,7 @@ func (a *Api) BulkDeleteReservations(c echo.Context) error {
 // @Success 			200 {object} schemas.Reservations "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
 // @Failure 			404 {object} errors.Error "Not Found"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
@@ -227,6 +543,18 @@ func (a *Api) GetReservationsByCommunityIdByUserId(c echo.Context) error {
 		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
 	}
 
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.Reservation.CheckUserReservationsAccess(
+		credentials,
+		userId,
+		schemas.PermissionResources{CommunityId: &communityId},
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
 	response, err := a.BllController.Reservation.GetReservationsByCommunityIdByUserId(communityId, userId)
 	if err != nil {
 		return errors.HandleError(*err, c)
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// @Summary 			Fetch Roles.
+// @Description 		Fetch all roles with their permissions.
+// @Tags 				Role
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.Roles "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - role:manage permission required"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/role/ [get]
+func (a *Api) FetchRoles(c echo.Context) error {
+	response, err := a.BllController.Role.FetchRoles()
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Fetch Permissions.
+// @Description 		Fetch every permission that can be granted to a role.
+// @Tags 				Role
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.Permissions "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - role:manage permission required"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/permission/ [get]
+func (a *Api) FetchPermissions(c echo.Context) error {
+	response, err := a.BllController.Role.FetchPermissions()
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Update Role Permissions.
+// @Description 		Replaces the permissions of a role. The administrator role can not be edited.
+// @Tags 				Role
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               roleId    path   string  true  "Role ID"
+// @Param               request    body   schemas.UpdateRolePermissionsRequest  true  "Permission codes"
+// @Success 			200 {object} schemas.Role "OK"
+// @Failure 			400 {object} errors.Error "Bad Request - Unknown permission"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - role:manage permission required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/role/{roleId}/permissions/ [put]
+func (a *Api) UpdateRolePermissions(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	roleId, parseErr := uuid.Parse(c.Param("roleId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRoleId, c)
+	}
+
+	var request schemas.UpdateRolePermissionsRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.Role.UpdateRolePermissions(roleId, request, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Fetch User Role Assignments.
+// @Description 		Fetch the roles granted to a user on top of their primary role.
+// @Tags 				Role
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Success 			200 {object} schemas.UserRoleAssignments "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - role:manage permission required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/role-assignment/user/{userId}/ [get]
+func (a *Api) FetchUserRoleAssignments(c echo.Context) error {
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	response, err := a.BllController.Role.FetchUserRoleAssignments(userId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Create User Role Assignment.
+// @Description 		Grants a role to a user. Community scoped roles such as COMMUNITY_MANAGER require a community.
+// @Tags 				Role
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Param               request    body   schemas.CreateUserRoleAssignmentRequest  true  "Role and optional community"
+// @Success 			201 {object} schemas.UserRoleAssignment "Created"
+// @Failure 			400 {object} errors.Error "Bad Request - Invalid community scope"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - role:manage permission required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict - Role already assigned"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/role-assignment/user/{userId}/ [post]
+func (a *Api) CreateUserRoleAssignment(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	var request schemas.CreateUserRoleAssignmentRequest
+	if err := c.Bind(&request); err != nil || request.RoleName == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.Role.CreateUserRoleAssignment(userId, request, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// @Summary 			Delete User Role Assignment.
+// @Description 		Revokes a role granted to a user.
+// @Tags 				Role
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               assignmentId    path   string  true  "Role assignment ID"
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - role:manage permission required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/role-assignment/{assignmentId}/ [delete]
+func (a *Api) DeleteUserRoleAssignment(c echo.Context) error {
+	assignmentId, parseErr := uuid.Parse(c.Param("assignmentId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserRoleAssignmentId, c)
+	}
+
+	if err := a.BllController.Role.DeleteUserRoleAssignment(assignmentId); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetCurrentUserPermissions godoc
+// @Summary 			Get current user permissions
+// @Description 		Returns the permissions of the current user and the community each one is limited to, if any.
+// @Tags 				Role
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.UserPermissions "OK"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/permissions/ [get]
+func (a *Api) GetCurrentUserPermissions(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	response, err := a.BllController.Role.GetUserPermissions(credentials.UserId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
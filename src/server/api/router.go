@@ -47,14 +47,26 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	healthCheck := a.Echo.Group("/health-check")
 	healthCheck.GET("/", a.HealthCheck)
 
-	// Authentication endpoints (public)
-	a.Echo.POST("/login/", a.Login)
-	a.Echo.POST("/register/", a.Register)
-	a.Echo.POST("/forgot-password/", a.ForgotPassword)
-	a.Echo.POST("/login/google/", a.GoogleLogin)
-
-	// Contact endpoints (public)
-	a.Echo.POST("/contact", a.ContactMessage)
+	// Authentication endpoints (public, rate limited by IP and email)
+	a.Echo.POST("/login/", a.Login, mw.RateLimitMiddleware("login"))
+	a.Echo.POST("/register/", a.Register, mw.RateLimitMiddleware("register"))
+	a.Echo.POST("/forgot-password/", a.ForgotPassword, mw.RateLimitMiddleware("forgot-password"))
+	a.Echo.POST("/forgot-password/sms/", a.SendResetPinBySMS, mw.RateLimitMiddleware("forgot-password"))
+	a.Echo.POST("/forgot-password/reset/", a.ResetPassword, mw.RateLimitMiddleware("forgot-password-reset"))
+	a.Echo.POST("/login/google/", a.GoogleLogin, mw.RateLimitMiddleware("login-google"))
+	a.Echo.GET("/login/oidc/", a.FetchOidcProviders)
+	a.Echo.POST("/login/oidc/:provider/", a.OidcLogin, mw.RateLimitMiddleware("login-oidc"))
+	a.Echo.POST("/login/2fa/", a.LoginWithTwoFactor, mw.RateLimitMiddleware("login-2fa"))
+	a.Echo.POST("/login/2fa/enroll/", a.BeginTwoFactorLoginEnrollment, mw.RateLimitMiddleware("login-2fa"))
+	a.Echo.POST("/verify-email/", a.VerifyEmail, mw.RateLimitMiddleware("verify-email-code"))
+	a.Echo.POST("/verify-email/resend/", a.ResendEmailVerification, mw.RateLimitMiddleware("verify-email"))
+
+	// Token endpoints (public, the access token may already be expired)
+	a.Echo.POST("/auth/refresh/", a.RefreshToken)
+	a.Echo.POST("/auth/logout/", a.Logout)
+
+	// Contact endpoints (public, rate limited by IP and email)
+	a.Echo.POST("/contact", a.ContactMessage, mw.RateLimitMiddleware("contact"))
 
 	// Public browsing endpoints (for both authenticated and unauthenticated users)
 	// Communities
@@ -80,10 +92,16 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	a.Echo.GET("/professional/", a.FetchProfessionals)
 	a.Echo.GET("/professional/:professionalId/", a.GetProfessional)
 	a.Echo.GET("/professional/:professionalId/image/", a.GetProfessionalWithImage)
+	a.Echo.GET("/professional/:professionalId/working-hours/", a.GetProfessionalWorkingHours)
 
 	// Sessions
 	a.Echo.GET("/session/", a.FetchSessions)
 	a.Echo.GET("/session/:sessionId/", a.GetSession)
+	a.Echo.GET("/session/:sessionId/cancellation-policy/", a.GetSessionCancellationPolicy)
+
+	// Cancellation Policies
+	a.Echo.GET("/cancellation-policy/", a.FetchCancellationPolicies)
+	a.Echo.GET("/cancellation-policy/:policyId/", a.GetCancellationPolicy)
 
 	// Community Services
 	a.Echo.GET("/community-service/", a.FetchCommunityServices)
@@ -106,20 +124,56 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	// Current user info
 	a.Echo.GET("/me/", a.GetCurrentUser, mw.JWTMiddleware)
 
-	// Auth management (authenticated users)
-	auth := a.Echo.Group("/auth")
-	auth.Use(mw.JWTMiddleware)
-	auth.POST("/refresh/", a.RefreshToken)
-	auth.POST("/logout/", a.Logout)
+	// Two-factor authentication of the current user
+	twoFactor := a.Echo.Group("/me/2fa")
+	twoFactor.Use(mw.JWTMiddleware)
+	twoFactor.GET("/", a.GetTwoFactorStatus)
+	twoFactor.DELETE("/", a.DisableTwoFactor)
+	twoFactor.POST("/enroll/", a.BeginTwoFactorEnrollment)
+	twoFactor.POST("/confirm/", a.ConfirmTwoFactorEnrollment)
+	twoFactor.POST("/recovery-codes/", a.RegenerateTwoFactorRecoveryCodes)
+
+	// Personal data of the current user
+	a.Echo.GET("/me/data-export/", a.ExportCurrentUserData, mw.JWTMiddleware)
+	a.Echo.POST(
+		"/me/data-erasure/",
+		a.EraseCurrentUserData,
+		mw.JWTMiddleware,
+		mw.RateLimitMiddleware("data-erasure"),
+	)
+
+	// Permissions of the current user
+	a.Echo.GET("/me/permissions/", a.GetCurrentUserPermissions, mw.JWTMiddleware)
+
+	// Login sessions of the current user
+	sessions := a.Echo.Group("/me/sessions")
+	sessions.Use(mw.JWTMiddleware)
+	sessions.GET("/", a.FetchCurrentUserSessions)
+	sessions.DELETE("/", a.RevokeOtherCurrentUserSessions)
+	sessions.DELETE("/:sessionId/", a.RevokeCurrentUserSession)
+
+	// OpenID Connect identities of the current user
+	identities := a.Echo.Group("/me/identities")
+	identities.Use(mw.JWTMiddleware)
+	identities.GET("/", a.FetchUserIdentities)
+	identities.POST("/", a.LinkUserIdentity)
+	identities.DELETE("/:provider/", a.UnlinkUserIdentity)
 
 	// ===== ADMIN + CLIENT MIXED ENDPOINTS (Both roles can access) =====
 
 	// Session availability and conflicts (both admin and client need this)
 	sessionMixed := a.Echo.Group("/session")
-	sessionMixed.Use(mw.JWTMiddleware, mw.AdminOrClientMiddleware) // Admin or Client required
+	sessionMixed.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionSessionRead))
 	sessionMixed.POST("/check-conflicts/", a.CheckSessionConflicts)
 	sessionMixed.POST("/availability/", a.GetDayAvailability)
 
+	// Time off of professionals (administrators, and professionals for their own)
+	professionalTimeOff := a.Echo.Group("/professional/:professionalId/time-off")
+	professionalTimeOff.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionSessionRead))
+	professionalTimeOff.GET("/", a.FetchProfessionalTimeOff)
+	professionalTimeOff.POST("/", a.CreateProfessionalTimeOff)
+	professionalTimeOff.DELETE("/:timeOffId/", a.DeleteProfessionalTimeOff)
+
 	// Community Plan associations (both admin and client need to read)
 	communityPlanMixed := a.Echo.Group("/community-plan")
 	communityPlanMixed.Use(mw.JWTMiddleware, mw.AdminOrClientMiddleware) // Admin or Client required
@@ -148,58 +202,104 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 
 	// Membership endpoints that both admin and client need
 	membershipMixed := a.Echo.Group("/membership")
-	membershipMixed.Use(mw.JWTMiddleware, mw.AdminOrClientMiddleware) // Admin or Client required
+	membershipMixed.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionMembershipRead))
 	membershipMixed.GET("/user/:userId/", a.GetMembershipsByUserId)
-	membershipMixed.GET("/community/:communityId/users", a.GetUsersByCommunityId)
+	membershipMixed.GET(
+		"/community/:communityId/users",
+		a.GetUsersByCommunityId,
+		mw.RequirePermission(schemas.PermissionMembershipAnyUser),
+	)
 	membershipMixed.GET("/user/:userId/community/:communityId", a.GetMembershipByUserAndCommunity)
 
 	// Reservation endpoints that both admin and client need
 	reservationMixed := a.Echo.Group("/reservation")
-	reservationMixed.Use(mw.JWTMiddleware, mw.AdminOrClientMiddleware) // Admin or Client required
-	reservationMixed.GET("/:reservationId/", a.GetReservation)
-	reservationMixed.GET("/", a.FetchReservations)
-	reservationMixed.GET("/:communityId/:userId/", a.GetReservationsByCommunityIdByUserId)
-	reservationMixed.POST("/", a.CreateReservation)
-	reservationMixed.PATCH("/:reservationId/", a.UpdateReservation)
-	reservationMixed.DELETE("/:reservationId/", a.DeleteReservation)
-	reservationMixed.DELETE("/bulk-delete/", a.BulkDeleteReservations)
-
-	// ===== ADMIN ONLY ENDPOINTS (Administrator role required) =====
-
-	// Community management (admin only)
+	reservationMixed.Use(mw.JWTMiddleware)
+	reservationRead := mw.RequirePermission(schemas.PermissionReservationRead)
+	reservationWrite := mw.RequirePermission(schemas.PermissionReservationWrite)
+	reservationMixed.GET("/:reservationId/", a.GetReservation, reservationRead)
+	reservationMixed.GET("/", a.FetchReservations, reservationRead)
+	reservationMixed.GET(
+		"/:communityId/:userId/",
+		a.GetReservationsByCommunityIdByUserId,
+		reservationRead,
+	)
+	reservationMixed.POST("/", a.CreateReservation, reservationWrite, mw.IdempotencyMiddleware)
+	reservationMixed.PATCH("/:reservationId/", a.UpdateReservation, reservationWrite)
+	reservationMixed.DELETE("/:reservationId/", a.DeleteReservation, reservationWrite)
+	reservationMixed.DELETE("/bulk-delete/", a.BulkDeleteReservations, reservationWrite)
+	reservationMixed.GET("/:reservationId/waitlist/", a.GetWaitlistPosition, reservationRead)
+	reservationMixed.DELETE("/:reservationId/waitlist/", a.LeaveWaitlist, reservationWrite)
+	reservationMixed.GET(
+		"/:reservationId/cancellation/",
+		a.PreviewReservationCancellation,
+		reservationRead,
+	)
+	reservationMixed.GET("/:reservationId/timeline/", a.GetReservationTimeline, reservationRead)
+	reservationMixed.GET(
+		"/:reservationId/check-in/qr/",
+		a.GetReservationCheckInQrCode,
+		reservationRead,
+	)
+	reservationMixed.POST(
+		"/check-in/",
+		a.CheckInReservation,
+		mw.RequirePermission(schemas.PermissionReservationCheckIn),
+	)
+
+	// Standing bookings (own user for members, any user with reservation permissions)
+	standingBooking := a.Echo.Group("/standing-booking")
+	standingBooking.Use(mw.JWTMiddleware)
+	standingBooking.GET("/", a.FetchStandingBookings, reservationRead)
+	standingBooking.GET("/:standingBookingId/", a.GetStandingBooking, reservationRead)
+	standingBooking.GET("/:standingBookingId/attempts/", a.FetchStandingBookingAttempts, reservationRead)
+	standingBooking.POST("/", a.CreateStandingBooking, reservationWrite)
+	standingBooking.PATCH("/:standingBookingId/", a.UpdateStandingBooking, reservationWrite)
+	standingBooking.DELETE("/:standingBookingId/", a.DeleteStandingBooking, reservationWrite)
+
+	// ===== ADMIN ENDPOINTS (Administrator role or the given permission required) =====
+
+	// Community management (community:write permission required)
 	community := a.Echo.Group("/community")
-	community.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	community.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionCommunityWrite))
 	community.POST("/", a.CreateCommunity)
 	community.PATCH("/:communityId/", a.UpdateCommunity)
 	community.DELETE("/:communityId/", a.DeleteCommunity)
-	community.POST("/bulk-create/", a.BulkCreateCommunities)
+	community.POST("/bulk-create/", a.BulkCreateCommunities, mw.IdempotencyMiddleware)
 	community.DELETE("/bulk-delete/", a.BulkDeleteCommunities)
 
-	// Professional management (admin only)
+	// Cancellation policies of communities (community:write permission required)
+	cancellationPolicy := a.Echo.Group("/cancellation-policy")
+	cancellationPolicy.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionCommunityWrite))
+	cancellationPolicy.POST("/", a.CreateCancellationPolicy)
+	cancellationPolicy.PATCH("/:policyId/", a.UpdateCancellationPolicy)
+	cancellationPolicy.DELETE("/:policyId/", a.DeleteCancellationPolicy)
+
+	// Professional management (professional:write permission required)
 	professional := a.Echo.Group("/professional")
-	professional.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	professional.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionProfessionalWrite))
 	professional.POST("/", a.CreateProfessional)
 	professional.PATCH("/:professionalId/", a.UpdateProfessional)
 	professional.DELETE("/:professionalId/", a.DeleteProfessional)
-	professional.POST("/bulk-create/", a.BulkCreateProfessionals)
+	professional.PUT("/:professionalId/working-hours/", a.UpdateProfessionalWorkingHours)
+	professional.POST("/bulk-create/", a.BulkCreateProfessionals, mw.IdempotencyMiddleware)
 	professional.DELETE("/bulk-delete/", a.BulkDeleteProfessionals)
 
-	// Local management (admin only)
+	// Local management (local:write permission required)
 	local := a.Echo.Group("/local")
-	local.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	local.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionLocalWrite))
 	local.POST("/", a.CreateLocal)
 	local.PATCH("/:localId/", a.UpdateLocal)
 	local.DELETE("/:localId/", a.DeleteLocal)
-	local.POST("/bulk-create/", a.BulkCreateLocals)
+	local.POST("/bulk-create/", a.BulkCreateLocals, mw.IdempotencyMiddleware)
 	local.DELETE("/bulk-delete/", a.BulkDeleteLocals)
 
-	// Plan management (admin only)
+	// Plan management (plan:write permission required)
 	plan := a.Echo.Group("/plan")
-	plan.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	plan.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionPlanWrite))
 	plan.POST("/", a.CreatePlan)
 	plan.PATCH("/:planId/", a.UpdatePlan)
 	plan.DELETE("/:planId/", a.DeletePlan)
-	plan.POST("/bulk-create/", a.BulkCreatePlans)
+	plan.POST("/bulk-create/", a.BulkCreatePlans, mw.IdempotencyMiddleware)
 	plan.DELETE("/bulk-delete/", a.BulkDeletePlans)
 
 	// User management (admin only)
@@ -212,38 +312,57 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	user.POST("/", a.CreateUser)
 	user.PATCH("/:userId/", a.UpdateUser)
 	user.DELETE("/:userId/", a.DeleteUser)
-	user.POST("/bulk-create/", a.BulkCreateUsers)
+	user.POST("/bulk-create/", a.BulkCreateUsers, mw.IdempotencyMiddleware)
 	user.DELETE("/bulk-delete/", a.BulkDeleteUsers)
 	user.PATCH("/:userId/role/", a.ChangeUserRole)
+	user.POST("/:userId/force-logout/", a.ForceUserLogout)
+	user.GET("/:userId/sessions/", a.FetchUserSessions)
+	user.DELETE("/:userId/sessions/:sessionId/", a.RevokeUserSession)
+	user.POST("/:userId/unlock/", a.UnlockUser)
+	user.POST("/:userId/2fa/reset/", a.ResetUserTwoFactor)
+	user.GET("/:userId/data-export/", a.ExportUserData)
+	user.POST("/:userId/erase/", a.EraseUserData)
 	user.GET("/stats/", a.GetUserStats)
 
 	// User management (admin and client)
 	userMixed := a.Echo.Group("/user")
-	userMixed.POST("/change-password/", a.ChangePassword)
+	userMixed.POST("/change-password/", a.ChangePassword, mw.RateLimitMiddleware("change-password"))
 
-	// Service management (admin only)
+	// Service management (service:write permission required)
 	service := a.Echo.Group("/service")
-	service.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	service.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionServiceWrite))
 	service.POST("/", a.CreateService)
 	service.PATCH("/:serviceId/", a.UpdateService)
 	service.DELETE("/:serviceId/", a.DeleteService)
 	service.DELETE("/bulk-delete/", a.BulkDeleteServices)
 
-	// Session management (admin only)
+	// Session management (session:write permission required)
 	session := a.Echo.Group("/session")
-	session.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	session.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionSessionWrite))
 	session.POST("/", a.CreateSession)
 	session.PATCH("/:sessionId/", a.UpdateSession)
 	session.DELETE("/:sessionId/", a.DeleteSession)
-	session.POST("/bulk/", a.BulkCreateSessions)
+	session.POST("/bulk/", a.BulkCreateSessions, mw.IdempotencyMiddleware)
 	session.DELETE("/bulk-delete/", a.BulkDeleteSessions)
 
+	// Session series (recurring classes)
+	sessionSeries := a.Echo.Group("/session-series")
+	sessionSeries.Use(mw.JWTMiddleware)
+	sessionRead := mw.RequirePermission(schemas.PermissionSessionRead)
+	sessionWrite := mw.RequirePermission(schemas.PermissionSessionWrite)
+	sessionSeries.GET("/", a.FetchSessionSeries, sessionRead)
+	sessionSeries.GET("/:seriesId/", a.GetSessionSeries, sessionRead)
+	sessionSeries.GET("/:seriesId/sessions/", a.FetchSessionSeriesOccurrences, sessionRead)
+	sessionSeries.POST("/", a.CreateSessionSeries, sessionWrite)
+	sessionSeries.PATCH("/:seriesId/sessions/:sessionId/", a.UpdateSessionSeriesOccurrences, sessionWrite)
+	sessionSeries.POST("/:seriesId/sessions/:sessionId/cancel/", a.CancelSessionSeriesOccurrences, sessionWrite)
+
 	// Community Plan management (admin only)
 	communityPlan := a.Echo.Group("/community-plan")
 	communityPlan.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
 	communityPlan.POST("/", a.CreateCommunityPlan)
 	communityPlan.DELETE("/:communityId/:planId/", a.DeleteCommunityPlan)
-	communityPlan.POST("/bulk-create/", a.BulkCreateCommunityPlans)
+	communityPlan.POST("/bulk-create/", a.BulkCreateCommunityPlans, mw.IdempotencyMiddleware)
 	communityPlan.DELETE("/bulk-delete/", a.BulkDeleteCommunityPlans)
 
 	// Community Service management (admin only)
@@ -251,7 +370,7 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	communityService.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
 	communityService.POST("/", a.CreateCommunityService)
 	communityService.DELETE("/:communityId/:serviceId/", a.DeleteCommunityService)
-	communityService.POST("/bulk-create/", a.BulkCreateCommunityServices)
+	communityService.POST("/bulk-create/", a.BulkCreateCommunityServices, mw.IdempotencyMiddleware)
 	communityService.DELETE("/bulk-delete/", a.BulkDeleteCommunityServices)
 
 	// Service Local management (admin only)
@@ -259,7 +378,7 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	serviceLocal.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
 	serviceLocal.POST("/", a.CreateServiceLocal)
 	serviceLocal.DELETE("/:serviceId/:localId/", a.DeleteServiceLocal)
-	serviceLocal.POST("/bulk/", a.BulkCreateServiceLocals)
+	serviceLocal.POST("/bulk/", a.BulkCreateServiceLocals, mw.IdempotencyMiddleware)
 	serviceLocal.DELETE("/bulk/", a.BulkDeleteServiceLocals)
 
 	// Service Professional management (admin only)
@@ -267,30 +386,59 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	serviceProfessional.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
 	serviceProfessional.POST("/", a.CreateServiceProfessional)
 	serviceProfessional.DELETE("/:serviceId/:professionalId/", a.DeleteServiceProfessional)
-	serviceProfessional.POST("/bulk/", a.BulkCreateServiceProfessionals)
+	serviceProfessional.POST("/bulk/", a.BulkCreateServiceProfessionals, mw.IdempotencyMiddleware)
 	serviceProfessional.DELETE("/bulk/", a.BulkDeleteServiceProfessionals)
 
-	// Audit Log management (admin only)
+	// Audit Log management (audit:read permission required)
 	auditLog := a.Echo.Group("/audit-log")
-	auditLog.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	auditLog.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionAuditRead))
 	auditLog.GET("/", a.GetAuditLogs)
 	auditLog.GET("/:auditLogId/", a.GetAuditLogById)
 	auditLog.GET("/stats/", a.GetAuditStats)
 	auditLog.DELETE("/cleanup/", a.DeleteOldAuditLogs)
 
-	// Error Log management (admin only)
+	// Error Log management (audit:read permission required)
 	errorLog := a.Echo.Group("/error-log")
-	errorLog.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	errorLog.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionAuditRead))
 	errorLog.GET("/", a.GetErrorLogs)
 	errorLog.GET("/:auditLogId/", a.GetErrorLogById)
 	errorLog.GET("/stats/", a.GetErrorStats)
 
-	// Reports (admin only)
+	// Reports (report:read permission required)
 	reports := a.Echo.Group("/reports")
-	reports.Use(mw.JWTMiddleware, mw.AdminOnlyMiddleware)
+	reports.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionReportRead))
 	reports.GET("/services", a.GetServiceReport)
 	reports.GET("/communities", a.GetCommunityReport)
 
+	// Roles, permissions and role assignments (role:manage permission required)
+	role := a.Echo.Group("/role")
+	role.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionRoleManage))
+	role.GET("/", a.FetchRoles)
+	role.PUT("/:roleId/permissions/", a.UpdateRolePermissions)
+
+	permission := a.Echo.Group("/permission")
+	permission.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionRoleManage))
+	permission.GET("/", a.FetchPermissions)
+
+	roleAssignment := a.Echo.Group("/role-assignment")
+	roleAssignment.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionRoleManage))
+	roleAssignment.GET("/user/:userId/", a.FetchUserRoleAssignments)
+	roleAssignment.POST("/user/:userId/", a.CreateUserRoleAssignment)
+	roleAssignment.DELETE("/:assignmentId/", a.DeleteUserRoleAssignment)
+
+	// Service accounts and their API keys (service_account:manage permission required)
+	serviceAccount := a.Echo.Group("/service-account")
+	serviceAccount.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionServiceAccountManage))
+	serviceAccount.GET("/", a.FetchServiceAccounts)
+	serviceAccount.POST("/", a.CreateServiceAccount)
+	serviceAccount.POST("/:serviceAccountId/rotate/", a.RotateServiceAccountKey)
+	serviceAccount.POST("/:serviceAccountId/revoke/", a.RevokeServiceAccountKey)
+
+	// Onboarding lookup by document (user:read permission required)
+	onboardingLookup := a.Echo.Group("/onboarding/document")
+	onboardingLookup.Use(mw.JWTMiddleware, mw.RequirePermission(schemas.PermissionUserRead))
+	onboardingLookup.POST("/", a.GetOnboardingByDocument)
+
 	// ===== CLIENT ONLY ENDPOINTS (Client role required) =====
 
 	// Onboarding (client only)
@@ -312,8 +460,8 @@ func (a *Api) RegisterRoutes(envSettings *schemas.EnvSettings) {
 	membership.GET("/", a.FetchMemberships)
 	membership.GET("/user/:userId/", a.GetMembershipsByUserId)
 	membership.GET("/community/:communityId/", a.GetMembershipsByCommunityId)
-	membership.POST("/", a.CreateMembership)
-	membership.POST("/user/:userId/", a.CreateMembershipForUser)
+	membership.POST("/", a.CreateMembership, mw.IdempotencyMiddleware)
+	membership.POST("/user/:userId/", a.CreateMembershipForUser, mw.IdempotencyMiddleware)
 	membership.PATCH("/:membershipId/", a.UpdateMembership)
 	membership.DELETE("/:membershipId/", a.DeleteMembership)
 }
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"net"
+
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 	"onichankimochi.com/astro_cat_backend/src/logging"
@@ -31,15 +33,36 @@ func NewApi(
 ) (*Api, *gorm.DB) {
 	bllController, astroCatPsqlDB := controller.NewControllerCollection(logger, envSettings)
 
+	e := echo.New()
+	e.IPExtractor = newIPExtractor(envSettings.TrustedProxies)
+
 	return &Api{
 		Logger:        logger,
 		BllController: bllController,
 		EnvSettings:   envSettings,
-		Echo:          echo.New(),
+		Echo:          e,
 		S3Service:     services.NewS3Service(logger, envSettings),
 	}, astroCatPsqlDB
 }
 
+// Gets the client IP of requests. X-Forwarded-For is only read behind trusted proxies, and only the
+// hops they added are skipped, so clients can not choose the address rate limits and audit logs see.
+func newIPExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
 // @title AstroCat API
 // @version 1.0
 // @description AstroCat API sample for clients
@@ -51,5 +74,22 @@ func RunService(envSettings *schemas.EnvSettings, logger logging.Logger) {
 	expirer := jobs.NewMembershipExpirer(logger, db)
 	expirer.Start()
 
+	// Avanzar cada 5 minutos el estado de las sesiones y cerrar las reservas de las ya terminadas
+	sessionLifecycleUpdater := jobs.NewSessionLifecycleUpdater(
+		logger,
+		api.BllController.Session,
+		api.BllController.Reservation,
+		envSettings.AttendanceTrackingEnabled,
+	)
+	sessionLifecycleUpdater.Start()
+
+	// Reservar cada hora las sesiones de las reservas recurrentes
+	standingBookingScheduler := jobs.NewStandingBookingScheduler(logger, api.BllController.StandingBooking)
+	standingBookingScheduler.Start()
+
+	// Generar cada día las sesiones de las series recurrentes dentro del horizonte
+	sessionSeriesGenerator := jobs.NewSessionSeriesGenerator(logger, api.BllController.SessionSeries)
+	sessionSeriesGenerator.Start()
+
 	api.RunApi(envSettings)
 }
@@ -128,7 +128,7 @@ func (a *Api) FetchServices(c echo.Context) error {
 // @Router 				/service/ [post]
 func (a *Api) CreateService(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateServiceRequest
 	if err := c.Bind(&request); err != nil {
@@ -174,7 +174,7 @@ func (a *Api) CreateService(c echo.Context) error {
 // @Router 				/service/{serviceId}/ [patch]
 func (a *Api) UpdateService(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	serviceId, parseErr := uuid.Parse(c.Param("serviceId"))
 	if parseErr != nil {
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// @Summary 			Fetch Service Accounts.
+// @Description 		Fetch all service accounts. API keys are never returned, only their prefix.
+// @Tags 				ServiceAccount
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.ServiceAccounts "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - service_account:manage permission required"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/service-account/ [get]
+func (a *Api) FetchServiceAccounts(c echo.Context) error {
+	response, err := a.BllController.ServiceAccount.FetchServiceAccounts()
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Create Service Account.
+// @Description 		Creates a service account with the given scopes. The API key is only shown in this response.
+// @Tags 				ServiceAccount
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request    body   schemas.CreateServiceAccountRequest  true  "Service account"
+// @Success 			201 {object} schemas.ServiceAccountWithKey "Created"
+// @Failure 			400 {object} errors.Error "Bad Request - Unknown scope or invalid expiration"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - service_account:manage permission required"
+// @Failure 			409 {object} errors.Error "Conflict - Name already in use"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/service-account/ [post]
+func (a *Api) CreateServiceAccount(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	var request schemas.CreateServiceAccountRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.ServiceAccount.CreateServiceAccount(request, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// @Summary 			Rotate Service Account Key.
+// @Description 		Replaces the API key of a service account. The previous key stops working right away.
+// @Tags 				ServiceAccount
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               serviceAccountId    path   string  true  "Service Account ID"
+// @Param               request    body   schemas.RotateServiceAccountKeyRequest  true  "New expiration"
+// @Success 			200 {object} schemas.ServiceAccountWithKey "OK"
+// @Failure 			400 {object} errors.Error "Bad Request - Invalid expiration"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - service_account:manage permission required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/service-account/{serviceAccountId}/rotate/ [post]
+func (a *Api) RotateServiceAccountKey(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	serviceAccountId, parseErr := uuid.Parse(c.Param("serviceAccountId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidServiceAccountId, c)
+	}
+
+	var request schemas.RotateServiceAccountKeyRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.ServiceAccount.RotateServiceAccountKey(
+		serviceAccountId,
+		request,
+		updatedBy,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Revoke Service Account Key.
+// @Description 		Revokes the API key of a service account until it is rotated.
+// @Tags 				ServiceAccount
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               serviceAccountId    path   string  true  "Service Account ID"
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - service_account:manage permission required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/service-account/{serviceAccountId}/revoke/ [post]
+func (a *Api) RevokeServiceAccountKey(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	serviceAccountId, parseErr := uuid.Parse(c.Param("serviceAccountId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidServiceAccountId, c)
+	}
+
+	if err := a.BllController.ServiceAccount.RevokeServiceAccountKey(serviceAccountId, updatedBy); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
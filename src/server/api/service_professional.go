@@ -25,7 +25,7 @@ import (
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/service-professional/ [post]
 func (a *Api) CreateServiceProfessional(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateServiceProfessionalRequest
 	if err := c.Bind(&request); err != nil {
@@ -139,6 +139,7 @@ func (a *Api) DeleteServiceProfessional(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request body schemas.BatchCreateServiceProfessionalRequest true "Bulk Create ServiceProfessionals Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.ServiceProfessionals "Created"
 // @Failure 			400 {object} errors.Error "Bad Request (e.g., invalid updatedBy)"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -148,7 +149,7 @@ func (a *Api) DeleteServiceProfessional(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/service-professional/bulk/ [post]
 func (a *Api) BulkCreateServiceProfessionals(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.BatchCreateServiceProfessionalRequest
 	if err := c.Bind(&request); err != nil {
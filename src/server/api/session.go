@@ -90,7 +90,7 @@ func (a *Api) FetchSessions(c echo.Context) error {
 }
 
 // @Summary 			Create Session.
-// @Description 		Create the session information.
+// @Description 		Create the session information. The session must not overlap other sessions of its professional or local, and must fall within the working hours of the professional and outside their time off.
 // @Tags 				Session
 // @Accept 				json
 // @Produce 			json
@@ -100,12 +100,13 @@ func (a *Api) FetchSessions(c echo.Context) error {
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
 // @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict - Overlapping session, outside working hours or during time off"
 // @Failure 			422 {object} errors.Error "Unprocessable Entity"
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/session/ [post]
 func (a *Api) CreateSession(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateSessionRequest
 	if err := c.Bind(&request); err != nil {
@@ -121,7 +122,7 @@ func (a *Api) CreateSession(c echo.Context) error {
 }
 
 // @Summary 			Update Session.
-// @Description 		Update the session information.
+// @Description 		Update the session information. Setting the state to CANCELLED annuls the confirmed and waitlisted reservations and gives back their membership uses; setting it to RESCHEDULED carries them over to the new time. In both cases the attendees are notified by email and the affected reservations come back in `affected_reservation_ids`.
 // @Tags 				Session
 // @Accept 				json
 // @Produce 			json
@@ -137,7 +138,7 @@ func (a *Api) CreateSession(c echo.Context) error {
 // @Router 				/session/{sessionId}/ [patch]
 func (a *Api) UpdateSession(c echo.Context) error {
 	// TODO: Add access token validation (from here we will get the `updatedBy` param)
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	sessionId, parseErr := uuid.Parse(c.Param("sessionId"))
 	if parseErr != nil {
@@ -218,6 +219,7 @@ func (a *Api) BulkDeleteSessions(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request body schemas.BatchCreateSessionRequest true "Bulk Create Sessions Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			201 {object} schemas.Sessions "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -226,7 +228,7 @@ func (a *Api) BulkDeleteSessions(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/session/bulk/ [post]
 func (a *Api) BulkCreateSessions(c echo.Context) error {
-	updatedBy := "ADMIN"
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.BatchCreateSessionRequest
 
@@ -247,7 +249,7 @@ func (a *Api) BulkCreateSessions(c echo.Context) error {
 }
 
 // @Summary 			Check Session Conflicts.
-// @Description 		Check for time conflicts with existing sessions.
+// @Description 		Check for time conflicts with existing sessions and with the working hours and time off of the professional.
 // @Tags 				Session
 // @Accept 				json
 // @Produce 			json
@@ -274,7 +276,7 @@ func (a *Api) CheckSessionConflicts(c echo.Context) error {
 }
 
 // @Summary 			Get Day Availability.
-// @Description 		Get availability information for a specific date, optionally excluding a specific session.
+// @Description 		Get availability information for a specific date, optionally excluding a specific session. For a professional, the times outside their working hours and their time off are busy slots as well.
 // @Tags 				Session
 // @Accept 				json
 // @Produce 			json
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// @Summary 			Get Session Series.
+// @Description 		Gets a session series given its id.
+// @Tags 				Session Series
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               seriesId    path   string  true  "Session Series ID"
+// @Success 			200 {object} schemas.SessionSeries "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/session-series/{seriesId}/ [get]
+func (a *Api) GetSessionSeries(c echo.Context) error {
+	seriesId, parseErr := uuid.Parse(c.Param("seriesId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidSessionSeriesId, c)
+	}
+
+	response, err := a.BllController.SessionSeries.GetSessionSeries(seriesId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Fetch Session Series.
+// @Description 		Fetch all session series, filtered by professional.
+// @Tags 				Session Series
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param 				professionalIds query []string false "Professional IDs"
+// @Success 			200 {object} schemas.SessionSeriesList "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/session-series/ [get]
+func (a *Api) FetchSessionSeries(c echo.Context) error {
+	professionalIdsString := c.QueryParam("professionalIds")
+
+	professionalIds := []string{}
+	if professionalIdsString != "" {
+		professionalIds = strings.Split(professionalIdsString, ",")
+	}
+
+	response, err := a.BllController.SessionSeries.FetchSessionSeries(professionalIds)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Fetch Session Series Occurrences.
+// @Description 		Fetch the sessions generated so far for a series, with the day each one was generated for.
+// @Tags 				Session Series
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               seriesId    path   string  true  "Session Series ID"
+// @Success 			200 {object} schemas.SessionSeriesOccurrences "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/session-series/{seriesId}/sessions/ [get]
+func (a *Api) FetchSessionSeriesOccurrences(c echo.Context) error {
+	seriesId, parseErr := uuid.Parse(c.Param("seriesId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidSessionSeriesId, c)
+	}
+
+	response, err := a.BllController.SessionSeries.FetchSessionSeriesOccurrences(seriesId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Create Session Series.
+// @Description 		Create a recurring class from a weekly recurrence rule (RRULE subset of RFC 5545: FREQ=WEEKLY with BYDAY, INTERVAL, and UNTIL or COUNT) and the days to leave out (EXDATE). Its sessions are generated over a rolling horizon, and the occurrences that conflict with other sessions are reported as skipped.
+// @Tags 				Session Series
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request body schemas.CreateSessionSeriesRequest true "Create Session Series Request"
+// @Success 			201 {object} schemas.SessionSeriesReport "Created"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/session-series/ [post]
+func (a *Api) CreateSessionSeries(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	var request schemas.CreateSessionSeriesRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.SessionSeries.CreateSessionSeries(request, updatedBy)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// @Summary 			Update Session Series Occurrences.
+// @Description 		Edit an occurrence of a series (THIS), this and the following ones (THIS_AND_FOLLOWING, which splits the series in two) or every upcoming one (ALL). Occurrences edited on their own are left as they are by the other scopes, and those that would conflict with other sessions are reported as skipped.
+// @Tags 				Session Series
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               seriesId    path   string  true  "Session Series ID"
+// @Param               sessionId    path   string  true  "Session ID"
+// @Param 				scope query string false "THIS (default), THIS_AND_FOLLOWING or ALL"
+// @Param               request body schemas.UpdateSessionSeriesRequest true "Update Session Series Request"
+// @Success 			200 {object} schemas.SessionSeriesReport "OK"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict - Occurrence overlaps another session"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/session-series/{seriesId}/sessions/{sessionId}/ [patch]
+func (a *Api) UpdateSessionSeriesOccurrences(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	seriesId, parseErr := uuid.Parse(c.Param("seriesId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidSessionSeriesId, c)
+	}
+	sessionId, parseErr := uuid.Parse(c.Param("sessionId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidSessionId, c)
+	}
+
+	var request schemas.UpdateSessionSeriesRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.SessionSeries.UpdateSessionSeriesOccurrences(
+		seriesId,
+		sessionId,
+		getSessionSeriesScope(c),
+		request,
+		updatedBy,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Cancel Session Series Occurrences.
+// @Description 		Cancel an occurrence of a series (THIS), this and the following ones (THIS_AND_FOLLOWING) or every upcoming one (ALL). Cancelling several occurrences also ends the recurrence, and the reservations of the cancelled sessions are annulled.
+// @Tags 				Session Series
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               seriesId    path   string  true  "Session Series ID"
+// @Param               sessionId    path   string  true  "Session ID"
+// @Param 				scope query string false "THIS (default), THIS_AND_FOLLOWING or ALL"
+// @Success 			200 {object} schemas.SessionSeriesReport "OK"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/session-series/{seriesId}/sessions/{sessionId}/cancel/ [post]
+func (a *Api) CancelSessionSeriesOccurrences(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	seriesId, parseErr := uuid.Parse(c.Param("seriesId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidSessionSeriesId, c)
+	}
+	sessionId, parseErr := uuid.Parse(c.Param("sessionId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidSessionId, c)
+	}
+
+	response, err := a.BllController.SessionSeries.CancelSessionSeriesOccurrences(
+		seriesId,
+		sessionId,
+		getSessionSeriesScope(c),
+		updatedBy,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Gets the occurrences a change applies to, only the given one by default
+func getSessionSeriesScope(c echo.Context) string {
+	scope := strings.ToUpper(c.QueryParam("scope"))
+	if scope == "" {
+		return schemas.SessionSeriesScopeThis
+	}
+	return scope
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// @Summary 			Get Standing Booking.
+// @Description 		Gets a standing booking given its id.
+// @Tags 				Standing Booking
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               standingBookingId    path   string  true  "Standing Booking ID"
+// @Success 			200 {object} schemas.StandingBooking "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/standing-booking/{standingBookingId}/ [get]
+func (a *Api) GetStandingBooking(c echo.Context) error {
+	standingBookingId, parseErr := uuid.Parse(c.Param("standingBookingId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidStandingBookingId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.StandingBooking.CheckStandingBookingAccess(
+		credentials,
+		standingBookingId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.StandingBooking.GetStandingBooking(standingBookingId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Fetch Standing Bookings.
+// @Description 		Fetch all standing bookings, filtered by user.
+// @Tags 				Standing Booking
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param 				userIds query []string false "User IDs"
+// @Success 			200 {object} schemas.StandingBookings "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/standing-booking/ [get]
+func (a *Api) FetchStandingBookings(c echo.Context) error {
+	userIdsString := c.QueryParam("userIds")
+
+	userIds := []string{}
+	if userIdsString != "" {
+		userIds = strings.Split(userIdsString, ",")
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	userIds, restrictErr := a.BllController.Reservation.RestrictReservationUserIds(credentials, userIds)
+	if restrictErr != nil {
+		return errors.HandleError(*restrictErr, c)
+	}
+
+	response, err := a.BllController.StandingBooking.FetchStandingBookings(userIds)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Create Standing Booking.
+// @Description 		Subscribe a member to a weekly pattern (weekday, start time, service and professional). Every matching session is booked with the given membership as it enters the booking window, and the sessions that could not be booked are reported as skipped with the reason.
+// @Tags 				Standing Booking
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request body schemas.CreateStandingBookingRequest true "Create Standing Booking Request"
+// @Success 			201 {object} schemas.StandingBooking "Created"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			409 {object} errors.Error "Conflict - Same standing booking already exists"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/standing-booking/ [post]
+func (a *Api) CreateStandingBooking(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	var request schemas.CreateStandingBookingRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.StandingBooking.CheckCreateStandingBookingAccess(
+		credentials,
+		request,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, newErr := a.BllController.StandingBooking.CreateStandingBooking(request, updatedBy)
+	if newErr != nil {
+		return errors.HandleError(*newErr, c)
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// @Summary 			Update Standing Booking.
+// @Description 		Pause or resume a standing booking, or change the membership it books with. Resuming books the matching sessions within the booking window right away.
+// @Tags 				Standing Booking
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               standingBookingId    path   string  true  "Standing Booking ID"
+// @Param               request body schemas.UpdateStandingBookingRequest true "Update Standing Booking Request"
+// @Success 			200 {object} schemas.StandingBooking "OK"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/standing-booking/{standingBookingId}/ [patch]
+func (a *Api) UpdateStandingBooking(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	standingBookingId, parseErr := uuid.Parse(c.Param("standingBookingId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidStandingBookingId, c)
+	}
+
+	var request schemas.UpdateStandingBookingRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.StandingBooking.CheckUpdateStandingBookingAccess(
+		credentials,
+		standingBookingId,
+		request,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, newErr := a.BllController.StandingBooking.UpdateStandingBooking(
+		standingBookingId,
+		request,
+		updatedBy,
+	)
+	if newErr != nil {
+		return errors.HandleError(*newErr, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// @Summary 			Delete Standing Booking.
+// @Description 		Delete a standing booking given its id. The reservations it already made are kept.
+// @Tags 				Standing Booking
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               standingBookingId    path   string  true  "Standing Booking ID"
+// @Success 			204 "No Content"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/standing-booking/{standingBookingId}/ [delete]
+func (a *Api) DeleteStandingBooking(c echo.Context) error {
+	standingBookingId, parseErr := uuid.Parse(c.Param("standingBookingId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidStandingBookingId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.StandingBooking.CheckStandingBookingAccess(
+		credentials,
+		standingBookingId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	if err := a.BllController.StandingBooking.DeleteStandingBooking(standingBookingId); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary 			Fetch Standing Booking Attempts.
+// @Description 		Fetch the sessions a standing booking booked or skipped, with the reason of every skip.
+// @Tags 				Standing Booking
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               standingBookingId    path   string  true  "Standing Booking ID"
+// @Success 			200 {object} schemas.StandingBookingAttempts "OK"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Resource of another user"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/standing-booking/{standingBookingId}/attempts/ [get]
+func (a *Api) FetchStandingBookingAttempts(c echo.Context) error {
+	standingBookingId, parseErr := uuid.Parse(c.Param("standingBookingId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidStandingBookingId, c)
+	}
+
+	credentials, authErr := a.getCallerCredentials(c)
+	if authErr != nil {
+		return errors.HandleError(*authErr, c)
+	}
+	if err := a.BllController.StandingBooking.CheckStandingBookingAccess(
+		credentials,
+		standingBookingId,
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	response, err := a.BllController.StandingBooking.FetchStandingBookingAttempts(standingBookingId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
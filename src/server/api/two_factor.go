@@ -0,0 +1,249 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// LoginWithTwoFactor 	godoc
+// @Summary 			Two-factor login step
+// @Description 		Completes a login that returned a two-factor challenge using a TOTP or recovery code.
+// @Description 		Users that must enrol send the first code of their new secret and also get their recovery codes.
+// @Tags 				Login
+// @Accept 				json
+// @Produce 			json
+// @Param               request    body   schemas.TwoFactorLoginRequest  true  "Challenge token and code"
+// @Success 			200 {object} schemas.LoginResponse "Login successful"
+// @Failure 			400 {object} errors.Error "Bad Request - Invalid code"
+// @Failure 			401 {object} errors.Error "Unauthorized - Invalid or expired challenge"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			429 {object} errors.Error "Too Many Requests - Account locked"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/login/2fa/ [post]
+func (a *Api) LoginWithTwoFactor(c echo.Context) error {
+	var request schemas.TwoFactorLoginRequest
+	if err := c.Bind(&request); err != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+	if request.ChallengeToken == "" || (request.Code == "" && request.RecoveryCode == "") {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.Login.LoginWithTwoFactor(request)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+	a.BllController.LoginSession.RecordDevice(c, response.Tokens.SessionId)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// BeginTwoFactorLoginEnrollment godoc
+// @Summary 			Start mandatory two-factor enrolment
+// @Description 		Generates the TOTP secret of a user whose login challenge requires enrolment.
+// @Tags 				Login
+// @Accept 				json
+// @Produce 			json
+// @Param               request    body   schemas.TwoFactorChallengeRequest  true  "Challenge token"
+// @Success 			200 {object} schemas.TwoFactorEnrollmentResponse "Secret and provisioning URI"
+// @Failure 			400 {object} errors.Error "Bad Request - Already enabled"
+// @Failure 			401 {object} errors.Error "Unauthorized - Invalid or expired challenge"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/login/2fa/enroll/ [post]
+func (a *Api) BeginTwoFactorLoginEnrollment(c echo.Context) error {
+	var request schemas.TwoFactorChallengeRequest
+	if err := c.Bind(&request); err != nil || request.ChallengeToken == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.Login.BeginTwoFactorEnrollment(request.ChallengeToken)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetTwoFactorStatus 	godoc
+// @Summary 			Get two-factor status
+// @Description 		Returns whether the current user has two-factor authentication enabled or required.
+// @Tags 				TwoFactor
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.TwoFactorStatus "Two-factor status"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/2fa/ [get]
+func (a *Api) GetTwoFactorStatus(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	response, err := a.BllController.TwoFactor.GetStatus(credentials.UserId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// BeginTwoFactorEnrollment godoc
+// @Summary 			Start two-factor enrolment
+// @Description 		Generates a TOTP secret and the otpauth URI to show as a QR code. It must be confirmed with a code.
+// @Tags 				TwoFactor
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.TwoFactorEnrollmentResponse "Secret and provisioning URI"
+// @Failure 			400 {object} errors.Error "Bad Request - Already enabled"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/2fa/enroll/ [post]
+func (a *Api) BeginTwoFactorEnrollment(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	response, err := a.BllController.TwoFactor.BeginEnrollment(credentials.UserId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ConfirmTwoFactorEnrollment godoc
+// @Summary 			Confirm two-factor enrolment
+// @Description 		Enables two-factor authentication with a code of the authenticator app. The recovery codes are only shown once.
+// @Tags 				TwoFactor
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request    body   schemas.TwoFactorCodeRequest  true  "TOTP code"
+// @Success 			200 {object} schemas.TwoFactorRecoveryCodesResponse "Recovery codes"
+// @Failure 			400 {object} errors.Error "Bad Request - Invalid code or not enrolled"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/2fa/confirm/ [post]
+func (a *Api) ConfirmTwoFactorEnrollment(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	var request schemas.TwoFactorCodeRequest
+	if err := c.Bind(&request); err != nil || request.Code == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.TwoFactor.ConfirmEnrollment(credentials.UserId, request.Code)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RegenerateTwoFactorRecoveryCodes godoc
+// @Summary 			Regenerate recovery codes
+// @Description 		Replaces every recovery code of the current user. Requires a TOTP code.
+// @Tags 				TwoFactor
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request    body   schemas.TwoFactorCodeRequest  true  "TOTP code"
+// @Success 			200 {object} schemas.TwoFactorRecoveryCodesResponse "Recovery codes"
+// @Failure 			400 {object} errors.Error "Bad Request - Invalid code or not enrolled"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/2fa/recovery-codes/ [post]
+func (a *Api) RegenerateTwoFactorRecoveryCodes(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	var request schemas.TwoFactorCodeRequest
+	if err := c.Bind(&request); err != nil || request.Code == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.TwoFactor.RegenerateRecoveryCodes(credentials.UserId, request.Code)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// DisableTwoFactor 	godoc
+// @Summary 			Disable two-factor authentication
+// @Description 		Disables two-factor authentication with a TOTP or recovery code. Not allowed when the policy makes it mandatory.
+// @Tags 				TwoFactor
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request    body   schemas.TwoFactorCodeRequest  true  "TOTP or recovery code"
+// @Success 			204 "No Content"
+// @Failure 			400 {object} errors.Error "Bad Request - Invalid code or not enrolled"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			403 {object} errors.Error "Forbidden - Two-factor authentication is mandatory"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/2fa/ [delete]
+func (a *Api) DisableTwoFactor(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	var request schemas.TwoFactorCodeRequest
+	if err := c.Bind(&request); err != nil || request.Code == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	if err := a.BllController.TwoFactor.Disable(credentials.UserId, request.Code); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary 			Reset User Two-Factor.
+// @Description 		Removes the two-factor authentication of a user that lost the device and the recovery codes.
+// @Tags 				User
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Success 			204 "No Content"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Admin role required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/user/{userId}/2fa/reset/ [post]
+func (a *Api) ResetUserTwoFactor(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	if err := a.BllController.TwoFactor.Reset(userId, updatedBy); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -118,7 +118,7 @@ func (a *Api) FetchUsers(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/user/ [post]
 func (a *Api) CreateUser(c echo.Context) error {
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.CreateUserRequest
 	if err := c.Bind(&request); err != nil {
@@ -163,7 +163,7 @@ func (a *Api) CreateUser(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/user/{userId}/ [patch]
 func (a *Api) UpdateUser(c echo.Context) error {
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 
 	userId, parseErr := uuid.Parse(c.Param("userId"))
 	if parseErr != nil {
@@ -258,6 +258,7 @@ func (a *Api) BulkDeleteUsers(c echo.Context) error {
 // @Produce 			json
 // @Security			JWT
 // @Param               request	body   schemas.BulkCreateUserRequest true  "Bulk Create User Request"
+// @Param               Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 			200 {object} schemas.User "OK"
 // @Failure 			400 {object} errors.Error "Bad Request"
 // @Failure 			401 {object} errors.Error "Missing or malformed JWT"
@@ -266,7 +267,7 @@ func (a *Api) BulkDeleteUsers(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/user/bulk-create/ [post]
 func (a *Api) BulkCreateUsers(c echo.Context) error {
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 
 	var request schemas.BulkCreateUserRequest
 	if err := c.Bind(&request); err != nil {
@@ -341,7 +342,7 @@ func (a *Api) ChangePassword(c echo.Context) error {
 }
 
 // @Summary 			Change User Role.
-// @Description 		Changes the role of a user given its id.
+// @Description 		Changes the role of a user given its id. Community scoped roles such as COMMUNITY_MANAGER can not be a primary role, they are granted for a community through role assignments.
 // @Tags 				User
 // @Accept 				json
 // @Produce 			json
@@ -357,7 +358,7 @@ func (a *Api) ChangePassword(c echo.Context) error {
 // @Failure 			500 {object} errors.Error "Internal Server Error"
 // @Router 				/user/{userId}/role/ [patch]
 func (a *Api) ChangeUserRole(c echo.Context) error {
-	updateBy := "ADMIN"
+	updateBy := a.getUpdatedBy(c, "ADMIN")
 
 	userId, parseErr := uuid.Parse(c.Param("userId"))
 	if parseErr != nil {
@@ -383,6 +384,66 @@ func (a *Api) ChangeUserRole(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// @Summary 			Force User Logout.
+// @Description 		Invalidates every access and refresh token of a user so they have to log in again.
+// @Tags 				User
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Success 			204 "No Content"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Admin role required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/user/{userId}/force-logout/ [post]
+func (a *Api) ForceUserLogout(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	if err := a.BllController.User.ForceLogout(userId, updatedBy); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary 			Unlock User.
+// @Description 		Clears the failed login attempts and the lockout of a user.
+// @Tags 				User
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               userId    path   string  true  "User ID"
+// @Success 			204 "No Content"
+// @Failure 			400 {object} errors.Error "Bad Request"
+// @Failure 			401 {object} errors.Error "Missing or malformed JWT"
+// @Failure 			403 {object} errors.Error "Forbidden - Admin role required"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/user/{userId}/unlock/ [post]
+func (a *Api) UnlockUser(c echo.Context) error {
+	updatedBy := a.getUpdatedBy(c, "ADMIN")
+
+	userId, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidUserId, c)
+	}
+
+	if err := a.BllController.User.UnlockUser(userId, updatedBy); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 // @Summary 			Get User Statistics.
 // @Description 		Get user statistics including role distribution and recent connections.
 // @Tags 				User
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// FetchUserIdentities 	godoc
+// @Summary 			Fetch linked identities
+// @Description 		Returns the OpenID Connect identities linked to the current user.
+// @Tags 				UserIdentity
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Success 			200 {object} schemas.UserIdentities "Linked identities"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/identities/ [get]
+func (a *Api) FetchUserIdentities(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	response, err := a.BllController.UserIdentity.FetchUserIdentities(credentials.UserId)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// LinkUserIdentity 	godoc
+// @Summary 			Link an identity
+// @Description 		Links the identity of an ID token of a configured provider to the current user.
+// @Tags 				UserIdentity
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               request    body   schemas.LinkUserIdentityRequest  true  "Provider and ID token"
+// @Success 			201 {object} schemas.UserIdentity "Linked identity"
+// @Failure 			401 {object} errors.Error "Unauthorized - Invalid access or ID token"
+// @Failure 			404 {object} errors.Error "Not Found - Provider not configured"
+// @Failure 			409 {object} errors.Error "Conflict - Identity or provider already linked"
+// @Failure 			422 {object} errors.Error "Unprocessable Entity"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/identities/ [post]
+func (a *Api) LinkUserIdentity(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	var request schemas.LinkUserIdentityRequest
+	if err := c.Bind(&request); err != nil || request.Provider == "" || request.Token == "" {
+		return errors.HandleError(errors.UnprocessableEntityError.InvalidRequestBody, c)
+	}
+
+	response, err := a.BllController.UserIdentity.LinkIdentity(
+		c.Request().Context(),
+		credentials.UserId,
+		request,
+	)
+	if err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// UnlinkUserIdentity 	godoc
+// @Summary 			Unlink an identity
+// @Description 		Unlinks the identity of a provider from the current user. Accounts without password keep their last identity.
+// @Tags 				UserIdentity
+// @Accept 				json
+// @Produce 			json
+// @Security			JWT
+// @Param               provider    path   string  true  "Provider name"
+// @Success 			204 "No Content"
+// @Failure 			400 {object} errors.Error "Bad Request - Last login method"
+// @Failure 			401 {object} errors.Error "Unauthorized"
+// @Failure 			404 {object} errors.Error "Not Found"
+// @Failure 			500 {object} errors.Error "Internal Server Error"
+// @Router 				/me/identities/{provider}/ [delete]
+func (a *Api) UnlinkUserIdentity(c echo.Context) error {
+	_, credentials, authError := a.BllController.Auth.AccessTokenValidation(c)
+	if authError != nil {
+		return errors.HandleError(*authError, c)
+	}
+
+	if err := a.BllController.UserIdentity.UnlinkIdentity(
+		credentials.UserId,
+		c.Param("provider"),
+	); err != nil {
+		return errors.HandleError(*err, c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,184 @@
+package adapter
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPsql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type CancellationPolicy struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPsql.AstroCatPsqlCollection
+}
+
+// Creates CancellationPolicy adapter
+func NewCancellationPolicyAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPsql.AstroCatPsqlCollection,
+) *CancellationPolicy {
+	return &CancellationPolicy{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Gets a cancellation policy from postgresql DB given its ID and adapts it to its schema.
+func (cp *CancellationPolicy) GetPostgresqlCancellationPolicy(
+	policyId uuid.UUID,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	policyModel, err := cp.DaoPostgresql.CancellationPolicy.GetCancellationPolicy(policyId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.CancellationPolicyNotFound
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertCancellationPolicyModelToSchema(policyModel), nil
+}
+
+// Gets the cancellation policy that applies to a service of a community, or nil if there is none.
+func (cp *CancellationPolicy) GetPostgresqlApplicableCancellationPolicy(
+	communityId uuid.UUID,
+	serviceId uuid.UUID,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	policyModel, err := cp.DaoPostgresql.CancellationPolicy.GetApplicableCancellationPolicy(
+		communityId,
+		serviceId,
+	)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertCancellationPolicyModelToSchema(policyModel), nil
+}
+
+// Gets the cancellation policy of exactly a community, or of a service of it when `serviceId` is
+// given.
+func (cp *CancellationPolicy) GetPostgresqlCancellationPolicyByScope(
+	communityId uuid.UUID,
+	serviceId *uuid.UUID,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	policyModel, err := cp.DaoPostgresql.CancellationPolicy.GetCancellationPolicyByScope(
+		communityId,
+		serviceId,
+	)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.CancellationPolicyNotFound
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertCancellationPolicyModelToSchema(policyModel), nil
+}
+
+// Fetches cancellation policies from postgresql DB and adapts them to their schema.
+func (cp *CancellationPolicy) FetchPostgresqlCancellationPolicies(
+	communityIds []uuid.UUID,
+) ([]*schemas.CancellationPolicy, *errors.Error) {
+	policyModels, err := cp.DaoPostgresql.CancellationPolicy.FetchCancellationPolicies(communityIds)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	policies := make([]*schemas.CancellationPolicy, len(policyModels))
+	for i, policyModel := range policyModels {
+		policies[i] = convertCancellationPolicyModelToSchema(policyModel)
+	}
+
+	return policies, nil
+}
+
+// Creates a cancellation policy in postgresql DB and adapts it to its schema.
+func (cp *CancellationPolicy) CreatePostgresqlCancellationPolicy(
+	request schemas.CreateCancellationPolicyRequest,
+	updatedBy string,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	policyModel := &model.CancellationPolicy{
+		Id:                          uuid.New(),
+		FreeCancellationHours:       request.FreeCancellationHours,
+		LateCancellationBurnsCredit: request.LateCancellationBurnsCredit,
+		NoShowBurnsCredit:           request.NoShowBurnsCredit,
+		NoShowLimit:                 request.NoShowLimit,
+		NoShowWindowDays:            request.NoShowWindowDays,
+		CommunityId:                 request.CommunityId,
+		ServiceId:                   request.ServiceId,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := cp.DaoPostgresql.CancellationPolicy.CreateCancellationPolicy(policyModel); err != nil {
+		return nil, &errors.BadRequestError.CancellationPolicyNotCreated
+	}
+
+	return convertCancellationPolicyModelToSchema(policyModel), nil
+}
+
+// Updates a cancellation policy in postgresql DB and adapts it to its schema.
+func (cp *CancellationPolicy) UpdatePostgresqlCancellationPolicy(
+	policyId uuid.UUID,
+	request schemas.UpdateCancellationPolicyRequest,
+	updatedBy string,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	policyModel, err := cp.DaoPostgresql.CancellationPolicy.UpdateCancellationPolicy(
+		policyId,
+		request.FreeCancellationHours,
+		request.LateCancellationBurnsCredit,
+		request.NoShowBurnsCredit,
+		request.NoShowLimit,
+		request.NoShowWindowDays,
+		updatedBy,
+	)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.CancellationPolicyNotFound
+		}
+		return nil, &errors.BadRequestError.CancellationPolicyNotUpdated
+	}
+
+	return convertCancellationPolicyModelToSchema(policyModel), nil
+}
+
+// Deletes a cancellation policy from postgresql DB.
+func (cp *CancellationPolicy) DeletePostgresqlCancellationPolicy(policyId uuid.UUID) *errors.Error {
+	if err := cp.DaoPostgresql.CancellationPolicy.DeleteCancellationPolicy(policyId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.CancellationPolicyNotFound
+		}
+		return &errors.BadRequestError.CancellationPolicyNotDeleted
+	}
+
+	return nil
+}
+
+func convertCancellationPolicyModelToSchema(
+	policyModel *model.CancellationPolicy,
+) *schemas.CancellationPolicy {
+	return &schemas.CancellationPolicy{
+		Id:                          policyModel.Id,
+		FreeCancellationHours:       policyModel.FreeCancellationHours,
+		LateCancellationBurnsCredit: policyModel.LateCancellationBurnsCredit,
+		NoShowBurnsCredit:           policyModel.NoShowBurnsCredit,
+		NoShowLimit:                 policyModel.NoShowLimit,
+		NoShowWindowDays:            policyModel.NoShowWindowDays,
+		CommunityId:                 policyModel.CommunityId,
+		ServiceId:                   policyModel.ServiceId,
+	}
+}
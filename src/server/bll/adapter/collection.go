@@ -6,26 +6,43 @@ import (
 	"gorm.io/gorm"
 	"onichankimochi.com/astro_cat_backend/src/logging"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
 )
 
 type AdapterCollection struct {
-	Logger               logging.Logger
-	Community            *Community
-	Professional         *Professional
-	Local                *Local
-	User                 *User
-	Onboarding           *Onboarding
-	Membership           *Membership
-	Service              *Service
-	Plan                 *Plan
-	CommunityPlan        *CommunityPlan
-	CommunityService     *CommunityService
-	ServiceLocal         *ServiceLocal
-	ServiceProfessional  *ServiceProfessional
-	Session              *Session
-	Reservation          *Reservation
-	AuditLog             *AuditLog
-	MembershipSuspension *MembershipSuspension
+	Logger                  logging.Logger
+	Community               *Community
+	Professional            *Professional
+	Local                   *Local
+	User                    *User
+	Onboarding              *Onboarding
+	Membership              *Membership
+	Service                 *Service
+	Plan                    *Plan
+	CommunityPlan           *CommunityPlan
+	CommunityService        *CommunityService
+	ServiceLocal            *ServiceLocal
+	ServiceProfessional     *ServiceProfessional
+	Session                 *Session
+	Reservation             *Reservation
+	AuditLog                *AuditLog
+	MembershipSuspension    *MembershipSuspension
+	RefreshToken            *RefreshToken
+	PasswordReset           *PasswordReset
+	EmailVerification       *EmailVerification
+	RateLimitCounter        *RateLimitCounter
+	TwoFactorRecoveryCode   *TwoFactorRecoveryCode
+	Role                    *Role
+	UserRoleAssignment      *UserRoleAssignment
+	ServiceAccount          *ServiceAccount
+	UserIdentity            *UserIdentity
+	LoginSession            *LoginSession
+	CancellationPolicy      *CancellationPolicy
+	StandingBooking         *StandingBooking
+	IdempotencyKey          *IdempotencyKey
+	ReservationStateHistory *ReservationStateHistory
+	SessionSeries           *SessionSeries
+	ProfessionalSchedule    *ProfessionalSchedule
 }
 
 // Create bll adapter collection
@@ -34,23 +51,47 @@ func NewAdapterCollection(
 	envSettings *schemas.EnvSettings,
 ) (*AdapterCollection, *gorm.DB) {
 	daoAstroCatPsql, astroCatPsqlDB := daoPostgresql.NewAstroCatPsqlCollection(logger, envSettings)
+	piiCipher, err := utils.NewPiiCipher(
+		envSettings.PiiEncryptionKeys,
+		envSettings.PiiEncryptionKeyId,
+		envSettings.PiiBlindIndexKey,
+	)
+	if err != nil {
+		logger.Panicln("Invalid PII encryption settings", err)
+	}
 
 	return &AdapterCollection{
-		Community:            NewCommunityAdapter(logger, daoAstroCatPsql),
-		Professional:         NewProfessionalAdapter(logger, daoAstroCatPsql),
-		Local:                NewLocalAdapter(logger, daoAstroCatPsql),
-		User:                 NewUserAdapter(logger, daoAstroCatPsql),
-		Onboarding:           NewOnboardingAdapter(logger, daoAstroCatPsql),
-		Membership:           NewMembershipAdapter(logger, daoAstroCatPsql),
-		Service:              NewServiceAdapter(logger, daoAstroCatPsql),
-		Plan:                 NewPlanAdapter(logger, daoAstroCatPsql),
-		CommunityPlan:        NewCommunityPlanAdapter(logger, daoAstroCatPsql),
-		CommunityService:     NewCommunityServiceAdapter(logger, daoAstroCatPsql),
-		ServiceLocal:         NewServiceLocalAdapter(logger, daoAstroCatPsql),
-		ServiceProfessional:  NewServiceProfessionalAdapter(logger, daoAstroCatPsql),
-		Session:              NewSessionAdapter(logger, daoAstroCatPsql),
-		Reservation:          NewReservationAdapter(logger, daoAstroCatPsql),
-		AuditLog:             NewAuditLogAdapter(logger, daoAstroCatPsql),
-		MembershipSuspension: NewMembershipSuspensionAdapter(logger, daoAstroCatPsql),
+		Community:               NewCommunityAdapter(logger, daoAstroCatPsql),
+		Professional:            NewProfessionalAdapter(logger, daoAstroCatPsql),
+		Local:                   NewLocalAdapter(logger, daoAstroCatPsql),
+		User:                    NewUserAdapter(logger, daoAstroCatPsql, piiCipher),
+		Onboarding:              NewOnboardingAdapter(logger, daoAstroCatPsql, piiCipher),
+		Membership:              NewMembershipAdapter(logger, daoAstroCatPsql),
+		Service:                 NewServiceAdapter(logger, daoAstroCatPsql),
+		Plan:                    NewPlanAdapter(logger, daoAstroCatPsql),
+		CommunityPlan:           NewCommunityPlanAdapter(logger, daoAstroCatPsql),
+		CommunityService:        NewCommunityServiceAdapter(logger, daoAstroCatPsql),
+		ServiceLocal:            NewServiceLocalAdapter(logger, daoAstroCatPsql),
+		ServiceProfessional:     NewServiceProfessionalAdapter(logger, daoAstroCatPsql),
+		Session:                 NewSessionAdapter(logger, daoAstroCatPsql),
+		Reservation:             NewReservationAdapter(logger, daoAstroCatPsql),
+		AuditLog:                NewAuditLogAdapter(logger, daoAstroCatPsql),
+		MembershipSuspension:    NewMembershipSuspensionAdapter(logger, daoAstroCatPsql),
+		RefreshToken:            NewRefreshTokenAdapter(logger, daoAstroCatPsql),
+		PasswordReset:           NewPasswordResetAdapter(logger, daoAstroCatPsql),
+		EmailVerification:       NewEmailVerificationAdapter(logger, daoAstroCatPsql),
+		RateLimitCounter:        NewRateLimitCounterAdapter(logger, daoAstroCatPsql),
+		TwoFactorRecoveryCode:   NewTwoFactorRecoveryCodeAdapter(logger, daoAstroCatPsql),
+		Role:                    NewRoleAdapter(logger, daoAstroCatPsql),
+		UserRoleAssignment:      NewUserRoleAssignmentAdapter(logger, daoAstroCatPsql),
+		ServiceAccount:          NewServiceAccountAdapter(logger, daoAstroCatPsql),
+		UserIdentity:            NewUserIdentityAdapter(logger, daoAstroCatPsql),
+		LoginSession:            NewLoginSessionAdapter(logger, daoAstroCatPsql),
+		CancellationPolicy:      NewCancellationPolicyAdapter(logger, daoAstroCatPsql),
+		StandingBooking:         NewStandingBookingAdapter(logger, daoAstroCatPsql),
+		IdempotencyKey:          NewIdempotencyKeyAdapter(logger, daoAstroCatPsql),
+		ReservationStateHistory: NewReservationStateHistoryAdapter(logger, daoAstroCatPsql),
+		SessionSeries:           NewSessionSeriesAdapter(logger, daoAstroCatPsql),
+		ProfessionalSchedule:    NewProfessionalScheduleAdapter(logger, daoAstroCatPsql),
 	}, astroCatPsqlDB
 }
@@ -0,0 +1,107 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type EmailVerification struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewEmailVerificationAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *EmailVerification {
+	return &EmailVerification{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (e *EmailVerification) CreatePostgresqlEmailVerification(
+	userId uuid.UUID,
+	codeHash string,
+	expiresAt time.Time,
+	updatedBy string,
+) (*schemas.EmailVerification, *errors.Error) {
+	emailVerificationModel := &model.EmailVerification{
+		Id:        uuid.New(),
+		UserId:    userId,
+		CodeHash:  codeHash,
+		ExpiresAt: expiresAt,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := e.DaoPostgresql.EmailVerification.CreateEmailVerification(emailVerificationModel); err != nil {
+		return nil, &errors.BadRequestError.EmailVerificationNotCreated
+	}
+
+	return e.convertModelToSchema(emailVerificationModel), nil
+}
+
+func (e *EmailVerification) GetActivePostgresqlEmailVerificationByUserId(
+	userId uuid.UUID,
+) (*schemas.EmailVerification, *errors.Error) {
+	emailVerificationModel, err := e.DaoPostgresql.EmailVerification.GetActiveEmailVerificationByUserId(
+		userId,
+	)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.EmailVerificationNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return e.convertModelToSchema(emailVerificationModel), nil
+}
+
+func (e *EmailVerification) IncrementPostgresqlEmailVerificationAttempts(
+	emailVerificationId uuid.UUID,
+) (int, *errors.Error) {
+	attempts, err := e.DaoPostgresql.EmailVerification.IncrementEmailVerificationAttempts(
+		emailVerificationId,
+	)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, &errors.ObjectNotFoundError.EmailVerificationNotFound
+		}
+		return 0, &errors.BadRequestError.EmailVerificationNotUpdated
+	}
+
+	return attempts, nil
+}
+
+func (e *EmailVerification) ConsumePostgresqlUserEmailVerifications(
+	userId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := e.DaoPostgresql.EmailVerification.ConsumeUserEmailVerifications(userId, updatedBy); err != nil {
+		return &errors.BadRequestError.EmailVerificationNotUpdated
+	}
+
+	return nil
+}
+
+func (e *EmailVerification) convertModelToSchema(
+	emailVerificationModel *model.EmailVerification,
+) *schemas.EmailVerification {
+	return &schemas.EmailVerification{
+		Id:         emailVerificationModel.Id,
+		UserId:     emailVerificationModel.UserId,
+		CodeHash:   emailVerificationModel.CodeHash,
+		ExpiresAt:  emailVerificationModel.ExpiresAt,
+		Attempts:   emailVerificationModel.Attempts,
+		ConsumedAt: emailVerificationModel.ConsumedAt,
+	}
+}
@@ -0,0 +1,97 @@
+package adapter
+
+import (
+	"time"
+
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type IdempotencyKey struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewIdempotencyKeyAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *IdempotencyKey {
+	return &IdempotencyKey{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Claims a key for a request. Returns nil when it was claimed, or the stored key of the first
+// request otherwise.
+func (i *IdempotencyKey) ClaimPostgresqlIdempotencyKey(
+	scope string,
+	key string,
+	requestHash string,
+	ttl time.Duration,
+	lockTimeout time.Duration,
+) (*schemas.IdempotencyKey, *errors.Error) {
+	claimed, keyModel, err := i.DaoPostgresql.IdempotencyKey.ClaimIdempotencyKey(
+		scope,
+		key,
+		requestHash,
+		ttl,
+		lockTimeout,
+	)
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	return &schemas.IdempotencyKey{
+		Scope:        keyModel.Scope,
+		Key:          keyModel.Key,
+		RequestHash:  keyModel.RequestHash,
+		StatusCode:   keyModel.StatusCode,
+		ContentType:  keyModel.ContentType,
+		ResponseBody: keyModel.ResponseBody,
+		ExpiresAt:    keyModel.ExpiresAt,
+	}, nil
+}
+
+func (i *IdempotencyKey) CompletePostgresqlIdempotencyKey(
+	scope string,
+	key string,
+	statusCode int,
+	contentType string,
+	responseBody []byte,
+) *errors.Error {
+	err := i.DaoPostgresql.IdempotencyKey.CompleteIdempotencyKey(
+		scope,
+		key,
+		statusCode,
+		contentType,
+		responseBody,
+	)
+	if err != nil {
+		return &errors.InternalServerError.DatabaseError
+	}
+
+	return nil
+}
+
+func (i *IdempotencyKey) ReleasePostgresqlIdempotencyKey(scope string, key string) *errors.Error {
+	if err := i.DaoPostgresql.IdempotencyKey.ReleaseIdempotencyKey(scope, key); err != nil {
+		return &errors.InternalServerError.DatabaseError
+	}
+
+	return nil
+}
+
+func (i *IdempotencyKey) DeleteExpiredPostgresqlIdempotencyKeys() (int64, *errors.Error) {
+	deleted, err := i.DaoPostgresql.IdempotencyKey.DeleteExpiredIdempotencyKeys()
+	if err != nil {
+		return 0, &errors.InternalServerError.DatabaseError
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,162 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type LoginSession struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewLoginSessionAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *LoginSession {
+	return &LoginSession{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (l *LoginSession) CreatePostgresqlLoginSession(
+	sessionId uuid.UUID,
+	userId uuid.UUID,
+	expiresAt time.Time,
+	updatedBy string,
+) (*schemas.LoginSession, *errors.Error) {
+	sessionModel := &model.LoginSession{
+		Id:         sessionId,
+		UserId:     userId,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  expiresAt,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := l.DaoPostgresql.LoginSession.CreateLoginSession(sessionModel); err != nil {
+		return nil, &errors.BadRequestError.LoginSessionNotCreated
+	}
+
+	return l.convertModelToSchema(sessionModel), nil
+}
+
+func (l *LoginSession) GetPostgresqlLoginSession(
+	sessionId uuid.UUID,
+) (*schemas.LoginSession, *errors.Error) {
+	sessionModel, err := l.DaoPostgresql.LoginSession.GetLoginSession(sessionId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.LoginSessionNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return l.convertModelToSchema(sessionModel), nil
+}
+
+func (l *LoginSession) FetchPostgresqlActiveLoginSessionsByUserId(
+	userId uuid.UUID,
+) ([]*schemas.LoginSession, *errors.Error) {
+	sessionModels, err := l.DaoPostgresql.LoginSession.FetchActiveLoginSessionsByUserId(userId)
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	sessions := make([]*schemas.LoginSession, len(sessionModels))
+	for i, sessionModel := range sessionModels {
+		sessions[i] = l.convertModelToSchema(sessionModel)
+	}
+
+	return sessions, nil
+}
+
+func (l *LoginSession) UpdatePostgresqlLoginSessionDevice(
+	sessionId uuid.UUID,
+	device string,
+	userAgent string,
+	ipAddress string,
+) *errors.Error {
+	if err := l.DaoPostgresql.LoginSession.UpdateLoginSessionDevice(
+		sessionId,
+		device,
+		userAgent,
+		ipAddress,
+	); err != nil {
+		return &errors.InternalServerError.DatabaseError
+	}
+
+	return nil
+}
+
+func (l *LoginSession) TouchPostgresqlLoginSession(
+	sessionId uuid.UUID,
+	ipAddress string,
+	interval time.Duration,
+) *errors.Error {
+	if err := l.DaoPostgresql.LoginSession.TouchLoginSession(sessionId, ipAddress, interval); err != nil {
+		return &errors.InternalServerError.DatabaseError
+	}
+
+	return nil
+}
+
+func (l *LoginSession) ExtendPostgresqlLoginSession(
+	sessionId uuid.UUID,
+	expiresAt time.Time,
+) *errors.Error {
+	if err := l.DaoPostgresql.LoginSession.ExtendLoginSession(sessionId, expiresAt); err != nil {
+		return &errors.InternalServerError.DatabaseError
+	}
+
+	return nil
+}
+
+func (l *LoginSession) RevokePostgresqlLoginSession(
+	userId uuid.UUID,
+	sessionId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := l.DaoPostgresql.LoginSession.RevokeLoginSession(userId, sessionId, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.LoginSessionNotFound
+		}
+		return &errors.BadRequestError.LoginSessionNotRevoked
+	}
+
+	return nil
+}
+
+func (l *LoginSession) RevokePostgresqlUserLoginSessions(
+	userId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := l.DaoPostgresql.LoginSession.RevokeUserLoginSessions(userId, updatedBy); err != nil {
+		return &errors.BadRequestError.LoginSessionNotRevoked
+	}
+
+	return nil
+}
+
+func (l *LoginSession) convertModelToSchema(sessionModel *model.LoginSession) *schemas.LoginSession {
+	return &schemas.LoginSession{
+		Id:         sessionModel.Id,
+		UserId:     sessionModel.UserId,
+		Device:     sessionModel.Device,
+		UserAgent:  sessionModel.UserAgent,
+		IpAddress:  sessionModel.IpAddress,
+		LastSeenAt: sessionModel.LastSeenAt,
+		ExpiresAt:  sessionModel.ExpiresAt,
+		RevokedAt:  sessionModel.RevokedAt,
+		CreatedAt:  sessionModel.CreatedAt,
+	}
+}
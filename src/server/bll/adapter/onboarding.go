@@ -9,20 +9,29 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
 )
 
+// Layout of the birth dates, which are stored as encrypted text
+const onboardingBirthDateLayout = "2006-01-02"
+
+// The document number, phone number, birth date and address of onboardings are encrypted here, so
+// the rest of the code only sees plaintext
 type Onboarding struct {
 	logger        logging.Logger
 	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+	piiCipher     *utils.PiiCipher
 }
 
 func NewOnboardingAdapter(
 	logger logging.Logger,
 	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+	piiCipher *utils.PiiCipher,
 ) *Onboarding {
 	return &Onboarding{
 		logger:        logger,
 		DaoPostgresql: daoPostgresql,
+		piiCipher:     piiCipher,
 	}
 }
 
@@ -34,20 +43,7 @@ func (o *Onboarding) GetPostgresqlOnboarding(
 		return nil, &errors.ObjectNotFoundError.OnboardingNotFound
 	}
 
-	return &schemas.Onboarding{
-		Id:             onboardingModel.Id,
-		DocumentType:   schemas.DocumentType(onboardingModel.DocumentType),
-		DocumentNumber: onboardingModel.DocumentNumber,
-		PhoneNumber:    onboardingModel.PhoneNumber,
-		BirthDate:      onboardingModel.BirthDate,
-		Gender:         (*schemas.Gender)(onboardingModel.Gender),
-		PostalCode:     onboardingModel.PostalCode,
-		District:       onboardingModel.District,
-		Province:       onboardingModel.Province,
-		Region:         onboardingModel.Region,
-		Address:        onboardingModel.Address,
-		UserId:         onboardingModel.UserId,
-	}, nil
+	return convertOnboardingModelToSchema(o.logger, o.piiCipher, onboardingModel)
 }
 
 func (o *Onboarding) GetPostgresqlOnboardingByUserId(
@@ -58,20 +54,7 @@ func (o *Onboarding) GetPostgresqlOnboardingByUserId(
 		return nil, &errors.ObjectNotFoundError.OnboardingNotFound
 	}
 
-	return &schemas.Onboarding{
-		Id:             onboardingModel.Id,
-		DocumentType:   schemas.DocumentType(onboardingModel.DocumentType),
-		DocumentNumber: onboardingModel.DocumentNumber,
-		PhoneNumber:    onboardingModel.PhoneNumber,
-		BirthDate:      onboardingModel.BirthDate,
-		Gender:         (*schemas.Gender)(onboardingModel.Gender),
-		PostalCode:     onboardingModel.PostalCode,
-		District:       onboardingModel.District,
-		Province:       onboardingModel.Province,
-		Region:         onboardingModel.Region,
-		Address:        onboardingModel.Address,
-		UserId:         onboardingModel.UserId,
-	}, nil
+	return convertOnboardingModelToSchema(o.logger, o.piiCipher, onboardingModel)
 }
 
 func (o *Onboarding) FetchPostgresqlOnboardings() ([]*schemas.Onboarding, *errors.Error) {
@@ -82,20 +65,11 @@ func (o *Onboarding) FetchPostgresqlOnboardings() ([]*schemas.Onboarding, *error
 
 	onboardings := make([]*schemas.Onboarding, len(onboardingsModel))
 	for i, onboardingModel := range onboardingsModel {
-		onboardings[i] = &schemas.Onboarding{
-			Id:             onboardingModel.Id,
-			DocumentType:   schemas.DocumentType(onboardingModel.DocumentType),
-			DocumentNumber: onboardingModel.DocumentNumber,
-			PhoneNumber:    onboardingModel.PhoneNumber,
-			BirthDate:      onboardingModel.BirthDate,
-			Gender:         (*schemas.Gender)(onboardingModel.Gender),
-			PostalCode:     onboardingModel.PostalCode,
-			District:       onboardingModel.District,
-			Province:       onboardingModel.Province,
-			Region:         onboardingModel.Region,
-			Address:        onboardingModel.Address,
-			UserId:         onboardingModel.UserId,
+		onboarding, convertErr := convertOnboardingModelToSchema(o.logger, o.piiCipher, onboardingModel)
+		if convertErr != nil {
+			return nil, convertErr
 		}
+		onboardings[i] = onboarding
 	}
 
 	return onboardings, nil
@@ -132,13 +106,14 @@ func (o *Onboarding) CreatePostgresqlOnboarding(
 	}
 
 	// Parsear fecha de nacimiento si se proporciona
-	var parsedBirthDate *time.Time
+	var formattedBirthDate *string
 	if birthDate != nil && *birthDate != "" {
-		parsed, parseErr := time.Parse("2006-01-02", *birthDate)
+		parsed, parseErr := time.Parse(onboardingBirthDateLayout, *birthDate)
 		if parseErr != nil {
 			return nil, &errors.BadRequestError.OnboardingNotCreated
 		}
-		parsedBirthDate = &parsed
+		formatted := parsed.Format(onboardingBirthDateLayout)
+		formattedBirthDate = &formatted
 	}
 
 	onboardingModel := &model.Onboarding{
@@ -146,7 +121,7 @@ func (o *Onboarding) CreatePostgresqlOnboarding(
 		DocumentType:   model.DocumentType(documentType),
 		DocumentNumber: documentNumber,
 		PhoneNumber:    phoneNumber,
-		BirthDate:      parsedBirthDate,
+		BirthDate:      formattedBirthDate,
 		Gender:         (*model.Gender)(gender),
 		PostalCode:     postalCode,
 		District:       district,
@@ -159,24 +134,16 @@ func (o *Onboarding) CreatePostgresqlOnboarding(
 		},
 	}
 
+	if err := encryptOnboardingFields(o.piiCipher, onboardingModel); err != nil {
+		o.logger.Error("Failed to encrypt onboarding: ", err)
+		return nil, &errors.InternalServerError.PiiEncryptionFailed
+	}
+
 	if err := o.DaoPostgresql.Onboarding.CreateOnboarding(onboardingModel); err != nil {
 		return nil, &errors.BadRequestError.OnboardingNotCreated
 	}
 
-	return &schemas.Onboarding{
-		Id:             onboardingModel.Id,
-		DocumentType:   schemas.DocumentType(onboardingModel.DocumentType),
-		DocumentNumber: onboardingModel.DocumentNumber,
-		PhoneNumber:    onboardingModel.PhoneNumber,
-		BirthDate:      onboardingModel.BirthDate,
-		Gender:         (*schemas.Gender)(onboardingModel.Gender),
-		PostalCode:     onboardingModel.PostalCode,
-		District:       onboardingModel.District,
-		Province:       onboardingModel.Province,
-		Region:         onboardingModel.Region,
-		Address:        onboardingModel.Address,
-		UserId:         onboardingModel.UserId,
-	}, nil
+	return convertOnboardingModelToSchema(o.logger, o.piiCipher, onboardingModel)
 }
 
 func (o *Onboarding) UpdatePostgresqlOnboarding(
@@ -198,21 +165,41 @@ func (o *Onboarding) UpdatePostgresqlOnboarding(
 	}
 
 	// Parsear fecha de nacimiento si se proporciona
-	var parsedBirthDate *time.Time
+	var formattedBirthDate *string
 	if birthDate != nil && *birthDate != "" {
-		parsed, parseErr := time.Parse("2006-01-02", *birthDate)
+		parsed, parseErr := time.Parse(onboardingBirthDateLayout, *birthDate)
 		if parseErr != nil {
 			return nil, &errors.BadRequestError.OnboardingNotUpdated
 		}
-		parsedBirthDate = &parsed
+		formatted := parsed.Format(onboardingBirthDateLayout)
+		formattedBirthDate = &formatted
+	}
+
+	// Only the fields that change are encrypted, the index follows the document number
+	var documentNumberIndex *string
+	if documentNumber != nil {
+		index := o.piiCipher.BlindIndex(*documentNumber)
+		documentNumberIndex = &index
+	}
+	for _, field := range []**string{&documentNumber, &phoneNumber, &formattedBirthDate, &address} {
+		if *field == nil {
+			continue
+		}
+		encrypted, encryptErr := o.piiCipher.Encrypt(**field)
+		if encryptErr != nil {
+			o.logger.Error("Failed to encrypt onboarding: ", encryptErr)
+			return nil, &errors.InternalServerError.PiiEncryptionFailed
+		}
+		*field = &encrypted
 	}
 
 	onboardingModel, err := o.DaoPostgresql.Onboarding.UpdateOnboarding(
 		onboardingId,
 		(*model.DocumentType)(documentType),
 		documentNumber,
+		documentNumberIndex,
 		phoneNumber,
-		parsedBirthDate,
+		formattedBirthDate,
 		(*model.Gender)(gender),
 		postalCode,
 		district,
@@ -225,20 +212,7 @@ func (o *Onboarding) UpdatePostgresqlOnboarding(
 		return nil, &errors.BadRequestError.OnboardingNotUpdated
 	}
 
-	return &schemas.Onboarding{
-		Id:             onboardingModel.Id,
-		DocumentType:   schemas.DocumentType(onboardingModel.DocumentType),
-		DocumentNumber: onboardingModel.DocumentNumber,
-		PhoneNumber:    onboardingModel.PhoneNumber,
-		BirthDate:      onboardingModel.BirthDate,
-		Gender:         (*schemas.Gender)(onboardingModel.Gender),
-		PostalCode:     onboardingModel.PostalCode,
-		District:       onboardingModel.District,
-		Province:       onboardingModel.Province,
-		Region:         onboardingModel.Region,
-		Address:        onboardingModel.Address,
-		UserId:         onboardingModel.UserId,
-	}, nil
+	return convertOnboardingModelToSchema(o.logger, o.piiCipher, onboardingModel)
 }
 
 func (o *Onboarding) DeletePostgresqlOnboarding(onboardingId uuid.UUID) *errors.Error {
@@ -254,3 +228,150 @@ func (o *Onboarding) DeletePostgresqlOnboardingByUserId(userId uuid.UUID) *error
 	}
 	return nil
 }
+
+// Finds the onboarding with a document through the blind index of its number
+func (o *Onboarding) GetPostgresqlOnboardingByDocument(
+	documentType schemas.DocumentType,
+	documentNumber string,
+) (*schemas.Onboarding, *errors.Error) {
+	onboardingModel, err := o.DaoPostgresql.Onboarding.GetOnboardingByDocumentNumberIndex(
+		model.DocumentType(documentType),
+		o.piiCipher.BlindIndex(documentNumber),
+	)
+	if err != nil {
+		return nil, &errors.ObjectNotFoundError.OnboardingNotFound
+	}
+
+	return convertOnboardingModelToSchema(o.logger, o.piiCipher, onboardingModel)
+}
+
+// Encrypts the onboardings that are still in plaintext or use an old key, in batches, and returns
+// how many were rewritten. Soft deleted rows are included. Safe to run again after an interruption.
+func (o *Onboarding) EncryptPostgresqlOnboardings(batchSize int) (int, *errors.Error) {
+	encryptedCount := 0
+	afterId := uuid.Nil
+	for {
+		onboardingModels, err := o.DaoPostgresql.Onboarding.FetchOnboardingsBatch(afterId, batchSize)
+		if err != nil {
+			return encryptedCount, &errors.InternalServerError.DatabaseError
+		}
+		if len(onboardingModels) == 0 {
+			return encryptedCount, nil
+		}
+
+		for _, onboardingModel := range onboardingModels {
+			afterId = onboardingModel.Id
+
+			stored := *onboardingModel
+			if err := decryptOnboardingFields(o.piiCipher, onboardingModel); err != nil {
+				o.logger.Errorf("Failed to decrypt onboarding %s: %v", onboardingModel.Id, err)
+				return encryptedCount, &errors.InternalServerError.PiiDecryptionFailed
+			}
+			if isOnboardingEncryptionCurrent(o.piiCipher, &stored, onboardingModel.DocumentNumber) {
+				continue
+			}
+
+			if err := encryptOnboardingFields(o.piiCipher, onboardingModel); err != nil {
+				o.logger.Errorf("Failed to encrypt onboarding %s: %v", onboardingModel.Id, err)
+				return encryptedCount, &errors.InternalServerError.PiiEncryptionFailed
+			}
+			if err := o.DaoPostgresql.Onboarding.UpdateOnboardingEncryptedFields(onboardingModel); err != nil {
+				return encryptedCount, &errors.InternalServerError.DatabaseError
+			}
+			encryptedCount++
+		}
+	}
+}
+
+// Builds the schema of an onboarding, decrypting its personal data. A nil model gives a nil schema.
+func convertOnboardingModelToSchema(
+	logger logging.Logger,
+	piiCipher *utils.PiiCipher,
+	onboardingModel *model.Onboarding,
+) (*schemas.Onboarding, *errors.Error) {
+	if onboardingModel == nil {
+		return nil, nil
+	}
+
+	decrypted := *onboardingModel
+	if err := decryptOnboardingFields(piiCipher, &decrypted); err != nil {
+		logger.Errorf("Failed to decrypt onboarding %s: %v", onboardingModel.Id, err)
+		return nil, &errors.InternalServerError.PiiDecryptionFailed
+	}
+
+	var birthDate *time.Time
+	if decrypted.BirthDate != nil && *decrypted.BirthDate != "" {
+		parsed, err := time.Parse(onboardingBirthDateLayout, *decrypted.BirthDate)
+		if err != nil {
+			logger.Errorf("Invalid birth date in onboarding %s: %v", onboardingModel.Id, err)
+			return nil, &errors.InternalServerError.PiiDecryptionFailed
+		}
+		birthDate = &parsed
+	}
+
+	return &schemas.Onboarding{
+		Id:             decrypted.Id,
+		DocumentType:   schemas.DocumentType(decrypted.DocumentType),
+		DocumentNumber: decrypted.DocumentNumber,
+		PhoneNumber:    decrypted.PhoneNumber,
+		BirthDate:      birthDate,
+		Gender:         (*schemas.Gender)(decrypted.Gender),
+		PostalCode:     decrypted.PostalCode,
+		District:       decrypted.District,
+		Province:       decrypted.Province,
+		Region:         decrypted.Region,
+		Address:        decrypted.Address,
+		UserId:         decrypted.UserId,
+	}, nil
+}
+
+// Encrypts the personal data of an onboarding model in place and refreshes its blind index
+func encryptOnboardingFields(piiCipher *utils.PiiCipher, onboardingModel *model.Onboarding) error {
+	onboardingModel.DocumentNumberIndex = piiCipher.BlindIndex(onboardingModel.DocumentNumber)
+	return transformOnboardingFields(onboardingModel, piiCipher.Encrypt)
+}
+
+// Decrypts the personal data of an onboarding model in place
+func decryptOnboardingFields(piiCipher *utils.PiiCipher, onboardingModel *model.Onboarding) error {
+	return transformOnboardingFields(onboardingModel, piiCipher.Decrypt)
+}
+
+func transformOnboardingFields(onboardingModel *model.Onboarding, transform func(string) (string, error)) error {
+	fields := []*string{
+		&onboardingModel.DocumentNumber,
+		&onboardingModel.PhoneNumber,
+		&onboardingModel.Address,
+	}
+	if onboardingModel.BirthDate != nil {
+		birthDate := *onboardingModel.BirthDate
+		onboardingModel.BirthDate = &birthDate
+		fields = append(fields, onboardingModel.BirthDate)
+	}
+
+	for _, field := range fields {
+		transformed, err := transform(*field)
+		if err != nil {
+			return err
+		}
+		*field = transformed
+	}
+	return nil
+}
+
+// Whether a stored onboarding is already encrypted with the active key and indexed with the
+// current blind index key
+func isOnboardingEncryptionCurrent(
+	piiCipher *utils.PiiCipher,
+	stored *model.Onboarding,
+	documentNumber string,
+) bool {
+	if stored.DocumentNumberIndex != piiCipher.BlindIndex(documentNumber) {
+		return false
+	}
+	if stored.BirthDate != nil && !piiCipher.IsCurrent(*stored.BirthDate) {
+		return false
+	}
+	return piiCipher.IsCurrent(stored.DocumentNumber) &&
+		piiCipher.IsCurrent(stored.PhoneNumber) &&
+		piiCipher.IsCurrent(stored.Address)
+}
@@ -0,0 +1,117 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type PasswordReset struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewPasswordResetAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *PasswordReset {
+	return &PasswordReset{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (p *PasswordReset) CreatePostgresqlPasswordReset(
+	userId uuid.UUID,
+	pinHash string,
+	channel schemas.PasswordResetChannel,
+	expiresAt time.Time,
+	updatedBy string,
+) (*schemas.PasswordReset, *errors.Error) {
+	passwordResetModel := &model.PasswordReset{
+		Id:        uuid.New(),
+		UserId:    userId,
+		PinHash:   pinHash,
+		Channel:   model.PasswordResetChannel(channel),
+		ExpiresAt: expiresAt,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := p.DaoPostgresql.PasswordReset.CreatePasswordReset(passwordResetModel); err != nil {
+		return nil, &errors.BadRequestError.PasswordResetNotCreated
+	}
+
+	return p.convertModelToSchema(passwordResetModel), nil
+}
+
+func (p *PasswordReset) GetActivePostgresqlPasswordResetByUserId(
+	userId uuid.UUID,
+) (*schemas.PasswordReset, *errors.Error) {
+	passwordResetModel, err := p.DaoPostgresql.PasswordReset.GetActivePasswordResetByUserId(userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.PasswordResetNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return p.convertModelToSchema(passwordResetModel), nil
+}
+
+func (p *PasswordReset) IncrementPostgresqlPasswordResetAttempts(
+	passwordResetId uuid.UUID,
+) (int, *errors.Error) {
+	attempts, err := p.DaoPostgresql.PasswordReset.IncrementPasswordResetAttempts(passwordResetId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, &errors.ObjectNotFoundError.PasswordResetNotFound
+		}
+		return 0, &errors.BadRequestError.PasswordResetNotUpdated
+	}
+
+	return attempts, nil
+}
+
+func (p *PasswordReset) ConsumePostgresqlPasswordReset(
+	passwordResetId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := p.DaoPostgresql.PasswordReset.ConsumePasswordReset(passwordResetId, updatedBy); err != nil {
+		return &errors.BadRequestError.PasswordResetNotUpdated
+	}
+
+	return nil
+}
+
+func (p *PasswordReset) ConsumePostgresqlUserPasswordResets(
+	userId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := p.DaoPostgresql.PasswordReset.ConsumeUserPasswordResets(userId, updatedBy); err != nil {
+		return &errors.BadRequestError.PasswordResetNotUpdated
+	}
+
+	return nil
+}
+
+func (p *PasswordReset) convertModelToSchema(
+	passwordResetModel *model.PasswordReset,
+) *schemas.PasswordReset {
+	return &schemas.PasswordReset{
+		Id:         passwordResetModel.Id,
+		UserId:     passwordResetModel.UserId,
+		PinHash:    passwordResetModel.PinHash,
+		Channel:    schemas.PasswordResetChannel(passwordResetModel.Channel),
+		ExpiresAt:  passwordResetModel.ExpiresAt,
+		Attempts:   passwordResetModel.Attempts,
+		ConsumedAt: passwordResetModel.ConsumedAt,
+	}
+}
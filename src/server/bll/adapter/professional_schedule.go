@@ -0,0 +1,171 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPsql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type ProfessionalSchedule struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPsql.AstroCatPsqlCollection
+}
+
+// Creates ProfessionalSchedule adapter
+func NewProfessionalScheduleAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPsql.AstroCatPsqlCollection,
+) *ProfessionalSchedule {
+	return &ProfessionalSchedule{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Fetches the working hours of a professional from postgresql DB and adapts them to their schema.
+func (ps *ProfessionalSchedule) FetchPostgresqlWorkingHours(
+	professionalId uuid.UUID,
+) ([]*schemas.WorkingHours, *errors.Error) {
+	workingHoursModels, err := ps.DaoPostgresql.ProfessionalSchedule.FetchWorkingHours(professionalId)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	workingHours := make([]*schemas.WorkingHours, len(workingHoursModels))
+	for i, workingHoursModel := range workingHoursModels {
+		workingHours[i] = &schemas.WorkingHours{
+			Weekday:   workingHoursModel.Weekday,
+			StartTime: workingHoursModel.StartTime,
+			EndTime:   workingHoursModel.EndTime,
+		}
+	}
+
+	return workingHours, nil
+}
+
+// Replaces the working hours of a professional in postgresql DB.
+func (ps *ProfessionalSchedule) ReplacePostgresqlWorkingHours(
+	professionalId uuid.UUID,
+	workingHours []*schemas.WorkingHours,
+	updatedBy string,
+) *errors.Error {
+	if updatedBy == "" {
+		return &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	workingHoursModels := make([]*model.ProfessionalWorkingHours, len(workingHours))
+	for i, hours := range workingHours {
+		workingHoursModels[i] = &model.ProfessionalWorkingHours{
+			Id:             uuid.New(),
+			Weekday:        hours.Weekday,
+			StartTime:      hours.StartTime,
+			EndTime:        hours.EndTime,
+			ProfessionalId: professionalId,
+			AuditFields: model.AuditFields{
+				UpdatedBy: updatedBy,
+			},
+		}
+	}
+
+	if err := ps.DaoPostgresql.ProfessionalSchedule.ReplaceWorkingHours(
+		professionalId,
+		workingHoursModels,
+	); err != nil {
+		return &errors.BadRequestError.WorkingHoursNotUpdated
+	}
+
+	return nil
+}
+
+// Gets a time off from postgresql DB given its id and adapts it to its schema.
+func (ps *ProfessionalSchedule) GetPostgresqlProfessionalTimeOff(
+	timeOffId uuid.UUID,
+) (*schemas.ProfessionalTimeOff, *errors.Error) {
+	timeOffModel, err := ps.DaoPostgresql.ProfessionalSchedule.GetTimeOff(timeOffId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.ProfessionalTimeOffNotFound
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertProfessionalTimeOffModelToSchema(timeOffModel), nil
+}
+
+// Fetches the time off of a professional that overlaps a time range, if provided, from
+// postgresql DB and adapts it to its schema.
+func (ps *ProfessionalSchedule) FetchPostgresqlProfessionalTimeOff(
+	professionalId uuid.UUID,
+	from *time.Time,
+	to *time.Time,
+) ([]*schemas.ProfessionalTimeOff, *errors.Error) {
+	timeOffModels, err := ps.DaoPostgresql.ProfessionalSchedule.FetchTimeOff(professionalId, from, to)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	timeOff := make([]*schemas.ProfessionalTimeOff, len(timeOffModels))
+	for i, timeOffModel := range timeOffModels {
+		timeOff[i] = convertProfessionalTimeOffModelToSchema(timeOffModel)
+	}
+
+	return timeOff, nil
+}
+
+// Creates a time off of a professional in postgresql DB and adapts it to its schema.
+func (ps *ProfessionalSchedule) CreatePostgresqlProfessionalTimeOff(
+	professionalId uuid.UUID,
+	request schemas.CreateProfessionalTimeOffRequest,
+	updatedBy string,
+) (*schemas.ProfessionalTimeOff, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	timeOffModel := &model.ProfessionalTimeOff{
+		Id:             uuid.New(),
+		StartTime:      request.StartTime,
+		EndTime:        request.EndTime,
+		Reason:         request.Reason,
+		ProfessionalId: professionalId,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := ps.DaoPostgresql.ProfessionalSchedule.CreateTimeOff(timeOffModel); err != nil {
+		return nil, &errors.BadRequestError.ProfessionalTimeOffNotCreated
+	}
+
+	return convertProfessionalTimeOffModelToSchema(timeOffModel), nil
+}
+
+// Deletes a time off from postgresql DB.
+func (ps *ProfessionalSchedule) DeletePostgresqlProfessionalTimeOff(timeOffId uuid.UUID) *errors.Error {
+	if err := ps.DaoPostgresql.ProfessionalSchedule.DeleteTimeOff(timeOffId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.ProfessionalTimeOffNotFound
+		}
+		return &errors.BadRequestError.ProfessionalTimeOffNotDeleted
+	}
+
+	return nil
+}
+
+func convertProfessionalTimeOffModelToSchema(
+	timeOffModel *model.ProfessionalTimeOff,
+) *schemas.ProfessionalTimeOff {
+	return &schemas.ProfessionalTimeOff{
+		Id:             timeOffModel.Id,
+		ProfessionalId: timeOffModel.ProfessionalId,
+		StartTime:      timeOffModel.StartTime,
+		EndTime:        timeOffModel.EndTime,
+		Reason:         timeOffModel.Reason,
+	}
+}
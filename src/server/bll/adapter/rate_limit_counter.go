@@ -0,0 +1,50 @@
+package adapter
+
+import (
+	"time"
+
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type RateLimitCounter struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewRateLimitCounterAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *RateLimitCounter {
+	return &RateLimitCounter{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (r *RateLimitCounter) IncrementPostgresqlRateLimitCounter(
+	key string,
+	window time.Duration,
+) (*schemas.RateLimitCounter, *errors.Error) {
+	counterModel, err := r.DaoPostgresql.RateLimitCounter.IncrementRateLimitCounter(key, window)
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return &schemas.RateLimitCounter{
+		Key:       counterModel.Key,
+		Count:     counterModel.Count,
+		ExpiresAt: counterModel.ExpiresAt,
+	}, nil
+}
+
+func (r *RateLimitCounter) DeleteExpiredPostgresqlRateLimitCounters() (int64, *errors.Error) {
+	deleted, err := r.DaoPostgresql.RateLimitCounter.DeleteExpiredRateLimitCounters()
+	if err != nil {
+		return 0, &errors.InternalServerError.DatabaseError
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type RefreshToken struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewRefreshTokenAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *RefreshToken {
+	return &RefreshToken{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Stores the hash of a newly issued refresh token
+func (r *RefreshToken) CreatePostgresqlRefreshToken(
+	refreshTokenId uuid.UUID,
+	userId uuid.UUID,
+	familyId uuid.UUID,
+	tokenHash string,
+	expiresAt time.Time,
+	updatedBy string,
+) (*schemas.RefreshToken, *errors.Error) {
+	refreshTokenModel := &model.RefreshToken{
+		Id:        refreshTokenId,
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := r.DaoPostgresql.RefreshToken.CreateRefreshToken(refreshTokenModel); err != nil {
+		return nil, &errors.BadRequestError.RefreshTokenNotCreated
+	}
+
+	return r.convertModelToSchema(refreshTokenModel), nil
+}
+
+// Gets a refresh token by the hash of its opaque value
+func (r *RefreshToken) GetPostgresqlRefreshTokenByHash(
+	tokenHash string,
+) (*schemas.RefreshToken, *errors.Error) {
+	refreshTokenModel, err := r.DaoPostgresql.RefreshToken.GetRefreshTokenByHash(tokenHash)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.RefreshTokenNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return r.convertModelToSchema(refreshTokenModel), nil
+}
+
+// Revokes a refresh token if it is still active, returning whether this call revoked it
+func (r *RefreshToken) RevokePostgresqlRefreshToken(
+	refreshTokenId uuid.UUID,
+	replacedById *uuid.UUID,
+	updatedBy string,
+) (bool, *errors.Error) {
+	revoked, err := r.DaoPostgresql.RefreshToken.RevokeRefreshToken(
+		refreshTokenId,
+		replacedById,
+		updatedBy,
+	)
+	if err != nil {
+		return false, &errors.BadRequestError.RefreshTokenNotRevoked
+	}
+
+	return revoked, nil
+}
+
+// Revokes every active token rotated from the same login
+func (r *RefreshToken) RevokePostgresqlRefreshTokenFamily(
+	familyId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := r.DaoPostgresql.RefreshToken.RevokeRefreshTokenFamily(familyId, updatedBy); err != nil {
+		return &errors.BadRequestError.RefreshTokenNotRevoked
+	}
+
+	return nil
+}
+
+// Revokes every active refresh token of a user
+func (r *RefreshToken) RevokePostgresqlUserRefreshTokens(
+	userId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := r.DaoPostgresql.RefreshToken.RevokeUserRefreshTokens(userId, updatedBy); err != nil {
+		return &errors.BadRequestError.RefreshTokenNotRevoked
+	}
+
+	return nil
+}
+
+func (r *RefreshToken) convertModelToSchema(
+	refreshTokenModel *model.RefreshToken,
+) *schemas.RefreshToken {
+	return &schemas.RefreshToken{
+		Id:           refreshTokenModel.Id,
+		UserId:       refreshTokenModel.UserId,
+		FamilyId:     refreshTokenModel.FamilyId,
+		ExpiresAt:    refreshTokenModel.ExpiresAt,
+		RevokedAt:    refreshTokenModel.RevokedAt,
+		ReplacedById: refreshTokenModel.ReplacedById,
+	}
+}
@@ -38,30 +38,7 @@ func (r *Reservation) GetPostgresqlReservation(
 		return nil, &errors.ObjectNotFoundError.ReservationNotFound
 	}
 
-	return &schemas.Reservation{
-		Id:               reservationModel.Id,
-		Name:             reservationModel.Name,
-		ReservationTime:  reservationModel.ReservationTime,
-		State:            string(reservationModel.State),
-		LastModification: reservationModel.LastModification,
-		UserId:           reservationModel.UserId,
-		SessionId:        reservationModel.SessionId,
-		Session: schemas.Session{
-			Id:                 reservationModel.Session.Id,
-			Title:              reservationModel.Session.Title,
-			Date:               reservationModel.Session.Date,
-			StartTime:          reservationModel.Session.StartTime,
-			EndTime:            reservationModel.Session.EndTime,
-			State:              string(reservationModel.Session.State),
-			RegisteredCount:    reservationModel.Session.RegisteredCount,
-			Capacity:           reservationModel.Session.Capacity,
-			SessionLink:        reservationModel.Session.SessionLink,
-			ProfessionalId:     reservationModel.Session.ProfessionalId,
-			LocalId:            reservationModel.Session.LocalId,
-			CommunityServiceId: reservationModel.Session.CommunityServiceId,
-		},
-		MembershipId: reservationModel.MembershipId,
-	}, nil
+	return convertReservationModelToSchema(reservationModel), nil
 }
 
 // Fetch all reservations from postgresql DB and adapts them to Reservation schema.
@@ -79,35 +56,7 @@ func (r *Reservation) FetchPostgresqlReservations(
 		return nil, &errors.ObjectNotFoundError.ReservationNotFound
 	}
 
-	reservations := make([]*schemas.Reservation, len(reservationModels))
-	for i, reservationModel := range reservationModels {
-		reservations[i] = &schemas.Reservation{
-			Id:               reservationModel.Id,
-			Name:             reservationModel.Name,
-			ReservationTime:  reservationModel.ReservationTime,
-			State:            string(reservationModel.State),
-			LastModification: reservationModel.LastModification,
-			UserId:           reservationModel.UserId,
-			SessionId:        reservationModel.SessionId,
-			Session: schemas.Session{
-				Id:                 reservationModel.Session.Id,
-				Title:              reservationModel.Session.Title,
-				Date:               reservationModel.Session.Date,
-				StartTime:          reservationModel.Session.StartTime,
-				EndTime:            reservationModel.Session.EndTime,
-				State:              string(reservationModel.Session.State),
-				RegisteredCount:    reservationModel.Session.RegisteredCount,
-				Capacity:           reservationModel.Session.Capacity,
-				SessionLink:        reservationModel.Session.SessionLink,
-				ProfessionalId:     reservationModel.Session.ProfessionalId,
-				LocalId:            reservationModel.Session.LocalId,
-				CommunityServiceId: reservationModel.Session.CommunityServiceId,
-			},
-			MembershipId: reservationModel.MembershipId,
-		}
-	}
-
-	return reservations, nil
+	return convertReservationModelsToSchemas(reservationModels), nil
 }
 
 // Creates a reservation in postgresql DB and adapts it to Reservation schema.
@@ -118,6 +67,7 @@ func (r *Reservation) CreatePostgresqlReservation(
 	userId uuid.UUID,
 	sessionId uuid.UUID,
 	membershipId *uuid.UUID,
+	joinWaitlist bool,
 	updatedBy string,
 ) (*schemas.Reservation, *errors.Error) {
 	if updatedBy == "" {
@@ -131,39 +81,26 @@ func (r *Reservation) CreatePostgresqlReservation(
 		userId,
 		sessionId,
 		membershipId,
+		joinWaitlist,
 		updatedBy,
 	)
 	if err != nil {
+		switch err {
+		case daoPsql.ErrSessionFull:
+			return nil, &errors.ConflictError.SessionFull
+		case daoPsql.ErrMembershipLimitReached:
+			return nil, &errors.ForbiddenError.MembershipReservationLimitReached
+		case daoPsql.ErrInvalidReservationInitialState:
+			return nil, &errors.BadRequestError.InvalidReservationInitialState
+		}
 		return nil, &errors.InternalServerError.Default
 	}
 
-	return &schemas.Reservation{
-		Id:               reservationModel.Id,
-		Name:             reservationModel.Name,
-		ReservationTime:  reservationModel.ReservationTime,
-		State:            string(reservationModel.State),
-		LastModification: reservationModel.LastModification,
-		UserId:           reservationModel.UserId,
-		SessionId:        reservationModel.SessionId,
-		Session: schemas.Session{
-			Id:                 reservationModel.Session.Id,
-			Title:              reservationModel.Session.Title,
-			Date:               reservationModel.Session.Date,
-			StartTime:          reservationModel.Session.StartTime,
-			EndTime:            reservationModel.Session.EndTime,
-			State:              string(reservationModel.Session.State),
-			RegisteredCount:    reservationModel.Session.RegisteredCount,
-			Capacity:           reservationModel.Session.Capacity,
-			SessionLink:        reservationModel.Session.SessionLink,
-			ProfessionalId:     reservationModel.Session.ProfessionalId,
-			LocalId:            reservationModel.Session.LocalId,
-			CommunityServiceId: reservationModel.Session.CommunityServiceId,
-		},
-		MembershipId: reservationModel.MembershipId,
-	}, nil
+	return convertReservationModelToSchema(reservationModel), nil
 }
 
-// Updates a reservation in postgresql DB and adapts it to Reservation schema.
+// Updates a reservation in postgresql DB and adapts it to Reservation schema, together with the
+// waitlisted reservations promoted to the spot it gave back.
 func (r *Reservation) UpdatePostgresqlReservation(
 	reservationId uuid.UUID,
 	name *string,
@@ -172,13 +109,14 @@ func (r *Reservation) UpdatePostgresqlReservation(
 	userId *uuid.UUID,
 	sessionId *uuid.UUID,
 	membershipId *uuid.UUID,
+	forfeitCredit bool,
 	updatedBy string,
-) (*schemas.Reservation, *errors.Error) {
+) (*schemas.Reservation, []*schemas.Reservation, *errors.Error) {
 	if updatedBy == "" {
-		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+		return nil, nil, &errors.BadRequestError.InvalidUpdatedByValue
 	}
 
-	reservationModel, err := r.DaoPostgresql.Reservation.UpdateReservation(
+	reservationModel, promotedModels, err := r.DaoPostgresql.Reservation.UpdateReservation(
 		reservationId,
 		name,
 		reservationTime,
@@ -186,18 +124,189 @@ func (r *Reservation) UpdatePostgresqlReservation(
 		userId,
 		sessionId,
 		membershipId,
+		forfeitCredit,
 		updatedBy,
 	)
+	if err != nil {
+		switch err {
+		case daoPsql.ErrSessionFull:
+			return nil, nil, &errors.ConflictError.SessionFull
+		case daoPsql.ErrMembershipLimitReached:
+			return nil, nil, &errors.ForbiddenError.MembershipReservationLimitReached
+		case daoPsql.ErrInvalidReservationTransition:
+			return nil, nil, &errors.BadRequestError.InvalidReservationTransition
+		case gorm.ErrRecordNotFound:
+			return nil, nil, &errors.ObjectNotFoundError.ReservationNotFound
+		}
+		return nil, nil, &errors.InternalServerError.Default
+	}
+
+	return convertReservationModelToSchema(reservationModel),
+		convertReservationModelsToSchemas(promotedModels), nil
+}
+
+// Marks as DONE the confirmed reservations of completed sessions in postgresql DB and adapts them
+// to their schema.
+func (r *Reservation) CompletePostgresqlReservations(updatedBy string) ([]*schemas.Reservation, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	reservationModels, err := r.DaoPostgresql.Reservation.CompleteReservations(updatedBy)
 	if err != nil {
 		return nil, &errors.InternalServerError.Default
 	}
 
+	return convertReservationModelsToSchemas(reservationModels), nil
+}
+
+// Deletes a reservation from postgresql DB and returns the waitlisted reservations promoted to the
+// spot it gave back.
+func (r *Reservation) DeletePostgresqlReservation(
+	reservationId uuid.UUID,
+	forfeitCredit bool,
+) ([]*schemas.Reservation, *errors.Error) {
+	promotedModels, err := r.DaoPostgresql.Reservation.DeleteReservation(reservationId, forfeitCredit)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.ReservationNotFound
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertReservationModelsToSchemas(promotedModels), nil
+}
+
+// Bulk deletes reservations from postgresql DB and returns the waitlisted reservations promoted to
+// the spots they gave back.
+func (r *Reservation) BulkDeletePostgresqlReservations(
+	reservationIds []string,
+	forfeitIds []uuid.UUID,
+) ([]*schemas.Reservation, *errors.Error) {
+	// Convert string IDs to UUIDs
+	uuidIds := make([]uuid.UUID, len(reservationIds))
+	for i, id := range reservationIds {
+		parsedId, parseErr := uuid.Parse(id)
+		if parseErr != nil {
+			return nil, &errors.UnprocessableEntityError.InvalidReservationId
+		}
+		uuidIds[i] = parsedId
+	}
+
+	promotedModels, err := r.DaoPostgresql.Reservation.BulkDeleteReservations(uuidIds, forfeitIds)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertReservationModelsToSchemas(promotedModels), nil
+}
+
+// Checks the attendee of a reservation in and adapts the reservation to Reservation schema.
+func (r *Reservation) CheckInPostgresqlReservation(
+	reservationId uuid.UUID,
+	checkedInAt time.Time,
+	updatedBy string,
+) (*schemas.Reservation, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	reservationModel, err := r.DaoPostgresql.Reservation.CheckInReservation(reservationId, checkedInAt, updatedBy)
+	if err != nil {
+		switch err {
+		case daoPsql.ErrReservationAlreadyCheckedIn:
+			return nil, &errors.ConflictError.ReservationAlreadyCheckedIn
+		case daoPsql.ErrReservationNotConfirmed:
+			return nil, &errors.BadRequestError.ReservationNotConfirmed
+		case gorm.ErrRecordNotFound:
+			return nil, &errors.ObjectNotFoundError.ReservationNotFound
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertReservationModelToSchema(reservationModel), nil
+}
+
+// Fetch the confirmed reservations never checked in of the sessions that ended before a given time
+// and adapts them to Reservation schema.
+func (r *Reservation) FetchPostgresqlMissedReservations(
+	endedBefore time.Time,
+) ([]*schemas.Reservation, *errors.Error) {
+	reservationModels, err := r.DaoPostgresql.Reservation.FetchMissedReservations(endedBefore)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertReservationModelsToSchemas(reservationModels), nil
+}
+
+// Marks a reservation never checked in as a no-show. Returns whether it was marked, which is not
+// the case when it was checked in or changed meanwhile.
+func (r *Reservation) MarkPostgresqlReservationNoShow(
+	reservationId uuid.UUID,
+	forfeitCredit bool,
+	updatedBy string,
+) (bool, *errors.Error) {
+	marked, err := r.DaoPostgresql.Reservation.MarkReservationNoShow(reservationId, forfeitCredit, updatedBy)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, &errors.ObjectNotFoundError.ReservationNotFound
+		}
+		return false, &errors.InternalServerError.Default
+	}
+
+	return marked, nil
+}
+
+// Gets the start times of the sessions a user missed since a given time in a community, or in one
+// of its services when `serviceId` is given.
+func (r *Reservation) FetchPostgresqlNoShowTimes(
+	userId uuid.UUID,
+	communityId uuid.UUID,
+	serviceId *uuid.UUID,
+	since time.Time,
+) ([]time.Time, *errors.Error) {
+	times, err := r.DaoPostgresql.Reservation.FetchNoShowTimes(userId, communityId, serviceId, since)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return times, nil
+}
+
+// Gets the place of a waitlisted reservation in the waitlist of its session and the size of that
+// waitlist.
+func (r *Reservation) GetPostgresqlWaitlistPosition(
+	reservation *schemas.Reservation,
+) (*schemas.WaitlistPosition, *errors.Error) {
+	position, size, err := r.DaoPostgresql.Reservation.GetWaitlistPosition(&model.Reservation{
+		Id:           reservation.Id,
+		SessionId:    reservation.SessionId,
+		WaitlistedAt: reservation.WaitlistedAt,
+	})
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return &schemas.WaitlistPosition{
+		ReservationId: reservation.Id,
+		SessionId:     reservation.SessionId,
+		Position:      position,
+		WaitlistSize:  size,
+		WaitlistedAt:  reservation.WaitlistedAt,
+	}, nil
+}
+
+func convertReservationModelToSchema(reservationModel *model.Reservation) *schemas.Reservation {
 	return &schemas.Reservation{
 		Id:               reservationModel.Id,
 		Name:             reservationModel.Name,
 		ReservationTime:  reservationModel.ReservationTime,
 		State:            string(reservationModel.State),
 		LastModification: reservationModel.LastModification,
+		WaitlistedAt:     reservationModel.WaitlistedAt,
+		CreditForfeited:  reservationModel.CreditForfeited,
+		CheckedInAt:      reservationModel.CheckedInAt,
 		UserId:           reservationModel.UserId,
 		SessionId:        reservationModel.SessionId,
 		Session: schemas.Session{
@@ -215,40 +324,16 @@ func (r *Reservation) UpdatePostgresqlReservation(
 			CommunityServiceId: reservationModel.Session.CommunityServiceId,
 		},
 		MembershipId: reservationModel.MembershipId,
-	}, nil
-}
-
-// Deletes a reservation from postgresql DB.
-func (r *Reservation) DeletePostgresqlReservation(reservationId uuid.UUID) *errors.Error {
-	err := r.DaoPostgresql.Reservation.DeleteReservation(reservationId)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return &errors.ObjectNotFoundError.ReservationNotFound
-		}
-		return &errors.InternalServerError.Default
 	}
-
-	return nil
 }
 
-// Bulk deletes reservations from postgresql DB.
-func (r *Reservation) BulkDeletePostgresqlReservations(reservationIds []string) *errors.Error {
-	// Convert string IDs to UUIDs
-	uuidIds := make([]uuid.UUID, len(reservationIds))
-	for i, id := range reservationIds {
-		parsedId, parseErr := uuid.Parse(id)
-		if parseErr != nil {
-			return &errors.UnprocessableEntityError.InvalidReservationId
-		}
-		uuidIds[i] = parsedId
-	}
-
-	err := r.DaoPostgresql.Reservation.BulkDeleteReservations(uuidIds)
-	if err != nil {
-		return &errors.InternalServerError.Default
+func convertReservationModelsToSchemas(reservationModels []*model.Reservation) []*schemas.Reservation {
+	reservations := make([]*schemas.Reservation, len(reservationModels))
+	for i, reservationModel := range reservationModels {
+		reservations[i] = convertReservationModelToSchema(reservationModel)
 	}
 
-	return nil
+	return reservations
 }
 
 // GetServiceReport obtiene reservas, hace preload profundo y agrupa por servicio y fecha
@@ -0,0 +1,54 @@
+package adapter
+
+import (
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type ReservationStateHistory struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+// Creates ReservationStateHistory adapter
+func NewReservationStateHistoryAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *ReservationStateHistory {
+	return &ReservationStateHistory{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Fetch the changes of state of a reservation from postgresql DB, oldest first
+func (r *ReservationStateHistory) FetchPostgresqlReservationStateHistory(
+	reservationId uuid.UUID,
+) ([]*schemas.ReservationStateChange, *errors.Error) {
+	historyModels, err := r.DaoPostgresql.ReservationStateHistory.FetchReservationStateHistory(reservationId)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	changes := []*schemas.ReservationStateChange{}
+	for _, historyModel := range historyModels {
+		var fromState *string
+		if historyModel.FromState != nil {
+			state := string(*historyModel.FromState)
+			fromState = &state
+		}
+		changes = append(changes, &schemas.ReservationStateChange{
+			Id:        historyModel.Id,
+			FromState: fromState,
+			ToState:   string(historyModel.ToState),
+			Reason:    string(historyModel.Reason),
+			ChangedBy: historyModel.ChangedBy,
+			ChangedAt: historyModel.ChangedAt,
+		})
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,205 @@
+package adapter
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type Role struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewRoleAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *Role {
+	return &Role{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (r *Role) FetchPostgresqlRoles() ([]*schemas.Role, *errors.Error) {
+	roleModels, err := r.DaoPostgresql.Role.FetchRoles()
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	roles := make([]*schemas.Role, len(roleModels))
+	for i, roleModel := range roleModels {
+		roles[i] = r.convertModelToSchema(roleModel)
+	}
+
+	return roles, nil
+}
+
+func (r *Role) GetPostgresqlRole(roleId uuid.UUID) (*schemas.Role, *errors.Error) {
+	roleModel, err := r.DaoPostgresql.Role.GetRole(roleId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.RoleNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return r.convertModelToSchema(roleModel), nil
+}
+
+func (r *Role) GetPostgresqlRoleByName(name schemas.UserRol) (*schemas.Role, *errors.Error) {
+	roleModel, err := r.DaoPostgresql.Role.GetRoleByName(model.UserRol(name))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.RoleNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return r.convertModelToSchema(roleModel), nil
+}
+
+func (r *Role) FetchPostgresqlPermissions() ([]*schemas.Permission, *errors.Error) {
+	permissionModels, err := r.DaoPostgresql.Role.FetchPermissions()
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	permissions := make([]*schemas.Permission, len(permissionModels))
+	for i, permissionModel := range permissionModels {
+		permissions[i] = &schemas.Permission{
+			Id:          permissionModel.Id,
+			Code:        permissionModel.Code,
+			Description: permissionModel.Description,
+		}
+	}
+
+	return permissions, nil
+}
+
+// Replaces the permissions of a role given their codes. Unknown codes are rejected.
+func (r *Role) SetPostgresqlRolePermissions(
+	roleId uuid.UUID,
+	codes []string,
+	updatedBy string,
+) *errors.Error {
+	permissionModels, err := r.DaoPostgresql.Role.GetPermissionsByCodes(codes)
+	if err != nil {
+		return &errors.InternalServerError.DatabaseError
+	}
+	if len(permissionModels) != len(uniqueCodes(codes)) {
+		return &errors.BadRequestError.InvalidPermission
+	}
+
+	rolePermissions := make([]*model.RolePermission, len(permissionModels))
+	for i, permissionModel := range permissionModels {
+		rolePermissions[i] = &model.RolePermission{
+			Id:           uuid.New(),
+			RoleId:       roleId,
+			PermissionId: permissionModel.Id,
+			AuditFields: model.AuditFields{
+				UpdatedBy: updatedBy,
+			},
+		}
+	}
+
+	if err := r.DaoPostgresql.Role.SetRolePermissions(roleId, rolePermissions); err != nil {
+		return &errors.BadRequestError.RoleNotUpdated
+	}
+
+	return nil
+}
+
+// Seeds the default roles and permissions. ADMINISTRATOR always gets every permission.
+func (r *Role) SeedPostgresqlRolesAndPermissions(
+	permissionDefinitions []schemas.PermissionDefinition,
+	roleDefinitions []schemas.RoleDefinition,
+) *errors.Error {
+	permissionModels := make([]*model.Permission, len(permissionDefinitions))
+	allCodes := make([]string, len(permissionDefinitions))
+	for i, definition := range permissionDefinitions {
+		permissionModels[i] = &model.Permission{
+			Id:          uuid.New(),
+			Code:        definition.Code,
+			Description: definition.Description,
+			AuditFields: model.AuditFields{
+				UpdatedBy: "SYSTEM",
+			},
+		}
+		allCodes[i] = definition.Code
+	}
+
+	roleModels := make([]*model.Role, len(roleDefinitions))
+	rolePermissionCodes := make(map[model.UserRol][]string, len(roleDefinitions))
+	for i, definition := range roleDefinitions {
+		roleModels[i] = &model.Role{
+			Id:              uuid.New(),
+			Name:            model.UserRol(definition.Name),
+			Description:     definition.Description,
+			CommunityScoped: definition.CommunityScoped,
+			AuditFields: model.AuditFields{
+				UpdatedBy: "SYSTEM",
+			},
+		}
+		rolePermissionCodes[model.UserRol(definition.Name)] = definition.Permissions
+	}
+	rolePermissionCodes[model.UserRolAdmin] = allCodes
+
+	if err := r.DaoPostgresql.Role.SeedRolesAndPermissions(
+		permissionModels,
+		roleModels,
+		rolePermissionCodes,
+		model.UserRolAdmin,
+	); err != nil {
+		return &errors.InternalServerError.DatabaseError
+	}
+
+	return nil
+}
+
+func (r *Role) GetPostgresqlUserPermissionGrants(
+	userId uuid.UUID,
+	primaryRol schemas.UserRol,
+) ([]*schemas.PermissionGrant, *errors.Error) {
+	grantModels, err := r.DaoPostgresql.Role.GetUserPermissionGrants(userId, model.UserRol(primaryRol))
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	grants := make([]*schemas.PermissionGrant, len(grantModels))
+	for i, grantModel := range grantModels {
+		grants[i] = &schemas.PermissionGrant{
+			Code:        grantModel.Code,
+			CommunityId: grantModel.CommunityId,
+		}
+	}
+
+	return grants, nil
+}
+
+func (r *Role) convertModelToSchema(roleModel *model.Role) *schemas.Role {
+	permissions := make([]string, 0, len(roleModel.RolePermissions))
+	for _, rolePermission := range roleModel.RolePermissions {
+		permissions = append(permissions, rolePermission.Permission.Code)
+	}
+
+	return &schemas.Role{
+		Id:              roleModel.Id,
+		Name:            schemas.UserRol(roleModel.Name),
+		Description:     roleModel.Description,
+		CommunityScoped: roleModel.CommunityScoped,
+		Permissions:     permissions,
+	}
+}
+
+func uniqueCodes(codes []string) map[string]struct{} {
+	unique := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		unique[code] = struct{}{}
+	}
+	return unique
+}
@@ -0,0 +1,184 @@
+package adapter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type ServiceAccount struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewServiceAccountAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *ServiceAccount {
+	return &ServiceAccount{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (s *ServiceAccount) CreatePostgresqlServiceAccount(
+	name string,
+	description string,
+	scopes []string,
+	keyPrefix string,
+	keyHash string,
+	expiresAt *time.Time,
+	updatedBy string,
+) (*schemas.ServiceAccount, *errors.Error) {
+	serviceAccountModel := &model.ServiceAccount{
+		Id:          uuid.New(),
+		Name:        name,
+		Description: description,
+		Scopes:      strings.Join(scopes, " "),
+		KeyPrefix:   keyPrefix,
+		KeyHash:     keyHash,
+		ExpiresAt:   expiresAt,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := s.DaoPostgresql.ServiceAccount.CreateServiceAccount(serviceAccountModel); err != nil {
+		return nil, &errors.BadRequestError.ServiceAccountNotCreated
+	}
+
+	return s.convertModelToSchema(serviceAccountModel), nil
+}
+
+func (s *ServiceAccount) GetPostgresqlServiceAccount(
+	serviceAccountId uuid.UUID,
+) (*schemas.ServiceAccount, *errors.Error) {
+	serviceAccountModel, err := s.DaoPostgresql.ServiceAccount.GetServiceAccount(serviceAccountId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.ServiceAccountNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return s.convertModelToSchema(serviceAccountModel), nil
+}
+
+func (s *ServiceAccount) GetPostgresqlServiceAccountByName(
+	name string,
+) (*schemas.ServiceAccount, *errors.Error) {
+	serviceAccountModel, err := s.DaoPostgresql.ServiceAccount.GetServiceAccountByName(name)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.ServiceAccountNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return s.convertModelToSchema(serviceAccountModel), nil
+}
+
+func (s *ServiceAccount) GetPostgresqlServiceAccountByKeyHash(
+	keyHash string,
+) (*schemas.ServiceAccount, *errors.Error) {
+	serviceAccountModel, err := s.DaoPostgresql.ServiceAccount.GetServiceAccountByKeyHash(keyHash)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.ServiceAccountNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return s.convertModelToSchema(serviceAccountModel), nil
+}
+
+func (s *ServiceAccount) FetchPostgresqlServiceAccounts() ([]*schemas.ServiceAccount, *errors.Error) {
+	serviceAccountModels, err := s.DaoPostgresql.ServiceAccount.FetchServiceAccounts()
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	serviceAccounts := make([]*schemas.ServiceAccount, len(serviceAccountModels))
+	for i, serviceAccountModel := range serviceAccountModels {
+		serviceAccounts[i] = s.convertModelToSchema(serviceAccountModel)
+	}
+
+	return serviceAccounts, nil
+}
+
+func (s *ServiceAccount) RotatePostgresqlServiceAccountKey(
+	serviceAccountId uuid.UUID,
+	keyPrefix string,
+	keyHash string,
+	expiresAt *time.Time,
+	updatedBy string,
+) *errors.Error {
+	if err := s.DaoPostgresql.ServiceAccount.RotateServiceAccountKey(
+		serviceAccountId,
+		keyPrefix,
+		keyHash,
+		expiresAt,
+		updatedBy,
+	); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.ServiceAccountNotFound
+		}
+		return &errors.BadRequestError.ServiceAccountNotUpdated
+	}
+
+	return nil
+}
+
+func (s *ServiceAccount) RevokePostgresqlServiceAccountKey(
+	serviceAccountId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	if err := s.DaoPostgresql.ServiceAccount.RevokeServiceAccountKey(
+		serviceAccountId,
+		updatedBy,
+	); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.ServiceAccountNotFound
+		}
+		return &errors.BadRequestError.ServiceAccountNotUpdated
+	}
+
+	return nil
+}
+
+func (s *ServiceAccount) TouchPostgresqlServiceAccountLastUsed(
+	serviceAccountId uuid.UUID,
+	interval time.Duration,
+) *errors.Error {
+	if err := s.DaoPostgresql.ServiceAccount.TouchServiceAccountLastUsed(
+		serviceAccountId,
+		interval,
+	); err != nil {
+		return &errors.BadRequestError.ServiceAccountNotUpdated
+	}
+
+	return nil
+}
+
+func (s *ServiceAccount) convertModelToSchema(
+	serviceAccountModel *model.ServiceAccount,
+) *schemas.ServiceAccount {
+	return &schemas.ServiceAccount{
+		Id:          serviceAccountModel.Id,
+		Name:        serviceAccountModel.Name,
+		Description: serviceAccountModel.Description,
+		Scopes:      strings.Fields(serviceAccountModel.Scopes),
+		KeyPrefix:   serviceAccountModel.KeyPrefix,
+		ExpiresAt:   serviceAccountModel.ExpiresAt,
+		LastUsedAt:  serviceAccountModel.LastUsedAt,
+		RevokedAt:   serviceAccountModel.RevokedAt,
+		CreatedAt:   serviceAccountModel.CreatedAt,
+	}
+}
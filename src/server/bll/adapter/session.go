@@ -81,6 +81,7 @@ func (s *Session) CreatePostgresqlSession(
 		ProfessionalId:  sessionModel.ProfessionalId,
 		LocalId:         sessionModel.LocalId,
 		CommunityServiceId: sessionModel.CommunityServiceId,
+		SeriesId:           sessionModel.SeriesId,
 	}, nil
 }
 
@@ -104,10 +105,12 @@ func (s *Session) GetPostgresqlSession(sessionId uuid.UUID) (*schemas.Session, *
 		ProfessionalId:  sessionModel.ProfessionalId,
 		LocalId:         sessionModel.LocalId,
 		CommunityServiceId: sessionModel.CommunityServiceId,
+		SeriesId:           sessionModel.SeriesId,
 	}, nil
 }
 
-// Updates a session given fields in postgresql DB and returns it.
+// Updates a session given fields in postgresql DB, annulling its reservations when it is
+// cancelled. Returns the updated session, the session as it was before and the annulled reservations.
 func (s *Session) UpdatePostgresqlSession(
 	sessionId uuid.UUID,
 	title *string,
@@ -122,13 +125,13 @@ func (s *Session) UpdatePostgresqlSession(
 	localId *uuid.UUID,
 	communityServiceId *uuid.UUID,
 	updatedBy string,
-) (*schemas.Session, *errors.Error) {
+) (*schemas.Session, *schemas.Session, []*schemas.Reservation, *errors.Error) {
 	if updatedBy == "" {
-		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+		return nil, nil, nil, &errors.BadRequestError.InvalidUpdatedByValue
 	}
 
 	// Call the DAO with individual parameters following the Local pattern
-	sessionModel, err := s.DaoPostgresql.Session.UpdateSession(
+	sessionModel, previousModel, annulledModels, err := s.DaoPostgresql.Session.UpdateSession(
 		sessionId,
 		title,
 		date,
@@ -144,23 +147,12 @@ func (s *Session) UpdatePostgresqlSession(
 		updatedBy,
 	)
 	if err != nil {
-		return nil, &errors.BadRequestError.SessionNotUpdated
+		return nil, nil, nil, &errors.BadRequestError.SessionNotUpdated
 	}
 
-	return &schemas.Session{
-		Id:              sessionModel.Id,
-		Title:           sessionModel.Title,
-		Date:            sessionModel.Date,
-		StartTime:       sessionModel.StartTime,
-		EndTime:         sessionModel.EndTime,
-		State:           string(sessionModel.State),
-		RegisteredCount: sessionModel.RegisteredCount,
-		Capacity:        sessionModel.Capacity,
-		SessionLink:     sessionModel.SessionLink,
-		ProfessionalId:  sessionModel.ProfessionalId,
-		LocalId:         sessionModel.LocalId,
-		CommunityServiceId: sessionModel.CommunityServiceId,
-	}, nil
+	return convertSeriesSessionModelToSchema(sessionModel),
+		convertSeriesSessionModelToSchema(previousModel),
+		convertReservationModelsToSchemas(annulledModels), nil
 }
 
 // Soft deletes a session from postgresql DB.
@@ -200,6 +192,7 @@ func (s *Session) FetchPostgresqlSessions(
 			ProfessionalId:  sessionModel.ProfessionalId,
 			LocalId:         sessionModel.LocalId,
 			CommunityServiceId: sessionModel.CommunityServiceId,
+			SeriesId:           sessionModel.SeriesId,
 		}
 	}
 
@@ -271,3 +264,21 @@ func (s *Session) BulkDeletePostgresqlSessions(
 
 	return nil
 }
+
+// Moves the sessions of postgresql DB to ONGOING or COMPLETED as of a given time. Returns how many
+// sessions moved to each state.
+func (s *Session) AdvancePostgresqlSessionStates(
+	now time.Time,
+	updatedBy string,
+) (int64, int64, *errors.Error) {
+	if updatedBy == "" {
+		return 0, 0, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	started, completed, err := s.DaoPostgresql.Session.AdvanceSessionStates(now, updatedBy)
+	if err != nil {
+		return 0, 0, &errors.InternalServerError.Default
+	}
+
+	return started, completed, nil
+}
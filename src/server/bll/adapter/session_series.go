@@ -0,0 +1,334 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPsql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type SessionSeries struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPsql.AstroCatPsqlCollection
+}
+
+// Creates SessionSeries adapter
+func NewSessionSeriesAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPsql.AstroCatPsqlCollection,
+) *SessionSeries {
+	return &SessionSeries{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Gets a session series from postgresql DB given its ID and adapts it to its schema.
+func (ss *SessionSeries) GetPostgresqlSessionSeries(
+	seriesId uuid.UUID,
+) (*schemas.SessionSeries, *errors.Error) {
+	seriesModel, err := ss.DaoPostgresql.SessionSeries.GetSessionSeries(seriesId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.SessionSeriesNotFound
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertSessionSeriesModelToSchema(seriesModel), nil
+}
+
+// Fetches session series from postgresql DB and adapts them to their schema.
+func (ss *SessionSeries) FetchPostgresqlSessionSeries(
+	professionalIds []uuid.UUID,
+) ([]*schemas.SessionSeries, *errors.Error) {
+	seriesModels, err := ss.DaoPostgresql.SessionSeries.FetchSessionSeries(professionalIds)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertSessionSeriesModelsToSchemas(seriesModels), nil
+}
+
+// Fetches the session series not generated up to a day yet and adapts them to their schema.
+func (ss *SessionSeries) FetchPostgresqlSessionSeriesToGenerate(
+	until time.Time,
+) ([]*schemas.SessionSeries, *errors.Error) {
+	seriesModels, err := ss.DaoPostgresql.SessionSeries.FetchSessionSeriesToGenerate(until)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertSessionSeriesModelsToSchemas(seriesModels), nil
+}
+
+// Creates a session series into postgresql DB, with no sessions generated yet, and returns it.
+func (ss *SessionSeries) CreatePostgresqlSessionSeries(
+	request schemas.CreateSessionSeriesRequest,
+	startDate time.Time,
+	updatedBy string,
+) (*schemas.SessionSeries, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	seriesModel := &model.SessionSeries{
+		Id:                 uuid.New(),
+		Title:              request.Title,
+		StartDate:          startDate,
+		StartTime:          request.StartTime,
+		DurationMinutes:    request.DurationMinutes,
+		Capacity:           request.Capacity,
+		SessionLink:        request.SessionLink,
+		Rule:               request.Rule,
+		ExceptionDates:     request.ExceptionDates,
+		GeneratedUntil:     startDate,
+		ProfessionalId:     request.ProfessionalId,
+		LocalId:            request.LocalId,
+		CommunityServiceId: request.CommunityServiceId,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := ss.DaoPostgresql.SessionSeries.CreateSessionSeries(seriesModel); err != nil {
+		return nil, &errors.BadRequestError.SessionSeriesNotCreated
+	}
+
+	return convertSessionSeriesModelToSchema(seriesModel), nil
+}
+
+// Updates the template or the recurrence of a session series in postgresql DB and returns it.
+func (ss *SessionSeries) UpdatePostgresqlSessionSeries(
+	seriesId uuid.UUID,
+	request schemas.UpdateSessionSeriesRequest,
+	rule *string,
+	updatedBy string,
+) (*schemas.SessionSeries, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	seriesModel, err := ss.DaoPostgresql.SessionSeries.UpdateSessionSeries(
+		seriesId,
+		request.Title,
+		request.StartTime,
+		request.DurationMinutes,
+		request.Capacity,
+		request.SessionLink,
+		request.ProfessionalId,
+		request.LocalId,
+		rule,
+		updatedBy,
+	)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.SessionSeriesNotFound
+		}
+		return nil, &errors.BadRequestError.SessionSeriesNotUpdated
+	}
+
+	return convertSessionSeriesModelToSchema(seriesModel), nil
+}
+
+// Records in postgresql DB that the sessions of a series have been generated up to a day.
+func (ss *SessionSeries) AdvancePostgresqlSessionSeries(seriesId uuid.UUID, until time.Time) *errors.Error {
+	if err := ss.DaoPostgresql.SessionSeries.AdvanceSessionSeries(seriesId, until); err != nil {
+		return &errors.InternalServerError.Default
+	}
+	return nil
+}
+
+// Splits a session series in postgresql DB at `startDate`. The series keeps the days before under
+// `rule`, and a new series with the changes of `request` takes the days from then on under
+// `newRule`, together with their sessions. Returns the new series.
+func (ss *SessionSeries) SplitPostgresqlSessionSeries(
+	series *schemas.SessionSeries,
+	rule string,
+	request schemas.UpdateSessionSeriesRequest,
+	startDate time.Time,
+	newRule string,
+	generatedUntil time.Time,
+	updatedBy string,
+) (*schemas.SessionSeries, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	newSeriesModel := &model.SessionSeries{
+		Id:                 uuid.New(),
+		Title:              series.Title,
+		StartDate:          startDate,
+		StartTime:          series.StartTime,
+		DurationMinutes:    series.DurationMinutes,
+		Capacity:           series.Capacity,
+		SessionLink:        series.SessionLink,
+		Rule:               newRule,
+		ExceptionDates:     series.ExceptionDates,
+		GeneratedUntil:     generatedUntil,
+		ProfessionalId:     series.ProfessionalId,
+		LocalId:            series.LocalId,
+		CommunityServiceId: series.CommunityServiceId,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+	if request.Title != nil {
+		newSeriesModel.Title = *request.Title
+	}
+	if request.StartTime != nil {
+		newSeriesModel.StartTime = *request.StartTime
+	}
+	if request.DurationMinutes != nil {
+		newSeriesModel.DurationMinutes = *request.DurationMinutes
+	}
+	if request.Capacity != nil {
+		newSeriesModel.Capacity = *request.Capacity
+	}
+	if request.SessionLink != nil {
+		newSeriesModel.SessionLink = request.SessionLink
+	}
+	if request.ProfessionalId != nil {
+		newSeriesModel.ProfessionalId = *request.ProfessionalId
+	}
+	if request.LocalId != nil {
+		newSeriesModel.LocalId = request.LocalId
+	}
+
+	if err := ss.DaoPostgresql.SessionSeries.SplitSessionSeries(series.Id, rule, newSeriesModel); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.SessionSeriesNotFound
+		}
+		return nil, &errors.BadRequestError.SessionSeriesNotUpdated
+	}
+
+	return convertSessionSeriesModelToSchema(newSeriesModel), nil
+}
+
+// Creates the session of a series for one of its days into postgresql DB and returns it, or nil
+// when the series already has it.
+func (ss *SessionSeries) CreatePostgresqlSeriesSession(
+	series *schemas.SessionSeries,
+	day time.Time,
+	startTime time.Time,
+	endTime time.Time,
+	updatedBy string,
+) (*schemas.Session, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	sessionModel := &model.Session{
+		Id:                 uuid.New(),
+		Title:              series.Title,
+		Date:               startTime,
+		StartTime:          startTime,
+		EndTime:            endTime,
+		State:              model.SessionStateScheduled,
+		RegisteredCount:    0,
+		Capacity:           series.Capacity,
+		SessionLink:        series.SessionLink,
+		ProfessionalId:     series.ProfessionalId,
+		LocalId:            series.LocalId,
+		CommunityServiceId: series.CommunityServiceId,
+		SeriesId:           &series.Id,
+		OccurrenceDate:     &day,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	created, err := ss.DaoPostgresql.SessionSeries.CreateSeriesSession(sessionModel)
+	if err != nil {
+		return nil, &errors.BadRequestError.SessionNotCreated
+	}
+	if !created {
+		return nil, nil
+	}
+
+	return convertSeriesSessionModelToSchema(sessionModel), nil
+}
+
+// Fetches the sessions of a series from postgresql DB, from a day if provided, and adapts them to
+// occurrences of the series.
+func (ss *SessionSeries) FetchPostgresqlSeriesOccurrences(
+	seriesId uuid.UUID,
+	from *time.Time,
+) ([]*schemas.SessionSeriesOccurrence, *errors.Error) {
+	sessionModels, err := ss.DaoPostgresql.SessionSeries.FetchSeriesSessions(seriesId, from)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	occurrences := make([]*schemas.SessionSeriesOccurrence, len(sessionModels))
+	for i, sessionModel := range sessionModels {
+		occurrences[i] = &schemas.SessionSeriesOccurrence{
+			Date:     sessionModel.OccurrenceDate.Format(time.DateOnly),
+			Detached: sessionModel.Detached,
+			Session:  convertSeriesSessionModelToSchema(sessionModel),
+		}
+	}
+
+	return occurrences, nil
+}
+
+// Marks a session of a series in postgresql DB as edited on its own.
+func (ss *SessionSeries) DetachPostgresqlSeriesSession(sessionId uuid.UUID, updatedBy string) *errors.Error {
+	if err := ss.DaoPostgresql.SessionSeries.DetachSeriesSession(sessionId, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.SessionNotFound
+		}
+		return &errors.BadRequestError.SessionNotUpdated
+	}
+	return nil
+}
+
+func convertSessionSeriesModelToSchema(seriesModel *model.SessionSeries) *schemas.SessionSeries {
+	return &schemas.SessionSeries{
+		Id:                 seriesModel.Id,
+		Title:              seriesModel.Title,
+		StartDate:          seriesModel.StartDate.Format(time.DateOnly),
+		StartTime:          seriesModel.StartTime,
+		DurationMinutes:    seriesModel.DurationMinutes,
+		Capacity:           seriesModel.Capacity,
+		SessionLink:        seriesModel.SessionLink,
+		Rule:               seriesModel.Rule,
+		ExceptionDates:     seriesModel.ExceptionDates,
+		GeneratedUntil:     seriesModel.GeneratedUntil.Format(time.DateOnly),
+		ProfessionalId:     seriesModel.ProfessionalId,
+		LocalId:            seriesModel.LocalId,
+		CommunityServiceId: seriesModel.CommunityServiceId,
+	}
+}
+
+func convertSessionSeriesModelsToSchemas(seriesModels []*model.SessionSeries) []*schemas.SessionSeries {
+	series := make([]*schemas.SessionSeries, len(seriesModels))
+	for i, seriesModel := range seriesModels {
+		series[i] = convertSessionSeriesModelToSchema(seriesModel)
+	}
+
+	return series
+}
+
+func convertSeriesSessionModelToSchema(sessionModel *model.Session) *schemas.Session {
+	return &schemas.Session{
+		Id:                 sessionModel.Id,
+		Title:              sessionModel.Title,
+		Date:               sessionModel.Date,
+		StartTime:          sessionModel.StartTime,
+		EndTime:            sessionModel.EndTime,
+		State:              string(sessionModel.State),
+		RegisteredCount:    sessionModel.RegisteredCount,
+		Capacity:           sessionModel.Capacity,
+		SessionLink:        sessionModel.SessionLink,
+		ProfessionalId:     sessionModel.ProfessionalId,
+		LocalId:            sessionModel.LocalId,
+		CommunityServiceId: sessionModel.CommunityServiceId,
+		SeriesId:           sessionModel.SeriesId,
+	}
+}
@@ -0,0 +1,332 @@
+package adapter
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPsql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type StandingBooking struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPsql.AstroCatPsqlCollection
+}
+
+// Creates StandingBooking adapter
+func NewStandingBookingAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPsql.AstroCatPsqlCollection,
+) *StandingBooking {
+	return &StandingBooking{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Gets a standing booking from postgresql DB given its ID and adapts it to its schema.
+func (sb *StandingBooking) GetPostgresqlStandingBooking(
+	standingBookingId uuid.UUID,
+) (*schemas.StandingBooking, *errors.Error) {
+	standingBookingModel, err := sb.DaoPostgresql.StandingBooking.GetStandingBooking(standingBookingId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.StandingBookingNotFound
+		}
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertStandingBookingModelToSchema(standingBookingModel), nil
+}
+
+// Fetches standing bookings from postgresql DB and adapts them to their schema.
+func (sb *StandingBooking) FetchPostgresqlStandingBookings(
+	userIds []uuid.UUID,
+	activeOnly bool,
+) ([]*schemas.StandingBooking, *errors.Error) {
+	standingBookingModels, err := sb.DaoPostgresql.StandingBooking.FetchStandingBookings(userIds, activeOnly)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertStandingBookingModelsToSchemas(standingBookingModels), nil
+}
+
+// Fetches the active standing bookings of a service led by a professional and adapts them to their
+// schema.
+func (sb *StandingBooking) FetchPostgresqlActiveStandingBookingsByScope(
+	serviceId uuid.UUID,
+	professionalId uuid.UUID,
+) ([]*schemas.StandingBooking, *errors.Error) {
+	standingBookingModels, err := sb.DaoPostgresql.StandingBooking.FetchActiveStandingBookingsByScope(
+		serviceId,
+		professionalId,
+	)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return convertStandingBookingModelsToSchemas(standingBookingModels), nil
+}
+
+// Whether a user is already subscribed to a pattern.
+func (sb *StandingBooking) ExistsPostgresqlStandingBooking(
+	userId uuid.UUID,
+	serviceId uuid.UUID,
+	professionalId uuid.UUID,
+	weekday int,
+	startTime string,
+) (bool, *errors.Error) {
+	exists, err := sb.DaoPostgresql.StandingBooking.ExistsStandingBooking(
+		userId,
+		serviceId,
+		professionalId,
+		weekday,
+		startTime,
+	)
+	if err != nil {
+		return false, &errors.InternalServerError.Default
+	}
+
+	return exists, nil
+}
+
+// Creates an active standing booking in postgresql DB and adapts it to its schema.
+func (sb *StandingBooking) CreatePostgresqlStandingBooking(
+	request schemas.CreateStandingBookingRequest,
+	updatedBy string,
+) (*schemas.StandingBooking, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	standingBookingModel := &model.StandingBooking{
+		Id:             uuid.New(),
+		Weekday:        request.Weekday,
+		StartTime:      request.StartTime,
+		Active:         true,
+		UserId:         request.UserId,
+		ServiceId:      request.ServiceId,
+		ProfessionalId: request.ProfessionalId,
+		MembershipId:   request.MembershipId,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := sb.DaoPostgresql.StandingBooking.CreateStandingBooking(standingBookingModel); err != nil {
+		return nil, &errors.BadRequestError.StandingBookingNotCreated
+	}
+
+	return convertStandingBookingModelToSchema(standingBookingModel), nil
+}
+
+// Updates a standing booking in postgresql DB and adapts it to its schema.
+func (sb *StandingBooking) UpdatePostgresqlStandingBooking(
+	standingBookingId uuid.UUID,
+	request schemas.UpdateStandingBookingRequest,
+	updatedBy string,
+) (*schemas.StandingBooking, *errors.Error) {
+	if updatedBy == "" {
+		return nil, &errors.BadRequestError.InvalidUpdatedByValue
+	}
+
+	standingBookingModel, err := sb.DaoPostgresql.StandingBooking.UpdateStandingBooking(
+		standingBookingId,
+		request.Active,
+		request.MembershipId,
+		updatedBy,
+	)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.StandingBookingNotFound
+		}
+		return nil, &errors.BadRequestError.StandingBookingNotUpdated
+	}
+
+	return convertStandingBookingModelToSchema(standingBookingModel), nil
+}
+
+// Deletes a standing booking from postgresql DB.
+func (sb *StandingBooking) DeletePostgresqlStandingBooking(standingBookingId uuid.UUID) *errors.Error {
+	if err := sb.DaoPostgresql.StandingBooking.DeleteStandingBooking(standingBookingId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.StandingBookingNotFound
+		}
+		return &errors.BadRequestError.StandingBookingNotDeleted
+	}
+
+	return nil
+}
+
+// Fetches the scheduled sessions a standing booking has not tried to book yet that start within a
+// time range, counting attempts claimed before `staleBefore` and never finished as not tried, and
+// adapts them to Session schema.
+func (sb *StandingBooking) FetchPostgresqlUntriedSessions(
+	standingBooking *schemas.StandingBooking,
+	from time.Time,
+	to time.Time,
+	staleBefore time.Time,
+) ([]*schemas.Session, *errors.Error) {
+	sessionModels, err := sb.DaoPostgresql.StandingBooking.FetchUntriedSessions(
+		&model.StandingBooking{
+			Id:             standingBooking.Id,
+			ServiceId:      standingBooking.ServiceId,
+			ProfessionalId: standingBooking.ProfessionalId,
+		},
+		from,
+		to,
+		staleBefore,
+	)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	sessions := make([]*schemas.Session, len(sessionModels))
+	for i, sessionModel := range sessionModels {
+		sessions[i] = &schemas.Session{
+			Id:                 sessionModel.Id,
+			Title:              sessionModel.Title,
+			Date:               sessionModel.Date,
+			StartTime:          sessionModel.StartTime,
+			EndTime:            sessionModel.EndTime,
+			State:              string(sessionModel.State),
+			RegisteredCount:    sessionModel.RegisteredCount,
+			Capacity:           sessionModel.Capacity,
+			SessionLink:        sessionModel.SessionLink,
+			ProfessionalId:     sessionModel.ProfessionalId,
+			LocalId:            sessionModel.LocalId,
+			CommunityServiceId: sessionModel.CommunityServiceId,
+		}
+	}
+
+	return sessions, nil
+}
+
+// Records that a standing booking is trying to book a session, taking over attempts claimed before
+// `staleBefore` and never finished. Returns nil when it already did.
+func (sb *StandingBooking) ClaimPostgresqlStandingBookingAttempt(
+	standingBookingId uuid.UUID,
+	session *schemas.Session,
+	staleBefore time.Time,
+	updatedBy string,
+) (*schemas.StandingBookingAttempt, *errors.Error) {
+	attemptModel, err := sb.DaoPostgresql.StandingBooking.ClaimStandingBookingAttempt(
+		standingBookingId,
+		session.Id,
+		staleBefore,
+		updatedBy,
+	)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+	if attemptModel == nil {
+		return nil, nil
+	}
+
+	attemptModel.Session.StartTime = session.StartTime
+	return convertStandingBookingAttemptModelToSchema(attemptModel), nil
+}
+
+// Gives up an attempt in postgresql DB so its session is tried again.
+func (sb *StandingBooking) ReleasePostgresqlStandingBookingAttempt(attemptId uuid.UUID) *errors.Error {
+	if err := sb.DaoPostgresql.StandingBooking.ReleaseStandingBookingAttempt(attemptId); err != nil {
+		return &errors.InternalServerError.Default
+	}
+
+	return nil
+}
+
+// Records the outcome of an attempt and adapts it to its schema. Skipped bookings keep the code and
+// message of the error that explains them.
+func (sb *StandingBooking) FinishPostgresqlStandingBookingAttempt(
+	attempt *schemas.StandingBookingAttempt,
+	reservationId *uuid.UUID,
+	skipReason *errors.Error,
+	updatedBy string,
+) (*schemas.StandingBookingAttempt, *errors.Error) {
+	status := model.StandingBookingAttemptBooked
+	var reason, detail *string
+	if skipReason != nil {
+		status = model.StandingBookingAttemptSkipped
+		reason = &skipReason.Code
+		detail = &skipReason.Message
+	}
+
+	attemptModel, err := sb.DaoPostgresql.StandingBooking.FinishStandingBookingAttempt(
+		attempt.Id,
+		status,
+		reservationId,
+		reason,
+		detail,
+		updatedBy,
+	)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	attemptModel.Session.StartTime = attempt.SessionStartTime
+	return convertStandingBookingAttemptModelToSchema(attemptModel), nil
+}
+
+// Fetches the attempts of a standing booking from postgresql DB and adapts them to their schema.
+func (sb *StandingBooking) FetchPostgresqlStandingBookingAttempts(
+	standingBookingId uuid.UUID,
+) ([]*schemas.StandingBookingAttempt, *errors.Error) {
+	attemptModels, err := sb.DaoPostgresql.StandingBooking.FetchStandingBookingAttempts(standingBookingId)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	attempts := make([]*schemas.StandingBookingAttempt, len(attemptModels))
+	for i, attemptModel := range attemptModels {
+		attempts[i] = convertStandingBookingAttemptModelToSchema(attemptModel)
+	}
+
+	return attempts, nil
+}
+
+func convertStandingBookingModelToSchema(
+	standingBookingModel *model.StandingBooking,
+) *schemas.StandingBooking {
+	return &schemas.StandingBooking{
+		Id:             standingBookingModel.Id,
+		Weekday:        standingBookingModel.Weekday,
+		StartTime:      standingBookingModel.StartTime,
+		Active:         standingBookingModel.Active,
+		UserId:         standingBookingModel.UserId,
+		ServiceId:      standingBookingModel.ServiceId,
+		ProfessionalId: standingBookingModel.ProfessionalId,
+		MembershipId:   standingBookingModel.MembershipId,
+	}
+}
+
+func convertStandingBookingModelsToSchemas(
+	standingBookingModels []*model.StandingBooking,
+) []*schemas.StandingBooking {
+	standingBookings := make([]*schemas.StandingBooking, len(standingBookingModels))
+	for i, standingBookingModel := range standingBookingModels {
+		standingBookings[i] = convertStandingBookingModelToSchema(standingBookingModel)
+	}
+
+	return standingBookings
+}
+
+func convertStandingBookingAttemptModelToSchema(
+	attemptModel *model.StandingBookingAttempt,
+) *schemas.StandingBookingAttempt {
+	return &schemas.StandingBookingAttempt{
+		Id:                attemptModel.Id,
+		StandingBookingId: attemptModel.StandingBookingId,
+		SessionId:         attemptModel.SessionId,
+		SessionStartTime:  attemptModel.Session.StartTime,
+		Status:            string(attemptModel.Status),
+		ReservationId:     attemptModel.ReservationId,
+		Reason:            attemptModel.Reason,
+		Detail:            attemptModel.Detail,
+	}
+}
@@ -0,0 +1,75 @@
+package adapter
+
+import (
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+)
+
+type TwoFactorRecoveryCode struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewTwoFactorRecoveryCodeAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *TwoFactorRecoveryCode {
+	return &TwoFactorRecoveryCode{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+// Stores the hashes of a new set of recovery codes, invalidating the previous ones
+func (t *TwoFactorRecoveryCode) ReplacePostgresqlUserTwoFactorRecoveryCodes(
+	userId uuid.UUID,
+	codeHashes []string,
+	updatedBy string,
+) *errors.Error {
+	recoveryCodeModels := make([]*model.TwoFactorRecoveryCode, len(codeHashes))
+	for i, codeHash := range codeHashes {
+		recoveryCodeModels[i] = &model.TwoFactorRecoveryCode{
+			Id:       uuid.New(),
+			UserId:   userId,
+			CodeHash: codeHash,
+			AuditFields: model.AuditFields{
+				UpdatedBy: updatedBy,
+			},
+		}
+	}
+
+	if err := t.DaoPostgresql.TwoFactorRecoveryCode.ReplaceUserTwoFactorRecoveryCodes(
+		userId,
+		recoveryCodeModels,
+	); err != nil {
+		return &errors.BadRequestError.TwoFactorRecoveryCodeNotCreated
+	}
+
+	return nil
+}
+
+func (t *TwoFactorRecoveryCode) UsePostgresqlTwoFactorRecoveryCode(
+	userId uuid.UUID,
+	codeHash string,
+) (bool, *errors.Error) {
+	used, err := t.DaoPostgresql.TwoFactorRecoveryCode.UseTwoFactorRecoveryCode(userId, codeHash)
+	if err != nil {
+		return false, &errors.BadRequestError.TwoFactorRecoveryCodeNotUpdated
+	}
+
+	return used, nil
+}
+
+func (t *TwoFactorRecoveryCode) CountUnusedPostgresqlTwoFactorRecoveryCodes(
+	userId uuid.UUID,
+) (int, *errors.Error) {
+	count, err := t.DaoPostgresql.TwoFactorRecoveryCode.CountUnusedTwoFactorRecoveryCodes(userId)
+	if err != nil {
+		return 0, &errors.InternalServerError.DatabaseError
+	}
+
+	return int(count), nil
+}
@@ -1,6 +1,8 @@
 package adapter
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"onichankimochi.com/astro_cat_backend/src/logging"
@@ -8,20 +10,24 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
 )
 
 type User struct {
 	logger        logging.Logger
 	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+	piiCipher     *utils.PiiCipher // Encrypts the onboarding loaded or created with the user
 }
 
 func NewUserAdapter(
 	logger logging.Logger,
 	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+	piiCipher *utils.PiiCipher,
 ) *User {
 	return &User{
 		logger:        logger,
 		DaoPostgresql: daoPostgresql,
+		piiCipher:     piiCipher,
 	}
 }
 
@@ -62,34 +68,30 @@ func (u *User) GetPostgresqlUser(
 		})
 	}
 	// Mapear onboarding (si existe)
-	var onboarding *schemas.Onboarding
-	if userModel.Onboarding != nil {
-		onboarding = &schemas.Onboarding{
-			Id:             userModel.Onboarding.Id,
-			DocumentType:   schemas.DocumentType(userModel.Onboarding.DocumentType),
-			DocumentNumber: userModel.Onboarding.DocumentNumber,
-			PhoneNumber:    userModel.Onboarding.PhoneNumber,
-			BirthDate:      userModel.Onboarding.BirthDate,
-			Gender:         (*schemas.Gender)(userModel.Onboarding.Gender),
-			PostalCode:     userModel.Onboarding.PostalCode,
-			District:       userModel.Onboarding.District,
-			Province:       userModel.Onboarding.Province,
-			Region:         userModel.Onboarding.Region,
-			Address:        userModel.Onboarding.Address,
-		}
+	onboarding, onboardingErr := convertOnboardingModelToSchema(u.logger, u.piiCipher, userModel.Onboarding)
+	if onboardingErr != nil {
+		return nil, onboardingErr
 	}
 
 	return &schemas.User{
-		Id:             userModel.Id,
-		Name:           userModel.Name,
-		FirstLastName:  userModel.FirstLastName,
-		SecondLastName: userModel.SecondLastName,
-		Password:       userModel.Password,
-		Email:          userModel.Email,
-		Rol:            schemas.UserRol(userModel.Rol),
-		ImageUrl:       userModel.ImageUrl,
-		Memberships:    memberships,
-		Onboarding:     onboarding,
+		Id:                  userModel.Id,
+		Name:                userModel.Name,
+		FirstLastName:       userModel.FirstLastName,
+		SecondLastName:      userModel.SecondLastName,
+		Password:            userModel.Password,
+		TokenVersion:        userModel.TokenVersion,
+		EmailVerifiedAt:     userModel.EmailVerifiedAt,
+		FailedLoginAttempts: userModel.FailedLoginAttempts,
+		LockedUntil:         userModel.LockedUntil,
+		TwoFactorSecret:     userModel.TwoFactorSecret,
+		TwoFactorEnabledAt:  userModel.TwoFactorEnabledAt,
+		TwoFactorLastStep:   userModel.TwoFactorLastStep,
+		ErasedAt:            userModel.ErasedAt,
+		Email:               userModel.Email,
+		Rol:                 schemas.UserRol(userModel.Rol),
+		ImageUrl:            userModel.ImageUrl,
+		Memberships:         memberships,
+		Onboarding:          onboarding,
 	}, nil
 }
 
@@ -127,34 +129,30 @@ func (u *User) GetPostgresqlUserByEmail(
 		})
 	}
 	// Mapear onboarding (si existe)
-	var onboarding *schemas.Onboarding
-	if userModel.Onboarding != nil {
-		onboarding = &schemas.Onboarding{
-			Id:             userModel.Onboarding.Id,
-			DocumentType:   schemas.DocumentType(userModel.Onboarding.DocumentType),
-			DocumentNumber: userModel.Onboarding.DocumentNumber,
-			PhoneNumber:    userModel.Onboarding.PhoneNumber,
-			BirthDate:      userModel.Onboarding.BirthDate,
-			Gender:         (*schemas.Gender)(userModel.Onboarding.Gender),
-			PostalCode:     userModel.Onboarding.PostalCode,
-			District:       userModel.Onboarding.District,
-			Province:       userModel.Onboarding.Province,
-			Region:         userModel.Onboarding.Region,
-			Address:        userModel.Onboarding.Address,
-		}
+	onboarding, onboardingErr := convertOnboardingModelToSchema(u.logger, u.piiCipher, userModel.Onboarding)
+	if onboardingErr != nil {
+		return nil, onboardingErr
 	}
 
 	return &schemas.User{
-		Id:             userModel.Id,
-		Name:           userModel.Name,
-		FirstLastName:  userModel.FirstLastName,
-		SecondLastName: userModel.SecondLastName,
-		Password:       userModel.Password,
-		Email:          userModel.Email,
-		Rol:            schemas.UserRol(userModel.Rol),
-		ImageUrl:       userModel.ImageUrl,
-		Memberships:    memberships,
-		Onboarding:     onboarding,
+		Id:                  userModel.Id,
+		Name:                userModel.Name,
+		FirstLastName:       userModel.FirstLastName,
+		SecondLastName:      userModel.SecondLastName,
+		Password:            userModel.Password,
+		TokenVersion:        userModel.TokenVersion,
+		EmailVerifiedAt:     userModel.EmailVerifiedAt,
+		FailedLoginAttempts: userModel.FailedLoginAttempts,
+		LockedUntil:         userModel.LockedUntil,
+		TwoFactorSecret:     userModel.TwoFactorSecret,
+		TwoFactorEnabledAt:  userModel.TwoFactorEnabledAt,
+		TwoFactorLastStep:   userModel.TwoFactorLastStep,
+		ErasedAt:            userModel.ErasedAt,
+		Email:               userModel.Email,
+		Rol:                 schemas.UserRol(userModel.Rol),
+		ImageUrl:            userModel.ImageUrl,
+		Memberships:         memberships,
+		Onboarding:          onboarding,
 	}, nil
 }
 
@@ -191,34 +189,30 @@ func (u *User) FetchPostgresqlUsers() ([]*schemas.User, *errors.Error) {
 			})
 		}
 		// Mapear onboarding (si existe)
-		var onboarding *schemas.Onboarding
-		if userModel.Onboarding != nil {
-			onboarding = &schemas.Onboarding{
-				Id:             userModel.Onboarding.Id,
-				DocumentType:   schemas.DocumentType(userModel.Onboarding.DocumentType),
-				DocumentNumber: userModel.Onboarding.DocumentNumber,
-				PhoneNumber:    userModel.Onboarding.PhoneNumber,
-				BirthDate:      userModel.Onboarding.BirthDate,
-				Gender:         (*schemas.Gender)(userModel.Onboarding.Gender),
-				PostalCode:     userModel.Onboarding.PostalCode,
-				District:       userModel.Onboarding.District,
-				Province:       userModel.Onboarding.Province,
-				Region:         userModel.Onboarding.Region,
-				Address:        userModel.Onboarding.Address,
-			}
+		onboarding, onboardingErr := convertOnboardingModelToSchema(u.logger, u.piiCipher, userModel.Onboarding)
+		if onboardingErr != nil {
+			return nil, onboardingErr
 		}
 
 		users[i] = &schemas.User{
-			Id:             userModel.Id,
-			Name:           userModel.Name,
-			FirstLastName:  userModel.FirstLastName,
-			SecondLastName: userModel.SecondLastName,
-			Password:       userModel.Password,
-			Email:          userModel.Email,
-			Rol:            schemas.UserRol(userModel.Rol),
-			ImageUrl:       userModel.ImageUrl,
-			Memberships:    memberships,
-			Onboarding:     onboarding,
+			Id:                  userModel.Id,
+			Name:                userModel.Name,
+			FirstLastName:       userModel.FirstLastName,
+			SecondLastName:      userModel.SecondLastName,
+			Password:            userModel.Password,
+			TokenVersion:        userModel.TokenVersion,
+			EmailVerifiedAt:     userModel.EmailVerifiedAt,
+			FailedLoginAttempts: userModel.FailedLoginAttempts,
+			LockedUntil:         userModel.LockedUntil,
+			TwoFactorSecret:     userModel.TwoFactorSecret,
+			TwoFactorEnabledAt:  userModel.TwoFactorEnabledAt,
+			TwoFactorLastStep:   userModel.TwoFactorLastStep,
+			ErasedAt:            userModel.ErasedAt,
+			Email:               userModel.Email,
+			Rol:                 schemas.UserRol(userModel.Rol),
+			ImageUrl:            userModel.ImageUrl,
+			Memberships:         memberships,
+			Onboarding:          onboarding,
 		}
 	}
 
@@ -292,12 +286,18 @@ func (u *User) CreatePostgresqlUser(
 			region = &defaultRegion
 		}
 
+		var birthDate *string
+		if onboarding.BirthDate != nil {
+			formattedBirthDate := onboarding.BirthDate.Format(onboardingBirthDateLayout)
+			birthDate = &formattedBirthDate
+		}
+
 		onboardingModel = &model.Onboarding{
 			Id:             uuid.New(),
 			DocumentType:   model.DocumentType(onboarding.DocumentType),
 			DocumentNumber: onboarding.DocumentNumber,
 			PhoneNumber:    onboarding.PhoneNumber,
-			BirthDate:      onboarding.BirthDate,
+			BirthDate:      birthDate,
 			Gender:         (*model.Gender)(onboarding.Gender),
 			PostalCode:     onboarding.PostalCode,
 			District:       district,
@@ -329,6 +329,10 @@ func (u *User) CreatePostgresqlUser(
 	// Establecer la relación UserId en el onboarding
 	if onboardingModel != nil {
 		onboardingModel.UserId = userModel.Id
+		if err := encryptOnboardingFields(u.piiCipher, onboardingModel); err != nil {
+			u.logger.Error("Failed to encrypt onboarding: ", err)
+			return nil, &errors.InternalServerError.PiiEncryptionFailed
+		}
 	}
 
 	if err := u.DaoPostgresql.User.CreateUser(userModel); err != nil {
@@ -336,16 +340,24 @@ func (u *User) CreatePostgresqlUser(
 	}
 
 	return &schemas.User{
-		Id:             userModel.Id,
-		Name:           userModel.Name,
-		FirstLastName:  userModel.FirstLastName,
-		SecondLastName: userModel.SecondLastName,
-		Password:       userModel.Password,
-		Email:          userModel.Email,
-		Rol:            schemas.UserRol(userModel.Rol),
-		ImageUrl:       userModel.ImageUrl,
-		Memberships:    memberships,
-		Onboarding:     onboarding,
+		Id:                  userModel.Id,
+		Name:                userModel.Name,
+		FirstLastName:       userModel.FirstLastName,
+		SecondLastName:      userModel.SecondLastName,
+		Password:            userModel.Password,
+		TokenVersion:        userModel.TokenVersion,
+		EmailVerifiedAt:     userModel.EmailVerifiedAt,
+		FailedLoginAttempts: userModel.FailedLoginAttempts,
+		LockedUntil:         userModel.LockedUntil,
+		TwoFactorSecret:     userModel.TwoFactorSecret,
+		TwoFactorEnabledAt:  userModel.TwoFactorEnabledAt,
+		TwoFactorLastStep:   userModel.TwoFactorLastStep,
+		ErasedAt:            userModel.ErasedAt,
+		Email:               userModel.Email,
+		Rol:                 schemas.UserRol(userModel.Rol),
+		ImageUrl:            userModel.ImageUrl,
+		Memberships:         memberships,
+		Onboarding:          onboarding,
 	}, nil
 }
 
@@ -385,22 +397,30 @@ func (u *User) UpdatePostgresqlUser(
 	}
 
 	return &schemas.User{
-		Id:             userModel.Id,
-		Name:           userModel.Name,
-		FirstLastName:  userModel.FirstLastName,
-		SecondLastName: userModel.SecondLastName,
-		Password:       userModel.Password,
-		Email:          userModel.Email,
-		Rol:            schemas.UserRol(userModel.Rol),
-		ImageUrl:       userModel.ImageUrl,
-		Memberships:    memberships,
-		Onboarding:     onboarding,
+		Id:                  userModel.Id,
+		Name:                userModel.Name,
+		FirstLastName:       userModel.FirstLastName,
+		SecondLastName:      userModel.SecondLastName,
+		Password:            userModel.Password,
+		TokenVersion:        userModel.TokenVersion,
+		EmailVerifiedAt:     userModel.EmailVerifiedAt,
+		FailedLoginAttempts: userModel.FailedLoginAttempts,
+		LockedUntil:         userModel.LockedUntil,
+		TwoFactorSecret:     userModel.TwoFactorSecret,
+		TwoFactorEnabledAt:  userModel.TwoFactorEnabledAt,
+		TwoFactorLastStep:   userModel.TwoFactorLastStep,
+		ErasedAt:            userModel.ErasedAt,
+		Email:               userModel.Email,
+		Rol:                 schemas.UserRol(userModel.Rol),
+		ImageUrl:            userModel.ImageUrl,
+		Memberships:         memberships,
+		Onboarding:          onboarding,
 	}, nil
 }
 
 func (u *User) DeletePostgresqlUser(userId uuid.UUID) *errors.Error {
 	// Primero eliminar el onboarding asociado si existe
-	onboardingAdapter := NewOnboardingAdapter(u.logger, u.DaoPostgresql)
+	onboardingAdapter := NewOnboardingAdapter(u.logger, u.DaoPostgresql, u.piiCipher)
 	onboardingAdapter.DeletePostgresqlOnboardingByUserId(userId) // Ignoramos el error si no existe
 
 	// Luego eliminar el usuario
@@ -474,34 +494,30 @@ func (u *User) BulkCreatePostgresqlUser(
 			})
 		}
 		// Mapear onboarding (si existe)
-		var onboarding *schemas.Onboarding
-		if userModel.Onboarding != nil {
-			onboarding = &schemas.Onboarding{
-				Id:             userModel.Onboarding.Id,
-				DocumentType:   schemas.DocumentType(userModel.Onboarding.DocumentType),
-				DocumentNumber: userModel.Onboarding.DocumentNumber,
-				PhoneNumber:    userModel.Onboarding.PhoneNumber,
-				BirthDate:      userModel.Onboarding.BirthDate,
-				Gender:         (*schemas.Gender)(userModel.Onboarding.Gender),
-				PostalCode:     userModel.Onboarding.PostalCode,
-				District:       userModel.Onboarding.District,
-				Province:       userModel.Onboarding.Province,
-				Region:         userModel.Onboarding.Region,
-				Address:        userModel.Onboarding.Address,
-			}
+		onboarding, onboardingErr := convertOnboardingModelToSchema(u.logger, u.piiCipher, userModel.Onboarding)
+		if onboardingErr != nil {
+			return nil, onboardingErr
 		}
 
 		users[i] = &schemas.User{
-			Id:             userModel.Id,
-			Name:           userModel.Name,
-			FirstLastName:  userModel.FirstLastName,
-			SecondLastName: userModel.SecondLastName,
-			Password:       userModel.Password,
-			Email:          userModel.Email,
-			Rol:            schemas.UserRol(userModel.Rol),
-			ImageUrl:       userModel.ImageUrl,
-			Memberships:    memberships,
-			Onboarding:     onboarding,
+			Id:                  userModel.Id,
+			Name:                userModel.Name,
+			FirstLastName:       userModel.FirstLastName,
+			SecondLastName:      userModel.SecondLastName,
+			Password:            userModel.Password,
+			TokenVersion:        userModel.TokenVersion,
+			EmailVerifiedAt:     userModel.EmailVerifiedAt,
+			FailedLoginAttempts: userModel.FailedLoginAttempts,
+			LockedUntil:         userModel.LockedUntil,
+			TwoFactorSecret:     userModel.TwoFactorSecret,
+			TwoFactorEnabledAt:  userModel.TwoFactorEnabledAt,
+			TwoFactorLastStep:   userModel.TwoFactorLastStep,
+			ErasedAt:            userModel.ErasedAt,
+			Email:               userModel.Email,
+			Rol:                 schemas.UserRol(userModel.Rol),
+			ImageUrl:            userModel.ImageUrl,
+			Memberships:         memberships,
+			Onboarding:          onboarding,
 		}
 	}
 
@@ -512,7 +528,7 @@ func (u *User) BulkDeletePostgresqlUser(
 	userIds []uuid.UUID,
 ) *errors.Error {
 	// Primero eliminar los onboardings asociados si existen
-	onboardingAdapter := NewOnboardingAdapter(u.logger, u.DaoPostgresql)
+	onboardingAdapter := NewOnboardingAdapter(u.logger, u.DaoPostgresql, u.piiCipher)
 	for _, userId := range userIds {
 		onboardingAdapter.DeletePostgresqlOnboardingByUserId(userId) // Ignoramos errores si no existen
 	}
@@ -550,6 +566,123 @@ func (u *User) UpdateUserPassword(userId uuid.UUID, hashedPassword string) *erro
 	return nil
 }
 
+// Marks the email of a user as verified. Users already verified keep their original timestamp.
+func (u *User) MarkPostgresqlUserEmailVerified(userId uuid.UUID, updatedBy string) *errors.Error {
+	if err := u.DaoPostgresql.User.MarkUserEmailVerified(userId, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.UserNotFound
+		}
+		return &errors.BadRequestError.UserNotUpdated
+	}
+	return nil
+}
+
+func (u *User) SetPostgresqlUserTwoFactorSecret(
+	userId uuid.UUID,
+	secret string,
+	updatedBy string,
+) *errors.Error {
+	if err := u.DaoPostgresql.User.SetUserTwoFactorSecret(userId, secret, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.TwoFactorError.TwoFactorAlreadyEnabled
+		}
+		return &errors.BadRequestError.UserNotUpdated
+	}
+	return nil
+}
+
+func (u *User) EnablePostgresqlUserTwoFactor(
+	userId uuid.UUID,
+	step int64,
+	updatedBy string,
+) *errors.Error {
+	if err := u.DaoPostgresql.User.EnableUserTwoFactor(userId, step, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.TwoFactorError.TwoFactorNotEnrolled
+		}
+		return &errors.BadRequestError.UserNotUpdated
+	}
+	return nil
+}
+
+func (u *User) DisablePostgresqlUserTwoFactor(userId uuid.UUID, updatedBy string) *errors.Error {
+	if err := u.DaoPostgresql.User.DisableUserTwoFactor(userId, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.UserNotFound
+		}
+		return &errors.BadRequestError.UserNotUpdated
+	}
+	return nil
+}
+
+// Records a TOTP step as used, returns false if the step (or a later one) was already used
+func (u *User) UsePostgresqlUserTwoFactorStep(userId uuid.UUID, step int64) (bool, *errors.Error) {
+	accepted, err := u.DaoPostgresql.User.UseUserTwoFactorStep(userId, step)
+	if err != nil {
+		return false, &errors.BadRequestError.UserNotUpdated
+	}
+	return accepted, nil
+}
+
+// Counts a failed login of a user and returns the consecutive failures so far
+func (u *User) IncrementPostgresqlUserFailedLoginAttempts(userId uuid.UUID) (int, *errors.Error) {
+	attempts, err := u.DaoPostgresql.User.IncrementUserFailedLoginAttempts(userId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, &errors.ObjectNotFoundError.UserNotFound
+		}
+		return 0, &errors.BadRequestError.UserNotUpdated
+	}
+	return attempts, nil
+}
+
+func (u *User) LockPostgresqlUser(userId uuid.UUID, lockedUntil time.Time) *errors.Error {
+	if err := u.DaoPostgresql.User.LockUser(userId, lockedUntil); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.UserNotFound
+		}
+		return &errors.BadRequestError.UserNotUpdated
+	}
+	return nil
+}
+
+// Clears the failed logins and the lock of a user
+func (u *User) UnlockPostgresqlUser(userId uuid.UUID, updatedBy string) *errors.Error {
+	if err := u.DaoPostgresql.User.UnlockUser(userId, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.UserNotFound
+		}
+		return &errors.BadRequestError.UserNotUpdated
+	}
+	return nil
+}
+
+// Bumps the token version of a user, invalidating every access token issued before
+func (u *User) IncrementPostgresqlUserTokenVersion(userId uuid.UUID, updatedBy string) *errors.Error {
+	if err := u.DaoPostgresql.User.IncrementUserTokenVersion(userId, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.UserNotFound
+		}
+		return &errors.BadRequestError.UserNotUpdated
+	}
+	return nil
+}
+
+// Anonymizes the personal data of a user that was not erased yet
+func (u *User) ErasePostgresqlUser(
+	userId uuid.UUID,
+	anonymizedEmail string,
+	updatedBy string,
+) *errors.Error {
+	if err := u.DaoPostgresql.User.EraseUser(userId, anonymizedEmail, updatedBy); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ConflictError.UserAlreadyErased
+		}
+		return &errors.BadRequestError.UserDataNotErased
+	}
+	return nil
+}
+
 func (u *User) GetUserStats() (*schemas.UserStats, *errors.Error) {
 	// Get all users to calculate statistics
 	users, err := u.FetchPostgresqlUsers()
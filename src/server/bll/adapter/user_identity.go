@@ -0,0 +1,104 @@
+package adapter
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type UserIdentity struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewUserIdentityAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *UserIdentity {
+	return &UserIdentity{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (u *UserIdentity) CreatePostgresqlUserIdentity(
+	userId uuid.UUID,
+	provider string,
+	subject string,
+	email string,
+	updatedBy string,
+) (*schemas.UserIdentity, *errors.Error) {
+	identityModel := &model.UserIdentity{
+		Id:       uuid.New(),
+		UserId:   userId,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := u.DaoPostgresql.UserIdentity.CreateUserIdentity(identityModel); err != nil {
+		return nil, &errors.BadRequestError.UserIdentityNotCreated
+	}
+
+	return u.convertModelToSchema(identityModel), nil
+}
+
+func (u *UserIdentity) GetPostgresqlUserIdentityByProviderSubject(
+	provider string,
+	subject string,
+) (*schemas.UserIdentity, *errors.Error) {
+	identityModel, err := u.DaoPostgresql.UserIdentity.GetUserIdentityByProviderSubject(provider, subject)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.UserIdentityNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return u.convertModelToSchema(identityModel), nil
+}
+
+func (u *UserIdentity) FetchPostgresqlUserIdentitiesByUserId(
+	userId uuid.UUID,
+) ([]*schemas.UserIdentity, *errors.Error) {
+	identityModels, err := u.DaoPostgresql.UserIdentity.FetchUserIdentitiesByUserId(userId)
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	identities := make([]*schemas.UserIdentity, len(identityModels))
+	for i, identityModel := range identityModels {
+		identities[i] = u.convertModelToSchema(identityModel)
+	}
+
+	return identities, nil
+}
+
+func (u *UserIdentity) DeletePostgresqlUserIdentity(userId uuid.UUID, provider string) *errors.Error {
+	if err := u.DaoPostgresql.UserIdentity.DeleteUserIdentity(userId, provider); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.UserIdentityNotFound
+		}
+		return &errors.BadRequestError.UserIdentityNotDeleted
+	}
+
+	return nil
+}
+
+func (u *UserIdentity) convertModelToSchema(identityModel *model.UserIdentity) *schemas.UserIdentity {
+	return &schemas.UserIdentity{
+		Id:        identityModel.Id,
+		UserId:    identityModel.UserId,
+		Provider:  identityModel.Provider,
+		Subject:   identityModel.Subject,
+		Email:     identityModel.Email,
+		CreatedAt: identityModel.CreatedAt,
+	}
+}
@@ -0,0 +1,117 @@
+package adapter
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	daoPostgresql "onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type UserRoleAssignment struct {
+	logger        logging.Logger
+	DaoPostgresql *daoPostgresql.AstroCatPsqlCollection
+}
+
+func NewUserRoleAssignmentAdapter(
+	logger logging.Logger,
+	daoPostgresql *daoPostgresql.AstroCatPsqlCollection,
+) *UserRoleAssignment {
+	return &UserRoleAssignment{
+		logger:        logger,
+		DaoPostgresql: daoPostgresql,
+	}
+}
+
+func (u *UserRoleAssignment) CreatePostgresqlUserRoleAssignment(
+	userId uuid.UUID,
+	role *schemas.Role,
+	communityId *uuid.UUID,
+	updatedBy string,
+) (*schemas.UserRoleAssignment, *errors.Error) {
+	assignmentModel := &model.UserRoleAssignment{
+		Id:          uuid.New(),
+		UserId:      userId,
+		RoleId:      role.Id,
+		CommunityId: communityId,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	if err := u.DaoPostgresql.UserRoleAssignment.CreateUserRoleAssignment(assignmentModel); err != nil {
+		return nil, &errors.BadRequestError.UserRoleAssignmentNotCreated
+	}
+	assignmentModel.Role = model.Role{Name: model.UserRol(role.Name)}
+
+	return u.convertModelToSchema(assignmentModel), nil
+}
+
+func (u *UserRoleAssignment) GetPostgresqlUserRoleAssignment(
+	assignmentId uuid.UUID,
+) (*schemas.UserRoleAssignment, *errors.Error) {
+	assignmentModel, err := u.DaoPostgresql.UserRoleAssignment.GetUserRoleAssignment(assignmentId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &errors.ObjectNotFoundError.UserRoleAssignmentNotFound
+		}
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	return u.convertModelToSchema(assignmentModel), nil
+}
+
+func (u *UserRoleAssignment) FetchPostgresqlUserRoleAssignmentsByUserId(
+	userId uuid.UUID,
+) ([]*schemas.UserRoleAssignment, *errors.Error) {
+	assignmentModels, err := u.DaoPostgresql.UserRoleAssignment.FetchUserRoleAssignmentsByUserId(userId)
+	if err != nil {
+		return nil, &errors.InternalServerError.DatabaseError
+	}
+
+	assignments := make([]*schemas.UserRoleAssignment, len(assignmentModels))
+	for i, assignmentModel := range assignmentModels {
+		assignments[i] = u.convertModelToSchema(assignmentModel)
+	}
+
+	return assignments, nil
+}
+
+func (u *UserRoleAssignment) ExistsPostgresqlUserRoleAssignment(
+	userId uuid.UUID,
+	roleId uuid.UUID,
+	communityId *uuid.UUID,
+) (bool, *errors.Error) {
+	exists, err := u.DaoPostgresql.UserRoleAssignment.ExistsUserRoleAssignment(userId, roleId, communityId)
+	if err != nil {
+		return false, &errors.InternalServerError.DatabaseError
+	}
+
+	return exists, nil
+}
+
+func (u *UserRoleAssignment) DeletePostgresqlUserRoleAssignment(assignmentId uuid.UUID) *errors.Error {
+	if err := u.DaoPostgresql.UserRoleAssignment.DeleteUserRoleAssignment(assignmentId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &errors.ObjectNotFoundError.UserRoleAssignmentNotFound
+		}
+		return &errors.BadRequestError.UserRoleAssignmentNotDeleted
+	}
+
+	return nil
+}
+
+func (u *UserRoleAssignment) convertModelToSchema(
+	assignmentModel *model.UserRoleAssignment,
+) *schemas.UserRoleAssignment {
+	return &schemas.UserRoleAssignment{
+		Id:          assignmentModel.Id,
+		UserId:      assignmentModel.UserId,
+		RoleId:      assignmentModel.RoleId,
+		RoleName:    schemas.UserRol(assignmentModel.Role.Name),
+		CommunityId: assignmentModel.CommunityId,
+		CreatedAt:   assignmentModel.CreatedAt,
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
 )
 
 type Auth struct {
@@ -36,7 +37,6 @@ func NewAuthController(
 func (a *Auth) GenerateToken(
 	userId uuid.UUID,
 	userEmail string,
-	userPassword string,
 	userRoles []string,
 	expirationDelta time.Duration,
 ) (schemas.TokenResponse, *errors.Error) {
@@ -45,33 +45,94 @@ func (a *Auth) GenerateToken(
 		return schemas.TokenResponse{}, &errors.InternalServerError.Default
 	}
 
+	// Every login starts a new refresh token family, which is also its login session
+	sessionId := uuid.New()
+	if _, err := a.Adapter.LoginSession.CreatePostgresqlLoginSession(
+		sessionId,
+		userId,
+		time.Now().Add(a.EnvSettings.RefreshTokenExpiration),
+		"SYSTEM",
+	); err != nil {
+		return schemas.TokenResponse{}, err
+	}
+
+	accessToken, tokenErr := a.signAccessToken(user, userEmail, userRoles, sessionId, expirationDelta)
+	if tokenErr != nil {
+		return schemas.TokenResponse{}, tokenErr
+	}
+
+	refreshToken, refreshErr := a.issueRefreshToken(uuid.New(), userId, sessionId)
+	if refreshErr != nil {
+		return schemas.TokenResponse{}, refreshErr
+	}
+
+	return schemas.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expirationDelta,
+		SessionId:    sessionId,
+	}, nil
+}
+
+// Signs a short-lived access token for a user
+func (a *Auth) signAccessToken(
+	user *schemas.User,
+	userEmail string,
+	userRoles []string,
+	sessionId uuid.UUID,
+	expirationDelta time.Duration,
+) (string, *errors.Error) {
 	expirationTime := time.Now().Add(expirationDelta)
 
 	claims := &schemas.CustomClaims{
-		UserId:        userId,
+		UserId:        user.Id,
 		UserEmail:     userEmail,
-		UserPassword:  userPassword,
 		UserRoles:     userRoles,
+		TokenVersion:  user.TokenVersion,
 		UserName:      user.Name,
 		UserFirstName: user.FirstLastName,
 		UserLastName:  user.SecondLastName,
 		UserImageUrl:  user.ImageUrl,
+		SessionId:     sessionId,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			Audience:  jwt.ClaimStrings{schemas.AccessTokenAudience},
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	accessToken, tokenErr := token.SignedString(a.EnvSettings.TokenSignatureKey)
 	if tokenErr != nil {
-		return schemas.TokenResponse{}, &errors.InternalServerError.Default
+		return "", &errors.InternalServerError.Default
 	}
 
-	return schemas.TokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: accessToken,
-		ExpiresIn:    expirationDelta,
-	}, nil
+	return accessToken, nil
+}
+
+// Creates an opaque refresh token and stores only its hash
+func (a *Auth) issueRefreshToken(
+	refreshTokenId uuid.UUID,
+	userId uuid.UUID,
+	familyId uuid.UUID,
+) (string, *errors.Error) {
+	refreshToken, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", &errors.InternalServerError.Default
+	}
+
+	_, createErr := a.Adapter.RefreshToken.CreatePostgresqlRefreshToken(
+		refreshTokenId,
+		userId,
+		familyId,
+		utils.HashToken(refreshToken),
+		time.Now().Add(a.EnvSettings.RefreshTokenExpiration),
+		"SYSTEM",
+	)
+	if createErr != nil {
+		return "", createErr
+	}
+
+	return refreshToken, nil
 }
 
 func (a *Auth) AccessTokenValidation(
@@ -96,6 +157,7 @@ func (a *Auth) AccessTokenValidation(
 			}
 			return []byte(a.EnvSettings.TokenSignatureKey), nil
 		},
+		jwt.WithAudience(schemas.AccessTokenAudience),
 	)
 
 	if tokenErr != nil || !accessToken.Valid {
@@ -107,6 +169,16 @@ func (a *Auth) AccessTokenValidation(
 		return nil, nil, &errors.AuthenticationError.UnauthorizedUser
 	}
 
+	if credentials.SessionId != nil {
+		if err := a.Adapter.LoginSession.TouchPostgresqlLoginSession(
+			*credentials.SessionId,
+			c.RealIP(),
+			loginSessionLastSeenInterval,
+		); err != nil {
+			a.Logger.Error("Failed to record the use of a login session: ", err.Message)
+		}
+	}
+
 	return accessToken, credentials, nil
 }
 
@@ -116,7 +188,6 @@ func (a *Auth) GetCredentialByAccessToken(
 	claims := accessToken.Claims.(*schemas.CustomClaims)
 	userId := claims.UserId
 	userEmail := claims.UserEmail
-	userPassword := claims.UserPassword
 	userRoles := claims.UserRoles
 	userName := claims.UserName
 	userFirstName := claims.UserFirstName
@@ -132,37 +203,163 @@ func (a *Auth) GetCredentialByAccessToken(
 		return nil, &errors.AuthenticationError.UnauthorizedUser
 	}
 
-	if user.Password != userPassword {
+	// Tokens issued before a password change, role change or forced logout are no longer valid
+	if user.TokenVersion != claims.TokenVersion {
 		return nil, &errors.AuthenticationError.UnauthorizedUser
 	}
 
+	// Tokens of revoked sessions are rejected before they expire
+	var sessionId *uuid.UUID
+	if claims.SessionId != uuid.Nil {
+		session, err := a.Adapter.LoginSession.GetPostgresqlLoginSession(claims.SessionId)
+		if err != nil || session.UserId != userId || session.RevokedAt != nil {
+			return nil, &errors.AuthenticationError.UnauthorizedUser
+		}
+		sessionId = &session.Id
+	}
+
 	return &schemas.Credentials{
 		UserId:        userId,
 		UserEmail:     userEmail,
-		UserPassword:  userPassword,
 		UserRoles:     userRoles,
 		UserName:      userName,
 		UserFirstName: userFirstName,
 		UserLastName:  userLastName,
 		UserImageUrl:  userImageUrl,
+		SessionId:     sessionId,
 	}, nil
 }
 
-func (a *Auth) RefreshToken(accessToken *jwt.Token) (*schemas.TokenResponse, *errors.Error) {
-	credentials, err := a.GetCredentialByAccessToken(accessToken)
+// Exchanges a refresh token for a new token pair. The presented token is revoked and replaced by a
+// new one of the same family; presenting an already rotated token revokes the whole family.
+func (a *Auth) RefreshToken(refreshToken string) (*schemas.TokenResponse, *errors.Error) {
+	if refreshToken == "" {
+		return nil, &errors.AuthenticationError.InvalidRefreshToken
+	}
+
+	storedToken, err := a.Adapter.RefreshToken.GetPostgresqlRefreshTokenByHash(
+		utils.HashToken(refreshToken),
+	)
 	if err != nil {
-		return nil, &errors.AuthenticationError.UnauthorizedUser
+		return nil, &errors.AuthenticationError.InvalidRefreshToken
 	}
-	newToken, tokenErr := a.GenerateToken(
-		credentials.UserId,
-		credentials.UserEmail,
-		credentials.UserPassword,
-		credentials.UserRoles,
-		time.Hour*2,
+
+	if storedToken.RevokedAt != nil {
+		return nil, a.handleRefreshTokenReuse(storedToken)
+	}
+
+	if time.Now().After(storedToken.ExpiresAt) {
+		return nil, &errors.AuthenticationError.ExpiredRefreshToken
+	}
+
+	user, err := a.Adapter.User.GetPostgresqlUser(storedToken.UserId)
+	if err != nil {
+		return nil, &errors.AuthenticationError.InvalidRefreshToken
+	}
+
+	newRefreshTokenId := uuid.New()
+	revoked, err := a.Adapter.RefreshToken.RevokePostgresqlRefreshToken(
+		storedToken.Id,
+		&newRefreshTokenId,
+		"SYSTEM",
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// Another request rotated this token first
+		return nil, a.handleRefreshTokenReuse(storedToken)
+	}
+
+	accessToken, tokenErr := a.signAccessToken(
+		user,
+		user.Email,
+		[]string{string(user.Rol)},
+		storedToken.FamilyId,
+		a.EnvSettings.AccessTokenExpiration,
 	)
 	if tokenErr != nil {
 		return nil, tokenErr
 	}
 
-	return &newToken, nil
+	newRefreshToken, refreshErr := a.issueRefreshToken(
+		newRefreshTokenId,
+		user.Id,
+		storedToken.FamilyId,
+	)
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+
+	if err := a.Adapter.LoginSession.ExtendPostgresqlLoginSession(
+		storedToken.FamilyId,
+		time.Now().Add(a.EnvSettings.RefreshTokenExpiration),
+	); err != nil {
+		a.Logger.Error("Failed to extend login session: ", err.Message)
+	}
+
+	return &schemas.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    a.EnvSettings.AccessTokenExpiration,
+		SessionId:    storedToken.FamilyId,
+	}, nil
+}
+
+// Revokes the family of a reused refresh token
+func (a *Auth) handleRefreshTokenReuse(storedToken *schemas.RefreshToken) *errors.Error {
+	a.Logger.Warnf(
+		"Refresh token reuse detected for user %s, revoking token family %s",
+		storedToken.UserId,
+		storedToken.FamilyId,
+	)
+
+	if err := a.revokeLoginSession(storedToken); err != nil {
+		return err
+	}
+
+	return &errors.AuthenticationError.RefreshTokenReused
+}
+
+// Revokes the refresh token family of the given token, ending that login
+func (a *Auth) RevokeRefreshToken(refreshToken string) *errors.Error {
+	storedToken, err := a.Adapter.RefreshToken.GetPostgresqlRefreshTokenByHash(
+		utils.HashToken(refreshToken),
+	)
+	if err != nil {
+		return &errors.AuthenticationError.InvalidRefreshToken
+	}
+
+	return a.revokeLoginSession(storedToken)
+}
+
+// Revokes every refresh token of a user, ending all of their logins
+func (a *Auth) RevokeUserRefreshTokens(userId uuid.UUID) *errors.Error {
+	if err := a.Adapter.RefreshToken.RevokePostgresqlUserRefreshTokens(userId, "SYSTEM"); err != nil {
+		return err
+	}
+
+	return a.Adapter.LoginSession.RevokePostgresqlUserLoginSessions(userId, "SYSTEM")
+}
+
+// Revokes the refresh token family of a token and its login session
+func (a *Auth) revokeLoginSession(storedToken *schemas.RefreshToken) *errors.Error {
+	if err := a.Adapter.RefreshToken.RevokePostgresqlRefreshTokenFamily(
+		storedToken.FamilyId,
+		"SYSTEM",
+	); err != nil {
+		return err
+	}
+
+	// Families issued before login sessions existed have no session
+	err := a.Adapter.LoginSession.RevokePostgresqlLoginSession(
+		storedToken.UserId,
+		storedToken.FamilyId,
+		"SYSTEM",
+	)
+	if err != nil && err.Code != errors.ObjectNotFoundError.LoginSessionNotFound.Code {
+		return err
+	}
+
+	return nil
 }
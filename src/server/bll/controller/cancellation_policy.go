@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	bllAdapter "onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type CancellationPolicy struct {
+	logger      logging.Logger
+	Adapter     *bllAdapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+// Create CancellationPolicy controller
+func NewCancellationPolicyController(
+	logger logging.Logger,
+	adapter *bllAdapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *CancellationPolicy {
+	return &CancellationPolicy{
+		logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+// Gets a cancellation policy.
+func (cp *CancellationPolicy) GetCancellationPolicy(
+	policyId uuid.UUID,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	return cp.Adapter.CancellationPolicy.GetPostgresqlCancellationPolicy(policyId)
+}
+
+// Fetch all cancellation policies, filtered by `communityIds` if provided.
+func (cp *CancellationPolicy) FetchCancellationPolicies(
+	communityIds []string,
+) (*schemas.CancellationPolicies, *errors.Error) {
+	parsedCommunityIds := []uuid.UUID{}
+	for _, id := range communityIds {
+		parsedId, err := uuid.Parse(id)
+		if err != nil {
+			return nil, &errors.UnprocessableEntityError.InvalidCommunityId
+		}
+		parsedCommunityIds = append(parsedCommunityIds, parsedId)
+	}
+
+	policies, err := cp.Adapter.CancellationPolicy.FetchPostgresqlCancellationPolicies(parsedCommunityIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.CancellationPolicies{CancellationPolicies: policies}, nil
+}
+
+// Gets the cancellation policy that applies to a session, so users know it before booking.
+func (cp *CancellationPolicy) GetSessionCancellationPolicy(
+	sessionId uuid.UUID,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	session, err := cp.Adapter.Session.GetPostgresqlSession(sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := getSessionCancellationPolicy(cp.Adapter, session)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, &errors.ObjectNotFoundError.CancellationPolicyNotFound
+	}
+
+	return policy, nil
+}
+
+// Creates the cancellation policy of a community, or of a service of it.
+func (cp *CancellationPolicy) CreateCancellationPolicy(
+	createPolicyData schemas.CreateCancellationPolicyRequest,
+	updatedBy string,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	if createPolicyData.FreeCancellationHours < 0 ||
+		createPolicyData.NoShowLimit < 0 ||
+		createPolicyData.NoShowWindowDays < 0 {
+		return nil, &errors.BadRequestError.InvalidCancellationPolicy
+	}
+
+	if _, err := cp.Adapter.Community.GetPostgresqlCommunity(createPolicyData.CommunityId); err != nil {
+		return nil, err
+	}
+	if createPolicyData.ServiceId != nil {
+		if _, err := cp.Adapter.CommunityService.GetPostgresqlCommunityService(
+			createPolicyData.CommunityId,
+			*createPolicyData.ServiceId,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	// Each community, and each service of it, has at most one policy
+	_, err := cp.Adapter.CancellationPolicy.GetPostgresqlCancellationPolicyByScope(
+		createPolicyData.CommunityId,
+		createPolicyData.ServiceId,
+	)
+	if err == nil {
+		return nil, &errors.ConflictError.CancellationPolicyAlreadyExists
+	} else if err.Code != errors.ObjectNotFoundError.CancellationPolicyNotFound.Code {
+		return nil, err
+	}
+
+	return cp.Adapter.CancellationPolicy.CreatePostgresqlCancellationPolicy(createPolicyData, updatedBy)
+}
+
+// Updates a cancellation policy.
+func (cp *CancellationPolicy) UpdateCancellationPolicy(
+	policyId uuid.UUID,
+	updatePolicyData schemas.UpdateCancellationPolicyRequest,
+	updatedBy string,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	for _, value := range []*int{
+		updatePolicyData.FreeCancellationHours,
+		updatePolicyData.NoShowLimit,
+		updatePolicyData.NoShowWindowDays,
+	} {
+		if value != nil && *value < 0 {
+			return nil, &errors.BadRequestError.InvalidCancellationPolicy
+		}
+	}
+
+	return cp.Adapter.CancellationPolicy.UpdatePostgresqlCancellationPolicy(
+		policyId,
+		updatePolicyData,
+		updatedBy,
+	)
+}
+
+// Deletes a cancellation policy, after which cancellations of its scope are free again.
+func (cp *CancellationPolicy) DeleteCancellationPolicy(policyId uuid.UUID) *errors.Error {
+	return cp.Adapter.CancellationPolicy.DeletePostgresqlCancellationPolicy(policyId)
+}
+
+// Gets the cancellation policy that applies to a session, or nil when it has none. Sessions outside
+// a community have no policy.
+func getSessionCancellationPolicy(
+	adapter *bllAdapter.AdapterCollection,
+	session *schemas.Session,
+) (*schemas.CancellationPolicy, *errors.Error) {
+	if session.CommunityServiceId == nil {
+		return nil, nil
+	}
+
+	communityService, err := adapter.CommunityService.GetPostgresqlCommunityServiceById(
+		*session.CommunityServiceId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return adapter.CancellationPolicy.GetPostgresqlApplicableCancellationPolicy(
+		communityService.CommunityId,
+		communityService.ServiceId,
+	)
+}
+
+// Until when a reservation of a session can be cancelled without penalty, or nil when it always can
+func freeCancellationDeadline(policy *schemas.CancellationPolicy, session *schemas.Session) *time.Time {
+	if policy == nil || !policy.LateCancellationBurnsCredit {
+		return nil
+	}
+
+	deadline := session.StartTime.Add(-time.Duration(policy.FreeCancellationHours) * time.Hour)
+	return &deadline
+}
+
+// Whether a reservation moving to a state at a given time keeps its membership use as a penalty.
+// Only confirmed reservations paid with a membership have a use to keep, and only late
+// cancellations and no-shows keep it, when the policy says so.
+func shouldForfeitCredit(
+	policy *schemas.CancellationPolicy,
+	reservation *schemas.Reservation,
+	state string,
+	now time.Time,
+) bool {
+	if policy == nil || reservation.State != "CONFIRMED" || reservation.MembershipId == nil {
+		return false
+	}
+
+	switch state {
+	case "CANCELLED":
+		deadline := freeCancellationDeadline(policy, &reservation.Session)
+		return deadline != nil && now.After(*deadline)
+	case "NO_SHOW":
+		return policy.NoShowBurnsCredit
+	}
+	return false
+}
+
+// Rejects bookings of a user who missed too many sessions within the window of the policy of a
+// session. The ban lifts by itself once enough of those no-shows leave the window.
+func checkNoShowBan(
+	adapter *bllAdapter.AdapterCollection,
+	userId uuid.UUID,
+	session *schemas.Session,
+	now time.Time,
+) *errors.Error {
+	policy, err := getSessionCancellationPolicy(adapter, session)
+	if err != nil {
+		return err
+	}
+	if policy == nil || policy.NoShowLimit == 0 || policy.NoShowWindowDays == 0 {
+		return nil
+	}
+
+	noShows, err := adapter.Reservation.FetchPostgresqlNoShowTimes(
+		userId,
+		policy.CommunityId,
+		policy.ServiceId,
+		now.AddDate(0, 0, -policy.NoShowWindowDays),
+	)
+	if err != nil {
+		return err
+	}
+	if len(noShows) >= policy.NoShowLimit {
+		return &errors.ForbiddenError.BookingSuspendedForNoShows
+	}
+
+	return nil
+}
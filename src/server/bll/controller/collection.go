@@ -8,27 +8,40 @@ import (
 )
 
 type ControllerCollection struct {
-	Logger              logging.Logger
-	EnvSettings         *schemas.EnvSettings
-	Auth                *Auth
-	Login               *Login
-	Community           *Community
-	Professional        *Professional
-	Local               *Local
-	User                *User
-	Onboarding          *Onboarding
-	Membership          *Membership
-	Service             *Service
-	Plan                *Plan
-	CommunityPlan       *CommunityPlan
-	CommunityService    *CommunityService
-	ServiceLocal        *ServiceLocal
-	ServiceProfessional *ServiceProfessional
-	Session             *Session
-	Reservation         *Reservation
-	ForgotPassword      *ForgotPassword
-	Contact             *Contact
-	AuditLog            *AuditLog
+	Logger               logging.Logger
+	EnvSettings          *schemas.EnvSettings
+	Auth                 *Auth
+	EmailVerification    *EmailVerification
+	TwoFactor            *TwoFactor
+	UserIdentity         *UserIdentity
+	Login                *Login
+	LoginSession         *LoginSession
+	Community            *Community
+	Professional         *Professional
+	ProfessionalSchedule *ProfessionalSchedule
+	Local                *Local
+	User                 *User
+	Onboarding           *Onboarding
+	Membership           *Membership
+	Service              *Service
+	Plan                 *Plan
+	CommunityPlan        *CommunityPlan
+	CommunityService     *CommunityService
+	ServiceLocal         *ServiceLocal
+	ServiceProfessional  *ServiceProfessional
+	Session              *Session
+	SessionSeries        *SessionSeries
+	Reservation          *Reservation
+	StandingBooking      *StandingBooking
+	CancellationPolicy   *CancellationPolicy
+	ForgotPassword       *ForgotPassword
+	Contact              *Contact
+	AuditLog             *AuditLog
+	RateLimit            *RateLimit
+	IdempotencyKey       *IdempotencyKey
+	Role                 *Role
+	ServiceAccount       *ServiceAccount
+	Privacy              *Privacy
 }
 
 // Create bll controller collection
@@ -41,11 +54,23 @@ func NewControllerCollection(
 		envSettings,
 	)
 	auth := NewAuthController(logger, bllAdapter, envSettings)
-	login := NewLoginController(logger, bllAdapter, envSettings, auth)
+	emailVerification := NewEmailVerificationController(logger, bllAdapter, envSettings)
+	twoFactor := NewTwoFactorController(logger, bllAdapter, envSettings)
+	userIdentity := NewUserIdentityController(logger, bllAdapter, envSettings)
+	login := NewLoginController(
+		logger,
+		bllAdapter,
+		envSettings,
+		auth,
+		emailVerification,
+		twoFactor,
+		userIdentity,
+	)
+	loginSession := NewLoginSessionController(logger, bllAdapter, envSettings)
 	community := NewCommunityController(logger, bllAdapter, envSettings)
 	professional := NewProfessionalController(logger, bllAdapter, envSettings)
 	local := NewLocalController(logger, bllAdapter, envSettings)
-	user := NewUserController(logger, bllAdapter, envSettings)
+	user := NewUserController(logger, bllAdapter, envSettings, emailVerification)
 	onboarding := NewOnboardingController(logger, bllAdapter, envSettings)
 	membership := NewMembershipController(logger, bllAdapter, envSettings)
 	service := NewServiceController(logger, bllAdapter, envSettings)
@@ -54,33 +79,64 @@ func NewControllerCollection(
 	communityService := NewCommunityServiceController(logger, bllAdapter, envSettings)
 	serviceLocal := NewServiceLocalController(logger, bllAdapter, envSettings)
 	serviceProfessional := NewServiceProfessionalController(logger, bllAdapter, envSettings)
-	session := NewSessionController(logger, bllAdapter, envSettings)
 	reservation := NewReservationController(logger, bllAdapter, envSettings)
+	standingBooking := NewStandingBookingController(logger, bllAdapter, envSettings, reservation)
+	professionalSchedule := NewProfessionalScheduleController(logger, bllAdapter, envSettings)
+	session := NewSessionController(
+		logger,
+		bllAdapter,
+		envSettings,
+		standingBooking,
+		professionalSchedule,
+	)
+	sessionSeries := NewSessionSeriesController(logger, bllAdapter, envSettings, session)
+	cancellationPolicy := NewCancellationPolicyController(logger, bllAdapter, envSettings)
 	forgotPassword := NewForgotPasswordController(logger, bllAdapter, envSettings)
 	contact := NewContactController(logger, bllAdapter, envSettings)
 	auditLog := NewAuditLogController(logger, bllAdapter, envSettings)
+	rateLimit := NewRateLimitController(logger, bllAdapter, envSettings)
+	idempotencyKey := NewIdempotencyKeyController(logger, bllAdapter, envSettings)
+	role := NewRoleController(logger, bllAdapter, envSettings)
+	if err := role.SeedDefaultRoles(); err != nil {
+		logger.Error("Failed to seed default roles: ", err.Message)
+	}
+	serviceAccount := NewServiceAccountController(logger, bllAdapter, envSettings)
+	privacy := NewPrivacyController(logger, bllAdapter, envSettings)
 
 	return &ControllerCollection{
-		Logger:              logger,
-		EnvSettings:         envSettings,
-		Auth:                auth,
-		Login:               login,
-		Community:           community,
-		Professional:        professional,
-		Local:               local,
-		User:                user,
-		Onboarding:          onboarding,
-		Membership:          membership,
-		Service:             service,
-		Plan:                plan,
-		CommunityPlan:       communityPlan,
-		CommunityService:    communityService,
-		ServiceLocal:        serviceLocal,
-		ServiceProfessional: serviceProfessional,
-		Session:             session,
-		Reservation:         reservation,
-		ForgotPassword:      forgotPassword,
-		Contact:             contact,
-		AuditLog:            auditLog,
+		Logger:               logger,
+		EnvSettings:          envSettings,
+		Auth:                 auth,
+		EmailVerification:    emailVerification,
+		TwoFactor:            twoFactor,
+		UserIdentity:         userIdentity,
+		Login:                login,
+		LoginSession:         loginSession,
+		Community:            community,
+		Professional:         professional,
+		ProfessionalSchedule: professionalSchedule,
+		Local:                local,
+		User:                 user,
+		Onboarding:           onboarding,
+		Membership:           membership,
+		Service:              service,
+		Plan:                 plan,
+		CommunityPlan:        communityPlan,
+		CommunityService:     communityService,
+		ServiceLocal:         serviceLocal,
+		ServiceProfessional:  serviceProfessional,
+		Session:              session,
+		SessionSeries:        sessionSeries,
+		Reservation:          reservation,
+		StandingBooking:      standingBooking,
+		CancellationPolicy:   cancellationPolicy,
+		ForgotPassword:       forgotPassword,
+		Contact:              contact,
+		AuditLog:             auditLog,
+		RateLimit:            rateLimit,
+		IdempotencyKey:       idempotencyKey,
+		Role:                 role,
+		ServiceAccount:       serviceAccount,
+		Privacy:              privacy,
 	}, astroCatPsqlDB
 }
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+type EmailVerification struct {
+	Logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+func NewEmailVerificationController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *EmailVerification {
+	return &EmailVerification{
+		Logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+// Creates a new verification code for a user, superseding any previous one, and emails it.
+// Only the code hash is stored.
+func (ev *EmailVerification) SendVerificationCode(user *schemas.User) *errors.Error {
+	code, codeErr := utils.GenerateNumericCode(6)
+	if codeErr != nil {
+		return &errors.InternalServerError.Default
+	}
+
+	codeHash, hashErr := utils.HashPassword(code)
+	if hashErr != nil {
+		return &errors.InternalServerError.Default
+	}
+
+	if err := ev.Adapter.EmailVerification.ConsumePostgresqlUserEmailVerifications(
+		user.Id,
+		"SYSTEM",
+	); err != nil {
+		return err
+	}
+
+	if _, err := ev.Adapter.EmailVerification.CreatePostgresqlEmailVerification(
+		user.Id,
+		codeHash,
+		time.Now().Add(ev.EnvSettings.EmailVerificationCodeExpiration),
+		"SYSTEM",
+	); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hola %s,\n\nTu código de verificación es: %s\n\nEl código vence en %d minutos.\n\nSaludos,\nAstrocat 🐾",
+		user.Name,
+		code,
+		int(ev.EnvSettings.EmailVerificationCodeExpiration.Minutes()),
+	)
+
+	// Don't fail the request if email service is not configured, the code can be resent
+	if emailErr := utils.SendEmail(ev.EnvSettings, user.Email, "Verifica tu correo", body); emailErr != nil {
+		ev.Logger.Warnf("Failed to send verification email: %v", emailErr)
+	}
+
+	return nil
+}
+
+// Sends a new verification code to a user that has not verified their email yet. Unknown and
+// already verified emails get the same response, so it can not tell which emails have an account.
+func (ev *EmailVerification) ResendVerificationCode(
+	email string,
+) (*schemas.EmailVerificationResponse, *errors.Error) {
+	response := &schemas.EmailVerificationResponse{
+		Message: "Si el correo está registrado y sin verificar, se envió un nuevo código de verificación",
+	}
+
+	user, err := ev.Adapter.User.GetPostgresqlUserByEmail(email)
+	if err != nil || user.EmailVerifiedAt != nil {
+		return response, nil
+	}
+
+	if err := ev.SendVerificationCode(user); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Verifies the email of a user with the code sent to it. After too many wrong codes the
+// verification is locked and a new code must be requested.
+func (ev *EmailVerification) VerifyEmail(
+	request schemas.VerifyEmailRequest,
+) (*schemas.EmailVerificationResponse, *errors.Error) {
+	user, err := ev.Adapter.User.GetPostgresqlUserByEmail(request.Email)
+	if err != nil {
+		return nil, &errors.EmailVerificationError.InvalidOrExpiredCode
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil, &errors.EmailVerificationError.EmailAlreadyVerified
+	}
+
+	emailVerification, err := ev.Adapter.EmailVerification.GetActivePostgresqlEmailVerificationByUserId(
+		user.Id,
+	)
+	if err != nil {
+		return nil, &errors.EmailVerificationError.InvalidOrExpiredCode
+	}
+
+	if emailVerification.Attempts >= ev.EnvSettings.EmailVerificationMaxAttempts {
+		return nil, &errors.TooManyRequestsError.EmailVerificationLocked
+	}
+
+	if hashErr := utils.CheckPasswordHash(request.Code, emailVerification.CodeHash); hashErr != nil {
+		attempts, err := ev.Adapter.EmailVerification.IncrementPostgresqlEmailVerificationAttempts(
+			emailVerification.Id,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if attempts >= ev.EnvSettings.EmailVerificationMaxAttempts {
+			if err := ev.Adapter.EmailVerification.ConsumePostgresqlUserEmailVerifications(
+				user.Id,
+				"SYSTEM",
+			); err != nil {
+				return nil, err
+			}
+			return nil, &errors.TooManyRequestsError.EmailVerificationLocked
+		}
+
+		return nil, &errors.EmailVerificationError.InvalidOrExpiredCode
+	}
+
+	if err := ev.Adapter.User.MarkPostgresqlUserEmailVerified(user.Id, "SYSTEM"); err != nil {
+		return nil, err
+	}
+
+	if err := ev.Adapter.EmailVerification.ConsumePostgresqlUserEmailVerifications(
+		user.Id,
+		"SYSTEM",
+	); err != nil {
+		return nil, err
+	}
+
+	return &schemas.EmailVerificationResponse{
+		Message: "Correo verificado correctamente",
+	}, nil
+}
+
+// Applies the verified email policy, which blocks reservations and memberships for unverified
+// accounts when enabled.
+func checkVerifiedEmailPolicy(envSettings *schemas.EnvSettings, user *schemas.User) *errors.Error {
+	if envSettings.RequireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return &errors.ForbiddenError.EmailNotVerified
+	}
+
+	return nil
+}
@@ -2,7 +2,7 @@ package controller
 
 import (
 	"fmt"
-	"math/rand"
+	"time"
 
 	"onichankimochi.com/astro_cat_backend/src/logging"
 	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
@@ -17,9 +17,6 @@ type ForgotPassword struct {
 	EnvSettings *schemas.EnvSettings
 }
 
-// guardo el pin
-var resetPins = map[string]string{}
-
 func NewForgotPasswordController(
 	logger logging.Logger,
 	adapter *adapter.AdapterCollection,
@@ -40,10 +37,17 @@ func (fp *ForgotPassword) GenerateResetPin(
 		return nil, err
 	}
 
-	pin := fmt.Sprintf("%06d", rand.Intn(1000000))
-	resetPins[user.Email] = pin
+	pin, err := fp.issueResetPin(user, schemas.PasswordResetChannelEmail)
+	if err != nil {
+		return nil, err
+	}
 
-	body := fmt.Sprintf("Hola %s,\n\nTu código de recuperación es: %s\n\nSaludos,\nAstrocat 🐾", user.Name, pin)
+	body := fmt.Sprintf(
+		"Hola %s,\n\nTu código de recuperación es: %s\n\nEl código vence en %d minutos.\n\nSaludos,\nAstrocat 🐾",
+		user.Name,
+		pin,
+		int(fp.EnvSettings.PasswordResetPinExpiration.Minutes()),
+	)
 
 	// Try to send email, but don't fail the request if email service is not configured
 	if emailErr := utils.SendEmail(fp.EnvSettings, user.Email, "Recuperación de contraseña", body); emailErr != nil {
@@ -54,7 +58,6 @@ func (fp *ForgotPassword) GenerateResetPin(
 
 	return &schemas.ForgotPasswordResponse{
 		Message: "Código enviado al correo",
-		Pin:     pin, // solo para curso/test
 	}, nil
 }
 
@@ -67,16 +70,20 @@ func (fp *ForgotPassword) SendResetPinBySMS(
 		return nil, &errors.ForgotPasswordError.InvalidEmail
 	}
 
-	// Verifica que haya completado el onboarding para enviar el SM
+	// Verifica que haya completado el onboarding para enviar el SMS
 	onboarding, err := fp.Adapter.Onboarding.GetPostgresqlOnboardingByUserId(user.Id)
-	if err != nil {
-		return nil, &errors.ForgotPasswordError.InvalidEmail
+	if err != nil || onboarding.PhoneNumber == "" {
+		return nil, &errors.ForgotPasswordError.MissingPhoneNumber
 	}
 
 	// Generar PIN de 6 dígitos
+	pin, err := fp.issueResetPin(user, schemas.PasswordResetChannelSms)
+	if err != nil {
+		return nil, err
+	}
 
 	// Enviar por SMS
-	errSMS := utils.SendPINBySMS(onboarding.PhoneNumber, resetPins[user.Email])
+	errSMS := utils.SendPINBySMS(onboarding.PhoneNumber, pin)
 	if errSMS != nil {
 		return nil, &errors.ForgotPasswordError.FailedToSendSMS
 	}
@@ -85,3 +92,106 @@ func (fp *ForgotPassword) SendResetPinBySMS(
 		Message: "Código enviado por SMS",
 	}, nil
 }
+
+// Verifies a reset PIN and sets the new password. After too many wrong PINs the reset is locked
+// and a new PIN must be requested.
+func (fp *ForgotPassword) ResetPassword(
+	request schemas.ResetPasswordRequest,
+) (*schemas.ForgotPasswordResponse, *errors.Error) {
+	user, err := fp.Adapter.User.GetPostgresqlUserByEmail(request.Email)
+	if err != nil {
+		return nil, &errors.ForgotPasswordError.InvalidOrExpiredPin
+	}
+
+	passwordReset, err := fp.Adapter.PasswordReset.GetActivePostgresqlPasswordResetByUserId(user.Id)
+	if err != nil {
+		return nil, &errors.ForgotPasswordError.InvalidOrExpiredPin
+	}
+
+	if passwordReset.Attempts >= fp.EnvSettings.PasswordResetMaxAttempts {
+		return nil, &errors.TooManyRequestsError.PasswordResetLocked
+	}
+
+	if hashErr := utils.CheckPasswordHash(request.Pin, passwordReset.PinHash); hashErr != nil {
+		attempts, err := fp.Adapter.PasswordReset.IncrementPostgresqlPasswordResetAttempts(
+			passwordReset.Id,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if attempts >= fp.EnvSettings.PasswordResetMaxAttempts {
+			if err := fp.Adapter.PasswordReset.ConsumePostgresqlPasswordReset(
+				passwordReset.Id,
+				"SYSTEM",
+			); err != nil {
+				return nil, err
+			}
+			return nil, &errors.TooManyRequestsError.PasswordResetLocked
+		}
+
+		return nil, &errors.ForgotPasswordError.InvalidOrExpiredPin
+	}
+
+	hashedPassword, hashErr := utils.HashPassword(request.NewPassword)
+	if hashErr != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	if err := fp.Adapter.User.UpdateUserPassword(user.Id, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	// Invalidates every access token issued with the old password
+	if err := fp.Adapter.User.IncrementPostgresqlUserTokenVersion(user.Id, "SYSTEM"); err != nil {
+		return nil, err
+	}
+
+	if err := fp.Adapter.PasswordReset.ConsumePostgresqlUserPasswordResets(user.Id, "SYSTEM"); err != nil {
+		return nil, err
+	}
+
+	if err := fp.Adapter.RefreshToken.RevokePostgresqlUserRefreshTokens(user.Id, "SYSTEM"); err != nil {
+		return nil, err
+	}
+
+	if err := fp.Adapter.LoginSession.RevokePostgresqlUserLoginSessions(user.Id, "SYSTEM"); err != nil {
+		return nil, err
+	}
+
+	return &schemas.ForgotPasswordResponse{
+		Message: "Contraseña actualizada correctamente",
+	}, nil
+}
+
+// Creates a new reset PIN for a user, superseding any previous one. Only the PIN hash is stored.
+func (fp *ForgotPassword) issueResetPin(
+	user *schemas.User,
+	channel schemas.PasswordResetChannel,
+) (string, *errors.Error) {
+	pin, pinErr := utils.GenerateNumericCode(6)
+	if pinErr != nil {
+		return "", &errors.InternalServerError.Default
+	}
+
+	pinHash, hashErr := utils.HashPassword(pin)
+	if hashErr != nil {
+		return "", &errors.InternalServerError.Default
+	}
+
+	if err := fp.Adapter.PasswordReset.ConsumePostgresqlUserPasswordResets(user.Id, "SYSTEM"); err != nil {
+		return "", err
+	}
+
+	if _, err := fp.Adapter.PasswordReset.CreatePostgresqlPasswordReset(
+		user.Id,
+		pinHash,
+		channel,
+		time.Now().Add(fp.EnvSettings.PasswordResetPinExpiration),
+		"SYSTEM",
+	); err != nil {
+		return "", err
+	}
+
+	return pin, nil
+}
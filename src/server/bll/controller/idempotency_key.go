@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+const (
+	// Longest accepted Idempotency-Key header
+	idempotencyKeyMaxLength = 255
+	// How long a request may hold its key before a retry can take it over
+	idempotencyKeyLockTimeout = time.Minute
+)
+
+type IdempotencyKey struct {
+	logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+	mutex       sync.Mutex
+	lastSweep   time.Time
+}
+
+// Create IdempotencyKey controller
+func NewIdempotencyKeyController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *IdempotencyKey {
+	return &IdempotencyKey{
+		logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+		lastSweep:   time.Now(),
+	}
+}
+
+// Claims the key of a request of a caller. Returns nil when the request should run, or the stored
+// key whose response must be replayed when a previous request with the same key and body finished.
+// A key reused with another body, or whose first request is still running, is rejected.
+func (i *IdempotencyKey) Begin(
+	scope string,
+	key string,
+	requestHash string,
+) (*schemas.IdempotencyKey, *errors.Error) {
+	if key == "" || len(key) > idempotencyKeyMaxLength {
+		return nil, &errors.UnprocessableEntityError.InvalidIdempotencyKey
+	}
+
+	i.sweepExpired()
+
+	stored, err := i.Adapter.IdempotencyKey.ClaimPostgresqlIdempotencyKey(
+		scope,
+		key,
+		requestHash,
+		i.EnvSettings.IdempotencyKeyTtl,
+		idempotencyKeyLockTimeout,
+	)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	if stored.RequestHash != requestHash {
+		return nil, &errors.UnprocessableEntityError.IdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return nil, &errors.ConflictError.IdempotencyKeyInProgress
+	}
+
+	return stored, nil
+}
+
+// Stores the response of the request that claimed a key, to be replayed to its retries
+func (i *IdempotencyKey) Complete(
+	scope string,
+	key string,
+	statusCode int,
+	contentType string,
+	responseBody []byte,
+) *errors.Error {
+	return i.Adapter.IdempotencyKey.CompletePostgresqlIdempotencyKey(
+		scope,
+		key,
+		statusCode,
+		contentType,
+		responseBody,
+	)
+}
+
+// Frees the key of a request that failed without a response worth replaying, so a retry runs again
+func (i *IdempotencyKey) Release(scope string, key string) *errors.Error {
+	return i.Adapter.IdempotencyKey.ReleasePostgresqlIdempotencyKey(scope, key)
+}
+
+// Deletes expired keys at most once per hour. Failures are ignored since expired keys are taken
+// over on their own when reused.
+func (i *IdempotencyKey) sweepExpired() {
+	i.mutex.Lock()
+	if time.Since(i.lastSweep) < time.Hour {
+		i.mutex.Unlock()
+		return
+	}
+	i.lastSweep = time.Now()
+	i.mutex.Unlock()
+
+	_, _ = i.Adapter.IdempotencyKey.DeleteExpiredPostgresqlIdempotencyKeys()
+}
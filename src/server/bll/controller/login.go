@@ -2,10 +2,9 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
-	"google.golang.org/api/idtoken"
-
 	"onichankimochi.com/astro_cat_backend/src/logging"
 	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
@@ -14,10 +13,13 @@ import (
 )
 
 type Login struct {
-	Logger      logging.Logger
-	Adapter     *adapter.AdapterCollection
-	EnvSettings *schemas.EnvSettings
-	Auth        *Auth
+	Logger            logging.Logger
+	Adapter           *adapter.AdapterCollection
+	EnvSettings       *schemas.EnvSettings
+	Auth              *Auth
+	EmailVerification *EmailVerification
+	TwoFactor         *TwoFactor
+	UserIdentity      *UserIdentity
 }
 
 func NewLoginController(
@@ -25,12 +27,18 @@ func NewLoginController(
 	adapter *adapter.AdapterCollection,
 	envSettings *schemas.EnvSettings,
 	auth *Auth,
+	emailVerification *EmailVerification,
+	twoFactor *TwoFactor,
+	userIdentity *UserIdentity,
 ) *Login {
 	return &Login{
-		Logger:      logger,
-		Adapter:     adapter,
-		EnvSettings: envSettings,
-		Auth:        auth,
+		Logger:            logger,
+		Adapter:           adapter,
+		EnvSettings:       envSettings,
+		Auth:              auth,
+		EmailVerification: emailVerification,
+		TwoFactor:         twoFactor,
+		UserIdentity:      userIdentity,
 	}
 }
 
@@ -43,37 +51,216 @@ func (l *Login) Login(
 		return nil, &errors.AuthenticationError.UnauthorizedUser
 	}
 
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return nil, &errors.TooManyRequestsError.AccountLocked
+	}
+
 	if err := utils.CheckPasswordHash(password, user.Password); err != nil {
-		return nil, &errors.AuthenticationError.UnauthorizedUser
+		return nil, l.registerFailedLogin(user)
+	}
+
+	// A successful login ends any expired lock and restarts the failure count
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := l.Adapter.User.UnlockPostgresqlUser(user.Id, "SYSTEM"); err != nil {
+			return nil, err
+		}
+		if user.LockedUntil != nil {
+			logUserSecurityEvent(
+				l.Logger,
+				l.Adapter,
+				user,
+				schemas.AuditActionAccountUnlocked,
+				"Lock expired, unlocked on successful login",
+			)
+		}
+	}
+
+	// Accounts with two-factor authentication only get a challenge until the second step is passed
+	if user.TwoFactorEnabledAt != nil || l.TwoFactor.IsRequired(user) {
+		challenge, err := l.TwoFactor.IssueChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &schemas.LoginResponse{
+			User:               newUserProfile(user),
+			TwoFactorChallenge: challenge,
+		}, nil
+	}
+
+	return l.completeLogin(user, nil)
+}
+
+// Second step of a login with two-factor authentication. Takes the challenge of the password step
+// and a TOTP or recovery code. Users that must enrol confirm their new secret with the code and
+// get their recovery codes along with the tokens.
+func (l *Login) LoginWithTwoFactor(
+	request schemas.TwoFactorLoginRequest,
+) (*schemas.LoginResponse, *errors.Error) {
+	user, err := l.TwoFactor.ParseChallenge(request.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return nil, &errors.TooManyRequestsError.AccountLocked
+	}
+
+	var recoveryCodes []string
+	var factorErr *errors.Error
+	if user.TwoFactorEnabledAt == nil {
+		var enrollment *schemas.TwoFactorRecoveryCodesResponse
+		enrollment, factorErr = l.TwoFactor.ConfirmEnrollment(user.Id, request.Code)
+		if factorErr == nil {
+			enabledAt := time.Now()
+			user.TwoFactorEnabledAt = &enabledAt
+			recoveryCodes = enrollment.RecoveryCodes
+		}
+	} else {
+		factorErr = l.TwoFactor.VerifyLoginFactor(user, request.Code, request.RecoveryCode)
 	}
 
+	if factorErr != nil {
+		if *factorErr != errors.TwoFactorError.InvalidTwoFactorCode {
+			return nil, factorErr
+		}
+		// Wrong codes count towards the same lockout as wrong passwords
+		if lockErr := l.registerFailedLogin(user); *lockErr == errors.TooManyRequestsError.AccountLocked {
+			return nil, lockErr
+		}
+		return nil, factorErr
+	}
+
+	if user.FailedLoginAttempts > 0 {
+		if err := l.Adapter.User.UnlockPostgresqlUser(user.Id, "SYSTEM"); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.completeLogin(user, recoveryCodes)
+}
+
+// Starts the mandatory enrolment of a user that got a challenge without having two-factor
+// authentication set up yet
+func (l *Login) BeginTwoFactorEnrollment(
+	challengeToken string,
+) (*schemas.TwoFactorEnrollmentResponse, *errors.Error) {
+	user, err := l.TwoFactor.ParseChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.TwoFactor.BeginEnrollment(user.Id)
+}
+
+// Issues the tokens of a user that passed every step of the login
+func (l *Login) completeLogin(
+	user *schemas.User,
+	recoveryCodes []string,
+) (*schemas.LoginResponse, *errors.Error) {
 	userRoles := []string{string(user.Rol)}
 
 	tokenResponse, tokenErr := l.Auth.GenerateToken(
 		user.Id,
 		user.Email,
-		user.Password,
 		userRoles,
-		time.Hour*2,
+		l.EnvSettings.AccessTokenExpiration,
 	)
 	if tokenErr != nil {
 		return nil, tokenErr
 	}
 
 	return &schemas.LoginResponse{
-		User: schemas.UserProfile{
-			Id:             user.Id,
-			Name:           user.Name,
-			FirstLastName:  user.FirstLastName,
-			SecondLastName: user.SecondLastName,
-			Email:          user.Email,
-			Rol:            user.Rol,
-			ImageUrl:       user.ImageUrl,
-		},
-		Tokens: tokenResponse,
+		User:          newUserProfile(user),
+		Tokens:        tokenResponse,
+		RecoveryCodes: recoveryCodes,
 	}, nil
 }
 
+func newUserProfile(user *schemas.User) schemas.UserProfile {
+	return schemas.UserProfile{
+		Id:                 user.Id,
+		Name:               user.Name,
+		FirstLastName:      user.FirstLastName,
+		SecondLastName:     user.SecondLastName,
+		Email:              user.Email,
+		Rol:                user.Rol,
+		ImageUrl:           user.ImageUrl,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		TwoFactorEnabledAt: user.TwoFactorEnabledAt,
+	}
+}
+
+// Counts a failed login and locks the account every time the failures reach a multiple of the
+// max attempts. Each consecutive lock doubles the previous lockout, up to the configured max.
+func (l *Login) registerFailedLogin(user *schemas.User) *errors.Error {
+	attempts, err := l.Adapter.User.IncrementPostgresqlUserFailedLoginAttempts(user.Id)
+	if err != nil {
+		return err
+	}
+
+	maxAttempts := l.EnvSettings.LoginMaxFailedAttempts
+	if attempts%maxAttempts != 0 {
+		return &errors.AuthenticationError.UnauthorizedUser
+	}
+
+	lockout := l.EnvSettings.LoginLockoutDuration
+	for i := 1; i < attempts/maxAttempts && lockout < l.EnvSettings.LoginMaxLockoutDuration; i++ {
+		lockout *= 2
+	}
+	if lockout > l.EnvSettings.LoginMaxLockoutDuration {
+		lockout = l.EnvSettings.LoginMaxLockoutDuration
+	}
+
+	lockedUntil := time.Now().Add(lockout)
+	if err := l.Adapter.User.LockPostgresqlUser(user.Id, lockedUntil); err != nil {
+		return err
+	}
+
+	logUserSecurityEvent(
+		l.Logger,
+		l.Adapter,
+		user,
+		schemas.AuditActionAccountLocked,
+		fmt.Sprintf(
+			"Locked until %s after %d failed login attempts",
+			lockedUntil.Format(time.RFC3339),
+			attempts,
+		),
+	)
+
+	return &errors.TooManyRequestsError.AccountLocked
+}
+
+// Writes a security event of a user account to the audit log. Failures are only logged so they never
+// block a login.
+func logUserSecurityEvent(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	user *schemas.User,
+	action schemas.AuditActionType,
+	info string,
+) {
+	entityName := user.Email
+	event := schemas.AuditEvent{
+		Action:         action,
+		EntityType:     schemas.AuditEntityUser,
+		EntityId:       &user.Id,
+		EntityName:     &entityName,
+		AdditionalInfo: &info,
+		Success:        true,
+	}
+	auditContext := schemas.AuditContext{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		UserRole:  user.Rol,
+	}
+
+	if err := adapter.AuditLog.LogAuditEvent(auditContext, event); err != nil {
+		logger.Error("Failed to log user security event: ", err)
+	}
+}
+
 func (l *Login) Register(
 	name string,
 	firstLastName string,
@@ -103,91 +290,168 @@ func (l *Login) Register(
 		return nil, err
 	}
 
+	// The account stays usable while unverified, the code can be resent if this fails
+	if err := l.EmailVerification.SendVerificationCode(user); err != nil {
+		l.Logger.Warnf("Failed to issue email verification code: %v", err.Message)
+	}
+
 	userRoles := []string{string(user.Rol)}
 
 	tokenResponse, tokenErr := l.Auth.GenerateToken(
 		user.Id,
 		user.Email,
-		user.Password,
 		userRoles,
-		time.Hour*2,
+		l.EnvSettings.AccessTokenExpiration,
 	)
 	if tokenErr != nil {
 		return nil, tokenErr
 	}
 
 	return &schemas.LoginResponse{
-		User: schemas.UserProfile{
-			Id:             user.Id,
-			Name:           user.Name,
-			FirstLastName:  user.FirstLastName,
-			SecondLastName: user.SecondLastName,
-			Email:          user.Email,
-			Rol:            user.Rol,
-			ImageUrl:       user.ImageUrl,
-		},
+		User:   newUserProfile(user),
 		Tokens: tokenResponse,
 	}, nil
 }
 
+// Logs in with a Google ID token, kept for clients that predate the generic OpenID Connect login
 func (l *Login) GoogleLogin(
 	ctx context.Context,
 	idToken string,
 ) (*schemas.GoogleLoginResponse, *errors.Error) {
-	// 1. Validar token de Google
-	payload, err := idtoken.Validate(ctx, idToken, "")
+	response, err := l.OidcLogin(ctx, "google", idToken)
 	if err != nil {
-		l.Logger.Error("Token de Google inválido:", err)
-		return nil, &errors.AuthenticationError.InvalidAccessToken
+		return nil, err
 	}
 
-	// 2. Extraer datos del token
-	email, _ := payload.Claims["email"].(string)
-	name, _ := payload.Claims["name"].(string)
-	picture, _ := payload.Claims["picture"].(string)
+	return (*schemas.GoogleLoginResponse)(response), nil
+}
 
-	// 3. Buscar usuario por email
-	user, userErr := l.Adapter.User.GetPostgresqlUserByEmail(email)
-	if userErr != nil {
-		// 4. Crear usuario si no existe
-		newUser, createErr := l.Adapter.User.CreatePostgresqlUser(
-			name,
-			"",  // firstLastName
-			nil, // secondLastName
-			"",  // password
-			email,
-			string(schemas.UserRolClient),
-			picture,
-			"SYSTEM",
-			nil,
-			nil,
-		)
-		if createErr != nil {
-			l.Logger.Error("Error al crear usuario con Google:", createErr)
-			return nil, &errors.InternalServerError.Default
+// Logs in with an ID token of a configured OpenID Connect provider. Users are found by their
+// linked identity first and by email otherwise, in which case the identity is linked. Unknown
+// emails get a new client account.
+func (l *Login) OidcLogin(
+	ctx context.Context,
+	provider string,
+	idToken string,
+) (*schemas.OidcLoginResponse, *errors.Error) {
+	// 1. Validar el ID token con las llaves del proveedor
+	claims, err := l.UserIdentity.VerifyIdToken(ctx, provider, idToken)
+	if err != nil {
+		return nil, err
+	}
+	emailVerified := isEmailVerifiedClaim(claims.EmailVerified)
+
+	// 2. Buscar el usuario por la identidad vinculada o por email
+	user, err := l.findOidcUser(provider, claims, emailVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider already verified the email, so there is no need to send a code
+	if emailVerified && user.EmailVerifiedAt == nil && user.Email == claims.Email {
+		if err := l.Adapter.User.MarkPostgresqlUserEmailVerified(user.Id, "SYSTEM"); err != nil {
+			return nil, err
 		}
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+	}
 
-		// reconstruir user localmente (porque Create no lo retorna)
-		user = newUser
+	// The provider only proves the first factor, accounts with two-factor authentication get a challenge
+	if user.TwoFactorEnabledAt != nil || l.TwoFactor.IsRequired(user) {
+		challenge, err := l.TwoFactor.IssueChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &schemas.OidcLoginResponse{
+			User:               *user,
+			TwoFactorChallenge: challenge,
+		}, nil
 	}
 
 	userRoles := []string{string(user.Rol)}
 
-	// 5. Generar tokens JWT
+	// 3. Generar tokens JWT
 	tokenResponse, tokenErr := l.Auth.GenerateToken(
 		user.Id,
 		user.Email,
-		user.Password,
 		userRoles,
-		time.Hour*2,
+		l.EnvSettings.AccessTokenExpiration,
 	)
 	if tokenErr != nil {
 		return nil, tokenErr
 	}
 
-	// 6. Retornar estructura de respuesta
-	return &schemas.GoogleLoginResponse{
+	return &schemas.OidcLoginResponse{
 		User:   *user,
 		Tokens: tokenResponse,
 	}, nil
 }
+
+// Finds the user of an ID token, linking the identity to the account with the same email or to a
+// new account. Identities are only linked by email when the provider verified it, otherwise
+// anyone could claim an account by registering its email at the provider.
+func (l *Login) findOidcUser(
+	provider string,
+	claims *schemas.OidcIdTokenClaims,
+	emailVerified bool,
+) (*schemas.User, *errors.Error) {
+	identity, err := l.Adapter.UserIdentity.GetPostgresqlUserIdentityByProviderSubject(
+		provider,
+		claims.Subject,
+	)
+	if err == nil {
+		return l.Adapter.User.GetPostgresqlUser(identity.UserId)
+	}
+	if err.Code != errors.ObjectNotFoundError.UserIdentityNotFound.Code {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, &errors.AuthenticationError.InvalidIdToken
+	}
+
+	user, userErr := l.Adapter.User.GetPostgresqlUserByEmail(claims.Email)
+	if userErr == nil {
+		if !emailVerified {
+			return nil, &errors.ForbiddenError.UnverifiedIdentityEmail
+		}
+	} else {
+		// Crear usuario si no existe
+		newUser, createErr := l.Adapter.User.CreatePostgresqlUser(
+			claims.Name,
+			"",  // firstLastName
+			nil, // secondLastName
+			"",  // password
+			claims.Email,
+			string(schemas.UserRolClient),
+			claims.Picture,
+			"SYSTEM",
+			nil,
+			nil,
+		)
+		if createErr != nil {
+			l.Logger.Error("Error al crear usuario con "+provider+":", createErr)
+			return nil, &errors.InternalServerError.Default
+		}
+		user = newUser
+	}
+
+	if _, err := l.UserIdentity.linkIdentity(user, provider, claims, "SYSTEM"); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// The email_verified claim is a boolean, but some providers encode it as a string
+func isEmailVerifiedClaim(claim any) bool {
+	switch value := claim.(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
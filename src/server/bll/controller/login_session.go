@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+const (
+	// Minimum time between two writes of the last use of a login session
+	loginSessionLastSeenInterval = time.Minute
+	// Longest user agent kept for a session
+	loginSessionMaxUserAgentLength = 512
+)
+
+type LoginSession struct {
+	Logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+func NewLoginSessionController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *LoginSession {
+	return &LoginSession{
+		Logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+// Records the device and address of the request that started a login session
+func (l *LoginSession) RecordDevice(c echo.Context, sessionId uuid.UUID) {
+	if sessionId == uuid.Nil {
+		return
+	}
+
+	userAgent := c.Request().UserAgent()
+	if len(userAgent) > loginSessionMaxUserAgentLength {
+		userAgent = userAgent[:loginSessionMaxUserAgentLength]
+	}
+
+	if err := l.Adapter.LoginSession.UpdatePostgresqlLoginSessionDevice(
+		sessionId,
+		utils.DescribeUserAgent(userAgent),
+		userAgent,
+		c.RealIP(),
+	); err != nil {
+		l.Logger.Error("Failed to record the device of a login session: ", err.Message)
+	}
+}
+
+// Gets the active sessions of a user, marking the one of the caller
+func (l *LoginSession) FetchUserSessions(
+	userId uuid.UUID,
+	currentSessionId *uuid.UUID,
+) (*schemas.LoginSessions, *errors.Error) {
+	sessions, err := l.Adapter.LoginSession.FetchPostgresqlActiveLoginSessionsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = currentSessionId != nil && session.Id == *currentSessionId
+	}
+
+	return &schemas.LoginSessions{Sessions: sessions}, nil
+}
+
+// Revokes a session of a user, its access tokens stop working right away
+func (l *LoginSession) RevokeUserSession(
+	userId uuid.UUID,
+	sessionId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	user, err := l.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if err := l.Adapter.LoginSession.RevokePostgresqlLoginSession(
+		userId,
+		sessionId,
+		updatedBy,
+	); err != nil {
+		return err
+	}
+
+	logUserSecurityEvent(
+		l.Logger,
+		l.Adapter,
+		user,
+		schemas.AuditActionSessionRevoked,
+		"Revoked login session "+sessionId.String()+" by "+updatedBy,
+	)
+
+	return nil
+}
+
+// Revokes every session of a user except the given one, e.g. after noticing an unknown device
+func (l *LoginSession) RevokeOtherUserSessions(
+	userId uuid.UUID,
+	currentSessionId *uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	sessions, err := l.Adapter.LoginSession.FetchPostgresqlActiveLoginSessionsByUserId(userId)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if currentSessionId != nil && session.Id == *currentSessionId {
+			continue
+		}
+		if err := l.RevokeUserSession(userId, session.Id, updatedBy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
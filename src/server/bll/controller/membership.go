@@ -68,11 +68,15 @@ func (m *Membership) CreateMembership(
 	updatedBy string,
 ) (*schemas.Membership, *errors.Error) {
 	// Validar que el usuario existe
-	_, userErr := m.Adapter.User.GetPostgresqlUser(createMembershipRequest.UserId)
+	user, userErr := m.Adapter.User.GetPostgresqlUser(createMembershipRequest.UserId)
 	if userErr != nil {
 		return nil, userErr
 	}
 
+	if policyErr := checkVerifiedEmailPolicy(m.EnvSettings, user); policyErr != nil {
+		return nil, policyErr
+	}
+
 	// Validar que la comunidad existe
 	_, communityErr := m.Adapter.Community.GetPostgresqlCommunity(createMembershipRequest.CommunityId)
 	if communityErr != nil {
@@ -122,11 +126,15 @@ func (m *Membership) CreateMembershipForUser(
 	updatedBy string,
 ) (*schemas.Membership, *errors.Error) {
 	// Validar que el usuario existe antes de crear la membership
-	_, userErr := m.Adapter.User.GetPostgresqlUser(userId)
+	user, userErr := m.Adapter.User.GetPostgresqlUser(userId)
 	if userErr != nil {
 		return nil, userErr
 	}
 
+	if policyErr := checkVerifiedEmailPolicy(m.EnvSettings, user); policyErr != nil {
+		return nil, policyErr
+	}
+
 	// Validar que la comunidad existe
 	_, communityErr := m.Adapter.Community.GetPostgresqlCommunity(createMembershipForUserRequest.CommunityId)
 	if communityErr != nil {
@@ -295,3 +303,108 @@ func (m *Membership) GetUsersByCommunityId(communityId uuid.UUID) (*schemas.User
 func (m *Membership) DeleteMembership(membershipId uuid.UUID) *errors.Error {
 	return m.Adapter.Membership.DeletePostgresqlMembership(membershipId)
 }
+
+// Checks that the caller owns a membership or can access the ones of other users in its community
+func (m *Membership) CheckMembershipAccess(
+	credentials *schemas.Credentials,
+	membershipId uuid.UUID,
+) *errors.Error {
+	if credentials == nil || isOwnershipExempt(credentials) {
+		return nil
+	}
+
+	membership, err := m.Adapter.Membership.GetPostgresqlMembership(membershipId)
+	if err != nil {
+		return err
+	}
+	if membership.UserId == credentials.UserId || canActForOtherUsers(
+		m.logger,
+		m.Adapter,
+		credentials,
+		schemas.PermissionMembershipAnyUser,
+		schemas.PermissionResources{CommunityId: &membership.CommunityId},
+	) {
+		return nil
+	}
+
+	return checkResourceOwner(
+		m.logger,
+		m.Adapter,
+		credentials,
+		membership.UserId,
+		schemas.AuditEntityMembership,
+		membership.Id,
+	)
+}
+
+// Checks that the caller only reads or creates memberships of their own user, unless they can
+// access the memberships of other users in the given community, or everywhere when it is nil
+func (m *Membership) CheckUserMembershipsAccess(
+	credentials *schemas.Credentials,
+	userId uuid.UUID,
+	communityId *uuid.UUID,
+) *errors.Error {
+	if credentials != nil && credentials.UserId != userId && canActForOtherUsers(
+		m.logger,
+		m.Adapter,
+		credentials,
+		schemas.PermissionMembershipAnyUser,
+		schemas.PermissionResources{CommunityId: communityId},
+	) {
+		return nil
+	}
+
+	return checkResourceOwner(
+		m.logger,
+		m.Adapter,
+		credentials,
+		userId,
+		schemas.AuditEntityMembership,
+		userId,
+	)
+}
+
+// Checks that the caller owns a membership and does not move it to another user
+func (m *Membership) CheckUpdateMembershipAccess(
+	credentials *schemas.Credentials,
+	membershipId uuid.UUID,
+	request schemas.UpdateMembershipRequest,
+) *errors.Error {
+	if err := m.CheckMembershipAccess(credentials, membershipId); err != nil {
+		return err
+	}
+	if request.UserId != nil {
+		return m.CheckUserMembershipsAccess(credentials, *request.UserId, request.CommunityId)
+	}
+
+	return nil
+}
+
+// Keeps only the memberships of the caller and the ones of the communities where they can access
+// the memberships of other users. Administrators see all of them.
+func (m *Membership) FilterOwnedMemberships(
+	credentials *schemas.Credentials,
+	memberships *schemas.Memberships,
+) *schemas.Memberships {
+	if credentials == nil || isOwnershipExempt(credentials) {
+		return memberships
+	}
+
+	scope, err := getPermissionScope(m.Adapter, credentials.UserId, schemas.PermissionMembershipAnyUser)
+	if err != nil {
+		m.logger.Warnf("Failed to get the membership grants of user %s: %v", credentials.UserId, err.Message)
+		scope = &permissionScope{}
+	}
+	if scope.everywhere {
+		return memberships
+	}
+
+	owned := []*schemas.Membership{}
+	for _, membership := range memberships.Memberships {
+		if membership.UserId == credentials.UserId || scope.communityIds[membership.CommunityId] {
+			owned = append(owned, membership)
+		}
+	}
+
+	return &schemas.Memberships{Memberships: owned}
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	bllAdapter "onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// Resolves the membership a user books a session with. A given membership must pass every
+// entitlement check. Without one, the best eligible membership of the user for the community of the
+// session is picked. When there is none the booking goes without membership, unless
+// `requireMembership` is set, in which case the reason a membership of that community is not
+// eligible is returned.
+//
+// Sessions outside a community need no membership and skip the community check.
+func resolveBookingMembership(
+	adapter *bllAdapter.AdapterCollection,
+	requireMembership bool,
+	userId uuid.UUID,
+	session *schemas.Session,
+	membershipId *uuid.UUID,
+) (*uuid.UUID, *errors.Error) {
+	communityId, err := getSessionCommunityId(adapter, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if membershipId != nil {
+		membership, err := adapter.Membership.GetPostgresqlMembership(*membershipId)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMembershipEntitlement(membership, userId, communityId, session); err != nil {
+			return nil, err
+		}
+		return membershipId, nil
+	}
+
+	if communityId == nil {
+		return nil, nil
+	}
+
+	memberships, err := adapter.Membership.GetPostgresqlMembershipsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible, closestErr := []*schemas.Membership{}, &errors.ForbiddenError.NoEligibleMembership
+	for _, membership := range memberships {
+		if membership.CommunityId != *communityId {
+			continue
+		}
+		if err := checkMembershipEntitlement(membership, userId, communityId, session); err != nil {
+			closestErr = err
+			continue
+		}
+		eligible = append(eligible, membership)
+	}
+
+	if len(eligible) == 0 {
+		if requireMembership {
+			return nil, closestErr
+		}
+		return nil, nil
+	}
+
+	return &pickBestMembership(eligible).Id, nil
+}
+
+// Runs every entitlement check of a membership for a booking of a session by a user. The checks go
+// from the most to the least permanent failure so the error tells what would fix it.
+func checkMembershipEntitlement(
+	membership *schemas.Membership,
+	userId uuid.UUID,
+	communityId *uuid.UUID,
+	session *schemas.Session,
+) *errors.Error {
+	if membership.UserId != userId {
+		return &errors.ForbiddenError.MembershipOfAnotherUser
+	}
+	if communityId != nil && membership.CommunityId != *communityId {
+		return &errors.ForbiddenError.MembershipCommunityMismatch
+	}
+	if membership.Status != schemas.MembershipStatusActive {
+		return &errors.ForbiddenError.MembershipNotActive
+	}
+
+	sessionDay := calendarDay(session.Date)
+	if sessionDay.Before(calendarDay(membership.StartDate)) || sessionDay.After(calendarDay(membership.EndDate)) {
+		return &errors.ForbiddenError.MembershipOutOfPeriod
+	}
+
+	if remainingReservations(membership) == 0 {
+		return &errors.ForbiddenError.MembershipReservationLimitReached
+	}
+
+	return nil
+}
+
+// Checks the membership whose use a late cancelled reservation kept still entitles its user to the
+// session. Booking the reservation again spends the kept use, so it does not count against the limit.
+func checkForfeitedMembershipEntitlement(
+	adapter *bllAdapter.AdapterCollection,
+	userId uuid.UUID,
+	session *schemas.Session,
+	membershipId uuid.UUID,
+) *errors.Error {
+	communityId, err := getSessionCommunityId(adapter, session)
+	if err != nil {
+		return err
+	}
+
+	membership, err := adapter.Membership.GetPostgresqlMembership(membershipId)
+	if err != nil {
+		return err
+	}
+	if membership.ReservationsUsed != nil && *membership.ReservationsUsed > 0 {
+		reservationsUsed := *membership.ReservationsUsed - 1
+		membership.ReservationsUsed = &reservationsUsed
+	}
+
+	return checkMembershipEntitlement(membership, userId, communityId, session)
+}
+
+// Picks the membership to spend first: the one ending soonest, then the one with the fewest
+// reservations left, so what would be lost first is used first.
+func pickBestMembership(memberships []*schemas.Membership) *schemas.Membership {
+	// Unlimited memberships are spent last
+	remaining := func(membership *schemas.Membership) int {
+		if left := remainingReservations(membership); left >= 0 {
+			return left
+		}
+		return math.MaxInt
+	}
+
+	sort.SliceStable(memberships, func(i, j int) bool {
+		a, b := memberships[i], memberships[j]
+		if !calendarDay(a.EndDate).Equal(calendarDay(b.EndDate)) {
+			return a.EndDate.Before(b.EndDate)
+		}
+		if remaining(a) != remaining(b) {
+			return remaining(a) < remaining(b)
+		}
+		return a.Id.String() < b.Id.String()
+	})
+
+	return memberships[0]
+}
+
+// Reservations a membership can still make, or -1 when it is unlimited
+func remainingReservations(membership *schemas.Membership) int {
+	if membership.ReservationsUsed == nil || membership.Plan.ReservationLimit == nil {
+		return -1
+	}
+
+	return max(*membership.Plan.ReservationLimit-*membership.ReservationsUsed, 0)
+}
+
+func getSessionCommunityId(
+	adapter *bllAdapter.AdapterCollection,
+	session *schemas.Session,
+) (*uuid.UUID, *errors.Error) {
+	if session.CommunityServiceId == nil {
+		return nil, nil
+	}
+
+	communityService, err := adapter.CommunityService.GetPostgresqlCommunityServiceById(
+		*session.CommunityServiceId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &communityService.CommunityId, nil
+}
+
+// Date part of a time, so membership periods include their whole first and last days
+func calendarDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
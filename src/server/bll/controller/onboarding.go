@@ -34,6 +34,21 @@ func (o *Onboarding) GetOnboardingByUserId(userId uuid.UUID) (*schemas.Onboardin
 	return o.Adapter.Onboarding.GetPostgresqlOnboardingByUserId(userId)
 }
 
+// Finds the onboarding with a document. The number is matched through its blind index since it is
+// stored encrypted.
+func (o *Onboarding) GetOnboardingByDocument(
+	documentType schemas.DocumentType,
+	documentNumber string,
+) (*schemas.Onboarding, *errors.Error) {
+	switch documentType {
+	case schemas.DocumentTypeDNI, schemas.DocumentTypeForeignerCard, schemas.DocumentTypePassport:
+	default:
+		return nil, &errors.UnprocessableEntityError.InvalidDocumentType
+	}
+
+	return o.Adapter.Onboarding.GetPostgresqlOnboardingByDocument(documentType, documentNumber)
+}
+
 func (o *Onboarding) FetchOnboardings() (*schemas.Onboardings, *errors.Error) {
 	onboardings, err := o.Adapter.Onboarding.FetchPostgresqlOnboardings()
 	if err != nil {
@@ -129,3 +144,61 @@ func (o *Onboarding) DeleteOnboarding(onboardingId uuid.UUID) *errors.Error {
 func (o *Onboarding) DeleteOnboardingByUserId(userId uuid.UUID) *errors.Error {
 	return o.Adapter.Onboarding.DeletePostgresqlOnboardingByUserId(userId)
 }
+
+// Checks that the caller owns an onboarding
+func (o *Onboarding) CheckOnboardingAccess(
+	credentials *schemas.Credentials,
+	onboardingId uuid.UUID,
+) *errors.Error {
+	if credentials == nil || isOwnershipExempt(credentials) {
+		return nil
+	}
+
+	onboarding, err := o.Adapter.Onboarding.GetPostgresqlOnboarding(onboardingId)
+	if err != nil {
+		return err
+	}
+
+	return checkResourceOwner(
+		o.logger,
+		o.Adapter,
+		credentials,
+		onboarding.UserId,
+		schemas.AuditEntityOnboarding,
+		onboarding.Id,
+	)
+}
+
+// Checks that the caller only manages the onboarding of their own user
+func (o *Onboarding) CheckUserOnboardingAccess(
+	credentials *schemas.Credentials,
+	userId uuid.UUID,
+) *errors.Error {
+	return checkResourceOwner(
+		o.logger,
+		o.Adapter,
+		credentials,
+		userId,
+		schemas.AuditEntityOnboarding,
+		userId,
+	)
+}
+
+// Keeps only the onboarding of the caller, administrators see all of them
+func (o *Onboarding) FilterOwnedOnboardings(
+	credentials *schemas.Credentials,
+	onboardings *schemas.Onboardings,
+) *schemas.Onboardings {
+	if credentials == nil || isOwnershipExempt(credentials) {
+		return onboardings
+	}
+
+	owned := []*schemas.Onboarding{}
+	for _, onboarding := range onboardings.Onboardings {
+		if onboarding.UserId == credentials.UserId {
+			owned = append(owned, onboarding)
+		}
+	}
+
+	return &schemas.Onboardings{Onboardings: owned}
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+const (
+	// Domain of the placeholder emails of erased users, reserved so it can never receive mail
+	erasedUserEmailDomain = "erased.invalid"
+	// Page size used to read every audit log entry of a user
+	dataExportAuditLogPageSize = 200
+)
+
+type Privacy struct {
+	Logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+func NewPrivacyController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *Privacy {
+	return &Privacy{
+		Logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+// Gathers the personal data held about a user. Reservations and memberships are included whatever
+// their state, together with every audit log entry of the user.
+func (p *Privacy) ExportUserData(userId uuid.UUID, requestedBy string) (*schemas.UserDataExport, *errors.Error) {
+	user, err := p.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	onboarding, err := p.Adapter.Onboarding.GetPostgresqlOnboardingByUserId(userId)
+	if err != nil {
+		if err.Code != errors.ObjectNotFoundError.OnboardingNotFound.Code {
+			return nil, err
+		}
+		onboarding = nil
+	}
+
+	memberships, err := p.Adapter.Membership.GetPostgresqlMembershipsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := p.Adapter.Reservation.FetchPostgresqlReservations([]uuid.UUID{userId}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := p.Adapter.UserIdentity.FetchPostgresqlUserIdentitiesByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	loginSessions, err := p.Adapter.LoginSession.FetchPostgresqlActiveLoginSessionsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogs, err := p.fetchUserAuditLogs(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	logUserSecurityEvent(
+		p.Logger,
+		p.Adapter,
+		user,
+		schemas.AuditActionDataExported,
+		"Exported by "+requestedBy,
+	)
+
+	return &schemas.UserDataExport{
+		GeneratedAt: time.Now(),
+		User: &schemas.UserProfile{
+			Id:                 user.Id,
+			Name:               user.Name,
+			FirstLastName:      user.FirstLastName,
+			SecondLastName:     user.SecondLastName,
+			Email:              user.Email,
+			Rol:                user.Rol,
+			ImageUrl:           user.ImageUrl,
+			EmailVerifiedAt:    user.EmailVerifiedAt,
+			TwoFactorEnabledAt: user.TwoFactorEnabledAt,
+		},
+		ErasedAt:      user.ErasedAt,
+		Onboarding:    onboarding,
+		Memberships:   memberships,
+		Reservations:  reservations,
+		Identities:    identities,
+		LoginSessions: loginSessions,
+		AuditLogs:     auditLogs,
+	}, nil
+}
+
+// Reads every page of the audit log entries of a user
+func (p *Privacy) fetchUserAuditLogs(userId uuid.UUID) ([]*schemas.AuditLog, *errors.Error) {
+	auditLogs := []*schemas.AuditLog{}
+	for page := 1; ; page++ {
+		result, err := p.Adapter.AuditLog.GetAuditLogs(schemas.AuditLogFilters{
+			UserIds:  []string{userId.String()},
+			Page:     page,
+			PageSize: dataExportAuditLogPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		auditLogs = append(auditLogs, result.AuditLogs...)
+		if page >= result.TotalPages {
+			return auditLogs, nil
+		}
+	}
+}
+
+// Packs a data export in a ZIP archive with one JSON file per section, plus the whole export
+func (p *Privacy) BuildDataExportArchive(export *schemas.UserDataExport) ([]byte, *errors.Error) {
+	sections := []struct {
+		name string
+		data any
+	}{
+		{"user.json", export.User},
+		{"onboarding.json", export.Onboarding},
+		{"memberships.json", export.Memberships},
+		{"reservations.json", export.Reservations},
+		{"identities.json", export.Identities},
+		{"login_sessions.json", export.LoginSessions},
+		{"audit_logs.json", export.AuditLogs},
+		{"export.json", export},
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, section := range sections {
+		content, marshalErr := json.MarshalIndent(section.data, "", "  ")
+		if marshalErr != nil {
+			p.Logger.Error("Failed to encode the data export: ", marshalErr)
+			return nil, &errors.InternalServerError.Default
+		}
+
+		file, createErr := archive.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if createErr != nil {
+			p.Logger.Error("Failed to write the data export archive: ", createErr)
+			return nil, &errors.InternalServerError.Default
+		}
+		if _, writeErr := file.Write(content); writeErr != nil {
+			p.Logger.Error("Failed to write the data export archive: ", writeErr)
+			return nil, &errors.InternalServerError.Default
+		}
+	}
+	if closeErr := archive.Close(); closeErr != nil {
+		p.Logger.Error("Failed to write the data export archive: ", closeErr)
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Erases the personal data of the current user once they confirm their password. Accounts created
+// through a login provider have no password and skip the confirmation.
+func (p *Privacy) EraseCurrentUserData(userId uuid.UUID, request schemas.DataErasureRequest) *errors.Error {
+	user, err := p.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return err
+	}
+	if user.ErasedAt != nil {
+		return &errors.ConflictError.UserAlreadyErased
+	}
+
+	if user.Password != "" {
+		if hashErr := utils.CheckPasswordHash(request.Password, user.Password); hashErr != nil {
+			return &errors.AuthenticationError.InvalidPasswordConfirmation
+		}
+	}
+
+	// The email of the user is personal data too, so it is not kept as the author of the change
+	return p.eraseUserData(user, anonymizedUserEmail(user.Id))
+}
+
+// Erases the personal data of a user on their behalf
+func (p *Privacy) EraseUserData(userId uuid.UUID, updatedBy string) *errors.Error {
+	user, err := p.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return err
+	}
+	if user.ErasedAt != nil {
+		return &errors.ConflictError.UserAlreadyErased
+	}
+
+	return p.eraseUserData(user, updatedBy)
+}
+
+// Anonymizes the user and their onboarding while reservations and memberships stay in place for
+// reporting. The account can no longer be used to log in.
+func (p *Privacy) eraseUserData(user *schemas.User, updatedBy string) *errors.Error {
+	anonymizedEmail := anonymizedUserEmail(user.Id)
+	if err := p.Adapter.User.ErasePostgresqlUser(user.Id, anonymizedEmail, updatedBy); err != nil {
+		return err
+	}
+
+	erasedUser := *user
+	erasedUser.Email = anonymizedEmail
+	logUserSecurityEvent(
+		p.Logger,
+		p.Adapter,
+		&erasedUser,
+		schemas.AuditActionDataErased,
+		"Erased by "+updatedBy,
+	)
+
+	return nil
+}
+
+func anonymizedUserEmail(userId uuid.UUID) string {
+	return fmt.Sprintf("erased-%s@%s", userId, erasedUserEmailDomain)
+}
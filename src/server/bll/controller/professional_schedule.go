@@ -0,0 +1,328 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	bllAdapter "onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type ProfessionalSchedule struct {
+	logger      logging.Logger
+	Adapter     *bllAdapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+// Create ProfessionalSchedule controller
+func NewProfessionalScheduleController(
+	logger logging.Logger,
+	adapter *bllAdapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *ProfessionalSchedule {
+	return &ProfessionalSchedule{
+		logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+// Gets the weekly working hours of a professional.
+func (ps *ProfessionalSchedule) GetWorkingHours(
+	professionalId uuid.UUID,
+) (*schemas.ProfessionalWorkingHours, *errors.Error) {
+	if _, err := ps.Adapter.Professional.GetPostgresqlProfessional(professionalId); err != nil {
+		return nil, err
+	}
+
+	workingHours, err := ps.Adapter.ProfessionalSchedule.FetchPostgresqlWorkingHours(professionalId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.ProfessionalWorkingHours{
+		ProfessionalId: professionalId,
+		WorkingHours:   workingHours,
+	}, nil
+}
+
+// Replaces the weekly working hours of a professional. Without ranges the professional can lead
+// sessions at any time. Sessions already scheduled are left as they are.
+func (ps *ProfessionalSchedule) UpdateWorkingHours(
+	professionalId uuid.UUID,
+	request schemas.UpdateWorkingHoursRequest,
+	updatedBy string,
+) (*schemas.ProfessionalWorkingHours, *errors.Error) {
+	if _, err := ps.Adapter.Professional.GetPostgresqlProfessional(professionalId); err != nil {
+		return nil, err
+	}
+
+	workingHours, err := normalizeWorkingHours(request.WorkingHours)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ps.Adapter.ProfessionalSchedule.ReplacePostgresqlWorkingHours(
+		professionalId,
+		workingHours,
+		updatedBy,
+	); err != nil {
+		return nil, err
+	}
+
+	return &schemas.ProfessionalWorkingHours{
+		ProfessionalId: professionalId,
+		WorkingHours:   workingHours,
+	}, nil
+}
+
+// Fetch the time off of a professional.
+func (ps *ProfessionalSchedule) FetchTimeOff(
+	professionalId uuid.UUID,
+) (*schemas.ProfessionalTimeOffList, *errors.Error) {
+	if _, err := ps.Adapter.Professional.GetPostgresqlProfessional(professionalId); err != nil {
+		return nil, err
+	}
+
+	timeOff, err := ps.Adapter.ProfessionalSchedule.FetchPostgresqlProfessionalTimeOff(professionalId, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.ProfessionalTimeOffList{TimeOff: timeOff}, nil
+}
+
+// Creates a time off of a professional. Sessions already scheduled within it are left as they are.
+func (ps *ProfessionalSchedule) CreateTimeOff(
+	professionalId uuid.UUID,
+	request schemas.CreateProfessionalTimeOffRequest,
+	updatedBy string,
+) (*schemas.ProfessionalTimeOff, *errors.Error) {
+	if !request.EndTime.After(request.StartTime) {
+		return nil, &errors.BadRequestError.InvalidProfessionalTimeOff
+	}
+	if _, err := ps.Adapter.Professional.GetPostgresqlProfessional(professionalId); err != nil {
+		return nil, err
+	}
+
+	return ps.Adapter.ProfessionalSchedule.CreatePostgresqlProfessionalTimeOff(
+		professionalId,
+		request,
+		updatedBy,
+	)
+}
+
+// Deletes a time off of a professional.
+func (ps *ProfessionalSchedule) DeleteTimeOff(professionalId uuid.UUID, timeOffId uuid.UUID) *errors.Error {
+	timeOff, err := ps.Adapter.ProfessionalSchedule.GetPostgresqlProfessionalTimeOff(timeOffId)
+	if err != nil {
+		return err
+	}
+	if timeOff.ProfessionalId != professionalId {
+		return &errors.ObjectNotFoundError.ProfessionalTimeOffNotFound
+	}
+
+	return ps.Adapter.ProfessionalSchedule.DeletePostgresqlProfessionalTimeOff(timeOffId)
+}
+
+// Checks that the caller is the professional whose time off is managed, unless it is exempt.
+// Professionals are matched to their user by email.
+func (ps *ProfessionalSchedule) CheckTimeOffAccess(
+	credentials *schemas.Credentials,
+	professionalId uuid.UUID,
+) *errors.Error {
+	if credentials == nil || isOwnershipExempt(credentials) {
+		return nil
+	}
+
+	professional, err := ps.Adapter.Professional.GetPostgresqlProfessional(professionalId)
+	if err != nil {
+		return err
+	}
+
+	ownerId := uuid.Nil
+	if user, err := ps.Adapter.User.GetPostgresqlUserByEmail(professional.Email); err == nil {
+		ownerId = user.Id
+	}
+
+	return checkResourceOwner(
+		ps.logger,
+		ps.Adapter,
+		credentials,
+		ownerId,
+		schemas.AuditEntityProfessional,
+		professionalId,
+	)
+}
+
+// Checks a time range against the schedule of a professional. Gets whether it falls outside their
+// working hours and the time off it overlaps.
+func (ps *ProfessionalSchedule) CheckSchedule(
+	professionalId uuid.UUID,
+	startTime time.Time,
+	endTime time.Time,
+) (bool, []*schemas.ProfessionalTimeOff, *errors.Error) {
+	workingHours, err := ps.Adapter.ProfessionalSchedule.FetchPostgresqlWorkingHours(professionalId)
+	if err != nil {
+		return false, nil, err
+	}
+	timeOff, err := ps.Adapter.ProfessionalSchedule.FetchPostgresqlProfessionalTimeOff(
+		professionalId,
+		&startTime,
+		&endTime,
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	outsideWorkingHours := len(workingHours) > 0 && !ps.isWithinWorkingHours(workingHours, startTime, endTime)
+	return outsideWorkingHours, timeOff, nil
+}
+
+// Gets the slots of a day in which a professional cannot lead sessions: those outside their
+// working hours and their time off. Times are in the booking time zone, the end of the day being
+// 24:00.
+func (ps *ProfessionalSchedule) GetUnavailableSlots(
+	professionalId uuid.UUID,
+	date time.Time,
+) ([]schemas.TimeSlot, *errors.Error) {
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, ps.EnvSettings.BookingTimeZone)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	workingHours, err := ps.Adapter.ProfessionalSchedule.FetchPostgresqlWorkingHours(professionalId)
+	if err != nil {
+		return nil, err
+	}
+	timeOff, err := ps.Adapter.ProfessionalSchedule.FetchPostgresqlProfessionalTimeOff(
+		professionalId,
+		&dayStart,
+		&dayEnd,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []schemas.TimeSlot{}
+	if len(workingHours) > 0 {
+		// Working hours are sorted and do not overlap, so the gaps between them are unavailable
+		cursor := 0
+		for _, hours := range workingHours {
+			if hours.Weekday != int(dayStart.Weekday()) {
+				continue
+			}
+			start, end := clockMinutes(hours.StartTime), clockMinutes(hours.EndTime)
+			if start > cursor {
+				slots = append(slots, outsideWorkingHoursSlot(cursor, start))
+			}
+			cursor = end
+		}
+		if cursor < minutesPerDay {
+			slots = append(slots, outsideWorkingHoursSlot(cursor, minutesPerDay))
+		}
+	}
+
+	for _, absence := range timeOff {
+		start, end := 0, minutesPerDay
+		if absence.StartTime.After(dayStart) {
+			start = int(absence.StartTime.Sub(dayStart).Minutes())
+		}
+		if absence.EndTime.Before(dayEnd) {
+			end = int(absence.EndTime.Sub(dayStart).Minutes())
+		}
+
+		title := "Time off"
+		if absence.Reason != nil && *absence.Reason != "" {
+			title = *absence.Reason
+		}
+		slots = append(slots, schemas.TimeSlot{
+			Start: formatClockMinutes(start),
+			End:   formatClockMinutes(end),
+			Title: title,
+			Type:  "time_off",
+		})
+	}
+
+	return slots, nil
+}
+
+// Whether a time range falls, in the booking time zone, within one of the working hours of its day
+func (ps *ProfessionalSchedule) isWithinWorkingHours(
+	workingHours []*schemas.WorkingHours,
+	startTime time.Time,
+	endTime time.Time,
+) bool {
+	localStart := startTime.In(ps.EnvSettings.BookingTimeZone)
+	localEnd := endTime.In(ps.EnvSettings.BookingTimeZone)
+
+	start := localStart.Hour()*60 + localStart.Minute()
+	end := int(localEnd.Sub(localStart).Minutes()) + start
+	for _, hours := range workingHours {
+		if hours.Weekday == int(localStart.Weekday()) &&
+			clockMinutes(hours.StartTime) <= start &&
+			end <= clockMinutes(hours.EndTime) {
+			return true
+		}
+	}
+	return false
+}
+
+const minutesPerDay = 24 * 60
+
+// Validates working hours and sorts them by weekday and time, with their times as HH:MM
+func normalizeWorkingHours(workingHours []*schemas.WorkingHours) ([]*schemas.WorkingHours, *errors.Error) {
+	normalized := make([]*schemas.WorkingHours, len(workingHours))
+	for i, hours := range workingHours {
+		if hours == nil || hours.Weekday < 0 || hours.Weekday > 6 {
+			return nil, &errors.BadRequestError.InvalidWorkingHours
+		}
+		startTime, startErr := time.Parse("15:04", hours.StartTime)
+		endTime, endErr := time.Parse("15:04", hours.EndTime)
+		if startErr != nil || endErr != nil || !endTime.After(startTime) {
+			return nil, &errors.BadRequestError.InvalidWorkingHours
+		}
+
+		normalized[i] = &schemas.WorkingHours{
+			Weekday:   hours.Weekday,
+			StartTime: startTime.Format("15:04"),
+			EndTime:   endTime.Format("15:04"),
+		}
+	}
+
+	sort.Slice(normalized, func(i, j int) bool {
+		if normalized[i].Weekday != normalized[j].Weekday {
+			return normalized[i].Weekday < normalized[j].Weekday
+		}
+		return normalized[i].StartTime < normalized[j].StartTime
+	})
+	for i := 1; i < len(normalized); i++ {
+		if normalized[i].Weekday == normalized[i-1].Weekday &&
+			normalized[i].StartTime < normalized[i-1].EndTime {
+			return nil, &errors.BadRequestError.InvalidWorkingHours
+		}
+	}
+
+	return normalized, nil
+}
+
+// Minutes since midnight of a time of day given as HH:MM
+func clockMinutes(clock string) int {
+	parsed, _ := time.Parse("15:04", clock)
+	return parsed.Hour()*60 + parsed.Minute()
+}
+
+func formatClockMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func outsideWorkingHoursSlot(start int, end int) schemas.TimeSlot {
+	return schemas.TimeSlot{
+		Start: formatClockMinutes(start),
+		End:   formatClockMinutes(end),
+		Title: "Outside working hours",
+		Type:  "outside_working_hours",
+	}
+}
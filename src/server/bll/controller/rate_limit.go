@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// RateLimitStore counts hits per key within a fixed window
+type RateLimitStore interface {
+	Increment(key string, window time.Duration) (*schemas.RateLimitCounter, *errors.Error)
+}
+
+type RateLimit struct {
+	logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+	Store       RateLimitStore
+}
+
+// Create RateLimit controller with the store configured on env settings
+func NewRateLimitController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *RateLimit {
+	var store RateLimitStore
+	switch envSettings.RateLimitStore {
+	case schemas.RateLimitStorePostgres:
+		store = NewPostgresRateLimitStore(adapter)
+	default:
+		store = NewMemoryRateLimitStore()
+	}
+
+	return &RateLimit{
+		logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+		Store:       store,
+	}
+}
+
+// Counts a hit on a key and returns a too many requests error once the limit of the window is
+// exceeded. The returned counter tells when the window resets.
+func (r *RateLimit) Hit(
+	key string,
+	limit int,
+) (*schemas.RateLimitCounter, *errors.Error) {
+	counter, err := r.Store.Increment(key, r.EnvSettings.RateLimitWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	if counter.Count > limit {
+		return counter, &errors.TooManyRequestsError.RateLimitExceeded
+	}
+
+	return counter, nil
+}
+
+/*
+--------------------------------
+	Rate limit stores
+--------------------------------
+*/
+
+// MemoryRateLimitStore keeps counters in the process, so each instance limits on its own
+type MemoryRateLimitStore struct {
+	mutex     sync.Mutex
+	counters  map[string]*schemas.RateLimitCounter
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		counters:  make(map[string]*schemas.RateLimitCounter),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *MemoryRateLimitStore) Increment(
+	key string,
+	window time.Duration,
+) (*schemas.RateLimitCounter, *errors.Error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.sweepExpired(now, window)
+
+	counter, ok := m.counters[key]
+	if !ok || !counter.ExpiresAt.After(now) {
+		counter = &schemas.RateLimitCounter{Key: key, ExpiresAt: now.Add(window)}
+		m.counters[key] = counter
+	}
+	counter.Count++
+
+	result := *counter
+	return &result, nil
+}
+
+// Drops expired counters at most once per window so the map does not grow unbounded
+func (m *MemoryRateLimitStore) sweepExpired(now time.Time, window time.Duration) {
+	if now.Sub(m.lastSweep) < window {
+		return
+	}
+
+	for key, counter := range m.counters {
+		if !counter.ExpiresAt.After(now) {
+			delete(m.counters, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// PostgresRateLimitStore keeps counters in the database, so every instance shares them
+type PostgresRateLimitStore struct {
+	adapter   *adapter.AdapterCollection
+	mutex     sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresRateLimitStore(adapter *adapter.AdapterCollection) *PostgresRateLimitStore {
+	return &PostgresRateLimitStore{
+		adapter:   adapter,
+		lastSweep: time.Now(),
+	}
+}
+
+func (p *PostgresRateLimitStore) Increment(
+	key string,
+	window time.Duration,
+) (*schemas.RateLimitCounter, *errors.Error) {
+	p.sweepExpired(window)
+	return p.adapter.RateLimitCounter.IncrementPostgresqlRateLimitCounter(key, window)
+}
+
+// Deletes expired counters at most once per window. Failures are ignored since the counters
+// restart on their own once expired.
+func (p *PostgresRateLimitStore) sweepExpired(window time.Duration) {
+	p.mutex.Lock()
+	if time.Since(p.lastSweep) < window {
+		p.mutex.Unlock()
+		return
+	}
+	p.lastSweep = time.Now()
+	p.mutex.Unlock()
+
+	_, _ = p.adapter.RateLimitCounter.DeleteExpiredPostgresqlRateLimitCounters()
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,6 +9,7 @@ import (
 	bllAdapter "onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
 )
 
 type Reservation struct {
@@ -107,28 +109,55 @@ func (r *Reservation) CreateReservation(
 		return nil, &errors.BadRequestError.UserNotCreated // Use existing error for validation
 	}
 
+	// Reservations start CONFIRMED, or on the waitlist through JoinWaitlist. Any other state is
+	// only reached through the transitions of UpdateReservation.
+	if createReservationData.State == "" {
+		createReservationData.State = "CONFIRMED"
+	}
+	if createReservationData.State != "CONFIRMED" {
+		return nil, &errors.BadRequestError.InvalidReservationInitialState
+	}
+
 	// Validate that the user exists
-	_, userErr := r.Adapter.User.GetPostgresqlUser(createReservationData.UserId)
+	user, userErr := r.Adapter.User.GetPostgresqlUser(createReservationData.UserId)
 	if userErr != nil {
 		return nil, userErr
 	}
 
+	if policyErr := checkVerifiedEmailPolicy(r.EnvSettings, user); policyErr != nil {
+		return nil, policyErr
+	}
+
 	// Validate that the session exists
 	session, sessionErr := r.Adapter.Session.GetPostgresqlSession(createReservationData.SessionId)
 	if sessionErr != nil {
 		return nil, sessionErr
 	}
 
-	// Validate that the membership exists if provided
-	var membershipToUpdate *schemas.Membership
-	if createReservationData.MembershipId != nil {
-		membership, membershipErr := r.Adapter.Membership.GetPostgresqlMembership(*createReservationData.MembershipId)
-		if membershipErr != nil {
-			return nil, membershipErr
-		}
-		membershipToUpdate = membership
+	// Booking follows the same rules as confirming a reservation: only sessions still to come, and
+	// not for users suspended for missing sessions
+	if guardErr := reservationStateGuards["CONFIRMED"](
+		r,
+		createReservationData.UserId,
+		session,
+		time.Now(),
+	); guardErr != nil {
+		return nil, guardErr
 	}
 
+	// Check the given membership entitles the user to the session, or pick the best one they have
+	membershipId, membershipErr := resolveBookingMembership(
+		r.Adapter,
+		r.EnvSettings.RequireMembershipForBooking,
+		createReservationData.UserId,
+		session,
+		createReservationData.MembershipId,
+	)
+	if membershipErr != nil {
+		return nil, membershipErr
+	}
+	createReservationData.MembershipId = membershipId
+
 	// Check for user reservation conflicts (user cannot be in two sessions at the same time)
 	userReservations, reservationErr := r.Adapter.Reservation.FetchPostgresqlReservations(
 		[]uuid.UUID{createReservationData.UserId},
@@ -157,70 +186,18 @@ func (r *Reservation) CreateReservation(
 		}
 	}
 
-	// Modify `registered_count` field of the session only if the reservation is CONFIRMED
-	if createReservationData.State == "CONFIRMED" {
-		session.RegisteredCount++
-		_, sessionErr = r.Adapter.Session.UpdatePostgresqlSession(
-			createReservationData.SessionId,
-			nil,
-			nil,
-			nil,
-			nil,
-			nil,
-			&session.RegisteredCount,
-			nil,
-			nil,
-			nil,
-			nil,
-			nil,
-			updatedBy,
-		)
-		if sessionErr != nil {
-			r.logger.Error("Failed to increment registered_count for session", sessionErr)
-			// Continue with reservation creation even if session update fails
-		}
-	}
-
-	// Create the reservation
-	newReservation, createErr := r.Adapter.Reservation.CreatePostgresqlReservation(
+	// Create the reservation. The session counter and the membership uses are updated in the same
+	// transaction, which rejects the booking or puts it on the waitlist once the session is full.
+	return r.Adapter.Reservation.CreatePostgresqlReservation(
 		createReservationData.Name,
 		createReservationData.ReservationTime,
 		createReservationData.State,
 		createReservationData.UserId,
 		createReservationData.SessionId,
 		createReservationData.MembershipId,
+		createReservationData.JoinWaitlist,
 		updatedBy,
 	)
-
-	if createErr != nil {
-		return nil, createErr
-	}
-
-	// Incrementar reservations_used de la membership si existe y si no es ilimitada (null)
-	if membershipToUpdate != nil && membershipToUpdate.ReservationsUsed != nil && createReservationData.State == "CONFIRMED" {
-		currentUsed := *membershipToUpdate.ReservationsUsed
-		newUsed := currentUsed + 1
-
-		_, updateErr := r.Adapter.Membership.UpdatePostgresqlMembership(
-			*createReservationData.MembershipId,
-			nil,
-			nil,
-			nil,
-			nil,
-			&newUsed,
-			nil,
-			nil,
-			nil,
-			updatedBy,
-		)
-
-		if updateErr != nil {
-			r.logger.Error("Failed to update reservations_used for membership", updateErr)
-			// No devolvemos error para no afectar la creación de la reserva
-		}
-	}
-
-	return newReservation, nil
 }
 
 // Updates a reservation.
@@ -229,7 +206,6 @@ func (r *Reservation) UpdateReservation(
 	updateReservationData schemas.UpdateReservationRequest,
 	updatedBy string,
 ) (*schemas.Reservation, *errors.Error) {
-	// Obtener la reserva actual para comparar estados
 	currentReservation, getErr := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
 	if getErr != nil {
 		return nil, getErr
@@ -244,57 +220,100 @@ func (r *Reservation) UpdateReservation(
 	}
 
 	// Validate that the session exists if provided
+	session := &currentReservation.Session
 	if updateReservationData.SessionId != nil {
-		_, sessionErr := r.Adapter.Session.GetPostgresqlSession(*updateReservationData.SessionId)
+		newSession, sessionErr := r.Adapter.Session.GetPostgresqlSession(*updateReservationData.SessionId)
 		if sessionErr != nil {
 			return nil, sessionErr
 		}
+		session = newSession
 	}
 
-	// Verificar si hay un cambio de membresía
-	var oldMembershipId *uuid.UUID
-	var newMembershipId *uuid.UUID
-
-	oldMembershipId = currentReservation.MembershipId
-	if updateReservationData.MembershipId != nil {
-		newMembershipId = updateReservationData.MembershipId
-	} else {
-		newMembershipId = oldMembershipId
+	userId := currentReservation.UserId
+	if updateReservationData.UserId != nil {
+		userId = *updateReservationData.UserId
 	}
 
-	// Validate that the membership exists if provided
-	var oldMembership *schemas.Membership
-	var newMembership *schemas.Membership
-
-	if oldMembershipId != nil {
-		membership, membershipErr := r.Adapter.Membership.GetPostgresqlMembership(*oldMembershipId)
-		if membershipErr != nil {
-			return nil, membershipErr
+	// Booking a cancelled reservation again is a new booking: the users on the waitlist of the
+	// session keep their turn and the membership must still entitle the user to the session
+	reinstating := updateReservationData.State != nil &&
+		*updateReservationData.State == "CONFIRMED" &&
+		currentReservation.State == "CANCELLED"
+	if reinstating {
+		waitlisted, waitlistErr := r.Adapter.Reservation.FetchPostgresqlReservations(
+			nil,
+			[]uuid.UUID{session.Id},
+			[]string{"WAITLISTED"},
+		)
+		if waitlistErr != nil {
+			return nil, waitlistErr
+		}
+		if len(waitlisted) > 0 {
+			return nil, &errors.ConflictError.SessionHasWaitlist
 		}
-		oldMembership = membership
 	}
 
-	if newMembershipId != nil && (oldMembershipId == nil || *newMembershipId != *oldMembershipId) {
-		membership, membershipErr := r.Adapter.Membership.GetPostgresqlMembership(*newMembershipId)
-		if membershipErr != nil {
-			return nil, membershipErr
+	// Check a new membership entitles the user of the reservation to its session
+	membershipChanged := updateReservationData.MembershipId != nil &&
+		(currentReservation.MembershipId == nil ||
+			*currentReservation.MembershipId != *updateReservationData.MembershipId)
+	if membershipChanged || reinstating {
+		membershipId := updateReservationData.MembershipId
+		if membershipId == nil {
+			membershipId = currentReservation.MembershipId
+		}
+		if !membershipChanged && currentReservation.CreditForfeited && membershipId != nil {
+			if membershipErr := checkForfeitedMembershipEntitlement(
+				r.Adapter,
+				userId,
+				session,
+				*membershipId,
+			); membershipErr != nil {
+				return nil, membershipErr
+			}
+		} else {
+			resolvedMembershipId, membershipErr := resolveBookingMembership(
+				r.Adapter,
+				r.EnvSettings.RequireMembershipForBooking,
+				userId,
+				session,
+				membershipId,
+			)
+			if membershipErr != nil {
+				return nil, membershipErr
+			}
+			updateReservationData.MembershipId = resolvedMembershipId
 		}
-		newMembership = membership
-	} else if newMembershipId != nil {
-		newMembership = oldMembership
 	}
 
-	// Verificar si hay un cambio de estado
-	oldState := currentReservation.State
-	var newState string
+	// A change of state must be a declared transition whose guard passes. Transitions giving the
+	// spot back keep the membership use when the cancellation policy of the session says so.
+	forfeitCredit := false
 	if updateReservationData.State != nil {
-		newState = *updateReservationData.State
-	} else {
-		newState = oldState
+		now := time.Now()
+		transition, transitionErr := r.checkReservationTransition(
+			currentReservation,
+			userId,
+			session,
+			*updateReservationData.State,
+			now,
+		)
+		if transitionErr != nil {
+			return nil, transitionErr
+		}
+
+		if transition != nil && transition.MayForfeitCredit {
+			policy, policyErr := getSessionCancellationPolicy(r.Adapter, &currentReservation.Session)
+			if policyErr != nil {
+				return nil, policyErr
+			}
+			forfeitCredit = shouldForfeitCredit(policy, currentReservation, transition.To, now)
+		}
 	}
 
-	// Realizar la actualización de la reserva
-	updatedReservation, updateErr := r.Adapter.Reservation.UpdatePostgresqlReservation(
+	// Changes of state, session or membership move the session counter and the membership uses in
+	// the same transaction as the reservation itself, and a spot given back goes to the waitlist
+	updatedReservation, promoted, err := r.Adapter.Reservation.UpdatePostgresqlReservation(
 		reservationId,
 		updateReservationData.Name,
 		updateReservationData.ReservationTime,
@@ -302,337 +321,179 @@ func (r *Reservation) UpdateReservation(
 		updateReservationData.UserId,
 		updateReservationData.SessionId,
 		updateReservationData.MembershipId,
+		forfeitCredit,
 		updatedBy,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	if updateErr != nil {
-		return nil, updateErr
-	}
-
-	// Actualizar el contador de reservas usadas en la membresía según los cambios de estado
-
-	// Caso 1: Estado cambia de confirmado a anulado o cancelado
-	if oldState == "CONFIRMED" && (newState == "ANULLED" || newState == "CANCELLED") {
-		// Decrementar registered_count de la sesión
-		session, sessionErr := r.Adapter.Session.GetPostgresqlSession(currentReservation.SessionId)
-		if sessionErr == nil && session.RegisteredCount > 0 {
-			session.RegisteredCount--
-			_, updateSessionErr := r.Adapter.Session.UpdatePostgresqlSession(
-				currentReservation.SessionId,
-				nil, // title
-				nil, // date
-				nil, // start_time
-				nil, // end_time
-				nil, // state
-				&session.RegisteredCount, // decrementar contador
-				nil, // capacity
-				nil, // session_link
-				nil, // professional_id
-				nil, // local_id
-				nil, // community_service_id
-				updatedBy,
-			)
-			if updateSessionErr != nil {
-				r.logger.Error("Failed to decrement registered_count for session", updateSessionErr)
-			}
-		}
+	r.notifyPromotedReservations(promoted)
+	return updatedReservation, nil
+}
 
-		// Decrementar contador si la membresía tiene un contador (no es ilimitada)
-		if oldMembership != nil && oldMembership.ReservationsUsed != nil {
-			currentUsed := *oldMembership.ReservationsUsed
-			newUsed := currentUsed - 1
-			if newUsed < 0 {
-				newUsed = 0
-			}
+// Deletes a reservation, giving back its spot in the session and its membership use. Deleting a
+// confirmed reservation counts as cancelling it, so the cancellation policy of its session applies.
+func (r *Reservation) DeleteReservation(reservationId uuid.UUID) *errors.Error {
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return err
+	}
 
-			_, membershipErr := r.Adapter.Membership.UpdatePostgresqlMembership(
-				*oldMembershipId,
-				nil,
-				nil,
-				nil,
-				nil,
-				&newUsed,
-				nil,
-				nil,
-				nil,
-				updatedBy,
-			)
+	forfeitCredit, err := r.forfeitsCreditOnCancel(reservation)
+	if err != nil {
+		return err
+	}
 
-			if membershipErr != nil {
-				r.logger.Error("Failed to decrement reservations_used for membership", membershipErr)
-				// No devolvemos error para no afectar la actualización de la reserva
-			}
-		}
+	promoted, err := r.Adapter.Reservation.DeletePostgresqlReservation(reservationId, forfeitCredit)
+	if err != nil {
+		return err
 	}
 
-	// Caso 2: Estado cambia de anulado o cancelado a confirmado
-	if (oldState == "ANULLED" || oldState == "CANCELLED") && newState == "CONFIRMED" {
-		// Incrementar registered_count de la sesión
-		session, sessionErr := r.Adapter.Session.GetPostgresqlSession(currentReservation.SessionId)
-		if sessionErr == nil {
-			session.RegisteredCount++
-			_, updateSessionErr := r.Adapter.Session.UpdatePostgresqlSession(
-				currentReservation.SessionId,
-				nil, // title
-				nil, // date
-				nil, // start_time
-				nil, // end_time
-				nil, // state
-				&session.RegisteredCount, // incrementar contador
-				nil, // capacity
-				nil, // session_link
-				nil, // professional_id
-				nil, // local_id
-				nil, // community_service_id
-				updatedBy,
-			)
-			if updateSessionErr != nil {
-				r.logger.Error("Failed to increment registered_count for session", updateSessionErr)
-			}
-		}
+	r.notifyPromotedReservations(promoted)
+	return nil
+}
 
-		// Incrementar contador si la nueva membresía tiene un contador (no es ilimitada)
-		if newMembership != nil && newMembership.ReservationsUsed != nil {
-			currentUsed := *newMembership.ReservationsUsed
-			newUsed := currentUsed + 1
-
-			_, membershipErr := r.Adapter.Membership.UpdatePostgresqlMembership(
-				*newMembershipId,
-				nil,
-				nil,
-				nil,
-				nil,
-				&newUsed,
-				nil,
-				nil,
-				nil,
-				updatedBy,
-			)
+// Bulk deletes reservations, giving back their spots and membership uses. As with a single
+// delete, the cancellation policy of each session applies.
+func (r *Reservation) BulkDeleteReservations(
+	bulkDeleteReservationData schemas.BulkDeleteReservationRequest,
+) *errors.Error {
+	forfeitIds := []uuid.UUID{}
+	for _, idStr := range bulkDeleteReservationData.Reservations {
+		reservationId, parseErr := uuid.Parse(idStr)
+		if parseErr != nil {
+			return &errors.UnprocessableEntityError.InvalidReservationId
+		}
 
-			if membershipErr != nil {
-				r.logger.Error("Failed to increment reservations_used for membership", membershipErr)
-				// No devolvemos error para no afectar la actualización de la reserva
-			}
+		// Missing reservations are left to the delete itself, which ignores them
+		reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+		if err != nil {
+			continue
+		}
+		forfeitCredit, err := r.forfeitsCreditOnCancel(reservation)
+		if err != nil {
+			return err
+		}
+		if forfeitCredit {
+			forfeitIds = append(forfeitIds, reservationId)
 		}
 	}
 
-	// Caso 3: Cambio de membresía manteniendo estado confirmado
-	if oldState == "CONFIRMED" && newState == "CONFIRMED" &&
-		oldMembershipId != nil && newMembershipId != nil &&
-		*oldMembershipId != *newMembershipId {
-
-		// Decrementar contador en la membresía antigua si no es ilimitada
-		if oldMembership != nil && oldMembership.ReservationsUsed != nil {
-			currentUsed := *oldMembership.ReservationsUsed
-			newUsed := currentUsed - 1
-			if newUsed < 0 {
-				newUsed = 0
-			}
+	promoted, err := r.Adapter.Reservation.BulkDeletePostgresqlReservations(
+		bulkDeleteReservationData.Reservations,
+		forfeitIds,
+	)
+	if err != nil {
+		return err
+	}
 
-			_, membershipErr := r.Adapter.Membership.UpdatePostgresqlMembership(
-				*oldMembershipId,
-				nil,
-				nil,
-				nil,
-				nil,
-				&newUsed,
-				nil,
-				nil,
-				nil,
-				updatedBy,
-			)
+	r.notifyPromotedReservations(promoted)
+	return nil
+}
 
-			if membershipErr != nil {
-				r.logger.Error("Failed to decrement reservations_used for old membership", membershipErr)
-			}
-		}
+// Gets the place of a reservation in the waitlist of its session.
+func (r *Reservation) GetWaitlistPosition(
+	reservationId uuid.UUID,
+) (*schemas.WaitlistPosition, *errors.Error) {
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.State != "WAITLISTED" {
+		return nil, &errors.BadRequestError.ReservationNotWaitlisted
+	}
 
-		// Incrementar contador en la nueva membresía si no es ilimitada
-		if newMembership != nil && newMembership.ReservationsUsed != nil {
-			currentUsed := *newMembership.ReservationsUsed
-			newUsed := currentUsed + 1
-
-			_, membershipErr := r.Adapter.Membership.UpdatePostgresqlMembership(
-				*newMembershipId,
-				nil,
-				nil,
-				nil,
-				nil,
-				&newUsed,
-				nil,
-				nil,
-				nil,
-				updatedBy,
-			)
+	return r.Adapter.Reservation.GetPostgresqlWaitlistPosition(reservation)
+}
 
-			if membershipErr != nil {
-				r.logger.Error("Failed to increment reservations_used for new membership", membershipErr)
-			}
-		}
+// Takes a reservation off the waitlist of its session by cancelling it.
+func (r *Reservation) LeaveWaitlist(
+	reservationId uuid.UUID,
+	updatedBy string,
+) (*schemas.Reservation, *errors.Error) {
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.State != "WAITLISTED" {
+		return nil, &errors.BadRequestError.ReservationNotWaitlisted
 	}
 
-	return updatedReservation, nil
+	// A waitlisted reservation holds no spot, so nobody gets promoted
+	cancelled := "CANCELLED"
+	updatedReservation, _, err := r.Adapter.Reservation.UpdatePostgresqlReservation(
+		reservationId,
+		nil,
+		nil,
+		&cancelled,
+		nil,
+		nil,
+		nil,
+		false,
+		updatedBy,
+	)
+	return updatedReservation, err
 }
 
-// Deletes a reservation.
-func (r *Reservation) DeleteReservation(reservationId uuid.UUID) *errors.Error {
-	// Obtener la reserva antes de eliminarla para obtener info de la membresía
-	currentReservation, getErr := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
-	if getErr != nil {
-		return getErr
-	}
-
-	// Si la reserva está confirmada, decrementar registered_count y membresía
-	if currentReservation.State == "CONFIRMED" {
-		// Decrementar registered_count de la sesión
-		session, sessionErr := r.Adapter.Session.GetPostgresqlSession(currentReservation.SessionId)
-		if sessionErr == nil && session.RegisteredCount > 0 {
-			session.RegisteredCount--
-			_, updateSessionErr := r.Adapter.Session.UpdatePostgresqlSession(
-				currentReservation.SessionId,
-				nil, // title
-				nil, // date
-				nil, // start_time
-				nil, // end_time
-				nil, // state
-				&session.RegisteredCount, // decrementar contador
-				nil, // capacity
-				nil, // session_link
-				nil, // professional_id
-				nil, // local_id
-				nil, // community_service_id
-				"SYSTEM", // En caso de eliminación, usamos SYSTEM como updatedBy
-			)
-			if updateSessionErr != nil {
-				r.logger.Error("Failed to decrement registered_count on reservation delete", updateSessionErr)
-			}
-		}
+// Tells what cancelling a reservation right now would cost under the cancellation policy of its
+// session, so users know it before they cancel.
+func (r *Reservation) PreviewCancellation(
+	reservationId uuid.UUID,
+) (*schemas.CancellationPreview, *errors.Error) {
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return nil, err
+	}
 
-		// Decrementar contador de membresía si tiene una asociada
-		if currentReservation.MembershipId != nil {
-			// Obtener la membresía actual
-			membership, membershipErr := r.Adapter.Membership.GetPostgresqlMembership(*currentReservation.MembershipId)
-			if membershipErr == nil && membership.ReservationsUsed != nil {
-				// Decrementar el contador si no es membresía ilimitada
-				currentUsed := *membership.ReservationsUsed
-				newUsed := currentUsed - 1
-				if newUsed < 0 {
-					newUsed = 0
-				}
-
-				// Actualizar la membresía
-				_, updateErr := r.Adapter.Membership.UpdatePostgresqlMembership(
-					*currentReservation.MembershipId,
-					nil,
-					nil,
-					nil,
-					nil,
-					&newUsed,
-					nil,
-					nil,
-					nil,
-					"SYSTEM", // En caso de eliminación, usamos SYSTEM como updatedBy
-				)
-
-				if updateErr != nil {
-					r.logger.Error("Failed to decrement reservations_used on reservation delete", updateErr)
-					// No devolvemos error para no afectar la eliminación de la reserva
-				}
-			}
-		}
+	policy, err := getSessionCancellationPolicy(r.Adapter, &reservation.Session)
+	if err != nil {
+		return nil, err
 	}
 
-	return r.Adapter.Reservation.DeletePostgresqlReservation(reservationId)
+	now := time.Now()
+	deadline := freeCancellationDeadline(policy, &reservation.Session)
+	return &schemas.CancellationPreview{
+		ReservationId:          reservation.Id,
+		Policy:                 policy,
+		FreeCancellationUntil:  deadline,
+		IsLateCancellation:     deadline != nil && now.After(*deadline),
+		CreditWouldBeForfeited: shouldForfeitCredit(policy, reservation, "CANCELLED", now),
+	}, nil
 }
 
-// Bulk deletes reservations.
-func (r *Reservation) BulkDeleteReservations(
-	bulkDeleteReservationData schemas.BulkDeleteReservationRequest,
-) *errors.Error {
-	// Convertir las IDs de string a UUID
-	uuidIds := make([]uuid.UUID, 0, len(bulkDeleteReservationData.Reservations))
-	for _, idStr := range bulkDeleteReservationData.Reservations {
-		id, err := uuid.Parse(idStr)
-		if err != nil {
-			return &errors.UnprocessableEntityError.InvalidReservationId
-		}
-		uuidIds = append(uuidIds, id)
+// Whether cancelling a reservation now keeps its membership use under the policy of its session
+func (r *Reservation) forfeitsCreditOnCancel(reservation *schemas.Reservation) (bool, *errors.Error) {
+	policy, err := getSessionCancellationPolicy(r.Adapter, &reservation.Session)
+	if err != nil {
+		return false, err
 	}
 
-	// Para cada reserva confirmada, decrementar el contador de la membresía
-	for _, reservationId := range uuidIds {
-		// Obtener la reserva antes de eliminarla
-		reservation, getErr := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
-		if getErr != nil {
-			// Ignorar errores y continuar con las siguientes
-			r.logger.Error("Failed to get reservation for bulk delete", getErr)
+	return shouldForfeitCredit(policy, reservation, "CANCELLED", time.Now()), nil
+}
+
+// Lets the users of reservations promoted from a waitlist know they got a spot. Failures are only
+// logged, the promotion stands either way.
+func (r *Reservation) notifyPromotedReservations(promoted []*schemas.Reservation) {
+	for _, reservation := range promoted {
+		user, err := r.Adapter.User.GetPostgresqlUser(reservation.UserId)
+		if err != nil {
+			r.logger.Warnf("Failed to get the user of promoted reservation %s: %v", reservation.Id, err.Message)
 			continue
 		}
 
-		// Si está confirmada, decrementar registered_count y membresía
-		if reservation.State == "CONFIRMED" {
-			// Decrementar registered_count de la sesión
-			session, sessionErr := r.Adapter.Session.GetPostgresqlSession(reservation.SessionId)
-			if sessionErr == nil && session.RegisteredCount > 0 {
-				session.RegisteredCount--
-				_, updateSessionErr := r.Adapter.Session.UpdatePostgresqlSession(
-					reservation.SessionId,
-					nil, // title
-					nil, // date
-					nil, // start_time
-					nil, // end_time
-					nil, // state
-					&session.RegisteredCount, // decrementar contador
-					nil, // capacity
-					nil, // session_link
-					nil, // professional_id
-					nil, // local_id
-					nil, // community_service_id
-					"SYSTEM", // En caso de eliminación en bloque, usamos SYSTEM como updatedBy
-				)
-				if updateSessionErr != nil {
-					r.logger.Error("Failed to decrement registered_count on bulk delete", updateSessionErr)
-				}
-			}
+		body := fmt.Sprintf(
+			"Hola %s,\n\nSe liberó un cupo y tu reserva de la lista de espera fue confirmada:\n\n🧘 Sesión: %s\n📅 Fecha: %s\n🕘 Hora: %s\n\nSi ya no puedes asistir, cancela tu reserva para liberar el cupo.\n\nGracias por ser parte de ZenCat 🌿",
+			user.Name,
+			reservation.Session.Title,
+			reservation.Session.Date.Format("02/01/2006"),
+			reservation.Session.StartTime.Format("15:04"),
+		)
 
-			// Decrementar contador de membresía si tiene una asociada
-			if reservation.MembershipId != nil {
-				// Obtener la membresía
-				membership, membershipErr := r.Adapter.Membership.GetPostgresqlMembership(*reservation.MembershipId)
-				if membershipErr == nil && membership.ReservationsUsed != nil {
-					// Decrementar el contador si no es ilimitada
-					currentUsed := *membership.ReservationsUsed
-					newUsed := currentUsed - 1
-					if newUsed < 0 {
-						newUsed = 0
-					}
-
-					// Actualizar la membresía
-					_, updateErr := r.Adapter.Membership.UpdatePostgresqlMembership(
-						*reservation.MembershipId,
-						nil,
-						nil,
-						nil,
-						nil,
-						&newUsed,
-						nil,
-						nil,
-						nil,
-						"SYSTEM", // En caso de eliminación en bloque, usamos SYSTEM como updatedBy
-					)
-
-					if updateErr != nil {
-						r.logger.Error("Failed to decrement reservations_used on bulk delete", updateErr)
-						// Continuar con la siguiente reserva
-					}
-				}
-			}
+		if emailErr := utils.SendEmail(r.EnvSettings, user.Email, "Tu reserva en ZenCat fue confirmada", body); emailErr != nil {
+			r.logger.Warnf("Failed to send waitlist promotion email: %v", emailErr)
 		}
 	}
-
-	return r.Adapter.Reservation.BulkDeletePostgresqlReservations(
-		bulkDeleteReservationData.Reservations,
-	)
 }
 
 // Helper function to check if two dates are the same day
@@ -740,3 +601,224 @@ func (r *Reservation) GetServiceReport(from, to *time.Time, groupBy string) (*Se
 		Services: services,
 	}, nil
 }
+
+// Checks that the caller owns a reservation or can access the ones of other users in its community
+func (r *Reservation) CheckReservationAccess(
+	credentials *schemas.Credentials,
+	reservationId uuid.UUID,
+) *errors.Error {
+	if credentials == nil || isOwnershipExempt(credentials) {
+		return nil
+	}
+
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return err
+	}
+	if reservation.UserId == credentials.UserId || canActForOtherUsers(
+		r.logger,
+		r.Adapter,
+		credentials,
+		schemas.PermissionReservationAnyUser,
+		schemas.PermissionResources{SessionId: &reservation.SessionId},
+	) {
+		return nil
+	}
+
+	return checkResourceOwner(
+		r.logger,
+		r.Adapter,
+		credentials,
+		reservation.UserId,
+		schemas.AuditEntityReservation,
+		reservation.Id,
+	)
+}
+
+// Checks that the caller only reads or books reservations of their own user, unless they can
+// access the reservations of other users in every community of the given resources
+func (r *Reservation) CheckUserReservationsAccess(
+	credentials *schemas.Credentials,
+	userId uuid.UUID,
+	resources schemas.PermissionResources,
+) *errors.Error {
+	if credentials != nil && credentials.UserId != userId && canActForOtherUsers(
+		r.logger,
+		r.Adapter,
+		credentials,
+		schemas.PermissionReservationAnyUser,
+		resources,
+	) {
+		return nil
+	}
+
+	return checkResourceOwner(
+		r.logger,
+		r.Adapter,
+		credentials,
+		userId,
+		schemas.AuditEntityReservation,
+		userId,
+	)
+}
+
+// Checks that the caller books for their own user and with their own membership
+func (r *Reservation) CheckCreateReservationAccess(
+	credentials *schemas.Credentials,
+	request schemas.CreateReservationRequest,
+) *errors.Error {
+	if err := r.CheckUserReservationsAccess(
+		credentials,
+		request.UserId,
+		schemas.PermissionResources{SessionId: &request.SessionId},
+	); err != nil {
+		return err
+	}
+
+	return r.checkMembershipOwner(credentials, request.MembershipId)
+}
+
+// Checks that the caller owns a reservation and does not move it to another user or membership
+func (r *Reservation) CheckUpdateReservationAccess(
+	credentials *schemas.Credentials,
+	reservationId uuid.UUID,
+	request schemas.UpdateReservationRequest,
+) *errors.Error {
+	if err := r.CheckReservationAccess(credentials, reservationId); err != nil {
+		return err
+	}
+	if request.UserId != nil {
+		if err := r.CheckUserReservationsAccess(
+			credentials,
+			*request.UserId,
+			schemas.PermissionResources{ReservationId: &reservationId, SessionId: request.SessionId},
+		); err != nil {
+			return err
+		}
+	}
+	if err := r.checkStaffOnlyTransition(credentials, reservationId, request.State); err != nil {
+		return err
+	}
+
+	return r.checkMembershipOwner(credentials, request.MembershipId)
+}
+
+// Checks that the caller owns every reservation to delete. Invalid ids are left to the delete
+// itself to report.
+func (r *Reservation) CheckBulkDeleteReservationsAccess(
+	credentials *schemas.Credentials,
+	request schemas.BulkDeleteReservationRequest,
+) *errors.Error {
+	for _, idStr := range request.Reservations {
+		reservationId, parseErr := uuid.Parse(idStr)
+		if parseErr != nil {
+			continue
+		}
+		if err := r.CheckReservationAccess(credentials, reservationId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Limits a reservation listing to the user of the caller. Callers that do not filter by user get
+// their own reservations, filtering by other users is denied unless they can access the
+// reservations of other users in every community.
+func (r *Reservation) RestrictReservationUserIds(
+	credentials *schemas.Credentials,
+	userIds []string,
+) ([]string, *errors.Error) {
+	if canActForOtherUsers(
+		r.logger,
+		r.Adapter,
+		credentials,
+		schemas.PermissionReservationAnyUser,
+		schemas.PermissionResources{},
+	) {
+		return userIds, nil
+	}
+	if len(userIds) == 0 {
+		return []string{credentials.UserId.String()}, nil
+	}
+
+	for _, id := range userIds {
+		userId, parseErr := uuid.Parse(id)
+		if parseErr != nil {
+			return nil, &errors.UnprocessableEntityError.InvalidUserId
+		}
+		if err := r.CheckUserReservationsAccess(
+			credentials,
+			userId,
+			schemas.PermissionResources{},
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return userIds, nil
+}
+
+// Keeps members from moving their own reservations to states only staff can set, e.g. DONE
+func (r *Reservation) checkStaffOnlyTransition(
+	credentials *schemas.Credentials,
+	reservationId uuid.UUID,
+	state *string,
+) *errors.Error {
+	if state == nil || credentials == nil || isOwnershipExempt(credentials) {
+		return nil
+	}
+
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return err
+	}
+	if canActForOtherUsers(
+		r.logger,
+		r.Adapter,
+		credentials,
+		schemas.PermissionReservationAnyUser,
+		schemas.PermissionResources{SessionId: &reservation.SessionId},
+	) {
+		return nil
+	}
+
+	transition, ok := schemas.FindReservationTransition(reservation.State, *state)
+	if ok && transition.StaffOnly {
+		return &errors.ForbiddenError.ReservationStateStaffOnly
+	}
+
+	return nil
+}
+
+func (r *Reservation) checkMembershipOwner(
+	credentials *schemas.Credentials,
+	membershipId *uuid.UUID,
+) *errors.Error {
+	if membershipId == nil || credentials == nil || isOwnershipExempt(credentials) {
+		return nil
+	}
+
+	membership, err := r.Adapter.Membership.GetPostgresqlMembership(*membershipId)
+	if err != nil {
+		return err
+	}
+	if membership.UserId == credentials.UserId || canActForOtherUsers(
+		r.logger,
+		r.Adapter,
+		credentials,
+		schemas.PermissionMembershipAnyUser,
+		schemas.PermissionResources{CommunityId: &membership.CommunityId},
+	) {
+		return nil
+	}
+
+	return checkResourceOwner(
+		r.logger,
+		r.Adapter,
+		credentials,
+		membership.UserId,
+		schemas.AuditEntityMembership,
+		membership.Id,
+	)
+}
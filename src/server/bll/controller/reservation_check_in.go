@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+// Pixels per module of check-in QR codes rendered as PNG
+const checkInQrCodeScale = 8
+
+// Renders the check-in code of a reservation as a QR code, in png or svg format. Returns the image
+// and its content type. The code is a short-lived signed token, so a screenshot stops working soon.
+func (r *Reservation) GetCheckInQrCode(
+	reservationId uuid.UUID,
+	format string,
+) ([]byte, string, *errors.Error) {
+	if format != "png" && format != "svg" {
+		return nil, "", &errors.UnprocessableEntityError.InvalidQrCodeFormat
+	}
+
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkCheckInAllowed(reservation, time.Now()); err != nil {
+		return nil, "", err
+	}
+
+	token, err := r.issueCheckInToken(reservation.Id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	qrCode, qrErr := utils.EncodeQrCode([]byte(token))
+	if qrErr != nil {
+		r.logger.Errorf("Failed to encode check-in QR code of reservation %s: %v", reservation.Id, qrErr)
+		return nil, "", &errors.InternalServerError.Default
+	}
+
+	if format == "svg" {
+		return []byte(qrCode.Svg()), "image/svg+xml", nil
+	}
+
+	image, qrErr := qrCode.Png(checkInQrCodeScale)
+	if qrErr != nil {
+		r.logger.Errorf("Failed to render check-in QR code of reservation %s: %v", reservation.Id, qrErr)
+		return nil, "", &errors.InternalServerError.Default
+	}
+	return image, "image/png", nil
+}
+
+// Checks the attendee of a reservation in with the token read from their QR code, marking the
+// reservation as DONE.
+func (r *Reservation) CheckIn(
+	checkInToken string,
+	updatedBy string,
+) (*schemas.Reservation, *errors.Error) {
+	reservationId, err := r.parseCheckInToken(checkInToken)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := checkCheckInAllowed(reservation, now); err != nil {
+		return nil, err
+	}
+
+	return r.Adapter.Reservation.CheckInPostgresqlReservation(reservationId, now, updatedBy)
+}
+
+// Marks as no-shows the confirmed reservations never checked in of the sessions that ended before a
+// given time. Whether each one keeps its membership use as a penalty depends on the cancellation
+// policy of its session. Reservations that fail are logged and left for the next pass.
+func (r *Reservation) MarkNoShows(endedBefore time.Time) (*schemas.NoShowSweep, *errors.Error) {
+	missed, err := r.Adapter.Reservation.FetchPostgresqlMissedReservations(endedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sweep := &schemas.NoShowSweep{Reservations: []uuid.UUID{}}
+	policies := map[uuid.UUID]*schemas.CancellationPolicy{}
+	for _, reservation := range missed {
+		policy, seen := policies[reservation.SessionId]
+		if !seen {
+			policy, err = getSessionCancellationPolicy(r.Adapter, &reservation.Session)
+			if err != nil {
+				r.logger.Warnf("Failed to get the cancellation policy of session %s: %v", reservation.SessionId, err.Message)
+				continue
+			}
+			policies[reservation.SessionId] = policy
+		}
+
+		forfeitCredit := shouldForfeitCredit(policy, reservation, "NO_SHOW", now)
+		marked, err := r.Adapter.Reservation.MarkPostgresqlReservationNoShow(reservation.Id, forfeitCredit, "SYSTEM")
+		if err != nil {
+			r.logger.Warnf("Failed to mark reservation %s as no-show: %v", reservation.Id, err.Message)
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		sweep.Reservations = append(sweep.Reservations, reservation.Id)
+		if forfeitCredit {
+			sweep.CreditsForfeited++
+		}
+	}
+
+	return sweep, nil
+}
+
+// Marks as DONE the confirmed reservations of completed sessions. Meant for when attendance is not
+// tracked; otherwise attendees are marked DONE at check-in and the rest by MarkNoShows.
+func (r *Reservation) CompleteReservations() ([]uuid.UUID, *errors.Error) {
+	completed, err := r.Adapter.Reservation.CompletePostgresqlReservations("SYSTEM")
+	if err != nil {
+		return nil, err
+	}
+
+	reservationIds := make([]uuid.UUID, len(completed))
+	for i, reservation := range completed {
+		reservationIds[i] = reservation.Id
+	}
+
+	return reservationIds, nil
+}
+
+// Rejects check-ins of reservations that are not confirmed or whose session was cancelled or is over
+func checkCheckInAllowed(reservation *schemas.Reservation, now time.Time) *errors.Error {
+	if reservation.CheckedInAt != nil {
+		return &errors.ConflictError.ReservationAlreadyCheckedIn
+	}
+	if reservation.State != "CONFIRMED" {
+		return &errors.BadRequestError.ReservationNotConfirmed
+	}
+	if reservation.Session.State == "CANCELLED" || now.After(reservation.Session.EndTime) {
+		return &errors.BadRequestError.CheckInClosed
+	}
+
+	return nil
+}
+
+// Signs a short-lived token that identifies a reservation at check-in
+func (r *Reservation) issueCheckInToken(reservationId uuid.UUID) (string, *errors.Error) {
+	claims := &schemas.CheckInClaims{
+		ReservationId: reservationId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{schemas.CheckInTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(r.EnvSettings.CheckInTokenExpiration)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	checkInToken, err := token.SignedString(r.EnvSettings.TokenSignatureKey)
+	if err != nil {
+		return "", &errors.InternalServerError.Default
+	}
+
+	return checkInToken, nil
+}
+
+// Validates a check-in token and returns the reservation it identifies. Other tokens signed with
+// the same key are rejected since they lack the check-in audience.
+func (r *Reservation) parseCheckInToken(checkInToken string) (uuid.UUID, *errors.Error) {
+	token, err := jwt.ParseWithClaims(
+		checkInToken,
+		&schemas.CheckInClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method")
+			}
+			return []byte(r.EnvSettings.TokenSignatureKey), nil
+		},
+		jwt.WithAudience(schemas.CheckInTokenAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !token.Valid {
+		return uuid.Nil, &errors.BadRequestError.InvalidCheckInToken
+	}
+
+	return token.Claims.(*schemas.CheckInClaims).ReservationId, nil
+}
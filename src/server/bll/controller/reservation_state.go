@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// Checks a transition into a state is allowed right now for the user of a reservation and the
+// session it will be in
+type reservationStateGuard func(
+	r *Reservation,
+	userId uuid.UUID,
+	session *schemas.Session,
+	now time.Time,
+) *errors.Error
+
+// Guards of the transitions into each state, on top of the transitions declared in
+// schemas.ReservationTransitions. States without a guard can be reached at any time.
+var reservationStateGuards = map[string]reservationStateGuard{
+	// Booking, or taking a spot from the waitlist, is only possible before a session that was not
+	// cancelled and for users not suspended for missing sessions
+	"CONFIRMED": func(r *Reservation, userId uuid.UUID, session *schemas.Session, now time.Time) *errors.Error {
+		if session.State == "CANCELLED" {
+			return &errors.BadRequestError.ReservationSessionCancelled
+		}
+		if !now.Before(session.StartTime) {
+			return &errors.BadRequestError.ReservationSessionStarted
+		}
+		return checkNoShowBan(r.Adapter, userId, session, now)
+	},
+	"CANCELLED": func(_ *Reservation, _ uuid.UUID, session *schemas.Session, now time.Time) *errors.Error {
+		if now.After(session.EndTime) {
+			return &errors.BadRequestError.ReservationSessionEnded
+		}
+		return nil
+	},
+	"DONE": func(_ *Reservation, _ uuid.UUID, session *schemas.Session, _ time.Time) *errors.Error {
+		if session.State == "CANCELLED" {
+			return &errors.BadRequestError.ReservationSessionCancelled
+		}
+		return nil
+	},
+	"NO_SHOW": func(_ *Reservation, _ uuid.UUID, session *schemas.Session, now time.Time) *errors.Error {
+		if !now.After(session.EndTime) {
+			return &errors.BadRequestError.ReservationSessionNotEnded
+		}
+		return nil
+	},
+}
+
+// Gets the changes of state of a reservation, oldest first, together with its current state.
+func (r *Reservation) GetReservationTimeline(
+	reservationId uuid.UUID,
+) (*schemas.ReservationTimeline, *errors.Error) {
+	reservation, err := r.Adapter.Reservation.GetPostgresqlReservation(reservationId)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := r.Adapter.ReservationStateHistory.FetchPostgresqlReservationStateHistory(reservationId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.ReservationTimeline{
+		ReservationId: reservation.Id,
+		State:         reservation.State,
+		Changes:       changes,
+	}, nil
+}
+
+// Checks a reservation can move to a state: the transition must be declared and its guard must
+// pass. Returns the transition, or nil when the state does not change.
+func (r *Reservation) checkReservationTransition(
+	reservation *schemas.Reservation,
+	userId uuid.UUID,
+	session *schemas.Session,
+	state string,
+	now time.Time,
+) (*schemas.ReservationTransition, *errors.Error) {
+	if state == reservation.State {
+		return nil, nil
+	}
+
+	transition, ok := schemas.FindReservationTransition(reservation.State, state)
+	if !ok {
+		return nil, &errors.BadRequestError.InvalidReservationTransition
+	}
+
+	if guard, ok := reservationStateGuards[state]; ok {
+		if err := guard(r, userId, session, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return transition, nil
+}
@@ -0,0 +1,358 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+type Role struct {
+	logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+// Create role controller
+func NewRoleController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *Role {
+	return &Role{
+		logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+// Seeds the default roles and permissions, missing ones are created and existing ones are kept
+func (r *Role) SeedDefaultRoles() *errors.Error {
+	return r.Adapter.Role.SeedPostgresqlRolesAndPermissions(
+		schemas.DefaultPermissions,
+		schemas.DefaultRoles,
+	)
+}
+
+// Fetch all roles with their permissions
+func (r *Role) FetchRoles() (*schemas.Roles, *errors.Error) {
+	roles, err := r.Adapter.Role.FetchPostgresqlRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.Roles{Roles: roles}, nil
+}
+
+// Fetch every permission that can be granted to a role
+func (r *Role) FetchPermissions() (*schemas.Permissions, *errors.Error) {
+	permissions, err := r.Adapter.Role.FetchPostgresqlPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.Permissions{Permissions: permissions}, nil
+}
+
+// Replaces the permissions of a role. The administrator role can not be edited.
+func (r *Role) UpdateRolePermissions(
+	roleId uuid.UUID,
+	request schemas.UpdateRolePermissionsRequest,
+	updatedBy string,
+) (*schemas.Role, *errors.Error) {
+	role, err := r.Adapter.Role.GetPostgresqlRole(roleId)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == schemas.UserRolAdmin {
+		return nil, &errors.BadRequestError.AdministratorRoleNotEditable
+	}
+
+	if err := r.Adapter.Role.SetPostgresqlRolePermissions(
+		roleId,
+		request.Permissions,
+		updatedBy,
+	); err != nil {
+		return nil, err
+	}
+
+	return r.Adapter.Role.GetPostgresqlRole(roleId)
+}
+
+// Gets every permission of a user, from their primary rol and their role assignments
+func (r *Role) GetUserPermissions(userId uuid.UUID) (*schemas.UserPermissions, *errors.Error) {
+	user, err := r.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := r.Adapter.Role.GetPostgresqlUserPermissionGrants(user.Id, user.Rol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.UserPermissions{UserId: user.Id, Grants: grants}, nil
+}
+
+// Checks that a user has a permission, administrators have all of them. Grants limited to a
+// community only count when the community of the request is given and matches.
+func (r *Role) CheckPermission(
+	userId uuid.UUID,
+	permission string,
+	communityId *uuid.UUID,
+) *errors.Error {
+	return r.CheckRequestPermission(userId, permission, schemas.PermissionResources{
+		CommunityId: communityId,
+	})
+}
+
+// Checks that a user has a permission over the resources a request refers to. Grants limited to a
+// community only count when every resource belongs to one of the granted communities, which are
+// only looked up when the user has no grant that applies everywhere.
+func (r *Role) CheckRequestPermission(
+	userId uuid.UUID,
+	permission string,
+	resources schemas.PermissionResources,
+) *errors.Error {
+	scope, err := getPermissionScope(r.Adapter, userId, permission)
+	if err != nil {
+		return err
+	}
+
+	covered, err := scope.covers(r.Adapter, resources)
+	if err != nil {
+		return err
+	}
+	if !covered {
+		return &errors.ForbiddenError.InsufficientPrivileges
+	}
+
+	return nil
+}
+
+// Where a permission of a user applies: everywhere, or only in some communities
+type permissionScope struct {
+	everywhere   bool
+	communityIds map[uuid.UUID]bool
+}
+
+// Gets where a user has a permission, administrators have all of them everywhere
+func getPermissionScope(
+	adapter *adapter.AdapterCollection,
+	userId uuid.UUID,
+	permission string,
+) (*permissionScope, *errors.Error) {
+	user, err := adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Rol == schemas.UserRolAdmin {
+		return &permissionScope{everywhere: true}, nil
+	}
+
+	grants, err := adapter.Role.GetPostgresqlUserPermissionGrants(user.Id, user.Rol)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := &permissionScope{communityIds: map[uuid.UUID]bool{}}
+	for _, grant := range grants {
+		if grant.Code != permission {
+			continue
+		}
+		if grant.CommunityId == nil {
+			scope.everywhere = true
+			continue
+		}
+		scope.communityIds[*grant.CommunityId] = true
+	}
+
+	return scope, nil
+}
+
+// Whether the scope covers every community the resources belong to. They are only looked up when
+// the permission is limited to some communities.
+func (s *permissionScope) covers(
+	adapter *adapter.AdapterCollection,
+	resources schemas.PermissionResources,
+) (bool, *errors.Error) {
+	if s.everywhere {
+		return true, nil
+	}
+	if len(s.communityIds) == 0 {
+		return false, nil
+	}
+
+	communityIds, err := resolveResourceCommunities(adapter, resources)
+	if err != nil {
+		if *err == errors.ForbiddenError.InsufficientPrivileges {
+			return false, nil
+		}
+		return false, err
+	}
+	if len(communityIds) == 0 {
+		return false, nil
+	}
+	for _, communityId := range communityIds {
+		if !s.communityIds[communityId] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Gets the communities the resources of a request belong to. Sessions and series belong to the
+// community of their community service and reservations to the one of their session.
+func resolveResourceCommunities(
+	adapter *adapter.AdapterCollection,
+	resources schemas.PermissionResources,
+) ([]uuid.UUID, *errors.Error) {
+	communityIds := []uuid.UUID{}
+	communityServiceIds := []uuid.UUID{}
+	sessionIds := []uuid.UUID{}
+
+	if resources.CommunityId != nil {
+		communityIds = append(communityIds, *resources.CommunityId)
+	}
+	if resources.CommunityServiceId != nil {
+		communityServiceIds = append(communityServiceIds, *resources.CommunityServiceId)
+	}
+	if resources.SessionId != nil {
+		sessionIds = append(sessionIds, *resources.SessionId)
+	}
+	if resources.CancellationPolicyId != nil {
+		policy, err := adapter.CancellationPolicy.GetPostgresqlCancellationPolicy(*resources.CancellationPolicyId)
+		if err != nil {
+			return nil, err
+		}
+		communityIds = append(communityIds, policy.CommunityId)
+	}
+	if resources.SessionSeriesId != nil {
+		series, err := adapter.SessionSeries.GetPostgresqlSessionSeries(*resources.SessionSeriesId)
+		if err != nil {
+			return nil, err
+		}
+		if series.CommunityServiceId == nil {
+			return nil, &errors.ForbiddenError.InsufficientPrivileges
+		}
+		communityServiceIds = append(communityServiceIds, *series.CommunityServiceId)
+	}
+	if resources.ReservationId != nil {
+		reservation, err := adapter.Reservation.GetPostgresqlReservation(*resources.ReservationId)
+		if err != nil {
+			return nil, err
+		}
+		sessionIds = append(sessionIds, reservation.SessionId)
+	}
+
+	for _, sessionId := range sessionIds {
+		session, err := adapter.Session.GetPostgresqlSession(sessionId)
+		if err != nil {
+			return nil, err
+		}
+		if session.CommunityServiceId == nil {
+			return nil, &errors.ForbiddenError.InsufficientPrivileges
+		}
+		communityServiceIds = append(communityServiceIds, *session.CommunityServiceId)
+	}
+	for _, communityServiceId := range communityServiceIds {
+		communityService, err := adapter.CommunityService.GetPostgresqlCommunityServiceById(communityServiceId)
+		if err != nil {
+			return nil, err
+		}
+		communityIds = append(communityIds, communityService.CommunityId)
+	}
+
+	return communityIds, nil
+}
+
+// Fetch the role assignments of a user
+func (r *Role) FetchUserRoleAssignments(
+	userId uuid.UUID,
+) (*schemas.UserRoleAssignments, *errors.Error) {
+	if _, err := r.Adapter.User.GetPostgresqlUser(userId); err != nil {
+		return nil, err
+	}
+
+	assignments, err := r.Adapter.UserRoleAssignment.FetchPostgresqlUserRoleAssignmentsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.UserRoleAssignments{UserRoleAssignments: assignments}, nil
+}
+
+// Grants a role to a user. Community scoped roles must name an existing community and the
+// other roles can not be limited to one.
+func (r *Role) CreateUserRoleAssignment(
+	userId uuid.UUID,
+	request schemas.CreateUserRoleAssignmentRequest,
+	updatedBy string,
+) (*schemas.UserRoleAssignment, *errors.Error) {
+	if _, err := r.Adapter.User.GetPostgresqlUser(userId); err != nil {
+		return nil, err
+	}
+
+	role, err := r.Adapter.Role.GetPostgresqlRoleByName(request.RoleName)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.CommunityScoped && request.CommunityId == nil {
+		return nil, &errors.BadRequestError.CommunityRequiredForRole
+	}
+	if !role.CommunityScoped && request.CommunityId != nil {
+		return nil, &errors.BadRequestError.CommunityNotAllowedForRole
+	}
+	if request.CommunityId != nil {
+		if _, err := r.Adapter.Community.GetPostgresqlCommunity(*request.CommunityId); err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := r.Adapter.UserRoleAssignment.ExistsPostgresqlUserRoleAssignment(
+		userId,
+		role.Id,
+		request.CommunityId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, &errors.ConflictError.UserRoleAssignmentAlreadyExists
+	}
+
+	return r.Adapter.UserRoleAssignment.CreatePostgresqlUserRoleAssignment(
+		userId,
+		role,
+		request.CommunityId,
+		updatedBy,
+	)
+}
+
+// Revokes a role assignment
+func (r *Role) DeleteUserRoleAssignment(assignmentId uuid.UUID) *errors.Error {
+	return r.Adapter.UserRoleAssignment.DeletePostgresqlUserRoleAssignment(assignmentId)
+}
+
+// Checks that a rol can be the primary rol of a user. Community scoped roles would apply to every
+// community there, so they are only granted through role assignments. Unless `requireKnown` is set,
+// rols without a role are let through since they grant nothing.
+func checkPrimaryRol(rol string, requireKnown bool) *errors.Error {
+	for _, definition := range schemas.DefaultRoles {
+		if string(definition.Name) != rol {
+			continue
+		}
+		if definition.CommunityScoped {
+			return &errors.BadRequestError.CommunityScopedRoleNotPrimary
+		}
+		return nil
+	}
+
+	if requireKnown {
+		return &errors.BadRequestError.InvalidUserRol
+	}
+	return nil
+}
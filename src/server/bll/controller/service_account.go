@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+const (
+	// Marks API keys so they are easy to recognize, e.g. by secret scanners
+	apiKeyPrefix = "acsk_"
+	// Characters of the key kept in clear to tell keys apart
+	apiKeyDisplayLength = 12
+	// Minimum time between two writes of the last use of a key
+	apiKeyLastUsedInterval = time.Minute
+)
+
+type ServiceAccount struct {
+	Logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+func NewServiceAccountController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *ServiceAccount {
+	return &ServiceAccount{
+		Logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+func (s *ServiceAccount) FetchServiceAccounts() (*schemas.ServiceAccounts, *errors.Error) {
+	serviceAccounts, err := s.Adapter.ServiceAccount.FetchPostgresqlServiceAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.ServiceAccounts{ServiceAccounts: serviceAccounts}, nil
+}
+
+// Creates a service account and its first API key. The key is only returned here, only its hash
+// is stored.
+func (s *ServiceAccount) CreateServiceAccount(
+	request schemas.CreateServiceAccountRequest,
+	updatedBy string,
+) (*schemas.ServiceAccountWithKey, *errors.Error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, &errors.UnprocessableEntityError.InvalidRequestBody
+	}
+	if err := s.validateScopes(request.Scopes); err != nil {
+		return nil, err
+	}
+	if err := validateApiKeyExpiration(request.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if existing, _ := s.Adapter.ServiceAccount.GetPostgresqlServiceAccountByName(name); existing != nil {
+		return nil, &errors.ConflictError.ServiceAccountAlreadyExists
+	}
+
+	apiKey, keyErr := generateApiKey()
+	if keyErr != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	serviceAccount, err := s.Adapter.ServiceAccount.CreatePostgresqlServiceAccount(
+		name,
+		request.Description,
+		request.Scopes,
+		apiKey[:apiKeyDisplayLength],
+		utils.HashToken(apiKey),
+		request.ExpiresAt,
+		updatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.ServiceAccountWithKey{ServiceAccount: serviceAccount, ApiKey: apiKey}, nil
+}
+
+// Replaces the API key of a service account. The previous key stops working right away and a
+// revoked account becomes active again with the new key.
+func (s *ServiceAccount) RotateServiceAccountKey(
+	serviceAccountId uuid.UUID,
+	request schemas.RotateServiceAccountKeyRequest,
+	updatedBy string,
+) (*schemas.ServiceAccountWithKey, *errors.Error) {
+	if err := validateApiKeyExpiration(request.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	apiKey, keyErr := generateApiKey()
+	if keyErr != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	if err := s.Adapter.ServiceAccount.RotatePostgresqlServiceAccountKey(
+		serviceAccountId,
+		apiKey[:apiKeyDisplayLength],
+		utils.HashToken(apiKey),
+		request.ExpiresAt,
+		updatedBy,
+	); err != nil {
+		return nil, err
+	}
+
+	serviceAccount, err := s.Adapter.ServiceAccount.GetPostgresqlServiceAccount(serviceAccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.ServiceAccountWithKey{ServiceAccount: serviceAccount, ApiKey: apiKey}, nil
+}
+
+// Revokes the API key of a service account until it is rotated
+func (s *ServiceAccount) RevokeServiceAccountKey(
+	serviceAccountId uuid.UUID,
+	updatedBy string,
+) *errors.Error {
+	return s.Adapter.ServiceAccount.RevokePostgresqlServiceAccountKey(serviceAccountId, updatedBy)
+}
+
+// Validates the API key of a request and returns its service account. The result is kept in the
+// request context so the middlewares and the handler only check the key once.
+func (s *ServiceAccount) ApiKeyValidation(c echo.Context) (*schemas.ServiceAccount, *errors.Error) {
+	if serviceAccount, ok := c.Get(schemas.ServiceAccountContextKey).(*schemas.ServiceAccount); ok {
+		return serviceAccount, nil
+	}
+
+	apiKey := c.Request().Header.Get(schemas.ApiKeyHeader)
+	if apiKey == "" {
+		return nil, &errors.AuthenticationError.InvalidApiKey
+	}
+
+	serviceAccount, err := s.Authenticate(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set(schemas.ServiceAccountContextKey, serviceAccount)
+	return serviceAccount, nil
+}
+
+// Returns the service account of an API key, rejecting revoked and expired keys
+func (s *ServiceAccount) Authenticate(apiKey string) (*schemas.ServiceAccount, *errors.Error) {
+	serviceAccount, err := s.Adapter.ServiceAccount.GetPostgresqlServiceAccountByKeyHash(
+		utils.HashToken(apiKey),
+	)
+	if err != nil {
+		return nil, &errors.AuthenticationError.InvalidApiKey
+	}
+	if serviceAccount.RevokedAt != nil {
+		return nil, &errors.AuthenticationError.InvalidApiKey
+	}
+	if serviceAccount.ExpiresAt != nil && !time.Now().Before(*serviceAccount.ExpiresAt) {
+		return nil, &errors.AuthenticationError.InvalidApiKey
+	}
+
+	if err := s.Adapter.ServiceAccount.TouchPostgresqlServiceAccountLastUsed(
+		serviceAccount.Id,
+		apiKeyLastUsedInterval,
+	); err != nil {
+		s.Logger.Error("Failed to record the use of an API key: ", err.Message)
+	}
+
+	return serviceAccount, nil
+}
+
+// Checks that a service account was granted a permission
+func (s *ServiceAccount) CheckScope(
+	serviceAccount *schemas.ServiceAccount,
+	permission string,
+) *errors.Error {
+	for _, scope := range serviceAccount.Scopes {
+		if scope == permission {
+			return nil
+		}
+	}
+
+	return &errors.ForbiddenError.InsufficientPrivileges
+}
+
+// Builds the credentials of a service account, which act on behalf of any user within its scopes
+func (s *ServiceAccount) GetCredentials(serviceAccount *schemas.ServiceAccount) *schemas.Credentials {
+	return &schemas.Credentials{
+		UserId:           serviceAccount.Id,
+		UserEmail:        serviceAccount.Name,
+		UserRoles:        []string{string(schemas.UserRolServiceAccount)},
+		UserName:         serviceAccount.Name,
+		ServiceAccountId: &serviceAccount.Id,
+	}
+}
+
+// Scopes must be permission codes of the catalog
+func (s *ServiceAccount) validateScopes(scopes []string) *errors.Error {
+	if len(scopes) == 0 {
+		return &errors.BadRequestError.InvalidServiceAccountScope
+	}
+
+	permissions, err := s.Adapter.Role.FetchPostgresqlPermissions()
+	if err != nil {
+		return err
+	}
+	knownCodes := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		knownCodes[permission.Code] = true
+	}
+
+	for _, scope := range scopes {
+		if !knownCodes[scope] {
+			return &errors.BadRequestError.InvalidServiceAccountScope
+		}
+	}
+
+	return nil
+}
+
+func validateApiKeyExpiration(expiresAt *time.Time) *errors.Error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return &errors.BadRequestError.InvalidServiceAccountExpiration
+	}
+	return nil
+}
+
+func generateApiKey() (string, error) {
+	secret, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + secret, nil
+}
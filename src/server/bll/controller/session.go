@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,12 +9,15 @@ import (
 	bllAdapter "onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
 )
 
 type Session struct {
-	logger      logging.Logger
-	Adapter     *bllAdapter.AdapterCollection
-	EnvSettings *schemas.EnvSettings
+	logger               logging.Logger
+	Adapter              *bllAdapter.AdapterCollection
+	EnvSettings          *schemas.EnvSettings
+	StandingBooking      *StandingBooking
+	ProfessionalSchedule *ProfessionalSchedule
 }
 
 // Create Session controller
@@ -21,11 +25,15 @@ func NewSessionController(
 	logger logging.Logger,
 	adapter *bllAdapter.AdapterCollection,
 	envSettings *schemas.EnvSettings,
+	standingBooking *StandingBooking,
+	professionalSchedule *ProfessionalSchedule,
 ) *Session {
 	return &Session{
-		logger:      logger,
-		Adapter:     adapter,
-		EnvSettings: envSettings,
+		logger:               logger,
+		Adapter:              adapter,
+		EnvSettings:          envSettings,
+		StandingBooking:      standingBooking,
+		ProfessionalSchedule: professionalSchedule,
 	}
 }
 
@@ -87,11 +95,11 @@ func (s *Session) CreateSession(
 			conflictDetails = append(conflictDetails, "conflicto de local")
 		}
 
-		return nil, &errors.ConflictError.SessionTimeConflict
+		return nil, sessionConflictError(conflictResult)
 	}
 
 	// Create session if no conflicts
-	return s.Adapter.Session.CreatePostgresqlSession(
+	session, err := s.Adapter.Session.CreatePostgresqlSession(
 		req.Title,
 		req.Date,
 		req.StartTime,
@@ -103,6 +111,12 @@ func (s *Session) CreateSession(
 		req.CommunityServiceId,
 		updatedBy,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.bookStandingBookings([]*schemas.Session{session})
+	return session, nil
 }
 
 // Gets a session.
@@ -200,45 +214,11 @@ func (s *Session) UpdateSession(
 				conflictDetails = append(conflictDetails, "conflicto de local")
 			}
 
-			return nil, &errors.ConflictError.SessionTimeConflict
+			return nil, sessionConflictError(conflictResult)
 		}
 	}
 
-	// If the session is being cancelled, update all related reservations to ANNULLED
-	if req.State != nil && *req.State == "CANCELLED" {
-		// Get all reservations for this session
-		reservations, err := s.Adapter.Reservation.FetchPostgresqlReservations(
-			[]uuid.UUID{},          // No user filter
-			[]uuid.UUID{sessionId}, // Filter by this session
-			[]string{},             // No state filter
-		)
-		if err != nil {
-			s.logger.Warn("Error fetching reservations for session", "error", err)
-			// Continue with session update even if reservation fetch fails
-		} else {
-			// Update each reservation to ANULLED state
-			annulledState := "ANULLED"
-			for _, reservation := range reservations {
-				_, updateErr := s.Adapter.Reservation.UpdatePostgresqlReservation(
-					reservation.Id,
-					nil,            // No name change
-					nil,            // No reservation time change
-					&annulledState, // Change state to ANNULLED
-					nil,            // No user change
-					nil,            // No session change
-					nil,            // No membership change
-					updatedBy,
-				)
-				if updateErr != nil {
-					s.logger.Warn("Error updating reservation status", "reservationId", reservation.Id, "error", updateErr)
-					// Continue with other reservations even if one fails
-				}
-			}
-			s.logger.Info("Updated reservations to ANNULLED for cancelled session", "sessionId", sessionId, "count", len(reservations))
-		}
-	}
-
-	return s.Adapter.Session.UpdatePostgresqlSession(
+	session, previous, annulled, err := s.Adapter.Session.UpdatePostgresqlSession(
 		sessionId,
 		req.Title,
 		req.Date,
@@ -253,6 +233,46 @@ func (s *Session) UpdateSession(
 		req.CommunityServiceId,
 		updatedBy,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cancelling a session annuls its reservations, giving back their membership uses, and
+	// rescheduling it carries them over to the new time. Both are told to the attendees only when
+	// the stored session actually changed, so repeating an update does not email them again.
+	affected := annulled
+	if session.State != "CANCELLED" && sessionRescheduled(previous, session) {
+		affected, err = s.Adapter.Reservation.FetchPostgresqlReservations(
+			nil,
+			[]uuid.UUID{sessionId},
+			[]string{"CONFIRMED", "WAITLISTED"},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(affected) == 0 {
+		return session, nil
+	}
+
+	session.AffectedReservationIds = make([]uuid.UUID, len(affected))
+	for i, reservation := range affected {
+		session.AffectedReservationIds[i] = reservation.Id
+	}
+	s.notifySessionAttendees(session, affected)
+
+	return session, nil
+}
+
+// Tells whether an update moved a session to another time or into the RESCHEDULED state
+func sessionRescheduled(previous *schemas.Session, session *schemas.Session) bool {
+	if session.State == "RESCHEDULED" && previous.State != "RESCHEDULED" {
+		return true
+	}
+
+	return !session.Date.Equal(previous.Date) ||
+		!session.StartTime.Equal(previous.StartTime) ||
+		!session.EndTime.Equal(previous.EndTime)
 }
 
 // Soft deletes a session.
@@ -381,7 +401,7 @@ func (s *Session) BulkCreateSessions(
 			return nil, conflictErr
 		}
 		if conflictResult.HasConflict {
-			return nil, &errors.ConflictError.SessionTimeConflict
+			return nil, sessionConflictError(conflictResult)
 		}
 		// 2. Check against rest of batch (internal conflicts)
 		for j, other := range createSessionsData {
@@ -409,6 +429,7 @@ func (s *Session) BulkCreateSessions(
 		return nil, err
 	}
 
+	s.bookStandingBookings(sessions)
 	return &schemas.Sessions{Sessions: sessions}, nil
 }
 
@@ -460,12 +481,25 @@ func (s *Session) CheckConflicts(
 		}
 	}
 
-	hasConflict := len(professionalConflicts) > 0 || len(localConflicts) > 0
+	// Check the working hours and time off of the professional
+	outsideWorkingHours, timeOffConflicts, err := s.ProfessionalSchedule.CheckSchedule(
+		req.ProfessionalId,
+		req.StartTime,
+		req.EndTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hasConflict := len(professionalConflicts) > 0 || len(localConflicts) > 0 ||
+		outsideWorkingHours || len(timeOffConflicts) > 0
 
 	return &schemas.ConflictResult{
 		HasConflict:           hasConflict,
 		ProfessionalConflicts: professionalConflicts,
 		LocalConflicts:        localConflicts,
+		OutsideWorkingHours:   outsideWorkingHours,
+		TimeOffConflicts:      timeOffConflicts,
 	}, nil
 }
 
@@ -526,6 +560,15 @@ func (s *Session) GetAvailability(
 		}
 	}
 
+	// Add the slots the professional is not working or is on time off
+	if req.ProfessionalId != nil {
+		unavailableSlots, err := s.ProfessionalSchedule.GetUnavailableSlots(*req.ProfessionalId, req.Date)
+		if err != nil {
+			return nil, err
+		}
+		busySlots = append(busySlots, unavailableSlots...)
+	}
+
 	isAvailable := len(busySlots) == 0
 
 	return &schemas.AvailabilityResult{
@@ -534,6 +577,30 @@ func (s *Session) GetAvailability(
 	}, nil
 }
 
+// Moves the sessions that started before `now` to ONGOING and the ones already over to COMPLETED.
+// Cancelled sessions are left as they are.
+func (s *Session) AdvanceSessionStates(now time.Time) (*schemas.SessionLifecycleSweep, *errors.Error) {
+	started, completed, err := s.Adapter.Session.AdvancePostgresqlSessionStates(now, "SYSTEM")
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.SessionLifecycleSweep{StartedSessions: started, CompletedSessions: completed}, nil
+}
+
+// Gets the error of a conflict: other sessions come first, then the schedule of the professional
+func sessionConflictError(result *schemas.ConflictResult) *errors.Error {
+	switch {
+	case len(result.ProfessionalConflicts) > 0 || len(result.LocalConflicts) > 0:
+		return &errors.ConflictError.SessionTimeConflict
+	case len(result.TimeOffConflicts) > 0:
+		return &errors.ConflictError.ProfessionalOnTimeOff
+	case result.OutsideWorkingHours:
+		return &errors.ConflictError.ProfessionalOutsideWorkingHours
+	}
+	return &errors.ConflictError.SessionTimeConflict
+}
+
 // Helper function to check if two dates are the same day
 func (s *Session) isSameDate(date1, date2 time.Time) bool {
 	y1, m1, d1 := date1.Date()
@@ -541,6 +608,51 @@ func (s *Session) isSameDate(date1, date2 time.Time) bool {
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
 
+// Books new sessions for the standing bookings they match. A failure never undoes the creation of
+// the sessions, the standing booking job tries them again.
+func (s *Session) bookStandingBookings(sessions []*schemas.Session) {
+	for _, session := range sessions {
+		if _, err := s.StandingBooking.BookSession(session); err != nil {
+			s.logger.Warnf("Failed to book session %s for standing bookings: %v", session.Id, err.Message)
+		}
+	}
+}
+
+// Lets the users of the reservations of a cancelled or rescheduled session know about it. Failures
+// are only logged, the change of the session stands either way.
+func (s *Session) notifySessionAttendees(session *schemas.Session, reservations []*schemas.Reservation) {
+	for _, reservation := range reservations {
+		user, err := s.Adapter.User.GetPostgresqlUser(reservation.UserId)
+		if err != nil {
+			s.logger.Warnf("Failed to get the user of reservation %s: %v", reservation.Id, err.Message)
+			continue
+		}
+
+		subject := "Tu sesión en ZenCat fue reprogramada"
+		body := fmt.Sprintf(
+			"Hola %s,\n\nLa sesión que reservaste cambió de horario y tu reserva se mantiene:\n\n🧘 Sesión: %s\n📅 Fecha: %s\n🕘 Hora: %s\n\nSi ya no puedes asistir, cancela tu reserva para liberar el cupo.\n\nGracias por ser parte de ZenCat 🌿",
+			user.Name,
+			session.Title,
+			session.Date.Format("02/01/2006"),
+			session.StartTime.Format("15:04"),
+		)
+		if session.State == "CANCELLED" {
+			subject = "Tu sesión en ZenCat fue cancelada"
+			body = fmt.Sprintf(
+				"Hola %s,\n\nLa sesión que reservaste fue cancelada y tu reserva fue anulada:\n\n🧘 Sesión: %s\n📅 Fecha: %s\n🕘 Hora: %s\n\nLa reserva fue devuelta a tu membresía.\n\nGracias por ser parte de ZenCat 🌿",
+				user.Name,
+				session.Title,
+				session.Date.Format("02/01/2006"),
+				session.StartTime.Format("15:04"),
+			)
+		}
+
+		if emailErr := utils.SendEmail(s.EnvSettings, user.Email, subject, body); emailErr != nil {
+			s.logger.Warnf("Failed to send session change email: %v", emailErr)
+		}
+	}
+}
+
 // Helper function to check if two time ranges overlap
 func (s *Session) hasTimeOverlap(start1, end1, start2, end2 time.Time) bool {
 	return start1.Before(end2) && end1.After(start2)
@@ -559,6 +671,7 @@ func (s *Session) BatchCreateSessions(
 		return nil, err
 	}
 
+	s.bookStandingBookings(sessions)
 	return &schemas.Sessions{Sessions: sessions}, nil
 }
 
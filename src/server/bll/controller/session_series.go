@@ -0,0 +1,598 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	bllAdapter "onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+type SessionSeries struct {
+	logger      logging.Logger
+	Adapter     *bllAdapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+	Session     *Session
+}
+
+// Create SessionSeries controller
+func NewSessionSeriesController(
+	logger logging.Logger,
+	adapter *bllAdapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+	session *Session,
+) *SessionSeries {
+	return &SessionSeries{
+		logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+		Session:     session,
+	}
+}
+
+// Gets a session series.
+func (ss *SessionSeries) GetSessionSeries(seriesId uuid.UUID) (*schemas.SessionSeries, *errors.Error) {
+	return ss.Adapter.SessionSeries.GetPostgresqlSessionSeries(seriesId)
+}
+
+// Fetch all session series, filtered by `professionalIds` if provided.
+func (ss *SessionSeries) FetchSessionSeries(
+	professionalIds []string,
+) (*schemas.SessionSeriesList, *errors.Error) {
+	parsedProfessionalIds := []uuid.UUID{}
+	for _, id := range professionalIds {
+		parsedId, err := uuid.Parse(id)
+		if err != nil {
+			return nil, &errors.UnprocessableEntityError.InvalidProfessionalId
+		}
+		parsedProfessionalIds = append(parsedProfessionalIds, parsedId)
+	}
+
+	series, err := ss.Adapter.SessionSeries.FetchPostgresqlSessionSeries(parsedProfessionalIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.SessionSeriesList{Series: series}, nil
+}
+
+// Fetch the sessions generated for a series so far, by the day they were generated for.
+func (ss *SessionSeries) FetchSessionSeriesOccurrences(
+	seriesId uuid.UUID,
+) (*schemas.SessionSeriesOccurrences, *errors.Error) {
+	if _, err := ss.Adapter.SessionSeries.GetPostgresqlSessionSeries(seriesId); err != nil {
+		return nil, err
+	}
+
+	occurrences, err := ss.Adapter.SessionSeries.FetchPostgresqlSeriesOccurrences(seriesId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.SessionSeriesOccurrences{Occurrences: occurrences}, nil
+}
+
+// Creates a session series and generates its sessions within the horizon. Occurrences that
+// conflict with other sessions are skipped and reported; later ones are generated as the horizon
+// moves forward.
+func (ss *SessionSeries) CreateSessionSeries(
+	request schemas.CreateSessionSeriesRequest,
+	updatedBy string,
+) (*schemas.SessionSeriesReport, *errors.Error) {
+	rule, exceptions, err := parseSessionSeriesRecurrence(request.Rule, request.ExceptionDates)
+	if err != nil {
+		return nil, err
+	}
+	request.Rule = rule.String()
+	request.ExceptionDates = utils.FormatRecurrenceDates(exceptions)
+
+	startDate, parseErr := time.Parse(time.DateOnly, request.StartDate)
+	if parseErr != nil || request.DurationMinutes <= 0 {
+		return nil, &errors.BadRequestError.InvalidSessionSeriesSchedule
+	}
+	startTime, parseErr := time.Parse("15:04", request.StartTime)
+	if parseErr != nil {
+		return nil, &errors.BadRequestError.InvalidSessionSeriesSchedule
+	}
+	request.StartTime = startTime.Format("15:04")
+
+	if _, err := ss.Adapter.Professional.GetPostgresqlProfessional(request.ProfessionalId); err != nil {
+		return nil, err
+	}
+	if request.LocalId != nil {
+		if _, err := ss.Adapter.Local.GetPostgresqlLocal(*request.LocalId); err != nil {
+			return nil, err
+		}
+	}
+	if request.CommunityServiceId != nil {
+		if _, err := ss.Adapter.CommunityService.GetPostgresqlCommunityServiceById(*request.CommunityServiceId); err != nil {
+			return nil, err
+		}
+	}
+
+	series, err := ss.Adapter.SessionSeries.CreatePostgresqlSessionSeries(request, startDate, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.generateSessions(series, ss.horizon(), updatedBy)
+}
+
+// Generates the sessions of every series up to the horizon. A failing series does not stop the
+// rest, the next run tries it again.
+func (ss *SessionSeries) GenerateUpcomingSessions() ([]*schemas.SessionSeriesReport, *errors.Error) {
+	until := ss.horizon()
+	seriesList, err := ss.Adapter.SessionSeries.FetchPostgresqlSessionSeriesToGenerate(until)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []*schemas.SessionSeriesReport{}
+	for _, series := range seriesList {
+		report, err := ss.generateSessions(series, until, "SYSTEM")
+		if err != nil {
+			ss.logger.Warnf("Failed to generate the sessions of series %s: %v", series.Id, err.Message)
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// Edits an occurrence of a series, this occurrence and the following ones, or every upcoming
+// occurrence. Editing the following ones splits the series in two at the occurrence, so that the
+// occurrences generated later follow the changes as well. Occurrences edited on their own are
+// left as they are by the edits of several occurrences, and those that would conflict with other
+// sessions are skipped and reported.
+func (ss *SessionSeries) UpdateSessionSeriesOccurrences(
+	seriesId uuid.UUID,
+	sessionId uuid.UUID,
+	scope string,
+	request schemas.UpdateSessionSeriesRequest,
+	updatedBy string,
+) (*schemas.SessionSeriesReport, *errors.Error) {
+	if err := validateSessionSeriesScope(scope); err != nil {
+		return nil, err
+	}
+	if err := ss.validateSeriesChanges(&request); err != nil {
+		return nil, err
+	}
+
+	series, err := ss.Adapter.SessionSeries.GetPostgresqlSessionSeries(seriesId)
+	if err != nil {
+		return nil, err
+	}
+	occurrence, err := ss.findOccurrence(seriesId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if !occurrence.Session.StartTime.After(time.Now()) {
+		return nil, &errors.BadRequestError.SessionSeriesOccurrenceStarted
+	}
+
+	if scope == schemas.SessionSeriesScopeThis {
+		updated, err := ss.Session.UpdateSession(sessionId, ss.occurrenceChanges(occurrence, request), updatedBy)
+		if err != nil {
+			return nil, err
+		}
+		if err := ss.Adapter.SessionSeries.DetachPostgresqlSeriesSession(sessionId, updatedBy); err != nil {
+			return nil, err
+		}
+
+		return newSessionSeriesReport(series, []*schemas.Session{updated}), nil
+	}
+
+	startDate, _ := time.Parse(time.DateOnly, series.StartDate)
+	day, _ := time.Parse(time.DateOnly, occurrence.Date)
+	if scope == schemas.SessionSeriesScopeAll || day.Equal(startDate) {
+		series, err = ss.Adapter.SessionSeries.UpdatePostgresqlSessionSeries(seriesId, request, nil, updatedBy)
+	} else {
+		rule, ruleErr := parseStoredSessionSeriesRule(series)
+		if ruleErr != nil {
+			return nil, ruleErr
+		}
+
+		generatedUntil, _ := time.Parse(time.DateOnly, series.GeneratedUntil)
+		if generatedUntil.Before(day) {
+			generatedUntil = day
+		}
+
+		series, err = ss.Adapter.SessionSeries.SplitPostgresqlSessionSeries(
+			series,
+			rule.EndingBefore(startDate, day).String(),
+			request,
+			day,
+			rule.StartingOn(startDate, day).String(),
+			generatedUntil,
+			updatedBy,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := ss.Adapter.SessionSeries.FetchPostgresqlSeriesOccurrences(series.Id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newSessionSeriesReport(series, []*schemas.Session{})
+	now := time.Now()
+	for _, occurrence := range occurrences {
+		if occurrence.Detached || !isOpenSeriesOccurrence(occurrence, now) {
+			continue
+		}
+
+		updated, err := ss.Session.UpdateSession(
+			occurrence.Session.Id,
+			ss.occurrenceChanges(occurrence, request),
+			updatedBy,
+		)
+		if err != nil {
+			addSessionSeriesSkip(report, occurrence, *err)
+			continue
+		}
+		report.Sessions = append(report.Sessions, updated)
+	}
+
+	return report, nil
+}
+
+// Cancels an occurrence of a series, this occurrence and the following ones, or every upcoming
+// occurrence. Cancelling several occurrences also ends the recurrence before the first of them,
+// so that no more sessions are generated. Reservations of cancelled sessions are annulled.
+func (ss *SessionSeries) CancelSessionSeriesOccurrences(
+	seriesId uuid.UUID,
+	sessionId uuid.UUID,
+	scope string,
+	updatedBy string,
+) (*schemas.SessionSeriesReport, *errors.Error) {
+	if err := validateSessionSeriesScope(scope); err != nil {
+		return nil, err
+	}
+
+	series, err := ss.Adapter.SessionSeries.GetPostgresqlSessionSeries(seriesId)
+	if err != nil {
+		return nil, err
+	}
+	occurrence, err := ss.findOccurrence(seriesId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cancelled := "CANCELLED"
+	if scope != schemas.SessionSeriesScopeAll && !occurrence.Session.StartTime.After(now) {
+		return nil, &errors.BadRequestError.SessionSeriesOccurrenceStarted
+	}
+
+	if scope == schemas.SessionSeriesScopeThis {
+		updated, err := ss.Session.UpdateSession(
+			sessionId,
+			schemas.UpdateSessionRequest{State: &cancelled},
+			updatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return newSessionSeriesReport(series, []*schemas.Session{updated}), nil
+	}
+
+	occurrences, err := ss.Adapter.SessionSeries.FetchPostgresqlSeriesOccurrences(seriesId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole series ends before its first occurrence yet to start, or where generation stands
+	cutDay, _ := time.Parse(time.DateOnly, occurrence.Date)
+	if scope == schemas.SessionSeriesScopeAll {
+		cutDay, _ = time.Parse(time.DateOnly, series.GeneratedUntil)
+		for _, other := range occurrences {
+			if other.Session.StartTime.After(now) {
+				cutDay, _ = time.Parse(time.DateOnly, other.Date)
+				break
+			}
+		}
+	}
+
+	rule, err := parseStoredSessionSeriesRule(series)
+	if err != nil {
+		return nil, err
+	}
+	startDate, _ := time.Parse(time.DateOnly, series.StartDate)
+	truncatedRule := rule.EndingBefore(startDate, cutDay).String()
+	series, err = ss.Adapter.SessionSeries.UpdatePostgresqlSessionSeries(
+		seriesId,
+		schemas.UpdateSessionSeriesRequest{},
+		&truncatedRule,
+		updatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newSessionSeriesReport(series, []*schemas.Session{})
+	for _, other := range occurrences {
+		day, _ := time.Parse(time.DateOnly, other.Date)
+		if day.Before(cutDay) || !isOpenSeriesOccurrence(other, now) {
+			continue
+		}
+
+		updated, err := ss.Session.UpdateSession(
+			other.Session.Id,
+			schemas.UpdateSessionRequest{State: &cancelled},
+			updatedBy,
+		)
+		if err != nil {
+			addSessionSeriesSkip(report, other, *err)
+			continue
+		}
+		report.Sessions = append(report.Sessions, updated)
+	}
+
+	return report, nil
+}
+
+// Generates the sessions of a series from where it stands up to the day before `until`. Each day
+// only gets its session once, even with runs on several instances, and days already past are not
+// generated.
+func (ss *SessionSeries) generateSessions(
+	series *schemas.SessionSeries,
+	until time.Time,
+	updatedBy string,
+) (*schemas.SessionSeriesReport, *errors.Error) {
+	rule, err := parseStoredSessionSeriesRule(series)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, _ := utils.ParseRecurrenceDates(series.ExceptionDates)
+
+	startDate, _ := time.Parse(time.DateOnly, series.StartDate)
+	from, _ := time.Parse(time.DateOnly, series.GeneratedUntil)
+	if today := ss.today(); from.Before(today) {
+		from = today
+	}
+
+	report := newSessionSeriesReport(series, []*schemas.Session{})
+	now := time.Now()
+	for _, day := range rule.Occurrences(startDate, from, until, exceptions) {
+		startTime, endTime := ss.occurrenceTimes(
+			day,
+			series.StartTime,
+			time.Duration(series.DurationMinutes)*time.Minute,
+		)
+		if !startTime.After(now) {
+			continue
+		}
+
+		conflictResult, err := ss.Session.CheckConflicts(schemas.CheckConflictRequest{
+			Date:               startTime,
+			StartTime:          startTime,
+			EndTime:            endTime,
+			ProfessionalId:     series.ProfessionalId,
+			LocalId:            series.LocalId,
+			CommunityServiceId: series.CommunityServiceId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if conflictResult.HasConflict {
+			conflictErr := sessionConflictError(conflictResult)
+			report.Skipped = append(report.Skipped, &schemas.SessionSeriesSkip{
+				Date:   day.Format(time.DateOnly),
+				Reason: conflictErr.Code,
+				Detail: conflictErr.Message,
+			})
+			continue
+		}
+
+		session, err := ss.Adapter.SessionSeries.CreatePostgresqlSeriesSession(
+			series,
+			day,
+			startTime,
+			endTime,
+			updatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			report.Sessions = append(report.Sessions, session)
+		}
+	}
+
+	if err := ss.Adapter.SessionSeries.AdvancePostgresqlSessionSeries(series.Id, until); err != nil {
+		return nil, err
+	}
+	series.GeneratedUntil = until.Format(time.DateOnly)
+
+	ss.Session.bookStandingBookings(report.Sessions)
+	return report, nil
+}
+
+// Finds the occurrence of a series a session is
+func (ss *SessionSeries) findOccurrence(
+	seriesId uuid.UUID,
+	sessionId uuid.UUID,
+) (*schemas.SessionSeriesOccurrence, *errors.Error) {
+	occurrences, err := ss.Adapter.SessionSeries.FetchPostgresqlSeriesOccurrences(seriesId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, occurrence := range occurrences {
+		if occurrence.Session.Id == sessionId {
+			return occurrence, nil
+		}
+	}
+
+	if _, err := ss.Adapter.Session.GetPostgresqlSession(sessionId); err != nil {
+		return nil, err
+	}
+	return nil, &errors.BadRequestError.SessionNotInSeries
+}
+
+// Translates changes to a series into the changes of one of its sessions. New times keep the day
+// the session is on in the booking time zone.
+func (ss *SessionSeries) occurrenceChanges(
+	occurrence *schemas.SessionSeriesOccurrence,
+	request schemas.UpdateSessionSeriesRequest,
+) schemas.UpdateSessionRequest {
+	changes := schemas.UpdateSessionRequest{
+		Title:          request.Title,
+		Capacity:       request.Capacity,
+		SessionLink:    request.SessionLink,
+		ProfessionalId: request.ProfessionalId,
+		LocalId:        request.LocalId,
+	}
+
+	if request.StartTime != nil || request.DurationMinutes != nil {
+		localStart := occurrence.Session.StartTime.In(ss.EnvSettings.BookingTimeZone)
+
+		clock := localStart.Format("15:04")
+		if request.StartTime != nil {
+			clock = *request.StartTime
+		}
+		duration := occurrence.Session.EndTime.Sub(occurrence.Session.StartTime)
+		if request.DurationMinutes != nil {
+			duration = time.Duration(*request.DurationMinutes) * time.Minute
+		}
+
+		startTime, endTime := ss.occurrenceTimes(utils.TruncateToDay(localStart), clock, duration)
+		changes.Date = &startTime
+		changes.StartTime = &startTime
+		changes.EndTime = &endTime
+	}
+
+	return changes
+}
+
+// Checks and normalizes changes to a series
+func (ss *SessionSeries) validateSeriesChanges(request *schemas.UpdateSessionSeriesRequest) *errors.Error {
+	if request.StartTime != nil {
+		startTime, err := time.Parse("15:04", *request.StartTime)
+		if err != nil {
+			return &errors.BadRequestError.InvalidSessionSeriesSchedule
+		}
+		normalized := startTime.Format("15:04")
+		request.StartTime = &normalized
+	}
+	if request.DurationMinutes != nil && *request.DurationMinutes <= 0 {
+		return &errors.BadRequestError.InvalidSessionSeriesSchedule
+	}
+
+	if request.ProfessionalId != nil {
+		if _, err := ss.Adapter.Professional.GetPostgresqlProfessional(*request.ProfessionalId); err != nil {
+			return err
+		}
+	}
+	if request.LocalId != nil {
+		if _, err := ss.Adapter.Local.GetPostgresqlLocal(*request.LocalId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Gets when the occurrence of a day starts and ends, given the time of day it starts at in the
+// booking time zone
+func (ss *SessionSeries) occurrenceTimes(
+	day time.Time,
+	clock string,
+	duration time.Duration,
+) (time.Time, time.Time) {
+	startClock, _ := time.Parse("15:04", clock)
+	startTime := time.Date(
+		day.Year(),
+		day.Month(),
+		day.Day(),
+		startClock.Hour(),
+		startClock.Minute(),
+		0,
+		0,
+		ss.EnvSettings.BookingTimeZone,
+	)
+
+	return startTime, startTime.Add(duration)
+}
+
+// Gets the current day in the booking time zone
+func (ss *SessionSeries) today() time.Time {
+	return utils.TruncateToDay(time.Now().In(ss.EnvSettings.BookingTimeZone))
+}
+
+// Gets the day up to which, excluded, the sessions of series are generated
+func (ss *SessionSeries) horizon() time.Time {
+	return ss.today().AddDate(0, 0, ss.EnvSettings.SessionSeriesHorizonDays)
+}
+
+func parseSessionSeriesRecurrence(
+	rule string,
+	exceptionDates string,
+) (*utils.RecurrenceRule, []time.Time, *errors.Error) {
+	parsedRule, err := utils.ParseRecurrenceRule(rule)
+	if err != nil {
+		return nil, nil, &errors.BadRequestError.InvalidSessionSeriesRule
+	}
+	exceptions, err := utils.ParseRecurrenceDates(exceptionDates)
+	if err != nil {
+		return nil, nil, &errors.BadRequestError.InvalidSessionSeriesRule
+	}
+
+	return parsedRule, exceptions, nil
+}
+
+// Rules are validated before being stored, so a failure here means the data was changed by hand
+func parseStoredSessionSeriesRule(series *schemas.SessionSeries) (*utils.RecurrenceRule, *errors.Error) {
+	rule, err := utils.ParseRecurrenceRule(series.Rule)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+	return rule, nil
+}
+
+func validateSessionSeriesScope(scope string) *errors.Error {
+	switch scope {
+	case schemas.SessionSeriesScopeThis,
+		schemas.SessionSeriesScopeThisAndFollowing,
+		schemas.SessionSeriesScopeAll:
+		return nil
+	}
+	return &errors.BadRequestError.InvalidSessionSeriesScope
+}
+
+// Whether an occurrence can still be edited or cancelled along with the rest of its series
+func isOpenSeriesOccurrence(occurrence *schemas.SessionSeriesOccurrence, now time.Time) bool {
+	state := occurrence.Session.State
+	return (state == "SCHEDULED" || state == "RESCHEDULED") && occurrence.Session.StartTime.After(now)
+}
+
+func newSessionSeriesReport(
+	series *schemas.SessionSeries,
+	sessions []*schemas.Session,
+) *schemas.SessionSeriesReport {
+	return &schemas.SessionSeriesReport{
+		Series:   series,
+		Sessions: sessions,
+		Skipped:  []*schemas.SessionSeriesSkip{},
+	}
+}
+
+func addSessionSeriesSkip(
+	report *schemas.SessionSeriesReport,
+	occurrence *schemas.SessionSeriesOccurrence,
+	reason errors.Error,
+) {
+	report.Skipped = append(report.Skipped, &schemas.SessionSeriesSkip{
+		Date:      occurrence.Date,
+		SessionId: &occurrence.Session.Id,
+		Reason:    reason.Code,
+		Detail:    reason.Message,
+	})
+}
@@ -0,0 +1,463 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	bllAdapter "onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// Time after which an attempt that was claimed but never finished, e.g. by an instance that
+// crashed, can be taken over by another run
+const standingBookingClaimTimeout = 10 * time.Minute
+
+type StandingBooking struct {
+	logger      logging.Logger
+	Adapter     *bllAdapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+	Reservation *Reservation
+}
+
+// Create StandingBooking controller
+func NewStandingBookingController(
+	logger logging.Logger,
+	adapter *bllAdapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+	reservation *Reservation,
+) *StandingBooking {
+	return &StandingBooking{
+		logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+		Reservation: reservation,
+	}
+}
+
+// Gets a standing booking.
+func (sb *StandingBooking) GetStandingBooking(
+	standingBookingId uuid.UUID,
+) (*schemas.StandingBooking, *errors.Error) {
+	return sb.Adapter.StandingBooking.GetPostgresqlStandingBooking(standingBookingId)
+}
+
+// Fetch all standing bookings, filtered by `userIds` if provided.
+func (sb *StandingBooking) FetchStandingBookings(
+	userIds []string,
+) (*schemas.StandingBookings, *errors.Error) {
+	parsedUserIds := []uuid.UUID{}
+	for _, id := range userIds {
+		parsedId, err := uuid.Parse(id)
+		if err != nil {
+			return nil, &errors.UnprocessableEntityError.InvalidUserId
+		}
+		parsedUserIds = append(parsedUserIds, parsedId)
+	}
+
+	standingBookings, err := sb.Adapter.StandingBooking.FetchPostgresqlStandingBookings(parsedUserIds, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.StandingBookings{StandingBookings: standingBookings}, nil
+}
+
+// Subscribes a user to a recurring pattern and books the matching sessions already within the
+// booking window. Later sessions are booked as they are created or as the window reaches them.
+func (sb *StandingBooking) CreateStandingBooking(
+	request schemas.CreateStandingBookingRequest,
+	updatedBy string,
+) (*schemas.StandingBooking, *errors.Error) {
+	if request.Weekday < 0 || request.Weekday > 6 {
+		return nil, &errors.BadRequestError.InvalidStandingBookingPattern
+	}
+	startTime, parseErr := time.Parse("15:04", request.StartTime)
+	if parseErr != nil {
+		return nil, &errors.BadRequestError.InvalidStandingBookingPattern
+	}
+	request.StartTime = startTime.Format("15:04")
+
+	if _, err := sb.Adapter.User.GetPostgresqlUser(request.UserId); err != nil {
+		return nil, err
+	}
+	if _, err := sb.Adapter.Service.GetPostgresqlService(request.ServiceId); err != nil {
+		return nil, err
+	}
+	if _, err := sb.Adapter.Professional.GetPostgresqlProfessional(request.ProfessionalId); err != nil {
+		return nil, err
+	}
+	if err := sb.checkMembershipOfUser(request.MembershipId, request.UserId); err != nil {
+		return nil, err
+	}
+
+	exists, err := sb.Adapter.StandingBooking.ExistsPostgresqlStandingBooking(
+		request.UserId,
+		request.ServiceId,
+		request.ProfessionalId,
+		request.Weekday,
+		request.StartTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, &errors.ConflictError.StandingBookingAlreadyExists
+	}
+
+	standingBooking, err := sb.Adapter.StandingBooking.CreatePostgresqlStandingBooking(request, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// The standing booking stands even if the first bookings fail, the next run retries the rest
+	if _, err := sb.runStandingBooking(standingBooking, time.Now()); err != nil {
+		sb.logger.Warnf("Failed to book the sessions of standing booking %s: %v", standingBooking.Id, err.Message)
+	}
+
+	return standingBooking, nil
+}
+
+// Pauses or resumes a standing booking, or changes the membership it books with. Resuming books the
+// matching sessions within the booking window right away.
+func (sb *StandingBooking) UpdateStandingBooking(
+	standingBookingId uuid.UUID,
+	request schemas.UpdateStandingBookingRequest,
+	updatedBy string,
+) (*schemas.StandingBooking, *errors.Error) {
+	current, err := sb.Adapter.StandingBooking.GetPostgresqlStandingBooking(standingBookingId)
+	if err != nil {
+		return nil, err
+	}
+	if err := sb.checkMembershipOfUser(request.MembershipId, current.UserId); err != nil {
+		return nil, err
+	}
+
+	standingBooking, err := sb.Adapter.StandingBooking.UpdatePostgresqlStandingBooking(
+		standingBookingId,
+		request,
+		updatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if standingBooking.Active && !current.Active {
+		if _, err := sb.runStandingBooking(standingBooking, time.Now()); err != nil {
+			sb.logger.Warnf("Failed to book the sessions of standing booking %s: %v", standingBooking.Id, err.Message)
+		}
+	}
+
+	return standingBooking, nil
+}
+
+// Deletes a standing booking. The reservations it already made are kept.
+func (sb *StandingBooking) DeleteStandingBooking(standingBookingId uuid.UUID) *errors.Error {
+	return sb.Adapter.StandingBooking.DeletePostgresqlStandingBooking(standingBookingId)
+}
+
+// Fetch the sessions a standing booking booked or skipped, with the reason of every skip.
+func (sb *StandingBooking) FetchStandingBookingAttempts(
+	standingBookingId uuid.UUID,
+) (*schemas.StandingBookingAttempts, *errors.Error) {
+	if _, err := sb.Adapter.StandingBooking.GetPostgresqlStandingBooking(standingBookingId); err != nil {
+		return nil, err
+	}
+
+	attempts, err := sb.Adapter.StandingBooking.FetchPostgresqlStandingBookingAttempts(standingBookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.StandingBookingAttempts{Attempts: attempts}, nil
+}
+
+// Books a newly created session for the active standing bookings whose pattern it matches, when it
+// starts within the booking window. Sessions further ahead are left for BookUpcomingSessions.
+func (sb *StandingBooking) BookSession(session *schemas.Session) (*schemas.StandingBookingReport, *errors.Error) {
+	report := &schemas.StandingBookingReport{
+		Booked:  []*schemas.StandingBookingAttempt{},
+		Skipped: []*schemas.StandingBookingAttempt{},
+	}
+
+	now := time.Now()
+	if session.CommunityServiceId == nil || !session.StartTime.After(now) ||
+		session.StartTime.After(now.AddDate(0, 0, sb.EnvSettings.StandingBookingWindowDays)) {
+		return report, nil
+	}
+
+	communityService, err := sb.Adapter.CommunityService.GetPostgresqlCommunityServiceById(
+		*session.CommunityServiceId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	standingBookings, err := sb.Adapter.StandingBooking.FetchPostgresqlActiveStandingBookingsByScope(
+		communityService.ServiceId,
+		session.ProfessionalId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, standingBooking := range standingBookings {
+		if !sb.matchesPattern(standingBooking, session) {
+			continue
+		}
+		attempt, err := sb.bookOccurrence(standingBooking, session)
+		if err != nil {
+			return nil, err
+		}
+		addStandingBookingAttempt(report, attempt)
+	}
+
+	return report, nil
+}
+
+// Books, for every active standing booking, the matching sessions that entered the booking window
+// since the last run. Each session is tried once per standing booking, so runs on several instances
+// at once book it only once.
+func (sb *StandingBooking) BookUpcomingSessions() (*schemas.StandingBookingReport, *errors.Error) {
+	standingBookings, err := sb.Adapter.StandingBooking.FetchPostgresqlStandingBookings(nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &schemas.StandingBookingReport{
+		Booked:  []*schemas.StandingBookingAttempt{},
+		Skipped: []*schemas.StandingBookingAttempt{},
+	}
+	for _, standingBooking := range standingBookings {
+		standingBookingReport, err := sb.runStandingBooking(standingBooking, now)
+		if err != nil {
+			sb.logger.Warnf("Failed to book the sessions of standing booking %s: %v", standingBooking.Id, err.Message)
+			continue
+		}
+		report.Booked = append(report.Booked, standingBookingReport.Booked...)
+		report.Skipped = append(report.Skipped, standingBookingReport.Skipped...)
+	}
+
+	return report, nil
+}
+
+// Books the matching sessions of a standing booking within the booking window it has not tried yet
+func (sb *StandingBooking) runStandingBooking(
+	standingBooking *schemas.StandingBooking,
+	now time.Time,
+) (*schemas.StandingBookingReport, *errors.Error) {
+	sessions, err := sb.Adapter.StandingBooking.FetchPostgresqlUntriedSessions(
+		standingBooking,
+		now,
+		now.AddDate(0, 0, sb.EnvSettings.StandingBookingWindowDays),
+		now.Add(-standingBookingClaimTimeout),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &schemas.StandingBookingReport{
+		Booked:  []*schemas.StandingBookingAttempt{},
+		Skipped: []*schemas.StandingBookingAttempt{},
+	}
+	for _, session := range sessions {
+		if !sb.matchesPattern(standingBooking, session) {
+			continue
+		}
+		attempt, err := sb.bookOccurrence(standingBooking, session)
+		if err != nil {
+			return nil, err
+		}
+		addStandingBookingAttempt(report, attempt)
+	}
+
+	return report, nil
+}
+
+// Tries to book a session for a standing booking and records the outcome. Returns nil when the
+// session was already tried. Only rejections of the booking are recorded as skipped, on unexpected
+// failures the attempt is given up so the session is tried again on the next run.
+func (sb *StandingBooking) bookOccurrence(
+	standingBooking *schemas.StandingBooking,
+	session *schemas.Session,
+) (*schemas.StandingBookingAttempt, *errors.Error) {
+	attempt, err := sb.Adapter.StandingBooking.ClaimPostgresqlStandingBookingAttempt(
+		standingBooking.Id,
+		session,
+		time.Now().Add(-standingBookingClaimTimeout),
+		"SYSTEM",
+	)
+	if err != nil || attempt == nil {
+		return nil, err
+	}
+
+	reservationId, skipReason := sb.bookForUser(standingBooking, session)
+	if skipReason != nil && errors.IsInternalError(*skipReason) {
+		if releaseErr := sb.Adapter.StandingBooking.ReleasePostgresqlStandingBookingAttempt(
+			attempt.Id,
+		); releaseErr != nil {
+			sb.logger.Warnf("Failed to release standing booking attempt %s: %v", attempt.Id, releaseErr.Message)
+		}
+		return nil, skipReason
+	}
+
+	return sb.Adapter.StandingBooking.FinishPostgresqlStandingBookingAttempt(
+		attempt,
+		reservationId,
+		skipReason,
+		"SYSTEM",
+	)
+}
+
+// Books a session for the user of a standing booking with the same checks as any booking, and
+// always with a membership of the community so its quota is respected. Returns the reservation,
+// or the reason it could not be made.
+func (sb *StandingBooking) bookForUser(
+	standingBooking *schemas.StandingBooking,
+	session *schemas.Session,
+) (*uuid.UUID, *errors.Error) {
+	existing, err := sb.Adapter.Reservation.FetchPostgresqlReservations(
+		[]uuid.UUID{standingBooking.UserId},
+		[]uuid.UUID{session.Id},
+		[]string{},
+	)
+	if err != nil {
+		return nil, err
+	}
+	// A reservation the user made or cancelled themselves is not overridden
+	if len(existing) > 0 {
+		return nil, &errors.ConflictError.SessionAlreadyBooked
+	}
+
+	membershipId, err := resolveBookingMembership(
+		sb.Adapter,
+		true,
+		standingBooking.UserId,
+		session,
+		standingBooking.MembershipId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := sb.Reservation.CreateReservation(schemas.CreateReservationRequest{
+		Name:            session.Title,
+		ReservationTime: time.Now(),
+		State:           "CONFIRMED",
+		UserId:          standingBooking.UserId,
+		SessionId:       session.Id,
+		MembershipId:    membershipId,
+	}, "SYSTEM")
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation.Id, nil
+}
+
+// Files an attempt under the booked or skipped sessions of a report
+func addStandingBookingAttempt(
+	report *schemas.StandingBookingReport,
+	attempt *schemas.StandingBookingAttempt,
+) {
+	if attempt == nil {
+		return
+	}
+	if attempt.Status == "BOOKED" {
+		report.Booked = append(report.Booked, attempt)
+	} else {
+		report.Skipped = append(report.Skipped, attempt)
+	}
+}
+
+// Whether a session starts on the weekday and at the time of a standing booking, in the booking
+// time zone
+func (sb *StandingBooking) matchesPattern(
+	standingBooking *schemas.StandingBooking,
+	session *schemas.Session,
+) bool {
+	localStart := session.StartTime.In(sb.EnvSettings.BookingTimeZone)
+	return int(localStart.Weekday()) == standingBooking.Weekday &&
+		localStart.Format("15:04") == standingBooking.StartTime
+}
+
+// Rejects a membership that does not belong to the user of a standing booking
+func (sb *StandingBooking) checkMembershipOfUser(membershipId *uuid.UUID, userId uuid.UUID) *errors.Error {
+	if membershipId == nil {
+		return nil
+	}
+
+	membership, err := sb.Adapter.Membership.GetPostgresqlMembership(*membershipId)
+	if err != nil {
+		return err
+	}
+	if membership.UserId != userId {
+		return &errors.ForbiddenError.MembershipOfAnotherUser
+	}
+
+	return nil
+}
+
+// Checks that the caller owns a standing booking or can access the reservations of other users
+func (sb *StandingBooking) CheckStandingBookingAccess(
+	credentials *schemas.Credentials,
+	standingBookingId uuid.UUID,
+) *errors.Error {
+	if credentials == nil || isOwnershipExempt(credentials) {
+		return nil
+	}
+
+	standingBooking, err := sb.Adapter.StandingBooking.GetPostgresqlStandingBooking(standingBookingId)
+	if err != nil {
+		return err
+	}
+	if standingBooking.UserId == credentials.UserId || canActForOtherUsers(
+		sb.logger,
+		sb.Adapter,
+		credentials,
+		schemas.PermissionReservationAnyUser,
+		schemas.PermissionResources{},
+	) {
+		return nil
+	}
+
+	return checkResourceOwner(
+		sb.logger,
+		sb.Adapter,
+		credentials,
+		standingBooking.UserId,
+		schemas.AuditEntityReservation,
+		standingBooking.Id,
+	)
+}
+
+// Checks that the caller subscribes their own user with their own membership
+func (sb *StandingBooking) CheckCreateStandingBookingAccess(
+	credentials *schemas.Credentials,
+	request schemas.CreateStandingBookingRequest,
+) *errors.Error {
+	if err := sb.Reservation.CheckUserReservationsAccess(
+		credentials,
+		request.UserId,
+		schemas.PermissionResources{},
+	); err != nil {
+		return err
+	}
+
+	return sb.Reservation.checkMembershipOwner(credentials, request.MembershipId)
+}
+
+// Checks that the caller owns a standing booking and only moves it to their own membership
+func (sb *StandingBooking) CheckUpdateStandingBookingAccess(
+	credentials *schemas.Credentials,
+	standingBookingId uuid.UUID,
+	request schemas.UpdateStandingBookingRequest,
+) *errors.Error {
+	if err := sb.CheckStandingBookingAccess(credentials, standingBookingId); err != nil {
+		return err
+	}
+
+	return sb.Reservation.checkMembershipOwner(credentials, request.MembershipId)
+}
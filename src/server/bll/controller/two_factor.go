@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+// Accepted clock drift between the server and the authenticator app, in TOTP steps
+const twoFactorAllowedSkew = 1
+
+type TwoFactor struct {
+	Logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+}
+
+func NewTwoFactorController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *TwoFactor {
+	return &TwoFactor{
+		Logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+	}
+}
+
+// Whether the policy makes two-factor authentication mandatory for the user
+func (tf *TwoFactor) IsRequired(user *schemas.User) bool {
+	return tf.EnvSettings.RequireAdminTwoFactor && user.Rol == schemas.UserRolAdmin
+}
+
+func (tf *TwoFactor) GetStatus(userId uuid.UUID) (*schemas.TwoFactorStatus, *errors.Error) {
+	user, err := tf.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	remainingRecoveryCodes, err := tf.Adapter.TwoFactorRecoveryCode.CountUnusedPostgresqlTwoFactorRecoveryCodes(
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.TwoFactorStatus{
+		Enabled:                user.TwoFactorEnabledAt != nil,
+		EnabledAt:              user.TwoFactorEnabledAt,
+		Required:               tf.IsRequired(user),
+		RemainingRecoveryCodes: remainingRecoveryCodes,
+	}, nil
+}
+
+// Starts the enrolment of a user generating a new TOTP secret. The secret is only active once it
+// is confirmed with a valid code, calling this again replaces a pending secret.
+func (tf *TwoFactor) BeginEnrollment(
+	userId uuid.UUID,
+) (*schemas.TwoFactorEnrollmentResponse, *errors.Error) {
+	user, err := tf.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return nil, &errors.TwoFactorError.TwoFactorAlreadyEnabled
+	}
+
+	secret, secretErr := utils.GenerateTotpSecret()
+	if secretErr != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	if err := tf.Adapter.User.SetPostgresqlUserTwoFactorSecret(user.Id, secret, user.Email); err != nil {
+		return nil, err
+	}
+
+	return &schemas.TwoFactorEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningUri: utils.BuildTotpProvisioningUri(tf.EnvSettings.TwoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirms a pending enrolment with a code of the authenticator app and returns a first set of
+// recovery codes. The recovery codes are only shown once.
+func (tf *TwoFactor) ConfirmEnrollment(
+	userId uuid.UUID,
+	code string,
+) (*schemas.TwoFactorRecoveryCodesResponse, *errors.Error) {
+	user, err := tf.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return nil, &errors.TwoFactorError.TwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == nil {
+		return nil, &errors.TwoFactorError.TwoFactorNotEnrolled
+	}
+
+	step, valid := utils.ValidateTotpCode(*user.TwoFactorSecret, code, time.Now(), twoFactorAllowedSkew)
+	if !valid {
+		return nil, &errors.TwoFactorError.InvalidTwoFactorCode
+	}
+
+	if err := tf.Adapter.User.EnablePostgresqlUserTwoFactor(user.Id, step, user.Email); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := tf.issueRecoveryCodes(user)
+	if err != nil {
+		return nil, err
+	}
+
+	logUserSecurityEvent(
+		tf.Logger,
+		tf.Adapter,
+		user,
+		schemas.AuditActionTwoFactorEnabled,
+		"Two-factor authentication enabled",
+	)
+
+	return recoveryCodes, nil
+}
+
+// Replaces the recovery codes of a user. Requires a valid TOTP code so a stolen session alone
+// can not mint new recovery codes.
+func (tf *TwoFactor) RegenerateRecoveryCodes(
+	userId uuid.UUID,
+	code string,
+) (*schemas.TwoFactorRecoveryCodesResponse, *errors.Error) {
+	user, err := tf.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := tf.verifyTotpCode(user, code); err != nil {
+		return nil, err
+	}
+
+	return tf.issueRecoveryCodes(user)
+}
+
+// Disables two-factor authentication of a user after checking a TOTP or recovery code. Users for
+// which the policy makes it mandatory can not disable it.
+func (tf *TwoFactor) Disable(userId uuid.UUID, code string) *errors.Error {
+	user, err := tf.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return err
+	}
+	if tf.IsRequired(user) {
+		return &errors.ForbiddenError.TwoFactorRequired
+	}
+	if err := tf.VerifyLoginFactor(user, code, code); err != nil {
+		return err
+	}
+
+	return tf.disable(user, user.Email, "Two-factor authentication disabled")
+}
+
+// Removes the two-factor authentication of a user that lost both the device and the recovery
+// codes, so they can enrol again on their next login
+func (tf *TwoFactor) Reset(userId uuid.UUID, updatedBy string) *errors.Error {
+	user, err := tf.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return err
+	}
+
+	return tf.disable(user, updatedBy, "Two-factor authentication reset by "+updatedBy)
+}
+
+func (tf *TwoFactor) disable(user *schemas.User, updatedBy string, info string) *errors.Error {
+	if err := tf.Adapter.User.DisablePostgresqlUserTwoFactor(user.Id, updatedBy); err != nil {
+		return err
+	}
+	if err := tf.Adapter.TwoFactorRecoveryCode.ReplacePostgresqlUserTwoFactorRecoveryCodes(
+		user.Id,
+		nil,
+		updatedBy,
+	); err != nil {
+		return err
+	}
+
+	logUserSecurityEvent(tf.Logger, tf.Adapter, user, schemas.AuditActionTwoFactorDisabled, info)
+
+	return nil
+}
+
+// Checks the second factor of a login, either a TOTP code or an unused recovery code
+func (tf *TwoFactor) VerifyLoginFactor(
+	user *schemas.User,
+	code string,
+	recoveryCode string,
+) *errors.Error {
+	if user.TwoFactorEnabledAt == nil || user.TwoFactorSecret == nil {
+		return &errors.TwoFactorError.TwoFactorNotEnrolled
+	}
+
+	if code != "" && tf.verifyTotpCode(user, code) == nil {
+		return nil
+	}
+
+	if recoveryCode != "" {
+		used, err := tf.Adapter.TwoFactorRecoveryCode.UsePostgresqlTwoFactorRecoveryCode(
+			user.Id,
+			utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)),
+		)
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+	}
+
+	return &errors.TwoFactorError.InvalidTwoFactorCode
+}
+
+// Checks a TOTP code of an enabled secret, rejecting codes of a step that was already used
+func (tf *TwoFactor) verifyTotpCode(user *schemas.User, code string) *errors.Error {
+	if user.TwoFactorEnabledAt == nil || user.TwoFactorSecret == nil {
+		return &errors.TwoFactorError.TwoFactorNotEnrolled
+	}
+
+	step, valid := utils.ValidateTotpCode(*user.TwoFactorSecret, code, time.Now(), twoFactorAllowedSkew)
+	if !valid {
+		return &errors.TwoFactorError.InvalidTwoFactorCode
+	}
+
+	accepted, err := tf.Adapter.User.UsePostgresqlUserTwoFactorStep(user.Id, step)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return &errors.TwoFactorError.InvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// Generates a new set of recovery codes for a user and stores only their hashes
+func (tf *TwoFactor) issueRecoveryCodes(
+	user *schemas.User,
+) (*schemas.TwoFactorRecoveryCodesResponse, *errors.Error) {
+	recoveryCodes := make([]string, tf.EnvSettings.TwoFactorRecoveryCodeCount)
+	codeHashes := make([]string, len(recoveryCodes))
+	for i := range recoveryCodes {
+		recoveryCode, codeErr := utils.GenerateRecoveryCode()
+		if codeErr != nil {
+			return nil, &errors.InternalServerError.Default
+		}
+		recoveryCodes[i] = recoveryCode
+		codeHashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode))
+	}
+
+	if err := tf.Adapter.TwoFactorRecoveryCode.ReplacePostgresqlUserTwoFactorRecoveryCodes(
+		user.Id,
+		codeHashes,
+		user.Email,
+	); err != nil {
+		return nil, err
+	}
+
+	return &schemas.TwoFactorRecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// Signs a short-lived token that proves the password step of a login was passed
+func (tf *TwoFactor) IssueChallenge(user *schemas.User) (*schemas.TwoFactorChallenge, *errors.Error) {
+	expiresIn := tf.EnvSettings.TwoFactorChallengeExpiration
+	claims := &schemas.TwoFactorChallengeClaims{
+		UserId:       user.Id,
+		TokenVersion: user.TokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{schemas.TwoFactorChallengeAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	challengeToken, err := token.SignedString(tf.EnvSettings.TokenSignatureKey)
+	if err != nil {
+		return nil, &errors.InternalServerError.Default
+	}
+
+	return &schemas.TwoFactorChallenge{
+		ChallengeToken:     challengeToken,
+		ExpiresIn:          expiresIn,
+		EnrollmentRequired: user.TwoFactorEnabledAt == nil,
+	}, nil
+}
+
+// Validates a challenge token and returns the user that passed the password step. Access tokens
+// are rejected since they lack the challenge audience.
+func (tf *TwoFactor) ParseChallenge(challengeToken string) (*schemas.User, *errors.Error) {
+	token, err := jwt.ParseWithClaims(
+		challengeToken,
+		&schemas.TwoFactorChallengeClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method")
+			}
+			return []byte(tf.EnvSettings.TokenSignatureKey), nil
+		},
+		jwt.WithAudience(schemas.TwoFactorChallengeAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !token.Valid {
+		return nil, &errors.AuthenticationError.InvalidTwoFactorChallenge
+	}
+
+	claims := token.Claims.(*schemas.TwoFactorChallengeClaims)
+	user, userErr := tf.Adapter.User.GetPostgresqlUser(claims.UserId)
+	if userErr != nil {
+		return nil, &errors.AuthenticationError.InvalidTwoFactorChallenge
+	}
+
+	// A password change or forced logout also invalidates pending challenges
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, &errors.AuthenticationError.InvalidTwoFactorChallenge
+	}
+
+	return user, nil
+}
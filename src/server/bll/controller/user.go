@@ -10,20 +10,23 @@ import (
 )
 
 type User struct {
-	logger      logging.Logger
-	Adapter     *bllAdapter.AdapterCollection
-	EnvSettings *schemas.EnvSettings
+	logger            logging.Logger
+	Adapter           *bllAdapter.AdapterCollection
+	EnvSettings       *schemas.EnvSettings
+	EmailVerification *EmailVerification
 }
 
 func NewUserController(
 	logger logging.Logger,
 	adapter *bllAdapter.AdapterCollection,
 	envSettings *schemas.EnvSettings,
+	emailVerification *EmailVerification,
 ) *User {
 	return &User{
-		logger:      logger,
-		Adapter:     adapter,
-		EnvSettings: envSettings,
+		logger:            logger,
+		Adapter:           adapter,
+		EnvSettings:       envSettings,
+		EmailVerification: emailVerification,
 	}
 }
 
@@ -44,12 +47,16 @@ func (u *User) CreateUser(
 	createUserRequest schemas.CreateUserRequest,
 	updatedBy string,
 ) (*schemas.User, *errors.Error) {
+	if err := checkPrimaryRol(createUserRequest.Rol, false); err != nil {
+		return nil, err
+	}
+
 	var secondLastName *string
 	if createUserRequest.SecondLastName != "" {
 		secondLastName = &createUserRequest.SecondLastName
 	}
 
-	return u.Adapter.User.CreatePostgresqlUser(
+	user, err := u.Adapter.User.CreatePostgresqlUser(
 		createUserRequest.Name,
 		createUserRequest.FirstLastName,
 		secondLastName,
@@ -61,6 +68,16 @@ func (u *User) CreateUser(
 		createUserRequest.Memberships,
 		createUserRequest.Onboarding,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account stays usable while unverified, the code can be resent if this fails
+	if err := u.EmailVerification.SendVerificationCode(user); err != nil {
+		u.logger.Warnf("Failed to issue email verification code: %v", err.Message)
+	}
+
+	return user, nil
 }
 
 func (u *User) UpdateUser(
@@ -68,7 +85,28 @@ func (u *User) UpdateUser(
 	updateUserRequest schemas.UpdateUserRequest,
 	updatedBy string,
 ) (*schemas.User, *errors.Error) {
-	return u.Adapter.User.UpdatePostgresqlUser(
+	currentUser, err := u.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if updateUserRequest.Rol != nil {
+		if err := checkPrimaryRol(*updateUserRequest.Rol, true); err != nil {
+			return nil, err
+		}
+	}
+
+	// Passwords are stored hashed, so the new one is compared with the hash of the current one
+	passwordChanged := false
+	if updateUserRequest.Password != nil {
+		passwordChanged = utils.CheckPasswordHash(*updateUserRequest.Password, currentUser.Password) != nil
+		hashedPassword, hashErr := utils.HashPassword(*updateUserRequest.Password)
+		if hashErr != nil {
+			return nil, &errors.InternalServerError.Default
+		}
+		updateUserRequest.Password = &hashedPassword
+	}
+
+	updatedUser, err := u.Adapter.User.UpdatePostgresqlUser(
 		userId,
 		updateUserRequest.Name,
 		updateUserRequest.FirstLastName,
@@ -81,6 +119,70 @@ func (u *User) UpdateUser(
 		updateUserRequest.Onboarding,
 		updatedBy,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new email loses its verification, so a code is sent to the new address. The account stays
+	// usable while unverified, the code can be resent if this fails.
+	emailChanged := updateUserRequest.Email != nil && *updateUserRequest.Email != currentUser.Email
+	if emailChanged {
+		if err := u.EmailVerification.SendVerificationCode(updatedUser); err != nil {
+			u.logger.Warnf("Failed to issue email verification code: %v", err.Message)
+		}
+	}
+
+	// Tokens carry the role and were issued with the old credentials, so they must be invalidated
+	roleChanged := updateUserRequest.Rol != nil && *updateUserRequest.Rol != string(currentUser.Rol)
+	if roleChanged || passwordChanged {
+		if err := u.invalidateUserTokens(userId, updatedBy); err != nil {
+			return nil, err
+		}
+		// Read again so the user returned carries the new token version
+		return u.Adapter.User.GetPostgresqlUser(userId)
+	}
+
+	return updatedUser, nil
+}
+
+// Invalidates every token of a user so they have to log in again
+func (u *User) ForceLogout(userId uuid.UUID, updatedBy string) *errors.Error {
+	return u.invalidateUserTokens(userId, updatedBy)
+}
+
+// Clears the failed logins and the lock of a user, e.g. once an admin confirms their identity
+func (u *User) UnlockUser(userId uuid.UUID, updatedBy string) *errors.Error {
+	user, err := u.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if err := u.Adapter.User.UnlockPostgresqlUser(userId, updatedBy); err != nil {
+		return err
+	}
+
+	logUserSecurityEvent(
+		u.logger,
+		u.Adapter,
+		user,
+		schemas.AuditActionAccountUnlocked,
+		"Unlocked by "+updatedBy,
+	)
+
+	return nil
+}
+
+// Bumps the token version of a user and revokes their refresh tokens and login sessions
+func (u *User) invalidateUserTokens(userId uuid.UUID, updatedBy string) *errors.Error {
+	if err := u.Adapter.User.IncrementPostgresqlUserTokenVersion(userId, updatedBy); err != nil {
+		return err
+	}
+
+	if err := u.Adapter.RefreshToken.RevokePostgresqlUserRefreshTokens(userId, updatedBy); err != nil {
+		return err
+	}
+
+	return u.Adapter.LoginSession.RevokePostgresqlUserLoginSessions(userId, updatedBy)
 }
 
 func (u *User) DeleteUser(userId uuid.UUID) *errors.Error {
@@ -91,6 +193,12 @@ func (u *User) BulkCreateUsers(
 	createUsersData []*schemas.CreateUserRequest,
 	updatedBy string,
 ) ([]*schemas.User, *errors.Error) {
+	for _, createUserRequest := range createUsersData {
+		if err := checkPrimaryRol(createUserRequest.Rol, false); err != nil {
+			return nil, err
+		}
+	}
+
 	return u.Adapter.User.BulkCreatePostgresqlUser(
 		createUsersData,
 		updatedBy,
@@ -139,7 +247,8 @@ func (u *User) ChangePassword(
 		return &errors.BadRequestError.UserPasswordNotUpdated
 	}
 
-	return nil
+	// Los tokens emitidos con la contraseña anterior dejan de ser válidos
+	return u.invalidateUserTokens(user.Id, "SYSTEM")
 }
 
 func (u *User) GetUserStats() (*schemas.UserStats, *errors.Error) {
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+// Signing algorithms accepted for ID tokens, HMAC is excluded since providers sign with their keys
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+type UserIdentity struct {
+	Logger      logging.Logger
+	Adapter     *adapter.AdapterCollection
+	EnvSettings *schemas.EnvSettings
+	keySets     map[string]*utils.JwksKeySet // Keyed by JWKS url
+	keySetMutex sync.Mutex
+}
+
+func NewUserIdentityController(
+	logger logging.Logger,
+	adapter *adapter.AdapterCollection,
+	envSettings *schemas.EnvSettings,
+) *UserIdentity {
+	return &UserIdentity{
+		Logger:      logger,
+		Adapter:     adapter,
+		EnvSettings: envSettings,
+		keySets:     map[string]*utils.JwksKeySet{},
+	}
+}
+
+// Names of the configured OpenID Connect providers
+func (u *UserIdentity) FetchProviders() *schemas.OidcProviders {
+	providers := make([]string, 0, len(u.EnvSettings.OidcProviders))
+	for name := range u.EnvSettings.OidcProviders {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+
+	return &schemas.OidcProviders{Providers: providers}
+}
+
+// Validates an ID token of a provider: its signature against the JWKS of the provider, the issuer,
+// the audience when a client id is configured, and the expiration
+func (u *UserIdentity) VerifyIdToken(
+	ctx context.Context,
+	providerName string,
+	idToken string,
+) (*schemas.OidcIdTokenClaims, *errors.Error) {
+	provider, ok := u.EnvSettings.OidcProviders[providerName]
+	if !ok {
+		return nil, &errors.ObjectNotFoundError.OidcProviderNotFound
+	}
+	keySet := u.getKeySet(provider)
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithAudience(provider.ClientId),
+	}
+
+	token, err := jwt.ParseWithClaims(
+		idToken,
+		&schemas.OidcIdTokenClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			if kid == "" {
+				return nil, fmt.Errorf("missing key id")
+			}
+			return keySet.Key(ctx, kid)
+		},
+		options...,
+	)
+	if err != nil || !token.Valid {
+		u.Logger.Warnf("Invalid ID token of provider %s: %v", providerName, err)
+		return nil, &errors.AuthenticationError.InvalidIdToken
+	}
+
+	claims := token.Claims.(*schemas.OidcIdTokenClaims)
+	if !slices.Contains(provider.Issuers, claims.Issuer) || claims.Subject == "" {
+		return nil, &errors.AuthenticationError.InvalidIdToken
+	}
+
+	return claims, nil
+}
+
+func (u *UserIdentity) FetchUserIdentities(userId uuid.UUID) (*schemas.UserIdentities, *errors.Error) {
+	identities, err := u.Adapter.UserIdentity.FetchPostgresqlUserIdentitiesByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.UserIdentities{Identities: identities}, nil
+}
+
+// Links the identity of an ID token to a user. An identity belongs to a single user and a user has
+// at most one identity per provider.
+func (u *UserIdentity) LinkIdentity(
+	ctx context.Context,
+	userId uuid.UUID,
+	request schemas.LinkUserIdentityRequest,
+) (*schemas.UserIdentity, *errors.Error) {
+	user, err := u.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := u.VerifyIdToken(ctx, request.Provider, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.linkIdentity(user, request.Provider, claims, user.Email)
+}
+
+// Unlinks the identity of a user at a provider. Accounts without password keep at least one
+// identity so they can still log in.
+func (u *UserIdentity) UnlinkIdentity(userId uuid.UUID, provider string) *errors.Error {
+	user, err := u.Adapter.User.GetPostgresqlUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if user.Password == "" {
+		identities, err := u.Adapter.UserIdentity.FetchPostgresqlUserIdentitiesByUserId(userId)
+		if err != nil {
+			return err
+		}
+		if len(identities) <= 1 {
+			return &errors.BadRequestError.LastLoginMethod
+		}
+	}
+
+	if err := u.Adapter.UserIdentity.DeletePostgresqlUserIdentity(userId, provider); err != nil {
+		return err
+	}
+
+	logUserSecurityEvent(
+		u.Logger,
+		u.Adapter,
+		user,
+		schemas.AuditActionIdentityUnlinked,
+		"Unlinked "+provider+" identity",
+	)
+
+	return nil
+}
+
+func (u *UserIdentity) linkIdentity(
+	user *schemas.User,
+	provider string,
+	claims *schemas.OidcIdTokenClaims,
+	updatedBy string,
+) (*schemas.UserIdentity, *errors.Error) {
+	existing, err := u.Adapter.UserIdentity.GetPostgresqlUserIdentityByProviderSubject(
+		provider,
+		claims.Subject,
+	)
+	if err == nil {
+		if existing.UserId == user.Id {
+			return existing, nil
+		}
+		return nil, &errors.ConflictError.UserIdentityAlreadyLinked
+	}
+	if err.Code != errors.ObjectNotFoundError.UserIdentityNotFound.Code {
+		return nil, err
+	}
+
+	identities, err := u.Adapter.UserIdentity.FetchPostgresqlUserIdentitiesByUserId(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			return nil, &errors.ConflictError.UserProviderAlreadyLinked
+		}
+	}
+
+	identity, err := u.Adapter.UserIdentity.CreatePostgresqlUserIdentity(
+		user.Id,
+		provider,
+		claims.Subject,
+		claims.Email,
+		updatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	logUserSecurityEvent(
+		u.Logger,
+		u.Adapter,
+		user,
+		schemas.AuditActionIdentityLinked,
+		"Linked "+provider+" identity",
+	)
+
+	return identity, nil
+}
+
+// Key sets are shared by the providers that use the same JWKS url
+func (u *UserIdentity) getKeySet(provider *schemas.OidcProvider) *utils.JwksKeySet {
+	u.keySetMutex.Lock()
+	defer u.keySetMutex.Unlock()
+
+	keySet, ok := u.keySets[provider.JwksUrl]
+	if !ok {
+		keySet = utils.NewJwksKeySet(provider.JwksUrl, u.EnvSettings.OidcJwksRefresh)
+		u.keySets[provider.JwksUrl] = keySet
+	}
+	return keySet
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// Encrypts the personal data of the onboardings stored in plaintext, and re-encrypts the ones that
+// use a retired key after a rotation. It can be stopped and run again at any time.
+func main() {
+	batchSize := flag.Int("batch-size", 500, "Onboardings read per query")
+	flag.Parse()
+
+	logger := logging.NewLogger("AstroCatEncryptOnboardingPii", "Version 1.0", logging.FormatText, 4)
+	envSettings := schemas.NewEnvSettings(logger)
+	if len(envSettings.PiiEncryptionKeys) == 0 {
+		logger.Panicln("PII_ENCRYPTION_KEYS must be set to encrypt the onboardings")
+	}
+
+	adapterCollection, _ := adapter.NewAdapterCollection(logger, envSettings)
+
+	encryptedCount, err := adapterCollection.Onboarding.EncryptPostgresqlOnboardings(*batchSize)
+	if err != nil {
+		logger.Panicln("Failed to encrypt onboardings after", encryptedCount, "rows:", err.Message)
+	}
+
+	logger.Infof("Encrypted %d onboardings with key %s", encryptedCount, envSettings.PiiEncryptionKeyId)
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type CancellationPolicy struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+// Create CancellationPolicy postgresql controller
+func NewCancellationPolicyController(logger logging.Logger, postgresqlDB *gorm.DB) *CancellationPolicy {
+	return &CancellationPolicy{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Gets a cancellation policy model given its ID.
+func (cp *CancellationPolicy) GetCancellationPolicy(policyId uuid.UUID) (*model.CancellationPolicy, error) {
+	var policy model.CancellationPolicy
+	if err := cp.PostgresqlDB.First(&policy, "id = ?", policyId).Error; err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Gets the policy that applies to a service of a community: the one of the service if there is
+// one, otherwise the one of the whole community.
+func (cp *CancellationPolicy) GetApplicableCancellationPolicy(
+	communityId uuid.UUID,
+	serviceId uuid.UUID,
+) (*model.CancellationPolicy, error) {
+	var policy model.CancellationPolicy
+	err := cp.PostgresqlDB.
+		Where("community_id = ? AND (service_id = ? OR service_id IS NULL)", communityId, serviceId).
+		Order("service_id IS NULL").
+		First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Gets the policy of exactly a community, or of a service of it when `serviceId` is given.
+func (cp *CancellationPolicy) GetCancellationPolicyByScope(
+	communityId uuid.UUID,
+	serviceId *uuid.UUID,
+) (*model.CancellationPolicy, error) {
+	query := cp.PostgresqlDB.Where("community_id = ?", communityId)
+	if serviceId != nil {
+		query = query.Where("service_id = ?", *serviceId)
+	} else {
+		query = query.Where("service_id IS NULL")
+	}
+
+	var policy model.CancellationPolicy
+	if err := query.First(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Fetch all cancellation policies, filtered by `communityIds` if provided.
+func (cp *CancellationPolicy) FetchCancellationPolicies(
+	communityIds []uuid.UUID,
+) ([]*model.CancellationPolicy, error) {
+	policies := []*model.CancellationPolicy{}
+
+	query := cp.PostgresqlDB.Model(&model.CancellationPolicy{})
+	if len(communityIds) > 0 {
+		query = query.Where("community_id IN (?)", communityIds)
+	}
+
+	if err := query.Order("community_id, service_id NULLS FIRST").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// Creates a cancellation policy given its model.
+func (cp *CancellationPolicy) CreateCancellationPolicy(policy *model.CancellationPolicy) error {
+	return cp.PostgresqlDB.Create(policy).Error
+}
+
+// Updates a cancellation policy given fields to update.
+func (cp *CancellationPolicy) UpdateCancellationPolicy(
+	policyId uuid.UUID,
+	freeCancellationHours *int,
+	lateCancellationBurnsCredit *bool,
+	noShowBurnsCredit *bool,
+	noShowLimit *int,
+	noShowWindowDays *int,
+	updatedBy string,
+) (*model.CancellationPolicy, error) {
+	updateFields := map[string]any{
+		"updated_by": updatedBy,
+	}
+
+	if freeCancellationHours != nil {
+		updateFields["free_cancellation_hours"] = *freeCancellationHours
+	}
+	if lateCancellationBurnsCredit != nil {
+		updateFields["late_cancellation_burns_credit"] = *lateCancellationBurnsCredit
+	}
+	if noShowBurnsCredit != nil {
+		updateFields["no_show_burns_credit"] = *noShowBurnsCredit
+	}
+	if noShowLimit != nil {
+		updateFields["no_show_limit"] = *noShowLimit
+	}
+	if noShowWindowDays != nil {
+		updateFields["no_show_window_days"] = *noShowWindowDays
+	}
+
+	var policy model.CancellationPolicy
+	result := cp.PostgresqlDB.Model(&policy).
+		Clauses(clause.Returning{}).
+		Where("id = ?", policyId).
+		Updates(updateFields)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &policy, nil
+}
+
+// Soft deletes a cancellation policy given its ID.
+func (cp *CancellationPolicy) DeleteCancellationPolicy(policyId uuid.UUID) error {
+	result := cp.PostgresqlDB.Delete(&model.CancellationPolicy{}, "id = ?", policyId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -12,23 +12,39 @@ import (
 )
 
 type AstroCatPsqlCollection struct {
-	Logger               logging.Logger
-	Community            *Community
-	Professional         *Professional
-	Local                *Local
-	User                 *User
-	Onboarding           *Onboarding
-	Membership           *Membership
-	Service              *Service
-	Plan                 *Plan
-	CommunityPlan        *CommunityPlan
-	CommunityService     *CommunityService
-	ServiceLocal         *ServiceLocal
-	ServiceProfessional  *ServiceProfessional
-	Session              *Session
-	Reservation          *Reservation
-	AuditLog             *AuditLog
-	MembershipSuspension *MembershipSuspension
+	Logger                  logging.Logger
+	Community               *Community
+	Professional            *Professional
+	Local                   *Local
+	User                    *User
+	Onboarding              *Onboarding
+	Membership              *Membership
+	Service                 *Service
+	Plan                    *Plan
+	CommunityPlan           *CommunityPlan
+	CommunityService        *CommunityService
+	ServiceLocal            *ServiceLocal
+	ServiceProfessional     *ServiceProfessional
+	Session                 *Session
+	Reservation             *Reservation
+	AuditLog                *AuditLog
+	MembershipSuspension    *MembershipSuspension
+	RefreshToken            *RefreshToken
+	PasswordReset           *PasswordReset
+	EmailVerification       *EmailVerification
+	RateLimitCounter        *RateLimitCounter
+	TwoFactorRecoveryCode   *TwoFactorRecoveryCode
+	Role                    *Role
+	UserRoleAssignment      *UserRoleAssignment
+	ServiceAccount          *ServiceAccount
+	UserIdentity            *UserIdentity
+	LoginSession            *LoginSession
+	CancellationPolicy      *CancellationPolicy
+	StandingBooking         *StandingBooking
+	IdempotencyKey          *IdempotencyKey
+	ReservationStateHistory *ReservationStateHistory
+	SessionSeries           *SessionSeries
+	ProfessionalSchedule    *ProfessionalSchedule
 }
 
 // Create dao controller collection
@@ -55,23 +71,39 @@ func NewAstroCatPsqlCollection(
 	createTables(postgresqlDB)
 
 	return &AstroCatPsqlCollection{
-		Logger:               logger,
-		Community:            NewCommunityController(logger, postgresqlDB),
-		Professional:         NewProfessionalController(logger, postgresqlDB),
-		Local:                NewLocalController(logger, postgresqlDB),
-		User:                 NewUserController(logger, postgresqlDB),
-		Onboarding:           NewOnboardingController(logger, postgresqlDB),
-		Membership:           NewMembershipController(logger, postgresqlDB),
-		Service:              NewServiceController(logger, postgresqlDB),
-		Plan:                 NewPlanController(logger, postgresqlDB),
-		CommunityPlan:        NewCommunityPlanController(logger, postgresqlDB),
-		CommunityService:     NewCommunityServiceController(logger, postgresqlDB),
-		ServiceLocal:         NewServiceLocalController(logger, postgresqlDB),
-		ServiceProfessional:  NewServiceProfessionalController(logger, postgresqlDB),
-		Session:              NewSessionController(logger, postgresqlDB),
-		Reservation:          NewReservationController(logger, postgresqlDB),
-		AuditLog:             NewAuditLogController(logger, postgresqlDB),
-		MembershipSuspension: NewMembershipSuspensionController(logger, postgresqlDB),
+		Logger:                  logger,
+		Community:               NewCommunityController(logger, postgresqlDB),
+		Professional:            NewProfessionalController(logger, postgresqlDB),
+		Local:                   NewLocalController(logger, postgresqlDB),
+		User:                    NewUserController(logger, postgresqlDB),
+		Onboarding:              NewOnboardingController(logger, postgresqlDB),
+		Membership:              NewMembershipController(logger, postgresqlDB),
+		Service:                 NewServiceController(logger, postgresqlDB),
+		Plan:                    NewPlanController(logger, postgresqlDB),
+		CommunityPlan:           NewCommunityPlanController(logger, postgresqlDB),
+		CommunityService:        NewCommunityServiceController(logger, postgresqlDB),
+		ServiceLocal:            NewServiceLocalController(logger, postgresqlDB),
+		ServiceProfessional:     NewServiceProfessionalController(logger, postgresqlDB),
+		Session:                 NewSessionController(logger, postgresqlDB),
+		Reservation:             NewReservationController(logger, postgresqlDB),
+		AuditLog:                NewAuditLogController(logger, postgresqlDB),
+		MembershipSuspension:    NewMembershipSuspensionController(logger, postgresqlDB),
+		RefreshToken:            NewRefreshTokenController(logger, postgresqlDB),
+		PasswordReset:           NewPasswordResetController(logger, postgresqlDB),
+		EmailVerification:       NewEmailVerificationController(logger, postgresqlDB),
+		RateLimitCounter:        NewRateLimitCounterController(logger, postgresqlDB),
+		TwoFactorRecoveryCode:   NewTwoFactorRecoveryCodeController(logger, postgresqlDB),
+		Role:                    NewRoleController(logger, postgresqlDB),
+		UserRoleAssignment:      NewUserRoleAssignmentController(logger, postgresqlDB),
+		ServiceAccount:          NewServiceAccountController(logger, postgresqlDB),
+		UserIdentity:            NewUserIdentityController(logger, postgresqlDB),
+		LoginSession:            NewLoginSessionController(logger, postgresqlDB),
+		CancellationPolicy:      NewCancellationPolicyController(logger, postgresqlDB),
+		StandingBooking:         NewStandingBookingController(logger, postgresqlDB),
+		IdempotencyKey:          NewIdempotencyKeyController(logger, postgresqlDB),
+		ReservationStateHistory: NewReservationStateHistoryController(logger, postgresqlDB),
+		SessionSeries:           NewSessionSeriesController(logger, postgresqlDB),
+		ProfessionalSchedule:    NewProfessionalScheduleController(logger, postgresqlDB),
 	}, postgresqlDB
 }
 
@@ -198,6 +230,146 @@ func createTables(astroCatPsqlDB *gorm.DB) {
 	}
 	fmt.Println("AuditLog table created successfully")
 
+	fmt.Println("Creating RefreshToken table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.RefreshToken{}); err != nil {
+		fmt.Printf("Error creating RefreshToken table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("RefreshToken table created successfully")
+
+	fmt.Println("Creating PasswordReset table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.PasswordReset{}); err != nil {
+		fmt.Printf("Error creating PasswordReset table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("PasswordReset table created successfully")
+
+	fmt.Println("Creating EmailVerification table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.EmailVerification{}); err != nil {
+		fmt.Printf("Error creating EmailVerification table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("EmailVerification table created successfully")
+
+	fmt.Println("Creating RateLimitCounter table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.RateLimitCounter{}); err != nil {
+		fmt.Printf("Error creating RateLimitCounter table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("RateLimitCounter table created successfully")
+
+	fmt.Println("Creating TwoFactorRecoveryCode table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.TwoFactorRecoveryCode{}); err != nil {
+		fmt.Printf("Error creating TwoFactorRecoveryCode table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("TwoFactorRecoveryCode table created successfully")
+
+	fmt.Println("Creating Permission table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.Permission{}); err != nil {
+		fmt.Printf("Error creating Permission table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("Permission table created successfully")
+
+	fmt.Println("Creating Role table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.Role{}); err != nil {
+		fmt.Printf("Error creating Role table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("Role table created successfully")
+
+	fmt.Println("Creating RolePermission table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.RolePermission{}); err != nil {
+		fmt.Printf("Error creating RolePermission table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("RolePermission table created successfully")
+
+	fmt.Println("Creating UserRoleAssignment table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.UserRoleAssignment{}); err != nil {
+		fmt.Printf("Error creating UserRoleAssignment table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("UserRoleAssignment table created successfully")
+
+	fmt.Println("Creating ServiceAccount table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.ServiceAccount{}); err != nil {
+		fmt.Printf("Error creating ServiceAccount table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("ServiceAccount table created successfully")
+
+	fmt.Println("Creating UserIdentity table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.UserIdentity{}); err != nil {
+		fmt.Printf("Error creating UserIdentity table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("UserIdentity table created successfully")
+
+	fmt.Println("Creating LoginSession table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.LoginSession{}); err != nil {
+		fmt.Printf("Error creating LoginSession table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("LoginSession table created successfully")
+
+	fmt.Println("Creating CancellationPolicy table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.CancellationPolicy{}); err != nil {
+		fmt.Printf("Error creating CancellationPolicy table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("CancellationPolicy table created successfully")
+
+	fmt.Println("Creating StandingBooking table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.StandingBooking{}); err != nil {
+		fmt.Printf("Error creating StandingBooking table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("StandingBooking table created successfully")
+
+	fmt.Println("Creating StandingBookingAttempt table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.StandingBookingAttempt{}); err != nil {
+		fmt.Printf("Error creating StandingBookingAttempt table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("StandingBookingAttempt table created successfully")
+
+	fmt.Println("Creating IdempotencyKey table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.IdempotencyKey{}); err != nil {
+		fmt.Printf("Error creating IdempotencyKey table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("IdempotencyKey table created successfully")
+
+	fmt.Println("Creating ReservationStateHistory table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.ReservationStateHistory{}); err != nil {
+		fmt.Printf("Error creating ReservationStateHistory table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("ReservationStateHistory table created successfully")
+
+	fmt.Println("Creating SessionSeries table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.SessionSeries{}); err != nil {
+		fmt.Printf("Error creating SessionSeries table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("SessionSeries table created successfully")
+
+	fmt.Println("Creating ProfessionalWorkingHours table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.ProfessionalWorkingHours{}); err != nil {
+		fmt.Printf("Error creating ProfessionalWorkingHours table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("ProfessionalWorkingHours table created successfully")
+
+	fmt.Println("Creating ProfessionalTimeOff table...")
+	if err := astroCatPsqlDB.AutoMigrate(&model.ProfessionalTimeOff{}); err != nil {
+		fmt.Printf("Error creating ProfessionalTimeOff table: %v\n", err)
+		panic(err)
+	}
+	fmt.Println("ProfessionalTimeOff table created successfully")
+
 	fmt.Println("All tables created successfully!")
 }
 
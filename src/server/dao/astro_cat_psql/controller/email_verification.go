@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type EmailVerification struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewEmailVerificationController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *EmailVerification {
+	return &EmailVerification{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (e *EmailVerification) CreateEmailVerification(emailVerification *model.EmailVerification) error {
+	result := e.PostgresqlDB.Create(emailVerification)
+	if result.Error != nil {
+		e.logger.Errorf("failed to create email verification: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Gets the latest email verification of a user that has not been consumed nor expired
+func (e *EmailVerification) GetActiveEmailVerificationByUserId(
+	userId uuid.UUID,
+) (*model.EmailVerification, error) {
+	var emailVerification model.EmailVerification
+	result := e.PostgresqlDB.
+		Where("user_id = ? AND consumed_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("created_at desc").
+		First(&emailVerification)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &emailVerification, nil
+}
+
+// Atomically increments the failed attempts of an email verification and returns the new count
+func (e *EmailVerification) IncrementEmailVerificationAttempts(emailVerificationId uuid.UUID) (int, error) {
+	var emailVerification model.EmailVerification
+	result := e.PostgresqlDB.Model(&emailVerification).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "attempts"}}}).
+		Where("id = ?", emailVerificationId).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		e.logger.Errorf("failed to increment email verification attempts: %v", result.Error)
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	return emailVerification.Attempts, nil
+}
+
+// Consumes every open email verification of a user, e.g. when a new code supersedes them
+func (e *EmailVerification) ConsumeUserEmailVerifications(userId uuid.UUID, updatedBy string) error {
+	result := e.PostgresqlDB.Model(&model.EmailVerification{}).
+		Where("user_id = ? AND consumed_at IS NULL", userId).
+		Updates(map[string]any{
+			"consumed_at": time.Now(),
+			"updated_by":  updatedBy,
+		})
+	if result.Error != nil {
+		e.logger.Errorf("failed to consume user email verifications: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
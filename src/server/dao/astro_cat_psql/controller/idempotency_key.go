@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type IdempotencyKey struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewIdempotencyKeyController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *IdempotencyKey {
+	return &IdempotencyKey{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Atomically claims a key for a request, taking it over when it has expired or when the request
+// that claimed it has been in progress for longer than `lockTimeout`, e.g. because its instance
+// crashed. Returns whether it was claimed and, when it was not, the stored key of the first request.
+func (i *IdempotencyKey) ClaimIdempotencyKey(
+	scope string,
+	key string,
+	requestHash string,
+	ttl time.Duration,
+	lockTimeout time.Duration,
+) (bool, *model.IdempotencyKey, error) {
+	now := time.Now()
+	idempotencyKey := model.IdempotencyKey{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	result := i.PostgresqlDB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"request_hash":  idempotencyKey.RequestHash,
+			"status_code":   0,
+			"content_type":  "",
+			"response_body": nil,
+			"created_at":    idempotencyKey.CreatedAt,
+			"expires_at":    idempotencyKey.ExpiresAt,
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{
+				SQL: "astro_cat_idempotency_key.expires_at <= ? OR " +
+					"(astro_cat_idempotency_key.status_code = 0 AND astro_cat_idempotency_key.created_at <= ?)",
+				Vars: []any{now, now.Add(-lockTimeout)},
+			},
+		}},
+	}).Create(&idempotencyKey)
+	if result.Error != nil {
+		i.logger.Errorf("failed to claim idempotency key: %v", result.Error)
+		return false, nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil, nil
+	}
+
+	var stored model.IdempotencyKey
+	if err := i.PostgresqlDB.Where("scope = ? AND key = ?", scope, key).First(&stored).Error; err != nil {
+		i.logger.Errorf("failed to get idempotency key: %v", err)
+		return false, nil, err
+	}
+
+	return false, &stored, nil
+}
+
+// Stores the response of the request that claimed a key
+func (i *IdempotencyKey) CompleteIdempotencyKey(
+	scope string,
+	key string,
+	statusCode int,
+	contentType string,
+	responseBody []byte,
+) error {
+	result := i.PostgresqlDB.Model(&model.IdempotencyKey{}).
+		Where("scope = ? AND key = ? AND status_code = 0", scope, key).
+		Updates(map[string]any{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": responseBody,
+		})
+	if result.Error != nil {
+		i.logger.Errorf("failed to complete idempotency key: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Frees a key whose request failed before storing a response, so a retry runs again
+func (i *IdempotencyKey) ReleaseIdempotencyKey(scope string, key string) error {
+	result := i.PostgresqlDB.
+		Where("scope = ? AND key = ? AND status_code = 0", scope, key).
+		Delete(&model.IdempotencyKey{})
+	if result.Error != nil {
+		i.logger.Errorf("failed to release idempotency key: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Removes keys whose time to live has already passed
+func (i *IdempotencyKey) DeleteExpiredIdempotencyKeys() (int64, error) {
+	result := i.PostgresqlDB.Where("expires_at <= ?", time.Now()).Delete(&model.IdempotencyKey{})
+	if result.Error != nil {
+		i.logger.Errorf("failed to delete expired idempotency keys: %v", result.Error)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type LoginSession struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewLoginSessionController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *LoginSession {
+	return &LoginSession{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (l *LoginSession) CreateLoginSession(session *model.LoginSession) error {
+	result := l.PostgresqlDB.Create(session)
+	if result.Error != nil {
+		l.logger.Errorf("failed to create login session: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (l *LoginSession) GetLoginSession(sessionId uuid.UUID) (*model.LoginSession, error) {
+	var session model.LoginSession
+	result := l.PostgresqlDB.Where("id = ?", sessionId).First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &session, nil
+}
+
+// Gets the sessions of a user that were neither revoked nor expired, most recently used first
+func (l *LoginSession) FetchActiveLoginSessionsByUserId(
+	userId uuid.UUID,
+) ([]*model.LoginSession, error) {
+	var sessions []*model.LoginSession
+	result := l.PostgresqlDB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return sessions, nil
+}
+
+// Records the device of a session and its last use
+func (l *LoginSession) UpdateLoginSessionDevice(
+	sessionId uuid.UUID,
+	device string,
+	userAgent string,
+	ipAddress string,
+) error {
+	result := l.PostgresqlDB.Model(&model.LoginSession{}).
+		Where("id = ?", sessionId).
+		UpdateColumns(map[string]any{
+			"device":       device,
+			"user_agent":   userAgent,
+			"ip_address":   ipAddress,
+			"last_seen_at": time.Now(),
+		})
+	if result.Error != nil {
+		l.logger.Errorf("failed to update login session device: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Records the last use of a session at most once per interval
+func (l *LoginSession) TouchLoginSession(
+	sessionId uuid.UUID,
+	ipAddress string,
+	interval time.Duration,
+) error {
+	now := time.Now()
+	result := l.PostgresqlDB.Model(&model.LoginSession{}).
+		Where("id = ? AND last_seen_at < ?", sessionId, now.Add(-interval)).
+		UpdateColumns(map[string]any{
+			"ip_address":   ipAddress,
+			"last_seen_at": now,
+		})
+	if result.Error != nil {
+		l.logger.Errorf("failed to update login session last seen: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Moves the expiration of a session along with its latest refresh token
+func (l *LoginSession) ExtendLoginSession(sessionId uuid.UUID, expiresAt time.Time) error {
+	result := l.PostgresqlDB.Model(&model.LoginSession{}).
+		Where("id = ? AND revoked_at IS NULL", sessionId).
+		UpdateColumns(map[string]any{
+			"expires_at":   expiresAt,
+			"last_seen_at": time.Now(),
+		})
+	if result.Error != nil {
+		l.logger.Errorf("failed to extend login session: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Revokes a session of a user together with its refresh tokens
+func (l *LoginSession) RevokeLoginSession(
+	userId uuid.UUID,
+	sessionId uuid.UUID,
+	updatedBy string,
+) error {
+	return l.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		var session model.LoginSession
+		if err := tx.Where("id = ? AND user_id = ?", sessionId, userId).First(&session).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&model.LoginSession{}).
+			Where("id = ? AND revoked_at IS NULL", sessionId).
+			Updates(map[string]any{"revoked_at": now, "updated_by": updatedBy}).Error; err != nil {
+			l.logger.Errorf("failed to revoke login session: %v", err)
+			return err
+		}
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", sessionId).
+			Updates(map[string]any{"revoked_at": now, "updated_by": updatedBy}).Error; err != nil {
+			l.logger.Errorf("failed to revoke login session refresh tokens: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (l *LoginSession) RevokeUserLoginSessions(userId uuid.UUID, updatedBy string) error {
+	result := l.PostgresqlDB.Model(&model.LoginSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Updates(map[string]any{
+			"revoked_at": time.Now(),
+			"updated_by": updatedBy,
+		})
+	if result.Error != nil {
+		l.logger.Errorf("failed to revoke user login sessions: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
@@ -1,8 +1,6 @@
 package controller
 
 import (
-	"time"
-
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -66,8 +64,9 @@ func (o *Onboarding) UpdateOnboarding(
 	id uuid.UUID,
 	documentType *model.DocumentType,
 	documentNumber *string,
+	documentNumberIndex *string,
 	phoneNumber *string,
-	birthDate *time.Time,
+	birthDate *string,
 	gender *model.Gender,
 	postalCode *string,
 	district *string,
@@ -86,6 +85,9 @@ func (o *Onboarding) UpdateOnboarding(
 	if documentNumber != nil {
 		updateFields["document_number"] = *documentNumber
 	}
+	if documentNumberIndex != nil {
+		updateFields["document_number_index"] = *documentNumberIndex
+	}
 	if phoneNumber != nil {
 		updateFields["phone_number"] = *phoneNumber
 	}
@@ -131,6 +133,62 @@ func (o *Onboarding) UpdateOnboarding(
 	return &onboarding, nil
 }
 
+// Finds an onboarding by document type and blind index of the document number
+func (o *Onboarding) GetOnboardingByDocumentNumberIndex(
+	documentType model.DocumentType,
+	documentNumberIndex string,
+) (*model.Onboarding, error) {
+	onboarding := &model.Onboarding{}
+	result := o.PostgresqlDB.
+		Where("document_type = ? AND document_number_index = ?", documentType, documentNumberIndex).
+		Order("created_at").
+		First(onboarding)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return onboarding, nil
+}
+
+// Returns up to size onboardings with an id greater than afterId, soft deleted ones included, to
+// walk the whole table in batches
+func (o *Onboarding) FetchOnboardingsBatch(afterId uuid.UUID, size int) ([]*model.Onboarding, error) {
+	onboardings := []*model.Onboarding{}
+	result := o.PostgresqlDB.Unscoped().
+		Where("id > ?", afterId).
+		Order("id").
+		Limit(size).
+		Find(&onboardings)
+	if result.Error != nil {
+		o.logger.Errorf("failed to fetch onboardings batch: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return onboardings, nil
+}
+
+// Writes the encrypted fields and blind index of an onboarding, leaving the audit fields untouched
+func (o *Onboarding) UpdateOnboardingEncryptedFields(onboarding *model.Onboarding) error {
+	result := o.PostgresqlDB.Unscoped().
+		Model(&model.Onboarding{}).
+		Where("id = ?", onboarding.Id).
+		UpdateColumns(map[string]any{
+			"document_number":       onboarding.DocumentNumber,
+			"document_number_index": onboarding.DocumentNumberIndex,
+			"phone_number":          onboarding.PhoneNumber,
+			"birth_date":            onboarding.BirthDate,
+			"address":               onboarding.Address,
+		})
+	if result.Error != nil {
+		o.logger.Errorf("failed to update onboarding encrypted fields: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (o *Onboarding) DeleteOnboarding(onboardingId uuid.UUID) error {
 	result := o.PostgresqlDB.Delete(&model.Onboarding{}, "id = ?", onboardingId)
 	if result.Error != nil {
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type PasswordReset struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewPasswordResetController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *PasswordReset {
+	return &PasswordReset{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (p *PasswordReset) CreatePasswordReset(passwordReset *model.PasswordReset) error {
+	result := p.PostgresqlDB.Create(passwordReset)
+	if result.Error != nil {
+		p.logger.Errorf("failed to create password reset: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Gets the latest password reset of a user that has not been consumed nor expired
+func (p *PasswordReset) GetActivePasswordResetByUserId(userId uuid.UUID) (*model.PasswordReset, error) {
+	var passwordReset model.PasswordReset
+	result := p.PostgresqlDB.
+		Where("user_id = ? AND consumed_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("created_at desc").
+		First(&passwordReset)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &passwordReset, nil
+}
+
+// Atomically increments the failed attempts of a password reset and returns the new count
+func (p *PasswordReset) IncrementPasswordResetAttempts(passwordResetId uuid.UUID) (int, error) {
+	var passwordReset model.PasswordReset
+	result := p.PostgresqlDB.Model(&passwordReset).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "attempts"}}}).
+		Where("id = ?", passwordResetId).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		p.logger.Errorf("failed to increment password reset attempts: %v", result.Error)
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	return passwordReset.Attempts, nil
+}
+
+func (p *PasswordReset) ConsumePasswordReset(passwordResetId uuid.UUID, updatedBy string) error {
+	result := p.PostgresqlDB.Model(&model.PasswordReset{}).
+		Where("id = ? AND consumed_at IS NULL", passwordResetId).
+		Updates(map[string]any{
+			"consumed_at": time.Now(),
+			"updated_by":  updatedBy,
+		})
+	if result.Error != nil {
+		p.logger.Errorf("failed to consume password reset: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Consumes every open password reset of a user, e.g. when a new PIN supersedes them
+func (p *PasswordReset) ConsumeUserPasswordResets(userId uuid.UUID, updatedBy string) error {
+	result := p.PostgresqlDB.Model(&model.PasswordReset{}).
+		Where("user_id = ? AND consumed_at IS NULL", userId).
+		Updates(map[string]any{
+			"consumed_at": time.Now(),
+			"updated_by":  updatedBy,
+		})
+	if result.Error != nil {
+		p.logger.Errorf("failed to consume user password resets: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
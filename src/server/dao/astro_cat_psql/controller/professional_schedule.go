@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type ProfessionalSchedule struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+// Create ProfessionalSchedule postgresql controller
+func NewProfessionalScheduleController(logger logging.Logger, postgresqlDB *gorm.DB) *ProfessionalSchedule {
+	return &ProfessionalSchedule{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Fetch the working hours of a professional by weekday and time.
+func (ps *ProfessionalSchedule) FetchWorkingHours(
+	professionalId uuid.UUID,
+) ([]*model.ProfessionalWorkingHours, error) {
+	workingHours := []*model.ProfessionalWorkingHours{}
+	if err := ps.PostgresqlDB.
+		Where("professional_id = ?", professionalId).
+		Order("weekday, start_time").
+		Find(&workingHours).Error; err != nil {
+		return nil, err
+	}
+
+	return workingHours, nil
+}
+
+// Replaces the working hours of a professional with the given ones.
+func (ps *ProfessionalSchedule) ReplaceWorkingHours(
+	professionalId uuid.UUID,
+	workingHours []*model.ProfessionalWorkingHours,
+) error {
+	err := ps.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("professional_id = ?", professionalId).
+			Delete(&model.ProfessionalWorkingHours{}).Error; err != nil {
+			return err
+		}
+		if len(workingHours) == 0 {
+			return nil
+		}
+
+		return tx.Create(&workingHours).Error
+	})
+	if err != nil {
+		ps.logger.Errorf("failed to replace working hours of professional %s: %v", professionalId, err)
+	}
+	return err
+}
+
+// Gets a time off given its id.
+func (ps *ProfessionalSchedule) GetTimeOff(timeOffId uuid.UUID) (*model.ProfessionalTimeOff, error) {
+	timeOff := &model.ProfessionalTimeOff{}
+	if err := ps.PostgresqlDB.First(timeOff, "id = ?", timeOffId).Error; err != nil {
+		return nil, err
+	}
+	return timeOff, nil
+}
+
+// Fetch the time off of a professional that overlaps [from, to), or all of it when no range is
+// given.
+func (ps *ProfessionalSchedule) FetchTimeOff(
+	professionalId uuid.UUID,
+	from *time.Time,
+	to *time.Time,
+) ([]*model.ProfessionalTimeOff, error) {
+	timeOff := []*model.ProfessionalTimeOff{}
+
+	query := ps.PostgresqlDB.Where("professional_id = ?", professionalId)
+	if from != nil {
+		query = query.Where("end_time > ?", *from)
+	}
+	if to != nil {
+		query = query.Where("start_time < ?", *to)
+	}
+
+	if err := query.Order("start_time, id").Find(&timeOff).Error; err != nil {
+		return nil, err
+	}
+	return timeOff, nil
+}
+
+// Creates a time off given its model.
+func (ps *ProfessionalSchedule) CreateTimeOff(timeOff *model.ProfessionalTimeOff) error {
+	if err := ps.PostgresqlDB.Create(timeOff).Error; err != nil {
+		ps.logger.Errorf("failed to create time off: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Soft deletes a time off given its id.
+func (ps *ProfessionalSchedule) DeleteTimeOff(timeOffId uuid.UUID) error {
+	result := ps.PostgresqlDB.Delete(&model.ProfessionalTimeOff{}, "id = ?", timeOffId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type RateLimitCounter struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewRateLimitCounterController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *RateLimitCounter {
+	return &RateLimitCounter{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Atomically counts a hit on a key. The counter restarts when its window has expired, so every
+// instance sharing the database sees the same count.
+func (r *RateLimitCounter) IncrementRateLimitCounter(
+	key string,
+	window time.Duration,
+) (*model.RateLimitCounter, error) {
+	now := time.Now()
+	counter := model.RateLimitCounter{
+		Key:       key,
+		Count:     1,
+		ExpiresAt: now.Add(window),
+	}
+
+	result := r.PostgresqlDB.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"count": gorm.Expr(
+					"CASE WHEN astro_cat_rate_limit_counter.expires_at <= ? THEN 1 "+
+						"ELSE astro_cat_rate_limit_counter.count + 1 END",
+					now,
+				),
+				"expires_at": gorm.Expr(
+					"CASE WHEN astro_cat_rate_limit_counter.expires_at <= ? THEN ? "+
+						"ELSE astro_cat_rate_limit_counter.expires_at END",
+					now,
+					counter.ExpiresAt,
+				),
+			}),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "count"}, {Name: "expires_at"}}},
+	).Create(&counter)
+	if result.Error != nil {
+		r.logger.Errorf("failed to increment rate limit counter: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return &counter, nil
+}
+
+// Removes counters whose window has already expired
+func (r *RateLimitCounter) DeleteExpiredRateLimitCounters() (int64, error) {
+	result := r.PostgresqlDB.Where("expires_at <= ?", time.Now()).Delete(&model.RateLimitCounter{})
+	if result.Error != nil {
+		r.logger.Errorf("failed to delete expired rate limit counters: %v", result.Error)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
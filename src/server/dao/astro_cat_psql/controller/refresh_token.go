@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type RefreshToken struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewRefreshTokenController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *RefreshToken {
+	return &RefreshToken{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (r *RefreshToken) CreateRefreshToken(refreshToken *model.RefreshToken) error {
+	result := r.PostgresqlDB.Create(refreshToken)
+	if result.Error != nil {
+		r.logger.Errorf("failed to create refresh token: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *RefreshToken) GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	var refreshToken model.RefreshToken
+	result := r.PostgresqlDB.Where("token_hash = ?", tokenHash).First(&refreshToken)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &refreshToken, nil
+}
+
+// Revokes a single token only if it is still active. Returns whether the token was revoked by this
+// call, so concurrent rotations of the same token can be detected.
+func (r *RefreshToken) RevokeRefreshToken(
+	refreshTokenId uuid.UUID,
+	replacedById *uuid.UUID,
+	updatedBy string,
+) (bool, error) {
+	result := r.PostgresqlDB.Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", refreshTokenId).
+		Updates(map[string]any{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": replacedById,
+			"updated_by":     updatedBy,
+		})
+	if result.Error != nil {
+		r.logger.Errorf("failed to revoke refresh token: %v", result.Error)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *RefreshToken) RevokeRefreshTokenFamily(familyId uuid.UUID, updatedBy string) error {
+	result := r.PostgresqlDB.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Updates(map[string]any{
+			"revoked_at": time.Now(),
+			"updated_by": updatedBy,
+		})
+	if result.Error != nil {
+		r.logger.Errorf("failed to revoke refresh token family: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *RefreshToken) RevokeUserRefreshTokens(userId uuid.UUID, updatedBy string) error {
+	result := r.PostgresqlDB.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Updates(map[string]any{
+			"revoked_at": time.Now(),
+			"updated_by": updatedBy,
+		})
+	if result.Error != nil {
+		r.logger.Errorf("failed to revoke user refresh tokens: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
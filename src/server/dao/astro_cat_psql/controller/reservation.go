@@ -1,12 +1,31 @@
 package controller
 
 import (
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"onichankimochi.com/astro_cat_backend/src/logging"
 	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+var (
+	// Returned when a reservation would take a spot of a session that is already full
+	ErrSessionFull = errors.New("session is full")
+	// Returned when a reservation would go over the reservation limit of the plan of its membership
+	ErrMembershipLimitReached = errors.New("membership reservation limit reached")
+	// Returned when checking in a reservation that is not confirmed
+	ErrReservationNotConfirmed = errors.New("reservation is not confirmed")
+	// Returned when checking in a reservation that was already checked in
+	ErrReservationAlreadyCheckedIn = errors.New("reservation already checked in")
+	// Returned when a reservation would change between states with no declared transition
+	ErrInvalidReservationTransition = errors.New("invalid reservation state transition")
+	// Returned when a reservation would be created in a state other than CONFIRMED
+	ErrInvalidReservationInitialState = errors.New("invalid reservation initial state")
 )
 
 type Reservation struct {
@@ -64,7 +83,10 @@ func (r *Reservation) FetchReservations(
 	return reservations, nil
 }
 
-// Creates a new reservation.
+// Creates a new reservation. When the reservation holds a spot, the session counter and the uses of
+// the membership are updated in the same transaction, with the session row locked so concurrent
+// bookings cannot go over its capacity. With joinWaitlist, a booking of a full session is put on
+// its waitlist instead of being rejected.
 func (r *Reservation) CreateReservation(
 	name string,
 	reservationTime time.Time,
@@ -72,8 +94,14 @@ func (r *Reservation) CreateReservation(
 	userId uuid.UUID,
 	sessionId uuid.UUID,
 	membershipId *uuid.UUID,
+	joinWaitlist bool,
 	updatedBy string,
 ) (*model.Reservation, error) {
+	// Reservations are booked CONFIRMED, and only end up WAITLISTED when the session is full
+	if model.ReservationState(state) != model.ReservationStateConfirmed {
+		return nil, ErrInvalidReservationInitialState
+	}
+
 	reservation := model.Reservation{
 		Id:               uuid.New(),
 		Name:             name,
@@ -85,7 +113,28 @@ func (r *Reservation) CreateReservation(
 		MembershipId:     membershipId,
 	}
 
-	if err := r.PostgresqlDB.Create(&reservation).Error; err != nil {
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		if reservation.State.HoldsSpot() {
+			err := claimReservationSpot(tx, sessionId, membershipId, updatedBy)
+			if err == ErrSessionFull && joinWaitlist {
+				reservation.State = model.ReservationStateWaitlisted
+			} else if err != nil {
+				return err
+			}
+		}
+		if reservation.State == model.ReservationStateWaitlisted {
+			reservation.WaitlistedAt = &reservation.LastModification
+		}
+
+		if err := tx.Create(&reservation).Error; err != nil {
+			return err
+		}
+		return recordReservationStateChange(tx, &reservation, nil, model.ReservationStateChangeBooking, updatedBy)
+	})
+	if err != nil {
+		if err != ErrSessionFull && err != ErrMembershipLimitReached {
+			r.logger.Errorf("failed to create reservation: %v", err)
+		}
 		return nil, err
 	}
 
@@ -97,7 +146,12 @@ func (r *Reservation) CreateReservation(
 	return &reservation, nil
 }
 
-// Updates an existing reservation.
+// Updates an existing reservation. A change of state must be a declared transition from the state
+// the reservation has once locked, and is recorded in its history. Moving it in or out of a state
+// that holds a spot, or to another session or membership, updates the counters in the same
+// transaction. With forfeitCredit, a spot
+// given back keeps its membership use as a penalty. A spot given back is taken by the waitlist of
+// the session, and the promoted reservations are returned.
 func (r *Reservation) UpdateReservation(
 	reservationId uuid.UUID,
 	name *string,
@@ -106,35 +160,254 @@ func (r *Reservation) UpdateReservation(
 	userId *uuid.UUID,
 	sessionId *uuid.UUID,
 	membershipId *uuid.UUID,
+	forfeitCredit bool,
 	updatedBy string,
-) (*model.Reservation, error) {
+) (*model.Reservation, []*model.Reservation, error) {
 	var reservation model.Reservation
-	if err := r.PostgresqlDB.First(&reservation, reservationId).Error; err != nil {
-		return nil, err
-	}
+	var promoted []*model.Reservation
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&reservation, "id = ?", reservationId).Error; err != nil {
+			return err
+		}
+		previous := reservation
+
+		// Update fields if provided
+		if name != nil {
+			reservation.Name = *name
+		}
+		if reservationTime != nil {
+			reservation.ReservationTime = *reservationTime
+		}
+		if state != nil && model.ReservationState(*state) != previous.State {
+			if _, ok := schemas.FindReservationTransition(string(previous.State), *state); !ok {
+				return ErrInvalidReservationTransition
+			}
+			reservation.State = model.ReservationState(*state)
+		}
+		if userId != nil {
+			reservation.UserId = *userId
+		}
+		if sessionId != nil {
+			reservation.SessionId = *sessionId
+		}
+		if membershipId != nil {
+			reservation.MembershipId = membershipId
+		}
+		reservation.LastModification = time.Now()
+		if reservation.State != model.ReservationStateWaitlisted {
+			reservation.WaitlistedAt = nil
+		} else if previous.State != model.ReservationStateWaitlisted || previous.SessionId != reservation.SessionId {
+			reservation.WaitlistedAt = &reservation.LastModification
+		}
 
-	// Update fields if provided
-	if name != nil {
-		reservation.Name = *name
+		if err := moveReservationSpot(tx, &previous, &reservation, forfeitCredit, updatedBy); err != nil {
+			return err
+		}
+		if err := tx.Save(&reservation).Error; err != nil {
+			return err
+		}
+		if reservation.State != previous.State {
+			if err := recordReservationStateChange(
+				tx,
+				&reservation,
+				&previous.State,
+				model.ReservationStateChangeUpdate,
+				updatedBy,
+			); err != nil {
+				return err
+			}
+		}
+
+		if previous.State.HoldsSpot() &&
+			(!reservation.State.HoldsSpot() || previous.SessionId != reservation.SessionId) {
+			var err error
+			promoted, err = promoteWaitlistedReservations(tx, previous.SessionId, updatedBy)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if err != ErrSessionFull && err != ErrMembershipLimitReached && err != gorm.ErrRecordNotFound &&
+			err != ErrInvalidReservationTransition {
+			r.logger.Errorf("failed to update reservation %s: %v", reservationId, err)
+		}
+		return nil, nil, err
 	}
-	if reservationTime != nil {
-		reservation.ReservationTime = *reservationTime
+
+	// Reload with preloaded relationships
+	if err := r.PostgresqlDB.Preload("User").Preload("Session").Preload("Membership").First(&reservation, reservation.Id).Error; err != nil {
+		return nil, nil, err
 	}
-	if state != nil {
-		reservation.State = model.ReservationState(*state)
+
+	return &reservation, promoted, nil
+}
+
+// Deletes a reservation, giving back its spot and membership use if it held one. With
+// forfeitCredit, the membership use is kept as a penalty. The spot is taken by the waitlist of the
+// session, and the promoted reservations are returned.
+func (r *Reservation) DeleteReservation(
+	reservationId uuid.UUID,
+	forfeitCredit bool,
+) ([]*model.Reservation, error) {
+	var promoted []*model.Reservation
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		var reservation model.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&reservation, "id = ?", reservationId).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&reservation).Error; err != nil {
+			return err
+		}
+		if !reservation.State.HoldsSpot() {
+			return nil
+		}
+
+		releasedMembershipId := reservation.MembershipId
+		if forfeitCredit {
+			releasedMembershipId = nil
+		}
+		if err := releaseReservationSpot(tx, reservation.SessionId, releasedMembershipId, "SYSTEM"); err != nil {
+			return err
+		}
+		var err error
+		promoted, err = promoteWaitlistedReservations(tx, reservation.SessionId, "SYSTEM")
+		return err
+	})
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.Errorf("failed to delete reservation %s: %v", reservationId, err)
+		}
+		return nil, err
 	}
-	if userId != nil {
-		reservation.UserId = *userId
+
+	return promoted, nil
+}
+
+// Bulk deletes reservations, giving back the spots and membership uses they held. The reservations
+// in `forfeitIds` keep their membership use as a penalty. The spots are taken by the waitlists of
+// the sessions, and the promoted reservations are returned.
+func (r *Reservation) BulkDeleteReservations(
+	reservationIds []uuid.UUID,
+	forfeitIds []uuid.UUID,
+) ([]*model.Reservation, error) {
+	var promoted []*model.Reservation
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		var reservations []*model.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN (?)", reservationIds).
+			Order("id").
+			Find(&reservations).Error; err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&reservations).Error; err != nil {
+			return err
+		}
+
+		releasedSessionIds := []uuid.UUID{}
+		for _, reservation := range reservations {
+			if !reservation.State.HoldsSpot() {
+				continue
+			}
+			releasedMembershipId := reservation.MembershipId
+			if slices.Contains(forfeitIds, reservation.Id) {
+				releasedMembershipId = nil
+			}
+			if err := releaseReservationSpot(tx, reservation.SessionId, releasedMembershipId, "SYSTEM"); err != nil {
+				return err
+			}
+			if !slices.Contains(releasedSessionIds, reservation.SessionId) {
+				releasedSessionIds = append(releasedSessionIds, reservation.SessionId)
+			}
+		}
+
+		for _, sessionId := range releasedSessionIds {
+			sessionPromoted, err := promoteWaitlistedReservations(tx, sessionId, "SYSTEM")
+			if err != nil {
+				return err
+			}
+			promoted = append(promoted, sessionPromoted...)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Errorf("failed to bulk delete reservations: %v", err)
+		return nil, err
 	}
-	if sessionId != nil {
-		reservation.SessionId = *sessionId
+
+	return promoted, nil
+}
+
+// Gets the place of a waitlisted reservation in the waitlist of its session, starting at 1, and
+// the size of that waitlist.
+func (r *Reservation) GetWaitlistPosition(reservation *model.Reservation) (int, int, error) {
+	var result struct {
+		Ahead int
+		Size  int
 	}
-	if membershipId != nil {
-		reservation.MembershipId = membershipId
+	err := r.PostgresqlDB.Model(&model.Reservation{}).
+		Select(
+			"COUNT(*) FILTER (WHERE (waitlisted_at, id) < (?, ?)) AS ahead, COUNT(*) AS size",
+			reservation.WaitlistedAt,
+			reservation.Id,
+		).
+		Where("session_id = ? AND state = ?", reservation.SessionId, model.ReservationStateWaitlisted).
+		Scan(&result).Error
+	if err != nil {
+		r.logger.Errorf("failed to get waitlist position of reservation %s: %v", reservation.Id, err)
+		return 0, 0, err
 	}
-	reservation.LastModification = time.Now()
 
-	if err := r.PostgresqlDB.Save(&reservation).Error; err != nil {
+	return result.Ahead + 1, result.Size, nil
+}
+
+// Checks the attendee of a confirmed reservation in, marking it as DONE. The reservation keeps its
+// spot and membership use. The row is locked so a token scanned twice only checks in once.
+func (r *Reservation) CheckInReservation(
+	reservationId uuid.UUID,
+	checkedInAt time.Time,
+	updatedBy string,
+) (*model.Reservation, error) {
+	var reservation model.Reservation
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&reservation, "id = ?", reservationId).Error; err != nil {
+			return err
+		}
+		if reservation.CheckedInAt != nil {
+			return ErrReservationAlreadyCheckedIn
+		}
+		if reservation.State != model.ReservationStateConfirmed {
+			return ErrReservationNotConfirmed
+		}
+
+		previousState := reservation.State
+		reservation.State = model.ReservationStateDone
+		reservation.CheckedInAt = &checkedInAt
+		reservation.LastModification = checkedInAt
+		reservation.UpdatedBy = updatedBy
+		if err := tx.Save(&reservation).Error; err != nil {
+			return err
+		}
+		return recordReservationStateChange(
+			tx,
+			&reservation,
+			&previousState,
+			model.ReservationStateChangeCheckIn,
+			updatedBy,
+		)
+	})
+	if err != nil {
+		if err != ErrReservationAlreadyCheckedIn && err != ErrReservationNotConfirmed &&
+			err != gorm.ErrRecordNotFound {
+			r.logger.Errorf("failed to check in reservation %s: %v", reservationId, err)
+		}
 		return nil, err
 	}
 
@@ -146,27 +419,445 @@ func (r *Reservation) UpdateReservation(
 	return &reservation, nil
 }
 
-// Deletes a reservation.
-func (r *Reservation) DeleteReservation(reservationId uuid.UUID) error {
-	result := r.PostgresqlDB.Where("id = ?", reservationId).Delete(&model.Reservation{})
-	if result.Error != nil {
-		return result.Error
+// Fetch the confirmed reservations never checked in of the sessions that ended before a given time.
+// Cancelled sessions are left out since nobody could attend them.
+func (r *Reservation) FetchMissedReservations(endedBefore time.Time) ([]*model.Reservation, error) {
+	reservations := []*model.Reservation{}
+	err := r.PostgresqlDB.Model(&model.Reservation{}).
+		Preload("Session").
+		Joins("JOIN astro_cat_session ON astro_cat_session.id = astro_cat_reservation.session_id").
+		Where("astro_cat_reservation.state = ?", model.ReservationStateConfirmed).
+		Where("astro_cat_reservation.checked_in_at IS NULL").
+		Where("astro_cat_session.end_time < ?", endedBefore).
+		Where("astro_cat_session.state <> ?", model.SessionStateCancelled).
+		Order("astro_cat_session.end_time, astro_cat_reservation.id").
+		Find(&reservations).Error
+	if err != nil {
+		r.logger.Errorf("failed to fetch missed reservations: %v", err)
+		return nil, err
 	}
 
-	// Check if any rows were affected
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	return reservations, nil
+}
+
+// Marks a confirmed reservation never checked in as a no-show, giving back its spot and, unless
+// forfeitCredit is set, its membership use. Returns false without changes when the reservation was
+// checked in or changed meanwhile, so concurrent passes do not mark it twice.
+func (r *Reservation) MarkReservationNoShow(
+	reservationId uuid.UUID,
+	forfeitCredit bool,
+	updatedBy string,
+) (bool, error) {
+	marked := false
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		var reservation model.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&reservation, "id = ?", reservationId).Error; err != nil {
+			return err
+		}
+		if reservation.State != model.ReservationStateConfirmed || reservation.CheckedInAt != nil {
+			return nil
+		}
+		previous := reservation
+
+		reservation.State = model.ReservationStateNoShow
+		reservation.LastModification = time.Now()
+		reservation.UpdatedBy = updatedBy
+		if err := moveReservationSpot(tx, &previous, &reservation, forfeitCredit, updatedBy); err != nil {
+			return err
+		}
+		if err := tx.Save(&reservation).Error; err != nil {
+			return err
+		}
+		if err := recordReservationStateChange(
+			tx,
+			&reservation,
+			&previous.State,
+			model.ReservationStateChangeNoShow,
+			updatedBy,
+		); err != nil {
+			return err
+		}
+
+		marked = true
+		return nil
+	})
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.Errorf("failed to mark reservation %s as no-show: %v", reservationId, err)
+		}
+		return false, err
 	}
 
-	return nil
+	return marked, nil
 }
 
-// Bulk deletes reservations.
-func (r *Reservation) BulkDeleteReservations(reservationIds []uuid.UUID) error {
-	result := r.PostgresqlDB.Where("id IN (?)", reservationIds).Delete(&model.Reservation{})
-	if result.Error != nil {
-		return result.Error
+// Annuls the confirmed and waitlisted reservations of a cancelled session, giving back the spots
+// and membership uses they held. Returns the annulled reservations.
+func annulSessionReservations(
+	tx *gorm.DB,
+	sessionId uuid.UUID,
+	updatedBy string,
+) ([]*model.Reservation, error) {
+	var reservations []*model.Reservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("session_id = ? AND state IN (?)", sessionId, []model.ReservationState{
+			model.ReservationStateConfirmed,
+			model.ReservationStateWaitlisted,
+		}).
+		Order("id").
+		Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, reservation := range reservations {
+		previous := *reservation
+
+		reservation.State = model.ReservationStateAnulled
+		reservation.LastModification = now
+		reservation.WaitlistedAt = nil
+		reservation.UpdatedBy = updatedBy
+		if err := moveReservationSpot(tx, &previous, reservation, false, updatedBy); err != nil {
+			return nil, err
+		}
+		if err := tx.Save(reservation).Error; err != nil {
+			return nil, err
+		}
+		if err := recordReservationStateChange(
+			tx,
+			reservation,
+			&previous.State,
+			model.ReservationStateChangeSessionCancelled,
+			updatedBy,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return reservations, nil
+}
+
+// Marks as DONE the confirmed reservations of completed sessions. Only the reservation rows are
+// locked, and Postgres checks them again once they are free, so concurrent passes skip the ones
+// already marked. Returns the reservations marked.
+func (r *Reservation) CompleteReservations(updatedBy string) ([]*model.Reservation, error) {
+	completed := []*model.Reservation{}
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		var reservations []*model.Reservation
+		if err := tx.Clauses(clause.Locking{
+			Strength: "UPDATE",
+			Table:    clause.Table{Name: clause.CurrentTable},
+		}).
+			Joins("JOIN astro_cat_session ON astro_cat_session.id = astro_cat_reservation.session_id").
+			Where("astro_cat_reservation.state = ?", model.ReservationStateConfirmed).
+			Where("astro_cat_session.state = ?", model.SessionStateCompleted).
+			Order("astro_cat_reservation.id").
+			Find(&reservations).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, reservation := range reservations {
+			previousState := reservation.State
+
+			reservation.State = model.ReservationStateDone
+			reservation.LastModification = now
+			reservation.UpdatedBy = updatedBy
+			if err := tx.Save(reservation).Error; err != nil {
+				return err
+			}
+			if err := recordReservationStateChange(
+				tx,
+				reservation,
+				&previousState,
+				model.ReservationStateChangeSessionCompleted,
+				updatedBy,
+			); err != nil {
+				return err
+			}
+			completed = append(completed, reservation)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Errorf("failed to complete reservations: %v", err)
+		return nil, err
+	}
+
+	return completed, nil
+}
+
+// Gets the start times of the sessions a user missed since a given time, oldest first. Only
+// sessions of the community count, and only those of one of its services when `serviceId` is given.
+func (r *Reservation) FetchNoShowTimes(
+	userId uuid.UUID,
+	communityId uuid.UUID,
+	serviceId *uuid.UUID,
+	since time.Time,
+) ([]time.Time, error) {
+	query := r.PostgresqlDB.Model(&model.Reservation{}).
+		Joins("JOIN astro_cat_session ON astro_cat_session.id = astro_cat_reservation.session_id").
+		Joins("JOIN astro_cat_community_service ON astro_cat_community_service.id = astro_cat_session.community_service_id").
+		Where("astro_cat_reservation.user_id = ?", userId).
+		Where("astro_cat_reservation.state = ?", model.ReservationStateNoShow).
+		Where("astro_cat_community_service.community_id = ?", communityId).
+		Where("astro_cat_session.start_time >= ?", since)
+	if serviceId != nil {
+		query = query.Where("astro_cat_community_service.service_id = ?", *serviceId)
+	}
+
+	times := []time.Time{}
+	if err := query.Order("astro_cat_session.start_time").
+		Pluck("astro_cat_session.start_time", &times).Error; err != nil {
+		r.logger.Errorf("failed to fetch no-shows of user %s: %v", userId, err)
+		return nil, err
+	}
+
+	return times, nil
+}
+
+// Gives back the spot of the previous version of a reservation and takes the one of the current
+// version, when they differ in state, session or membership. With forfeitCredit, a reservation
+// giving back its spot keeps its membership use, which is only returned if it is reinstated with
+// the same membership.
+func moveReservationSpot(
+	tx *gorm.DB,
+	previous *model.Reservation,
+	current *model.Reservation,
+	forfeitCredit bool,
+	updatedBy string,
+) error {
+	sameMembership := (previous.MembershipId == nil && current.MembershipId == nil) ||
+		(previous.MembershipId != nil && current.MembershipId != nil &&
+			*previous.MembershipId == *current.MembershipId)
+	if previous.State.HoldsSpot() == current.State.HoldsSpot() &&
+		previous.SessionId == current.SessionId && sameMembership {
+		return nil
+	}
+
+	if previous.State.HoldsSpot() {
+		releasedMembershipId := previous.MembershipId
+		if forfeitCredit && !current.State.HoldsSpot() && previous.MembershipId != nil {
+			releasedMembershipId = nil
+			current.CreditForfeited = true
+		}
+		if err := releaseReservationSpot(tx, previous.SessionId, releasedMembershipId, updatedBy); err != nil {
+			return err
+		}
+	} else if previous.CreditForfeited && (current.State.HoldsSpot() || !sameMembership) {
+		if sameMembership {
+			if err := releaseMembershipUse(tx, previous.MembershipId, updatedBy); err != nil {
+				return err
+			}
+		}
+		current.CreditForfeited = false
+	}
+	if current.State.HoldsSpot() {
+		return claimReservationSpot(tx, current.SessionId, current.MembershipId, updatedBy)
 	}
 
 	return nil
 }
+
+// Takes a spot of a session and a use of a membership. The session and membership rows stay locked
+// until the transaction ends, so concurrent bookings wait and then see the new counts. Memberships
+// without a counter are unlimited and left untouched.
+func claimReservationSpot(
+	tx *gorm.DB,
+	sessionId uuid.UUID,
+	membershipId *uuid.UUID,
+	updatedBy string,
+) error {
+	var session model.Session
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "registered_count", "capacity").
+		First(&session, "id = ?", sessionId).Error; err != nil {
+		return err
+	}
+	if session.RegisteredCount >= session.Capacity {
+		return ErrSessionFull
+	}
+
+	countsMembership, err := checkMembershipReservationLimit(tx, membershipId)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.Session{}).
+		Where("id = ?", sessionId).
+		Updates(map[string]any{
+			"registered_count": gorm.Expr("registered_count + 1"),
+			"updated_by":       updatedBy,
+		}).Error; err != nil {
+		return err
+	}
+
+	if !countsMembership {
+		return nil
+	}
+	return tx.Model(&model.Membership{}).
+		Where("id = ?", *membershipId).
+		Updates(map[string]any{
+			"reservations_used": gorm.Expr("reservations_used + 1"),
+			"updated_by":        updatedBy,
+		}).Error
+}
+
+// Locks a membership and checks it has a reservation left in its plan. Returns whether its uses are
+// counted at all, which is not the case for unlimited or missing memberships.
+func checkMembershipReservationLimit(tx *gorm.DB, membershipId *uuid.UUID) (bool, error) {
+	if membershipId == nil {
+		return false, nil
+	}
+
+	var membership model.Membership
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "plan_id", "reservations_used").
+		First(&membership, "id = ?", *membershipId).Error
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if membership.ReservationsUsed == nil {
+		return false, nil
+	}
+
+	var plan model.Plan
+	if err := tx.Select("id", "reservation_limit").First(&plan, "id = ?", membership.PlanId).Error; err != nil {
+		return false, err
+	}
+	if plan.ReservationLimit != nil && *membership.ReservationsUsed >= *plan.ReservationLimit {
+		return false, ErrMembershipLimitReached
+	}
+
+	return true, nil
+}
+
+// Gives back a spot of a session and a use of a membership, never going below zero
+func releaseReservationSpot(
+	tx *gorm.DB,
+	sessionId uuid.UUID,
+	membershipId *uuid.UUID,
+	updatedBy string,
+) error {
+	if err := tx.Model(&model.Session{}).
+		Where("id = ? AND registered_count > 0", sessionId).
+		Updates(map[string]any{
+			"registered_count": gorm.Expr("registered_count - 1"),
+			"updated_by":       updatedBy,
+		}).Error; err != nil {
+		return err
+	}
+
+	return releaseMembershipUse(tx, membershipId, updatedBy)
+}
+
+// Gives back a use of a membership, never going below zero
+func releaseMembershipUse(tx *gorm.DB, membershipId *uuid.UUID, updatedBy string) error {
+	if membershipId == nil {
+		return nil
+	}
+	return tx.Model(&model.Membership{}).
+		Where("id = ? AND reservations_used > 0", *membershipId).
+		Updates(map[string]any{
+			"reservations_used": gorm.Expr("reservations_used - 1"),
+			"updated_by":        updatedBy,
+		}).Error
+}
+
+// Gives the free spots of a session to its waitlist, first come first served. Waitlisted
+// reservations whose membership has no reservations left stay on the waitlist, and rows being
+// changed by another transaction are skipped rather than waited for. Sessions that are no longer
+// scheduled keep their waitlist as it is.
+func promoteWaitlistedReservations(
+	tx *gorm.DB,
+	sessionId uuid.UUID,
+	updatedBy string,
+) ([]*model.Reservation, error) {
+	var session model.Session
+	if err := tx.Select("id", "state").First(&session, "id = ?", sessionId).Error; err != nil {
+		return nil, err
+	}
+	if session.State != model.SessionStateScheduled && session.State != model.SessionStateRescheduled {
+		return nil, nil
+	}
+
+	var candidates []*model.Reservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("session_id = ? AND state = ?", sessionId, model.ReservationStateWaitlisted).
+		Order("waitlisted_at, id").
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	promotedIds := []uuid.UUID{}
+	for _, candidate := range candidates {
+		if candidate.MembershipId != nil {
+			isValid, err := isMembershipValid(tx, *candidate.MembershipId)
+			if err != nil {
+				return nil, err
+			}
+			if !isValid {
+				continue
+			}
+		}
+
+		err := claimReservationSpot(tx, sessionId, candidate.MembershipId, updatedBy)
+		if err == ErrSessionFull {
+			break
+		}
+		if err == ErrMembershipLimitReached {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		previousState := candidate.State
+		candidate.State = model.ReservationStateConfirmed
+		candidate.WaitlistedAt = nil
+		candidate.LastModification = time.Now()
+		candidate.UpdatedBy = updatedBy
+		if err := tx.Save(candidate).Error; err != nil {
+			return nil, err
+		}
+		if err := recordReservationStateChange(
+			tx,
+			candidate,
+			&previousState,
+			model.ReservationStateChangeWaitlistPromotion,
+			updatedBy,
+		); err != nil {
+			return nil, err
+		}
+		promotedIds = append(promotedIds, candidate.Id)
+	}
+
+	promoted := []*model.Reservation{}
+	if len(promotedIds) == 0 {
+		return promoted, nil
+	}
+	if err := tx.Preload("Session").Preload("Membership").
+		Where("id IN (?)", promotedIds).
+		Find(&promoted).Error; err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
+// Whether a membership can still be used to book, i.e. it is active and has not ended
+func isMembershipValid(tx *gorm.DB, membershipId uuid.UUID) (bool, error) {
+	var membership model.Membership
+	err := tx.Select("id", "status", "end_date").First(&membership, "id = ?", membershipId).Error
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return membership.Status == model.MembershipStatusActive && !membership.EndDate.Before(time.Now()), nil
+}
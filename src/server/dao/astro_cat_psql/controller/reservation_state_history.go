@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type ReservationStateHistory struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewReservationStateHistoryController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *ReservationStateHistory {
+	return &ReservationStateHistory{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Fetch the changes of state of a reservation, oldest first
+func (r *ReservationStateHistory) FetchReservationStateHistory(
+	reservationId uuid.UUID,
+) ([]*model.ReservationStateHistory, error) {
+	history := []*model.ReservationStateHistory{}
+	if err := r.PostgresqlDB.
+		Where("reservation_id = ?", reservationId).
+		Order("changed_at, id").
+		Find(&history).Error; err != nil {
+		r.logger.Errorf("failed to fetch state history of reservation %s: %v", reservationId, err)
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// Records the change of a reservation from a previous state, if any, to its current one
+func recordReservationStateChange(
+	tx *gorm.DB,
+	reservation *model.Reservation,
+	fromState *model.ReservationState,
+	reason model.ReservationStateChangeReason,
+	changedBy string,
+) error {
+	return tx.Create(&model.ReservationStateHistory{
+		Id:            uuid.New(),
+		FromState:     fromState,
+		ToState:       reservation.State,
+		Reason:        reason,
+		ChangedBy:     changedBy,
+		ChangedAt:     reservation.LastModification,
+		ReservationId: reservation.Id,
+	}).Error
+}
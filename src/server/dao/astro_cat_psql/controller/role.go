@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type Role struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewRoleController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *Role {
+	return &Role{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (r *Role) FetchRoles() ([]*model.Role, error) {
+	var roles []*model.Role
+	result := r.PostgresqlDB.
+		Preload("RolePermissions.Permission").
+		Order("name").
+		Find(&roles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return roles, nil
+}
+
+func (r *Role) GetRole(roleId uuid.UUID) (*model.Role, error) {
+	var role model.Role
+	result := r.PostgresqlDB.
+		Preload("RolePermissions.Permission").
+		Where("id = ?", roleId).
+		First(&role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &role, nil
+}
+
+func (r *Role) GetRoleByName(name model.UserRol) (*model.Role, error) {
+	var role model.Role
+	result := r.PostgresqlDB.
+		Preload("RolePermissions.Permission").
+		Where("name = ?", name).
+		First(&role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &role, nil
+}
+
+func (r *Role) FetchPermissions() ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	result := r.PostgresqlDB.Order("code").Find(&permissions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return permissions, nil
+}
+
+func (r *Role) GetPermissionsByCodes(codes []string) ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	result := r.PostgresqlDB.Where("code IN ?", codes).Find(&permissions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return permissions, nil
+}
+
+// Replaces the permissions of a role in a single transaction
+func (r *Role) SetRolePermissions(
+	roleId uuid.UUID,
+	rolePermissions []*model.RolePermission,
+) error {
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("role_id = ?", roleId).
+			Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(rolePermissions) == 0 {
+			return nil
+		}
+		return tx.Create(&rolePermissions).Error
+	})
+	if err != nil {
+		r.logger.Errorf("failed to set role permissions: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Inserts the given permissions, roles and role permissions skipping the ones that already
+// exist, so it is safe to run on every start up and from several instances at once. Role
+// permissions are seeded for roles created by this call and the ones of forceRole, and existing
+// roles only get the permissions that did not exist before this call.
+func (r *Role) SeedRolesAndPermissions(
+	permissions []*model.Permission,
+	roles []*model.Role,
+	rolePermissionCodes map[model.UserRol][]string,
+	forceRole model.UserRol,
+) error {
+	err := r.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		codes := make([]string, len(permissions))
+		for i, permission := range permissions {
+			codes[i] = permission.Code
+		}
+		var existingCodes []string
+		if err := tx.Model(&model.Permission{}).
+			Where("code IN ?", codes).
+			Pluck("code", &existingCodes).Error; err != nil {
+			return err
+		}
+		existing := map[string]bool{}
+		for _, code := range existingCodes {
+			existing[code] = true
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permissions).Error; err != nil {
+			return err
+		}
+
+		seededCodes := map[model.UserRol][]string{}
+		for _, role := range roles {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(role)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 || role.Name == forceRole {
+				seededCodes[role.Name] = rolePermissionCodes[role.Name]
+				continue
+			}
+			for _, code := range rolePermissionCodes[role.Name] {
+				if !existing[code] {
+					seededCodes[role.Name] = append(seededCodes[role.Name], code)
+				}
+			}
+		}
+
+		for roleName, codes := range seededCodes {
+			if len(codes) == 0 {
+				continue
+			}
+
+			// Ids are looked up again since conflicting inserts keep the existing rows
+			var role model.Role
+			if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+				return err
+			}
+			var rolePermissionModels []*model.Permission
+			if err := tx.Where("code IN ?", codes).Find(&rolePermissionModels).Error; err != nil {
+				return err
+			}
+			if len(rolePermissionModels) == 0 {
+				continue
+			}
+			rolePermissions := make([]*model.RolePermission, len(rolePermissionModels))
+			for i, permission := range rolePermissionModels {
+				rolePermissions[i] = &model.RolePermission{
+					Id:           uuid.New(),
+					RoleId:       role.Id,
+					PermissionId: permission.Id,
+					AuditFields:  model.AuditFields{UpdatedBy: "SYSTEM"},
+				}
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&rolePermissions).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		r.logger.Errorf("failed to seed roles and permissions: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Gets every permission of a user: the ones of their primary rol, which apply everywhere, and the
+// ones of their role assignments, which may be limited to a community. A community scoped role only
+// counts through its assignments, never as a primary rol.
+func (r *Role) GetUserPermissionGrants(
+	userId uuid.UUID,
+	primaryRol model.UserRol,
+) ([]*model.PermissionGrant, error) {
+	var grants []*model.PermissionGrant
+	result := r.PostgresqlDB.Raw(`
+		SELECT p.code, NULL::uuid AS community_id
+		FROM astro_cat_role r
+		JOIN astro_cat_role_permission rp ON rp.role_id = r.id AND rp.deleted_at IS NULL
+		JOIN astro_cat_permission p ON p.id = rp.permission_id AND p.deleted_at IS NULL
+		WHERE r.name = ? AND NOT r.community_scoped AND r.deleted_at IS NULL
+		UNION
+		SELECT p.code, a.community_id
+		FROM astro_cat_user_role_assignment a
+		JOIN astro_cat_role r ON r.id = a.role_id AND r.deleted_at IS NULL
+		JOIN astro_cat_role_permission rp ON rp.role_id = r.id AND rp.deleted_at IS NULL
+		JOIN astro_cat_permission p ON p.id = rp.permission_id AND p.deleted_at IS NULL
+		WHERE a.user_id = ? AND a.deleted_at IS NULL`,
+		primaryRol,
+		userId,
+	).Scan(&grants)
+	if result.Error != nil {
+		r.logger.Errorf("failed to get user permission grants: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return grants, nil
+}
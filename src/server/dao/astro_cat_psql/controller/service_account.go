@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type ServiceAccount struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewServiceAccountController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *ServiceAccount {
+	return &ServiceAccount{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (s *ServiceAccount) CreateServiceAccount(serviceAccount *model.ServiceAccount) error {
+	result := s.PostgresqlDB.Create(serviceAccount)
+	if result.Error != nil {
+		s.logger.Errorf("failed to create service account: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (s *ServiceAccount) GetServiceAccount(serviceAccountId uuid.UUID) (*model.ServiceAccount, error) {
+	var serviceAccount model.ServiceAccount
+	result := s.PostgresqlDB.Where("id = ?", serviceAccountId).First(&serviceAccount)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &serviceAccount, nil
+}
+
+func (s *ServiceAccount) GetServiceAccountByName(name string) (*model.ServiceAccount, error) {
+	var serviceAccount model.ServiceAccount
+	result := s.PostgresqlDB.Where("name = ?", name).First(&serviceAccount)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &serviceAccount, nil
+}
+
+func (s *ServiceAccount) GetServiceAccountByKeyHash(keyHash string) (*model.ServiceAccount, error) {
+	var serviceAccount model.ServiceAccount
+	result := s.PostgresqlDB.Where("key_hash = ?", keyHash).First(&serviceAccount)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &serviceAccount, nil
+}
+
+func (s *ServiceAccount) FetchServiceAccounts() ([]*model.ServiceAccount, error) {
+	var serviceAccounts []*model.ServiceAccount
+	result := s.PostgresqlDB.Order("name").Find(&serviceAccounts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return serviceAccounts, nil
+}
+
+// Replaces the API key of a service account, which also lifts a previous revocation
+func (s *ServiceAccount) RotateServiceAccountKey(
+	serviceAccountId uuid.UUID,
+	keyPrefix string,
+	keyHash string,
+	expiresAt *time.Time,
+	updatedBy string,
+) error {
+	result := s.PostgresqlDB.Model(&model.ServiceAccount{}).
+		Where("id = ?", serviceAccountId).
+		Updates(map[string]any{
+			"key_prefix":   keyPrefix,
+			"key_hash":     keyHash,
+			"expires_at":   expiresAt,
+			"last_used_at": nil,
+			"revoked_at":   nil,
+			"updated_by":   updatedBy,
+		})
+	if result.Error != nil {
+		s.logger.Errorf("failed to rotate service account key: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *ServiceAccount) RevokeServiceAccountKey(serviceAccountId uuid.UUID, updatedBy string) error {
+	result := s.PostgresqlDB.Model(&model.ServiceAccount{}).
+		Where("id = ?", serviceAccountId).
+		Updates(map[string]any{
+			"revoked_at": gorm.Expr("COALESCE(revoked_at, ?)", time.Now()),
+			"updated_by": updatedBy,
+		})
+	if result.Error != nil {
+		s.logger.Errorf("failed to revoke service account key: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Records the use of a key. Writes are skipped while the last record is newer than the given
+// interval so busy integrations do not update the row on every request.
+func (s *ServiceAccount) TouchServiceAccountLastUsed(
+	serviceAccountId uuid.UUID,
+	interval time.Duration,
+) error {
+	now := time.Now()
+	result := s.PostgresqlDB.Model(&model.ServiceAccount{}).
+		Where(
+			"id = ? AND (last_used_at IS NULL OR last_used_at < ?)",
+			serviceAccountId,
+			now.Add(-interval),
+		).
+		UpdateColumn("last_used_at", now)
+	if result.Error != nil {
+		s.logger.Errorf("failed to update service account last used: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
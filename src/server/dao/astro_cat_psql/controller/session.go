@@ -43,7 +43,9 @@ func (s *Session) GetSession(sessionId uuid.UUID) (*model.Session, error) {
 	return session, nil
 }
 
-// Updates session given fields to update.
+// Updates session given fields to update. Cancelling the session annuls its reservations in the
+// same transaction, so a session is never left cancelled with reservations holding its spots.
+// Returns the updated session, the session as it was before the update and the annulled reservations.
 func (s *Session) UpdateSession(
 	id uuid.UUID,
 	title *string,
@@ -58,7 +60,7 @@ func (s *Session) UpdateSession(
 	localId *uuid.UUID,
 	communityServiceId *uuid.UUID,
 	updatedBy string,
-) (*model.Session, error) {
+) (*model.Session, *model.Session, []*model.Reservation, error) {
 	updateFields := map[string]any{
 		"updated_by": updatedBy,
 	}
@@ -96,28 +98,46 @@ func (s *Session) UpdateSession(
 		updateFields["community_service_id"] = *communityServiceId
 	}
 
-	// Check if there are any fields to update
-	var session model.Session
-	if len(updateFields) == 1 {
-		if err := s.PostgresqlDB.Preload("Professional").Preload("Local").First(&session, "id = ?", id).Error; err != nil {
-			return nil, err
+	var previous, session model.Session
+	annulled := []*model.Reservation{}
+	err := s.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&previous, "id = ?", id).Error; err != nil {
+			return err
 		}
-		return &session, nil
-	}
 
-	// Perform the update
-	result := s.PostgresqlDB.Model(&session).
-		Clauses(clause.Returning{}).
-		Where("id = ?", id).
-		Updates(updateFields)
-	if result.Error != nil {
-		return nil, result.Error
-	}
-	if result.RowsAffected == 0 {
-		return nil, gorm.ErrRecordNotFound
+		// Check if there are any fields to update
+		if len(updateFields) == 1 {
+			return tx.Preload("Professional").Preload("Local").First(&session, "id = ?", id).Error
+		}
+
+		// Perform the update
+		result := tx.Model(&session).
+			Clauses(clause.Returning{}).
+			Where("id = ?", id).
+			Updates(updateFields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if session.State != model.SessionStateCancelled || previous.State == model.SessionStateCancelled {
+			return nil
+		}
+		var err error
+		if annulled, err = annulSessionReservations(tx, id, updatedBy); err != nil {
+			return err
+		}
+		// Reads the session again to return the spots given back
+		return tx.First(&session, "id = ?", id).Error
+	})
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	return &session, nil
+	return &session, &previous, annulled, nil
 }
 
 // Soft deletes a session given its ID.
@@ -188,3 +208,45 @@ func (s *Session) BulkCreateSessions(sessions []*model.Session) error {
 	}
 	return s.PostgresqlDB.Create(&sessions).Error
 }
+
+// Moves the sessions that started before `now` to ONGOING and the ones that ended before it to
+// COMPLETED. Each update only touches sessions still in an earlier state, so running it again or
+// on several instances at once changes nothing twice. Returns how many sessions moved to each state.
+func (s *Session) AdvanceSessionStates(now time.Time, updatedBy string) (int64, int64, error) {
+	started := int64(0)
+	completed := int64(0)
+	err := s.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Session{}).
+			Where("state IN (?)", []model.SessionState{
+				model.SessionStateScheduled,
+				model.SessionStateRescheduled,
+				model.SessionStateOnGoing,
+			}).
+			Where("end_time <= ?", now).
+			Updates(map[string]any{"state": model.SessionStateCompleted, "updated_by": updatedBy})
+		if result.Error != nil {
+			return result.Error
+		}
+		completed = result.RowsAffected
+
+		result = tx.Model(&model.Session{}).
+			Where("state IN (?)", []model.SessionState{
+				model.SessionStateScheduled,
+				model.SessionStateRescheduled,
+			}).
+			Where("start_time <= ? AND end_time > ?", now, now).
+			Updates(map[string]any{"state": model.SessionStateOnGoing, "updated_by": updatedBy})
+		if result.Error != nil {
+			return result.Error
+		}
+		started = result.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorf("failed to advance session states: %v", err)
+		return 0, 0, err
+	}
+
+	return started, completed, nil
+}
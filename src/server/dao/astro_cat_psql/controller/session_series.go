@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type SessionSeries struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+// Create SessionSeries postgresql controller
+func NewSessionSeriesController(logger logging.Logger, postgresqlDB *gorm.DB) *SessionSeries {
+	return &SessionSeries{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Creates a session series given its model.
+func (ss *SessionSeries) CreateSessionSeries(series *model.SessionSeries) error {
+	if err := ss.PostgresqlDB.Create(series).Error; err != nil {
+		ss.logger.Errorf("failed to create session series: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Gets a session series given its id.
+func (ss *SessionSeries) GetSessionSeries(seriesId uuid.UUID) (*model.SessionSeries, error) {
+	series := &model.SessionSeries{}
+	if err := ss.PostgresqlDB.First(series, "id = ?", seriesId).Error; err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Fetch all session series, filtered by professional if `professionalIds` is not empty.
+func (ss *SessionSeries) FetchSessionSeries(professionalIds []uuid.UUID) ([]*model.SessionSeries, error) {
+	series := []*model.SessionSeries{}
+
+	query := ss.PostgresqlDB.Model(&model.SessionSeries{})
+	if len(professionalIds) > 0 {
+		query = query.Where("professional_id IN (?)", professionalIds)
+	}
+
+	if err := query.Order("start_date, id").Find(&series).Error; err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Fetch the series whose sessions have not been generated up to a day yet.
+func (ss *SessionSeries) FetchSessionSeriesToGenerate(until time.Time) ([]*model.SessionSeries, error) {
+	series := []*model.SessionSeries{}
+	if err := ss.PostgresqlDB.
+		Where("generated_until < ?", until).
+		Order("generated_until, id").
+		Find(&series).Error; err != nil {
+		ss.logger.Errorf("failed to fetch session series to generate: %v", err)
+		return nil, err
+	}
+	return series, nil
+}
+
+// Updates the template or the recurrence of a session series given fields to update.
+func (ss *SessionSeries) UpdateSessionSeries(
+	seriesId uuid.UUID,
+	title *string,
+	startTime *string,
+	durationMinutes *int,
+	capacity *int,
+	sessionLink *string,
+	professionalId *uuid.UUID,
+	localId *uuid.UUID,
+	rule *string,
+	updatedBy string,
+) (*model.SessionSeries, error) {
+	updateFields := map[string]any{
+		"updated_by": updatedBy,
+	}
+	if title != nil {
+		updateFields["title"] = *title
+	}
+	if startTime != nil {
+		updateFields["start_time"] = *startTime
+	}
+	if durationMinutes != nil {
+		updateFields["duration_minutes"] = *durationMinutes
+	}
+	if capacity != nil {
+		updateFields["capacity"] = *capacity
+	}
+	if sessionLink != nil {
+		updateFields["session_link"] = *sessionLink
+	}
+	if professionalId != nil {
+		updateFields["professional_id"] = *professionalId
+	}
+	if localId != nil {
+		updateFields["local_id"] = *localId
+	}
+	if rule != nil {
+		updateFields["rule"] = *rule
+	}
+
+	var series model.SessionSeries
+	result := ss.PostgresqlDB.Model(&series).
+		Clauses(clause.Returning{}).
+		Where("id = ?", seriesId).
+		Updates(updateFields)
+	if result.Error != nil {
+		ss.logger.Errorf("failed to update session series %s: %v", seriesId, result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &series, nil
+}
+
+// Records that the sessions of a series have been generated for the days before `until`. It never
+// goes back, so runs on several instances can finish in any order.
+func (ss *SessionSeries) AdvanceSessionSeries(seriesId uuid.UUID, until time.Time) error {
+	err := ss.PostgresqlDB.Model(&model.SessionSeries{}).
+		Where("id = ?", seriesId).
+		Update("generated_until", gorm.Expr("GREATEST(generated_until, ?)", until)).Error
+	if err != nil {
+		ss.logger.Errorf("failed to advance session series %s: %v", seriesId, err)
+	}
+	return err
+}
+
+// Splits a series at the day `newSeries` starts on: the series keeps the occurrences before it
+// under `rule`, and the new series takes the sessions from that day on.
+func (ss *SessionSeries) SplitSessionSeries(
+	seriesId uuid.UUID,
+	rule string,
+	newSeries *model.SessionSeries,
+) error {
+	err := ss.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		var series model.SessionSeries
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&series, "id = ?", seriesId).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&series).Updates(map[string]any{
+			"rule":       rule,
+			"updated_by": newSeries.UpdatedBy,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(newSeries).Error; err != nil {
+			return err
+		}
+
+		// Deleted sessions move as well, so the new series does not generate them again
+		return tx.Unscoped().Model(&model.Session{}).
+			Where("series_id = ? AND occurrence_date >= ?", seriesId, newSeries.StartDate).
+			Update("series_id", newSeries.Id).Error
+	})
+	if err != nil && err != gorm.ErrRecordNotFound {
+		ss.logger.Errorf("failed to split session series %s: %v", seriesId, err)
+	}
+	return err
+}
+
+// Creates the session of a series for one of its days. Returns false when the series already has
+// it, so concurrent runs on several instances generate each occurrence only once.
+func (ss *SessionSeries) CreateSeriesSession(session *model.Session) (bool, error) {
+	result := ss.PostgresqlDB.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "series_id"}, {Name: "occurrence_date"}},
+			DoNothing: true,
+		}).
+		Create(session)
+	if result.Error != nil {
+		ss.logger.Errorf("failed to create session of series %s: %v", *session.SeriesId, result.Error)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Fetch the sessions of a series by the day they were generated for, from a day if provided.
+func (ss *SessionSeries) FetchSeriesSessions(seriesId uuid.UUID, from *time.Time) ([]*model.Session, error) {
+	sessions := []*model.Session{}
+
+	query := ss.PostgresqlDB.Where("series_id = ?", seriesId)
+	if from != nil {
+		query = query.Where("occurrence_date >= ?", *from)
+	}
+
+	if err := query.Order("occurrence_date, id").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Marks a session of a series as edited on its own.
+func (ss *SessionSeries) DetachSeriesSession(sessionId uuid.UUID, updatedBy string) error {
+	result := ss.PostgresqlDB.Model(&model.Session{}).
+		Where("id = ? AND series_id IS NOT NULL", sessionId).
+		Updates(map[string]any{"detached": true, "updated_by": updatedBy})
+	if result.Error != nil {
+		ss.logger.Errorf("failed to detach session %s from its series: %v", sessionId, result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type StandingBooking struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+// Create StandingBooking postgresql controller
+func NewStandingBookingController(logger logging.Logger, postgresqlDB *gorm.DB) *StandingBooking {
+	return &StandingBooking{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Gets a standing booking model given its ID.
+func (sb *StandingBooking) GetStandingBooking(standingBookingId uuid.UUID) (*model.StandingBooking, error) {
+	var standingBooking model.StandingBooking
+	if err := sb.PostgresqlDB.First(&standingBooking, "id = ?", standingBookingId).Error; err != nil {
+		return nil, err
+	}
+
+	return &standingBooking, nil
+}
+
+// Fetch all standing bookings, filtered by `userIds` if provided, and only the active ones with
+// `activeOnly`.
+func (sb *StandingBooking) FetchStandingBookings(
+	userIds []uuid.UUID,
+	activeOnly bool,
+) ([]*model.StandingBooking, error) {
+	standingBookings := []*model.StandingBooking{}
+
+	query := sb.PostgresqlDB.Model(&model.StandingBooking{})
+	if len(userIds) > 0 {
+		query = query.Where("user_id IN (?)", userIds)
+	}
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+
+	if err := query.Order("created_at, id").Find(&standingBookings).Error; err != nil {
+		return nil, err
+	}
+
+	return standingBookings, nil
+}
+
+// Fetch the active standing bookings of a service led by a professional.
+func (sb *StandingBooking) FetchActiveStandingBookingsByScope(
+	serviceId uuid.UUID,
+	professionalId uuid.UUID,
+) ([]*model.StandingBooking, error) {
+	standingBookings := []*model.StandingBooking{}
+	if err := sb.PostgresqlDB.
+		Where("service_id = ? AND professional_id = ? AND active = ?", serviceId, professionalId, true).
+		Order("created_at, id").
+		Find(&standingBookings).Error; err != nil {
+		return nil, err
+	}
+
+	return standingBookings, nil
+}
+
+// Whether a user is already subscribed to a pattern.
+func (sb *StandingBooking) ExistsStandingBooking(
+	userId uuid.UUID,
+	serviceId uuid.UUID,
+	professionalId uuid.UUID,
+	weekday int,
+	startTime string,
+) (bool, error) {
+	var count int64
+	if err := sb.PostgresqlDB.Model(&model.StandingBooking{}).
+		Where(
+			"user_id = ? AND service_id = ? AND professional_id = ? AND weekday = ? AND start_time = ?",
+			userId,
+			serviceId,
+			professionalId,
+			weekday,
+			startTime,
+		).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Creates a standing booking given its model.
+func (sb *StandingBooking) CreateStandingBooking(standingBooking *model.StandingBooking) error {
+	return sb.PostgresqlDB.Create(standingBooking).Error
+}
+
+// Updates a standing booking given fields to update.
+func (sb *StandingBooking) UpdateStandingBooking(
+	standingBookingId uuid.UUID,
+	active *bool,
+	membershipId *uuid.UUID,
+	updatedBy string,
+) (*model.StandingBooking, error) {
+	updateFields := map[string]any{
+		"updated_by": updatedBy,
+	}
+
+	if active != nil {
+		updateFields["active"] = *active
+	}
+	if membershipId != nil {
+		updateFields["membership_id"] = *membershipId
+	}
+
+	var standingBooking model.StandingBooking
+	result := sb.PostgresqlDB.Model(&standingBooking).
+		Clauses(clause.Returning{}).
+		Where("id = ?", standingBookingId).
+		Updates(updateFields)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &standingBooking, nil
+}
+
+// Soft deletes a standing booking given its ID. The reservations it made are kept.
+func (sb *StandingBooking) DeleteStandingBooking(standingBookingId uuid.UUID) error {
+	result := sb.PostgresqlDB.Delete(&model.StandingBooking{}, "id = ?", standingBookingId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Fetch the scheduled sessions of the service and professional of a standing booking starting
+// within a time range that it has not tried to book yet, or whose attempt was claimed before
+// `staleBefore` and never finished. Weekday and time are left to the caller, which knows the
+// booking time zone.
+func (sb *StandingBooking) FetchUntriedSessions(
+	standingBooking *model.StandingBooking,
+	from time.Time,
+	to time.Time,
+	staleBefore time.Time,
+) ([]*model.Session, error) {
+	sessions := []*model.Session{}
+	err := sb.PostgresqlDB.Model(&model.Session{}).
+		Joins("JOIN astro_cat_community_service ON astro_cat_community_service.id = astro_cat_session.community_service_id").
+		Where("astro_cat_community_service.service_id = ?", standingBooking.ServiceId).
+		Where("astro_cat_session.professional_id = ?", standingBooking.ProfessionalId).
+		Where(
+			"astro_cat_session.state IN (?)",
+			[]model.SessionState{model.SessionStateScheduled, model.SessionStateRescheduled},
+		).
+		Where("astro_cat_session.start_time > ? AND astro_cat_session.start_time <= ?", from, to).
+		Where(
+			"NOT EXISTS (SELECT 1 FROM astro_cat_standing_booking_attempt WHERE "+
+				"astro_cat_standing_booking_attempt.standing_booking_id = ? AND "+
+				"astro_cat_standing_booking_attempt.session_id = astro_cat_session.id AND "+
+				"NOT (astro_cat_standing_booking_attempt.status = ? AND "+
+				"astro_cat_standing_booking_attempt.updated_at < ?))",
+			standingBooking.Id,
+			model.StandingBookingAttemptPending,
+			staleBefore,
+		).
+		Order("astro_cat_session.start_time, astro_cat_session.id").
+		Find(&sessions).Error
+	if err != nil {
+		sb.logger.Errorf("failed to fetch untried sessions of standing booking %s: %v", standingBooking.Id, err)
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Records that a standing booking is trying to book a session. Returns nil when it already did,
+// so concurrent runs on several instances book each session only once. An attempt claimed before
+// `staleBefore` and never finished, e.g. by an instance that crashed, is taken over.
+func (sb *StandingBooking) ClaimStandingBookingAttempt(
+	standingBookingId uuid.UUID,
+	sessionId uuid.UUID,
+	staleBefore time.Time,
+	updatedBy string,
+) (*model.StandingBookingAttempt, error) {
+	attempt := &model.StandingBookingAttempt{
+		Id:                uuid.New(),
+		Status:            model.StandingBookingAttemptPending,
+		StandingBookingId: standingBookingId,
+		SessionId:         sessionId,
+		AuditFields: model.AuditFields{
+			UpdatedBy: updatedBy,
+		},
+	}
+
+	result := sb.PostgresqlDB.Clauses(clause.OnConflict{DoNothing: true}).Create(attempt)
+	if result.Error != nil {
+		sb.logger.Errorf("failed to claim standing booking attempt: %v", result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return attempt, nil
+	}
+
+	// The update waits for any other instance taking it over and checks the row again, so only
+	// one of them gets it
+	var staleAttempt model.StandingBookingAttempt
+	result = sb.PostgresqlDB.Model(&staleAttempt).
+		Clauses(clause.Returning{}).
+		Where("standing_booking_id = ? AND session_id = ?", standingBookingId, sessionId).
+		Where("status = ? AND updated_at < ?", model.StandingBookingAttemptPending, staleBefore).
+		Updates(map[string]any{
+			"updated_at": time.Now(),
+			"updated_by": updatedBy,
+		})
+	if result.Error != nil {
+		sb.logger.Errorf("failed to take over standing booking attempt: %v", result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &staleAttempt, nil
+}
+
+// Gives up an attempt that could not be finished, so the session is tried again on the next run.
+func (sb *StandingBooking) ReleaseStandingBookingAttempt(attemptId uuid.UUID) error {
+	result := sb.PostgresqlDB.Unscoped().
+		Where("id = ? AND status = ?", attemptId, model.StandingBookingAttemptPending).
+		Delete(&model.StandingBookingAttempt{})
+	if result.Error != nil {
+		sb.logger.Errorf("failed to release standing booking attempt %s: %v", attemptId, result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// Records the outcome of an attempt: the reservation it made, or the reason it was skipped.
+func (sb *StandingBooking) FinishStandingBookingAttempt(
+	attemptId uuid.UUID,
+	status model.StandingBookingAttemptStatus,
+	reservationId *uuid.UUID,
+	reason *string,
+	detail *string,
+	updatedBy string,
+) (*model.StandingBookingAttempt, error) {
+	var attempt model.StandingBookingAttempt
+	result := sb.PostgresqlDB.Model(&attempt).
+		Clauses(clause.Returning{}).
+		Where("id = ?", attemptId).
+		Updates(map[string]any{
+			"status":         status,
+			"reservation_id": reservationId,
+			"reason":         reason,
+			"detail":         detail,
+			"updated_by":     updatedBy,
+		})
+	if result.Error != nil {
+		sb.logger.Errorf("failed to finish standing booking attempt %s: %v", attemptId, result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &attempt, nil
+}
+
+// Fetch the attempts of a standing booking with their sessions, latest sessions first.
+func (sb *StandingBooking) FetchStandingBookingAttempts(
+	standingBookingId uuid.UUID,
+) ([]*model.StandingBookingAttempt, error) {
+	attempts := []*model.StandingBookingAttempt{}
+	if err := sb.PostgresqlDB.
+		Preload("Session").
+		Joins("JOIN astro_cat_session ON astro_cat_session.id = astro_cat_standing_booking_attempt.session_id").
+		Where("astro_cat_standing_booking_attempt.standing_booking_id = ?", standingBookingId).
+		Order("astro_cat_session.start_time DESC, astro_cat_standing_booking_attempt.id").
+		Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type TwoFactorRecoveryCode struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewTwoFactorRecoveryCodeController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *TwoFactorRecoveryCode {
+	return &TwoFactorRecoveryCode{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+// Replaces every recovery code of a user with the given ones in a single transaction
+func (t *TwoFactorRecoveryCode) ReplaceUserTwoFactorRecoveryCodes(
+	userId uuid.UUID,
+	recoveryCodes []*model.TwoFactorRecoveryCode,
+) error {
+	err := t.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).
+			Delete(&model.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(recoveryCodes) == 0 {
+			return nil
+		}
+		return tx.Create(&recoveryCodes).Error
+	})
+	if err != nil {
+		t.logger.Errorf("failed to replace two factor recovery codes: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Marks an unused recovery code of a user as used. Returns whether a code was consumed, so the
+// same code can never be used twice even by concurrent requests.
+func (t *TwoFactorRecoveryCode) UseTwoFactorRecoveryCode(userId uuid.UUID, codeHash string) (bool, error) {
+	result := t.PostgresqlDB.Model(&model.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Updates(map[string]any{
+			"used_at":    time.Now(),
+			"updated_by": "SYSTEM",
+		})
+	if result.Error != nil {
+		t.logger.Errorf("failed to use two factor recovery code: %v", result.Error)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (t *TwoFactorRecoveryCode) CountUnusedTwoFactorRecoveryCodes(userId uuid.UUID) (int64, error) {
+	var count int64
+	result := t.PostgresqlDB.Model(&model.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return count, nil
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -104,6 +106,11 @@ func (u *User) UpdateUser(
 	}
 	if email != nil {
 		updateFields["email"] = *email
+		// A new address has to be verified again
+		updateFields["email_verified_at"] = gorm.Expr(
+			"CASE WHEN email = ? THEN email_verified_at ELSE NULL END",
+			*email,
+		)
 	}
 	if rol != nil {
 		updateFields["rol"] = *rol
@@ -181,6 +188,167 @@ func (u *User) UpdateUserPassword(userId uuid.UUID, hashedPassword string) error
 	return nil
 }
 
+func (u *User) IncrementUserTokenVersion(userId uuid.UUID, updatedBy string) error {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]any{
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_by":    updatedBy,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Atomically counts a failed login and returns the consecutive failures so far
+func (u *User) IncrementUserFailedLoginAttempts(userId uuid.UUID) (int, error) {
+	var user model.User
+	result := u.PostgresqlDB.
+		Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		Where("id = ?", userId).
+		Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1"))
+	if result.Error != nil {
+		u.logger.Errorf("failed to increment failed login attempts: %v", result.Error)
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return user.FailedLoginAttempts, nil
+}
+
+func (u *User) LockUser(userId uuid.UUID, lockedUntil time.Time) error {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ?", userId).
+		Update("locked_until", lockedUntil)
+	if result.Error != nil {
+		u.logger.Errorf("failed to lock user: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (u *User) UnlockUser(userId uuid.UUID, updatedBy string) error {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]any{
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+			"updated_by":            updatedBy,
+		})
+	if result.Error != nil {
+		u.logger.Errorf("failed to unlock user: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Stores a new TOTP secret for a user. Only allowed while two-factor authentication is not
+// enabled, so a confirmed secret can not be replaced without disabling it first.
+func (u *User) SetUserTwoFactorSecret(userId uuid.UUID, secret string, updatedBy string) error {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ? AND two_factor_enabled_at IS NULL", userId).
+		Updates(map[string]any{
+			"two_factor_secret": secret,
+			"updated_by":        updatedBy,
+		})
+	if result.Error != nil {
+		u.logger.Errorf("failed to set two factor secret: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Confirms the pending TOTP secret of a user, recording the step of the code used to confirm it
+func (u *User) EnableUserTwoFactor(userId uuid.UUID, step int64, updatedBy string) error {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ? AND two_factor_secret IS NOT NULL AND two_factor_enabled_at IS NULL", userId).
+		Updates(map[string]any{
+			"two_factor_enabled_at": time.Now(),
+			"two_factor_last_step":  step,
+			"updated_by":            updatedBy,
+		})
+	if result.Error != nil {
+		u.logger.Errorf("failed to enable two factor: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (u *User) DisableUserTwoFactor(userId uuid.UUID, updatedBy string) error {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]any{
+			"two_factor_secret":     nil,
+			"two_factor_enabled_at": nil,
+			"two_factor_last_step":  0,
+			"updated_by":            updatedBy,
+		})
+	if result.Error != nil {
+		u.logger.Errorf("failed to disable two factor: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Records a TOTP step as used only if it is newer than the last one. Returns whether the step was
+// accepted, so a code can not be replayed even by concurrent requests.
+func (u *User) UseUserTwoFactorStep(userId uuid.UUID, step int64) (bool, error) {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ? AND two_factor_last_step < ?", userId, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		u.logger.Errorf("failed to use two factor step: %v", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Sets the email verification timestamp of a user only if it is not already set
+func (u *User) MarkUserEmailVerified(userId uuid.UUID, updatedBy string) error {
+	result := u.PostgresqlDB.
+		Model(&model.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]any{
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+			"updated_by":        updatedBy,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (u *User) GetUsersByIds(userIds []uuid.UUID) ([]*model.User, error) {
 	var users []*model.User
 	result := u.PostgresqlDB.
@@ -196,3 +364,121 @@ func (u *User) GetUsersByIds(userIds []uuid.UUID) ([]*model.User, error) {
 
 	return users, nil
 }
+
+// Anonymizes the personal data of a user and their onboarding in a single transaction. Reservations,
+// memberships and audit logs are kept for reporting, only the personal data they copy is scrubbed.
+// Identities and one-time codes are hard deleted and every token of the user is revoked.
+func (u *User) EraseUser(userId uuid.UUID, anonymizedEmail string, updatedBy string) error {
+	return u.PostgresqlDB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND erased_at IS NULL", userId).
+			Updates(map[string]any{
+				"name":                  "Deleted",
+				"first_last_name":       "User",
+				"second_last_name":      nil,
+				"password":              "",
+				"email":                 anonymizedEmail,
+				"image_url":             "",
+				"email_verified_at":     nil,
+				"failed_login_attempts": 0,
+				"locked_until":          nil,
+				"two_factor_secret":     nil,
+				"two_factor_enabled_at": nil,
+				"two_factor_last_step":  0,
+				"token_version":         gorm.Expr("token_version + 1"),
+				"erased_at":             now,
+				"updated_by":            updatedBy,
+			})
+		if result.Error != nil {
+			u.logger.Errorf("failed to anonymize user: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var onboardingIds []uuid.UUID
+		if err := tx.Model(&model.Onboarding{}).
+			Where("user_id = ?", userId).
+			Pluck("id", &onboardingIds).Error; err != nil {
+			u.logger.Errorf("failed to fetch user onboarding: %v", err)
+			return err
+		}
+		if err := tx.Model(&model.Onboarding{}).
+			Where("user_id = ?", userId).
+			Updates(map[string]any{
+				"document_number":       "",
+				"document_number_index": "",
+				"phone_number":          "",
+				"birth_date":            nil,
+				"gender":                nil,
+				"postal_code":           "",
+				"address":               "",
+				"district":              "",
+				"province":              "",
+				"region":                "",
+				"updated_by":            updatedBy,
+			}).Error; err != nil {
+			u.logger.Errorf("failed to anonymize user onboarding: %v", err)
+			return err
+		}
+
+		for _, secret := range []any{
+			&model.UserIdentity{},
+			&model.EmailVerification{},
+			&model.PasswordReset{},
+			&model.TwoFactorRecoveryCode{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", userId).Delete(secret).Error; err != nil {
+				u.logger.Errorf("failed to delete user credentials: %v", err)
+				return err
+			}
+		}
+
+		revoked := map[string]any{"revoked_at": now, "updated_by": updatedBy}
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userId).
+			Updates(revoked).Error; err != nil {
+			u.logger.Errorf("failed to revoke user refresh tokens: %v", err)
+			return err
+		}
+		if err := tx.Model(&model.LoginSession{}).
+			Where("user_id = ? AND revoked_at IS NULL", userId).
+			Updates(revoked).Error; err != nil {
+			u.logger.Errorf("failed to revoke user login sessions: %v", err)
+			return err
+		}
+		if err := tx.Model(&model.LoginSession{}).
+			Where("user_id = ?", userId).
+			Updates(map[string]any{"device": "", "user_agent": "", "ip_address": ""}).Error; err != nil {
+			u.logger.Errorf("failed to anonymize user login sessions: %v", err)
+			return err
+		}
+
+		if err := tx.Model(&model.AuditLog{}).
+			Where("user_id = ?", userId).
+			Updates(map[string]any{
+				"user_email": anonymizedEmail,
+				"ip_address": "",
+				"user_agent": nil,
+			}).Error; err != nil {
+			u.logger.Errorf("failed to anonymize user audit logs: %v", err)
+			return err
+		}
+		entityIds := append(onboardingIds, userId)
+		if err := tx.Model(&model.AuditLog{}).
+			Where("entity_id IN ?", entityIds).
+			Updates(map[string]any{
+				"entity_name": anonymizedEmail,
+				"old_values":  nil,
+				"new_values":  nil,
+			}).Error; err != nil {
+			u.logger.Errorf("failed to anonymize user audit log entries: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
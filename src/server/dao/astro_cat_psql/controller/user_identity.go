@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type UserIdentity struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewUserIdentityController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *UserIdentity {
+	return &UserIdentity{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (u *UserIdentity) CreateUserIdentity(identity *model.UserIdentity) error {
+	result := u.PostgresqlDB.Create(identity)
+	if result.Error != nil {
+		u.logger.Errorf("failed to create user identity: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (u *UserIdentity) GetUserIdentityByProviderSubject(
+	provider string,
+	subject string,
+) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	result := u.PostgresqlDB.
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &identity, nil
+}
+
+func (u *UserIdentity) FetchUserIdentitiesByUserId(userId uuid.UUID) ([]*model.UserIdentity, error) {
+	var identities []*model.UserIdentity
+	result := u.PostgresqlDB.
+		Where("user_id = ?", userId).
+		Order("created_at").
+		Find(&identities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return identities, nil
+}
+
+// Removes the identity of a user at a provider. Rows are hard deleted so the identity can be linked
+// again, to the same or another user.
+func (u *UserIdentity) DeleteUserIdentity(userId uuid.UUID, provider string) error {
+	result := u.PostgresqlDB.Unscoped().
+		Where("user_id = ? AND provider = ?", userId, provider).
+		Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		u.logger.Errorf("failed to delete user identity: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type UserRoleAssignment struct {
+	logger       logging.Logger
+	PostgresqlDB *gorm.DB
+}
+
+func NewUserRoleAssignmentController(
+	logger logging.Logger,
+	postgresqlDB *gorm.DB,
+) *UserRoleAssignment {
+	return &UserRoleAssignment{
+		logger:       logger,
+		PostgresqlDB: postgresqlDB,
+	}
+}
+
+func (u *UserRoleAssignment) CreateUserRoleAssignment(assignment *model.UserRoleAssignment) error {
+	result := u.PostgresqlDB.Create(assignment)
+	if result.Error != nil {
+		u.logger.Errorf("failed to create user role assignment: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (u *UserRoleAssignment) GetUserRoleAssignment(assignmentId uuid.UUID) (*model.UserRoleAssignment, error) {
+	var assignment model.UserRoleAssignment
+	result := u.PostgresqlDB.Preload("Role").Where("id = ?", assignmentId).First(&assignment)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &assignment, nil
+}
+
+func (u *UserRoleAssignment) FetchUserRoleAssignmentsByUserId(
+	userId uuid.UUID,
+) ([]*model.UserRoleAssignment, error) {
+	var assignments []*model.UserRoleAssignment
+	result := u.PostgresqlDB.
+		Preload("Role").
+		Where("user_id = ?", userId).
+		Order("created_at").
+		Find(&assignments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return assignments, nil
+}
+
+// Checks whether a user already has a role, in the given community or globally when it is nil
+func (u *UserRoleAssignment) ExistsUserRoleAssignment(
+	userId uuid.UUID,
+	roleId uuid.UUID,
+	communityId *uuid.UUID,
+) (bool, error) {
+	query := u.PostgresqlDB.Model(&model.UserRoleAssignment{}).
+		Where("user_id = ? AND role_id = ?", userId, roleId)
+	if communityId == nil {
+		query = query.Where("community_id IS NULL")
+	} else {
+		query = query.Where("community_id = ?", *communityId)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *UserRoleAssignment) DeleteUserRoleAssignment(assignmentId uuid.UUID) error {
+	result := u.PostgresqlDB.Delete(&model.UserRoleAssignment{}, "id = ?", assignmentId)
+	if result.Error != nil {
+		u.logger.Errorf("failed to delete user role assignment: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -25,6 +25,18 @@ const (
 	AuditActionCreateReservation AuditActionType = "CREATE_RESERVATION"
 	AuditActionCancelReservation AuditActionType = "CANCEL_RESERVATION"
 	AuditActionUpdateProfile     AuditActionType = "UPDATE_PROFILE"
+
+	// Security actions
+	AuditActionAccountLocked     AuditActionType = "ACCOUNT_LOCKED"
+	AuditActionAccountUnlocked   AuditActionType = "ACCOUNT_UNLOCKED"
+	AuditActionTwoFactorEnabled  AuditActionType = "TWO_FACTOR_ENABLED"
+	AuditActionTwoFactorDisabled AuditActionType = "TWO_FACTOR_DISABLED"
+	AuditActionAccessDenied      AuditActionType = "ACCESS_DENIED"
+	AuditActionIdentityLinked    AuditActionType = "IDENTITY_LINKED"
+	AuditActionIdentityUnlinked  AuditActionType = "IDENTITY_UNLINKED"
+	AuditActionSessionRevoked    AuditActionType = "SESSION_REVOKED"
+	AuditActionDataExported      AuditActionType = "DATA_EXPORTED"
+	AuditActionDataErased        AuditActionType = "DATA_ERASED"
 )
 
 type AuditEntityType string
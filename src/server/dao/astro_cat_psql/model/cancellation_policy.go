@@ -0,0 +1,27 @@
+package model
+
+import "github.com/google/uuid"
+
+// Rules applied when a reservation of a community is cancelled or missed. A policy with a service
+// applies to the sessions of that service in the community, and takes precedence over the policy
+// of the whole community.
+type CancellationPolicy struct {
+	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+	// Hours before the start of a session until which a cancellation gives the credit back
+	FreeCancellationHours       int
+	LateCancellationBurnsCredit bool
+	NoShowBurnsCredit           bool
+	// No-shows within NoShowWindowDays after which the user cannot book, 0 disables the ban
+	NoShowLimit      int
+	NoShowWindowDays int
+	AuditFields
+
+	CommunityId uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Community   Community  `gorm:"foreignKey:CommunityId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ServiceId   *uuid.UUID `gorm:"type:uuid"`
+	Service     *Service   `gorm:"foreignKey:ServiceId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (CancellationPolicy) TableName() string {
+	return "astro_cat_cancellation_policy"
+}
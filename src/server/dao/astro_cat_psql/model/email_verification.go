@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailVerification struct {
+	Id         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	CodeHash   string     `gorm:"not null"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	Attempts   int        `gorm:"not null;default:0"`
+	ConsumedAt *time.Time // Set when the code is used, superseded or locked
+	AuditFields
+
+	UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+	User   User      `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (EmailVerification) TableName() string {
+	return "astro_cat_email_verification"
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	Scope        string    `gorm:"size:100;primaryKey"` // Caller that sent the key, e.g. user:<id>
+	Key          string    `gorm:"size:255;primaryKey"` // Value of the Idempotency-Key header
+	RequestHash  string    `gorm:"size:64;not null"`    // SHA-256 of the method, path and body
+	StatusCode   int       `gorm:"not null;default:0"`  // 0 while the first request is in progress
+	ContentType  string    `gorm:"size:255"`
+	ResponseBody []byte    `gorm:"type:bytea"`
+	CreatedAt    time.Time `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"` // After this, the key can be reused
+}
+
+func (IdempotencyKey) TableName() string {
+	return "astro_cat_idempotency_key"
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A login of a user on a device. Its id is the family id of the refresh tokens of the login and is
+// carried by its access tokens, so revoking it ends the login right away.
+type LoginSession struct {
+	Id         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserId     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User       User       `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Device     string     `gorm:"size:100"` // Short description derived from the user agent
+	UserAgent  string     `gorm:"size:512"`
+	IpAddress  string     `gorm:"size:64"` // Address of the last request
+	LastSeenAt time.Time  `gorm:"not null"`
+	ExpiresAt  time.Time  `gorm:"not null"` // Expiration of the current refresh token
+	RevokedAt  *time.Time // Pointer to allow NULL values
+	AuditFields
+}
+
+func (LoginSession) TableName() string {
+	return "astro_cat_login_session"
+}
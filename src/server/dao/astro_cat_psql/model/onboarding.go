@@ -1,8 +1,6 @@
 package model
 
 import (
-	"time"
-
 	"github.com/google/uuid"
 )
 
@@ -24,17 +22,18 @@ const (
 
 type Onboarding struct {
 	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
-	// Documento
-	DocumentType   DocumentType `gorm:"type:varchar(50);not null"`
-	DocumentNumber string       `gorm:"type:varchar(20);not null"`
+	// Documento. The number, phone, birth date and address are encrypted by the adapters
+	DocumentType        DocumentType `gorm:"type:varchar(50);not null"`
+	DocumentNumber      string       `gorm:"type:text;not null"`
+	DocumentNumberIndex string       `gorm:"type:varchar(64);index"` // Blind index of the document number
 	// Contacto
-	PhoneNumber string `gorm:"type:varchar(20);not null"`
+	PhoneNumber string `gorm:"type:text;not null"`
 	// Datos personales adicionales
-	BirthDate *time.Time `gorm:"type:date"`
-	Gender    *Gender    `gorm:"type:varchar(20)"`
+	BirthDate *string `gorm:"type:text"` // Formatted as 2006-01-02 before encryption
+	Gender    *Gender `gorm:"type:varchar(20)"`
 	// Dirección
 	PostalCode string  `gorm:"type:varchar(10);not null"`
-	Address    string  `gorm:"type:varchar(255);not null"`
+	Address    string  `gorm:"type:text;not null"`
 	District   *string `gorm:"type:varchar(100);not null"`
 	Province   *string `gorm:"type:varchar(100);not null"`
 	Region     *string `gorm:"type:varchar(100);not null"`
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetChannel string
+
+const (
+	PasswordResetChannelEmail PasswordResetChannel = "EMAIL"
+	PasswordResetChannelSms   PasswordResetChannel = "SMS"
+)
+
+type PasswordReset struct {
+	Id         uuid.UUID            `gorm:"type:uuid;primaryKey"`
+	PinHash    string               `gorm:"not null"`
+	Channel    PasswordResetChannel `gorm:"size:10;not null"`
+	ExpiresAt  time.Time            `gorm:"not null"`
+	Attempts   int                  `gorm:"not null;default:0"`
+	ConsumedAt *time.Time           // Set when the PIN is used, superseded or locked
+	AuditFields
+
+	UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+	User   User      `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (PasswordReset) TableName() string {
+	return "astro_cat_password_reset"
+}
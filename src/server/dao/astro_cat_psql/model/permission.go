@@ -0,0 +1,20 @@
+package model
+
+import "github.com/google/uuid"
+
+type Permission struct {
+	Id          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Code        string    `gorm:"size:100;not null;uniqueIndex"` // e.g. "reservation:write"
+	Description string
+	AuditFields
+}
+
+func (Permission) TableName() string {
+	return "astro_cat_permission"
+}
+
+// A permission granted to a user, either globally or only within a community
+type PermissionGrant struct {
+	Code        string
+	CommunityId *uuid.UUID
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Range of a weekday in which a professional leads sessions. Professionals without working hours
+// can lead sessions at any time.
+type ProfessionalWorkingHours struct {
+	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+	// Day of the week, 0 being Sunday as in time.Weekday
+	Weekday int
+	// Times of day the range starts and ends at in the booking time zone, as HH:MM
+	StartTime string `gorm:"size:5"`
+	EndTime   string `gorm:"size:5"`
+	AuditFields
+
+	ProfessionalId uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Professional   Professional `gorm:"foreignKey:ProfessionalId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (ProfessionalWorkingHours) TableName() string {
+	return "astro_cat_professional_working_hours"
+}
+
+// Absence of a professional, in which they cannot lead sessions
+type ProfessionalTimeOff struct {
+	Id        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	StartTime time.Time
+	EndTime   time.Time
+	Reason    *string
+	AuditFields
+
+	ProfessionalId uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Professional   Professional `gorm:"foreignKey:ProfessionalId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (ProfessionalTimeOff) TableName() string {
+	return "astro_cat_professional_time_off"
+}
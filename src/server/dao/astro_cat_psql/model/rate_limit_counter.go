@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type RateLimitCounter struct {
+	Key       string    `gorm:"size:255;primaryKey"` // Scope plus client identifier, e.g. login:ip:1.2.3.4
+	Count     int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null;index"` // End of the current window
+}
+
+func (RateLimitCounter) TableName() string {
+	return "astro_cat_rate_limit_counter"
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	Id           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	FamilyId     uuid.UUID  `gorm:"type:uuid;not null;index"` // Shared by every token rotated from the same login
+	TokenHash    string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt    time.Time  `gorm:"not null"`
+	RevokedAt    *time.Time // Pointer to allow NULL values
+	ReplacedById *uuid.UUID `gorm:"type:uuid"` // Token issued when this one was rotated
+	AuditFields
+
+	UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+	User   User      `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (RefreshToken) TableName() string {
+	return "astro_cat_refresh_token"
+}
@@ -9,18 +9,31 @@ import (
 type ReservationState string
 
 const (
-	ReservationStateDone      ReservationState = "DONE"
-	ReservationStateConfirmed ReservationState = "CONFIRMED"
-	ReservationStateCancelled ReservationState = "CANCELLED"
-	ReservationStateAnulled   ReservationState = "ANULLED"
+	ReservationStateDone       ReservationState = "DONE"
+	ReservationStateConfirmed  ReservationState = "CONFIRMED"
+	ReservationStateCancelled  ReservationState = "CANCELLED"
+	ReservationStateAnulled    ReservationState = "ANULLED"
+	ReservationStateWaitlisted ReservationState = "WAITLISTED"
+	ReservationStateNoShow     ReservationState = "NO_SHOW"
 )
 
+// Whether a reservation in this state takes a spot of its session and a use of its membership
+func (s ReservationState) HoldsSpot() bool {
+	return s == ReservationStateConfirmed || s == ReservationStateDone
+}
+
 type Reservation struct {
 	Id               uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Name             string
 	ReservationTime  time.Time
 	State            ReservationState
 	LastModification time.Time
+	// When the reservation joined the waitlist of its session, which orders the waitlist
+	WaitlistedAt *time.Time `gorm:"index"`
+	// The membership use was kept as a penalty when the reservation was cancelled late or missed
+	CreditForfeited bool
+	// When the attendee was checked in at the session
+	CheckedInAt *time.Time
 	AuditFields
 
 	UserId       uuid.UUID   `gorm:"type:uuid"`
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationStateChangeReason string
+
+const (
+	ReservationStateChangeBooking           ReservationStateChangeReason = "BOOKING"
+	ReservationStateChangeUpdate            ReservationStateChangeReason = "UPDATE"
+	ReservationStateChangeCheckIn           ReservationStateChangeReason = "CHECK_IN"
+	ReservationStateChangeNoShow            ReservationStateChangeReason = "NO_SHOW_SWEEP"
+	ReservationStateChangeWaitlistPromotion ReservationStateChangeReason = "WAITLIST_PROMOTION"
+	ReservationStateChangeSessionCancelled  ReservationStateChangeReason = "SESSION_CANCELLED"
+	ReservationStateChangeSessionCompleted  ReservationStateChangeReason = "SESSION_COMPLETED"
+)
+
+// One change of state of a reservation. The first one of a reservation has no previous state.
+type ReservationStateHistory struct {
+	Id        uuid.UUID                    `gorm:"type:uuid;primaryKey"`
+	FromState *ReservationState            `gorm:"size:20"`
+	ToState   ReservationState             `gorm:"size:20;not null"`
+	Reason    ReservationStateChangeReason `gorm:"size:30;not null"`
+	ChangedBy string                       `gorm:"size:255"`
+	ChangedAt time.Time                    `gorm:"not null"`
+
+	ReservationId uuid.UUID   `gorm:"type:uuid;index"`
+	Reservation   Reservation `gorm:"foreignKey:ReservationId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (ReservationStateHistory) TableName() string {
+	return "astro_cat_reservation_state_history"
+}
@@ -0,0 +1,17 @@
+package model
+
+import "github.com/google/uuid"
+
+type Role struct {
+	Id              uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name            UserRol   `gorm:"size:50;not null;uniqueIndex"`
+	Description     string
+	CommunityScoped bool `gorm:"not null;default:false"` // Assignments of the role must name a community
+	AuditFields
+
+	RolePermissions []*RolePermission `gorm:"foreignKey:RoleId"`
+}
+
+func (Role) TableName() string {
+	return "astro_cat_role"
+}
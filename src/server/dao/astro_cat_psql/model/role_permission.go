@@ -0,0 +1,16 @@
+package model
+
+import "github.com/google/uuid"
+
+type RolePermission struct {
+	Id           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	RoleId       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_role_permission"`
+	Role         Role       `gorm:"foreignKey:RoleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PermissionId uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_role_permission"`
+	Permission   Permission `gorm:"foreignKey:PermissionId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	AuditFields
+}
+
+func (RolePermission) TableName() string {
+	return "astro_cat_role_permission"
+}
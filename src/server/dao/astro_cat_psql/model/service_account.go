@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A machine client, such as a kiosk or an accounting system, that authenticates with an API key
+type ServiceAccount struct {
+	Id          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Name        string     `gorm:"size:100;not null;uniqueIndex"`
+	Description string     `gorm:"size:255"`
+	Scopes      string     `gorm:"size:1024;not null"` // Permission codes separated by spaces
+	KeyPrefix   string     `gorm:"size:16;not null"`   // Shown to admins to tell keys apart
+	KeyHash     string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt   *time.Time // Nil when the key never expires
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	AuditFields
+}
+
+func (ServiceAccount) TableName() string {
+	return "astro_cat_service_account"
+}
@@ -35,6 +35,13 @@ type Session struct {
 	
 	CommunityServiceId *uuid.UUID       `gorm:"type:uuid"`
 	CommunityService   *CommunityService `gorm:"foreignKey:CommunityServiceId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
+	// Series the session is an occurrence of, and the day it was generated for even if it moved
+	SeriesId       *uuid.UUID     `gorm:"type:uuid;uniqueIndex:idx_session_series_occurrence"`
+	Series         *SessionSeries `gorm:"foreignKey:SeriesId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	OccurrenceDate *time.Time     `gorm:"type:date;uniqueIndex:idx_session_series_occurrence"`
+	// Edited on its own, so edits of the whole series leave it as it is
+	Detached bool
 }
 
 func (Session) TableName() string {
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Weekly class whose sessions are generated from a recurrence rule over a rolling horizon. Its
+// sessions point back to it together with the day they were generated for.
+type SessionSeries struct {
+	Id    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Title string
+	// Day of the first occurrence in the booking time zone
+	StartDate time.Time `gorm:"type:date"`
+	// Time of day the sessions start at in the booking time zone, as HH:MM
+	StartTime       string `gorm:"size:5"`
+	DurationMinutes int
+	Capacity        int
+	SessionLink     *string
+	// RRULE value of RFC 5545, e.g. FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10
+	Rule string
+	// EXDATE value of RFC 5545: days without a session, as YYYYMMDD separated by commas
+	ExceptionDates string
+	// Sessions have been generated for the days before this one
+	GeneratedUntil time.Time `gorm:"type:date;index"`
+	AuditFields
+
+	ProfessionalId     uuid.UUID         `gorm:"type:uuid"`
+	Professional       Professional      `gorm:"foreignKey:ProfessionalId"`
+	LocalId            *uuid.UUID        `gorm:"type:uuid"`
+	Local              *Local            `gorm:"foreignKey:LocalId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CommunityServiceId *uuid.UUID        `gorm:"type:uuid"`
+	CommunityService   *CommunityService `gorm:"foreignKey:CommunityServiceId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+func (SessionSeries) TableName() string {
+	return "astro_cat_session_series"
+}
@@ -0,0 +1,58 @@
+package model
+
+import "github.com/google/uuid"
+
+type StandingBookingAttemptStatus string
+
+const (
+	StandingBookingAttemptPending StandingBookingAttemptStatus = "PENDING"
+	StandingBookingAttemptBooked  StandingBookingAttemptStatus = "BOOKED"
+	StandingBookingAttemptSkipped StandingBookingAttemptStatus = "SKIPPED"
+)
+
+// Recurring pattern a user is subscribed to. Every session of the service led by the professional
+// on the weekday and at the time of the pattern is booked for the user.
+type StandingBooking struct {
+	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+	// Day of the week of the sessions, 0 being Sunday as in time.Weekday
+	Weekday int
+	// Time of day the sessions start at in the booking time zone, as HH:MM
+	StartTime string `gorm:"size:5"`
+	Active    bool
+	AuditFields
+
+	UserId         uuid.UUID    `gorm:"type:uuid;not null;index"`
+	User           User         `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ServiceId      uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Service        Service      `gorm:"foreignKey:ServiceId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ProfessionalId uuid.UUID    `gorm:"type:uuid;not null"`
+	Professional   Professional `gorm:"foreignKey:ProfessionalId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// Membership to book with, otherwise the best eligible one at each booking
+	MembershipId *uuid.UUID  `gorm:"type:uuid"`
+	Membership   *Membership `gorm:"foreignKey:MembershipId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+func (StandingBooking) TableName() string {
+	return "astro_cat_standing_booking"
+}
+
+// Outcome of booking one session for a standing booking. Each session is only tried once per
+// standing booking, and a skipped booking keeps the code and message of the reason.
+type StandingBookingAttempt struct {
+	Id     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Status StandingBookingAttemptStatus
+	Reason *string
+	Detail *string
+	AuditFields
+
+	StandingBookingId uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_standing_booking_attempt_session"`
+	StandingBooking   StandingBooking `gorm:"foreignKey:StandingBookingId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SessionId         uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_standing_booking_attempt_session"`
+	Session           Session         `gorm:"foreignKey:SessionId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ReservationId     *uuid.UUID      `gorm:"type:uuid"`
+	Reservation       *Reservation    `gorm:"foreignKey:ReservationId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+func (StandingBookingAttempt) TableName() string {
+	return "astro_cat_standing_booking_attempt"
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TwoFactorRecoveryCode struct {
+	Id       uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	CodeHash string     `gorm:"size:64;not null;index"`
+	UsedAt   *time.Time // Each recovery code can only be used once
+	AuditFields
+
+	UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+	User   User      `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (TwoFactorRecoveryCode) TableName() string {
+	return "astro_cat_two_factor_recovery_code"
+}
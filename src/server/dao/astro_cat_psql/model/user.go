@@ -1,24 +1,40 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UserRol string
 
 const (
-	UserRolAdmin  UserRol = "ADMINISTRATOR"
-	UserRolClient UserRol = "CLIENT"
-	UserRolGuest  UserRol = "GUEST"
+	UserRolAdmin            UserRol = "ADMINISTRATOR"
+	UserRolClient           UserRol = "CLIENT"
+	UserRolGuest            UserRol = "GUEST"
+	UserRolProfessional     UserRol = "PROFESSIONAL"
+	UserRolCommunityManager UserRol = "COMMUNITY_MANAGER" // Only manages the communities assigned to them
+	UserRolFrontDesk        UserRol = "FRONT_DESK"
+	UserRolServiceAccount   UserRol = "SERVICE_ACCOUNT" // Only attributes the actions of service accounts in audit logs
 )
 
 type User struct {
-	Id             uuid.UUID `gorm:"type:uuid;primaryKey"`
-	Name           string
-	FirstLastName  string
-	SecondLastName *string
-	Password       string
-	Email          string
-	Rol            UserRol
-	ImageUrl       string
+	Id                  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name                string
+	FirstLastName       string
+	SecondLastName      *string
+	Password            string
+	Email               string
+	Rol                 UserRol
+	ImageUrl            string
+	TokenVersion        int        `gorm:"not null;default:0"` // Bumped to invalidate every token issued before
+	EmailVerifiedAt     *time.Time // Pointer to allow NULL values, NULL while the email is unverified
+	FailedLoginAttempts int        `gorm:"not null;default:0"` // Consecutive failed logins, reset on success
+	LockedUntil         *time.Time // Logins are rejected until this time
+	TwoFactorSecret     *string    `gorm:"size:64"` // Base32 TOTP secret, set on enrolment
+	TwoFactorEnabledAt  *time.Time // NULL while the enrolment is not confirmed
+	TwoFactorLastStep   int64      `gorm:"not null;default:0"` // Last TOTP time step used, to reject replays
+	ErasedAt            *time.Time // Set once the personal data of the user is anonymized
 	AuditFields
 
 	Onboarding  *Onboarding   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
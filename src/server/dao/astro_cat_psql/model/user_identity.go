@@ -0,0 +1,19 @@
+package model
+
+import "github.com/google/uuid"
+
+// External identity of a user at an OpenID Connect provider. A user may link one identity per
+// provider and an identity belongs to a single user.
+type UserIdentity struct {
+	Id       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserId   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_identity_user_provider"`
+	User     User      `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Provider string    `gorm:"size:50;not null;uniqueIndex:idx_user_identity_user_provider;uniqueIndex:idx_user_identity_provider_subject"`
+	Subject  string    `gorm:"size:255;not null;uniqueIndex:idx_user_identity_provider_subject"` // sub claim of the ID token
+	Email    string    `gorm:"size:255"`                                                         // Email reported by the provider when linked
+	AuditFields
+}
+
+func (UserIdentity) TableName() string {
+	return "astro_cat_user_identity"
+}
@@ -0,0 +1,20 @@
+package model
+
+import "github.com/google/uuid"
+
+// Grants a role to a user on top of their primary rol. Community scoped roles name the community
+// they apply to.
+type UserRoleAssignment struct {
+	Id          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserId      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User        User       `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	RoleId      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Role        Role       `gorm:"foreignKey:RoleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CommunityId *uuid.UUID `gorm:"type:uuid;index"` // NULL for roles that apply everywhere
+	Community   *Community `gorm:"foreignKey:CommunityId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	AuditFields
+}
+
+func (UserRoleAssignment) TableName() string {
+	return "astro_cat_user_role_assignment"
+}
@@ -0,0 +1,74 @@
+package factories
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+)
+
+type CancellationPolicyModelF struct {
+	Id                          *uuid.UUID
+	FreeCancellationHours       *int
+	LateCancellationBurnsCredit *bool
+	NoShowBurnsCredit           *bool
+	NoShowLimit                 *int
+	NoShowWindowDays            *int
+	CommunityId                 *uuid.UUID
+	ServiceId                   *uuid.UUID
+}
+
+// Create a new cancellation policy on DB
+func NewCancellationPolicyModel(
+	db *gorm.DB,
+	option ...CancellationPolicyModelF,
+) *model.CancellationPolicy {
+	policy := &model.CancellationPolicy{
+		Id:                          uuid.New(),
+		FreeCancellationHours:       24,
+		LateCancellationBurnsCredit: true,
+		NoShowBurnsCredit:           true,
+		AuditFields: model.AuditFields{
+			UpdatedBy: "ADMIN",
+		},
+	}
+
+	if len(option) > 0 {
+		parameters := option[0]
+		if parameters.Id != nil {
+			policy.Id = *parameters.Id
+		}
+		if parameters.FreeCancellationHours != nil {
+			policy.FreeCancellationHours = *parameters.FreeCancellationHours
+		}
+		if parameters.LateCancellationBurnsCredit != nil {
+			policy.LateCancellationBurnsCredit = *parameters.LateCancellationBurnsCredit
+		}
+		if parameters.NoShowBurnsCredit != nil {
+			policy.NoShowBurnsCredit = *parameters.NoShowBurnsCredit
+		}
+		if parameters.NoShowLimit != nil {
+			policy.NoShowLimit = *parameters.NoShowLimit
+		}
+		if parameters.NoShowWindowDays != nil {
+			policy.NoShowWindowDays = *parameters.NoShowWindowDays
+		}
+		if parameters.CommunityId != nil {
+			policy.CommunityId = *parameters.CommunityId
+		}
+		policy.ServiceId = parameters.ServiceId
+	}
+
+	// Create default community if not provided
+	if policy.CommunityId == uuid.Nil {
+		policy.CommunityId = NewCommunityModel(db).Id
+	}
+
+	result := db.Create(policy)
+	if result.Error != nil {
+		log.Fatalf("Error when trying to create cancellation policy: %v", result.Error)
+	}
+
+	return policy
+}
@@ -10,14 +10,15 @@ import (
 )
 
 type MembershipModelF struct {
-	Id          *uuid.UUID
-	Description *string
-	StartDate   *time.Time
-	EndDate     *time.Time
-	Status      *model.MembershipStatus
-	CommunityId *uuid.UUID
-	UserId      *uuid.UUID
-	PlanId      *uuid.UUID
+	Id               *uuid.UUID
+	Description      *string
+	StartDate        *time.Time
+	EndDate          *time.Time
+	Status           *model.MembershipStatus
+	ReservationsUsed *int
+	CommunityId      *uuid.UUID
+	UserId           *uuid.UUID
+	PlanId           *uuid.UUID
 }
 
 // Create a new membership on DB
@@ -65,6 +66,9 @@ func NewMembershipModel(db *gorm.DB, option ...MembershipModelF) *model.Membersh
 			if parameters.Status != nil {
 				membership.Status = *parameters.Status
 			}
+			if parameters.ReservationsUsed != nil {
+				membership.ReservationsUsed = parameters.ReservationsUsed
+			}
 			if parameters.CommunityId != nil {
 				membership.CommunityId = *parameters.CommunityId
 			}
@@ -29,7 +29,7 @@ func NewOnboardingModel(db *gorm.DB, option ...OnboardingModelF) *model.Onboardi
 	// Create default user if not provided
 	user := NewUserModel(db)
 
-	birthDate := time.Now().AddDate(-30, 0, 0) // 30 years ago
+	birthDate := time.Now().AddDate(-30, 0, 0).Format("2006-01-02") // 30 years ago
 	gender := model.GenderMale
 	district := "Test District"
 	province := "Test Province"
@@ -68,7 +68,8 @@ func NewOnboardingModel(db *gorm.DB, option ...OnboardingModelF) *model.Onboardi
 			onboarding.PhoneNumber = *parameters.PhoneNumber
 		}
 		if parameters.BirthDate != nil {
-			onboarding.BirthDate = parameters.BirthDate
+			formattedBirthDate := parameters.BirthDate.Format("2006-01-02")
+			onboarding.BirthDate = &formattedBirthDate
 		}
 		if parameters.Gender != nil {
 			onboarding.Gender = parameters.Gender
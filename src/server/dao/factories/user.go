@@ -2,6 +2,7 @@ package factories
 
 import (
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -10,14 +11,15 @@ import (
 )
 
 type UserModelF struct {
-	Id             *uuid.UUID
-	Name           *string
-	FirstLastName  *string
-	SecondLastName *string
-	Password       *string
-	Email          *string
-	Rol            *model.UserRol
-	ImageUrl       *string
+	Id              *uuid.UUID
+	Name            *string
+	FirstLastName   *string
+	SecondLastName  *string
+	Password        *string
+	Email           *string
+	Rol             *model.UserRol
+	ImageUrl        *string
+	EmailVerifiedAt *time.Time
 }
 
 // Create a new user on DB
@@ -72,6 +74,9 @@ func NewUserModel(db *gorm.DB, option ...UserModelF) *model.User {
 		if parameters.ImageUrl != nil {
 			user.ImageUrl = *parameters.ImageUrl
 		}
+		if parameters.EmailVerifiedAt != nil {
+			user.EmailVerifiedAt = parameters.EmailVerifiedAt
+		}
 	}
 
 	result := db.Create(user)
@@ -31,6 +31,19 @@ var (
 		SessionNotFound              Error
 		AuditLogNotFound             Error
 		MembershipSuspensionNotFound Error
+		RefreshTokenNotFound         Error
+		PasswordResetNotFound        Error
+		EmailVerificationNotFound    Error
+		RoleNotFound                 Error
+		UserRoleAssignmentNotFound   Error
+		ServiceAccountNotFound       Error
+		UserIdentityNotFound         Error
+		OidcProviderNotFound         Error
+		LoginSessionNotFound         Error
+		CancellationPolicyNotFound   Error
+		StandingBookingNotFound      Error
+		SessionSeriesNotFound        Error
+		ProfessionalTimeOffNotFound  Error
 	}{
 		CommunityNotFound: Error{
 			Code:    "COMMUNITY_ERROR_001",
@@ -92,6 +105,58 @@ var (
 			Code:    "MEMBERSHIP_SUSPENSION_ERROR_001",
 			Message: "Membership suspension not found",
 		},
+		RefreshTokenNotFound: Error{
+			Code:    "REFRESH_TOKEN_ERROR_001",
+			Message: "Refresh token not found",
+		},
+		PasswordResetNotFound: Error{
+			Code:    "PASSWORD_RESET_ERROR_001",
+			Message: "Password reset not found",
+		},
+		EmailVerificationNotFound: Error{
+			Code:    "EMAIL_VERIFICATION_ERROR_001",
+			Message: "Email verification not found",
+		},
+		RoleNotFound: Error{
+			Code:    "ROLE_ERROR_001",
+			Message: "Role not found",
+		},
+		UserRoleAssignmentNotFound: Error{
+			Code:    "ROLE_ASSIGNMENT_ERROR_001",
+			Message: "Role assignment not found",
+		},
+		ServiceAccountNotFound: Error{
+			Code:    "SERVICE_ACCOUNT_ERROR_001",
+			Message: "Service account not found",
+		},
+		UserIdentityNotFound: Error{
+			Code:    "USER_IDENTITY_ERROR_001",
+			Message: "User identity not found",
+		},
+		OidcProviderNotFound: Error{
+			Code:    "USER_IDENTITY_ERROR_002",
+			Message: "OpenID Connect provider not configured",
+		},
+		LoginSessionNotFound: Error{
+			Code:    "LOGIN_SESSION_ERROR_001",
+			Message: "Login session not found",
+		},
+		CancellationPolicyNotFound: Error{
+			Code:    "CANCELLATION_POLICY_ERROR_001",
+			Message: "Cancellation policy not found",
+		},
+		StandingBookingNotFound: Error{
+			Code:    "STANDING_BOOKING_ERROR_001",
+			Message: "Standing booking not found",
+		},
+		SessionSeriesNotFound: Error{
+			Code:    "SESSION_SERIES_ERROR_001",
+			Message: "Session series not found",
+		},
+		ProfessionalTimeOffNotFound: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_001",
+			Message: "Professional time off not found",
+		},
 	}
 
 	// For 422 Unprocessable Entity errors
@@ -114,6 +179,19 @@ var (
 		InvalidSessionId              Error
 		InvalidReservationId          Error
 		InvalidMembershipSuspensionId Error
+		InvalidRoleId                 Error
+		InvalidUserRoleAssignmentId   Error
+		InvalidServiceAccountId       Error
+		InvalidLoginSessionId         Error
+		InvalidDataExportFormat       Error
+		InvalidDocumentType           Error
+		InvalidCancellationPolicyId   Error
+		InvalidQrCodeFormat           Error
+		InvalidStandingBookingId      Error
+		InvalidIdempotencyKey         Error
+		IdempotencyKeyReused          Error
+		InvalidSessionSeriesId        Error
+		InvalidProfessionalTimeOffId  Error
 	}{
 		InvalidRequestBody: Error{
 			Code:    "REQUEST_ERROR_001",
@@ -187,53 +265,162 @@ var (
 			Code:    "MEMBERSHIP_SUSPENSION_ERROR_004",
 			Message: "Invalid membership suspension id",
 		},
+		InvalidRoleId: Error{
+			Code:    "ROLE_ERROR_002",
+			Message: "Invalid role id",
+		},
+		InvalidUserRoleAssignmentId: Error{
+			Code:    "ROLE_ASSIGNMENT_ERROR_002",
+			Message: "Invalid role assignment id",
+		},
+		InvalidServiceAccountId: Error{
+			Code:    "SERVICE_ACCOUNT_ERROR_002",
+			Message: "Invalid service account id",
+		},
+		InvalidLoginSessionId: Error{
+			Code:    "LOGIN_SESSION_ERROR_002",
+			Message: "Invalid login session id",
+		},
+		InvalidDataExportFormat: Error{
+			Code:    "USER_ERROR_010",
+			Message: "Invalid data export format, use json or zip",
+		},
+		InvalidDocumentType: Error{
+			Code:    "ONBOARDING_ERROR_004",
+			Message: "Invalid document type",
+		},
+		InvalidCancellationPolicyId: Error{
+			Code:    "CANCELLATION_POLICY_ERROR_002",
+			Message: "Invalid cancellation policy id",
+		},
+		InvalidQrCodeFormat: Error{
+			Code:    "RESERVATION_ERROR_012",
+			Message: "QR code format must be png or svg",
+		},
+		InvalidStandingBookingId: Error{
+			Code:    "STANDING_BOOKING_ERROR_002",
+			Message: "Invalid standing booking id",
+		},
+		InvalidIdempotencyKey: Error{
+			Code:    "IDEMPOTENCY_KEY_ERROR_001",
+			Message: "Idempotency key must have between 1 and 255 characters",
+		},
+		IdempotencyKeyReused: Error{
+			Code:    "IDEMPOTENCY_KEY_ERROR_002",
+			Message: "Idempotency key was already used with a different request",
+		},
+		InvalidSessionSeriesId: Error{
+			Code:    "SESSION_SERIES_ERROR_002",
+			Message: "Invalid session series id",
+		},
+		InvalidProfessionalTimeOffId: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_002",
+			Message: "Invalid professional time off id",
+		},
 	}
 
 	// For 400 Bad Request errors
 	BadRequestError = struct {
-		InvalidUpdatedByValue          Error
-		InvalidCommunityName           Error
-		InvalidServiceName             Error
-		DuplicateCommunityName         Error
-		DuplicateUserEmail             Error
-		CommunityNotCreated            Error
-		CommunityNotUpdated            Error
-		CommunityNotSoftDeleted        Error
-		LocalNotCreated                Error
-		LocalNotUpdated                Error
-		LocalNotSoftDeleted            Error
-		ProfessionalNotCreated         Error
-		ProfessionalNotUpdated         Error
-		ProfessionalNotSoftDeleted     Error
-		ServiceNotCreated              Error
-		ServiceNotUpdated              Error
-		ServiceNotSoftDeleted          Error
-		PlanNotCreated                 Error
-		PlanNotUpdated                 Error
-		PlanNotSoftDeleted             Error
-		InvalidPlanType                Error
-		MembershipNotCreated           Error
-		MembershipNotUpdated           Error
-		MembershipNotDeleted           Error
-		OnboardingNotCreated           Error
-		OnboardingNotUpdated           Error
-		UserNotCreated                 Error
-		UserNotUpdated                 Error
-		UserNotSoftDeleted             Error
-		UserPasswordNotUpdated         Error
-		CommunityPlanNotCreated        Error
-		CommunityPlanNotDeleted        Error
-		CommunityServiceNotCreated     Error
-		CommunityServiceNotDeleted     Error
-		ServiceLocalNotCreated         Error
-		ServiceLocalNotDeleted         Error
-		ServiceProfessionalNotCreated  Error
-		ServiceProfessionalNotDeleted  Error
-		SessionNotCreated              Error
-		SessionNotUpdated              Error
-		SessionNotSoftDeleted          Error
-		MembershipSuspensionNotCreated Error
-		MembershipSuspensionNotUpdated Error
+		InvalidUpdatedByValue           Error
+		InvalidCommunityName            Error
+		InvalidServiceName              Error
+		DuplicateCommunityName          Error
+		DuplicateUserEmail              Error
+		CommunityNotCreated             Error
+		CommunityNotUpdated             Error
+		CommunityNotSoftDeleted         Error
+		LocalNotCreated                 Error
+		LocalNotUpdated                 Error
+		LocalNotSoftDeleted             Error
+		ProfessionalNotCreated          Error
+		ProfessionalNotUpdated          Error
+		ProfessionalNotSoftDeleted      Error
+		ServiceNotCreated               Error
+		ServiceNotUpdated               Error
+		ServiceNotSoftDeleted           Error
+		PlanNotCreated                  Error
+		PlanNotUpdated                  Error
+		PlanNotSoftDeleted              Error
+		InvalidPlanType                 Error
+		MembershipNotCreated            Error
+		MembershipNotUpdated            Error
+		MembershipNotDeleted            Error
+		OnboardingNotCreated            Error
+		OnboardingNotUpdated            Error
+		UserNotCreated                  Error
+		UserNotUpdated                  Error
+		UserNotSoftDeleted              Error
+		UserPasswordNotUpdated          Error
+		CommunityPlanNotCreated         Error
+		CommunityPlanNotDeleted         Error
+		CommunityServiceNotCreated      Error
+		CommunityServiceNotDeleted      Error
+		ServiceLocalNotCreated          Error
+		ServiceLocalNotDeleted          Error
+		ServiceProfessionalNotCreated   Error
+		ServiceProfessionalNotDeleted   Error
+		SessionNotCreated               Error
+		SessionNotUpdated               Error
+		SessionNotSoftDeleted           Error
+		MembershipSuspensionNotCreated  Error
+		MembershipSuspensionNotUpdated  Error
+		RefreshTokenNotCreated          Error
+		RefreshTokenNotRevoked          Error
+		PasswordResetNotCreated         Error
+		PasswordResetNotUpdated         Error
+		EmailVerificationNotCreated     Error
+		EmailVerificationNotUpdated     Error
+		TwoFactorRecoveryCodeNotCreated Error
+		TwoFactorRecoveryCodeNotUpdated Error
+		InvalidPermission               Error
+		RoleNotUpdated                  Error
+		UserRoleAssignmentNotCreated    Error
+		UserRoleAssignmentNotDeleted    Error
+		CommunityRequiredForRole        Error
+		CommunityNotAllowedForRole      Error
+		AdministratorRoleNotEditable    Error
+		ServiceAccountNotCreated        Error
+		ServiceAccountNotUpdated        Error
+		InvalidServiceAccountScope      Error
+		InvalidServiceAccountExpiration Error
+		UserIdentityNotCreated          Error
+		UserIdentityNotDeleted          Error
+		LastLoginMethod                 Error
+		LoginSessionNotCreated          Error
+		LoginSessionNotRevoked          Error
+		UserDataNotErased               Error
+		ReservationNotWaitlisted        Error
+		CancellationPolicyNotCreated    Error
+		CancellationPolicyNotUpdated    Error
+		CancellationPolicyNotDeleted    Error
+		InvalidCancellationPolicy       Error
+		InvalidCheckInToken             Error
+		ReservationNotConfirmed         Error
+		CheckInClosed                   Error
+		StandingBookingNotCreated       Error
+		StandingBookingNotUpdated       Error
+		StandingBookingNotDeleted       Error
+		InvalidStandingBookingPattern   Error
+		InvalidReservationTransition    Error
+		ReservationSessionStarted       Error
+		ReservationSessionEnded         Error
+		ReservationSessionNotEnded      Error
+		ReservationSessionCancelled     Error
+		SessionSeriesNotCreated         Error
+		SessionSeriesNotUpdated         Error
+		InvalidSessionSeriesRule        Error
+		InvalidSessionSeriesScope       Error
+		SessionNotInSeries              Error
+		SessionSeriesOccurrenceStarted  Error
+		InvalidSessionSeriesSchedule    Error
+		WorkingHoursNotUpdated          Error
+		InvalidWorkingHours             Error
+		ProfessionalTimeOffNotCreated   Error
+		ProfessionalTimeOffNotDeleted   Error
+		InvalidProfessionalTimeOff      Error
+		CommunityScopedRoleNotPrimary   Error
+		InvalidUserRol                  Error
+		InvalidReservationInitialState  Error
 	}{
 		InvalidUpdatedByValue: Error{
 			Code:    "BAD_REQUEST_ERROR_001",
@@ -407,6 +594,234 @@ var (
 			Code:    "MEMBERSHIP_SUSPension_ERROR_003",
 			Message: "Membership suspension not updated",
 		},
+		RefreshTokenNotCreated: Error{
+			Code:    "REFRESH_TOKEN_ERROR_002",
+			Message: "Refresh token not created",
+		},
+		RefreshTokenNotRevoked: Error{
+			Code:    "REFRESH_TOKEN_ERROR_003",
+			Message: "Refresh token not revoked",
+		},
+		PasswordResetNotCreated: Error{
+			Code:    "PASSWORD_RESET_ERROR_002",
+			Message: "Password reset not created",
+		},
+		PasswordResetNotUpdated: Error{
+			Code:    "PASSWORD_RESET_ERROR_003",
+			Message: "Password reset not updated",
+		},
+		EmailVerificationNotCreated: Error{
+			Code:    "EMAIL_VERIFICATION_ERROR_002",
+			Message: "Email verification not created",
+		},
+		EmailVerificationNotUpdated: Error{
+			Code:    "EMAIL_VERIFICATION_ERROR_003",
+			Message: "Email verification not updated",
+		},
+		TwoFactorRecoveryCodeNotCreated: Error{
+			Code:    "TWO_FACTOR_ERROR_001",
+			Message: "Two-factor recovery codes not created",
+		},
+		TwoFactorRecoveryCodeNotUpdated: Error{
+			Code:    "TWO_FACTOR_ERROR_002",
+			Message: "Two-factor recovery code not updated",
+		},
+		InvalidPermission: Error{
+			Code:    "ROLE_ERROR_003",
+			Message: "Unknown permission code",
+		},
+		RoleNotUpdated: Error{
+			Code:    "ROLE_ERROR_004",
+			Message: "Role not updated",
+		},
+		UserRoleAssignmentNotCreated: Error{
+			Code:    "ROLE_ASSIGNMENT_ERROR_003",
+			Message: "Role assignment not created",
+		},
+		UserRoleAssignmentNotDeleted: Error{
+			Code:    "ROLE_ASSIGNMENT_ERROR_004",
+			Message: "Role assignment not deleted",
+		},
+		CommunityRequiredForRole: Error{
+			Code:    "ROLE_ASSIGNMENT_ERROR_005",
+			Message: "This role must be assigned for a community",
+		},
+		CommunityNotAllowedForRole: Error{
+			Code:    "ROLE_ASSIGNMENT_ERROR_006",
+			Message: "This role can not be limited to a community",
+		},
+		AdministratorRoleNotEditable: Error{
+			Code:    "ROLE_ERROR_005",
+			Message: "The administrator role always has every permission",
+		},
+		ServiceAccountNotCreated: Error{
+			Code:    "SERVICE_ACCOUNT_ERROR_003",
+			Message: "The service account was not created",
+		},
+		ServiceAccountNotUpdated: Error{
+			Code:    "SERVICE_ACCOUNT_ERROR_004",
+			Message: "The service account was not updated",
+		},
+		InvalidServiceAccountScope: Error{
+			Code:    "SERVICE_ACCOUNT_ERROR_005",
+			Message: "Scopes must be known permission codes",
+		},
+		InvalidServiceAccountExpiration: Error{
+			Code:    "SERVICE_ACCOUNT_ERROR_006",
+			Message: "The expiration of the API key must be in the future",
+		},
+		UserIdentityNotCreated: Error{
+			Code:    "USER_IDENTITY_ERROR_003",
+			Message: "Failed to link the identity",
+		},
+		UserIdentityNotDeleted: Error{
+			Code:    "USER_IDENTITY_ERROR_004",
+			Message: "Failed to unlink the identity",
+		},
+		LastLoginMethod: Error{
+			Code:    "USER_IDENTITY_ERROR_005",
+			Message: "The last login method of an account without password can not be unlinked",
+		},
+		LoginSessionNotCreated: Error{
+			Code:    "LOGIN_SESSION_ERROR_003",
+			Message: "Failed to create the login session",
+		},
+		LoginSessionNotRevoked: Error{
+			Code:    "LOGIN_SESSION_ERROR_004",
+			Message: "Failed to revoke the login session",
+		},
+		UserDataNotErased: Error{
+			Code:    "USER_ERROR_009",
+			Message: "The personal data of the user could not be erased",
+		},
+		ReservationNotWaitlisted: Error{
+			Code:    "RESERVATION_ERROR_006",
+			Message: "The reservation is not on the waitlist",
+		},
+		CancellationPolicyNotCreated: Error{
+			Code:    "CANCELLATION_POLICY_ERROR_003",
+			Message: "Cancellation policy not created",
+		},
+		CancellationPolicyNotUpdated: Error{
+			Code:    "CANCELLATION_POLICY_ERROR_004",
+			Message: "Cancellation policy not updated",
+		},
+		CancellationPolicyNotDeleted: Error{
+			Code:    "CANCELLATION_POLICY_ERROR_005",
+			Message: "Cancellation policy not deleted",
+		},
+		InvalidCancellationPolicy: Error{
+			Code:    "CANCELLATION_POLICY_ERROR_006",
+			Message: "Cancellation policy hours, days and limits cannot be negative",
+		},
+		InvalidCheckInToken: Error{
+			Code:    "RESERVATION_ERROR_008",
+			Message: "Invalid or expired check-in code",
+		},
+		ReservationNotConfirmed: Error{
+			Code:    "RESERVATION_ERROR_010",
+			Message: "Only confirmed reservations can be checked in",
+		},
+		CheckInClosed: Error{
+			Code:    "RESERVATION_ERROR_011",
+			Message: "Check-in is closed for this session",
+		},
+		StandingBookingNotCreated: Error{
+			Code:    "STANDING_BOOKING_ERROR_003",
+			Message: "Standing booking not created",
+		},
+		StandingBookingNotUpdated: Error{
+			Code:    "STANDING_BOOKING_ERROR_004",
+			Message: "Standing booking not updated",
+		},
+		StandingBookingNotDeleted: Error{
+			Code:    "STANDING_BOOKING_ERROR_005",
+			Message: "Standing booking not deleted",
+		},
+		InvalidStandingBookingPattern: Error{
+			Code:    "STANDING_BOOKING_ERROR_006",
+			Message: "Weekday must be between 0 (Sunday) and 6 and start time must be HH:MM",
+		},
+		InvalidReservationTransition: Error{
+			Code:    "RESERVATION_ERROR_013",
+			Message: "The reservation cannot change from its current state to the requested one",
+		},
+		ReservationSessionStarted: Error{
+			Code:    "RESERVATION_ERROR_014",
+			Message: "The session of the reservation already started",
+		},
+		ReservationSessionEnded: Error{
+			Code:    "RESERVATION_ERROR_015",
+			Message: "The session of the reservation already ended",
+		},
+		ReservationSessionNotEnded: Error{
+			Code:    "RESERVATION_ERROR_016",
+			Message: "The session of the reservation has not ended yet",
+		},
+		ReservationSessionCancelled: Error{
+			Code:    "RESERVATION_ERROR_017",
+			Message: "The session of the reservation was cancelled",
+		},
+		SessionSeriesNotCreated: Error{
+			Code:    "SESSION_SERIES_ERROR_003",
+			Message: "Session series not created",
+		},
+		SessionSeriesNotUpdated: Error{
+			Code:    "SESSION_SERIES_ERROR_004",
+			Message: "Session series not updated",
+		},
+		InvalidSessionSeriesRule: Error{
+			Code:    "SESSION_SERIES_ERROR_005",
+			Message: "Invalid recurrence of the session series, only weekly rules with BYDAY and UNTIL or COUNT are supported",
+		},
+		InvalidSessionSeriesScope: Error{
+			Code:    "SESSION_SERIES_ERROR_006",
+			Message: "Invalid scope, it must be THIS, THIS_AND_FOLLOWING or ALL",
+		},
+		SessionNotInSeries: Error{
+			Code:    "SESSION_SERIES_ERROR_007",
+			Message: "The session is not an occurrence of the series",
+		},
+		SessionSeriesOccurrenceStarted: Error{
+			Code:    "SESSION_SERIES_ERROR_008",
+			Message: "The occurrence has already started",
+		},
+		InvalidSessionSeriesSchedule: Error{
+			Code:    "SESSION_SERIES_ERROR_009",
+			Message: "Invalid start date, start time or duration of the session series",
+		},
+		WorkingHoursNotUpdated: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_003",
+			Message: "Working hours not updated",
+		},
+		InvalidWorkingHours: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_004",
+			Message: "Working hours must have a weekday from 0 to 6 and HH:MM times with the start before the end, without overlapping",
+		},
+		ProfessionalTimeOffNotCreated: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_005",
+			Message: "Professional time off not created",
+		},
+		ProfessionalTimeOffNotDeleted: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_006",
+			Message: "Professional time off not deleted",
+		},
+		InvalidProfessionalTimeOff: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_007",
+			Message: "Time off must end after it starts",
+		},
+		CommunityScopedRoleNotPrimary: Error{
+			Code:    "ROLE_ERROR_006",
+			Message: "A community scoped role can only be granted through a role assignment",
+		},
+		InvalidUserRol: Error{
+			Code:    "USER_ERROR_011",
+			Message: "Invalid user rol",
+		},
+		InvalidReservationInitialState: Error{
+			Code:    "RESERVATION_ERROR_020",
+			Message: "A reservation can only be created as CONFIRMED, ask to join the waitlist to wait for a spot",
+		},
 	}
 
 	ContactError = struct {
@@ -430,9 +845,15 @@ var (
 
 	// For 401 Unauthorized errors
 	AuthenticationError = struct {
-		UnauthorizedUser    Error
-		InvalidRefreshToken Error
-		InvalidAccessToken  Error
+		UnauthorizedUser            Error
+		InvalidRefreshToken         Error
+		InvalidAccessToken          Error
+		ExpiredRefreshToken         Error
+		RefreshTokenReused          Error
+		InvalidTwoFactorChallenge   Error
+		InvalidApiKey               Error
+		InvalidIdToken              Error
+		InvalidPasswordConfirmation Error
 	}{
 		UnauthorizedUser: Error{
 			Code:    "AUTHENTICATION_ERROR_001",
@@ -446,16 +867,100 @@ var (
 			Code:    "AUTHENTICATION_ERROR_003",
 			Message: "Invalid access token",
 		},
+		ExpiredRefreshToken: Error{
+			Code:    "AUTHENTICATION_ERROR_004",
+			Message: "Refresh token has expired",
+		},
+		RefreshTokenReused: Error{
+			Code:    "AUTHENTICATION_ERROR_005",
+			Message: "Refresh token reuse detected, please log in again",
+		},
+		InvalidTwoFactorChallenge: Error{
+			Code:    "AUTHENTICATION_ERROR_006",
+			Message: "Invalid or expired two-factor challenge, please log in again",
+		},
+		InvalidApiKey: Error{
+			Code:    "AUTHENTICATION_ERROR_007",
+			Message: "Invalid, expired or revoked API key",
+		},
+		InvalidIdToken: Error{
+			Code:    "AUTHENTICATION_ERROR_008",
+			Message: "Invalid or expired ID token",
+		},
+		InvalidPasswordConfirmation: Error{
+			Code:    "AUTHENTICATION_ERROR_009",
+			Message: "The password confirmation does not match",
+		},
 	}
 
 	// For 403 Forbidden errors
 	ForbiddenError = struct {
-		InsufficientPrivileges Error
+		InsufficientPrivileges            Error
+		EmailNotVerified                  Error
+		TwoFactorRequired                 Error
+		ResourceNotOwned                  Error
+		UnverifiedIdentityEmail           Error
+		MembershipNotActive               Error
+		MembershipOfAnotherUser           Error
+		MembershipCommunityMismatch       Error
+		MembershipOutOfPeriod             Error
+		MembershipReservationLimitReached Error
+		NoEligibleMembership              Error
+		BookingSuspendedForNoShows        Error
+		ReservationStateStaffOnly         Error
 	}{
 		InsufficientPrivileges: Error{
 			Code:    "FORBIDDEN_ERROR_001",
 			Message: "Insufficient privileges to access this resource",
 		},
+		EmailNotVerified: Error{
+			Code:    "FORBIDDEN_ERROR_002",
+			Message: "The email of the account must be verified first",
+		},
+		TwoFactorRequired: Error{
+			Code:    "FORBIDDEN_ERROR_003",
+			Message: "Two-factor authentication is mandatory for this account",
+		},
+		ResourceNotOwned: Error{
+			Code:    "FORBIDDEN_ERROR_004",
+			Message: "The resource belongs to another user",
+		},
+		UnverifiedIdentityEmail: Error{
+			Code:    "FORBIDDEN_ERROR_005",
+			Message: "The provider did not verify the email, log in and link the identity from your account",
+		},
+		MembershipNotActive: Error{
+			Code:    "MEMBERSHIP_ERROR_006",
+			Message: "The membership is not active",
+		},
+		MembershipOfAnotherUser: Error{
+			Code:    "MEMBERSHIP_ERROR_007",
+			Message: "The membership belongs to another user",
+		},
+		MembershipCommunityMismatch: Error{
+			Code:    "MEMBERSHIP_ERROR_008",
+			Message: "The membership does not cover the community of the session",
+		},
+		MembershipOutOfPeriod: Error{
+			Code:    "MEMBERSHIP_ERROR_009",
+			Message: "The session is outside the period of the membership",
+		},
+		MembershipReservationLimitReached: Error{
+			Code:    "MEMBERSHIP_ERROR_010",
+			Message: "The membership has no reservations left",
+		},
+		NoEligibleMembership: Error{
+			Code:    "MEMBERSHIP_ERROR_011",
+			Message: "No membership of the user covers this session",
+		},
+		BookingSuspendedForNoShows: Error{
+			Code:    "RESERVATION_ERROR_007",
+			Message: "Bookings are suspended for a while after too many missed sessions",
+		},
+		ReservationStateStaffOnly: Error{
+			Code:    "RESERVATION_ERROR_018",
+			Message: "Only staff can move a reservation to this state",
+		},
 	}
 
 	// For 409 Conflict errors
@@ -467,6 +972,20 @@ var (
 		UserAlreadyExists                Error
 		SessionTimeConflict              Error
 		UserReservationTimeConflict      Error
+		UserRoleAssignmentAlreadyExists  Error
+		ServiceAccountAlreadyExists      Error
+		UserIdentityAlreadyLinked        Error
+		UserProviderAlreadyLinked        Error
+		UserAlreadyErased                Error
+		SessionFull                      Error
+		CancellationPolicyAlreadyExists  Error
+		ReservationAlreadyCheckedIn      Error
+		StandingBookingAlreadyExists     Error
+		SessionAlreadyBooked             Error
+		IdempotencyKeyInProgress         Error
+		ProfessionalOutsideWorkingHours  Error
+		ProfessionalOnTimeOff            Error
+		SessionHasWaitlist               Error
 	}{
 		CommunityPlanAlreadyExists: Error{
 			Code:    "COMMUNITY_PLAN_ERROR_006",
@@ -496,6 +1015,62 @@ var (
 			Code:    "CONFLICT_ERROR_002",
 			Message: "User has another reservation at the same time",
 		},
+		UserRoleAssignmentAlreadyExists: Error{
+			Code:    "ROLE_ASSIGNMENT_ERROR_007",
+			Message: "User already has this role",
+		},
+		ServiceAccountAlreadyExists: Error{
+			Code:    "SERVICE_ACCOUNT_ERROR_007",
+			Message: "A service account with this name already exists",
+		},
+		UserIdentityAlreadyLinked: Error{
+			Code:    "USER_IDENTITY_ERROR_006",
+			Message: "The identity is already linked to an account",
+		},
+		UserProviderAlreadyLinked: Error{
+			Code:    "USER_IDENTITY_ERROR_007",
+			Message: "The account already has an identity of this provider",
+		},
+		UserAlreadyErased: Error{
+			Code:    "USER_ERROR_008",
+			Message: "The personal data of the user was already erased",
+		},
+		SessionFull: Error{
+			Code:    "RESERVATION_ERROR_005",
+			Message: "The session has no spots left",
+		},
+		CancellationPolicyAlreadyExists: Error{
+			Code:    "CANCELLATION_POLICY_ERROR_007",
+			Message: "The community or service already has a cancellation policy",
+		},
+		ReservationAlreadyCheckedIn: Error{
+			Code:    "RESERVATION_ERROR_009",
+			Message: "Reservation already checked in",
+		},
+		StandingBookingAlreadyExists: Error{
+			Code:    "STANDING_BOOKING_ERROR_007",
+			Message: "The user is already subscribed to this pattern",
+		},
+		SessionAlreadyBooked: Error{
+			Code:    "STANDING_BOOKING_ERROR_008",
+			Message: "The user already has a reservation for this session",
+		},
+		IdempotencyKeyInProgress: Error{
+			Code:    "IDEMPOTENCY_KEY_ERROR_003",
+			Message: "A request with this idempotency key is still in progress",
+		},
+		ProfessionalOutsideWorkingHours: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_008",
+			Message: "Session is outside the working hours of the professional",
+		},
+		ProfessionalOnTimeOff: Error{
+			Code:    "PROFESSIONAL_SCHEDULE_ERROR_009",
+			Message: "Professional is on time off during the session",
+		},
+		SessionHasWaitlist: Error{
+			Code:    "RESERVATION_ERROR_019",
+			Message: "The session has a waitlist, a cancelled reservation can not take a spot ahead of it",
+		},
 	}
 
 	// For 500 Internal Server errors
@@ -504,6 +1079,8 @@ var (
 		FailedToUploadImage   Error
 		FailedToDownloadImage Error
 		DatabaseError         Error
+		PiiEncryptionFailed   Error
+		PiiDecryptionFailed   Error
 	}{
 		Default: Error{
 			Code:    "INTERNAL_SERVER_ERROR_001",
@@ -521,6 +1098,14 @@ var (
 			Code:    "INTERNAL_SERVER_ERROR_004",
 			Message: "Database error",
 		},
+		PiiEncryptionFailed: Error{
+			Code:    "INTERNAL_SERVER_ERROR_005",
+			Message: "Failed to encrypt personal data",
+		},
+		PiiDecryptionFailed: Error{
+			Code:    "INTERNAL_SERVER_ERROR_006",
+			Message: "Failed to decrypt personal data",
+		},
 	}
 
 	// For forgot password or recovery flows
@@ -529,6 +1114,7 @@ var (
 		FailedToSendEmail   Error
 		InvalidOrExpiredPin Error
 		FailedToSendSMS     Error
+		MissingPhoneNumber  Error
 	}{
 		InvalidEmail: Error{
 			Code:    "FORGOT_PASSWORD_ERROR_001",
@@ -546,6 +1132,70 @@ var (
 			Code:    "FORGOT_PASSWORD_ERROR_004",
 			Message: "Failed to send SMS with recovery PIN",
 		},
+		MissingPhoneNumber: Error{
+			Code:    "FORGOT_PASSWORD_ERROR_005",
+			Message: "No phone number registered to send the recovery PIN",
+		},
+	}
+
+	// For email verification flows
+	EmailVerificationError = struct {
+		InvalidOrExpiredCode Error
+		EmailAlreadyVerified Error
+	}{
+		InvalidOrExpiredCode: Error{
+			Code:    "EMAIL_VERIFICATION_ERROR_004",
+			Message: "Invalid or expired verification code",
+		},
+		EmailAlreadyVerified: Error{
+			Code:    "EMAIL_VERIFICATION_ERROR_005",
+			Message: "Email is already verified",
+		},
+	}
+
+	// For two-factor authentication flows
+	TwoFactorError = struct {
+		InvalidTwoFactorCode    Error
+		TwoFactorNotEnrolled    Error
+		TwoFactorAlreadyEnabled Error
+	}{
+		InvalidTwoFactorCode: Error{
+			Code:    "TWO_FACTOR_ERROR_003",
+			Message: "Invalid two-factor code",
+		},
+		TwoFactorNotEnrolled: Error{
+			Code:    "TWO_FACTOR_ERROR_004",
+			Message: "Two-factor authentication has not been set up",
+		},
+		TwoFactorAlreadyEnabled: Error{
+			Code:    "TWO_FACTOR_ERROR_005",
+			Message: "Two-factor authentication is already enabled",
+		},
+	}
+
+	// For 429 Too Many Requests errors
+	TooManyRequestsError = struct {
+		PasswordResetLocked     Error
+		EmailVerificationLocked Error
+		RateLimitExceeded       Error
+		AccountLocked           Error
+	}{
+		PasswordResetLocked: Error{
+			Code:    "TOO_MANY_REQUESTS_ERROR_001",
+			Message: "Too many invalid PIN attempts, please request a new code",
+		},
+		EmailVerificationLocked: Error{
+			Code:    "TOO_MANY_REQUESTS_ERROR_002",
+			Message: "Too many invalid verification attempts, please request a new code",
+		},
+		RateLimitExceeded: Error{
+			Code:    "TOO_MANY_REQUESTS_ERROR_003",
+			Message: "Too many requests, please try again later",
+		},
+		AccountLocked: Error{
+			Code:    "TOO_MANY_REQUESTS_ERROR_004",
+			Message: "Account temporarily locked after too many failed login attempts",
+		},
 	}
 )
 
@@ -560,34 +1210,55 @@ func isInErrorGroup(err Error, group interface{}) bool {
 	return false
 }
 
-// General error handler function for endpoints.
-func HandleError(err Error, c echo.Context) error {
-	var statusCode int
+// Gets the HTTP status code an error is answered with.
+func GetStatusCode(err Error) int {
 	switch {
 	case isInErrorGroup(err, ObjectNotFoundError):
-		statusCode = http.StatusNotFound
+		return http.StatusNotFound
 
 	case isInErrorGroup(err, UnprocessableEntityError):
-		statusCode = http.StatusUnprocessableEntity
+		return http.StatusUnprocessableEntity
 
 	case isInErrorGroup(err, BadRequestError):
-		statusCode = http.StatusBadRequest
+		return http.StatusBadRequest
 
 	case isInErrorGroup(err, ConflictError):
-		statusCode = http.StatusConflict
+		return http.StatusConflict
 
 	case isInErrorGroup(err, InternalServerError):
-		statusCode = http.StatusInternalServerError
+		return http.StatusInternalServerError
 
 	case isInErrorGroup(err, AuthenticationError):
-		statusCode = http.StatusUnauthorized
+		return http.StatusUnauthorized
 
 	case isInErrorGroup(err, ForbiddenError):
-		statusCode = http.StatusForbidden
+		return http.StatusForbidden
+
+	case isInErrorGroup(err, ForgotPasswordError):
+		return http.StatusBadRequest
+
+	case isInErrorGroup(err, EmailVerificationError):
+		return http.StatusBadRequest
+
+	case isInErrorGroup(err, TwoFactorError):
+		return http.StatusBadRequest
+
+	case isInErrorGroup(err, TooManyRequestsError):
+		return http.StatusTooManyRequests
 
 	default:
-		statusCode = http.StatusInternalServerError // Default case for other errors
+		return http.StatusInternalServerError // Default case for other errors
 	}
+}
+
+// Whether an error is an unexpected failure instead of the rejection of a request.
+func IsInternalError(err Error) bool {
+	return GetStatusCode(err) >= http.StatusInternalServerError
+}
+
+// General error handler function for endpoints.
+func HandleError(err Error, c echo.Context) error {
+	statusCode := GetStatusCode(err)
 
 	// Send JSON response with the error code and message
 	return c.JSON(statusCode, err)
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+)
+
+// SessionLifecycleUpdater es un job en segundo plano que pasa las sesiones a
+// ONGOING cuando empiezan y a COMPLETED cuando terminan, y cierra las reservas
+// CONFIRMED de las sesiones completadas: pasan a DONE o, si se controla la
+// asistencia, a NO_SHOW las que nunca hicieron check-in. Se ejecuta cada 5
+// minutos.
+type SessionLifecycleUpdater struct {
+	cron               *cron.Cron
+	logger             logging.Logger
+	session            *controller.Session
+	reservation        *controller.Reservation
+	attendanceTracking bool
+}
+
+// NewSessionLifecycleUpdater crea la instancia y registra el job en el
+// scheduler, pero NO lo arranca; para eso hay que llamar Start().
+func NewSessionLifecycleUpdater(
+	logger logging.Logger,
+	session *controller.Session,
+	reservation *controller.Reservation,
+	attendanceTracking bool,
+) *SessionLifecycleUpdater {
+	c := cron.New()
+	updater := &SessionLifecycleUpdater{
+		cron:               c,
+		logger:             logger,
+		session:            session,
+		reservation:        reservation,
+		attendanceTracking: attendanceTracking,
+	}
+
+	// "*/5 * * * *"  ->  Cada 5 minutos
+	_, err := c.AddFunc("*/5 * * * *", updater.run)
+	if err != nil {
+		logger.Errorf("SessionLifecycleUpdater: error añadiendo cron job: %v", err)
+	}
+
+	return updater
+}
+
+// Start inicia el scheduler.
+func (u *SessionLifecycleUpdater) Start() {
+	u.logger.Infoln("SessionLifecycleUpdater: cron iniciado (cada 5 minutos)")
+	u.cron.Start()
+}
+
+// run avanza los estados. Cada cambio solo toca sesiones y reservas que siguen
+// en el estado anterior, así que varias instancias pueden correrlo a la vez.
+func (u *SessionLifecycleUpdater) run() {
+	now := time.Now()
+
+	sweep, err := u.session.AdvanceSessionStates(now)
+	if err != nil {
+		u.logger.Errorf("SessionLifecycleUpdater: fallo al actualizar sesiones: %v", err.Message)
+		return
+	}
+	if sweep.StartedSessions > 0 || sweep.CompletedSessions > 0 {
+		u.logger.Infof(
+			"SessionLifecycleUpdater: %d sesiones pasaron a ONGOING, %d a COMPLETED",
+			sweep.StartedSessions,
+			sweep.CompletedSessions,
+		)
+	}
+
+	// Con control de asistencia, quienes hicieron check-in ya están en DONE
+	if u.attendanceTracking {
+		noShows, err := u.reservation.MarkNoShows(now)
+		if err != nil {
+			u.logger.Errorf("SessionLifecycleUpdater: fallo al marcar inasistencias: %v", err.Message)
+			return
+		}
+		if len(noShows.Reservations) > 0 {
+			u.logger.Infof(
+				"SessionLifecycleUpdater: %d reservas pasaron a NO_SHOW (%d con penalidad)",
+				len(noShows.Reservations),
+				noShows.CreditsForfeited,
+			)
+		}
+		return
+	}
+
+	completed, err := u.reservation.CompleteReservations()
+	if err != nil {
+		u.logger.Errorf("SessionLifecycleUpdater: fallo al cerrar reservas: %v", err.Message)
+		return
+	}
+	if len(completed) > 0 {
+		u.logger.Infof("SessionLifecycleUpdater: %d reservas pasaron a DONE", len(completed))
+	}
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"github.com/robfig/cron/v3"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+)
+
+// SessionSeriesGenerator es un job en segundo plano que genera las sesiones de
+// las series recurrentes a medida que el horizonte avanza. Se ejecuta cada día
+// a las 00:30.
+type SessionSeriesGenerator struct {
+	cron          *cron.Cron
+	logger        logging.Logger
+	sessionSeries *controller.SessionSeries
+}
+
+// NewSessionSeriesGenerator crea la instancia y registra el job en el
+// scheduler, pero NO lo arranca; para eso hay que llamar Start().
+func NewSessionSeriesGenerator(
+	logger logging.Logger,
+	sessionSeries *controller.SessionSeries,
+) *SessionSeriesGenerator {
+	c := cron.New()
+	generator := &SessionSeriesGenerator{cron: c, logger: logger, sessionSeries: sessionSeries}
+
+	// "30 0 * * *"  ->  Todos los días a las 00:30
+	_, err := c.AddFunc("30 0 * * *", generator.run)
+	if err != nil {
+		logger.Errorf("SessionSeriesGenerator: error añadiendo cron job: %v", err)
+	}
+
+	return generator
+}
+
+// Start inicia el scheduler.
+func (g *SessionSeriesGenerator) Start() {
+	g.logger.Infoln("SessionSeriesGenerator: cron iniciado (diario a las 00:30)")
+	g.cron.Start()
+}
+
+// run genera las sesiones hasta el horizonte. Cada día de una serie tiene una
+// sola sesión, así que varias instancias pueden correrlo a la vez.
+func (g *SessionSeriesGenerator) run() {
+	reports, err := g.sessionSeries.GenerateUpcomingSessions()
+	if err != nil {
+		g.logger.Errorf("SessionSeriesGenerator: fallo al generar sesiones: %v", err.Message)
+		return
+	}
+
+	created, skipped := 0, 0
+	for _, report := range reports {
+		created += len(report.Sessions)
+		skipped += len(report.Skipped)
+	}
+
+	if created > 0 || skipped > 0 {
+		g.logger.Infof(
+			"SessionSeriesGenerator: %d sesiones generadas, %d omitidas por conflictos",
+			created,
+			skipped,
+		)
+	}
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"github.com/robfig/cron/v3"
+	"onichankimochi.com/astro_cat_backend/src/logging"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+)
+
+// StandingBookingScheduler es un job en segundo plano que reserva, para cada
+// reserva recurrente activa, las sesiones que entran en la ventana de reserva.
+// Se ejecuta cada hora.
+type StandingBookingScheduler struct {
+	cron            *cron.Cron
+	logger          logging.Logger
+	standingBooking *controller.StandingBooking
+}
+
+// NewStandingBookingScheduler crea la instancia y registra el job en el
+// scheduler, pero NO lo arranca; para eso hay que llamar Start().
+func NewStandingBookingScheduler(
+	logger logging.Logger,
+	standingBooking *controller.StandingBooking,
+) *StandingBookingScheduler {
+	c := cron.New()
+	scheduler := &StandingBookingScheduler{cron: c, logger: logger, standingBooking: standingBooking}
+
+	// "5 * * * *"  ->  Al minuto 5 de cada hora
+	_, err := c.AddFunc("5 * * * *", scheduler.run)
+	if err != nil {
+		logger.Errorf("StandingBookingScheduler: error añadiendo cron job: %v", err)
+	}
+
+	return scheduler
+}
+
+// Start inicia el scheduler.
+func (s *StandingBookingScheduler) Start() {
+	s.logger.Infoln("StandingBookingScheduler: cron iniciado (cada hora)")
+	s.cron.Start()
+}
+
+// run reserva las sesiones próximas. Cada sesión se intenta una sola vez por
+// reserva recurrente, así que varias instancias pueden correrlo a la vez.
+func (s *StandingBookingScheduler) run() {
+	report, err := s.standingBooking.BookUpcomingSessions()
+	if err != nil {
+		s.logger.Errorf("StandingBookingScheduler: fallo al reservar sesiones: %v", err.Message)
+		return
+	}
+
+	if len(report.Booked) > 0 || len(report.Skipped) > 0 {
+		s.logger.Infof(
+			"StandingBookingScheduler: %d sesiones reservadas, %d omitidas",
+			len(report.Booked),
+			len(report.Skipped),
+		)
+	}
+}
@@ -22,47 +22,68 @@ type RegisterRequest struct {
 }
 
 type LoginResponse struct {
-	User   UserProfile   `json:"user"`
-	Tokens TokenResponse `json:"tokens"`
+	User               UserProfile         `json:"user"`
+	Tokens             TokenResponse       `json:"tokens"`
+	TwoFactorChallenge *TwoFactorChallenge `json:"two_factor_challenge,omitempty"` // Set instead of tokens
+	RecoveryCodes      []string            `json:"recovery_codes,omitempty"`       // Only after enrolment
 }
 
 type UserProfile struct {
-	Id             uuid.UUID `json:"id"`
-	Name           string    `json:"name"`
-	FirstLastName  string    `json:"first_last_name"`
-	SecondLastName *string   `json:"second_last_name"`
-	Email          string    `json:"email"`
-	Rol            UserRol   `json:"rol"`
-	ImageUrl       string    `json:"image_url"`
+	Id                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	FirstLastName      string     `json:"first_last_name"`
+	SecondLastName     *string    `json:"second_last_name"`
+	Email              string     `json:"email"`
+	Rol                UserRol    `json:"rol"`
+	ImageUrl           string     `json:"image_url"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
 }
 
 type TokenResponse struct {
 	AccessToken  string        `json:"access_token"`
 	RefreshToken string        `json:"refresh_token"`
 	ExpiresIn    time.Duration `json:"expires_in"`
+	SessionId    uuid.UUID     `json:"session_id"` // Login session the tokens belong to
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Audience of the access tokens, which share their signing key with the challenge and check-in
+// tokens, so none of them is accepted in place of another
+const AccessTokenAudience = "access"
+
 type CustomClaims struct {
 	UserId        uuid.UUID `json:"user_id"`
 	UserEmail     string    `json:"user_email"`
-	UserPassword  string    `json:"user_password"`
 	UserRoles     []string  `json:"user_roles"`
+	TokenVersion  int       `json:"token_version"`
 	UserName      string    `json:"user_name"`
 	UserFirstName string    `json:"user_first_name"`
 	UserLastName  *string   `json:"user_last_name"`
 	UserImageUrl  string    `json:"user_image_url"`
+	SessionId     uuid.UUID `json:"session_id"` // Nil for tokens issued before login sessions
 	jwt.RegisteredClaims
 }
 
 type Credentials struct {
 	UserId        uuid.UUID `json:"user_id"`
 	UserEmail     string    `json:"user_email"`
-	UserPassword  string    `json:"user_password"`
 	UserRoles     []string  `json:"user_roles"`
 	UserName      string    `json:"user_name"`
 	UserFirstName string    `json:"user_first_name"`
 	UserLastName  *string   `json:"user_last_name"`
 	UserImageUrl  string    `json:"user_image_url"`
+	// Login session of the access token, nil for service accounts
+	SessionId *uuid.UUID `json:"session_id,omitempty"`
+	// Set when the caller is a service account instead of a user
+	ServiceAccountId *uuid.UUID `json:"service_account_id,omitempty"`
 }
 
 type GoogleLoginRequest struct {
@@ -70,6 +91,7 @@ type GoogleLoginRequest struct {
 }
 
 type GoogleLoginResponse struct {
-	User   User          `json:"user"`
-	Tokens TokenResponse `json:"tokens"`
+	User               User                `json:"user"`
+	Tokens             TokenResponse       `json:"tokens"`
+	TwoFactorChallenge *TwoFactorChallenge `json:"two_factor_challenge,omitempty"` // Set instead of tokens
 }
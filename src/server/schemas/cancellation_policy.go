@@ -0,0 +1,53 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CancellationPolicy struct {
+	Id                          uuid.UUID  `json:"id"`
+	FreeCancellationHours       int        `json:"free_cancellation_hours"`
+	LateCancellationBurnsCredit bool       `json:"late_cancellation_burns_credit"`
+	NoShowBurnsCredit           bool       `json:"no_show_burns_credit"`
+	NoShowLimit                 int        `json:"no_show_limit"`
+	NoShowWindowDays            int        `json:"no_show_window_days"`
+	CommunityId                 uuid.UUID  `json:"community_id"`
+	ServiceId                   *uuid.UUID `json:"service_id,omitempty"`
+}
+
+type CancellationPolicies struct {
+	CancellationPolicies []*CancellationPolicy `json:"cancellation_policies"`
+}
+
+type CreateCancellationPolicyRequest struct {
+	// Hours before the start of a session until which a cancellation gives the credit back
+	FreeCancellationHours       int  `json:"free_cancellation_hours"`
+	LateCancellationBurnsCredit bool `json:"late_cancellation_burns_credit"`
+	NoShowBurnsCredit           bool `json:"no_show_burns_credit"`
+	// No-shows within no_show_window_days after which the user cannot book, 0 disables the ban
+	NoShowLimit      int       `json:"no_show_limit"`
+	NoShowWindowDays int       `json:"no_show_window_days"`
+	CommunityId      uuid.UUID `json:"community_id" validate:"required"`
+	// Limits the policy to a service of the community
+	ServiceId *uuid.UUID `json:"service_id,omitempty"`
+}
+
+type UpdateCancellationPolicyRequest struct {
+	FreeCancellationHours       *int  `json:"free_cancellation_hours"`
+	LateCancellationBurnsCredit *bool `json:"late_cancellation_burns_credit"`
+	NoShowBurnsCredit           *bool `json:"no_show_burns_credit"`
+	NoShowLimit                 *int  `json:"no_show_limit"`
+	NoShowWindowDays            *int  `json:"no_show_window_days"`
+}
+
+// What cancelling a reservation right now would cost, shown to the user before they cancel
+type CancellationPreview struct {
+	ReservationId uuid.UUID           `json:"reservation_id"`
+	Policy        *CancellationPolicy `json:"policy,omitempty"`
+	// Until when the reservation can be cancelled without penalty, nil when always free
+	FreeCancellationUntil  *time.Time `json:"free_cancellation_until,omitempty"`
+	IsLateCancellation     bool       `json:"is_late_cancellation"`
+	CreditWouldBeForfeited bool       `json:"credit_would_be_forfeited"`
+}
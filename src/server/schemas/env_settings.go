@@ -1,8 +1,12 @@
 package schemas
 
 import (
+	"encoding/base64"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -33,7 +37,59 @@ type EnvSettings struct {
 	AstroCatPsqlSslMode      string
 
 	// JWT
-	TokenSignatureKey []byte
+	TokenSignatureKey      []byte
+	AccessTokenExpiration  time.Duration
+	RefreshTokenExpiration time.Duration
+
+	// Password reset
+	PasswordResetPinExpiration time.Duration
+	PasswordResetMaxAttempts   int
+
+	// Email verification
+	EmailVerificationCodeExpiration time.Duration
+	EmailVerificationMaxAttempts    int
+	RequireVerifiedEmail            bool // Blocks reservations and memberships for unverified accounts
+
+	// Reservations
+	RequireMembershipForBooking bool // Rejects bookings of community sessions without an eligible membership
+	CheckInTokenExpiration      time.Duration
+	AttendanceTrackingEnabled   bool           // Marks confirmed reservations never checked in as no-shows
+	BookingTimeZone             *time.Location // Zone of the weekdays and times of standing bookings, session series and working hours
+	StandingBookingWindowDays   int            // How many days ahead standing bookings book sessions
+
+	// Session series
+	SessionSeriesHorizonDays int // How many days ahead the sessions of a series are generated
+
+	// Rate limiting
+	RateLimitEnabled          bool
+	RateLimitStore            RateLimitStoreType
+	RateLimitWindow           time.Duration
+	RateLimitIpMaxRequests    int
+	RateLimitEmailMaxRequests int
+	TrustedProxies            []*net.IPNet // Proxies whose X-Forwarded-For hops are trusted to carry the client IP
+
+	// Idempotency keys
+	IdempotencyKeyTtl time.Duration // How long the response of a request is replayed for retries
+
+	// Login lockout
+	LoginMaxFailedAttempts  int
+	LoginLockoutDuration    time.Duration // Doubles on every consecutive lockout
+	LoginMaxLockoutDuration time.Duration
+
+	// Two-factor authentication
+	RequireAdminTwoFactor        bool // Makes TOTP mandatory for the ADMINISTRATOR role
+	TwoFactorIssuer              string
+	TwoFactorChallengeExpiration time.Duration
+	TwoFactorRecoveryCodeCount   int
+
+	// OpenID Connect
+	OidcProviders   map[string]*OidcProvider // Keyed by provider name
+	OidcJwksRefresh time.Duration            // How long fetched signing keys are cached
+
+	// Encryption of personal data
+	PiiEncryptionKeys  map[string][]byte // Key encryption keys by id, older ones kept to decrypt
+	PiiEncryptionKeyId string            // Key that encrypts new values
+	PiiBlindIndexKey   []byte            // Key of the hashes used to look up encrypted values
 
 	// Email
 	EmailHost     string
@@ -98,6 +154,176 @@ func NewEnvSettings(logger logging.Logger) *EnvSettings {
 
 	tokenSignatureKey := []byte(os.Getenv("TOKEN_SIGNATURE_KEY"))
 
+	accessTokenExpirationMinutes, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_EXPIRATION_MINUTES"))
+	if err != nil || accessTokenExpirationMinutes <= 0 {
+		accessTokenExpirationMinutes = 15
+	}
+
+	refreshTokenExpirationDays, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_EXPIRATION_DAYS"))
+	if err != nil || refreshTokenExpirationDays <= 0 {
+		refreshTokenExpirationDays = 30
+	}
+
+	// Password reset
+	passwordResetPinExpirationMinutes, err := strconv.Atoi(
+		os.Getenv("PASSWORD_RESET_PIN_EXPIRATION_MINUTES"),
+	)
+	if err != nil || passwordResetPinExpirationMinutes <= 0 {
+		passwordResetPinExpirationMinutes = 15
+	}
+
+	passwordResetMaxAttempts, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_MAX_ATTEMPTS"))
+	if err != nil || passwordResetMaxAttempts <= 0 {
+		passwordResetMaxAttempts = 5
+	}
+
+	// Email verification
+	emailVerificationCodeExpirationMinutes, err := strconv.Atoi(
+		os.Getenv("EMAIL_VERIFICATION_CODE_EXPIRATION_MINUTES"),
+	)
+	if err != nil || emailVerificationCodeExpirationMinutes <= 0 {
+		emailVerificationCodeExpirationMinutes = 60
+	}
+
+	emailVerificationMaxAttempts, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_MAX_ATTEMPTS"))
+	if err != nil || emailVerificationMaxAttempts <= 0 {
+		emailVerificationMaxAttempts = 5
+	}
+
+	requireVerifiedEmail, err := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL"))
+	if err != nil {
+		requireVerifiedEmail = false
+	}
+
+	// Reservations
+	requireMembershipForBooking, err := strconv.ParseBool(os.Getenv("REQUIRE_MEMBERSHIP_FOR_BOOKING"))
+	if err != nil {
+		requireMembershipForBooking = false
+	}
+
+	checkInTokenExpirationMinutes, err := strconv.Atoi(os.Getenv("CHECK_IN_TOKEN_EXPIRATION_MINUTES"))
+	if err != nil || checkInTokenExpirationMinutes <= 0 {
+		checkInTokenExpirationMinutes = 5
+	}
+
+	attendanceTrackingEnabled, err := strconv.ParseBool(os.Getenv("ATTENDANCE_TRACKING_ENABLED"))
+	if err != nil {
+		attendanceTrackingEnabled = false
+	}
+
+	bookingTimeZoneName := os.Getenv("BOOKING_TIME_ZONE")
+	if bookingTimeZoneName == "" {
+		bookingTimeZoneName = "America/Lima"
+	}
+	bookingTimeZone, err := time.LoadLocation(bookingTimeZoneName)
+	if err != nil {
+		// Without tzdata, fall back to the offset of Lima, which has no daylight saving time
+		bookingTimeZone = time.FixedZone("UTC-5", -5*3600)
+	}
+
+	standingBookingWindowDays, err := strconv.Atoi(os.Getenv("STANDING_BOOKING_WINDOW_DAYS"))
+	if err != nil || standingBookingWindowDays <= 0 {
+		standingBookingWindowDays = 7
+	}
+
+	// Session series
+	sessionSeriesHorizonDays, err := strconv.Atoi(os.Getenv("SESSION_SERIES_HORIZON_DAYS"))
+	if err != nil || sessionSeriesHorizonDays <= 0 {
+		sessionSeriesHorizonDays = 56
+	}
+
+	// Rate limiting
+	rateLimitEnabled, err := strconv.ParseBool(os.Getenv("RATE_LIMIT_ENABLED"))
+	if err != nil {
+		rateLimitEnabled = true
+	}
+
+	rateLimitStore := RateLimitStoreType(os.Getenv("RATE_LIMIT_STORE"))
+	if rateLimitStore != RateLimitStorePostgres {
+		rateLimitStore = RateLimitStoreMemory
+	}
+
+	rateLimitWindowSeconds, err := strconv.Atoi(os.Getenv("RATE_LIMIT_WINDOW_SECONDS"))
+	if err != nil || rateLimitWindowSeconds <= 0 {
+		rateLimitWindowSeconds = 60
+	}
+
+	rateLimitIpMaxRequests, err := strconv.Atoi(os.Getenv("RATE_LIMIT_IP_MAX_REQUESTS"))
+	if err != nil || rateLimitIpMaxRequests <= 0 {
+		rateLimitIpMaxRequests = 20
+	}
+
+	rateLimitEmailMaxRequests, err := strconv.Atoi(os.Getenv("RATE_LIMIT_EMAIL_MAX_REQUESTS"))
+	if err != nil || rateLimitEmailMaxRequests <= 0 {
+		rateLimitEmailMaxRequests = 5
+	}
+
+	trustedProxies := parseTrustedProxies(logger)
+
+	// Idempotency keys
+	idempotencyKeyTtlHours, err := strconv.Atoi(os.Getenv("IDEMPOTENCY_KEY_TTL_HOURS"))
+	if err != nil || idempotencyKeyTtlHours <= 0 {
+		idempotencyKeyTtlHours = 24
+	}
+
+	// Login lockout
+	loginMaxFailedAttempts, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILED_ATTEMPTS"))
+	if err != nil || loginMaxFailedAttempts <= 0 {
+		loginMaxFailedAttempts = 5
+	}
+
+	loginLockoutMinutes, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES"))
+	if err != nil || loginLockoutMinutes <= 0 {
+		loginLockoutMinutes = 15
+	}
+
+	loginMaxLockoutHours, err := strconv.Atoi(os.Getenv("LOGIN_MAX_LOCKOUT_HOURS"))
+	if err != nil || loginMaxLockoutHours <= 0 {
+		loginMaxLockoutHours = 24
+	}
+
+	// Two-factor authentication
+	requireAdminTwoFactor, err := strconv.ParseBool(os.Getenv("REQUIRE_ADMIN_TWO_FACTOR"))
+	if err != nil {
+		requireAdminTwoFactor = false
+	}
+
+	twoFactorIssuer := os.Getenv("TWO_FACTOR_ISSUER")
+	if twoFactorIssuer == "" {
+		twoFactorIssuer = "AstroCat"
+	}
+
+	twoFactorChallengeExpirationMinutes, err := strconv.Atoi(
+		os.Getenv("TWO_FACTOR_CHALLENGE_EXPIRATION_MINUTES"),
+	)
+	if err != nil || twoFactorChallengeExpirationMinutes <= 0 {
+		twoFactorChallengeExpirationMinutes = 5
+	}
+
+	twoFactorRecoveryCodeCount, err := strconv.Atoi(os.Getenv("TWO_FACTOR_RECOVERY_CODE_COUNT"))
+	if err != nil || twoFactorRecoveryCodeCount <= 0 {
+		twoFactorRecoveryCodeCount = 10
+	}
+
+	// OpenID Connect
+	oidcProviders := parseOidcProviders(logger)
+
+	oidcJwksRefreshMinutes, err := strconv.Atoi(os.Getenv("OIDC_JWKS_REFRESH_MINUTES"))
+	if err != nil || oidcJwksRefreshMinutes <= 0 {
+		oidcJwksRefreshMinutes = 60
+	}
+
+	// Encryption of personal data
+	piiEncryptionKeys, piiEncryptionKeyId := parsePiiEncryptionKeys(logger)
+
+	piiBlindIndexKey, err := base64.StdEncoding.DecodeString(os.Getenv("PII_BLIND_INDEX_KEY"))
+	if err != nil {
+		logger.Panicln("PII_BLIND_INDEX_KEY must be base64", err)
+	}
+	if len(piiEncryptionKeys) > 0 && len(piiBlindIndexKey) < 32 {
+		logger.Panicln("PII_BLIND_INDEX_KEY must be at least 32 bytes long when PII encryption is enabled")
+	}
+
 	// Email
 	emailHost := os.Getenv("EMAIL_HOST")
 	emailPortStr := os.Getenv("EMAIL_PORT")
@@ -136,7 +362,51 @@ func NewEnvSettings(logger logging.Logger) *EnvSettings {
 		AstroCatPostgresName:     astroCatPostgresName,
 		AstroCatPsqlSslMode:      astroCatPsqlSslMode,
 
-		TokenSignatureKey: tokenSignatureKey,
+		TokenSignatureKey:      tokenSignatureKey,
+		AccessTokenExpiration:  time.Duration(accessTokenExpirationMinutes) * time.Minute,
+		RefreshTokenExpiration: time.Duration(refreshTokenExpirationDays) * 24 * time.Hour,
+
+		PasswordResetPinExpiration: time.Duration(passwordResetPinExpirationMinutes) * time.Minute,
+		PasswordResetMaxAttempts:   passwordResetMaxAttempts,
+
+		EmailVerificationCodeExpiration: time.Duration(emailVerificationCodeExpirationMinutes) * time.Minute,
+		EmailVerificationMaxAttempts:    emailVerificationMaxAttempts,
+		RequireVerifiedEmail:            requireVerifiedEmail,
+
+		RequireMembershipForBooking: requireMembershipForBooking,
+		CheckInTokenExpiration:      time.Duration(checkInTokenExpirationMinutes) * time.Minute,
+		AttendanceTrackingEnabled:   attendanceTrackingEnabled,
+		BookingTimeZone:             bookingTimeZone,
+		StandingBookingWindowDays:   standingBookingWindowDays,
+
+		SessionSeriesHorizonDays: sessionSeriesHorizonDays,
+
+		RateLimitEnabled:          rateLimitEnabled,
+		RateLimitStore:            rateLimitStore,
+		RateLimitWindow:           time.Duration(rateLimitWindowSeconds) * time.Second,
+		RateLimitIpMaxRequests:    rateLimitIpMaxRequests,
+		RateLimitEmailMaxRequests: rateLimitEmailMaxRequests,
+		TrustedProxies:            trustedProxies,
+
+		IdempotencyKeyTtl: time.Duration(idempotencyKeyTtlHours) * time.Hour,
+
+		LoginMaxFailedAttempts:  loginMaxFailedAttempts,
+		LoginLockoutDuration:    time.Duration(loginLockoutMinutes) * time.Minute,
+		LoginMaxLockoutDuration: time.Duration(loginMaxLockoutHours) * time.Hour,
+
+		RequireAdminTwoFactor: requireAdminTwoFactor,
+		TwoFactorIssuer:       twoFactorIssuer,
+		TwoFactorChallengeExpiration: time.Duration(
+			twoFactorChallengeExpirationMinutes,
+		) * time.Minute,
+		TwoFactorRecoveryCodeCount: twoFactorRecoveryCodeCount,
+
+		OidcProviders:   oidcProviders,
+		OidcJwksRefresh: time.Duration(oidcJwksRefreshMinutes) * time.Minute,
+
+		PiiEncryptionKeys:  piiEncryptionKeys,
+		PiiEncryptionKeyId: piiEncryptionKeyId,
+		PiiBlindIndexKey:   piiBlindIndexKey,
 
 		EmailHost:     emailHost,
 		EmailPort:     emailPort,
@@ -155,3 +425,124 @@ func NewEnvSettings(logger logging.Logger) *EnvSettings {
 		TwilioPhoneNumber: twilioPhoneNumber,
 	}
 }
+
+// Reads the OpenID Connect providers named in OIDC_PROVIDERS, a comma separated list. Each provider
+// is configured with OIDC_<NAME>_ISSUER (comma separated), OIDC_<NAME>_CLIENT_ID and
+// OIDC_<NAME>_JWKS_URL. Google only needs its client id. Providers without a client id are left
+// out, since any ID token they issue to another client would be accepted otherwise.
+func parseOidcProviders(logger logging.Logger) map[string]*OidcProvider {
+	providers := map[string]*OidcProvider{
+		"google": {
+			Name:     "google",
+			Issuers:  []string{"https://accounts.google.com", "accounts.google.com"},
+			ClientId: os.Getenv("OIDC_GOOGLE_CLIENT_ID"),
+			JwksUrl:  "https://www.googleapis.com/oauth2/v3/certs",
+		},
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider, ok := providers[name]
+		if !ok {
+			provider = &OidcProvider{Name: name}
+			providers[name] = provider
+		}
+		if issuers := os.Getenv(prefix + "ISSUER"); issuers != "" {
+			provider.Issuers = nil
+			for _, issuer := range strings.Split(issuers, ",") {
+				if issuer = strings.TrimSpace(issuer); issuer != "" {
+					provider.Issuers = append(provider.Issuers, issuer)
+				}
+			}
+		}
+		if clientId := os.Getenv(prefix + "CLIENT_ID"); clientId != "" {
+			provider.ClientId = clientId
+		}
+		if jwksUrl := os.Getenv(prefix + "JWKS_URL"); jwksUrl != "" {
+			provider.JwksUrl = jwksUrl
+		}
+
+		if len(provider.Issuers) == 0 || provider.JwksUrl == "" {
+			logger.Warnf("OIDC provider %s needs an issuer and a JWKS url, ignoring it", name)
+			delete(providers, name)
+		}
+	}
+
+	for name, provider := range providers {
+		if provider.ClientId == "" {
+			if name != "google" {
+				logger.Warnf("OIDC provider %s has no client id, ignoring it", name)
+			}
+			delete(providers, name)
+		}
+	}
+
+	return providers
+}
+
+// Reads the proxies in front of the API from TRUSTED_PROXIES, a comma separated list of IPs or
+// CIDR ranges. Without them the client IP is the address of the connection and forwarding headers
+// are ignored.
+func parseTrustedProxies(logger logging.Logger) []*net.IPNet {
+	proxies := []*net.IPNet{}
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, proxy, err := net.ParseCIDR(entry)
+		if err != nil {
+			logger.Warnf("TRUSTED_PROXIES entry %s is not an IP or CIDR range, ignoring it", entry)
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	return proxies
+}
+
+// Reads the key encryption keys of personal data from PII_ENCRYPTION_KEYS, a comma separated list of
+// <id>:<base64 key> entries. New values are encrypted with PII_ENCRYPTION_KEY_ID, or the first key
+// of the list. To rotate, add a new key, make it the active one and run the PII migration command;
+// the old key can be dropped once it finishes.
+func parsePiiEncryptionKeys(logger logging.Logger) (map[string][]byte, string) {
+	keys := map[string][]byte{}
+	activeKeyId := strings.TrimSpace(os.Getenv("PII_ENCRYPTION_KEY_ID"))
+
+	for _, entry := range strings.Split(os.Getenv("PII_ENCRYPTION_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		keyId, encodedKey, found := strings.Cut(entry, ":")
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+		if !found || err != nil {
+			logger.Panicln("PII_ENCRYPTION_KEYS entries must be <id>:<base64 key>")
+		}
+		keyId = strings.TrimSpace(keyId)
+		keys[keyId] = key
+		if activeKeyId == "" {
+			activeKeyId = keyId
+		}
+	}
+
+	if len(keys) == 0 {
+		logger.Warnln("PII_ENCRYPTION_KEYS is not set, personal data is stored in plaintext")
+	}
+
+	return keys, activeKeyId
+}
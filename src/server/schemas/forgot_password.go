@@ -6,9 +6,14 @@ type ForgotPasswordRequest struct {
 
 type ForgotPasswordResponse struct {
 	Message string `json:"message"`
-	Pin     string `json:"pin"`
 }
 
 type SendPINBySMSRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
+
+type ResetPasswordRequest struct {
+	Email       string `json:"email" validate:"required,email"`
+	Pin         string `json:"pin" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
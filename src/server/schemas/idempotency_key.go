@@ -0,0 +1,20 @@
+package schemas
+
+import "time"
+
+const (
+	// Header clients send to make retries of a mutating request safe
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// Header set on responses replayed from a previous request with the same key
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+type IdempotencyKey struct {
+	Scope        string    `json:"scope"`
+	Key          string    `json:"key"`
+	RequestHash  string    `json:"request_hash"`
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"content_type"`
+	ResponseBody []byte    `json:"response_body"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
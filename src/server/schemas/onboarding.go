@@ -70,6 +70,13 @@ type CreateOnboardingRequest struct {
 	Region     *string `json:"region"`
 }
 
+// Looks an onboarding up by its document. Sent in the body to keep the document number out of URLs
+// and access logs.
+type OnboardingDocumentLookupRequest struct {
+	DocumentType   DocumentType `json:"document_type" binding:"required"`
+	DocumentNumber string       `json:"document_number" binding:"required"`
+}
+
 type UpdateOnboardingRequest struct {
 	// Documento
 	DocumentType   *DocumentType `json:"document_type"`
@@ -0,0 +1,24 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetChannel string
+
+const (
+	PasswordResetChannelEmail PasswordResetChannel = "EMAIL"
+	PasswordResetChannelSms   PasswordResetChannel = "SMS"
+)
+
+type PasswordReset struct {
+	Id         uuid.UUID            `json:"id"`
+	UserId     uuid.UUID            `json:"user_id"`
+	PinHash    string               `json:"-"`
+	Channel    PasswordResetChannel `json:"channel"`
+	ExpiresAt  time.Time            `json:"expires_at"`
+	Attempts   int                  `json:"attempts"`
+	ConsumedAt *time.Time           `json:"consumed_at,omitempty"`
+}
@@ -0,0 +1,27 @@
+package schemas
+
+import "time"
+
+type DataExportFormat string
+
+const (
+	DataExportFormatJson DataExportFormat = "json"
+	DataExportFormatZip  DataExportFormat = "zip"
+)
+
+// Personal data held about a user, as handed over on a data export request
+type UserDataExport struct {
+	GeneratedAt   time.Time       `json:"generated_at"`
+	User          *UserProfile    `json:"user"`
+	ErasedAt      *time.Time      `json:"erased_at,omitempty"`
+	Onboarding    *Onboarding     `json:"onboarding"`
+	Memberships   []*Membership   `json:"memberships"`
+	Reservations  []*Reservation  `json:"reservations"`
+	Identities    []*UserIdentity `json:"identities"`
+	LoginSessions []*LoginSession `json:"login_sessions"`
+	AuditLogs     []*AuditLog     `json:"audit_logs"`
+}
+
+type DataErasureRequest struct {
+	Password string `json:"password"` // Required when the account has a password
+}
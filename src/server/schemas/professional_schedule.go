@@ -0,0 +1,45 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Range of a weekday in which a professional leads sessions
+type WorkingHours struct {
+	// Day of the week, 0 being Sunday
+	Weekday int `json:"weekday"`
+	// Times of day the range starts and ends at in the booking time zone, as HH:MM
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// Weekly template of working hours of a professional. Without ranges the professional can lead
+// sessions at any time.
+type ProfessionalWorkingHours struct {
+	ProfessionalId uuid.UUID       `json:"professional_id"`
+	WorkingHours   []*WorkingHours `json:"working_hours"`
+}
+
+type UpdateWorkingHoursRequest struct {
+	WorkingHours []*WorkingHours `json:"working_hours"`
+}
+
+type ProfessionalTimeOff struct {
+	Id             uuid.UUID `json:"id"`
+	ProfessionalId uuid.UUID `json:"professional_id"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	Reason         *string   `json:"reason,omitempty"`
+}
+
+type ProfessionalTimeOffList struct {
+	TimeOff []*ProfessionalTimeOff `json:"time_off"`
+}
+
+type CreateProfessionalTimeOffRequest struct {
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required"`
+	Reason    *string   `json:"reason,omitempty"`
+}
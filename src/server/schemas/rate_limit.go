@@ -0,0 +1,16 @@
+package schemas
+
+import "time"
+
+type RateLimitStoreType string
+
+const (
+	RateLimitStoreMemory   RateLimitStoreType = "memory"
+	RateLimitStorePostgres RateLimitStoreType = "postgres"
+)
+
+type RateLimitCounter struct {
+	Key       string    `json:"key"`
+	Count     int       `json:"count"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,16 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	Id           uuid.UUID  `json:"id"`
+	UserId       uuid.UUID  `json:"user_id"`
+	FamilyId     uuid.UUID  `json:"family_id"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ReplacedById *uuid.UUID `json:"replaced_by_id,omitempty"`
+}
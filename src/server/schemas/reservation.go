@@ -3,15 +3,21 @@ package schemas
 import (
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+const CheckInTokenAudience = "reservation_check_in"
+
 type Reservation struct {
 	Id               uuid.UUID  `json:"id"`
 	Name             string     `json:"name"`
 	ReservationTime  time.Time  `json:"reservation_time"`
 	State            string     `json:"state"`
 	LastModification time.Time  `json:"last_modification"`
+	WaitlistedAt     *time.Time `json:"waitlisted_at,omitempty"`
+	CreditForfeited  bool       `json:"credit_forfeited"`
+	CheckedInAt      *time.Time `json:"checked_in_at,omitempty"`
 	UserId           uuid.UUID  `json:"user_id"`
 	SessionId        uuid.UUID  `json:"session_id"`
 	Session          Session    `json:"session"`
@@ -23,12 +29,15 @@ type Reservations struct {
 }
 
 type CreateReservationRequest struct {
-	Name            string     `json:"name"`
-	ReservationTime time.Time  `json:"reservation_time"`
-	State           string     `json:"state"`
-	UserId          uuid.UUID  `json:"user_id"`
-	SessionId       uuid.UUID  `json:"session_id"`
-	MembershipId    *uuid.UUID `json:"membership_id,omitempty"`
+	Name            string    `json:"name"`
+	ReservationTime time.Time `json:"reservation_time"`
+	// Only CONFIRMED, the default when empty, is accepted
+	State        string     `json:"state"`
+	UserId       uuid.UUID  `json:"user_id"`
+	SessionId    uuid.UUID  `json:"session_id"`
+	MembershipId *uuid.UUID `json:"membership_id,omitempty"`
+	// Puts the reservation on the waitlist when the session is full instead of rejecting it
+	JoinWaitlist bool `json:"join_waitlist"`
 }
 
 type UpdateReservationRequest struct {
@@ -43,3 +52,27 @@ type UpdateReservationRequest struct {
 type BulkDeleteReservationRequest struct {
 	Reservations []string `json:"reservations"`
 }
+
+type WaitlistPosition struct {
+	ReservationId uuid.UUID  `json:"reservation_id"`
+	SessionId     uuid.UUID  `json:"session_id"`
+	Position      int        `json:"position"`
+	WaitlistSize  int        `json:"waitlist_size"`
+	WaitlistedAt  *time.Time `json:"waitlisted_at"`
+}
+
+type CheckInClaims struct {
+	ReservationId uuid.UUID `json:"reservation_id"`
+	jwt.RegisteredClaims
+}
+
+type CheckInRequest struct {
+	Token string `json:"token"`
+}
+
+type NoShowSweep struct {
+	// Reservations marked as no-shows
+	Reservations []uuid.UUID `json:"reservations"`
+	// Of those, the ones whose membership use was kept as a penalty
+	CreditsForfeited int `json:"credits_forfeited"`
+}
@@ -0,0 +1,55 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationTransition struct {
+	From string
+	To   string
+	// The membership use may be kept as a penalty when the cancellation policy says so
+	MayForfeitCredit bool
+	// Only staff can make it, members cannot on their own reservations
+	StaffOnly bool
+}
+
+// Changes of state a reservation can go through. DONE, NO_SHOW and ANULLED are final. Spots and
+// membership uses follow the state, only CONFIRMED and DONE reservations hold one.
+var ReservationTransitions = []ReservationTransition{
+	{From: "CONFIRMED", To: "CANCELLED", MayForfeitCredit: true},
+	{From: "CONFIRMED", To: "DONE", StaffOnly: true},
+	{From: "CONFIRMED", To: "NO_SHOW", MayForfeitCredit: true, StaffOnly: true},
+	{From: "CONFIRMED", To: "ANULLED", StaffOnly: true},
+	{From: "WAITLISTED", To: "CONFIRMED", StaffOnly: true},
+	{From: "WAITLISTED", To: "CANCELLED"},
+	{From: "WAITLISTED", To: "ANULLED", StaffOnly: true},
+	{From: "CANCELLED", To: "CONFIRMED"},
+}
+
+// Finds the declared transition between two reservation states
+func FindReservationTransition(from string, to string) (*ReservationTransition, bool) {
+	for _, transition := range ReservationTransitions {
+		if transition.From == from && transition.To == to {
+			return &transition, true
+		}
+	}
+	return nil, false
+}
+
+type ReservationStateChange struct {
+	Id        uuid.UUID `json:"id"`
+	FromState *string   `json:"from_state"`
+	ToState   string    `json:"to_state"`
+	// What made the change, e.g. BOOKING, UPDATE, CHECK_IN, WAITLIST_PROMOTION, SESSION_CANCELLED or SESSION_COMPLETED
+	Reason    string    `json:"reason"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type ReservationTimeline struct {
+	ReservationId uuid.UUID                 `json:"reservation_id"`
+	State         string                    `json:"state"`
+	Changes       []*ReservationStateChange `json:"changes"`
+}
@@ -0,0 +1,201 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permission codes checked by the RequirePermission middleware
+const (
+	PermissionCommunityWrite       = "community:write"
+	PermissionProfessionalWrite    = "professional:write"
+	PermissionLocalWrite           = "local:write"
+	PermissionPlanWrite            = "plan:write"
+	PermissionServiceWrite         = "service:write"
+	PermissionSessionRead          = "session:read"
+	PermissionSessionWrite         = "session:write"
+	PermissionReservationRead      = "reservation:read"
+	PermissionReservationWrite     = "reservation:write"
+	PermissionReservationCheckIn   = "reservation:check_in"
+	PermissionReservationAnyUser   = "reservation:any_user"
+	PermissionMembershipRead       = "membership:read"
+	PermissionMembershipWrite      = "membership:write"
+	PermissionMembershipAnyUser    = "membership:any_user"
+	PermissionOnboardingWrite      = "onboarding:write"
+	PermissionUserRead             = "user:read"
+	PermissionUserWrite            = "user:write"
+	PermissionRoleManage           = "role:manage"
+	PermissionAuditRead            = "audit:read"
+	PermissionReportRead           = "report:read"
+	PermissionServiceAccountManage = "service_account:manage"
+)
+
+type PermissionDefinition struct {
+	Code        string
+	Description string
+}
+
+type RoleDefinition struct {
+	Name            UserRol
+	Description     string
+	CommunityScoped bool
+	Permissions     []string
+}
+
+// Permissions seeded on start up. New permissions are added, existing ones are kept.
+var DefaultPermissions = []PermissionDefinition{
+	{PermissionCommunityWrite, "Create, update and delete communities"},
+	{PermissionProfessionalWrite, "Create, update and delete professionals"},
+	{PermissionLocalWrite, "Create, update and delete locals"},
+	{PermissionPlanWrite, "Create, update and delete plans"},
+	{PermissionServiceWrite, "Create, update and delete services"},
+	{PermissionSessionRead, "Check session availability and conflicts"},
+	{PermissionSessionWrite, "Create, update and delete sessions"},
+	{PermissionReservationRead, "View reservations"},
+	{PermissionReservationWrite, "Create, update and cancel reservations"},
+	{PermissionReservationCheckIn, "Check attendees in to their sessions"},
+	{PermissionReservationAnyUser, "Access the reservations of other users, e.g. to book walk-ins"},
+	{PermissionMembershipRead, "View memberships"},
+	{PermissionMembershipWrite, "Create, update and cancel memberships"},
+	{PermissionMembershipAnyUser, "Access the memberships of other users"},
+	{PermissionOnboardingWrite, "Manage onboarding data"},
+	{PermissionUserRead, "View users"},
+	{PermissionUserWrite, "Create, update and delete users"},
+	{PermissionRoleManage, "Manage roles and role assignments"},
+	{PermissionAuditRead, "View audit and error logs"},
+	{PermissionReportRead, "View reports"},
+	{PermissionServiceAccountManage, "Manage service accounts and their API keys"},
+}
+
+// Roles seeded on start up with their initial permissions. Permissions of a role that already
+// exists are not touched, except for ADMINISTRATOR which always gets every permission and for
+// permissions seeded for the first time, which are granted to the roles that list them.
+var DefaultRoles = []RoleDefinition{
+	{
+		Name:        UserRolAdmin,
+		Description: "Full access to every resource",
+	},
+	{
+		Name:        UserRolClient,
+		Description: "Books sessions and manages their own memberships",
+		Permissions: []string{
+			PermissionSessionRead,
+			PermissionReservationRead,
+			PermissionReservationWrite,
+			PermissionMembershipRead,
+			PermissionMembershipWrite,
+			PermissionOnboardingWrite,
+		},
+	},
+	{
+		Name:        UserRolGuest,
+		Description: "Only browses public resources",
+	},
+	{
+		Name:        UserRolProfessional,
+		Description: "Leads sessions and checks their attendees",
+		Permissions: []string{
+			PermissionSessionRead,
+			PermissionReservationRead,
+			PermissionReservationCheckIn,
+		},
+	},
+	{
+		Name:            UserRolCommunityManager,
+		Description:     "Manages the sessions, reservations and memberships of their communities",
+		CommunityScoped: true,
+		Permissions: []string{
+			PermissionCommunityWrite,
+			PermissionSessionRead,
+			PermissionSessionWrite,
+			PermissionReservationRead,
+			PermissionReservationWrite,
+			PermissionReservationCheckIn,
+			PermissionReservationAnyUser,
+			PermissionMembershipRead,
+			PermissionMembershipWrite,
+			PermissionMembershipAnyUser,
+			PermissionReportRead,
+		},
+	},
+	{
+		Name:        UserRolFrontDesk,
+		Description: "Books and checks reservations for walk-in users",
+		Permissions: []string{
+			PermissionSessionRead,
+			PermissionReservationRead,
+			PermissionReservationWrite,
+			PermissionReservationCheckIn,
+			PermissionReservationAnyUser,
+			PermissionMembershipRead,
+			PermissionMembershipAnyUser,
+		},
+	},
+}
+
+type Permission struct {
+	Id          uuid.UUID `json:"id"`
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+}
+
+type Permissions struct {
+	Permissions []*Permission `json:"permissions"`
+}
+
+type Role struct {
+	Id              uuid.UUID `json:"id"`
+	Name            UserRol   `json:"name"`
+	Description     string    `json:"description"`
+	CommunityScoped bool      `json:"community_scoped"`
+	Permissions     []string  `json:"permissions"`
+}
+
+type Roles struct {
+	Roles []*Role `json:"roles"`
+}
+
+type UpdateRolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// A permission of a user, CommunityId is nil when it applies to every community
+type PermissionGrant struct {
+	Code        string     `json:"code"`
+	CommunityId *uuid.UUID `json:"community_id"`
+}
+
+// Resources a request refers to, from its path, query or body. Grants limited to a community only
+// apply when every community these resources belong to is granted.
+type PermissionResources struct {
+	CommunityId          *uuid.UUID `json:"community_id"`
+	CommunityServiceId   *uuid.UUID `json:"community_service_id"`
+	SessionId            *uuid.UUID `json:"session_id"`
+	SessionSeriesId      *uuid.UUID `json:"-"`
+	ReservationId        *uuid.UUID `json:"-"`
+	CancellationPolicyId *uuid.UUID `json:"-"`
+}
+
+type UserPermissions struct {
+	UserId uuid.UUID          `json:"user_id"`
+	Grants []*PermissionGrant `json:"grants"`
+}
+
+type UserRoleAssignment struct {
+	Id          uuid.UUID  `json:"id"`
+	UserId      uuid.UUID  `json:"user_id"`
+	RoleId      uuid.UUID  `json:"role_id"`
+	RoleName    UserRol    `json:"role_name"`
+	CommunityId *uuid.UUID `json:"community_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type UserRoleAssignments struct {
+	UserRoleAssignments []*UserRoleAssignment `json:"user_role_assignments"`
+}
+
+type CreateUserRoleAssignmentRequest struct {
+	RoleName    UserRol    `json:"role_name"`
+	CommunityId *uuid.UUID `json:"community_id"`
+}
@@ -0,0 +1,47 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header that carries the API key of a service account
+	ApiKeyHeader = "X-Api-Key"
+	// Request context key of the service account authenticated by an API key
+	ServiceAccountContextKey = "service_account"
+)
+
+type ServiceAccount struct {
+	Id          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Scopes      []string   `json:"scopes"`
+	KeyPrefix   string     `json:"key_prefix"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type ServiceAccounts struct {
+	ServiceAccounts []*ServiceAccount `json:"service_accounts"`
+}
+
+// A service account with its API key. The key is only returned when it is created or rotated.
+type ServiceAccountWithKey struct {
+	ServiceAccount *ServiceAccount `json:"service_account"`
+	ApiKey         string          `json:"api_key"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type RotateServiceAccountKeyRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
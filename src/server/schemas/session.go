@@ -19,6 +19,10 @@ type Session struct {
 	ProfessionalId     uuid.UUID  `json:"professional_id"`
 	LocalId            *uuid.UUID `json:"local_id"`
 	CommunityServiceId *uuid.UUID `json:"community_service_id"`
+	// Series the session is an occurrence of
+	SeriesId *uuid.UUID `json:"series_id,omitempty"`
+	// Reservations annulled or carried over by cancelling or rescheduling the session
+	AffectedReservationIds []uuid.UUID `json:"affected_reservation_ids,omitempty"`
 }
 
 type Sessions struct {
@@ -69,10 +73,20 @@ type CheckConflictRequest struct {
 	ExcludeId          *uuid.UUID `json:"exclude_id"`
 }
 
+type SessionLifecycleSweep struct {
+	// Sessions moved to ONGOING
+	StartedSessions int64 `json:"started_sessions"`
+	// Sessions moved to COMPLETED
+	CompletedSessions int64 `json:"completed_sessions"`
+}
+
 type ConflictResult struct {
 	HasConflict           bool       `json:"has_conflict"`
 	ProfessionalConflicts []*Session `json:"professional_conflicts"`
 	LocalConflicts        []*Session `json:"local_conflicts"`
+	// Whether the session falls outside the working hours of the professional
+	OutsideWorkingHours bool                   `json:"outside_working_hours"`
+	TimeOffConflicts    []*ProfessionalTimeOff `json:"time_off_conflicts"`
 }
 
 type AvailabilityRequest struct {
@@ -86,7 +100,7 @@ type TimeSlot struct {
 	Start string `json:"start"`
 	End   string `json:"end"`
 	Title string `json:"title"`
-	Type  string `json:"type"` // "professional" | "local"
+	Type  string `json:"type"` // "professional" | "local" | "time_off" | "outside_working_hours"
 }
 
 type AvailabilityResult struct {
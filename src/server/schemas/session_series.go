@@ -0,0 +1,96 @@
+package schemas
+
+import "github.com/google/uuid"
+
+// Which occurrences of a series an edit or a cancellation applies to
+const (
+	SessionSeriesScopeThis             = "THIS"
+	SessionSeriesScopeThisAndFollowing = "THIS_AND_FOLLOWING"
+	SessionSeriesScopeAll              = "ALL"
+)
+
+type SessionSeries struct {
+	Id    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	// Day of the first occurrence in the booking time zone, as YYYY-MM-DD
+	StartDate string `json:"start_date"`
+	// Time of day the sessions start at in the booking time zone, as HH:MM
+	StartTime       string  `json:"start_time"`
+	DurationMinutes int     `json:"duration_minutes"`
+	Capacity        int     `json:"capacity"`
+	SessionLink     *string `json:"session_link"`
+	// RRULE value of RFC 5545, e.g. FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10
+	Rule string `json:"rrule"`
+	// EXDATE value of RFC 5545: days without a session, as YYYYMMDD separated by commas
+	ExceptionDates string `json:"exdate"`
+	// Sessions have been generated for the days before this one, as YYYY-MM-DD
+	GeneratedUntil     string     `json:"generated_until"`
+	ProfessionalId     uuid.UUID  `json:"professional_id"`
+	LocalId            *uuid.UUID `json:"local_id"`
+	CommunityServiceId *uuid.UUID `json:"community_service_id"`
+}
+
+type SessionSeriesList struct {
+	Series []*SessionSeries `json:"series"`
+}
+
+type CreateSessionSeriesRequest struct {
+	Title string `json:"title" validate:"required"`
+	// Day of the first occurrence in the booking time zone, as YYYY-MM-DD
+	StartDate string `json:"start_date" validate:"required"`
+	// Time of day the sessions start at in the booking time zone, as HH:MM
+	StartTime          string     `json:"start_time" validate:"required"`
+	DurationMinutes    int        `json:"duration_minutes"`
+	Capacity           int        `json:"capacity"`
+	SessionLink        *string    `json:"session_link"`
+	ProfessionalId     uuid.UUID  `json:"professional_id" validate:"required"`
+	LocalId            *uuid.UUID `json:"local_id"`
+	CommunityServiceId *uuid.UUID `json:"community_service_id"`
+	// RRULE value of RFC 5545, e.g. FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10
+	Rule string `json:"rrule" validate:"required"`
+	// EXDATE value of RFC 5545: days without a session, as YYYYMMDD separated by commas
+	ExceptionDates string `json:"exdate"`
+}
+
+// Changes to the occurrences of a series. Times are in the booking time zone and keep the day of
+// each occurrence.
+type UpdateSessionSeriesRequest struct {
+	Title *string `json:"title"`
+	// Time of day the sessions start at, as HH:MM
+	StartTime       *string    `json:"start_time"`
+	DurationMinutes *int       `json:"duration_minutes"`
+	Capacity        *int       `json:"capacity"`
+	SessionLink     *string    `json:"session_link"`
+	ProfessionalId  *uuid.UUID `json:"professional_id"`
+	LocalId         *uuid.UUID `json:"local_id"`
+}
+
+// Session generated by a series for one of its days
+type SessionSeriesOccurrence struct {
+	// Day the session was generated for, as YYYY-MM-DD, even if it was moved
+	Date string `json:"date"`
+	// Edited on its own, so edits of the whole series leave it as it is
+	Detached bool     `json:"detached"`
+	Session  *Session `json:"session"`
+}
+
+type SessionSeriesOccurrences struct {
+	Occurrences []*SessionSeriesOccurrence `json:"occurrences"`
+}
+
+// Occurrence of a series left out of a generation, an edit or a cancellation
+type SessionSeriesSkip struct {
+	Date      string     `json:"date"`
+	SessionId *uuid.UUID `json:"session_id,omitempty"`
+	// Error code and message of the reason it was left out
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
+
+// What a change to a series did: the series the occurrences now belong to, which is a new one
+// when this and the following occurrences were edited, and the sessions created or changed
+type SessionSeriesReport struct {
+	Series   *SessionSeries       `json:"series"`
+	Sessions []*Session           `json:"sessions"`
+	Skipped  []*SessionSeriesSkip `json:"skipped"`
+}
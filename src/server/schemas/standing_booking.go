@@ -0,0 +1,64 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StandingBooking struct {
+	Id uuid.UUID `json:"id"`
+	// Day of the week of the sessions, 0 being Sunday
+	Weekday int `json:"weekday"`
+	// Time of day the sessions start at in the booking time zone, as HH:MM
+	StartTime      string     `json:"start_time"`
+	Active         bool       `json:"active"`
+	UserId         uuid.UUID  `json:"user_id"`
+	ServiceId      uuid.UUID  `json:"service_id"`
+	ProfessionalId uuid.UUID  `json:"professional_id"`
+	MembershipId   *uuid.UUID `json:"membership_id,omitempty"`
+}
+
+type StandingBookings struct {
+	StandingBookings []*StandingBooking `json:"standing_bookings"`
+}
+
+type CreateStandingBookingRequest struct {
+	UserId         uuid.UUID `json:"user_id" validate:"required"`
+	ServiceId      uuid.UUID `json:"service_id" validate:"required"`
+	ProfessionalId uuid.UUID `json:"professional_id" validate:"required"`
+	// Day of the week of the sessions, 0 being Sunday
+	Weekday int `json:"weekday"`
+	// Time of day the sessions start at in the booking time zone, as HH:MM
+	StartTime string `json:"start_time" validate:"required"`
+	// Membership to book with, otherwise the best eligible one at each booking
+	MembershipId *uuid.UUID `json:"membership_id,omitempty"`
+}
+
+type UpdateStandingBookingRequest struct {
+	Active       *bool      `json:"active"`
+	MembershipId *uuid.UUID `json:"membership_id,omitempty"`
+}
+
+// Outcome of booking one session for a standing booking
+type StandingBookingAttempt struct {
+	Id                uuid.UUID  `json:"id"`
+	StandingBookingId uuid.UUID  `json:"standing_booking_id"`
+	SessionId         uuid.UUID  `json:"session_id"`
+	SessionStartTime  time.Time  `json:"session_start_time"`
+	Status            string     `json:"status"`
+	ReservationId     *uuid.UUID `json:"reservation_id,omitempty"`
+	// Error code and message of the reason a booking was skipped
+	Reason *string `json:"reason,omitempty"`
+	Detail *string `json:"detail,omitempty"`
+}
+
+type StandingBookingAttempts struct {
+	Attempts []*StandingBookingAttempt `json:"attempts"`
+}
+
+// What a standing booking run did
+type StandingBookingReport struct {
+	Booked  []*StandingBookingAttempt `json:"booked"`
+	Skipped []*StandingBookingAttempt `json:"skipped"`
+}
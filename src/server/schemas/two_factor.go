@@ -0,0 +1,55 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Audience of the challenge tokens issued between the password and the TOTP step of a login
+const TwoFactorChallengeAudience = "two_factor_challenge"
+
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at"`
+	Required               bool       `json:"required"`
+	RemainingRecoveryCodes int        `json:"remaining_recovery_codes"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningUri string `json:"provisioning_uri"` // otpauth:// URI to render as a QR code
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Returned by a login that needs a second step. Clients must send the challenge token along
+// with a TOTP or recovery code to get the real tokens.
+type TwoFactorChallenge struct {
+	ChallengeToken     string        `json:"challenge_token"`
+	ExpiresIn          time.Duration `json:"expires_in"`
+	EnrollmentRequired bool          `json:"enrollment_required"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorChallengeClaims struct {
+	UserId       uuid.UUID `json:"user_id"`
+	TokenVersion int       `json:"token_version"`
+	jwt.RegisteredClaims
+}
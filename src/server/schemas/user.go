@@ -1,26 +1,42 @@
 package schemas
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UserRol string
 
 const (
-	UserRolAdmin  UserRol = "ADMINISTRATOR"
-	UserRolClient UserRol = "CLIENT"
-	UserRolGuest  UserRol = "GUEST"
+	UserRolAdmin            UserRol = "ADMINISTRATOR"
+	UserRolClient           UserRol = "CLIENT"
+	UserRolGuest            UserRol = "GUEST"
+	UserRolProfessional     UserRol = "PROFESSIONAL"
+	UserRolCommunityManager UserRol = "COMMUNITY_MANAGER" // Only manages the communities assigned to them
+	UserRolFrontDesk        UserRol = "FRONT_DESK"
+	UserRolServiceAccount   UserRol = "SERVICE_ACCOUNT" // Only attributes the actions of service accounts in audit logs
 )
 
 type User struct {
-	Id             uuid.UUID     `json:"id"`
-	Name           string        `json:"name"`
-	FirstLastName  string        `json:"first_last_name"`
-	SecondLastName *string       `json:"second_last_name"`
-	Password       string        `json:"password"`
-	Email          string        `json:"email"`
-	Rol            UserRol       `json:"rol"`
-	ImageUrl       string        `json:"image_url"`
-	Memberships    []*Membership `json:"memberships,omitempty"`
-	Onboarding     *Onboarding   `json:"onboarding,omitempty"`
+	Id                  uuid.UUID     `json:"id"`
+	Name                string        `json:"name"`
+	FirstLastName       string        `json:"first_last_name"`
+	SecondLastName      *string       `json:"second_last_name"`
+	Password            string        `json:"-"`
+	Email               string        `json:"email"`
+	Rol                 UserRol       `json:"rol"`
+	ImageUrl            string        `json:"image_url"`
+	TokenVersion        int           `json:"-"`
+	EmailVerifiedAt     *time.Time    `json:"email_verified_at"`
+	FailedLoginAttempts int           `json:"-"`
+	LockedUntil         *time.Time    `json:"locked_until,omitempty"`
+	TwoFactorSecret     *string       `json:"-"`
+	TwoFactorEnabledAt  *time.Time    `json:"two_factor_enabled_at"`
+	TwoFactorLastStep   int64         `json:"-"`
+	ErasedAt            *time.Time    `json:"erased_at,omitempty"`
+	Memberships         []*Membership `json:"memberships,omitempty"`
+	Onboarding          *Onboarding   `json:"onboarding,omitempty"`
 }
 
 type Users struct {
@@ -0,0 +1,59 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// OpenID Connect provider trusted for login, configured with the OIDC_PROVIDERS env vars
+type OidcProvider struct {
+	Name     string
+	Issuers  []string // Accepted values of the iss claim
+	ClientId string   // Expected audience of the ID tokens
+	JwksUrl  string
+}
+
+// Claims of an OpenID Connect ID token used for login. Some providers encode email_verified as a
+// string, so it is kept untyped.
+type OidcIdTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+type OidcLoginRequest struct {
+	Token string `json:"token" example:"eyJhbGciOi..."`
+}
+
+type OidcLoginResponse struct {
+	User               User                `json:"user"`
+	Tokens             TokenResponse       `json:"tokens"`
+	TwoFactorChallenge *TwoFactorChallenge `json:"two_factor_challenge,omitempty"` // Set instead of tokens
+}
+
+type OidcProviders struct {
+	Providers []string `json:"providers"`
+}
+
+// External identity of a user at an OpenID Connect provider
+type UserIdentity struct {
+	Id        uuid.UUID `json:"id"`
+	UserId    uuid.UUID `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserIdentities struct {
+	Identities []*UserIdentity `json:"identities"`
+}
+
+type LinkUserIdentityRequest struct {
+	Provider string `json:"provider" example:"google"`
+	Token    string `json:"token" example:"eyJhbGciOi..."`
+}
@@ -8,6 +8,7 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/api"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 	testSetup "onichankimochi.com/astro_cat_backend/src/server/tests"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
 )
 
 type ApiWrapper struct {
@@ -22,6 +23,7 @@ func NewApiTestWrapper() *ApiWrapper {
 	envSettings := schemas.NewEnvSettings(testLogger)
 	envSettings.DisableAuthForTests = true
 	envSettings.EnableSqlLogs = false // Disable SQL logs for testing
+	utilsTest.ConfigurePiiEncryption(envSettings)
 	server, astroCatDB := api.NewApi(testLogger, envSettings)
 
 	// Register routes but don't start the HTTP server
@@ -17,8 +17,8 @@ import (
 
 func TestRefreshTokenSuccessfully(t *testing.T) {
 	/*
-		GIVEN: A valid user with a valid access token
-		WHEN:  POST /auth/refresh/ is called with valid token
+		GIVEN: A valid user with a valid refresh token
+		WHEN:  POST /auth/refresh/ is called with the refresh token
 		THEN:  A HTTP_200_OK status should be returned with new tokens
 	*/
 	// GIVEN
@@ -61,11 +61,13 @@ func TestRefreshTokenSuccessfully(t *testing.T) {
 	var loginResponse schemas.LoginResponse
 	err = json.NewDecoder(loginRec.Body).Decode(&loginResponse)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, loginResponse.Tokens.AccessToken)
+	assert.NotEmpty(t, loginResponse.Tokens.RefreshToken)
 
 	// WHEN
-	req := httptest.NewRequest(http.MethodPost, "/auth/refresh/", nil)
-	req.Header.Set("Authorization", "Bearer "+loginResponse.Tokens.AccessToken)
+	refreshBody, _ := json.Marshal(schemas.RefreshTokenRequest{
+		RefreshToken: loginResponse.Tokens.RefreshToken,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh/", bytes.NewBuffer(refreshBody))
 	req.Header.Set("Content-Type", "application/json")
 
 	rec := httptest.NewRecorder()
@@ -79,11 +81,12 @@ func TestRefreshTokenSuccessfully(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, response.AccessToken)
 	assert.NotEmpty(t, response.RefreshToken)
+	assert.NotEqual(t, loginResponse.Tokens.RefreshToken, response.RefreshToken)
 }
 
 func TestRefreshTokenMissingToken(t *testing.T) {
 	/*
-		GIVEN: No authorization token provided
+		GIVEN: No refresh token provided
 		WHEN:  POST /auth/refresh/ is called without token
 		THEN:  A HTTP_401_UNAUTHORIZED status should be returned
 	*/
@@ -103,16 +106,16 @@ func TestRefreshTokenMissingToken(t *testing.T) {
 
 func TestRefreshTokenInvalidToken(t *testing.T) {
 	/*
-		GIVEN: An invalid authorization token
-		WHEN:  POST /auth/refresh/ is called with invalid token
+		GIVEN: An unknown refresh token
+		WHEN:  POST /auth/refresh/ is called with the unknown token
 		THEN:  A HTTP_401_UNAUTHORIZED status should be returned
 	*/
 	// GIVEN
 	server, _ := apiTest.NewApiServerTestWrapper(t)
 
 	// WHEN
-	req := httptest.NewRequest(http.MethodPost, "/auth/refresh/", nil)
-	req.Header.Set("Authorization", "Bearer invalid.jwt.token")
+	refreshBody, _ := json.Marshal(schemas.RefreshTokenRequest{RefreshToken: "unknown-refresh-token"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh/", bytes.NewBuffer(refreshBody))
 	req.Header.Set("Content-Type", "application/json")
 
 	rec := httptest.NewRecorder()
@@ -124,8 +127,8 @@ func TestRefreshTokenInvalidToken(t *testing.T) {
 
 func TestRefreshTokenMalformedToken(t *testing.T) {
 	/*
-		GIVEN: A malformed authorization token
-		WHEN:  POST /auth/refresh/ is called with malformed token
+		GIVEN: An access token sent instead of a refresh token
+		WHEN:  POST /auth/refresh/ is called with it in the Authorization header only
 		THEN:  A HTTP_401_UNAUTHORIZED status should be returned
 	*/
 	// GIVEN
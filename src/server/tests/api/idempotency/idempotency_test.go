@@ -0,0 +1,103 @@
+package idempotency_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	apiTest "onichankimochi.com/astro_cat_backend/src/server/tests/api"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+// Sends POST /reservation/ with an idempotency key
+func postReservation(
+	server *echo.Echo,
+	key string,
+	request schemas.CreateReservationRequest,
+) *httptest.ResponseRecorder {
+	requestBody, _ := json.Marshal(request)
+	req := httptest.NewRequest(http.MethodPost, "/reservation/", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(schemas.IdempotencyKeyHeader, key)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateReservationRetryIsReplayed(t *testing.T) {
+	/*
+		GIVEN: A reservation request with an idempotency key
+		WHEN:  POST /reservation/ is sent twice with the same key and body
+		THEN:  The reservation is created once and the retry gets the same response back
+	*/
+	// GIVEN
+	server, db := apiTest.NewApiServerTestWrapper(t)
+
+	user := factories.NewUserModel(db, factories.UserModelF{})
+	date := time.Now().Add(24 * time.Hour)
+	session := factories.NewSessionModel(db, factories.SessionModelF{Date: &date})
+	request := schemas.CreateReservationRequest{
+		Name:      "API Test Reservation",
+		UserId:    user.Id,
+		SessionId: session.Id,
+	}
+	key := utilsTest.GenerateRandomString(20)
+
+	// WHEN
+	first := postReservation(server.Echo, key, request)
+	retry := postReservation(server.Echo, key, request)
+
+	// THEN
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(schemas.IdempotencyReplayedHeader))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+
+	var reservations int64
+	assert.NoError(t, db.Model(&model.Reservation{}).Where("session_id = ?", session.Id).Count(&reservations).Error)
+	assert.Equal(t, int64(1), reservations)
+}
+
+func TestCreateReservationReusedKeyWithAnotherBody(t *testing.T) {
+	/*
+		GIVEN: An idempotency key already used for a reservation
+		WHEN:  POST /reservation/ is sent with the same key and a different body
+		THEN:  A HTTP_422_UNPROCESSABLE_ENTITY status should be returned
+	*/
+	// GIVEN
+	server, db := apiTest.NewApiServerTestWrapper(t)
+
+	user := factories.NewUserModel(db, factories.UserModelF{})
+	date := time.Now().Add(24 * time.Hour)
+	session := factories.NewSessionModel(db, factories.SessionModelF{Date: &date})
+	request := schemas.CreateReservationRequest{
+		Name:      "API Test Reservation",
+		UserId:    user.Id,
+		SessionId: session.Id,
+	}
+	key := utilsTest.GenerateRandomString(20)
+	assert.Equal(t, http.StatusCreated, postReservation(server.Echo, key, request).Code)
+
+	// WHEN
+	request.Name = "Another Reservation"
+	rec := postReservation(server.Echo, key, request)
+
+	// THEN
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var response errors.Error
+	err := json.NewDecoder(rec.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, errors.UnprocessableEntityError.IdempotencyKeyReused.Code, response.Code)
+}
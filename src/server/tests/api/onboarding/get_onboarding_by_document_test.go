@@ -0,0 +1,92 @@
+package onboarding_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	apiTest "onichankimochi.com/astro_cat_backend/src/server/tests/api"
+)
+
+func TestGetOnboardingByDocumentSuccessfully(t *testing.T) {
+	/*
+		GIVEN: An existing onboarding of a user
+		WHEN:  POST /onboarding/document/ is called with its document in the body
+		THEN:  A HTTP_200_OK status should be returned with the onboarding data
+	*/
+	// GIVEN
+	server, db := apiTest.NewApiServerTestWrapper(t)
+	user := factories.NewUserModel(db, factories.UserModelF{})
+
+	createBody, _ := json.Marshal(schemas.CreateOnboardingRequest{
+		DocumentType:   schemas.DocumentTypeDNI,
+		DocumentNumber: "87654321",
+		PhoneNumber:    "123456789",
+		PostalCode:     "12345",
+		Address:        "123 Main St",
+	})
+	createReq := httptest.NewRequest(http.MethodPost, "/onboarding/user/"+user.Id.String()+"/", bytes.NewBuffer(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	server.Echo.ServeHTTP(createRec, createReq)
+	assert.Equal(t, http.StatusCreated, createRec.Code)
+
+	body, _ := json.Marshal(schemas.OnboardingDocumentLookupRequest{
+		DocumentType:   schemas.DocumentTypeDNI,
+		DocumentNumber: "87654321",
+	})
+
+	// WHEN
+	req := httptest.NewRequest(http.MethodPost, "/onboarding/document/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Echo.ServeHTTP(rec, req)
+
+	// THEN
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response schemas.Onboarding
+	err := json.NewDecoder(rec.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Id, response.UserId)
+	assert.Equal(t, "87654321", response.DocumentNumber)
+}
+
+func TestGetOnboardingByDocumentInvalidDocumentType(t *testing.T) {
+	/*
+		GIVEN: A document type that does not exist
+		WHEN:  POST /onboarding/document/ is called with it
+		THEN:  A HTTP_422_UNPROCESSABLE_ENTITY status should be returned
+	*/
+	// GIVEN
+	server, _ := apiTest.NewApiServerTestWrapper(t)
+
+	body, _ := json.Marshal(schemas.OnboardingDocumentLookupRequest{
+		DocumentType:   "LIBRARY_CARD",
+		DocumentNumber: "87654321",
+	})
+
+	// WHEN
+	req := httptest.NewRequest(http.MethodPost, "/onboarding/document/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Echo.ServeHTTP(rec, req)
+
+	// THEN
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var response errors.Error
+	err := json.NewDecoder(rec.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, errors.UnprocessableEntityError.InvalidDocumentType.Code, response.Code)
+}
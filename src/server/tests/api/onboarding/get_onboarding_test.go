@@ -41,7 +41,7 @@ func TestGetOnboardingSuccessfully(t *testing.T) {
 	district := "Test District"
 	province := "Test Province"
 	region := "Test Region"
-	birthDate := time.Now().AddDate(-30, 0, 0) // 30 years ago
+	birthDate := time.Now().AddDate(-30, 0, 0).Format("2006-01-02") // 30 years ago
 	gender := model.GenderMale
 
 	onboarding := &model.Onboarding{
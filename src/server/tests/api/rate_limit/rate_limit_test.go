@@ -0,0 +1,133 @@
+package rate_limit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	apiTest "onichankimochi.com/astro_cat_backend/src/server/tests/api"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestForgotPasswordRateLimitedByEmail(t *testing.T) {
+	/*
+		GIVEN: The same email is used on every request
+		WHEN:  POST /forgot-password/ is called more times than the per email limit
+		THEN:  A HTTP_429_TOO_MANY_REQUESTS status should be returned with a Retry-After header
+	*/
+	// GIVEN
+	server, _ := apiTest.NewApiServerTestWrapper(t)
+
+	forgotPasswordRequest := schemas.ForgotPasswordRequest{
+		Email: utilsTest.GenerateRandomEmail(),
+	}
+	requestBody, _ := json.Marshal(forgotPasswordRequest)
+
+	// WHEN
+	var rec *httptest.ResponseRecorder
+	for i := 0; i <= server.EnvSettings.RateLimitEmailMaxRequests; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/forgot-password/", bytes.NewBuffer(requestBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+
+		rec = httptest.NewRecorder()
+		server.Echo.ServeHTTP(rec, req)
+	}
+
+	// THEN
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	var response errors.Error
+	err := json.NewDecoder(rec.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, errors.TooManyRequestsError.RateLimitExceeded.Code, response.Code)
+}
+
+func TestForgotPasswordRateLimitedByIpIgnoringForwardedHeaders(t *testing.T) {
+	/*
+		GIVEN: No trusted proxies are configured
+		WHEN:  POST /forgot-password/ is called more times than the per IP limit, with a new email
+		       and a new X-Forwarded-For header on every request
+		THEN:  A HTTP_429_TOO_MANY_REQUESTS status should be returned
+	*/
+	// GIVEN
+	server, _ := apiTest.NewApiServerTestWrapper(t)
+
+	// WHEN
+	var rec *httptest.ResponseRecorder
+	for i := 0; i <= server.EnvSettings.RateLimitIpMaxRequests; i++ {
+		forgotPasswordRequest := schemas.ForgotPasswordRequest{
+			Email: utilsTest.GenerateRandomEmail(),
+		}
+		requestBody, _ := json.Marshal(forgotPasswordRequest)
+
+		req := httptest.NewRequest(http.MethodPost, "/forgot-password/", bytes.NewBuffer(requestBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i%250+1))
+		req.Header.Set("X-Real-IP", fmt.Sprintf("198.51.100.%d", i%250+1))
+
+		rec = httptest.NewRecorder()
+		server.Echo.ServeHTTP(rec, req)
+	}
+
+	// THEN
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestCredentialEndpointsRateLimitedByEmail(t *testing.T) {
+	/*
+		GIVEN: The same email is used on every request
+		WHEN:  An endpoint that checks a code or sets a password is called more times than the per
+		       email limit
+		THEN:  A HTTP_429_TOO_MANY_REQUESTS status should be returned with a Retry-After header
+	*/
+	requests := map[string]any{
+		"/forgot-password/reset/": schemas.ResetPasswordRequest{
+			Email:       utilsTest.GenerateRandomEmail(),
+			Pin:         "000000",
+			NewPassword: "newPassword123",
+		},
+		"/verify-email/": schemas.VerifyEmailRequest{
+			Email: utilsTest.GenerateRandomEmail(),
+			Code:  "000000",
+		},
+		"/user/change-password/": schemas.ChangePasswordInput{
+			Email:       utilsTest.GenerateRandomEmail(),
+			NewPassword: "newPassword123",
+		},
+	}
+
+	for path, request := range requests {
+		// GIVEN
+		server, _ := apiTest.NewApiServerTestWrapper(t)
+		requestBody, _ := json.Marshal(request)
+
+		// WHEN
+		var rec *httptest.ResponseRecorder
+		for i := 0; i <= server.EnvSettings.RateLimitEmailMaxRequests; i++ {
+			req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+
+			rec = httptest.NewRecorder()
+			server.Echo.ServeHTTP(rec, req)
+		}
+
+		// THEN
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, path)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"), path)
+
+		var response errors.Error
+		err := json.NewDecoder(rec.Body).Decode(&response)
+		assert.NoError(t, err, path)
+		assert.Equal(t, errors.TooManyRequestsError.RateLimitExceeded.Code, response.Code, path)
+	}
+}
@@ -0,0 +1,55 @@
+package reservation_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	apiTest "onichankimochi.com/astro_cat_backend/src/server/tests/api"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestGetReservationOfAnotherUserIsForbidden(t *testing.T) {
+	/*
+		GIVEN: A reservation of a user and another logged in client
+		WHEN:  GET /reservation/{reservationId}/ is called with the token of the other client
+		THEN:  A HTTP_403_FORBIDDEN status should be returned
+	*/
+	// GIVEN
+	server, db := apiTest.NewApiServerTestWrapper(t)
+	reservation := factories.NewReservationModel(db)
+	email := utilsTest.GenerateRandomEmail()
+	factories.NewUserModel(db, factories.UserModelF{Email: &email})
+
+	loginBody, _ := json.Marshal(schemas.LoginRequest{Email: email, Password: "testpassword123"})
+	loginReq := httptest.NewRequest(http.MethodPost, "/login/", bytes.NewBuffer(loginBody))
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginRec := httptest.NewRecorder()
+	server.Echo.ServeHTTP(loginRec, loginReq)
+	assert.Equal(t, http.StatusOK, loginRec.Code)
+
+	var loginResponse schemas.LoginResponse
+	err := json.NewDecoder(loginRec.Body).Decode(&loginResponse)
+	assert.NoError(t, err)
+
+	// WHEN
+	req := httptest.NewRequest(http.MethodGet, "/reservation/"+reservation.Id.String()+"/", nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+loginResponse.Tokens.AccessToken)
+	rec := httptest.NewRecorder()
+	server.Echo.ServeHTTP(rec, req)
+
+	// THEN
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	var response errors.Error
+	err = json.NewDecoder(rec.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned.Code, response.Code)
+}
@@ -139,3 +139,37 @@ func TestChangeUserRoleInvalidRequestBody(t *testing.T) {
 	// THEN
 	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 }
+
+func TestChangeUserRoleRejectsCommunityScopedRole(t *testing.T) {
+	/*
+		GIVEN: A user exists in the database
+		WHEN:  PATCH /user/{userId}/role/ is called with a community scoped role or an unknown one
+		THEN:  A HTTP_400_BAD_REQUEST status should be returned and the role is kept
+	*/
+	// GIVEN
+	server, db := apiTest.NewApiServerTestWrapper(t)
+
+	rol := model.UserRolClient
+	user := factories.NewUserModel(db, factories.UserModelF{
+		Rol: &rol,
+	})
+
+	for _, requestedRol := range []schemas.UserRol{schemas.UserRolCommunityManager, "SUPERUSER"} {
+		requestBody, _ := json.Marshal(schemas.ChangeUserRoleRequest{Rol: requestedRol})
+
+		// WHEN
+		req := httptest.NewRequest(http.MethodPatch, "/user/"+user.Id.String()+"/role/", bytes.NewBuffer(requestBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+
+		rec := httptest.NewRecorder()
+		server.Echo.ServeHTTP(rec, req)
+
+		// THEN
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	var storedUser model.User
+	assert.NoError(t, db.First(&storedUser, "id = ?", user.Id).Error)
+	assert.Equal(t, model.UserRolClient, storedUser.Rol)
+}
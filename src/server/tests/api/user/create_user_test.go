@@ -62,7 +62,7 @@ func TestCreateUserSuccessfully(t *testing.T) {
 	assert.Equal(t, schemas.UserRol(createUserRequest.Rol), response.Rol)
 	assert.True(t, strings.HasPrefix(response.ImageUrl, createUserRequest.ImageUrl))
 	// Password should not be returned
-	// assert.Empty(t, response.Password)
+	assert.Empty(t, response.Password)
 
 	// Verify the user was created in the database
 	var dbUser model.User
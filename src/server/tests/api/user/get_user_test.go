@@ -65,8 +65,8 @@ func TestGetUserSuccessfully(t *testing.T) {
 	assert.Equal(t, testUser.Email, response.Email)
 	assert.Equal(t, schemas.UserRol(testUser.Rol), response.Rol)
 	assert.Equal(t, testUser.ImageUrl, response.ImageUrl)
-	// Password should not be returned (but currently it is, so we'll accept that for now)
-	// assert.Empty(t, response.Password)
+	// Password should not be returned
+	assert.Empty(t, response.Password)
 }
 
 func TestGetUserNotFound(t *testing.T) {
@@ -60,8 +60,8 @@ func TestUpdateUserSuccessfully(t *testing.T) {
 	assert.Equal(t, user.Id, response.Id)
 	assert.Equal(t, *updateUserRequest.Name, response.Name)
 	assert.Equal(t, *updateUserRequest.FirstLastName, response.FirstLastName)
-	// Password should not be returned (but currently it is, so we'll accept that for now)
-	// assert.Empty(t, response.Password)
+	// Password should not be returned
+	assert.Empty(t, response.Password)
 }
 
 func TestUpdateUserNotFound(t *testing.T) {
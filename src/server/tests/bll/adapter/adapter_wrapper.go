@@ -8,6 +8,7 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/bll/adapter"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 	testSetup "onichankimochi.com/astro_cat_backend/src/server/tests"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
 )
 
 type AdapterTestWrapper struct {
@@ -21,6 +22,7 @@ func newAdapterTestWrapper() *AdapterTestWrapper {
 	testLogger := logging.NewLoggerMock()
 	envSettings := schemas.NewEnvSettings(testLogger)
 	envSettings.EnableSqlLogs = false // Disable SQL logs for testing
+	utilsTest.ConfigurePiiEncryption(envSettings)
 	testAdapter, astroCatPsqlDB := adapter.NewAdapterCollection(
 		testLogger,
 		envSettings,
@@ -0,0 +1,84 @@
+package onboarding_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	adapterTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/adapter"
+)
+
+func TestOnboardingAdapter_CreatePostgresqlOnboarding_EncryptsPersonalData(t *testing.T) {
+	// GIVEN: A user without onboarding
+	onboardingAdapter, _, db := adapterTest.NewOnboardingAdapterTestWrapper(t)
+	user := factories.NewUserModel(db, factories.UserModelF{})
+	birthDate := "1990-05-15"
+	district := "Lima"
+
+	// WHEN: The onboarding is created and then looked up by its document
+	created, err := onboardingAdapter.CreatePostgresqlOnboarding(
+		user.Id,
+		schemas.DocumentTypeDNI,
+		"12345678",
+		"987654321",
+		&birthDate,
+		nil,
+		"15001",
+		&district,
+		&district,
+		&district,
+		"Av. Example 123",
+		"test_user",
+	)
+	found, findErr := onboardingAdapter.GetPostgresqlOnboardingByDocument(schemas.DocumentTypeDNI, " 12345678 ")
+
+	// THEN: The stored columns are ciphertext while the adapter returns plaintext
+	assert.Nil(t, err)
+	assert.Nil(t, findErr)
+	assert.Equal(t, created.Id, found.Id)
+	assert.Equal(t, "12345678", found.DocumentNumber)
+	assert.Equal(t, "987654321", found.PhoneNumber)
+	assert.Equal(t, "Av. Example 123", found.Address)
+	assert.Equal(t, birthDate, found.BirthDate.Format("2006-01-02"))
+
+	var stored model.Onboarding
+	assert.Nil(t, db.First(&stored, "id = ?", created.Id).Error)
+	assert.True(t, strings.HasPrefix(stored.DocumentNumber, "pii:v1:"))
+	assert.True(t, strings.HasPrefix(stored.PhoneNumber, "pii:v1:"))
+	assert.True(t, strings.HasPrefix(stored.Address, "pii:v1:"))
+	assert.True(t, strings.HasPrefix(*stored.BirthDate, "pii:v1:"))
+	assert.NotContains(t, stored.DocumentNumberIndex, "12345678")
+	assert.NotEmpty(t, stored.DocumentNumberIndex)
+}
+
+func TestOnboardingAdapter_EncryptPostgresqlOnboardings_MigratesPlaintextRows(t *testing.T) {
+	// GIVEN: An onboarding written in plaintext before encryption was enabled
+	onboardingAdapter, _, db := adapterTest.NewOnboardingAdapterTestWrapper(t)
+	documentNumber := "87654321"
+	onboarding := factories.NewOnboardingModel(db, factories.OnboardingModelF{
+		DocumentNumber: &documentNumber,
+	})
+
+	// WHEN: The migration runs twice
+	firstCount, err := onboardingAdapter.EncryptPostgresqlOnboardings(10)
+	secondCount, secondErr := onboardingAdapter.EncryptPostgresqlOnboardings(10)
+
+	// THEN: The row is encrypted once, stays readable and can be found by its document
+	assert.Nil(t, err)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, 1, firstCount)
+	assert.Equal(t, 0, secondCount)
+
+	var stored model.Onboarding
+	assert.Nil(t, db.First(&stored, "id = ?", onboarding.Id).Error)
+	assert.True(t, strings.HasPrefix(stored.DocumentNumber, "pii:v1:"))
+
+	found, findErr := onboardingAdapter.GetPostgresqlOnboardingByDocument(schemas.DocumentTypeDNI, documentNumber)
+	assert.Nil(t, findErr)
+	assert.Equal(t, onboarding.Id, found.Id)
+	assert.Equal(t, onboarding.PhoneNumber, found.PhoneNumber)
+	assert.Equal(t, *onboarding.BirthDate, found.BirthDate.Format("2006-01-02"))
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
 	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	adapterTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/adapter"
@@ -36,6 +37,7 @@ func TestCreateReservationSuccessfully(t *testing.T) {
 		user.Id,
 		session.Id,
 		&membership.Id,
+		false, // joinWaitlist
 		updatedBy,
 	)
 
@@ -78,6 +80,7 @@ func TestCreateReservationEmptyUpdatedBy(t *testing.T) {
 		user.Id,
 		session.Id,
 		&membership.Id,
+		false, // joinWaitlist
 		emptyUpdatedBy,
 	)
 
@@ -87,11 +90,11 @@ func TestCreateReservationEmptyUpdatedBy(t *testing.T) {
 	assert.Equal(t, errors.BadRequestError.InvalidUpdatedByValue.Code, err.Code)
 }
 
-func TestCreateReservationWithDifferentStates(t *testing.T) {
+func TestCreateReservationWithInvalidInitialStates(t *testing.T) {
 	/*
-		GIVEN: Valid reservation data with different states
+		GIVEN: Valid reservation data with states other than CONFIRMED
 		WHEN:  CreatePostgresqlReservation is called
-		THEN:  Reservations are created with correct states
+		THEN:  The reservations are rejected and no spot is taken
 	*/
 	// GIVEN
 	adapter, _, db := adapterTest.NewReservationAdapterTestWrapper(t)
@@ -100,7 +103,7 @@ func TestCreateReservationWithDifferentStates(t *testing.T) {
 	session := factories.NewSessionModel(db, factories.SessionModelF{})
 	membership := factories.NewMembershipModel(db, factories.MembershipModelF{})
 
-	states := []string{"PENDING", "CONFIRMED", "CANCELLED"}
+	states := []string{"PENDING", "CANCELLED", "DONE", "NO_SHOW", "ANULLED", "WAITLISTED"}
 	updatedBy := "test-admin"
 
 	for _, state := range states {
@@ -112,14 +115,19 @@ func TestCreateReservationWithDifferentStates(t *testing.T) {
 			user.Id,
 			session.Id,
 			&membership.Id,
+			false, // joinWaitlist
 			updatedBy,
 		)
 
 		// THEN
-		assert.Nil(t, err)
-		assert.NotNil(t, reservation)
-		assert.Equal(t, state, reservation.State)
+		assert.NotNil(t, err, state)
+		assert.Nil(t, reservation, state)
+		assert.Equal(t, errors.BadRequestError.InvalidReservationInitialState, *err, state)
 	}
+
+	var storedSession model.Session
+	assert.Nil(t, db.First(&storedSession, "id = ?", session.Id).Error)
+	assert.Equal(t, session.RegisteredCount, storedSession.RegisteredCount)
 }
 
 func TestCreateReservationWithPastDate(t *testing.T) {
@@ -137,7 +145,7 @@ func TestCreateReservationWithPastDate(t *testing.T) {
 
 	name := "Past Reservation"
 	reservationTime := time.Now().AddDate(0, 0, -1) // Yesterday
-	state := "COMPLETED"
+	state := "CONFIRMED"
 	updatedBy := "test-admin"
 
 	// WHEN
@@ -148,6 +156,7 @@ func TestCreateReservationWithPastDate(t *testing.T) {
 		user.Id,
 		session.Id,
 		&membership.Id,
+		false, // joinWaitlist
 		updatedBy,
 	)
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
 	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	adapterTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/adapter"
@@ -26,14 +27,15 @@ func TestUpdateReservationName(t *testing.T) {
 	updatedBy := "test-admin"
 
 	// WHEN
-	result, err := adapter.UpdatePostgresqlReservation(
+	result, _, err := adapter.UpdatePostgresqlReservation(
 		reservation.Id,
 		&newName,
-		nil, // reservationTime
-		nil, // state
-		nil, // userId
-		nil, // sessionId
-		nil, // membershipId
+		nil,   // reservationTime
+		nil,   // state
+		nil,   // userId
+		nil,   // sessionId
+		nil,   // membershipId
+		false, // forfeitCredit
 		updatedBy,
 	)
 
@@ -67,7 +69,7 @@ func TestUpdateReservationAllFields(t *testing.T) {
 	updatedBy := "test-admin"
 
 	// WHEN
-	result, err := adapter.UpdatePostgresqlReservation(
+	result, _, err := adapter.UpdatePostgresqlReservation(
 		reservation.Id,
 		&newName,
 		&newReservationTime,
@@ -75,6 +77,7 @@ func TestUpdateReservationAllFields(t *testing.T) {
 		&newUser.Id,
 		&newSession.Id,
 		&newMembership.Id,
+		false, // forfeitCredit
 		updatedBy,
 	)
 
@@ -103,14 +106,15 @@ func TestUpdateReservationEmptyUpdatedBy(t *testing.T) {
 	emptyUpdatedBy := ""
 
 	// WHEN
-	result, err := adapter.UpdatePostgresqlReservation(
+	result, _, err := adapter.UpdatePostgresqlReservation(
 		reservation.Id,
 		nil, // name
 		nil, // reservationTime
 		&newState,
-		nil, // userId
-		nil, // sessionId
-		nil, // membershipId
+		nil,   // userId
+		nil,   // sessionId
+		nil,   // membershipId
+		false, // forfeitCredit
 		emptyUpdatedBy,
 	)
 
@@ -136,14 +140,15 @@ func TestUpdateReservationSuccessfully(t *testing.T) {
 	updatedBy := "test-admin"
 
 	// WHEN
-	updatedReservation, err := adapter.UpdatePostgresqlReservation(
+	updatedReservation, _, err := adapter.UpdatePostgresqlReservation(
 		reservation.Id,
 		&newName,
 		nil, // Don't update reservation time
 		&newState,
-		nil, // Don't update user
-		nil, // Don't update session
-		nil, // Don't update membership
+		nil,   // Don't update user
+		nil,   // Don't update session
+		nil,   // Don't update membership
+		false, // forfeitCredit
 		updatedBy,
 	)
 
@@ -171,11 +176,11 @@ func TestUpdateReservationWithCompleteData(t *testing.T) {
 
 	newName := "Completely Updated Reservation"
 	newReservationTime := time.Now().AddDate(0, 0, 3)
-	newState := "RESCHEDULED"
+	newState := "CANCELLED"
 	updatedBy := "test-admin"
 
 	// WHEN
-	updatedReservation, err := adapter.UpdatePostgresqlReservation(
+	updatedReservation, _, err := adapter.UpdatePostgresqlReservation(
 		reservation.Id,
 		&newName,
 		&newReservationTime,
@@ -183,6 +188,7 @@ func TestUpdateReservationWithCompleteData(t *testing.T) {
 		&newUser.Id,
 		&newSession.Id,
 		&newMembership.Id,
+		false, // forfeitCredit
 		updatedBy,
 	)
 
@@ -199,8 +205,8 @@ func TestUpdateReservationWithCompleteData(t *testing.T) {
 
 func TestUpdateReservationStateTransitions(t *testing.T) {
 	/*
-		GIVEN: A reservation exists in the database
-		WHEN:  UpdatePostgresqlReservation is called with different states
+		GIVEN: A confirmed reservation exists in the database
+		WHEN:  UpdatePostgresqlReservation is called along declared transitions
 		THEN:  The reservation state is updated correctly
 	*/
 	// GIVEN
@@ -209,18 +215,19 @@ func TestUpdateReservationStateTransitions(t *testing.T) {
 	reservation := factories.NewReservationModel(db, factories.ReservationModelF{})
 	updatedBy := "test-admin"
 
-	states := []string{"PENDING", "CONFIRMED", "CANCELLED", "COMPLETED"}
+	states := []string{"CANCELLED", "CONFIRMED", "DONE"}
 
 	for _, state := range states {
 		// WHEN
-		updatedReservation, err := adapter.UpdatePostgresqlReservation(
+		updatedReservation, _, err := adapter.UpdatePostgresqlReservation(
 			reservation.Id,
 			nil, // Don't update name
 			nil, // Don't update time
 			&state,
-			nil, // Don't update user
-			nil, // Don't update session
-			nil, // Don't update membership
+			nil,   // Don't update user
+			nil,   // Don't update session
+			nil,   // Don't update membership
+			false, // forfeitCredit
 			updatedBy,
 		)
 
@@ -231,6 +238,77 @@ func TestUpdateReservationStateTransitions(t *testing.T) {
 	}
 }
 
+func TestUpdateReservationUndeclaredTransitions(t *testing.T) {
+	/*
+		GIVEN: A confirmed reservation exists in the database
+		WHEN:  UpdatePostgresqlReservation is called with states it has no transition to
+		THEN:  The updates are rejected and the reservation keeps its state
+	*/
+	// GIVEN
+	adapter, _, db := adapterTest.NewReservationAdapterTestWrapper(t)
+
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{})
+	updatedBy := "test-admin"
+
+	states := []string{"PENDING", "COMPLETED", "RESCHEDULED", "WAITLISTED"}
+
+	for _, state := range states {
+		// WHEN
+		updatedReservation, _, err := adapter.UpdatePostgresqlReservation(
+			reservation.Id,
+			nil, // Don't update name
+			nil, // Don't update time
+			&state,
+			nil,   // Don't update user
+			nil,   // Don't update session
+			nil,   // Don't update membership
+			false, // forfeitCredit
+			updatedBy,
+		)
+
+		// THEN
+		assert.NotNil(t, err, state)
+		assert.Nil(t, updatedReservation, state)
+		assert.Equal(t, errors.BadRequestError.InvalidReservationTransition, *err, state)
+	}
+
+	storedReservation, err := adapter.GetPostgresqlReservation(reservation.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "CONFIRMED", storedReservation.State)
+}
+
+func TestUpdateReservationFromFinalState(t *testing.T) {
+	/*
+		GIVEN: A reservation that was already attended
+		WHEN:  UpdatePostgresqlReservation is called to confirm it again
+		THEN:  The update is rejected since DONE has no transitions out of it
+	*/
+	// GIVEN
+	adapter, _, db := adapterTest.NewReservationAdapterTestWrapper(t)
+
+	doneState := model.ReservationStateDone
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{State: &doneState})
+	newState := "CONFIRMED"
+
+	// WHEN
+	updatedReservation, _, err := adapter.UpdatePostgresqlReservation(
+		reservation.Id,
+		nil, // Don't update name
+		nil, // Don't update time
+		&newState,
+		nil,   // Don't update user
+		nil,   // Don't update session
+		nil,   // Don't update membership
+		false, // forfeitCredit
+		"test-admin",
+	)
+
+	// THEN
+	assert.NotNil(t, err)
+	assert.Nil(t, updatedReservation)
+	assert.Equal(t, errors.BadRequestError.InvalidReservationTransition, *err)
+}
+
 func TestDeleteReservationSuccessfully(t *testing.T) {
 	/*
 		GIVEN: A reservation exists in the database
@@ -243,7 +321,7 @@ func TestDeleteReservationSuccessfully(t *testing.T) {
 	reservation := factories.NewReservationModel(db, factories.ReservationModelF{})
 
 	// WHEN
-	err := adapter.DeletePostgresqlReservation(reservation.Id)
+	_, err := adapter.DeletePostgresqlReservation(reservation.Id, false)
 
 	// THEN
 	assert.Nil(t, err)
@@ -267,7 +345,7 @@ func TestDeleteReservationNotFound(t *testing.T) {
 	nonExistentId := uuid.New()
 
 	// WHEN
-	err := adapter.DeletePostgresqlReservation(nonExistentId)
+	_, err := adapter.DeletePostgresqlReservation(nonExistentId, false)
 
 	// THEN
 	assert.NotNil(t, err)
@@ -293,7 +371,7 @@ func TestBulkDeleteReservationsSuccessfully(t *testing.T) {
 	}
 
 	// WHEN
-	err := adapter.BulkDeletePostgresqlReservations(reservationIds)
+	_, err := adapter.BulkDeletePostgresqlReservations(reservationIds, nil)
 
 	// THEN
 	assert.Nil(t, err)
@@ -326,9 +404,9 @@ func TestBulkDeleteReservationsWithInvalidId(t *testing.T) {
 	invalidIds := []string{"invalid-uuid", "another-invalid-id"}
 
 	// WHEN
-	err := adapter.BulkDeletePostgresqlReservations(invalidIds)
+	_, err := adapter.BulkDeletePostgresqlReservations(invalidIds, nil)
 
 	// THEN
 	assert.NotNil(t, err)
 	assert.Equal(t, errors.UnprocessableEntityError.InvalidReservationId, *err)
-}
\ No newline at end of file
+}
@@ -27,7 +27,7 @@ func TestUpdateSessionSuccessfully(t *testing.T) {
 	updatedBy := "test-admin"
 
 	// WHEN
-	updatedSession, err := adapter.UpdatePostgresqlSession(
+	updatedSession, _, _, err := adapter.UpdatePostgresqlSession(
 		session.Id,
 		&newTitle,
 		nil, // Don't update date
@@ -66,7 +66,7 @@ func TestUpdateSessionWithEmptyUpdatedBy(t *testing.T) {
 	updatedBy := ""
 
 	// WHEN
-	updatedSession, err := adapter.UpdatePostgresqlSession(
+	updatedSession, _, _, err := adapter.UpdatePostgresqlSession(
 		session.Id,
 		&newTitle,
 		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
@@ -104,7 +104,7 @@ func TestUpdateSessionWithCompleteData(t *testing.T) {
 	updatedBy := "test-admin"
 
 	// WHEN
-	updatedSession, err := adapter.UpdatePostgresqlSession(
+	updatedSession, _, _, err := adapter.UpdatePostgresqlSession(
 		session.Id,
 		&newTitle,
 		&newDate,
@@ -37,7 +37,6 @@ func TestGenerateTokenSuccessfully(t *testing.T) {
 	result, err := controller.GenerateToken(
 		testUser.Id,
 		testUser.Email,
-		testUser.Password,
 		userRoles,
 		expirationDelta,
 	)
@@ -47,7 +46,7 @@ func TestGenerateTokenSuccessfully(t *testing.T) {
 	assert.NotEmpty(t, result.AccessToken)
 	assert.NotEmpty(t, result.RefreshToken)
 	assert.Equal(t, expirationDelta, result.ExpiresIn)
-	assert.Equal(t, result.AccessToken, result.RefreshToken) // Based on implementation
+	assert.NotEqual(t, result.AccessToken, result.RefreshToken) // Refresh tokens are opaque
 }
 
 func TestGenerateTokenUserNotFound(t *testing.T) {
@@ -56,7 +55,6 @@ func TestGenerateTokenUserNotFound(t *testing.T) {
 
 	nonExistentUserId := uuid.New()
 	userEmail := "nonexistent@example.com"
-	userPassword := "password123"
 	userRoles := []string{"CLIENT"}
 	expirationDelta := time.Hour * 1
 
@@ -64,7 +62,6 @@ func TestGenerateTokenUserNotFound(t *testing.T) {
 	result, err := controller.GenerateToken(
 		nonExistentUserId,
 		userEmail,
-		userPassword,
 		userRoles,
 		expirationDelta,
 	)
@@ -101,7 +98,6 @@ func TestGenerateTokenAdminRole(t *testing.T) {
 	result, err := controller.GenerateToken(
 		testUser.Id,
 		testUser.Email,
-		testUser.Password,
 		userRoles,
 		expirationDelta,
 	)
@@ -127,7 +123,6 @@ func TestGenerateTokenMultipleRoles(t *testing.T) {
 	result, err := controller.GenerateToken(
 		testUser.Id,
 		testUser.Email,
-		testUser.Password,
 		userRoles,
 		expirationDelta,
 	)
@@ -151,7 +146,6 @@ func TestGenerateTokenShortExpiration(t *testing.T) {
 	result, err := controller.GenerateToken(
 		testUser.Id,
 		testUser.Email,
-		testUser.Password,
 		userRoles,
 		expirationDelta,
 	)
@@ -175,7 +169,6 @@ func TestGenerateTokenLongExpiration(t *testing.T) {
 	result, err := controller.GenerateToken(
 		testUser.Id,
 		testUser.Email,
-		testUser.Password,
 		userRoles,
 		expirationDelta,
 	)
@@ -199,7 +192,6 @@ func TestGenerateTokenEmptyRoles(t *testing.T) {
 	result, err := controller.GenerateToken(
 		testUser.Id,
 		testUser.Email,
-		testUser.Password,
 		userRoles,
 		expirationDelta,
 	)
@@ -223,7 +215,6 @@ func TestGenerateTokenZeroExpiration(t *testing.T) {
 	result, err := controller.GenerateToken(
 		testUser.Id,
 		testUser.Email,
-		testUser.Password,
 		userRoles,
 		expirationDelta,
 	)
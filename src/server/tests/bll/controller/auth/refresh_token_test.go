@@ -0,0 +1,137 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+func TestRefreshTokenRotatesToken(t *testing.T) {
+	/*
+		GIVEN: A refresh token issued on login
+		WHEN:  RefreshToken is called with it
+		THEN:  A new token pair is returned and the old refresh token is revoked
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewAuthControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	tokens, err := controller.GenerateToken(
+		testUser.Id,
+		testUser.Email,
+		[]string{string(testUser.Rol)},
+		time.Minute*15,
+	)
+	assert.Nil(t, err)
+
+	// WHEN
+	result, refreshErr := controller.RefreshToken(tokens.RefreshToken)
+
+	// THEN
+	assert.Nil(t, refreshErr)
+	assert.NotNil(t, result)
+	assert.NotEmpty(t, result.AccessToken)
+	assert.NotEqual(t, tokens.RefreshToken, result.RefreshToken)
+
+	var oldToken model.RefreshToken
+	db.Where("token_hash = ?", utils.HashToken(tokens.RefreshToken)).First(&oldToken)
+	assert.NotNil(t, oldToken.RevokedAt)
+	assert.NotNil(t, oldToken.ReplacedById)
+
+	var newToken model.RefreshToken
+	db.Where("token_hash = ?", utils.HashToken(result.RefreshToken)).First(&newToken)
+	assert.Equal(t, oldToken.FamilyId, newToken.FamilyId)
+	assert.Nil(t, newToken.RevokedAt)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	/*
+		GIVEN: A refresh token that has already been rotated
+		WHEN:  RefreshToken is called again with the old token
+		THEN:  A reuse error is returned and the rotated token is revoked too
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewAuthControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	tokens, _ := controller.GenerateToken(
+		testUser.Id,
+		testUser.Email,
+		[]string{string(testUser.Rol)},
+		time.Minute*15,
+	)
+	rotated, _ := controller.RefreshToken(tokens.RefreshToken)
+
+	// WHEN
+	result, err := controller.RefreshToken(tokens.RefreshToken)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.AuthenticationError.RefreshTokenReused, *err)
+
+	_, rotatedErr := controller.RefreshToken(rotated.RefreshToken)
+	assert.NotNil(t, rotatedErr)
+}
+
+func TestRefreshTokenExpired(t *testing.T) {
+	/*
+		GIVEN: A refresh token whose expiration date has passed
+		WHEN:  RefreshToken is called with it
+		THEN:  An expired refresh token error is returned
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewAuthControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	tokens, _ := controller.GenerateToken(
+		testUser.Id,
+		testUser.Email,
+		[]string{string(testUser.Rol)},
+		time.Minute*15,
+	)
+	db.Model(&model.RefreshToken{}).
+		Where("token_hash = ?", utils.HashToken(tokens.RefreshToken)).
+		Update("expires_at", time.Now().Add(-time.Hour))
+
+	// WHEN
+	result, err := controller.RefreshToken(tokens.RefreshToken)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.AuthenticationError.ExpiredRefreshToken, *err)
+}
+
+func TestRevokeRefreshTokenOnLogout(t *testing.T) {
+	/*
+		GIVEN: A refresh token issued on login
+		WHEN:  RevokeRefreshToken is called with it
+		THEN:  The token can no longer be exchanged
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewAuthControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	tokens, _ := controller.GenerateToken(
+		testUser.Id,
+		testUser.Email,
+		[]string{string(testUser.Rol)},
+		time.Minute*15,
+	)
+
+	// WHEN
+	revokeErr := controller.RevokeRefreshToken(tokens.RefreshToken)
+
+	// THEN
+	assert.Nil(t, revokeErr)
+	result, err := controller.RefreshToken(tokens.RefreshToken)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,87 @@
+package cancellation_policy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestCreateCancellationPolicyForCommunityAndService(t *testing.T) {
+	// GIVEN: A community with a service
+	controller, _, db := controllerTest.NewCancellationPolicyControllerTestWrapper(t)
+
+	communityService := factories.NewCommunityServiceModel(db)
+
+	// WHEN: A policy is created for the community and another one for its service
+	communityPolicy, communityErr := controller.CreateCancellationPolicy(
+		schemas.CreateCancellationPolicyRequest{
+			FreeCancellationHours:       24,
+			LateCancellationBurnsCredit: true,
+			CommunityId:                 communityService.CommunityId,
+		},
+		"test_admin",
+	)
+	servicePolicy, serviceErr := controller.CreateCancellationPolicy(
+		schemas.CreateCancellationPolicyRequest{
+			FreeCancellationHours: 2,
+			CommunityId:           communityService.CommunityId,
+			ServiceId:             &communityService.ServiceId,
+		},
+		"test_admin",
+	)
+
+	// THEN: Both are created with their own scope
+	assert.Nil(t, communityErr)
+	assert.Nil(t, communityPolicy.ServiceId)
+	assert.Equal(t, 24, communityPolicy.FreeCancellationHours)
+
+	assert.Nil(t, serviceErr)
+	assert.NotNil(t, servicePolicy.ServiceId)
+	assert.Equal(t, communityService.ServiceId, *servicePolicy.ServiceId)
+}
+
+func TestCreateCancellationPolicyAlreadyExists(t *testing.T) {
+	// GIVEN: A community that already has a policy
+	controller, _, db := controllerTest.NewCancellationPolicyControllerTestWrapper(t)
+
+	existing := factories.NewCancellationPolicyModel(db)
+
+	// WHEN: Another policy is created for the community
+	result, err := controller.CreateCancellationPolicy(
+		schemas.CreateCancellationPolicyRequest{
+			FreeCancellationHours: 12,
+			CommunityId:           existing.CommunityId,
+		},
+		"test_admin",
+	)
+
+	// THEN: It is rejected
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ConflictError.CancellationPolicyAlreadyExists, *err)
+}
+
+func TestCreateCancellationPolicyNegativeValues(t *testing.T) {
+	// GIVEN: A policy request with a negative window
+	controller, _, db := controllerTest.NewCancellationPolicyControllerTestWrapper(t)
+
+	community := factories.NewCommunityModel(db)
+
+	// WHEN: The policy is created
+	result, err := controller.CreateCancellationPolicy(
+		schemas.CreateCancellationPolicyRequest{
+			FreeCancellationHours: -1,
+			CommunityId:           community.Id,
+		},
+		"test_admin",
+	)
+
+	// THEN: It is rejected
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.BadRequestError.InvalidCancellationPolicy, *err)
+}
@@ -8,6 +8,7 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 	testSetup "onichankimochi.com/astro_cat_backend/src/server/tests"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
 )
 
 type ControllerTestWrapper struct {
@@ -21,6 +22,7 @@ func newControllerTestWrapper() *ControllerTestWrapper {
 	testLogger := logging.NewLoggerMock()
 	envSettings := schemas.NewEnvSettings(testLogger)
 	envSettings.EnableSqlLogs = false // Disable SQL logs for testing
+	utilsTest.ConfigurePiiEncryption(envSettings)
 	testController, astroCatPsqlDB := controller.NewControllerCollection(
 		testLogger,
 		envSettings,
@@ -92,6 +94,26 @@ func NewReservationControllerTestWrapper(
 		controllerTestWrapper.astroCatPsqlDB
 }
 
+// Create new standing booking controller wrapper
+func NewStandingBookingControllerTestWrapper(
+	t *testing.T,
+) (*controller.StandingBooking, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.StandingBooking, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new cancellation policy controller wrapper
+func NewCancellationPolicyControllerTestWrapper(
+	t *testing.T,
+) (*controller.CancellationPolicy, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.CancellationPolicy, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
 // Create new service controller wrapper
 func NewServiceControllerTestWrapper(
 	t *testing.T,
@@ -112,6 +134,26 @@ func NewSessionControllerTestWrapper(
 		controllerTestWrapper.astroCatPsqlDB
 }
 
+// Create new session series controller wrapper
+func NewSessionSeriesControllerTestWrapper(
+	t *testing.T,
+) (*controller.SessionSeries, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.SessionSeries, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new professional schedule controller wrapper
+func NewProfessionalScheduleControllerTestWrapper(
+	t *testing.T,
+) (*controller.ProfessionalSchedule, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.ProfessionalSchedule, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
 // Create new auth controller wrapper
 func NewAuthControllerTestWrapper(
 	t *testing.T,
@@ -231,3 +273,93 @@ func NewLoginControllerTestWrapper(
 	return controllerTestWrapper.testController.Login, loggerMock,
 		controllerTestWrapper.astroCatPsqlDB
 }
+
+// Create new email verification controller wrapper
+func NewEmailVerificationControllerTestWrapper(
+	t *testing.T,
+) (*controller.EmailVerification, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.EmailVerification, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new idempotency key controller wrapper
+func NewIdempotencyKeyControllerTestWrapper(
+	t *testing.T,
+) (*controller.IdempotencyKey, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.IdempotencyKey, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new rate limit controller wrapper
+func NewRateLimitControllerTestWrapper(
+	t *testing.T,
+) (*controller.RateLimit, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.RateLimit, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new two factor controller wrapper
+func NewTwoFactorControllerTestWrapper(
+	t *testing.T,
+) (*controller.TwoFactor, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.TwoFactor, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new role controller wrapper
+func NewRoleControllerTestWrapper(
+	t *testing.T,
+) (*controller.Role, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.Role, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new service account controller wrapper
+func NewServiceAccountControllerTestWrapper(
+	t *testing.T,
+) (*controller.ServiceAccount, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.ServiceAccount, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new user identity controller wrapper
+func NewUserIdentityControllerTestWrapper(
+	t *testing.T,
+) (*controller.UserIdentity, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.UserIdentity, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new login session controller wrapper
+func NewLoginSessionControllerTestWrapper(
+	t *testing.T,
+) (*controller.LoginSession, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.LoginSession, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
+
+// Create new privacy controller wrapper
+func NewPrivacyControllerTestWrapper(
+	t *testing.T,
+) (*controller.Privacy, *logging.LoggerMock, *gorm.DB) {
+	controllerTestWrapper.restartDB(t)
+	loggerMock := controllerTestWrapper.logger.(*logging.LoggerMock)
+	return controllerTestWrapper.testController.Privacy, loggerMock,
+		controllerTestWrapper.astroCatPsqlDB
+}
@@ -0,0 +1,207 @@
+package email_verification_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+// Helper function to store a verification code with a known value
+func createEmailVerification(
+	t *testing.T,
+	db *gorm.DB,
+	userId uuid.UUID,
+	code string,
+) *model.EmailVerification {
+	codeHash, err := utils.HashPassword(code)
+	assert.NoError(t, err)
+
+	emailVerification := &model.EmailVerification{
+		Id:        uuid.New(),
+		UserId:    userId,
+		CodeHash:  codeHash,
+		ExpiresAt: time.Now().Add(time.Hour),
+		AuditFields: model.AuditFields{
+			UpdatedBy: "SYSTEM",
+		},
+	}
+	assert.NoError(t, db.Create(emailVerification).Error)
+	return emailVerification
+}
+
+func TestVerifyEmailSuccessfully(t *testing.T) {
+	/*
+		GIVEN: An unverified user with an active verification code
+		WHEN:  VerifyEmail is called with the right code
+		THEN:  The user is marked as verified and the code is consumed
+	*/
+	// GIVEN
+	emailVerificationController, _, db := controllerTest.NewEmailVerificationControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	emailVerification := createEmailVerification(t, db, testUser.Id, "123456")
+
+	// WHEN
+	result, err := emailVerificationController.VerifyEmail(schemas.VerifyEmailRequest{
+		Email: testUser.Email,
+		Code:  "123456",
+	})
+
+	// THEN
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	var updatedUser model.User
+	db.First(&updatedUser, "id = ?", testUser.Id)
+	assert.NotNil(t, updatedUser.EmailVerifiedAt)
+
+	var consumedVerification model.EmailVerification
+	db.First(&consumedVerification, "id = ?", emailVerification.Id)
+	assert.NotNil(t, consumedVerification.ConsumedAt)
+}
+
+func TestVerifyEmailWithWrongCode(t *testing.T) {
+	/*
+		GIVEN: An unverified user with an active verification code
+		WHEN:  VerifyEmail is called with a wrong code
+		THEN:  An invalid code error is returned, the attempt is counted and the user stays unverified
+	*/
+	// GIVEN
+	emailVerificationController, _, db := controllerTest.NewEmailVerificationControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	emailVerification := createEmailVerification(t, db, testUser.Id, "123456")
+
+	// WHEN
+	result, err := emailVerificationController.VerifyEmail(schemas.VerifyEmailRequest{
+		Email: testUser.Email,
+		Code:  "654321",
+	})
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.EmailVerificationError.InvalidOrExpiredCode, *err)
+
+	var updatedVerification model.EmailVerification
+	db.First(&updatedVerification, "id = ?", emailVerification.Id)
+	assert.Equal(t, 1, updatedVerification.Attempts)
+
+	var updatedUser model.User
+	db.First(&updatedUser, "id = ?", testUser.Id)
+	assert.Nil(t, updatedUser.EmailVerifiedAt)
+}
+
+func TestVerifyEmailLocksAfterMaxAttempts(t *testing.T) {
+	/*
+		GIVEN: An unverified user with an active verification code
+		WHEN:  VerifyEmail is called with wrong codes until the limit is reached
+		THEN:  The verification is locked and even the right code is rejected
+	*/
+	// GIVEN
+	emailVerificationController, _, db := controllerTest.NewEmailVerificationControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	createEmailVerification(t, db, testUser.Id, "123456")
+
+	var lastErr *errors.Error
+	for i := 0; i < emailVerificationController.EnvSettings.EmailVerificationMaxAttempts; i++ {
+		_, lastErr = emailVerificationController.VerifyEmail(schemas.VerifyEmailRequest{
+			Email: testUser.Email,
+			Code:  "000000",
+		})
+	}
+
+	// WHEN
+	result, err := emailVerificationController.VerifyEmail(schemas.VerifyEmailRequest{
+		Email: testUser.Email,
+		Code:  "123456",
+	})
+
+	// THEN
+	assert.NotNil(t, lastErr)
+	assert.Equal(t, errors.TooManyRequestsError.EmailVerificationLocked, *lastErr)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestResendVerificationCodeSupersedesPreviousCode(t *testing.T) {
+	/*
+		GIVEN: An unverified user with an active verification code
+		WHEN:  ResendVerificationCode is called
+		THEN:  The previous code is consumed and a new one is stored
+	*/
+	// GIVEN
+	emailVerificationController, _, db := controllerTest.NewEmailVerificationControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	previousVerification := createEmailVerification(t, db, testUser.Id, "123456")
+
+	// WHEN
+	result, err := emailVerificationController.ResendVerificationCode(testUser.Email)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	var consumedVerification model.EmailVerification
+	db.First(&consumedVerification, "id = ?", previousVerification.Id)
+	assert.NotNil(t, consumedVerification.ConsumedAt)
+
+	var activeCount int64
+	db.Model(&model.EmailVerification{}).
+		Where("user_id = ? AND consumed_at IS NULL", testUser.Id).
+		Count(&activeCount)
+	assert.Equal(t, int64(1), activeCount)
+}
+
+func TestResendVerificationCodeAlreadyVerified(t *testing.T) {
+	/*
+		GIVEN: A user whose email is already verified
+		WHEN:  ResendVerificationCode is called
+		THEN:  The usual response is returned and no code is created
+	*/
+	// GIVEN
+	emailVerificationController, _, db := controllerTest.NewEmailVerificationControllerTestWrapper(t)
+	verifiedAt := time.Now()
+	testUser := factories.NewUserModel(db, factories.UserModelF{EmailVerifiedAt: &verifiedAt})
+
+	// WHEN
+	result, err := emailVerificationController.ResendVerificationCode(testUser.Email)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	var verificationCount int64
+	db.Model(&model.EmailVerification{}).Where("user_id = ?", testUser.Id).Count(&verificationCount)
+	assert.Equal(t, int64(0), verificationCount)
+}
+
+func TestResendVerificationCodeUnknownEmail(t *testing.T) {
+	/*
+		GIVEN: An email without an account
+		WHEN:  ResendVerificationCode is called
+		THEN:  The same response as for a registered email is returned
+	*/
+	// GIVEN
+	emailVerificationController, _, db := controllerTest.NewEmailVerificationControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+
+	// WHEN
+	knownResult, knownErr := emailVerificationController.ResendVerificationCode(testUser.Email)
+	unknownResult, unknownErr := emailVerificationController.ResendVerificationCode(
+		utilsTest.GenerateRandomEmail(),
+	)
+
+	// THEN
+	assert.Nil(t, knownErr)
+	assert.Nil(t, unknownErr)
+	assert.Equal(t, knownResult, unknownResult)
+}
@@ -2,8 +2,10 @@ package forgot_password_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
 	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
 	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
@@ -13,7 +15,7 @@ func TestGenerateResetPinSuccessfully(t *testing.T) {
 	/*
 		GIVEN: Valid user exists
 		WHEN:  GenerateResetPin is called with valid email
-		THEN:  Reset pin should be generated and returned
+		THEN:  A hashed reset pin should be stored and not returned
 	*/
 	// GIVEN
 	forgotPasswordController, _, db := controllerTest.NewForgotPasswordControllerTestWrapper(t)
@@ -27,14 +29,16 @@ func TestGenerateResetPinSuccessfully(t *testing.T) {
 	// THEN
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
-	assert.NotEmpty(t, result.Pin)
 	assert.Equal(t, "Código enviado al correo", result.Message)
-	assert.Len(t, result.Pin, 6) // PIN should be 6 digits
 
-	// Verify PIN is numeric
-	for _, char := range result.Pin {
-		assert.True(t, char >= '0' && char <= '9', "PIN should contain only digits")
-	}
+	var passwordReset model.PasswordReset
+	dbErr := db.Where("user_id = ?", testUser.Id).First(&passwordReset).Error
+	assert.NoError(t, dbErr)
+	assert.Equal(t, model.PasswordResetChannelEmail, passwordReset.Channel)
+	assert.NotEmpty(t, passwordReset.PinHash)
+	assert.Len(t, passwordReset.PinHash, 60) // bcrypt hash, never the plain PIN
+	assert.True(t, passwordReset.ExpiresAt.After(time.Now()))
+	assert.Nil(t, passwordReset.ConsumedAt)
 }
 
 func TestGenerateResetPinWithNonExistentUser(t *testing.T) {
@@ -81,7 +85,7 @@ func TestGenerateResetPinMultipleTimes(t *testing.T) {
 	/*
 		GIVEN: Valid user exists
 		WHEN:  GenerateResetPin is called multiple times for the same user
-		THEN:  Different PINs should be generated each time
+		THEN:  Only the latest PIN should remain active
 	*/
 	// GIVEN
 	forgotPasswordController, _, db := controllerTest.NewForgotPasswordControllerTestWrapper(t)
@@ -100,7 +104,10 @@ func TestGenerateResetPinMultipleTimes(t *testing.T) {
 	assert.NotNil(t, result2)
 
 	// THEN
-	assert.NotEqual(t, result1.Pin, result2.Pin, "Different PINs should be generated")
-	assert.Len(t, result1.Pin, 6)
-	assert.Len(t, result2.Pin, 6)
+	var passwordResets []model.PasswordReset
+	db.Where("user_id = ?", testUser.Id).Order("created_at asc").Find(&passwordResets)
+	assert.Len(t, passwordResets, 2)
+	assert.NotNil(t, passwordResets[0].ConsumedAt, "Previous PIN should be superseded")
+	assert.Nil(t, passwordResets[1].ConsumedAt)
+	assert.NotEqual(t, passwordResets[0].PinHash, passwordResets[1].PinHash)
 }
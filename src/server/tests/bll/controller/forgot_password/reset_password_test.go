@@ -0,0 +1,154 @@
+package forgot_password_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+// Helper function to store a reset PIN with a known value
+func createPasswordReset(t *testing.T, db *gorm.DB, userId uuid.UUID, pin string) *model.PasswordReset {
+	pinHash, err := utils.HashPassword(pin)
+	assert.NoError(t, err)
+
+	passwordReset := &model.PasswordReset{
+		Id:        uuid.New(),
+		UserId:    userId,
+		PinHash:   pinHash,
+		Channel:   model.PasswordResetChannelEmail,
+		ExpiresAt: time.Now().Add(time.Minute * 15),
+		AuditFields: model.AuditFields{
+			UpdatedBy: "SYSTEM",
+		},
+	}
+	assert.NoError(t, db.Create(passwordReset).Error)
+	return passwordReset
+}
+
+func TestResetPasswordSuccessfully(t *testing.T) {
+	/*
+		GIVEN: A user with an active reset PIN
+		WHEN:  ResetPassword is called with the right PIN
+		THEN:  The password is updated, the PIN is consumed and the token version is bumped
+	*/
+	// GIVEN
+	forgotPasswordController, _, db := controllerTest.NewForgotPasswordControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	passwordReset := createPasswordReset(t, db, testUser.Id, "123456")
+
+	// WHEN
+	result, err := forgotPasswordController.ResetPassword(schemas.ResetPasswordRequest{
+		Email:       testUser.Email,
+		Pin:         "123456",
+		NewPassword: "newPassword123",
+	})
+
+	// THEN
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	var updatedUser model.User
+	db.First(&updatedUser, "id = ?", testUser.Id)
+	assert.NoError(t, utils.CheckPasswordHash("newPassword123", updatedUser.Password))
+	assert.Equal(t, testUser.TokenVersion+1, updatedUser.TokenVersion)
+
+	var consumedReset model.PasswordReset
+	db.First(&consumedReset, "id = ?", passwordReset.Id)
+	assert.NotNil(t, consumedReset.ConsumedAt)
+}
+
+func TestResetPasswordWithWrongPin(t *testing.T) {
+	/*
+		GIVEN: A user with an active reset PIN
+		WHEN:  ResetPassword is called with a wrong PIN
+		THEN:  An invalid PIN error is returned and the attempt is counted
+	*/
+	// GIVEN
+	forgotPasswordController, _, db := controllerTest.NewForgotPasswordControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	passwordReset := createPasswordReset(t, db, testUser.Id, "123456")
+
+	// WHEN
+	result, err := forgotPasswordController.ResetPassword(schemas.ResetPasswordRequest{
+		Email:       testUser.Email,
+		Pin:         "654321",
+		NewPassword: "newPassword123",
+	})
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ForgotPasswordError.InvalidOrExpiredPin, *err)
+
+	var updatedReset model.PasswordReset
+	db.First(&updatedReset, "id = ?", passwordReset.Id)
+	assert.Equal(t, 1, updatedReset.Attempts)
+}
+
+func TestResetPasswordLocksAfterMaxAttempts(t *testing.T) {
+	/*
+		GIVEN: A user with an active reset PIN
+		WHEN:  ResetPassword is called with wrong PINs until the limit is reached
+		THEN:  The reset is locked and even the right PIN is rejected
+	*/
+	// GIVEN
+	forgotPasswordController, _, db := controllerTest.NewForgotPasswordControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	createPasswordReset(t, db, testUser.Id, "123456")
+
+	var lastErr *errors.Error
+	for i := 0; i < forgotPasswordController.EnvSettings.PasswordResetMaxAttempts; i++ {
+		_, lastErr = forgotPasswordController.ResetPassword(schemas.ResetPasswordRequest{
+			Email:       testUser.Email,
+			Pin:         "000000",
+			NewPassword: "newPassword123",
+		})
+	}
+
+	// WHEN
+	result, err := forgotPasswordController.ResetPassword(schemas.ResetPasswordRequest{
+		Email:       testUser.Email,
+		Pin:         "123456",
+		NewPassword: "newPassword123",
+	})
+
+	// THEN
+	assert.NotNil(t, lastErr)
+	assert.Equal(t, errors.TooManyRequestsError.PasswordResetLocked, *lastErr)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestResetPasswordWithExpiredPin(t *testing.T) {
+	/*
+		GIVEN: A user whose reset PIN has expired
+		WHEN:  ResetPassword is called with that PIN
+		THEN:  An invalid or expired PIN error is returned
+	*/
+	// GIVEN
+	forgotPasswordController, _, db := controllerTest.NewForgotPasswordControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	passwordReset := createPasswordReset(t, db, testUser.Id, "123456")
+	db.Model(passwordReset).Update("expires_at", time.Now().Add(-time.Minute))
+
+	// WHEN
+	result, err := forgotPasswordController.ResetPassword(schemas.ResetPasswordRequest{
+		Email:       testUser.Email,
+		Pin:         "123456",
+		NewPassword: "newPassword123",
+	})
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ForgotPasswordError.InvalidOrExpiredPin, *err)
+}
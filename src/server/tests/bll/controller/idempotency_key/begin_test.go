@@ -0,0 +1,122 @@
+package idempotency_key_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestBeginReplaysCompletedRequest(t *testing.T) {
+	/*
+		GIVEN: A key whose first request finished
+		WHEN:  Begin is called again with the same key and request
+		THEN:  The stored response is returned to be replayed
+	*/
+	// GIVEN
+	idempotencyKeyController, _, _ := controllerTest.NewIdempotencyKeyControllerTestWrapper(t)
+	key := utilsTest.GenerateRandomString(20)
+
+	stored, err := idempotencyKeyController.Begin("user:test", key, "hash")
+	assert.Nil(t, err)
+	assert.Nil(t, stored)
+	assert.Nil(t, idempotencyKeyController.Complete(
+		"user:test",
+		key,
+		http.StatusCreated,
+		"application/json",
+		[]byte(`{"id":"1"}`),
+	))
+
+	// WHEN
+	replayed, err := idempotencyKeyController.Begin("user:test", key, "hash")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.NotNil(t, replayed)
+	assert.Equal(t, http.StatusCreated, replayed.StatusCode)
+	assert.Equal(t, "application/json", replayed.ContentType)
+	assert.Equal(t, []byte(`{"id":"1"}`), replayed.ResponseBody)
+}
+
+func TestBeginRejectsReusedOrRunningKeys(t *testing.T) {
+	/*
+		GIVEN: A key whose first request is still running
+		WHEN:  Begin is called with the same key, with the same and with another request
+		THEN:  The same request is told to wait and the other one is rejected
+	*/
+	// GIVEN
+	idempotencyKeyController, _, _ := controllerTest.NewIdempotencyKeyControllerTestWrapper(t)
+	key := utilsTest.GenerateRandomString(20)
+
+	_, err := idempotencyKeyController.Begin("user:test", key, "hash")
+	assert.Nil(t, err)
+
+	// WHEN
+	_, inProgressErr := idempotencyKeyController.Begin("user:test", key, "hash")
+	_, reusedErr := idempotencyKeyController.Begin("user:test", key, "another hash")
+	otherCaller, otherCallerErr := idempotencyKeyController.Begin("user:other", key, "another hash")
+
+	// THEN
+	assert.NotNil(t, inProgressErr)
+	assert.Equal(t, errors.ConflictError.IdempotencyKeyInProgress, *inProgressErr)
+	assert.NotNil(t, reusedErr)
+	assert.Equal(t, errors.UnprocessableEntityError.IdempotencyKeyReused, *reusedErr)
+	assert.Nil(t, otherCallerErr)
+	assert.Nil(t, otherCaller)
+}
+
+func TestBeginRunsAgainAfterReleaseOrExpiration(t *testing.T) {
+	/*
+		GIVEN: A released key and a completed key whose time to live passed
+		WHEN:  Begin is called again with each of them
+		THEN:  Both requests run again
+	*/
+	// GIVEN
+	idempotencyKeyController, _, db := controllerTest.NewIdempotencyKeyControllerTestWrapper(t)
+	releasedKey := utilsTest.GenerateRandomString(20)
+	expiredKey := utilsTest.GenerateRandomString(20)
+
+	_, err := idempotencyKeyController.Begin("user:test", releasedKey, "hash")
+	assert.Nil(t, err)
+	assert.Nil(t, idempotencyKeyController.Release("user:test", releasedKey))
+
+	_, err = idempotencyKeyController.Begin("user:test", expiredKey, "hash")
+	assert.Nil(t, err)
+	assert.Nil(t, idempotencyKeyController.Complete("user:test", expiredKey, http.StatusOK, "", nil))
+	assert.Nil(t, db.Table("astro_cat_idempotency_key").
+		Where("key = ?", expiredKey).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	// WHEN
+	released, releasedErr := idempotencyKeyController.Begin("user:test", releasedKey, "another hash")
+	expired, expiredErr := idempotencyKeyController.Begin("user:test", expiredKey, "another hash")
+
+	// THEN
+	assert.Nil(t, releasedErr)
+	assert.Nil(t, released)
+	assert.Nil(t, expiredErr)
+	assert.Nil(t, expired)
+}
+
+func TestBeginInvalidKey(t *testing.T) {
+	/*
+		GIVEN: A key longer than allowed
+		WHEN:  Begin is called with it
+		THEN:  The key is rejected
+	*/
+	// GIVEN
+	idempotencyKeyController, _, _ := controllerTest.NewIdempotencyKeyControllerTestWrapper(t)
+
+	// WHEN
+	_, err := idempotencyKeyController.Begin("user:test", strings.Repeat("k", 256), "hash")
+
+	// THEN
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.UnprocessableEntityError.InvalidIdempotencyKey, *err)
+}
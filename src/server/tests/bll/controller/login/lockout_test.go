@@ -0,0 +1,118 @@
+package login_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestLoginLocksAccountAfterMaxFailedAttempts(t *testing.T) {
+	/*
+		GIVEN: A user with a known password
+		WHEN:  Login fails as many times as the max attempts
+		THEN:  The account is locked, even the right password is rejected and the lock is audited
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+
+	// WHEN
+	var lastErr *errors.Error
+	for i := 0; i < loginController.EnvSettings.LoginMaxFailedAttempts; i++ {
+		_, lastErr = loginController.Login(email, "wrongPassword")
+	}
+	result, err := loginController.Login(email, password)
+
+	// THEN
+	assert.NotNil(t, lastErr)
+	assert.Equal(t, errors.TooManyRequestsError.AccountLocked, *lastErr)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.TooManyRequestsError.AccountLocked, *err)
+
+	var lockedUser model.User
+	db.First(&lockedUser, "id = ?", testUser.Id)
+	assert.NotNil(t, lockedUser.LockedUntil)
+	assert.True(t, lockedUser.LockedUntil.After(time.Now()))
+
+	var lockEvents int64
+	db.Model(&model.AuditLog{}).
+		Where("entity_id = ? AND action = ?", testUser.Id, model.AuditActionAccountLocked).
+		Count(&lockEvents)
+	assert.Equal(t, int64(1), lockEvents)
+}
+
+func TestLoginWithExpiredLockUnlocksAccount(t *testing.T) {
+	/*
+		GIVEN: A user whose lock has already expired
+		WHEN:  Login is called with the right password
+		THEN:  The login succeeds, the failures are reset and the unlock is audited
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+	db.Model(testUser).Updates(map[string]any{
+		"failed_login_attempts": loginController.EnvSettings.LoginMaxFailedAttempts,
+		"locked_until":          time.Now().Add(-time.Minute),
+	})
+
+	// WHEN
+	result, err := loginController.Login(email, password)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	var unlockedUser model.User
+	db.First(&unlockedUser, "id = ?", testUser.Id)
+	assert.Equal(t, 0, unlockedUser.FailedLoginAttempts)
+	assert.Nil(t, unlockedUser.LockedUntil)
+
+	var unlockEvents int64
+	db.Model(&model.AuditLog{}).
+		Where("entity_id = ? AND action = ?", testUser.Id, model.AuditActionAccountUnlocked).
+		Count(&unlockEvents)
+	assert.Equal(t, int64(1), unlockEvents)
+}
+
+func TestLoginLockoutDoublesOnConsecutiveLocks(t *testing.T) {
+	/*
+		GIVEN: A user that was already locked once and whose lock expired
+		WHEN:  Login fails again as many times as the max attempts
+		THEN:  The new lock lasts twice the base lockout
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+	db.Model(testUser).Updates(map[string]any{
+		"failed_login_attempts": loginController.EnvSettings.LoginMaxFailedAttempts,
+		"locked_until":          time.Now().Add(-time.Minute),
+	})
+
+	// WHEN
+	for i := 0; i < loginController.EnvSettings.LoginMaxFailedAttempts; i++ {
+		loginController.Login(email, "wrongPassword")
+	}
+
+	// THEN
+	var lockedUser model.User
+	db.First(&lockedUser, "id = ?", testUser.Id)
+	assert.NotNil(t, lockedUser.LockedUntil)
+	expectedLockout := 2 * loginController.EnvSettings.LoginLockoutDuration
+	if expectedLockout > loginController.EnvSettings.LoginMaxLockoutDuration {
+		expectedLockout = loginController.EnvSettings.LoginMaxLockoutDuration
+	}
+	assert.WithinDuration(t, time.Now().Add(expectedLockout), *lockedUser.LockedUntil, time.Minute)
+}
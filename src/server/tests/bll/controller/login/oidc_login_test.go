@@ -0,0 +1,141 @@
+package login_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestOidcLoginCreatesUserAndLinksIdentity(t *testing.T) {
+	/*
+		GIVEN: A configured provider and an ID token with an unknown, verified email
+		WHEN:  OidcLogin is called
+		THEN:  A verified client account is created, the identity is linked and tokens are returned
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	stub := utilsTest.RegisterOidcProviderStub(t, loginController.EnvSettings, "stub")
+	email := utilsTest.GenerateRandomEmail()
+	subject := uuid.NewString()
+
+	// WHEN
+	result, err := loginController.OidcLogin(
+		context.Background(),
+		"stub",
+		stub.SignIdToken(t, subject, email, true),
+	)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Tokens.AccessToken)
+	assert.Equal(t, email, result.User.Email)
+	assert.NotNil(t, result.User.EmailVerifiedAt)
+
+	var identity model.UserIdentity
+	db.Where("provider = ? AND subject = ?", "stub", subject).First(&identity)
+	assert.Equal(t, result.User.Id, identity.UserId)
+}
+
+func TestOidcLoginFindsUserByLinkedIdentity(t *testing.T) {
+	/*
+		GIVEN: A user with a linked identity whose email at the provider changed
+		WHEN:  OidcLogin is called with a token of that identity
+		THEN:  The linked user is logged in
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	stub := utilsTest.RegisterOidcProviderStub(t, loginController.EnvSettings, "stub")
+	testUser := factories.NewUserModel(db)
+	subject := uuid.NewString()
+	loginController.Adapter.UserIdentity.CreatePostgresqlUserIdentity(
+		testUser.Id,
+		"stub",
+		subject,
+		testUser.Email,
+		"TEST",
+	)
+
+	// WHEN
+	result, err := loginController.OidcLogin(
+		context.Background(),
+		"stub",
+		stub.SignIdToken(t, subject, utilsTest.GenerateRandomEmail(), true),
+	)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, testUser.Id, result.User.Id)
+}
+
+func TestOidcLoginRejectsUnverifiedEmailOfExistingAccount(t *testing.T) {
+	/*
+		GIVEN: An existing account and an ID token with its email not verified by the provider
+		WHEN:  OidcLogin is called
+		THEN:  The login is rejected and no identity is linked
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	stub := utilsTest.RegisterOidcProviderStub(t, loginController.EnvSettings, "stub")
+	email := utilsTest.GenerateRandomEmail()
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email})
+
+	// WHEN
+	result, err := loginController.OidcLogin(
+		context.Background(),
+		"stub",
+		stub.SignIdToken(t, uuid.NewString(), email, false),
+	)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.Equal(t, errors.ForbiddenError.UnverifiedIdentityEmail, *err)
+	var count int64
+	db.Model(&model.UserIdentity{}).Where("user_id = ?", testUser.Id).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestOidcLoginRejectsInvalidTokens(t *testing.T) {
+	/*
+		GIVEN: ID tokens of another audience, expired, and of an unknown provider
+		WHEN:  OidcLogin is called with them
+		THEN:  Every login is rejected
+	*/
+	// GIVEN
+	loginController, _, _ := controllerTest.NewLoginControllerTestWrapper(t)
+	stub := utilsTest.RegisterOidcProviderStub(t, loginController.EnvSettings, "stub")
+	claims := schemas.OidcIdTokenClaims{
+		Email:         utilsTest.GenerateRandomEmail(),
+		EmailVerified: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    stub.Issuer,
+			Subject:   uuid.NewString(),
+			Audience:  jwt.ClaimStrings{"another-client"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	otherAudienceToken := stub.Sign(t, claims)
+	claims.Audience = jwt.ClaimStrings{stub.ClientId}
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	expiredToken := stub.Sign(t, claims)
+	validToken := stub.SignIdToken(t, uuid.NewString(), utilsTest.GenerateRandomEmail(), true)
+
+	// WHEN
+	_, otherAudienceErr := loginController.OidcLogin(context.Background(), "stub", otherAudienceToken)
+	_, expiredErr := loginController.OidcLogin(context.Background(), "stub", expiredToken)
+	_, unknownProviderErr := loginController.OidcLogin(context.Background(), "unknown", validToken)
+
+	// THEN
+	assert.Equal(t, errors.AuthenticationError.InvalidIdToken, *otherAudienceErr)
+	assert.Equal(t, errors.AuthenticationError.InvalidIdToken, *expiredErr)
+	assert.Equal(t, errors.ObjectNotFoundError.OidcProviderNotFound, *unknownProviderErr)
+}
@@ -62,6 +62,15 @@ func TestRegisterSuccessfully(t *testing.T) {
 	assert.Equal(t, name, createdUser.Name)
 	assert.Equal(t, email, createdUser.Email)
 	assert.Equal(t, model.UserRolClient, createdUser.Rol)
+
+	// Verify the account starts unverified with a pending verification code
+	assert.Nil(t, result.User.EmailVerifiedAt)
+	assert.Nil(t, createdUser.EmailVerifiedAt)
+	var pendingVerifications int64
+	db.Model(&model.EmailVerification{}).
+		Where("user_id = ? AND consumed_at IS NULL", createdUser.Id).
+		Count(&pendingVerifications)
+	assert.Equal(t, int64(1), pendingVerifications)
 }
 
 func TestRegisterWithoutSecondLastName(t *testing.T) {
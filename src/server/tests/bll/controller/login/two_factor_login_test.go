@@ -0,0 +1,225 @@
+package login_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+// Enables two-factor for a user and returns its secret and recovery codes. The enrolment uses the
+// code of the current step, so logins in the same test must use a later step.
+func enableTwoFactor(
+	t *testing.T,
+	loginController *controller.Login,
+	user *model.User,
+) (string, []string) {
+	enrollment, err := loginController.TwoFactor.BeginEnrollment(user.Id)
+	assert.Nil(t, err)
+	code, _ := utils.GenerateTotpCode(enrollment.Secret, utils.TotpStep(time.Now()))
+	recoveryCodes, err := loginController.TwoFactor.ConfirmEnrollment(user.Id, code)
+	assert.Nil(t, err)
+	return enrollment.Secret, recoveryCodes.RecoveryCodes
+}
+
+func TestLoginWithTwoFactorReturnsChallenge(t *testing.T) {
+	/*
+		GIVEN: A user with two-factor enabled
+		WHEN:  Login is called with the right password and then the challenge is answered
+		THEN:  The first step only returns a challenge and the second one returns the tokens
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+	secret, _ := enableTwoFactor(t, loginController, testUser)
+
+	// WHEN
+	challengeResult, challengeErr := loginController.Login(email, password)
+	code, _ := utils.GenerateTotpCode(secret, utils.TotpStep(time.Now())+1)
+	result, err := loginController.LoginWithTwoFactor(schemas.TwoFactorLoginRequest{
+		ChallengeToken: challengeResult.TwoFactorChallenge.ChallengeToken,
+		Code:           code,
+	})
+
+	// THEN
+	assert.Nil(t, challengeErr)
+	assert.Empty(t, challengeResult.Tokens.AccessToken)
+	assert.Empty(t, challengeResult.Tokens.RefreshToken)
+	assert.False(t, challengeResult.TwoFactorChallenge.EnrollmentRequired)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Tokens.AccessToken)
+	assert.NotEmpty(t, result.Tokens.RefreshToken)
+	assert.Nil(t, result.TwoFactorChallenge)
+}
+
+func TestLoginWithTwoFactorRejectsReplayedCode(t *testing.T) {
+	/*
+		GIVEN: A user with two-factor enabled that already logged in with a code
+		WHEN:  The same code is used again on a new challenge
+		THEN:  An invalid code error is returned
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+	secret, _ := enableTwoFactor(t, loginController, testUser)
+	code, _ := utils.GenerateTotpCode(secret, utils.TotpStep(time.Now())+1)
+
+	firstChallenge, _ := loginController.Login(email, password)
+	_, firstErr := loginController.LoginWithTwoFactor(schemas.TwoFactorLoginRequest{
+		ChallengeToken: firstChallenge.TwoFactorChallenge.ChallengeToken,
+		Code:           code,
+	})
+	assert.Nil(t, firstErr)
+
+	// WHEN
+	secondChallenge, _ := loginController.Login(email, password)
+	result, err := loginController.LoginWithTwoFactor(schemas.TwoFactorLoginRequest{
+		ChallengeToken: secondChallenge.TwoFactorChallenge.ChallengeToken,
+		Code:           code,
+	})
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.TwoFactorError.InvalidTwoFactorCode, *err)
+}
+
+func TestLoginWithTwoFactorRecoveryCodeIsSingleUse(t *testing.T) {
+	/*
+		GIVEN: A user with two-factor enabled and its recovery codes
+		WHEN:  A recovery code is used to log in twice
+		THEN:  The first login succeeds and the second one is rejected
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+	_, recoveryCodes := enableTwoFactor(t, loginController, testUser)
+
+	// WHEN
+	firstChallenge, _ := loginController.Login(email, password)
+	firstResult, firstErr := loginController.LoginWithTwoFactor(schemas.TwoFactorLoginRequest{
+		ChallengeToken: firstChallenge.TwoFactorChallenge.ChallengeToken,
+		RecoveryCode:   recoveryCodes[0],
+	})
+	secondChallenge, _ := loginController.Login(email, password)
+	secondResult, secondErr := loginController.LoginWithTwoFactor(schemas.TwoFactorLoginRequest{
+		ChallengeToken: secondChallenge.TwoFactorChallenge.ChallengeToken,
+		RecoveryCode:   recoveryCodes[0],
+	})
+
+	// THEN
+	assert.Nil(t, firstErr)
+	assert.NotEmpty(t, firstResult.Tokens.AccessToken)
+	assert.Nil(t, secondResult)
+	assert.NotNil(t, secondErr)
+	assert.Equal(t, errors.TwoFactorError.InvalidTwoFactorCode, *secondErr)
+}
+
+func TestLoginWithTwoFactorRejectsAccessToken(t *testing.T) {
+	/*
+		GIVEN: A user without two-factor that logged in
+		WHEN:  Its access token is used as a two-factor challenge
+		THEN:  An invalid challenge error is returned
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+	loginResult, _ := loginController.Login(email, password)
+
+	// WHEN
+	result, err := loginController.LoginWithTwoFactor(schemas.TwoFactorLoginRequest{
+		ChallengeToken: loginResult.Tokens.AccessToken,
+		Code:           "123456",
+	})
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.AuthenticationError.InvalidTwoFactorChallenge, *err)
+}
+
+func TestTwoFactorChallengeRejectedAsAccessToken(t *testing.T) {
+	/*
+		GIVEN: A user with two-factor enabled that passed the password step
+		WHEN:  Its challenge token is sent as the access token of a request
+		THEN:  The request is not authenticated
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password})
+	enableTwoFactor(t, loginController, testUser)
+	challengeResult, challengeErr := loginController.Login(email, password)
+	assert.Nil(t, challengeErr)
+
+	// WHEN
+	request := httptest.NewRequest(http.MethodGet, "/me/", nil)
+	request.Header.Set("Authorization", "Bearer "+challengeResult.TwoFactorChallenge.ChallengeToken)
+	_, credentials, err := loginController.Auth.AccessTokenValidation(
+		echo.New().NewContext(request, httptest.NewRecorder()),
+	)
+
+	// THEN
+	assert.Nil(t, credentials)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.AuthenticationError.UnauthorizedUser, *err)
+}
+
+func TestLoginRequiresTwoFactorEnrollmentForAdmins(t *testing.T) {
+	/*
+		GIVEN: An administrator without two-factor and the admin policy turned on
+		WHEN:  The administrator logs in, enrols with the challenge and answers it
+		THEN:  The login asks for enrolment and finally returns tokens and recovery codes
+	*/
+	// GIVEN
+	loginController, _, db := controllerTest.NewLoginControllerTestWrapper(t)
+	loginController.EnvSettings.RequireAdminTwoFactor = true
+	defer func() { loginController.EnvSettings.RequireAdminTwoFactor = false }()
+
+	email := utilsTest.GenerateRandomEmail()
+	password := "testPassword123"
+	adminRol := model.UserRolAdmin
+	factories.NewUserModel(db, factories.UserModelF{Email: &email, Password: &password, Rol: &adminRol})
+
+	// WHEN
+	challengeResult, challengeErr := loginController.Login(email, password)
+	challengeToken := challengeResult.TwoFactorChallenge.ChallengeToken
+	enrollment, enrollmentErr := loginController.BeginTwoFactorEnrollment(challengeToken)
+	code, _ := utils.GenerateTotpCode(enrollment.Secret, utils.TotpStep(time.Now()))
+	result, err := loginController.LoginWithTwoFactor(schemas.TwoFactorLoginRequest{
+		ChallengeToken: challengeToken,
+		Code:           code,
+	})
+
+	// THEN
+	assert.Nil(t, challengeErr)
+	assert.True(t, challengeResult.TwoFactorChallenge.EnrollmentRequired)
+	assert.Empty(t, challengeResult.Tokens.AccessToken)
+	assert.Nil(t, enrollmentErr)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Tokens.AccessToken)
+	assert.Len(t, result.RecoveryCodes, loginController.EnvSettings.TwoFactorRecoveryCodeCount)
+	assert.NotNil(t, result.User.TwoFactorEnabledAt)
+}
@@ -0,0 +1,135 @@
+package login_session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+// Logs in a user the way the login endpoints do, recording the device of the request
+func login(
+	t *testing.T,
+	sessionController *controller.LoginSession,
+	user *model.User,
+	userAgent string,
+) schemas.TokenResponse {
+	auth := controller.NewAuthController(
+		sessionController.Logger,
+		sessionController.Adapter,
+		sessionController.EnvSettings,
+	)
+	tokens, err := auth.GenerateToken(
+		user.Id,
+		user.Email,
+		[]string{string(user.Rol)},
+		sessionController.EnvSettings.AccessTokenExpiration,
+	)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/login/", nil)
+	request.Header.Set("User-Agent", userAgent)
+	sessionController.RecordDevice(echo.New().NewContext(request, httptest.NewRecorder()), tokens.SessionId)
+
+	return tokens
+}
+
+// Validates an access token the way the JWT middleware does
+func validate(sessionController *controller.LoginSession, accessToken string) *errors.Error {
+	auth := controller.NewAuthController(
+		sessionController.Logger,
+		sessionController.Adapter,
+		sessionController.EnvSettings,
+	)
+	request := httptest.NewRequest(http.MethodGet, "/me/", nil)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	_, _, err := auth.AccessTokenValidation(echo.New().NewContext(request, httptest.NewRecorder()))
+	return err
+}
+
+func TestLoginCreatesSessionWithDevice(t *testing.T) {
+	/*
+		GIVEN: A user that logs in from a browser
+		WHEN:  FetchUserSessions is called from that session
+		THEN:  The session is listed with its device and marked as current
+	*/
+	// GIVEN
+	sessionController, _, db := controllerTest.NewLoginSessionControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	tokens := login(
+		t,
+		sessionController,
+		testUser,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/126.0 Safari/537.36",
+	)
+
+	// WHEN
+	result, err := sessionController.FetchUserSessions(testUser.Id, &tokens.SessionId)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Len(t, result.Sessions, 1)
+	assert.Equal(t, tokens.SessionId, result.Sessions[0].Id)
+	assert.Equal(t, "Chrome on Windows", result.Sessions[0].Device)
+	assert.True(t, result.Sessions[0].Current)
+}
+
+func TestRevokedSessionRejectsAccessToken(t *testing.T) {
+	/*
+		GIVEN: A user logged in on two devices
+		WHEN:  One of the sessions is revoked
+		THEN:  Its access and refresh tokens stop working while the other session keeps working
+	*/
+	// GIVEN
+	sessionController, _, db := controllerTest.NewLoginSessionControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	stolenTokens := login(t, sessionController, testUser, "curl/8.0")
+	ownTokens := login(t, sessionController, testUser, "Mozilla/5.0 (iPhone) Safari/604.1")
+
+	// WHEN
+	err := sessionController.RevokeUserSession(testUser.Id, stolenTokens.SessionId, testUser.Email)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		errors.AuthenticationError.UnauthorizedUser,
+		*validate(sessionController, stolenTokens.AccessToken),
+	)
+	assert.Nil(t, validate(sessionController, ownTokens.AccessToken))
+
+	var refreshToken model.RefreshToken
+	db.Where("family_id = ?", stolenTokens.SessionId).First(&refreshToken)
+	assert.NotNil(t, refreshToken.RevokedAt)
+
+	sessions, _ := sessionController.FetchUserSessions(testUser.Id, nil)
+	assert.Len(t, sessions.Sessions, 1)
+}
+
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	/*
+		GIVEN: A session of a user
+		WHEN:  Another user tries to revoke it
+		THEN:  The session is not found and keeps working
+	*/
+	// GIVEN
+	sessionController, _, db := controllerTest.NewLoginSessionControllerTestWrapper(t)
+	owner := factories.NewUserModel(db)
+	otherUser := factories.NewUserModel(db)
+	tokens := login(t, sessionController, owner, "curl/8.0")
+
+	// WHEN
+	err := sessionController.RevokeUserSession(otherUser.Id, tokens.SessionId, otherUser.Email)
+
+	// THEN
+	assert.Equal(t, errors.ObjectNotFoundError.LoginSessionNotFound, *err)
+	assert.Nil(t, validate(sessionController, tokens.AccessToken))
+}
@@ -0,0 +1,70 @@
+package membership_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestUserMembershipsAccessDeniedForAnotherUser(t *testing.T) {
+	/*
+		GIVEN: Two clients
+		WHEN:  CheckUserMembershipsAccess is called by one of them for the other
+		THEN:  A forbidden error is returned and the attempt is audited
+	*/
+	// GIVEN
+	membershipController, _, db := controllerTest.NewMembershipControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	client := factories.NewUserModel(db, factories.UserModelF{Email: &email})
+	otherEmail := utilsTest.GenerateRandomEmail()
+	otherUser := factories.NewUserModel(db, factories.UserModelF{Email: &otherEmail})
+	credentials := &schemas.Credentials{
+		UserId:    client.Id,
+		UserEmail: client.Email,
+		UserRoles: []string{string(schemas.UserRolClient)},
+	}
+
+	// WHEN
+	err := membershipController.CheckUserMembershipsAccess(credentials, otherUser.Id, nil)
+
+	// THEN
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned, *err)
+
+	var deniedEvents int64
+	db.Model(&model.AuditLog{}).
+		Where("user_id = ? AND action = ?", client.Id, model.AuditActionAccessDenied).
+		Count(&deniedEvents)
+	assert.Equal(t, int64(1), deniedEvents)
+}
+
+func TestFilterOwnedMembershipsKeepsOnlyCallerMemberships(t *testing.T) {
+	/*
+		GIVEN: Memberships of a client and of another user
+		WHEN:  FilterOwnedMemberships is called with the credentials of the client
+		THEN:  Only the memberships of the client are kept
+	*/
+	// GIVEN
+	membershipController, _, db := controllerTest.NewMembershipControllerTestWrapper(t)
+	ownMembership := factories.NewMembershipModel(db)
+	factories.NewMembershipModel(db)
+	memberships, err := membershipController.FetchMemberships()
+	assert.Nil(t, err)
+	credentials := &schemas.Credentials{
+		UserId:    ownMembership.UserId,
+		UserRoles: []string{string(schemas.UserRolClient)},
+	}
+
+	// WHEN
+	result := membershipController.FilterOwnedMemberships(credentials, memberships)
+
+	// THEN
+	assert.Len(t, memberships.Memberships, 2)
+	assert.Len(t, result.Memberships, 1)
+	assert.Equal(t, ownMembership.Id, result.Memberships[0].Id)
+}
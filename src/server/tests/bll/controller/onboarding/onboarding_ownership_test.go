@@ -0,0 +1,59 @@
+package onboarding_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestOnboardingAccessDeniedForAnotherUser(t *testing.T) {
+	/*
+		GIVEN: The onboarding of a user and another client
+		WHEN:  CheckOnboardingAccess is called by the other client
+		THEN:  A forbidden error is returned
+	*/
+	// GIVEN
+	onboardingController, _, db := controllerTest.NewOnboardingControllerTestWrapper(t)
+	onboarding := factories.NewOnboardingModel(db)
+	email := utilsTest.GenerateRandomEmail()
+	client := factories.NewUserModel(db, factories.UserModelF{Email: &email})
+	credentials := &schemas.Credentials{
+		UserId:    client.Id,
+		UserEmail: client.Email,
+		UserRoles: []string{string(schemas.UserRolClient)},
+	}
+
+	// WHEN
+	err := onboardingController.CheckOnboardingAccess(credentials, onboarding.Id)
+
+	// THEN
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned, *err)
+}
+
+func TestOnboardingAccessAllowedForOwner(t *testing.T) {
+	/*
+		GIVEN: The onboarding of a client
+		WHEN:  CheckOnboardingAccess and CheckUserOnboardingAccess are called by that client
+		THEN:  Both are allowed
+	*/
+	// GIVEN
+	onboardingController, _, db := controllerTest.NewOnboardingControllerTestWrapper(t)
+	onboarding := factories.NewOnboardingModel(db)
+	credentials := &schemas.Credentials{
+		UserId:    onboarding.UserId,
+		UserRoles: []string{string(schemas.UserRolClient)},
+	}
+
+	// WHEN
+	onboardingErr := onboardingController.CheckOnboardingAccess(credentials, onboarding.Id)
+	userErr := onboardingController.CheckUserOnboardingAccess(credentials, onboarding.UserId)
+
+	// THEN
+	assert.Nil(t, onboardingErr)
+	assert.Nil(t, userErr)
+}
@@ -0,0 +1,115 @@
+package privacy_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestExportUserDataIncludesOwnRecords(t *testing.T) {
+	/*
+		GIVEN: A user with an onboarding and a reservation, and a reservation of another user
+		WHEN:  ExportUserData is called and the export is packed
+		THEN:  Only the records of the user are exported and the archive has one file per section
+	*/
+	// GIVEN
+	privacyController, _, db := controllerTest.NewPrivacyControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	onboarding := factories.NewOnboardingModel(db, factories.OnboardingModelF{UserId: &testUser.Id})
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{UserId: &testUser.Id})
+	factories.NewReservationModel(db)
+
+	// WHEN
+	export, err := privacyController.ExportUserData(testUser.Id, "USER")
+	archive, archiveErr := privacyController.BuildDataExportArchive(export)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, archiveErr)
+	assert.Equal(t, testUser.Id, export.User.Id)
+	assert.Equal(t, onboarding.DocumentNumber, export.Onboarding.DocumentNumber)
+	assert.Len(t, export.Reservations, 1)
+	assert.Equal(t, reservation.Id, export.Reservations[0].Id)
+
+	reader, zipErr := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.Nil(t, zipErr)
+	names := []string{}
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	assert.Contains(t, names, "user.json")
+	assert.Contains(t, names, "reservations.json")
+	assert.Contains(t, names, "audit_logs.json")
+}
+
+func TestEraseCurrentUserDataAnonymizesUserAndKeepsReservations(t *testing.T) {
+	/*
+		GIVEN: A user with an onboarding and a reservation
+		WHEN:  EraseCurrentUserData is called with the password of the user
+		THEN:  The user and the onboarding are anonymized, the reservation is kept and a second
+		       erasure is rejected
+	*/
+	// GIVEN
+	privacyController, _, db := controllerTest.NewPrivacyControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	factories.NewOnboardingModel(db, factories.OnboardingModelF{UserId: &testUser.Id})
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{UserId: &testUser.Id})
+	request := schemas.DataErasureRequest{Password: "testpassword123"}
+
+	// WHEN
+	err := privacyController.EraseCurrentUserData(testUser.Id, request)
+
+	// THEN
+	assert.Nil(t, err)
+
+	var erasedUser model.User
+	assert.Nil(t, db.First(&erasedUser, "id = ?", testUser.Id).Error)
+	assert.NotNil(t, erasedUser.ErasedAt)
+	assert.NotEqual(t, testUser.Email, erasedUser.Email)
+	assert.Empty(t, erasedUser.Password)
+	assert.Equal(t, testUser.TokenVersion+1, erasedUser.TokenVersion)
+
+	var erasedOnboarding model.Onboarding
+	assert.Nil(t, db.First(&erasedOnboarding, "user_id = ?", testUser.Id).Error)
+	assert.Empty(t, erasedOnboarding.DocumentNumber)
+	assert.Empty(t, erasedOnboarding.PhoneNumber)
+	assert.Nil(t, erasedOnboarding.BirthDate)
+
+	var keptReservation model.Reservation
+	assert.Nil(t, db.First(&keptReservation, "id = ?", reservation.Id).Error)
+	assert.Equal(t, testUser.Id, keptReservation.UserId)
+
+	secondErr := privacyController.EraseUserData(testUser.Id, "ADMIN")
+	assert.Equal(t, errors.ConflictError.UserAlreadyErased.Code, secondErr.Code)
+}
+
+func TestEraseCurrentUserDataWithWrongPassword(t *testing.T) {
+	/*
+		GIVEN: A user
+		WHEN:  EraseCurrentUserData is called with a wrong password
+		THEN:  The erasure is rejected and the user is left untouched
+	*/
+	// GIVEN
+	privacyController, _, db := controllerTest.NewPrivacyControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db)
+	request := schemas.DataErasureRequest{Password: "wrong-password"}
+
+	// WHEN
+	err := privacyController.EraseCurrentUserData(testUser.Id, request)
+
+	// THEN
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.AuthenticationError.InvalidPasswordConfirmation.Code, err.Code)
+
+	var user model.User
+	assert.Nil(t, db.First(&user, "id = ?", testUser.Id).Error)
+	assert.Nil(t, user.ErasedAt)
+	assert.Equal(t, testUser.Email, user.Email)
+}
@@ -0,0 +1,164 @@
+package professional_schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestCreateSessionDuringTimeOff(t *testing.T) {
+	// GIVEN: A professional off for the whole next Monday
+	sessionController, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+	monday := nextMondayAt(sessionController, 0, 0)
+	timeOff, err := sessionController.ProfessionalSchedule.CreateTimeOff(
+		professional.Id,
+		schemas.CreateProfessionalTimeOffRequest{StartTime: monday, EndTime: monday.AddDate(0, 0, 1)},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+
+	// WHEN: A session on that Monday is checked and created
+	request := newSessionRequest(professional.Id, nextMondayAt(sessionController, 10, 0))
+	conflicts, checkErr := sessionController.CheckConflicts(schemas.CheckConflictRequest{
+		Date:           request.Date,
+		StartTime:      request.StartTime,
+		EndTime:        request.EndTime,
+		ProfessionalId: professional.Id,
+	})
+	_, createErr := sessionController.CreateSession(request, "test_admin")
+
+	// THEN: The time off is reported as the conflict and the session is not created
+	assert.Nil(t, checkErr)
+	assert.True(t, conflicts.HasConflict)
+	assert.Len(t, conflicts.TimeOffConflicts, 1)
+	assert.Equal(t, timeOff.Id, conflicts.TimeOffConflicts[0].Id)
+	assert.NotNil(t, createErr)
+	assert.Equal(t, errors.ConflictError.ProfessionalOnTimeOff, *createErr)
+}
+
+func TestCreateTimeOffEndingBeforeStart(t *testing.T) {
+	// GIVEN: A professional
+	scheduleController, _, db := controllerTest.NewProfessionalScheduleControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+	start := time.Now().Add(24 * time.Hour)
+
+	// WHEN: A time off ending before it starts is created
+	_, err := scheduleController.CreateTimeOff(
+		professional.Id,
+		schemas.CreateProfessionalTimeOffRequest{StartTime: start, EndTime: start.Add(-time.Hour)},
+		"test_admin",
+	)
+
+	// THEN: It is rejected
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.BadRequestError.InvalidProfessionalTimeOff, *err)
+}
+
+func TestGetAvailabilityShowsUnavailableSlots(t *testing.T) {
+	// GIVEN: A professional working Monday 09:00-12:00 and 14:00-18:00, off from 15:00 to 16:00
+	sessionController, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+	_, err := sessionController.ProfessionalSchedule.UpdateWorkingHours(
+		professional.Id,
+		schemas.UpdateWorkingHoursRequest{WorkingHours: []*schemas.WorkingHours{
+			{Weekday: 1, StartTime: "09:00", EndTime: "12:00"},
+			{Weekday: 1, StartTime: "14:00", EndTime: "18:00"},
+		}},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	reason := "Dentist"
+	_, err = sessionController.ProfessionalSchedule.CreateTimeOff(
+		professional.Id,
+		schemas.CreateProfessionalTimeOffRequest{
+			StartTime: nextMondayAt(sessionController, 15, 0),
+			EndTime:   nextMondayAt(sessionController, 16, 0),
+			Reason:    &reason,
+		},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+
+	// WHEN: The availability of the professional on that Monday is requested
+	monday := nextMondayAt(sessionController, 0, 0)
+	result, err := sessionController.GetAvailability(schemas.AvailabilityRequest{
+		Date:           time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, time.UTC),
+		ProfessionalId: &professional.Id,
+	})
+
+	// THEN: The times outside the working hours and the time off are busy
+	assert.Nil(t, err)
+	assert.False(t, result.IsAvailable)
+	assert.Equal(t, []schemas.TimeSlot{
+		{Start: "00:00", End: "09:00", Title: "Outside working hours", Type: "outside_working_hours"},
+		{Start: "12:00", End: "14:00", Title: "Outside working hours", Type: "outside_working_hours"},
+		{Start: "18:00", End: "24:00", Title: "Outside working hours", Type: "outside_working_hours"},
+		{Start: "15:00", End: "16:00", Title: reason, Type: "time_off"},
+	}, result.BusySlots)
+}
+
+func TestTimeOffAccessOfProfessionals(t *testing.T) {
+	// GIVEN: A professional with a user of the same email, and another professional
+	scheduleController, _, db := controllerTest.NewProfessionalScheduleControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	professionalRol := model.UserRolProfessional
+	user := factories.NewUserModel(db, factories.UserModelF{Email: &email, Rol: &professionalRol})
+	professional := factories.NewProfessionalModel(db, factories.ProfessionalModelF{Email: &email})
+	otherEmail := utilsTest.GenerateRandomEmail()
+	otherProfessional := factories.NewProfessionalModel(db, factories.ProfessionalModelF{Email: &otherEmail})
+	credentials := &schemas.Credentials{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		UserRoles: []string{string(schemas.UserRolProfessional)},
+	}
+
+	// WHEN: The user manages the time off of both professionals
+	ownErr := scheduleController.CheckTimeOffAccess(credentials, professional.Id)
+	otherErr := scheduleController.CheckTimeOffAccess(credentials, otherProfessional.Id)
+
+	// THEN: Only their own time off is allowed, and the denied attempt is audited
+	assert.Nil(t, ownErr)
+	assert.NotNil(t, otherErr)
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned, *otherErr)
+
+	var deniedEvents int64
+	db.Model(&model.AuditLog{}).
+		Where("user_id = ? AND action = ?", user.Id, model.AuditActionAccessDenied).
+		Count(&deniedEvents)
+	assert.Equal(t, int64(1), deniedEvents)
+}
+
+func TestDeleteTimeOffOfAnotherProfessional(t *testing.T) {
+	// GIVEN: A time off of a professional
+	scheduleController, _, db := controllerTest.NewProfessionalScheduleControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+	otherProfessional := factories.NewProfessionalModel(db)
+	start := time.Now().Add(24 * time.Hour)
+	timeOff, err := scheduleController.CreateTimeOff(
+		professional.Id,
+		schemas.CreateProfessionalTimeOffRequest{StartTime: start, EndTime: start.Add(time.Hour)},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+
+	// WHEN: It is deleted through another professional, then through its own
+	otherErr := scheduleController.DeleteTimeOff(otherProfessional.Id, timeOff.Id)
+	ownErr := scheduleController.DeleteTimeOff(professional.Id, timeOff.Id)
+
+	// THEN: Only the second deletion succeeds
+	assert.NotNil(t, otherErr)
+	assert.Equal(t, errors.ObjectNotFoundError.ProfessionalTimeOffNotFound, *otherErr)
+	assert.Nil(t, ownErr)
+
+	remaining, err := scheduleController.FetchTimeOff(professional.Id)
+	assert.Nil(t, err)
+	assert.Empty(t, remaining.TimeOff)
+}
@@ -0,0 +1,131 @@
+package professional_schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+// Gets the next Monday at a time of day in the booking time zone
+func nextMondayAt(sessionController *controller.Session, hour int, minute int) time.Time {
+	day := time.Now().In(sessionController.EnvSettings.BookingTimeZone).AddDate(0, 0, 1)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
+
+func newSessionRequest(professionalId uuid.UUID, start time.Time) schemas.CreateSessionRequest {
+	return schemas.CreateSessionRequest{
+		Title:          "Pilates",
+		Date:           start,
+		StartTime:      start,
+		EndTime:        start.Add(time.Hour),
+		Capacity:       10,
+		ProfessionalId: professionalId,
+	}
+}
+
+func TestUpdateWorkingHoursNormalizesAndSorts(t *testing.T) {
+	// GIVEN: A professional and two ranges of Monday out of order
+	scheduleController, _, db := controllerTest.NewProfessionalScheduleControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+
+	// WHEN: The working hours are updated
+	result, err := scheduleController.UpdateWorkingHours(
+		professional.Id,
+		schemas.UpdateWorkingHoursRequest{WorkingHours: []*schemas.WorkingHours{
+			{Weekday: 1, StartTime: "14:00", EndTime: "18:00"},
+			{Weekday: 1, StartTime: "9:00", EndTime: "12:00"},
+		}},
+		"test_admin",
+	)
+
+	// THEN: They are stored sorted, with HH:MM times
+	assert.Nil(t, err)
+	assert.Len(t, result.WorkingHours, 2)
+
+	stored, err := scheduleController.GetWorkingHours(professional.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, []*schemas.WorkingHours{
+		{Weekday: 1, StartTime: "09:00", EndTime: "12:00"},
+		{Weekday: 1, StartTime: "14:00", EndTime: "18:00"},
+	}, stored.WorkingHours)
+}
+
+func TestUpdateWorkingHoursRejectsInvalidRanges(t *testing.T) {
+	// GIVEN: A professional
+	scheduleController, _, db := controllerTest.NewProfessionalScheduleControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+
+	invalidRanges := [][]*schemas.WorkingHours{
+		{{Weekday: 1, StartTime: "09:00", EndTime: "12:00"}, {Weekday: 1, StartTime: "11:00", EndTime: "13:00"}},
+		{{Weekday: 2, StartTime: "12:00", EndTime: "09:00"}},
+		{{Weekday: 7, StartTime: "09:00", EndTime: "12:00"}},
+		{{Weekday: 3, StartTime: "9am", EndTime: "12:00"}},
+	}
+
+	for _, workingHours := range invalidRanges {
+		// WHEN: Overlapping, reversed or malformed ranges are set
+		_, err := scheduleController.UpdateWorkingHours(
+			professional.Id,
+			schemas.UpdateWorkingHoursRequest{WorkingHours: workingHours},
+			"test_admin",
+		)
+
+		// THEN: They are rejected
+		assert.NotNil(t, err)
+		assert.Equal(t, errors.BadRequestError.InvalidWorkingHours, *err)
+	}
+}
+
+func TestCreateSessionOutsideWorkingHours(t *testing.T) {
+	// GIVEN: A professional working on Monday mornings
+	sessionController, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+	_, err := sessionController.ProfessionalSchedule.UpdateWorkingHours(
+		professional.Id,
+		schemas.UpdateWorkingHoursRequest{WorkingHours: []*schemas.WorkingHours{
+			{Weekday: 1, StartTime: "09:00", EndTime: "12:00"},
+		}},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+
+	// WHEN: Sessions are created on Monday afternoon, across the end of the range and within it
+	afternoon := newSessionRequest(professional.Id, nextMondayAt(sessionController, 14, 0))
+	_, afternoonErr := sessionController.CreateSession(afternoon, "test_admin")
+	acrossEnd := newSessionRequest(professional.Id, nextMondayAt(sessionController, 11, 30))
+	_, acrossEndErr := sessionController.CreateSession(acrossEnd, "test_admin")
+	morning := newSessionRequest(professional.Id, nextMondayAt(sessionController, 10, 0))
+	session, morningErr := sessionController.CreateSession(morning, "test_admin")
+
+	// THEN: Only the one within the working hours is created
+	assert.NotNil(t, afternoonErr)
+	assert.Equal(t, errors.ConflictError.ProfessionalOutsideWorkingHours, *afternoonErr)
+	assert.NotNil(t, acrossEndErr)
+	assert.Equal(t, errors.ConflictError.ProfessionalOutsideWorkingHours, *acrossEndErr)
+	assert.Nil(t, morningErr)
+	assert.NotNil(t, session)
+}
+
+func TestCreateSessionWithoutWorkingHours(t *testing.T) {
+	// GIVEN: A professional without working hours
+	sessionController, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+	professional := factories.NewProfessionalModel(db)
+
+	// WHEN: A session is created early in the morning
+	request := newSessionRequest(professional.Id, nextMondayAt(sessionController, 3, 0))
+	session, err := sessionController.CreateSession(request, "test_admin")
+
+	// THEN: It is created
+	assert.Nil(t, err)
+	assert.NotNil(t, session)
+}
@@ -0,0 +1,83 @@
+package rate_limit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestHitAllowsRequestsUpToTheLimit(t *testing.T) {
+	/*
+		GIVEN: A fresh rate limit key
+		WHEN:  Hit is called as many times as the limit and once more
+		THEN:  The requests within the limit pass and the extra one is rejected
+	*/
+	// GIVEN
+	rateLimitController, _, _ := controllerTest.NewRateLimitControllerTestWrapper(t)
+	key := "test:ip:" + utilsTest.GenerateRandomString(10)
+	limit := 3
+
+	// WHEN
+	var hitErrs []*errors.Error
+	for i := 0; i < limit; i++ {
+		_, err := rateLimitController.Hit(key, limit)
+		hitErrs = append(hitErrs, err)
+	}
+	counter, err := rateLimitController.Hit(key, limit)
+
+	// THEN
+	for _, hitErr := range hitErrs {
+		assert.Nil(t, hitErr)
+	}
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.TooManyRequestsError.RateLimitExceeded, *err)
+	assert.Equal(t, limit+1, counter.Count)
+	assert.True(t, counter.ExpiresAt.After(time.Now()))
+}
+
+func TestMemoryStoreRestartsExpiredWindow(t *testing.T) {
+	/*
+		GIVEN: A memory store with a key whose window has expired
+		WHEN:  Increment is called again
+		THEN:  The count restarts at one
+	*/
+	// GIVEN
+	store := controller.NewMemoryRateLimitStore()
+	store.Increment("test:ip:127.0.0.1", time.Millisecond)
+	store.Increment("test:ip:127.0.0.1", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// WHEN
+	counter, err := store.Increment("test:ip:127.0.0.1", time.Minute)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, 1, counter.Count)
+}
+
+func TestPostgresStoreSharesCountersAcrossInstances(t *testing.T) {
+	/*
+		GIVEN: Two postgres stores backed by the same database
+		WHEN:  Both count hits on the same key
+		THEN:  The count is shared between them
+	*/
+	// GIVEN
+	rateLimitController, _, _ := controllerTest.NewRateLimitControllerTestWrapper(t)
+	firstStore := controller.NewPostgresRateLimitStore(rateLimitController.Adapter)
+	secondStore := controller.NewPostgresRateLimitStore(rateLimitController.Adapter)
+	key := "test:email:" + utilsTest.GenerateRandomEmail()
+
+	// WHEN
+	_, firstErr := firstStore.Increment(key, time.Minute)
+	counter, secondErr := secondStore.Increment(key, time.Minute)
+
+	// THEN
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, 2, counter.Count)
+}
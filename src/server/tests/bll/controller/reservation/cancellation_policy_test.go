@@ -0,0 +1,231 @@
+package reservation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+// Books, through a counted membership, a session starting after `startsIn` whose community has
+// the given cancellation policy
+func bookSessionWithPolicy(
+	t *testing.T,
+	reservationController *controller.Reservation,
+	db *gorm.DB,
+	startsIn time.Duration,
+	policy factories.CancellationPolicyModelF,
+) (*schemas.Reservation, *model.Membership) {
+	startTime := time.Now().Add(startsIn)
+	endTime := startTime.Add(time.Hour)
+	session := factories.NewSessionModel(db, factories.SessionModelF{
+		Date:      &startTime,
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	})
+
+	var communityService model.CommunityService
+	assert.Nil(t, db.First(&communityService, "id = ?", *session.CommunityServiceId).Error)
+
+	policy.CommunityId = &communityService.CommunityId
+	factories.NewCancellationPolicyModel(db, policy)
+
+	user := factories.NewUserModel(db, factories.UserModelF{})
+	reservationsUsed := 0
+	membership := factories.NewMembershipModel(db, factories.MembershipModelF{
+		UserId:           &user.Id,
+		CommunityId:      &communityService.CommunityId,
+		ReservationsUsed: &reservationsUsed,
+	})
+
+	reservation, err := reservationController.CreateReservation(schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now(),
+		State:           "CONFIRMED",
+		UserId:          user.Id,
+		SessionId:       session.Id,
+		MembershipId:    &membership.Id,
+	}, "test_admin")
+	assert.Nil(t, err)
+
+	return reservation, membership
+}
+
+func getReservationsUsed(t *testing.T, db *gorm.DB, membershipId uuid.UUID) int {
+	var membership model.Membership
+	assert.Nil(t, db.First(&membership, "id = ?", membershipId).Error)
+	return *membership.ReservationsUsed
+}
+
+func TestCancelReservationLateForfeitsCredit(t *testing.T) {
+	// GIVEN: A reservation of a session starting within the free cancellation window
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, membership := bookSessionWithPolicy(
+		t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{},
+	)
+	assert.Equal(t, 1, getReservationsUsed(t, db, membership.Id))
+
+	// WHEN: The reservation is cancelled
+	cancelled := "CANCELLED"
+	result, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+		"test_admin",
+	)
+
+	// THEN: The spot is given back but the membership use is kept
+	assert.Nil(t, err)
+	assert.True(t, result.CreditForfeited)
+	assert.Equal(t, 1, getReservationsUsed(t, db, membership.Id))
+
+	var session model.Session
+	assert.Nil(t, db.First(&session, "id = ?", reservation.SessionId).Error)
+	assert.Equal(t, 0, session.RegisteredCount)
+}
+
+func TestCancelReservationEarlyRefundsCredit(t *testing.T) {
+	// GIVEN: A reservation of a session starting after the free cancellation window
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, membership := bookSessionWithPolicy(
+		t, controller, db, 48*time.Hour, factories.CancellationPolicyModelF{},
+	)
+
+	// WHEN: The reservation is cancelled
+	cancelled := "CANCELLED"
+	result, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+		"test_admin",
+	)
+
+	// THEN: The membership use is given back
+	assert.Nil(t, err)
+	assert.False(t, result.CreditForfeited)
+	assert.Equal(t, 0, getReservationsUsed(t, db, membership.Id))
+}
+
+func TestConfirmLateCancelledReservationReusesCredit(t *testing.T) {
+	// GIVEN: A reservation cancelled late, which kept its membership use
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, membership := bookSessionWithPolicy(
+		t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{},
+	)
+	cancelled := "CANCELLED"
+	_, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+
+	// WHEN: The reservation is confirmed again
+	confirmed := "CONFIRMED"
+	result, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &confirmed},
+		"test_admin",
+	)
+
+	// THEN: The kept use is spent on it instead of a new one
+	assert.Nil(t, err)
+	assert.False(t, result.CreditForfeited)
+	assert.Equal(t, 1, getReservationsUsed(t, db, membership.Id))
+}
+
+func TestDeleteReservationLateForfeitsCredit(t *testing.T) {
+	// GIVEN: A reservation of a session starting within the free cancellation window
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, membership := bookSessionWithPolicy(
+		t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{},
+	)
+
+	// WHEN: The reservation is deleted
+	err := controller.DeleteReservation(reservation.Id)
+
+	// THEN: The membership use is kept
+	assert.Nil(t, err)
+	assert.Equal(t, 1, getReservationsUsed(t, db, membership.Id))
+}
+
+func TestPreviewReservationCancellation(t *testing.T) {
+	// GIVEN: A reservation of a session starting within the free cancellation window
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	freeCancellationHours := 24
+	reservation, _ := bookSessionWithPolicy(
+		t, controller, db, 2*time.Hour,
+		factories.CancellationPolicyModelF{FreeCancellationHours: &freeCancellationHours},
+	)
+
+	// WHEN: The user checks what cancelling would cost
+	preview, err := controller.PreviewCancellation(reservation.Id)
+
+	// THEN: The cancellation is late and would keep the credit
+	assert.Nil(t, err)
+	assert.NotNil(t, preview.Policy)
+	assert.True(t, preview.IsLateCancellation)
+	assert.True(t, preview.CreditWouldBeForfeited)
+	assert.NotNil(t, preview.FreeCancellationUntil)
+	assert.WithinDuration(
+		t,
+		reservation.Session.StartTime.Add(-24*time.Hour),
+		*preview.FreeCancellationUntil,
+		time.Second,
+	)
+}
+
+func TestCreateReservationBannedAfterNoShows(t *testing.T) {
+	// GIVEN: A user who missed two recent sessions of a community whose policy bans after two
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession, communityService := newCommunitySession(t, db)
+
+	noShowLimit := 2
+	noShowWindowDays := 30
+	factories.NewCancellationPolicyModel(db, factories.CancellationPolicyModelF{
+		NoShowLimit:      &noShowLimit,
+		NoShowWindowDays: &noShowWindowDays,
+		CommunityId:      &communityService.CommunityId,
+	})
+
+	noShow := model.ReservationStateNoShow
+	for range noShowLimit {
+		missedStart := time.Now().AddDate(0, 0, -3)
+		missedSession := factories.NewSessionModel(db, factories.SessionModelF{
+			StartTime:          &missedStart,
+			CommunityServiceId: &communityService.Id,
+		})
+		factories.NewReservationModel(db, factories.ReservationModelF{
+			State:     &noShow,
+			UserId:    &testUser.Id,
+			SessionId: &missedSession.Id,
+		})
+	}
+
+	// WHEN: The user books another session of the community
+	result, err := controller.CreateReservation(schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now(),
+		State:           "CONFIRMED",
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}, "test_admin")
+
+	// THEN: The booking is rejected
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ForbiddenError.BookingSuspendedForNoShows, *err)
+}
@@ -0,0 +1,198 @@
+package reservation_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+// Signs a check-in token for a reservation the way its QR code carries it
+func signCheckInToken(
+	t *testing.T,
+	reservationController *controller.Reservation,
+	reservationId uuid.UUID,
+	audience string,
+	expiresAt time.Time,
+) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &schemas.CheckInClaims{
+		ReservationId: reservationId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(reservationController.EnvSettings.TokenSignatureKey)
+	assert.Nil(t, err)
+
+	return token
+}
+
+func TestCheckInReservationMarksDone(t *testing.T) {
+	// GIVEN: A confirmed reservation of an upcoming session and its check-in token
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, _ := bookSessionWithPolicy(t, controller, db, 30*time.Minute, factories.CancellationPolicyModelF{})
+	token := signCheckInToken(t, controller, reservation.Id, schemas.CheckInTokenAudience, time.Now().Add(time.Minute))
+
+	// WHEN: The token is scanned twice
+	checkedIn, err := controller.CheckIn(token, "front_desk")
+	_, secondErr := controller.CheckIn(token, "front_desk")
+
+	// THEN: The reservation is marked as DONE with the check-in time, and only once
+	assert.Nil(t, err)
+	assert.Equal(t, "DONE", checkedIn.State)
+	assert.NotNil(t, checkedIn.CheckedInAt)
+
+	assert.NotNil(t, secondErr)
+	assert.Equal(t, errors.ConflictError.ReservationAlreadyCheckedIn, *secondErr)
+}
+
+func TestCheckInReservationRejectsInvalidTokens(t *testing.T) {
+	// GIVEN: A confirmed reservation and tokens that must not check it in
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, _ := bookSessionWithPolicy(t, controller, db, 30*time.Minute, factories.CancellationPolicyModelF{})
+
+	cases := map[string]string{
+		"expired": signCheckInToken(
+			t,
+			controller,
+			reservation.Id,
+			schemas.CheckInTokenAudience,
+			time.Now().Add(-time.Minute),
+		),
+		"another audience": signCheckInToken(
+			t,
+			controller,
+			reservation.Id,
+			schemas.TwoFactorChallengeAudience,
+			time.Now().Add(time.Minute),
+		),
+		"malformed": "not-a-token",
+	}
+
+	for name, token := range cases {
+		t.Run(name, func(t *testing.T) {
+			// WHEN: The token is scanned
+			result, err := controller.CheckIn(token, "front_desk")
+
+			// THEN: The check-in is rejected
+			assert.Nil(t, result)
+			assert.NotNil(t, err)
+			assert.Equal(t, errors.BadRequestError.InvalidCheckInToken, *err)
+		})
+	}
+
+	stored, err := controller.GetReservation(reservation.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "CONFIRMED", stored.State)
+}
+
+func TestGetCheckInQrCode(t *testing.T) {
+	// GIVEN: A confirmed reservation of an upcoming session and one of a session already over
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	upcoming, _ := bookSessionWithPolicy(t, controller, db, 30*time.Minute, factories.CancellationPolicyModelF{})
+	over, _ := bookSessionWithPolicy(t, controller, db, -2*time.Hour, factories.CancellationPolicyModelF{})
+
+	// WHEN: Their QR codes are requested
+	pngImage, pngType, pngErr := controller.GetCheckInQrCode(upcoming.Id, "png")
+	svgImage, svgType, svgErr := controller.GetCheckInQrCode(upcoming.Id, "svg")
+	_, _, formatErr := controller.GetCheckInQrCode(upcoming.Id, "gif")
+	_, _, closedErr := controller.GetCheckInQrCode(over.Id, "png")
+
+	// THEN: The upcoming one is rendered in each format and the other is closed
+	assert.Nil(t, pngErr)
+	assert.Equal(t, "image/png", pngType)
+	assert.True(t, bytes.HasPrefix(pngImage, []byte("\x89PNG")))
+
+	assert.Nil(t, svgErr)
+	assert.Equal(t, "image/svg+xml", svgType)
+	assert.True(t, bytes.HasPrefix(svgImage, []byte("<svg")))
+
+	assert.NotNil(t, formatErr)
+	assert.Equal(t, errors.UnprocessableEntityError.InvalidQrCodeFormat, *formatErr)
+
+	assert.NotNil(t, closedErr)
+	assert.Equal(t, errors.BadRequestError.CheckInClosed, *closedErr)
+}
+
+func TestMarkNoShows(t *testing.T) {
+	// GIVEN: Confirmed reservations of ended sessions, one under a policy that burns the credit of
+	// no-shows, one under a policy that does not and one that was checked in before the end
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	burnsCredit := false
+	burned, burnedMembership := bookSessionWithPolicy(t, controller, db, -2*time.Hour, factories.CancellationPolicyModelF{})
+	refunded, refundedMembership := bookSessionWithPolicy(t, controller, db, -2*time.Hour, factories.CancellationPolicyModelF{
+		NoShowBurnsCredit: &burnsCredit,
+	})
+	attended, _ := bookSessionWithPolicy(t, controller, db, -2*time.Hour, factories.CancellationPolicyModelF{})
+	checkedInAt := time.Now().Add(-90 * time.Minute)
+	assert.Nil(t, db.Table("astro_cat_reservation").
+		Where("id = ?", attended.Id).
+		Updates(map[string]any{"state": "DONE", "checked_in_at": checkedInAt}).Error)
+
+	// WHEN: The end-of-session pass runs twice
+	sweep, err := controller.MarkNoShows(time.Now())
+	secondSweep, secondErr := controller.MarkNoShows(time.Now())
+
+	// THEN: Only the reservations never checked in are marked, once, keeping the credit as the
+	// policy says
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{burned.Id, refunded.Id}, sweep.Reservations)
+	assert.Equal(t, 1, sweep.CreditsForfeited)
+
+	assert.Nil(t, secondErr)
+	assert.Empty(t, secondSweep.Reservations)
+
+	noShow, getErr := controller.GetReservation(burned.Id)
+	assert.Nil(t, getErr)
+	assert.Equal(t, "NO_SHOW", noShow.State)
+	assert.True(t, noShow.CreditForfeited)
+	assert.Equal(t, 1, getReservationsUsed(t, db, burnedMembership.Id))
+	assert.Equal(t, 0, getReservationsUsed(t, db, refundedMembership.Id))
+
+	stillDone, getErr := controller.GetReservation(attended.Id)
+	assert.Nil(t, getErr)
+	assert.Equal(t, "DONE", stillDone.State)
+}
+
+func TestCompleteReservations(t *testing.T) {
+	// GIVEN: A confirmed reservation of a completed session and another of a session still to come
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	completedState := model.SessionStateCompleted
+	completedSession := factories.NewSessionModel(db, factories.SessionModelF{State: &completedState})
+	attended := factories.NewReservationModel(db, factories.ReservationModelF{SessionId: &completedSession.Id})
+	upcoming := factories.NewReservationModel(db)
+
+	// WHEN: The end-of-session pass runs twice
+	completed, err := controller.CompleteReservations()
+	secondCompleted, secondErr := controller.CompleteReservations()
+
+	// THEN: Only the reservation of the completed session is marked DONE, once
+	assert.Nil(t, err)
+	assert.Contains(t, completed, attended.Id)
+	assert.NotContains(t, completed, upcoming.Id)
+
+	assert.Nil(t, secondErr)
+	assert.NotContains(t, secondCompleted, attended.Id)
+
+	done, getErr := controller.GetReservation(attended.Id)
+	assert.Nil(t, getErr)
+	assert.Equal(t, "DONE", done.State)
+
+	stillConfirmed, getErr := controller.GetReservation(upcoming.Id)
+	assert.Nil(t, getErr)
+	assert.Equal(t, "CONFIRMED", stillConfirmed.State)
+}
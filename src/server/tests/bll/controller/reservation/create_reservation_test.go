@@ -1,12 +1,15 @@
 package reservation_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
 	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
 )
@@ -331,3 +334,246 @@ func TestCreateReservationUserDifferentTimes(t *testing.T) {
 	assert.Equal(t, createRequest2.UserId, result.UserId)
 	assert.Equal(t, createRequest2.SessionId, result.SessionId)
 }
+
+func TestCreateReservationUnverifiedEmailBlockedByPolicy(t *testing.T) {
+	// GIVEN: The verified email policy is enabled and the user has not verified their email
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	controller.EnvSettings.RequireVerifiedEmail = true
+	defer func() { controller.EnvSettings.RequireVerifiedEmail = false }()
+
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{})
+
+	createRequest := schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}
+
+	// WHEN: CreateReservation is called
+	result, err := controller.CreateReservation(createRequest, "test_admin")
+
+	// THEN: The reservation is rejected until the email is verified
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ForbiddenError.EmailNotVerified, *err)
+}
+
+func TestCreateReservationVerifiedEmailAllowedByPolicy(t *testing.T) {
+	// GIVEN: The verified email policy is enabled and the user has verified their email
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	controller.EnvSettings.RequireVerifiedEmail = true
+	defer func() { controller.EnvSettings.RequireVerifiedEmail = false }()
+
+	verifiedAt := time.Now()
+	testUser := factories.NewUserModel(db, factories.UserModelF{EmailVerifiedAt: &verifiedAt})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{})
+
+	createRequest := schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}
+
+	// WHEN: CreateReservation is called
+	result, err := controller.CreateReservation(createRequest, "test_admin")
+
+	// THEN: The reservation is created
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+}
+
+func TestCreateReservationFullSession(t *testing.T) {
+	// GIVEN: A session whose spots are all taken
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	capacity := 2
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &capacity,
+		Capacity:        &capacity,
+	})
+
+	createRequest := schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		State:           "CONFIRMED",
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}
+
+	// WHEN: CreateReservation is called
+	result, err := controller.CreateReservation(createRequest, "test_admin")
+
+	// THEN: The booking is rejected and the session counter is left as it was
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ConflictError.SessionFull, *err)
+
+	var session model.Session
+	assert.Nil(t, db.First(&session, "id = ?", testSession.Id).Error)
+	assert.Equal(t, capacity, session.RegisteredCount)
+}
+
+func TestCreateReservationRejectsOtherInitialStates(t *testing.T) {
+	// GIVEN: Requests asking for a reservation in states only reached through transitions
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{})
+
+	for _, state := range []string{"DONE", "NO_SHOW", "ANULLED", "WAITLISTED", "CANCELLED", "PENDING"} {
+		testUser := factories.NewUserModel(db, factories.UserModelF{})
+		createRequest := schemas.CreateReservationRequest{
+			Name:            "Test Reservation",
+			ReservationTime: time.Now().Add(24 * time.Hour),
+			State:           state,
+			UserId:          testUser.Id,
+			SessionId:       testSession.Id,
+		}
+
+		// WHEN: CreateReservation is called
+		result, err := controller.CreateReservation(createRequest, "test_admin")
+
+		// THEN: The request is rejected
+		assert.Nil(t, result, state)
+		assert.NotNil(t, err, state)
+		assert.Equal(t, errors.BadRequestError.InvalidReservationInitialState, *err, state)
+	}
+
+	// THEN: No spot of the session was taken
+	var session model.Session
+	assert.Nil(t, db.First(&session, "id = ?", testSession.Id).Error)
+	assert.Equal(t, testSession.RegisteredCount, session.RegisteredCount)
+}
+
+func TestCreateReservationDefaultsToConfirmed(t *testing.T) {
+	// GIVEN: A request that does not give a state
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{})
+
+	createRequest := schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}
+
+	// WHEN: CreateReservation is called
+	result, err := controller.CreateReservation(createRequest, "test_admin")
+
+	// THEN: The reservation is confirmed
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "CONFIRMED", result.State)
+}
+
+func TestCreateReservationStartedSession(t *testing.T) {
+	// GIVEN: A session that already started
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	startTime := time.Now().Add(-10 * time.Minute)
+	endTime := time.Now().Add(50 * time.Minute)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	})
+
+	createRequest := schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now(),
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}
+
+	// WHEN: CreateReservation is called
+	result, err := controller.CreateReservation(createRequest, "test_admin")
+
+	// THEN: The booking is rejected
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.BadRequestError.ReservationSessionStarted, *err)
+}
+
+func TestCreateReservationCancelledSession(t *testing.T) {
+	// GIVEN: A session that was cancelled
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	cancelledState := model.SessionStateCancelled
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		State: &cancelledState,
+	})
+
+	createRequest := schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}
+
+	// WHEN: CreateReservation is called
+	result, err := controller.CreateReservation(createRequest, "test_admin")
+
+	// THEN: The booking is rejected
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.BadRequestError.ReservationSessionCancelled, *err)
+}
+
+func TestCreateReservationConcurrentLastSpot(t *testing.T) {
+	// GIVEN: A session with a single spot left and several users booking it at once
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	registeredCount := 4
+	capacity := 5
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &registeredCount,
+		Capacity:        &capacity,
+	})
+
+	requests := []schemas.CreateReservationRequest{}
+	for range 5 {
+		testUser := factories.NewUserModel(db, factories.UserModelF{})
+		requests = append(requests, schemas.CreateReservationRequest{
+			Name:            "Test Reservation",
+			ReservationTime: time.Now().Add(24 * time.Hour),
+			State:           "CONFIRMED",
+			UserId:          testUser.Id,
+			SessionId:       testSession.Id,
+		})
+	}
+
+	// WHEN: CreateReservation is called concurrently
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created, rejected := 0, 0
+	for _, request := range requests {
+		wg.Add(1)
+		go func(request schemas.CreateReservationRequest) {
+			defer wg.Done()
+			_, err := controller.CreateReservation(request, "test_admin")
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				created++
+			} else if err.Code == errors.ConflictError.SessionFull.Code {
+				rejected++
+			}
+		}(request)
+	}
+	wg.Wait()
+
+	// THEN: Only one booking gets the spot and the session never goes over its capacity
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 4, rejected)
+
+	var session model.Session
+	assert.Nil(t, db.First(&session, "id = ?", testSession.Id).Error)
+	assert.Equal(t, capacity, session.RegisteredCount)
+}
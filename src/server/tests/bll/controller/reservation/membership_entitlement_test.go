@@ -0,0 +1,168 @@
+package reservation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+// Creates a session together with the community it belongs to
+func newCommunitySession(t *testing.T, db *gorm.DB) (*model.Session, *model.CommunityService) {
+	session := factories.NewSessionModel(db, factories.SessionModelF{})
+
+	var communityService model.CommunityService
+	assert.Nil(t, db.First(&communityService, "id = ?", *session.CommunityServiceId).Error)
+
+	return session, &communityService
+}
+
+func TestCreateReservationPicksBestMembership(t *testing.T) {
+	// GIVEN: A user with two eligible memberships of the community of a session, one ending sooner
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession, communityService := newCommunitySession(t, db)
+
+	laterEnd := time.Now().AddDate(0, 2, 0)
+	soonerEnd := time.Now().AddDate(0, 0, 7)
+	factories.NewMembershipModel(db, factories.MembershipModelF{
+		UserId:      &testUser.Id,
+		CommunityId: &communityService.CommunityId,
+		EndDate:     &laterEnd,
+	})
+	soonerMembership := factories.NewMembershipModel(db, factories.MembershipModelF{
+		UserId:      &testUser.Id,
+		CommunityId: &communityService.CommunityId,
+		EndDate:     &soonerEnd,
+	})
+
+	// WHEN: The user books the session without choosing a membership
+	result, err := controller.CreateReservation(schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		State:           "CONFIRMED",
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}, "test_admin")
+
+	// THEN: The membership ending sooner is used
+	assert.Nil(t, err)
+	assert.NotNil(t, result.MembershipId)
+	assert.Equal(t, soonerMembership.Id, *result.MembershipId)
+}
+
+func TestCreateReservationMembershipEntitlementFailures(t *testing.T) {
+	// GIVEN: Memberships that fail each entitlement check for a session
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	otherUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession, communityService := newCommunitySession(t, db)
+
+	reservationLimit := 3
+	plan := factories.NewPlanModel(db, factories.PlanModelF{ReservationLimit: &reservationLimit})
+	suspended := model.MembershipStatusSuspended
+	pastStart := time.Now().AddDate(0, -2, 0)
+	pastEnd := time.Now().AddDate(0, -1, 0)
+
+	cases := []struct {
+		name       string
+		membership factories.MembershipModelF
+		expected   errors.Error
+	}{
+		{
+			name: "another user",
+			membership: factories.MembershipModelF{
+				UserId:      &otherUser.Id,
+				CommunityId: &communityService.CommunityId,
+			},
+			expected: errors.ForbiddenError.MembershipOfAnotherUser,
+		},
+		{
+			name:       "another community",
+			membership: factories.MembershipModelF{UserId: &testUser.Id},
+			expected:   errors.ForbiddenError.MembershipCommunityMismatch,
+		},
+		{
+			name: "not active",
+			membership: factories.MembershipModelF{
+				UserId:      &testUser.Id,
+				CommunityId: &communityService.CommunityId,
+				Status:      &suspended,
+			},
+			expected: errors.ForbiddenError.MembershipNotActive,
+		},
+		{
+			name: "out of period",
+			membership: factories.MembershipModelF{
+				UserId:      &testUser.Id,
+				CommunityId: &communityService.CommunityId,
+				StartDate:   &pastStart,
+				EndDate:     &pastEnd,
+			},
+			expected: errors.ForbiddenError.MembershipOutOfPeriod,
+		},
+		{
+			name: "limit reached",
+			membership: factories.MembershipModelF{
+				UserId:           &testUser.Id,
+				CommunityId:      &communityService.CommunityId,
+				PlanId:           &plan.Id,
+				ReservationsUsed: &reservationLimit,
+			},
+			expected: errors.ForbiddenError.MembershipReservationLimitReached,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			membership := factories.NewMembershipModel(db, tc.membership)
+
+			// WHEN: The user books the session with that membership
+			result, err := controller.CreateReservation(schemas.CreateReservationRequest{
+				Name:            "Test Reservation",
+				ReservationTime: time.Now().Add(24 * time.Hour),
+				State:           "CONFIRMED",
+				UserId:          testUser.Id,
+				SessionId:       testSession.Id,
+				MembershipId:    &membership.Id,
+			}, "test_admin")
+
+			// THEN: The booking is rejected with the error of the failed check
+			assert.Nil(t, result)
+			assert.NotNil(t, err)
+			assert.Equal(t, tc.expected, *err)
+		})
+	}
+}
+
+func TestCreateReservationWithoutMembershipBlockedByPolicy(t *testing.T) {
+	// GIVEN: The membership policy is enabled and the user has no membership of the community
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	controller.EnvSettings.RequireMembershipForBooking = true
+	defer func() { controller.EnvSettings.RequireMembershipForBooking = false }()
+
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession, _ := newCommunitySession(t, db)
+
+	// WHEN: The user books the session
+	result, err := controller.CreateReservation(schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		State:           "CONFIRMED",
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}, "test_admin")
+
+	// THEN: The booking is rejected
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ForbiddenError.NoEligibleMembership, *err)
+}
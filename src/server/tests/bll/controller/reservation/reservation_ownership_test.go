@@ -0,0 +1,191 @@
+package reservation_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func newClientCredentials(user *model.User) *schemas.Credentials {
+	return &schemas.Credentials{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		UserRoles: []string{string(schemas.UserRolClient)},
+	}
+}
+
+func TestReservationAccessDeniedForAnotherUser(t *testing.T) {
+	/*
+		GIVEN: A reservation of a user and another client
+		WHEN:  CheckReservationAccess is called with the credentials of the other client
+		THEN:  A forbidden error is returned and the attempt is audited
+	*/
+	// GIVEN
+	reservationController, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	reservation := factories.NewReservationModel(db)
+	email := utilsTest.GenerateRandomEmail()
+	otherUser := factories.NewUserModel(db, factories.UserModelF{Email: &email})
+
+	// WHEN
+	err := reservationController.CheckReservationAccess(
+		newClientCredentials(otherUser),
+		reservation.Id,
+	)
+
+	// THEN
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned, *err)
+
+	var deniedEvents int64
+	db.Model(&model.AuditLog{}).
+		Where(
+			"user_id = ? AND entity_id = ? AND action = ?",
+			otherUser.Id,
+			reservation.Id,
+			model.AuditActionAccessDenied,
+		).
+		Count(&deniedEvents)
+	assert.Equal(t, int64(1), deniedEvents)
+}
+
+func TestReservationAccessAllowedForOwnerAndAdmin(t *testing.T) {
+	/*
+		GIVEN: A reservation of a user
+		WHEN:  CheckReservationAccess is called by its owner and by an administrator
+		THEN:  Both are allowed
+	*/
+	// GIVEN
+	reservationController, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	reservation := factories.NewReservationModel(db)
+	var owner model.User
+	db.First(&owner, "id = ?", reservation.UserId)
+	adminEmail := utilsTest.GenerateRandomEmail()
+	adminRol := model.UserRolAdmin
+	admin := factories.NewUserModel(db, factories.UserModelF{Email: &adminEmail, Rol: &adminRol})
+	adminCredentials := &schemas.Credentials{
+		UserId:    admin.Id,
+		UserEmail: admin.Email,
+		UserRoles: []string{string(schemas.UserRolAdmin)},
+	}
+
+	// WHEN
+	ownerErr := reservationController.CheckReservationAccess(
+		newClientCredentials(&owner),
+		reservation.Id,
+	)
+	adminErr := reservationController.CheckReservationAccess(adminCredentials, reservation.Id)
+
+	// THEN
+	assert.Nil(t, ownerErr)
+	assert.Nil(t, adminErr)
+}
+
+func TestCreateReservationAccessDeniedWithMembershipOfAnotherUser(t *testing.T) {
+	/*
+		GIVEN: A client and a membership of another user
+		WHEN:  CheckCreateReservationAccess is called to book for themselves with that membership
+		THEN:  A forbidden error is returned
+	*/
+	// GIVEN
+	reservationController, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	membership := factories.NewMembershipModel(db)
+	email := utilsTest.GenerateRandomEmail()
+	client := factories.NewUserModel(db, factories.UserModelF{Email: &email})
+
+	// WHEN
+	err := reservationController.CheckCreateReservationAccess(
+		newClientCredentials(client),
+		schemas.CreateReservationRequest{
+			UserId:       client.Id,
+			MembershipId: &membership.Id,
+		},
+	)
+
+	// THEN
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned, *err)
+}
+
+func TestRestrictReservationUserIdsDefaultsToCaller(t *testing.T) {
+	/*
+		GIVEN: A client
+		WHEN:  RestrictReservationUserIds is called without user filter and with another user
+		THEN:  The listing is limited to the client and filtering by another user is denied
+	*/
+	// GIVEN
+	reservationController, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	email := utilsTest.GenerateRandomEmail()
+	client := factories.NewUserModel(db, factories.UserModelF{Email: &email})
+	otherEmail := utilsTest.GenerateRandomEmail()
+	otherUser := factories.NewUserModel(db, factories.UserModelF{Email: &otherEmail})
+	credentials := newClientCredentials(client)
+
+	// WHEN
+	ownUserIds, ownErr := reservationController.RestrictReservationUserIds(credentials, []string{})
+	otherUserIds, otherErr := reservationController.RestrictReservationUserIds(
+		credentials,
+		[]string{otherUser.Id.String()},
+	)
+
+	// THEN
+	assert.Nil(t, ownErr)
+	assert.Equal(t, []string{client.Id.String()}, ownUserIds)
+	assert.Nil(t, otherUserIds)
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned, *otherErr)
+}
+
+func TestReservationAccessAllowedForStaff(t *testing.T) {
+	/*
+		GIVEN: A reservation of a user, a front desk user and a user that manages another community
+		WHEN:  They check access to the reservation and book a walk-in for its user
+		THEN:  The front desk is allowed everywhere and the community manager is denied outside
+		       their community
+	*/
+	// GIVEN
+	reservationController, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+	reservation := factories.NewReservationModel(db)
+
+	frontDeskEmail := utilsTest.GenerateRandomEmail()
+	frontDeskRol := model.UserRolFrontDesk
+	frontDesk := factories.NewUserModel(db, factories.UserModelF{Email: &frontDeskEmail, Rol: &frontDeskRol})
+	frontDeskCredentials := &schemas.Credentials{
+		UserId:    frontDesk.Id,
+		UserEmail: frontDesk.Email,
+		UserRoles: []string{string(schemas.UserRolFrontDesk)},
+	}
+
+	managerEmail := utilsTest.GenerateRandomEmail()
+	manager := factories.NewUserModel(db, factories.UserModelF{Email: &managerEmail})
+	var managerRole model.Role
+	assert.Nil(t, db.First(&managerRole, "name = ?", model.UserRolCommunityManager).Error)
+	otherCommunity := factories.NewCommunityModel(db)
+	assert.Nil(t, db.Create(&model.UserRoleAssignment{
+		Id:          uuid.New(),
+		UserId:      manager.Id,
+		RoleId:      managerRole.Id,
+		CommunityId: &otherCommunity.Id,
+		AuditFields: model.AuditFields{UpdatedBy: "ADMIN"},
+	}).Error)
+
+	walkIn := schemas.CreateReservationRequest{
+		Name:      "Walk-in",
+		State:     "CONFIRMED",
+		UserId:    reservation.UserId,
+		SessionId: reservation.SessionId,
+	}
+
+	// WHEN
+	frontDeskErr := reservationController.CheckReservationAccess(frontDeskCredentials, reservation.Id)
+	frontDeskCreateErr := reservationController.CheckCreateReservationAccess(frontDeskCredentials, walkIn)
+	managerErr := reservationController.CheckReservationAccess(newClientCredentials(manager), reservation.Id)
+
+	// THEN
+	assert.Nil(t, frontDeskErr)
+	assert.Nil(t, frontDeskCreateErr)
+	assert.Equal(t, errors.ForbiddenError.ResourceNotOwned, *managerErr)
+}
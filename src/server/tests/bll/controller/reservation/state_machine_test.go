@@ -0,0 +1,167 @@
+package reservation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestUpdateReservationRejectsUndeclaredTransitions(t *testing.T) {
+	// GIVEN: A reservation already attended and one annulled
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	done, _ := bookSessionWithPolicy(t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{})
+	anulled, _ := bookSessionWithPolicy(t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{})
+	assert.Nil(t, db.Table("astro_cat_reservation").Where("id = ?", done.Id).Update("state", "DONE").Error)
+	assert.Nil(t, db.Table("astro_cat_reservation").Where("id = ?", anulled.Id).Update("state", "ANULLED").Error)
+
+	// WHEN: They are moved to states no transition leads to from theirs
+	confirmed := "CONFIRMED"
+	doneState := "DONE"
+	_, confirmErr := controller.UpdateReservation(
+		done.Id,
+		schemas.UpdateReservationRequest{State: &confirmed},
+		"test_admin",
+	)
+	_, doneErr := controller.UpdateReservation(
+		anulled.Id,
+		schemas.UpdateReservationRequest{State: &doneState},
+		"test_admin",
+	)
+
+	// THEN: Both changes are rejected and the reservations keep their state
+	assert.NotNil(t, confirmErr)
+	assert.Equal(t, errors.BadRequestError.InvalidReservationTransition, *confirmErr)
+	assert.NotNil(t, doneErr)
+	assert.Equal(t, errors.BadRequestError.InvalidReservationTransition, *doneErr)
+
+	stored, err := controller.GetReservation(anulled.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "ANULLED", stored.State)
+}
+
+func TestUpdateReservationChecksTransitionGuards(t *testing.T) {
+	// GIVEN: A reservation of an upcoming session and a cancelled one of a session already started
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	upcoming, _ := bookSessionWithPolicy(t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{})
+	started, _ := bookSessionWithPolicy(t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{})
+	assert.Nil(t, db.Table("astro_cat_reservation").Where("id = ?", started.Id).Update("state", "CANCELLED").Error)
+	assert.Nil(t, db.Table("astro_cat_session").
+		Where("id = ?", started.SessionId).
+		Updates(map[string]any{
+			"start_time": time.Now().Add(-10 * time.Minute),
+			"end_time":   time.Now().Add(50 * time.Minute),
+		}).Error)
+
+	// WHEN: The upcoming one is marked as a no-show and the other one is confirmed again
+	noShow := "NO_SHOW"
+	confirmed := "CONFIRMED"
+	_, noShowErr := controller.UpdateReservation(
+		upcoming.Id,
+		schemas.UpdateReservationRequest{State: &noShow},
+		"test_admin",
+	)
+	_, confirmErr := controller.UpdateReservation(
+		started.Id,
+		schemas.UpdateReservationRequest{State: &confirmed},
+		"test_admin",
+	)
+
+	// THEN: A no-show needs the session to be over and a booking needs it not to have started
+	assert.NotNil(t, noShowErr)
+	assert.Equal(t, errors.BadRequestError.ReservationSessionNotEnded, *noShowErr)
+	assert.NotNil(t, confirmErr)
+	assert.Equal(t, errors.BadRequestError.ReservationSessionStarted, *confirmErr)
+}
+
+func TestUpdateReservationAccessStaffOnlyStates(t *testing.T) {
+	// GIVEN: A confirmed reservation and the credentials of its owner
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, _ := bookSessionWithPolicy(t, controller, db, 2*time.Hour, factories.CancellationPolicyModelF{})
+	var owner model.User
+	assert.Nil(t, db.First(&owner, "id = ?", reservation.UserId).Error)
+	credentials := newClientCredentials(&owner)
+
+	// WHEN: The owner asks to mark it as attended and to cancel it
+	done := "DONE"
+	cancelled := "CANCELLED"
+	doneErr := controller.CheckUpdateReservationAccess(
+		credentials,
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &done},
+	)
+	cancelErr := controller.CheckUpdateReservationAccess(
+		credentials,
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+	)
+
+	// THEN: Only staff can mark attendance, while cancelling is up to the owner
+	assert.NotNil(t, doneErr)
+	assert.Equal(t, errors.ForbiddenError.ReservationStateStaffOnly, *doneErr)
+	assert.Nil(t, cancelErr)
+}
+
+func TestGetReservationTimeline(t *testing.T) {
+	// GIVEN: A full session with a reservation on its waitlist
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	registeredCount := 0
+	capacity := 1
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &registeredCount,
+		Capacity:        &capacity,
+	})
+	confirmedUser := factories.NewUserModel(db, factories.UserModelF{})
+	waitlistedUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	confirmed, err := controller.CreateReservation(
+		newWaitlistRequest(confirmedUser.Id, testSession.Id, nil),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	waitlisted, err := controller.CreateReservation(
+		newWaitlistRequest(waitlistedUser.Id, testSession.Id, nil),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+
+	// WHEN: The confirmed reservation is cancelled by the front desk
+	cancelled := "CANCELLED"
+	_, err = controller.UpdateReservation(
+		confirmed.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+		"front_desk",
+	)
+	assert.Nil(t, err)
+
+	// THEN: Each timeline tells how the reservation got to its state and who moved it
+	cancelledTimeline, err := controller.GetReservationTimeline(confirmed.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "CANCELLED", cancelledTimeline.State)
+	assert.Len(t, cancelledTimeline.Changes, 2)
+	assert.Nil(t, cancelledTimeline.Changes[0].FromState)
+	assert.Equal(t, "CONFIRMED", cancelledTimeline.Changes[0].ToState)
+	assert.Equal(t, "BOOKING", cancelledTimeline.Changes[0].Reason)
+	assert.Equal(t, "CONFIRMED", *cancelledTimeline.Changes[1].FromState)
+	assert.Equal(t, "CANCELLED", cancelledTimeline.Changes[1].ToState)
+	assert.Equal(t, "UPDATE", cancelledTimeline.Changes[1].Reason)
+	assert.Equal(t, "front_desk", cancelledTimeline.Changes[1].ChangedBy)
+
+	promotedTimeline, err := controller.GetReservationTimeline(waitlisted.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "CONFIRMED", promotedTimeline.State)
+	assert.Len(t, promotedTimeline.Changes, 2)
+	assert.Equal(t, "WAITLISTED", promotedTimeline.Changes[0].ToState)
+	assert.Equal(t, "WAITLISTED", *promotedTimeline.Changes[1].FromState)
+	assert.Equal(t, "CONFIRMED", promotedTimeline.Changes[1].ToState)
+	assert.Equal(t, "WAITLIST_PROMOTION", promotedTimeline.Changes[1].Reason)
+}
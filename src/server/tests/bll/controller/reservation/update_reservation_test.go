@@ -0,0 +1,149 @@
+package reservation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestUpdateReservationCancelReleasesSpot(t *testing.T) {
+	// GIVEN: A confirmed reservation that took the last spot of a session
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	registeredCount := 0
+	capacity := 1
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &registeredCount,
+		Capacity:        &capacity,
+	})
+
+	reservation, err := controller.CreateReservation(schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		State:           "CONFIRMED",
+		UserId:          testUser.Id,
+		SessionId:       testSession.Id,
+	}, "test_admin")
+	assert.Nil(t, err)
+
+	// WHEN: The reservation is cancelled
+	cancelled := "CANCELLED"
+	_, err = controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+		"test_admin",
+	)
+
+	// THEN: The spot is given back to the session
+	assert.Nil(t, err)
+
+	var session model.Session
+	assert.Nil(t, db.First(&session, "id = ?", testSession.Id).Error)
+	assert.Equal(t, 0, session.RegisteredCount)
+}
+
+func TestUpdateReservationConfirmFullSession(t *testing.T) {
+	// GIVEN: A cancelled reservation of a session that filled up afterwards
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	capacity := 1
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &capacity,
+		Capacity:        &capacity,
+	})
+
+	cancelledState := model.ReservationStateCancelled
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{
+		UserId:    &testUser.Id,
+		SessionId: &testSession.Id,
+		State:     &cancelledState,
+	})
+
+	// WHEN: The reservation is confirmed again
+	confirmed := "CONFIRMED"
+	result, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &confirmed},
+		"test_admin",
+	)
+
+	// THEN: The update is rejected and the reservation keeps its state
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ConflictError.SessionFull, *err)
+
+	current, getErr := controller.GetReservation(reservation.Id)
+	assert.Nil(t, getErr)
+	assert.Equal(t, "CANCELLED", current.State)
+}
+
+func TestUpdateReservationConfirmCancelledWithWaitlist(t *testing.T) {
+	// GIVEN: A cancelled reservation of a session whose waitlist has a reservation
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{})
+	cancelledState := model.ReservationStateCancelled
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId: &testSession.Id,
+		State:     &cancelledState,
+	})
+	waitlistedState := model.ReservationStateWaitlisted
+	factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId: &testSession.Id,
+		State:     &waitlistedState,
+	})
+
+	// WHEN: The cancelled reservation is confirmed again
+	confirmed := "CONFIRMED"
+	result, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &confirmed},
+		"test_admin",
+	)
+
+	// THEN: The update is rejected so the waitlist keeps its turn
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ConflictError.SessionHasWaitlist, *err)
+}
+
+func TestUpdateReservationConfirmCancelledChecksMembership(t *testing.T) {
+	// GIVEN: A reservation cancelled early whose membership expired afterwards
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	reservation, membership := bookSessionWithPolicy(
+		t, controller, db, 48*time.Hour, factories.CancellationPolicyModelF{},
+	)
+	cancelled := "CANCELLED"
+	_, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(&model.Membership{}).
+		Where("id = ?", membership.Id).
+		Update("status", model.MembershipStatusExpired).Error)
+
+	// WHEN: The reservation is confirmed again
+	confirmed := "CONFIRMED"
+	result, err := controller.UpdateReservation(
+		reservation.Id,
+		schemas.UpdateReservationRequest{State: &confirmed},
+		"test_admin",
+	)
+
+	// THEN: The update is rejected and no membership use is taken
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ForbiddenError.MembershipNotActive, *err)
+	assert.Equal(t, 0, getReservationsUsed(t, db, membership.Id))
+}
@@ -0,0 +1,196 @@
+package reservation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func newWaitlistRequest(userId uuid.UUID, sessionId uuid.UUID, membershipId *uuid.UUID) schemas.CreateReservationRequest {
+	return schemas.CreateReservationRequest{
+		Name:            "Test Reservation",
+		ReservationTime: time.Now().Add(24 * time.Hour),
+		State:           "CONFIRMED",
+		UserId:          userId,
+		SessionId:       sessionId,
+		MembershipId:    membershipId,
+		JoinWaitlist:    true,
+	}
+}
+
+func TestCreateReservationJoinsWaitlistWhenFull(t *testing.T) {
+	// GIVEN: A full session and two users asking to join its waitlist
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	capacity := 1
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &capacity,
+		Capacity:        &capacity,
+	})
+	firstUser := factories.NewUserModel(db, factories.UserModelF{})
+	secondUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	// WHEN: Both book the session
+	first, err := controller.CreateReservation(newWaitlistRequest(firstUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+	second, err := controller.CreateReservation(newWaitlistRequest(secondUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+
+	// THEN: Both are waitlisted in the order they booked and no spot is taken
+	assert.Equal(t, "WAITLISTED", first.State)
+	assert.Equal(t, "WAITLISTED", second.State)
+
+	position, err := controller.GetWaitlistPosition(second.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, position.Position)
+	assert.Equal(t, 2, position.WaitlistSize)
+
+	var session model.Session
+	assert.Nil(t, db.First(&session, "id = ?", testSession.Id).Error)
+	assert.Equal(t, capacity, session.RegisteredCount)
+}
+
+func TestCancelReservationPromotesFirstWaitlisted(t *testing.T) {
+	// GIVEN: A full session with two reservations on its waitlist
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	registeredCount := 0
+	capacity := 1
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &registeredCount,
+		Capacity:        &capacity,
+	})
+	confirmedUser := factories.NewUserModel(db, factories.UserModelF{})
+	firstUser := factories.NewUserModel(db, factories.UserModelF{})
+	secondUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	confirmed, err := controller.CreateReservation(newWaitlistRequest(confirmedUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+	first, err := controller.CreateReservation(newWaitlistRequest(firstUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+	second, err := controller.CreateReservation(newWaitlistRequest(secondUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+
+	// WHEN: The confirmed reservation is cancelled
+	cancelled := "CANCELLED"
+	_, err = controller.UpdateReservation(
+		confirmed.Id,
+		schemas.UpdateReservationRequest{State: &cancelled},
+		"test_admin",
+	)
+	assert.Nil(t, err)
+
+	// THEN: The first waitlisted reservation takes the spot and the second moves up
+	promoted, err := controller.GetReservation(first.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "CONFIRMED", promoted.State)
+	assert.Nil(t, promoted.WaitlistedAt)
+
+	position, err := controller.GetWaitlistPosition(second.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, position.Position)
+	assert.Equal(t, 1, position.WaitlistSize)
+
+	var session model.Session
+	assert.Nil(t, db.First(&session, "id = ?", testSession.Id).Error)
+	assert.Equal(t, capacity, session.RegisteredCount)
+}
+
+func TestDeleteReservationPromotionSkipsExhaustedMembership(t *testing.T) {
+	// GIVEN: A full session whose first waitlisted reservation uses a membership without
+	// reservations left
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	registeredCount := 0
+	capacity := 1
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &registeredCount,
+		Capacity:        &capacity,
+	})
+	confirmedUser := factories.NewUserModel(db, factories.UserModelF{})
+	exhaustedUser := factories.NewUserModel(db, factories.UserModelF{})
+	nextUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	var communityService model.CommunityService
+	assert.Nil(t, db.First(&communityService, "id = ?", *testSession.CommunityServiceId).Error)
+
+	reservationLimit := 10
+	reservationsUsed := reservationLimit - 1
+	plan := factories.NewPlanModel(db, factories.PlanModelF{ReservationLimit: &reservationLimit})
+	exhaustedMembership := factories.NewMembershipModel(db, factories.MembershipModelF{
+		UserId:           &exhaustedUser.Id,
+		CommunityId:      &communityService.CommunityId,
+		PlanId:           &plan.Id,
+		ReservationsUsed: &reservationsUsed,
+	})
+
+	confirmed, err := controller.CreateReservation(newWaitlistRequest(confirmedUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+	exhausted, err := controller.CreateReservation(
+		newWaitlistRequest(exhaustedUser.Id, testSession.Id, &exhaustedMembership.Id),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	next, err := controller.CreateReservation(newWaitlistRequest(nextUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+
+	// The membership spends its last reservation elsewhere while waiting
+	assert.Nil(t, db.Model(&model.Membership{}).
+		Where("id = ?", exhaustedMembership.Id).
+		Update("reservations_used", reservationLimit).Error)
+
+	// WHEN: The confirmed reservation is deleted
+	err = controller.DeleteReservation(confirmed.Id)
+	assert.Nil(t, err)
+
+	// THEN: The spot goes to the next reservation and the exhausted one stays on the waitlist
+	promoted, err := controller.GetReservation(next.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "CONFIRMED", promoted.State)
+
+	skipped, err := controller.GetReservation(exhausted.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "WAITLISTED", skipped.State)
+
+	var membership model.Membership
+	assert.Nil(t, db.First(&membership, "id = ?", exhaustedMembership.Id).Error)
+	assert.Equal(t, reservationLimit, *membership.ReservationsUsed)
+}
+
+func TestLeaveWaitlist(t *testing.T) {
+	// GIVEN: A waitlisted reservation and a confirmed one
+	controller, _, db := controllerTest.NewReservationControllerTestWrapper(t)
+
+	registeredCount := 0
+	capacity := 1
+	testSession := factories.NewSessionModel(db, factories.SessionModelF{
+		RegisteredCount: &registeredCount,
+		Capacity:        &capacity,
+	})
+	confirmedUser := factories.NewUserModel(db, factories.UserModelF{})
+	waitlistedUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	confirmed, err := controller.CreateReservation(newWaitlistRequest(confirmedUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+	waitlisted, err := controller.CreateReservation(newWaitlistRequest(waitlistedUser.Id, testSession.Id, nil), "test_admin")
+	assert.Nil(t, err)
+
+	// WHEN: Both try to leave the waitlist
+	left, leaveErr := controller.LeaveWaitlist(waitlisted.Id, "test_admin")
+	_, confirmedErr := controller.LeaveWaitlist(confirmed.Id, "test_admin")
+
+	// THEN: Only the waitlisted reservation is cancelled
+	assert.Nil(t, leaveErr)
+	assert.Equal(t, "CANCELLED", left.State)
+	assert.Nil(t, left.WaitlistedAt)
+
+	assert.NotNil(t, confirmedErr)
+	assert.Equal(t, errors.BadRequestError.ReservationNotWaitlisted, *confirmedErr)
+}
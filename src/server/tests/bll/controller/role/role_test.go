@@ -0,0 +1,269 @@
+package role_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestDefaultRolesAreSeeded(t *testing.T) {
+	/*
+		GIVEN: A started controller collection
+		WHEN:  FetchRoles is called
+		THEN:  Every default role exists and the administrator has every permission
+	*/
+	// GIVEN
+	roleController, _, _ := controllerTest.NewRoleControllerTestWrapper(t)
+
+	// WHEN
+	result, err := roleController.FetchRoles()
+
+	// THEN
+	assert.Nil(t, err)
+	roles := map[schemas.UserRol]*schemas.Role{}
+	for _, role := range result.Roles {
+		roles[role.Name] = role
+	}
+	for _, definition := range schemas.DefaultRoles {
+		assert.Contains(t, roles, definition.Name)
+	}
+	assert.Len(t, roles[schemas.UserRolAdmin].Permissions, len(schemas.DefaultPermissions))
+	assert.True(t, roles[schemas.UserRolCommunityManager].CommunityScoped)
+	assert.Contains(t, roles[schemas.UserRolClient].Permissions, schemas.PermissionReservationWrite)
+}
+
+func TestAdministratorRoleCanNotBeEdited(t *testing.T) {
+	/*
+		GIVEN: The seeded administrator role
+		WHEN:  UpdateRolePermissions is called on it
+		THEN:  An error is returned and its permissions are kept
+	*/
+	// GIVEN
+	roleController, _, _ := controllerTest.NewRoleControllerTestWrapper(t)
+	adminRole, _ := roleController.Adapter.Role.GetPostgresqlRoleByName(schemas.UserRolAdmin)
+
+	// WHEN
+	result, err := roleController.UpdateRolePermissions(
+		adminRole.Id,
+		schemas.UpdateRolePermissionsRequest{Permissions: []string{}},
+		"ADMIN",
+	)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.Equal(t, errors.BadRequestError.AdministratorRoleNotEditable, *err)
+}
+
+func TestUpdateRolePermissionsRejectsUnknownPermission(t *testing.T) {
+	/*
+		GIVEN: The seeded client role
+		WHEN:  UpdateRolePermissions is called with an unknown permission code
+		THEN:  An invalid permission error is returned
+	*/
+	// GIVEN
+	roleController, _, _ := controllerTest.NewRoleControllerTestWrapper(t)
+	clientRole, _ := roleController.Adapter.Role.GetPostgresqlRoleByName(schemas.UserRolClient)
+
+	// WHEN
+	result, err := roleController.UpdateRolePermissions(
+		clientRole.Id,
+		schemas.UpdateRolePermissionsRequest{Permissions: []string{"unknown:write"}},
+		"ADMIN",
+	)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.Equal(t, errors.BadRequestError.InvalidPermission, *err)
+}
+
+func TestCommunityScopedRoleRequiresCommunity(t *testing.T) {
+	/*
+		GIVEN: A user and the community scoped COMMUNITY_MANAGER role
+		WHEN:  CreateUserRoleAssignment is called without a community
+		THEN:  A community required error is returned
+	*/
+	// GIVEN
+	roleController, _, db := controllerTest.NewRoleControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	// WHEN
+	result, err := roleController.CreateUserRoleAssignment(
+		testUser.Id,
+		schemas.CreateUserRoleAssignmentRequest{RoleName: schemas.UserRolCommunityManager},
+		"ADMIN",
+	)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.Equal(t, errors.BadRequestError.CommunityRequiredForRole, *err)
+}
+
+func TestDuplicatedRoleAssignmentIsRejected(t *testing.T) {
+	/*
+		GIVEN: A user that manages a community
+		WHEN:  The same role is assigned again for the same community
+		THEN:  A conflict error is returned
+	*/
+	// GIVEN
+	roleController, _, db := controllerTest.NewRoleControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	community := factories.NewCommunityModel(db)
+	request := schemas.CreateUserRoleAssignmentRequest{
+		RoleName:    schemas.UserRolCommunityManager,
+		CommunityId: &community.Id,
+	}
+	assignment, err := roleController.CreateUserRoleAssignment(testUser.Id, request, "ADMIN")
+	assert.Nil(t, err)
+	assert.Equal(t, schemas.UserRolCommunityManager, assignment.RoleName)
+
+	// WHEN
+	result, err := roleController.CreateUserRoleAssignment(testUser.Id, request, "ADMIN")
+
+	// THEN
+	assert.Nil(t, result)
+	assert.Equal(t, errors.ConflictError.UserRoleAssignmentAlreadyExists, *err)
+}
+
+func TestScopedPermissionOnlyAppliesToItsCommunity(t *testing.T) {
+	/*
+		GIVEN: A guest user that manages one community
+		WHEN:  CheckPermission is called for that community, another one and no community
+		THEN:  Only the request for their community is allowed
+	*/
+	// GIVEN
+	roleController, _, db := controllerTest.NewRoleControllerTestWrapper(t)
+	guestRol := model.UserRolGuest
+	testUser := factories.NewUserModel(db, factories.UserModelF{Rol: &guestRol})
+	community := factories.NewCommunityModel(db)
+	otherCommunityId := uuid.New()
+	_, err := roleController.CreateUserRoleAssignment(
+		testUser.Id,
+		schemas.CreateUserRoleAssignmentRequest{
+			RoleName:    schemas.UserRolCommunityManager,
+			CommunityId: &community.Id,
+		},
+		"ADMIN",
+	)
+	assert.Nil(t, err)
+
+	// WHEN
+	ownErr := roleController.CheckPermission(
+		testUser.Id,
+		schemas.PermissionSessionWrite,
+		&community.Id,
+	)
+	otherErr := roleController.CheckPermission(
+		testUser.Id,
+		schemas.PermissionSessionWrite,
+		&otherCommunityId,
+	)
+	globalErr := roleController.CheckPermission(testUser.Id, schemas.PermissionSessionWrite, nil)
+
+	// THEN
+	assert.Nil(t, ownErr)
+	assert.Equal(t, errors.ForbiddenError.InsufficientPrivileges, *otherErr)
+	assert.Equal(t, errors.ForbiddenError.InsufficientPrivileges, *globalErr)
+}
+
+func TestRevokedRoleAssignmentRemovesPermission(t *testing.T) {
+	/*
+		GIVEN: A guest user with the FRONT_DESK role
+		WHEN:  The role assignment is deleted
+		THEN:  The user loses the permissions of the role
+	*/
+	// GIVEN
+	roleController, _, db := controllerTest.NewRoleControllerTestWrapper(t)
+	guestRol := model.UserRolGuest
+	testUser := factories.NewUserModel(db, factories.UserModelF{Rol: &guestRol})
+	assignment, err := roleController.CreateUserRoleAssignment(
+		testUser.Id,
+		schemas.CreateUserRoleAssignmentRequest{RoleName: schemas.UserRolFrontDesk},
+		"ADMIN",
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, roleController.CheckPermission(
+		testUser.Id,
+		schemas.PermissionReservationWrite,
+		nil,
+	))
+
+	// WHEN
+	err = roleController.DeleteUserRoleAssignment(assignment.Id)
+
+	// THEN
+	assert.Nil(t, err)
+	checkErr := roleController.CheckPermission(
+		testUser.Id,
+		schemas.PermissionReservationWrite,
+		nil,
+	)
+	assert.Equal(t, errors.ForbiddenError.InsufficientPrivileges, *checkErr)
+}
+
+func TestScopedPermissionAppliesToResourcesOfItsCommunity(t *testing.T) {
+	/*
+		GIVEN: A guest user that manages one community, with a session and a reservation there and a
+		       session of another community
+		WHEN:  CheckRequestPermission is called for those resources
+		THEN:  Only the resources of their community are allowed, also when a request mixes both
+	*/
+	// GIVEN
+	roleController, _, db := controllerTest.NewRoleControllerTestWrapper(t)
+	guestRol := model.UserRolGuest
+	testUser := factories.NewUserModel(db, factories.UserModelF{Rol: &guestRol})
+	community := factories.NewCommunityModel(db)
+	_, err := roleController.CreateUserRoleAssignment(
+		testUser.Id,
+		schemas.CreateUserRoleAssignmentRequest{
+			RoleName:    schemas.UserRolCommunityManager,
+			CommunityId: &community.Id,
+		},
+		"ADMIN",
+	)
+	assert.Nil(t, err)
+
+	communityService := factories.NewCommunityServiceModel(db, factories.CommunityServiceModelF{
+		CommunityId: &community.Id,
+	})
+	ownSession := factories.NewSessionModel(db, factories.SessionModelF{
+		CommunityServiceId: &communityService.Id,
+	})
+	ownReservation := factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId: &ownSession.Id,
+	})
+	otherSession := factories.NewSessionModel(db)
+
+	// WHEN
+	sessionErr := roleController.CheckRequestPermission(
+		testUser.Id,
+		schemas.PermissionSessionWrite,
+		schemas.PermissionResources{SessionId: &ownSession.Id},
+	)
+	reservationErr := roleController.CheckRequestPermission(
+		testUser.Id,
+		schemas.PermissionReservationWrite,
+		schemas.PermissionResources{ReservationId: &ownReservation.Id},
+	)
+	otherErr := roleController.CheckRequestPermission(
+		testUser.Id,
+		schemas.PermissionSessionWrite,
+		schemas.PermissionResources{SessionId: &otherSession.Id},
+	)
+	movedErr := roleController.CheckRequestPermission(
+		testUser.Id,
+		schemas.PermissionReservationWrite,
+		schemas.PermissionResources{ReservationId: &ownReservation.Id, SessionId: &otherSession.Id},
+	)
+
+	// THEN
+	assert.Nil(t, sessionErr)
+	assert.Nil(t, reservationErr)
+	assert.Equal(t, errors.ForbiddenError.InsufficientPrivileges, *otherErr)
+	assert.Equal(t, errors.ForbiddenError.InsufficientPrivileges, *movedErr)
+}
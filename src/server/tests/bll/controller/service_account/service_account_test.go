@@ -0,0 +1,141 @@
+package service_account_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+func newCreateRequest(name string) schemas.CreateServiceAccountRequest {
+	return schemas.CreateServiceAccountRequest{
+		Name:   name,
+		Scopes: []string{schemas.PermissionReservationRead},
+	}
+}
+
+func TestCreateServiceAccountStoresOnlyTheKeyHash(t *testing.T) {
+	/*
+		GIVEN: A request with a known scope
+		WHEN:  CreateServiceAccount is called
+		THEN:  The API key is returned once and only its hash and prefix are stored
+	*/
+	// GIVEN
+	serviceAccountController, _, db := controllerTest.NewServiceAccountControllerTestWrapper(t)
+
+	// WHEN
+	result, err := serviceAccountController.CreateServiceAccount(newCreateRequest("billing-sync"), "ADMIN")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(result.ApiKey, "acsk_"))
+	assert.True(t, strings.HasPrefix(result.ApiKey, result.ServiceAccount.KeyPrefix))
+
+	var stored model.ServiceAccount
+	db.First(&stored, "id = ?", result.ServiceAccount.Id)
+	assert.Equal(t, utils.HashToken(result.ApiKey), stored.KeyHash)
+	assert.NotEqual(t, result.ApiKey, stored.KeyHash)
+}
+
+func TestCreateServiceAccountRejectsUnknownScope(t *testing.T) {
+	/*
+		GIVEN: A request with a scope that is not a permission code
+		WHEN:  CreateServiceAccount is called
+		THEN:  An invalid scope error is returned
+	*/
+	// GIVEN
+	serviceAccountController, _, _ := controllerTest.NewServiceAccountControllerTestWrapper(t)
+	request := newCreateRequest("billing-sync")
+	request.Scopes = []string{"everything:write"}
+
+	// WHEN
+	result, err := serviceAccountController.CreateServiceAccount(request, "ADMIN")
+
+	// THEN
+	assert.Nil(t, result)
+	assert.Equal(t, errors.BadRequestError.InvalidServiceAccountScope, *err)
+}
+
+func TestAuthenticateRejectsRevokedAndExpiredKeys(t *testing.T) {
+	/*
+		GIVEN: A revoked key and an expired key
+		WHEN:  Authenticate is called with them
+		THEN:  Both are rejected
+	*/
+	// GIVEN
+	serviceAccountController, _, db := controllerTest.NewServiceAccountControllerTestWrapper(t)
+	revoked, _ := serviceAccountController.CreateServiceAccount(newCreateRequest("revoked"), "ADMIN")
+	serviceAccountController.RevokeServiceAccountKey(revoked.ServiceAccount.Id, "ADMIN")
+	expired, _ := serviceAccountController.CreateServiceAccount(newCreateRequest("expired"), "ADMIN")
+	db.Model(&model.ServiceAccount{}).
+		Where("id = ?", expired.ServiceAccount.Id).
+		Update("expires_at", time.Now().Add(-time.Hour))
+
+	// WHEN
+	_, revokedErr := serviceAccountController.Authenticate(revoked.ApiKey)
+	_, expiredErr := serviceAccountController.Authenticate(expired.ApiKey)
+
+	// THEN
+	assert.Equal(t, errors.AuthenticationError.InvalidApiKey, *revokedErr)
+	assert.Equal(t, errors.AuthenticationError.InvalidApiKey, *expiredErr)
+}
+
+func TestRotateServiceAccountKeyInvalidatesPreviousKey(t *testing.T) {
+	/*
+		GIVEN: A service account with a key
+		WHEN:  Its key is rotated
+		THEN:  The previous key is rejected, the new one works and its use is recorded
+	*/
+	// GIVEN
+	serviceAccountController, _, _ := controllerTest.NewServiceAccountControllerTestWrapper(t)
+	created, _ := serviceAccountController.CreateServiceAccount(newCreateRequest("billing-sync"), "ADMIN")
+
+	// WHEN
+	rotated, err := serviceAccountController.RotateServiceAccountKey(
+		created.ServiceAccount.Id,
+		schemas.RotateServiceAccountKeyRequest{},
+		"ADMIN",
+	)
+
+	// THEN
+	assert.Nil(t, err)
+	_, oldErr := serviceAccountController.Authenticate(created.ApiKey)
+	assert.Equal(t, errors.AuthenticationError.InvalidApiKey, *oldErr)
+	authenticated, newErr := serviceAccountController.Authenticate(rotated.ApiKey)
+	assert.Nil(t, newErr)
+	assert.Equal(t, created.ServiceAccount.Id, authenticated.Id)
+
+	stored, _ := serviceAccountController.Adapter.ServiceAccount.GetPostgresqlServiceAccount(created.ServiceAccount.Id)
+	assert.NotNil(t, stored.LastUsedAt)
+}
+
+func TestCheckScope(t *testing.T) {
+	/*
+		GIVEN: A service account scoped to reading reservations
+		WHEN:  CheckScope is called with a granted and a missing permission
+		THEN:  Only the granted permission passes
+	*/
+	// GIVEN
+	serviceAccountController, _, _ := controllerTest.NewServiceAccountControllerTestWrapper(t)
+	created, _ := serviceAccountController.CreateServiceAccount(newCreateRequest("billing-sync"), "ADMIN")
+
+	// WHEN
+	grantedErr := serviceAccountController.CheckScope(
+		created.ServiceAccount,
+		schemas.PermissionReservationRead,
+	)
+	missingErr := serviceAccountController.CheckScope(
+		created.ServiceAccount,
+		schemas.PermissionReservationWrite,
+	)
+
+	// THEN
+	assert.Nil(t, grantedErr)
+	assert.Equal(t, errors.ForbiddenError.InsufficientPrivileges, *missingErr)
+}
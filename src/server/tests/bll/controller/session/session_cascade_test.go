@@ -0,0 +1,191 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestUpdateSessionCancelledAnnulsReservations(t *testing.T) {
+	/*
+		GIVEN: A full session with a confirmed reservation and a waitlisted one
+		WHEN:  The session is cancelled
+		THEN:  Both reservations are annulled, the membership use is given back and their ids are returned
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+
+	startTime := time.Now().Add(48 * time.Hour)
+	endTime := startTime.Add(time.Hour)
+	capacity := 1
+	registeredCount := 1
+	session := factories.NewSessionModel(db, factories.SessionModelF{
+		Date:            &startTime,
+		StartTime:       &startTime,
+		EndTime:         &endTime,
+		Capacity:        &capacity,
+		RegisteredCount: &registeredCount,
+	})
+
+	reservationsUsed := 1
+	membership := factories.NewMembershipModel(db, factories.MembershipModelF{
+		ReservationsUsed: &reservationsUsed,
+	})
+	confirmed := factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId:    &session.Id,
+		MembershipId: &membership.Id,
+	})
+	waitlistedState := model.ReservationStateWaitlisted
+	waitlisted := factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId: &session.Id,
+		State:     &waitlistedState,
+	})
+
+	cancelled := "CANCELLED"
+
+	// WHEN
+	result, err := controller.UpdateSession(
+		session.Id,
+		schemas.UpdateSessionRequest{State: &cancelled},
+		"test_admin",
+	)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, cancelled, result.State)
+	assert.ElementsMatch(t, []uuid.UUID{confirmed.Id, waitlisted.Id}, result.AffectedReservationIds)
+
+	for _, reservationId := range result.AffectedReservationIds {
+		var reservation model.Reservation
+		assert.Nil(t, db.First(&reservation, "id = ?", reservationId).Error)
+		assert.Equal(t, model.ReservationStateAnulled, reservation.State)
+	}
+
+	var updatedMembership model.Membership
+	assert.Nil(t, db.First(&updatedMembership, "id = ?", membership.Id).Error)
+	assert.Equal(t, 0, *updatedMembership.ReservationsUsed)
+
+	var updatedSession model.Session
+	assert.Nil(t, db.First(&updatedSession, "id = ?", session.Id).Error)
+	assert.Equal(t, 0, updatedSession.RegisteredCount)
+}
+
+func TestUpdateSessionRescheduledKeepsReservations(t *testing.T) {
+	/*
+		GIVEN: A session with a confirmed reservation
+		WHEN:  The session is rescheduled to another day
+		THEN:  The reservation stays confirmed and its id is returned
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+
+	startTime := time.Now().Add(48 * time.Hour)
+	endTime := startTime.Add(time.Hour)
+	session := factories.NewSessionModel(db, factories.SessionModelF{
+		Date:      &startTime,
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	})
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId: &session.Id,
+	})
+
+	rescheduled := "RESCHEDULED"
+	newStartTime := startTime.Add(24 * time.Hour)
+	newEndTime := newStartTime.Add(time.Hour)
+
+	// WHEN
+	result, err := controller.UpdateSession(session.Id, schemas.UpdateSessionRequest{
+		State:     &rescheduled,
+		Date:      &newStartTime,
+		StartTime: &newStartTime,
+		EndTime:   &newEndTime,
+	}, "test_admin")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, rescheduled, result.State)
+	assert.Len(t, result.AffectedReservationIds, 1)
+	assert.Equal(t, reservation.Id, result.AffectedReservationIds[0])
+
+	var updatedReservation model.Reservation
+	assert.Nil(t, db.First(&updatedReservation, "id = ?", reservation.Id).Error)
+	assert.Equal(t, model.ReservationStateConfirmed, updatedReservation.State)
+}
+
+func TestUpdateSessionTimeChangeWithoutStateNotifiesReservations(t *testing.T) {
+	/*
+		GIVEN: A scheduled session with a confirmed reservation
+		WHEN:  The session is moved to another time without changing its state
+		THEN:  The reservation stays confirmed and its id is returned
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+
+	startTime := time.Now().Add(48 * time.Hour)
+	endTime := startTime.Add(time.Hour)
+	session := factories.NewSessionModel(db, factories.SessionModelF{
+		Date:      &startTime,
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	})
+	reservation := factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId: &session.Id,
+	})
+
+	newStartTime := startTime.Add(2 * time.Hour)
+	newEndTime := newStartTime.Add(time.Hour)
+
+	// WHEN
+	result, err := controller.UpdateSession(session.Id, schemas.UpdateSessionRequest{
+		StartTime: &newStartTime,
+		EndTime:   &newEndTime,
+	}, "test_admin")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Len(t, result.AffectedReservationIds, 1)
+	assert.Equal(t, reservation.Id, result.AffectedReservationIds[0])
+}
+
+func TestUpdateSessionRepeatedStateDoesNotCascadeAgain(t *testing.T) {
+	/*
+		GIVEN: A rescheduled session with a confirmed reservation
+		WHEN:  The session is updated again with the RESCHEDULED state and the same time
+		THEN:  No reservation is returned as affected
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+
+	startTime := time.Now().Add(48 * time.Hour)
+	endTime := startTime.Add(time.Hour)
+	rescheduledState := model.SessionStateRescheduled
+	session := factories.NewSessionModel(db, factories.SessionModelF{
+		Date:      &startTime,
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		State:     &rescheduledState,
+	})
+	factories.NewReservationModel(db, factories.ReservationModelF{
+		SessionId: &session.Id,
+	})
+
+	rescheduled := "RESCHEDULED"
+	title := "Nuevo título"
+
+	// WHEN
+	result, err := controller.UpdateSession(session.Id, schemas.UpdateSessionRequest{
+		Title: &title,
+		State: &rescheduled,
+	}, "test_admin")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Empty(t, result.AffectedReservationIds)
+}
@@ -0,0 +1,62 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestAdvanceSessionStates(t *testing.T) {
+	/*
+		GIVEN: A session that already started, one that is over, one still to come and a cancelled one that is over
+		WHEN:  AdvanceSessionStates is called twice
+		THEN:  The started session becomes ONGOING and the ended one COMPLETED, only on the first call
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewSessionControllerTestWrapper(t)
+
+	now := time.Now()
+	newSession := func(startsIn time.Duration, state model.SessionState) *model.Session {
+		startTime := now.Add(startsIn)
+		endTime := startTime.Add(time.Hour)
+		return factories.NewSessionModel(db, factories.SessionModelF{
+			Date:      &startTime,
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			State:     &state,
+		})
+	}
+	ongoing := newSession(-30*time.Minute, model.SessionStateScheduled)
+	over := newSession(-2*time.Hour, model.SessionStateRescheduled)
+	upcoming := newSession(2*time.Hour, model.SessionStateScheduled)
+	cancelled := newSession(-2*time.Hour, model.SessionStateCancelled)
+
+	// WHEN
+	sweep, err := controller.AdvanceSessionStates(now)
+	secondSweep, secondErr := controller.AdvanceSessionStates(now)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, sweep.StartedSessions, int64(1))
+	assert.GreaterOrEqual(t, sweep.CompletedSessions, int64(1))
+
+	assert.Nil(t, secondErr)
+	assert.Equal(t, int64(0), secondSweep.StartedSessions)
+	assert.Equal(t, int64(0), secondSweep.CompletedSessions)
+
+	expectedStates := map[*model.Session]string{
+		ongoing:   "ONGOING",
+		over:      "COMPLETED",
+		upcoming:  "SCHEDULED",
+		cancelled: "CANCELLED",
+	}
+	for session, expectedState := range expectedStates {
+		result, getErr := controller.GetSession(session.Id)
+		assert.Nil(t, getErr)
+		assert.Equal(t, expectedState, result.State)
+	}
+}
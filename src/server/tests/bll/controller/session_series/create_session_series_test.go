@@ -0,0 +1,179 @@
+package session_series_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+// Gets the first day after today, in the booking time zone, that falls on a weekday
+func nextWeekday(sessionSeriesController *controller.SessionSeries, weekday time.Weekday) time.Time {
+	day := time.Now().In(sessionSeriesController.EnvSettings.BookingTimeZone).AddDate(0, 0, 1)
+	for day.Weekday() != weekday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// Gets a time of a day in the booking time zone
+func atTime(sessionSeriesController *controller.SessionSeries, day time.Time, hour int, minute int) time.Time {
+	return time.Date(
+		day.Year(),
+		day.Month(),
+		day.Day(),
+		hour,
+		minute,
+		0,
+		0,
+		sessionSeriesController.EnvSettings.BookingTimeZone,
+	)
+}
+
+// Builds the request of a 10:00 series of one hour led by a new professional
+func newSessionSeriesRequest(
+	db *gorm.DB,
+	startDate time.Time,
+	rule string,
+	exceptionDates string,
+) schemas.CreateSessionSeriesRequest {
+	professional := factories.NewProfessionalModel(db)
+
+	return schemas.CreateSessionSeriesRequest{
+		Title:           "Yoga",
+		StartDate:       startDate.Format(time.DateOnly),
+		StartTime:       "10:00",
+		DurationMinutes: 60,
+		Capacity:        10,
+		ProfessionalId:  professional.Id,
+		Rule:            rule,
+		ExceptionDates:  exceptionDates,
+	}
+}
+
+func countSeriesSessions(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	assert.Nil(t, db.Model(&model.Session{}).Where("series_id IS NOT NULL").Count(&count).Error)
+	return count
+}
+
+func TestCreateSessionSeriesGeneratesOccurrences(t *testing.T) {
+	// GIVEN: A Monday and Wednesday series of four occurrences without its first Wednesday
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	wednesday := monday.AddDate(0, 0, 2)
+	request := newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=WE,MO;COUNT=4", wednesday.Format("20060102"))
+
+	// WHEN: The series is created
+	report, err := controller.CreateSessionSeries(request, "test_admin")
+
+	// THEN: The excluded day still counts, so three sessions are generated at 10:00 on the right days
+	assert.Nil(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", report.Series.Rule)
+	assert.Empty(t, report.Skipped)
+	assert.Len(t, report.Sessions, 3)
+
+	expectedStarts := []time.Time{
+		atTime(controller, monday, 10, 0),
+		atTime(controller, monday.AddDate(0, 0, 7), 10, 0),
+		atTime(controller, wednesday.AddDate(0, 0, 7), 10, 0),
+	}
+	for i, session := range report.Sessions {
+		assert.True(t, expectedStarts[i].Equal(session.StartTime))
+		assert.True(t, expectedStarts[i].Add(time.Hour).Equal(session.EndTime))
+		assert.Equal(t, report.Series.Id, *session.SeriesId)
+	}
+
+	occurrences, err := controller.FetchSessionSeriesOccurrences(report.Series.Id)
+	assert.Nil(t, err)
+	assert.Len(t, occurrences.Occurrences, 3)
+	assert.Equal(t, monday.Format(time.DateOnly), occurrences.Occurrences[0].Date)
+}
+
+func TestCreateSessionSeriesSkipsConflictingOccurrences(t *testing.T) {
+	// GIVEN: A weekly series whose professional already leads a session on its second Monday
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	request := newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO;COUNT=3", "")
+
+	conflictStart := atTime(controller, monday.AddDate(0, 0, 7), 10, 30)
+	conflictEnd := conflictStart.Add(time.Hour)
+	factories.NewSessionModel(db, factories.SessionModelF{
+		ProfessionalId: &request.ProfessionalId,
+		Date:           &conflictStart,
+		StartTime:      &conflictStart,
+		EndTime:        &conflictEnd,
+	})
+
+	// WHEN: The series is created
+	report, err := controller.CreateSessionSeries(request, "test_admin")
+
+	// THEN: The conflicting occurrence is skipped with the reason and the others are generated
+	assert.Nil(t, err)
+	assert.Len(t, report.Sessions, 2)
+	assert.Len(t, report.Skipped, 1)
+	assert.Equal(t, monday.AddDate(0, 0, 7).Format(time.DateOnly), report.Skipped[0].Date)
+	assert.Equal(t, errors.ConflictError.SessionTimeConflict.Code, report.Skipped[0].Reason)
+}
+
+func TestCreateSessionSeriesInvalidRecurrence(t *testing.T) {
+	// GIVEN: Recurrences outside of the supported subset
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	cases := map[string]schemas.CreateSessionSeriesRequest{
+		"daily":           newSessionSeriesRequest(db, monday, "FREQ=DAILY;COUNT=3", ""),
+		"without days":    newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;COUNT=3", ""),
+		"until and count": newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO;UNTIL=20991231;COUNT=3", ""),
+		"bad exception":   newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO;COUNT=3", "tomorrow"),
+	}
+
+	for name, request := range cases {
+		t.Run(name, func(t *testing.T) {
+			// WHEN: A series is created with them
+			report, err := controller.CreateSessionSeries(request, "test_admin")
+
+			// THEN: It is rejected
+			assert.Nil(t, report)
+			assert.NotNil(t, err)
+			assert.Equal(t, errors.BadRequestError.InvalidSessionSeriesRule, *err)
+		})
+	}
+
+	assert.Equal(t, int64(0), countSeriesSessions(t, db))
+}
+
+func TestGenerateUpcomingSessionsOnlyOncePerDay(t *testing.T) {
+	// GIVEN: A series without end whose generation is rewound, as if another instance was behind
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	report, err := controller.CreateSessionSeries(
+		newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO", ""),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	generated := countSeriesSessions(t, db)
+	assert.Equal(t, int64(len(report.Sessions)), generated)
+	assert.Nil(t, db.Model(&model.SessionSeries{}).
+		Where("id = ?", report.Series.Id).
+		Update("generated_until", monday).Error)
+
+	// WHEN: The generation runs again twice
+	_, firstErr := controller.GenerateUpcomingSessions()
+	_, secondErr := controller.GenerateUpcomingSessions()
+
+	// THEN: No day gets a second session
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, generated, countSeriesSessions(t, db))
+}
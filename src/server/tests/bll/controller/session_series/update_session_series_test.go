@@ -0,0 +1,181 @@
+package session_series_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestUpdateSessionSeriesThisOccurrence(t *testing.T) {
+	// GIVEN: A weekly series of three occurrences
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	created, err := controller.CreateSessionSeries(
+		newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO;COUNT=3", ""),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	second := created.Sessions[1]
+
+	// WHEN: The second occurrence is renamed and moved to 18:00, then the whole series is renamed
+	special := "Special yoga"
+	evening := "18:00"
+	_, thisErr := controller.UpdateSessionSeriesOccurrences(
+		created.Series.Id,
+		second.Id,
+		schemas.SessionSeriesScopeThis,
+		schemas.UpdateSessionSeriesRequest{Title: &special, StartTime: &evening},
+		"test_admin",
+	)
+	renamed := "Morning yoga"
+	allReport, allErr := controller.UpdateSessionSeriesOccurrences(
+		created.Series.Id,
+		created.Sessions[0].Id,
+		schemas.SessionSeriesScopeAll,
+		schemas.UpdateSessionSeriesRequest{Title: &renamed},
+		"test_admin",
+	)
+
+	// THEN: The occurrence edited on its own keeps its changes and the others follow the series
+	assert.Nil(t, thisErr)
+	assert.Nil(t, allErr)
+	assert.Equal(t, renamed, allReport.Series.Title)
+	assert.Len(t, allReport.Sessions, 2)
+
+	var sessions []model.Session
+	assert.Nil(t, db.Where("series_id = ?", created.Series.Id).Order("occurrence_date").Find(&sessions).Error)
+	assert.Len(t, sessions, 3)
+	assert.Equal(t, renamed, sessions[0].Title)
+	assert.Equal(t, special, sessions[1].Title)
+	assert.True(t, sessions[1].Detached)
+	assert.True(t, atTime(controller, monday.AddDate(0, 0, 7), 18, 0).Equal(sessions[1].StartTime))
+	assert.Equal(t, renamed, sessions[2].Title)
+}
+
+func TestUpdateSessionSeriesThisAndFollowingSplitsSeries(t *testing.T) {
+	// GIVEN: A weekly series of four occurrences
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	created, err := controller.CreateSessionSeries(
+		newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO;COUNT=4", ""),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	third := created.Sessions[2]
+
+	// WHEN: The third and following occurrences move to 11:30
+	lateMorning := "11:30"
+	report, err := controller.UpdateSessionSeriesOccurrences(
+		created.Series.Id,
+		third.Id,
+		schemas.SessionSeriesScopeThisAndFollowing,
+		schemas.UpdateSessionSeriesRequest{StartTime: &lateMorning},
+		"test_admin",
+	)
+
+	// THEN: The series ends before the third Monday and a new one takes the last two occurrences
+	assert.Nil(t, err)
+	assert.NotEqual(t, created.Series.Id, report.Series.Id)
+	assert.Equal(t, "11:30", report.Series.StartTime)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO;COUNT=2", report.Series.Rule)
+	assert.Len(t, report.Sessions, 2)
+	for i, session := range report.Sessions {
+		assert.Equal(t, report.Series.Id, *session.SeriesId)
+		assert.True(t, atTime(controller, monday.AddDate(0, 0, 7*(i+2)), 11, 30).Equal(session.StartTime))
+	}
+
+	original, err := controller.GetSessionSeries(created.Series.Id)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		"FREQ=WEEKLY;BYDAY=MO;UNTIL="+monday.AddDate(0, 0, 13).Format("20060102"),
+		original.Rule,
+	)
+
+	occurrences, err := controller.FetchSessionSeriesOccurrences(created.Series.Id)
+	assert.Nil(t, err)
+	assert.Len(t, occurrences.Occurrences, 2)
+	assert.True(t, atTime(controller, monday, 10, 0).Equal(occurrences.Occurrences[0].Session.StartTime))
+}
+
+func TestUpdateSessionSeriesRejectsOtherSessions(t *testing.T) {
+	// GIVEN: A series and a session that is not one of its occurrences
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	created, err := controller.CreateSessionSeries(
+		newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO;COUNT=2", ""),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	other := factories.NewSessionModel(db)
+
+	// WHEN: The session is edited as an occurrence of the series, and an occurrence with a bad scope
+	title := "Renamed"
+	_, otherErr := controller.UpdateSessionSeriesOccurrences(
+		created.Series.Id,
+		other.Id,
+		schemas.SessionSeriesScopeThis,
+		schemas.UpdateSessionSeriesRequest{Title: &title},
+		"test_admin",
+	)
+	_, scopeErr := controller.UpdateSessionSeriesOccurrences(
+		created.Series.Id,
+		created.Sessions[0].Id,
+		"NEXT",
+		schemas.UpdateSessionSeriesRequest{Title: &title},
+		"test_admin",
+	)
+
+	// THEN: Both are rejected
+	assert.NotNil(t, otherErr)
+	assert.Equal(t, errors.BadRequestError.SessionNotInSeries, *otherErr)
+	assert.NotNil(t, scopeErr)
+	assert.Equal(t, errors.BadRequestError.InvalidSessionSeriesScope, *scopeErr)
+}
+
+func TestCancelSessionSeriesThisAndFollowing(t *testing.T) {
+	// GIVEN: A weekly series without end
+	controller, _, db := controllerTest.NewSessionSeriesControllerTestWrapper(t)
+
+	monday := nextWeekday(controller, time.Monday)
+	created, err := controller.CreateSessionSeries(
+		newSessionSeriesRequest(db, monday, "FREQ=WEEKLY;BYDAY=MO", ""),
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	assert.Greater(t, len(created.Sessions), 2)
+
+	// WHEN: The second and following occurrences are cancelled and the generation runs again
+	report, err := controller.CancelSessionSeriesOccurrences(
+		created.Series.Id,
+		created.Sessions[1].Id,
+		schemas.SessionSeriesScopeThisAndFollowing,
+		"test_admin",
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(&model.SessionSeries{}).
+		Where("id = ?", created.Series.Id).
+		Update("generated_until", monday.Format(time.DateOnly)).Error)
+	_, generateErr := controller.GenerateUpcomingSessions()
+
+	// THEN: Only the first occurrence stays scheduled and the recurrence ends before the second
+	assert.Nil(t, generateErr)
+	assert.Len(t, report.Sessions, len(created.Sessions)-1)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO;UNTIL="+monday.AddDate(0, 0, 6).Format("20060102"), report.Series.Rule)
+
+	var scheduled int64
+	assert.Nil(t, db.Model(&model.Session{}).
+		Where("series_id = ? AND state = ?", created.Series.Id, model.SessionStateScheduled).
+		Count(&scheduled).Error)
+	assert.Equal(t, int64(1), scheduled)
+	assert.Equal(t, int64(len(created.Sessions)), countSeriesSessions(t, db))
+}
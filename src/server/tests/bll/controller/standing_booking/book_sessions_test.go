@@ -0,0 +1,179 @@
+package standing_booking_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestStandingBookingSkipsFullSession(t *testing.T) {
+	// GIVEN: A full session within the booking window
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+
+	capacity := 1
+	session, communityService := newSessionAt(t, db, time.Now().AddDate(0, 0, 2), factories.SessionModelF{
+		Capacity:        &capacity,
+		RegisteredCount: &capacity,
+	})
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{})
+
+	// WHEN: A member subscribes to its weekday and time
+	standingBooking, err := controller.CreateStandingBooking(request, "test_user")
+
+	// THEN: The session is skipped with the reason and the membership is not used
+	assert.Nil(t, err)
+
+	attempts, err := controller.FetchStandingBookingAttempts(standingBooking.Id)
+	assert.Nil(t, err)
+	assert.Len(t, attempts.Attempts, 1)
+	assert.Equal(t, "SKIPPED", attempts.Attempts[0].Status)
+	assert.Nil(t, attempts.Attempts[0].ReservationId)
+	assert.Equal(t, errors.ConflictError.SessionFull.Code, *attempts.Attempts[0].Reason)
+
+	var membership model.Membership
+	assert.Nil(t, db.First(&membership, "id = ?", *request.MembershipId).Error)
+	assert.Equal(t, 0, *membership.ReservationsUsed)
+}
+
+func TestStandingBookingSkipsWhenMembershipQuotaIsUsed(t *testing.T) {
+	// GIVEN: A member whose membership has no reservations left
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+
+	reservationLimit := 1
+	plan := factories.NewPlanModel(db, factories.PlanModelF{ReservationLimit: &reservationLimit})
+	session, communityService := newSessionAt(t, db, time.Now().AddDate(0, 0, 2), factories.SessionModelF{})
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{
+		PlanId:           &plan.Id,
+		ReservationsUsed: &reservationLimit,
+	})
+
+	// WHEN: The member subscribes to the weekday and time of a session
+	standingBooking, err := controller.CreateStandingBooking(request, "test_user")
+
+	// THEN: The session is skipped because of the quota
+	assert.Nil(t, err)
+
+	attempts, err := controller.FetchStandingBookingAttempts(standingBooking.Id)
+	assert.Nil(t, err)
+	assert.Len(t, attempts.Attempts, 1)
+	assert.Equal(t, "SKIPPED", attempts.Attempts[0].Status)
+	assert.Equal(t, errors.ForbiddenError.MembershipReservationLimitReached.Code, *attempts.Attempts[0].Reason)
+}
+
+func TestStandingBookingBooksSessionsEnteringTheWindowOnce(t *testing.T) {
+	// GIVEN: A standing booking matching a session beyond the booking window
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+	windowDays := controller.EnvSettings.StandingBookingWindowDays
+	defer func() { controller.EnvSettings.StandingBookingWindowDays = windowDays }()
+
+	session, communityService := newSessionAt(
+		t,
+		db,
+		time.Now().AddDate(0, 0, windowDays+2),
+		factories.SessionModelF{},
+	)
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{})
+	standingBooking, err := controller.CreateStandingBooking(request, "test_user")
+	assert.Nil(t, err)
+
+	scheduledSession, err := controller.Adapter.Session.GetPostgresqlSession(session.Id)
+	assert.Nil(t, err)
+	outOfWindow, err := controller.BookSession(scheduledSession)
+	assert.Nil(t, err)
+	assert.Empty(t, outOfWindow.Booked)
+
+	// WHEN: The window reaches the session and the booking runs more than once
+	controller.EnvSettings.StandingBookingWindowDays = windowDays + 7
+	report, err := controller.BookUpcomingSessions()
+	assert.Nil(t, err)
+	secondReport, secondErr := controller.BookSession(scheduledSession)
+
+	// THEN: The session is booked only the first time
+	assert.Len(t, report.Booked, 1)
+	assert.Equal(t, session.Id, report.Booked[0].SessionId)
+	assert.Equal(t, standingBooking.Id, report.Booked[0].StandingBookingId)
+
+	assert.Nil(t, secondErr)
+	assert.Empty(t, secondReport.Booked)
+	assert.Empty(t, secondReport.Skipped)
+
+	var reservations int64
+	assert.Nil(t, db.Model(&model.Reservation{}).Where("session_id = ?", session.Id).Count(&reservations).Error)
+	assert.Equal(t, int64(1), reservations)
+}
+
+func TestStandingBookingPausedIsNotBooked(t *testing.T) {
+	// GIVEN: A paused standing booking and a matching session within the window
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+	windowDays := controller.EnvSettings.StandingBookingWindowDays
+	defer func() { controller.EnvSettings.StandingBookingWindowDays = windowDays }()
+
+	session, communityService := newSessionAt(
+		t,
+		db,
+		time.Now().AddDate(0, 0, windowDays+2),
+		factories.SessionModelF{},
+	)
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{})
+	standingBooking, err := controller.CreateStandingBooking(request, "test_user")
+	assert.Nil(t, err)
+
+	active := false
+	_, err = controller.UpdateStandingBooking(standingBooking.Id, schemas.UpdateStandingBookingRequest{
+		Active: &active,
+	}, "test_user")
+	assert.Nil(t, err)
+
+	// WHEN: The window reaches the session
+	controller.EnvSettings.StandingBookingWindowDays = windowDays + 7
+	report, err := controller.BookUpcomingSessions()
+
+	// THEN: Nothing is booked
+	assert.Nil(t, err)
+	assert.Empty(t, report.Booked)
+	assert.Empty(t, report.Skipped)
+}
+
+func TestStandingBookingTakesOverStaleAttempts(t *testing.T) {
+	// GIVEN: A session entering the booking window whose attempt was claimed long ago and never
+	// finished, as when the instance booking it crashed
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+	windowDays := controller.EnvSettings.StandingBookingWindowDays
+	defer func() { controller.EnvSettings.StandingBookingWindowDays = windowDays }()
+
+	session, communityService := newSessionAt(
+		t,
+		db,
+		time.Now().AddDate(0, 0, windowDays+2),
+		factories.SessionModelF{},
+	)
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{})
+	standingBooking, err := controller.CreateStandingBooking(request, "test_user")
+	assert.Nil(t, err)
+
+	staleAttempt := &model.StandingBookingAttempt{
+		Id:                uuid.New(),
+		Status:            model.StandingBookingAttemptPending,
+		StandingBookingId: standingBooking.Id,
+		SessionId:         session.Id,
+	}
+	assert.Nil(t, db.Create(staleAttempt).Error)
+	assert.Nil(t, db.Model(staleAttempt).UpdateColumn("updated_at", time.Now().Add(-time.Hour)).Error)
+
+	// WHEN: The window reaches the session
+	controller.EnvSettings.StandingBookingWindowDays = windowDays + 7
+	report, err := controller.BookUpcomingSessions()
+
+	// THEN: The attempt is taken over and the session is booked
+	assert.Nil(t, err)
+	assert.Len(t, report.Booked, 1)
+	assert.Equal(t, staleAttempt.Id, report.Booked[0].Id)
+	assert.NotNil(t, report.Booked[0].ReservationId)
+}
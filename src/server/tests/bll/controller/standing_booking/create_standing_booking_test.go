@@ -0,0 +1,145 @@
+package standing_booking_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"onichankimochi.com/astro_cat_backend/src/server/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+// Creates a session of a service and professional starting at a given time
+func newSessionAt(
+	t *testing.T,
+	db *gorm.DB,
+	startTime time.Time,
+	option factories.SessionModelF,
+) (*model.Session, *model.CommunityService) {
+	endTime := startTime.Add(time.Hour)
+	option.Date = &startTime
+	option.StartTime = &startTime
+	option.EndTime = &endTime
+	session := factories.NewSessionModel(db, option)
+
+	var communityService model.CommunityService
+	assert.Nil(t, db.First(&communityService, "id = ?", *session.CommunityServiceId).Error)
+
+	return session, &communityService
+}
+
+// Builds the request of a standing booking matching the weekday and start time of a session, for a
+// user with a membership of its community
+func newStandingBookingRequest(
+	t *testing.T,
+	standingBookingController *controller.StandingBooking,
+	db *gorm.DB,
+	session *model.Session,
+	communityService *model.CommunityService,
+	membershipOption factories.MembershipModelF,
+) schemas.CreateStandingBookingRequest {
+	user := factories.NewUserModel(db, factories.UserModelF{})
+	membershipOption.UserId = &user.Id
+	membershipOption.CommunityId = &communityService.CommunityId
+	if membershipOption.ReservationsUsed == nil {
+		reservationsUsed := 0
+		membershipOption.ReservationsUsed = &reservationsUsed
+	}
+	membership := factories.NewMembershipModel(db, membershipOption)
+
+	localStart := session.StartTime.In(standingBookingController.EnvSettings.BookingTimeZone)
+	return schemas.CreateStandingBookingRequest{
+		UserId:         user.Id,
+		ServiceId:      communityService.ServiceId,
+		ProfessionalId: session.ProfessionalId,
+		Weekday:        int(localStart.Weekday()),
+		StartTime:      localStart.Format("15:04"),
+		MembershipId:   &membership.Id,
+	}
+}
+
+func TestCreateStandingBookingBooksMatchingSessions(t *testing.T) {
+	// GIVEN: A session within the booking window, a session of the same service and professional
+	// an hour later and a member subscribing to the weekday and time of the first one
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+
+	startTime := time.Now().AddDate(0, 0, 2).Truncate(time.Minute)
+	session, communityService := newSessionAt(t, db, startTime, factories.SessionModelF{})
+	otherSession, _ := newSessionAt(t, db, startTime.Add(time.Hour), factories.SessionModelF{
+		ProfessionalId:     &session.ProfessionalId,
+		CommunityServiceId: &communityService.Id,
+	})
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{})
+
+	// WHEN: The standing booking is created
+	standingBooking, err := controller.CreateStandingBooking(request, "test_user")
+
+	// THEN: Only the matching session is booked, with the membership of the standing booking
+	assert.Nil(t, err)
+	assert.True(t, standingBooking.Active)
+
+	var reservations []model.Reservation
+	assert.Nil(t, db.Where("user_id = ?", request.UserId).Find(&reservations).Error)
+	assert.Len(t, reservations, 1)
+	assert.Equal(t, session.Id, reservations[0].SessionId)
+	assert.Equal(t, model.ReservationStateConfirmed, reservations[0].State)
+	assert.Equal(t, *request.MembershipId, *reservations[0].MembershipId)
+	assert.NotEqual(t, otherSession.Id, reservations[0].SessionId)
+
+	attempts, err := controller.FetchStandingBookingAttempts(standingBooking.Id)
+	assert.Nil(t, err)
+	assert.Len(t, attempts.Attempts, 1)
+	assert.Equal(t, "BOOKED", attempts.Attempts[0].Status)
+	assert.Equal(t, reservations[0].Id, *attempts.Attempts[0].ReservationId)
+}
+
+func TestCreateStandingBookingInvalidPattern(t *testing.T) {
+	// GIVEN: Standing bookings with a weekday or start time out of range
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+
+	session, communityService := newSessionAt(t, db, time.Now().AddDate(0, 0, 2), factories.SessionModelF{})
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{})
+
+	badWeekday := request
+	badWeekday.Weekday = 7
+	badStartTime := request
+	badStartTime.StartTime = "25:00"
+
+	for name, request := range map[string]schemas.CreateStandingBookingRequest{
+		"weekday":    badWeekday,
+		"start time": badStartTime,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// WHEN: The standing booking is created
+			result, err := controller.CreateStandingBooking(request, "test_user")
+
+			// THEN: It is rejected
+			assert.Nil(t, result)
+			assert.NotNil(t, err)
+			assert.Equal(t, errors.BadRequestError.InvalidStandingBookingPattern, *err)
+		})
+	}
+}
+
+func TestCreateStandingBookingDuplicate(t *testing.T) {
+	// GIVEN: An existing standing booking
+	controller, _, db := controllerTest.NewStandingBookingControllerTestWrapper(t)
+
+	session, communityService := newSessionAt(t, db, time.Now().AddDate(0, 0, 2), factories.SessionModelF{})
+	request := newStandingBookingRequest(t, controller, db, session, communityService, factories.MembershipModelF{})
+	_, err := controller.CreateStandingBooking(request, "test_user")
+	assert.Nil(t, err)
+
+	// WHEN: The same pattern is subscribed again
+	result, err := controller.CreateStandingBooking(request, "test_user")
+
+	// THEN: It is rejected
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ConflictError.StandingBookingAlreadyExists, *err)
+}
@@ -0,0 +1,133 @@
+package two_factor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
+)
+
+func TestEnrollmentConfirmedWithValidCode(t *testing.T) {
+	/*
+		GIVEN: A user that started the two-factor enrolment
+		WHEN:  ConfirmEnrollment is called with the current code of the secret
+		THEN:  Two-factor is enabled, recovery codes are returned and the change is audited
+	*/
+	// GIVEN
+	twoFactorController, _, db := controllerTest.NewTwoFactorControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	enrollment, err := twoFactorController.BeginEnrollment(testUser.Id)
+	assert.Nil(t, err)
+	code, _ := utils.GenerateTotpCode(enrollment.Secret, utils.TotpStep(time.Now()))
+
+	// WHEN
+	result, err := twoFactorController.ConfirmEnrollment(testUser.Id, code)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Len(t, result.RecoveryCodes, twoFactorController.EnvSettings.TwoFactorRecoveryCodeCount)
+	assert.Contains(t, enrollment.ProvisioningUri, "otpauth://totp/")
+	assert.Contains(t, enrollment.ProvisioningUri, "secret="+enrollment.Secret)
+
+	var enabledUser model.User
+	db.First(&enabledUser, "id = ?", testUser.Id)
+	assert.NotNil(t, enabledUser.TwoFactorEnabledAt)
+
+	var storedCodes []model.TwoFactorRecoveryCode
+	db.Where("user_id = ?", testUser.Id).Find(&storedCodes)
+	assert.Len(t, storedCodes, len(result.RecoveryCodes))
+	for _, storedCode := range storedCodes {
+		assert.NotContains(t, result.RecoveryCodes, storedCode.CodeHash)
+	}
+
+	var enabledEvents int64
+	db.Model(&model.AuditLog{}).
+		Where("entity_id = ? AND action = ?", testUser.Id, model.AuditActionTwoFactorEnabled).
+		Count(&enabledEvents)
+	assert.Equal(t, int64(1), enabledEvents)
+}
+
+func TestEnrollmentRejectsInvalidCode(t *testing.T) {
+	/*
+		GIVEN: A user that started the two-factor enrolment
+		WHEN:  ConfirmEnrollment is called with a wrong code
+		THEN:  An invalid code error is returned and two-factor stays disabled
+	*/
+	// GIVEN
+	twoFactorController, _, db := controllerTest.NewTwoFactorControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	enrollment, _ := twoFactorController.BeginEnrollment(testUser.Id)
+	validCode, _ := utils.GenerateTotpCode(enrollment.Secret, utils.TotpStep(time.Now()))
+	wrongCode := "000000"
+	if validCode == wrongCode {
+		wrongCode = "111111"
+	}
+
+	// WHEN
+	result, err := twoFactorController.ConfirmEnrollment(testUser.Id, wrongCode)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.TwoFactorError.InvalidTwoFactorCode, *err)
+
+	var pendingUser model.User
+	db.First(&pendingUser, "id = ?", testUser.Id)
+	assert.Nil(t, pendingUser.TwoFactorEnabledAt)
+}
+
+func TestEnrollmentAlreadyEnabled(t *testing.T) {
+	/*
+		GIVEN: A user with two-factor already enabled
+		WHEN:  BeginEnrollment is called again
+		THEN:  An already enabled error is returned and the secret is kept
+	*/
+	// GIVEN
+	twoFactorController, _, db := controllerTest.NewTwoFactorControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	enrollment, _ := twoFactorController.BeginEnrollment(testUser.Id)
+	code, _ := utils.GenerateTotpCode(enrollment.Secret, utils.TotpStep(time.Now()))
+	twoFactorController.ConfirmEnrollment(testUser.Id, code)
+
+	// WHEN
+	result, err := twoFactorController.BeginEnrollment(testUser.Id)
+
+	// THEN
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.TwoFactorError.TwoFactorAlreadyEnabled, *err)
+
+	var enabledUser model.User
+	db.First(&enabledUser, "id = ?", testUser.Id)
+	assert.Equal(t, enrollment.Secret, *enabledUser.TwoFactorSecret)
+}
+
+func TestDisableForbiddenWhenMandatoryForAdmins(t *testing.T) {
+	/*
+		GIVEN: An administrator with two-factor enabled and the admin policy turned on
+		WHEN:  Disable is called with a valid code
+		THEN:  A forbidden error is returned
+	*/
+	// GIVEN
+	twoFactorController, _, db := controllerTest.NewTwoFactorControllerTestWrapper(t)
+	twoFactorController.EnvSettings.RequireAdminTwoFactor = true
+	defer func() { twoFactorController.EnvSettings.RequireAdminTwoFactor = false }()
+
+	adminRol := model.UserRolAdmin
+	testUser := factories.NewUserModel(db, factories.UserModelF{Rol: &adminRol})
+	enrollment, _ := twoFactorController.BeginEnrollment(testUser.Id)
+	code, _ := utils.GenerateTotpCode(enrollment.Secret, utils.TotpStep(time.Now()))
+	twoFactorController.ConfirmEnrollment(testUser.Id, code)
+
+	// WHEN
+	err := twoFactorController.Disable(testUser.Id, code)
+
+	// THEN
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ForbiddenError.TwoFactorRequired, *err)
+}
@@ -2,12 +2,15 @@ package user_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
 	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
 )
 
 func TestChangePasswordSuccessfully(t *testing.T) {
@@ -43,6 +46,36 @@ func TestChangePasswordSuccessfully(t *testing.T) {
 	updatedUser, getErr := controller.GetUser(testUser.Id)
 	assert.Nil(t, getErr)
 	assert.NotEqual(t, originalPassword, updatedUser.Password)
+	// Tokens issued before are invalidated once
+	assert.Equal(t, testUser.TokenVersion+1, updatedUser.TokenVersion)
+}
+
+func TestChangePasswordRevokesLoginSessions(t *testing.T) {
+	// GIVEN: A user logged in on a device
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+
+	email := utilsTest.GenerateRandomEmail()
+	testUser := factories.NewUserModel(db, factories.UserModelF{Email: &email})
+	loginSession, err := controller.Adapter.LoginSession.CreatePostgresqlLoginSession(
+		uuid.New(),
+		testUser.Id,
+		time.Now().Add(time.Hour),
+		"SYSTEM",
+	)
+	assert.Nil(t, err)
+
+	// WHEN: ChangePassword is called
+	err = controller.ChangePassword(email, schemas.ChangePasswordInput{
+		Email:       email,
+		NewPassword: "newSecurePassword123",
+	})
+
+	// THEN: The login session is revoked
+	assert.Nil(t, err)
+
+	revokedSession, getErr := controller.Adapter.LoginSession.GetPostgresqlLoginSession(loginSession.Id)
+	assert.Nil(t, getErr)
+	assert.NotNil(t, revokedSession.RevokedAt)
 }
 
 func TestChangePasswordUserNotFound(t *testing.T) {
@@ -0,0 +1,103 @@
+package user_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/astro_cat_psql/model"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+)
+
+func TestForceLogoutBumpsTokenVersion(t *testing.T) {
+	/*
+		GIVEN: An existing user
+		WHEN:  ForceLogout is called
+		THEN:  The user's token version is incremented
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	// WHEN
+	err := controller.ForceLogout(testUser.Id, "test_admin")
+
+	// THEN
+	assert.Nil(t, err)
+	var updatedUser model.User
+	db.First(&updatedUser, "id = ?", testUser.Id)
+	assert.Equal(t, testUser.TokenVersion+1, updatedUser.TokenVersion)
+}
+
+func TestForceLogoutUserNotFound(t *testing.T) {
+	/*
+		GIVEN: A non-existent user id
+		WHEN:  ForceLogout is called
+		THEN:  A user not found error is returned
+	*/
+	// GIVEN
+	controller, _, _ := controllerTest.NewUserControllerTestWrapper(t)
+
+	// WHEN
+	err := controller.ForceLogout(uuid.New(), "test_admin")
+
+	// THEN
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ObjectNotFoundError.UserNotFound, *err)
+}
+
+func TestUpdateUserRoleBumpsTokenVersion(t *testing.T) {
+	/*
+		GIVEN: An existing client user
+		WHEN:  UpdateUser changes the role to administrator
+		THEN:  The user's token version is incremented
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+	rol := model.UserRolClient
+	testUser := factories.NewUserModel(db, factories.UserModelF{Rol: &rol})
+
+	newRol := string(schemas.UserRolAdmin)
+
+	// WHEN
+	_, err := controller.UpdateUser(
+		testUser.Id,
+		schemas.UpdateUserRequest{Rol: &newRol},
+		"test_admin",
+	)
+
+	// THEN
+	assert.Nil(t, err)
+	var updatedUser model.User
+	db.First(&updatedUser, "id = ?", testUser.Id)
+	assert.Equal(t, testUser.TokenVersion+1, updatedUser.TokenVersion)
+}
+
+func TestUpdateUserNameKeepsTokenVersion(t *testing.T) {
+	/*
+		GIVEN: An existing user
+		WHEN:  UpdateUser only changes the name
+		THEN:  The user's token version is unchanged
+	*/
+	// GIVEN
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+
+	newName := "Renamed"
+
+	// WHEN
+	_, err := controller.UpdateUser(
+		testUser.Id,
+		schemas.UpdateUserRequest{Name: &newName},
+		"test_admin",
+	)
+
+	// THEN
+	assert.Nil(t, err)
+	var updatedUser model.User
+	db.First(&updatedUser, "id = ?", testUser.Id)
+	assert.Equal(t, testUser.TokenVersion, updatedUser.TokenVersion)
+}
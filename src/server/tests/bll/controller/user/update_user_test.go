@@ -2,6 +2,7 @@ package user_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -9,6 +10,8 @@ import (
 	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
 	"onichankimochi.com/astro_cat_backend/src/server/schemas"
 	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+	"onichankimochi.com/astro_cat_backend/src/server/utils"
 )
 
 func TestUpdateUserSuccessfully(t *testing.T) {
@@ -189,3 +192,100 @@ func TestUpdateUserPassword(t *testing.T) {
 	assert.NotEqual(t, originalPassword, result.Password)
 	// In current implementation, password might not be hashed - just ensure it's updated
 }
+
+func TestUpdateUserPasswordInvalidatesTokens(t *testing.T) {
+	// GIVEN: An existing user and a request with a new password
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+
+	testUser := factories.NewUserModel(db, factories.UserModelF{})
+	newPassword := "newSecurePassword123"
+
+	// WHEN: UpdateUser is called
+	result, err := controller.UpdateUser(
+		testUser.Id,
+		schemas.UpdateUserRequest{Password: &newPassword},
+		"test_admin",
+	)
+
+	// THEN: The password is stored hashed and the returned user carries the new token version
+	assert.Nil(t, err)
+	assert.Equal(t, testUser.TokenVersion+1, result.TokenVersion)
+	assert.Nil(t, utils.CheckPasswordHash(newPassword, result.Password))
+}
+
+func TestUpdateUserSamePasswordKeepsTokens(t *testing.T) {
+	// GIVEN: An existing user and a request with its current password
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+
+	currentPassword := "currentPassword123"
+	testUser := factories.NewUserModel(db, factories.UserModelF{Password: &currentPassword})
+
+	// WHEN: UpdateUser is called
+	result, err := controller.UpdateUser(
+		testUser.Id,
+		schemas.UpdateUserRequest{Password: &currentPassword},
+		"test_admin",
+	)
+
+	// THEN: The tokens of the user stay valid
+	assert.Nil(t, err)
+	assert.Equal(t, testUser.TokenVersion, result.TokenVersion)
+	assert.Nil(t, utils.CheckPasswordHash(currentPassword, result.Password))
+}
+
+func TestUpdateUserEmailResetsVerification(t *testing.T) {
+	// GIVEN: A user whose email was verified
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+
+	verifiedAt := time.Now().Add(-time.Hour)
+	testUser := factories.NewUserModel(db, factories.UserModelF{EmailVerifiedAt: &verifiedAt})
+
+	// WHEN: UpdateUser is called with a new email
+	newEmail := utilsTest.GenerateRandomEmail()
+	result, err := controller.UpdateUser(
+		testUser.Id,
+		schemas.UpdateUserRequest{Email: &newEmail},
+		"test_admin",
+	)
+
+	// THEN: The new email is unverified and a verification code is pending for it
+	assert.Nil(t, err)
+	assert.Equal(t, newEmail, result.Email)
+	assert.Nil(t, result.EmailVerifiedAt)
+
+	var storedUser model.User
+	assert.Nil(t, db.First(&storedUser, "id = ?", testUser.Id).Error)
+	assert.Nil(t, storedUser.EmailVerifiedAt)
+
+	var pendingVerifications int64
+	db.Model(&model.EmailVerification{}).
+		Where("user_id = ? AND consumed_at IS NULL", testUser.Id).
+		Count(&pendingVerifications)
+	assert.Equal(t, int64(1), pendingVerifications)
+}
+
+func TestUpdateUserSameEmailKeepsVerification(t *testing.T) {
+	// GIVEN: A user whose email was verified
+	controller, _, db := controllerTest.NewUserControllerTestWrapper(t)
+
+	verifiedAt := time.Now().Add(-time.Hour)
+	testUser := factories.NewUserModel(db, factories.UserModelF{EmailVerifiedAt: &verifiedAt})
+
+	// WHEN: UpdateUser is called with the email it already has
+	sameEmail := testUser.Email
+	result, err := controller.UpdateUser(
+		testUser.Id,
+		schemas.UpdateUserRequest{Email: &sameEmail},
+		"test_admin",
+	)
+
+	// THEN: The email stays verified and no code is sent
+	assert.Nil(t, err)
+	assert.NotNil(t, result.EmailVerifiedAt)
+
+	var pendingVerifications int64
+	db.Model(&model.EmailVerification{}).
+		Where("user_id = ?", testUser.Id).
+		Count(&pendingVerifications)
+	assert.Equal(t, int64(0), pendingVerifications)
+}
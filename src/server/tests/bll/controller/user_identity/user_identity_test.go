@@ -0,0 +1,94 @@
+package user_identity_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"onichankimochi.com/astro_cat_backend/src/server/dao/factories"
+	"onichankimochi.com/astro_cat_backend/src/server/errors"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+	controllerTest "onichankimochi.com/astro_cat_backend/src/server/tests/bll/controller"
+	utilsTest "onichankimochi.com/astro_cat_backend/src/server/tests/utils"
+)
+
+func TestLinkIdentity(t *testing.T) {
+	/*
+		GIVEN: A user and an ID token of a configured provider
+		WHEN:  LinkIdentity is called
+		THEN:  The identity is listed among the identities of the user
+	*/
+	// GIVEN
+	identityController, _, db := controllerTest.NewUserIdentityControllerTestWrapper(t)
+	stub := utilsTest.RegisterOidcProviderStub(t, identityController.EnvSettings, "stub")
+	testUser := factories.NewUserModel(db)
+	subject := uuid.NewString()
+
+	// WHEN
+	identity, err := identityController.LinkIdentity(
+		context.Background(),
+		testUser.Id,
+		schemas.LinkUserIdentityRequest{
+			Provider: "stub",
+			Token:    stub.SignIdToken(t, subject, testUser.Email, false),
+		},
+	)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, subject, identity.Subject)
+	identities, _ := identityController.FetchUserIdentities(testUser.Id)
+	assert.Len(t, identities.Identities, 1)
+	assert.Equal(t, "stub", identities.Identities[0].Provider)
+}
+
+func TestLinkIdentityOfAnotherUser(t *testing.T) {
+	/*
+		GIVEN: An identity linked to a user
+		WHEN:  Another user tries to link it
+		THEN:  A conflict error is returned
+	*/
+	// GIVEN
+	identityController, _, db := controllerTest.NewUserIdentityControllerTestWrapper(t)
+	stub := utilsTest.RegisterOidcProviderStub(t, identityController.EnvSettings, "stub")
+	owner := factories.NewUserModel(db)
+	otherUser := factories.NewUserModel(db)
+	request := schemas.LinkUserIdentityRequest{
+		Provider: "stub",
+		Token:    stub.SignIdToken(t, uuid.NewString(), owner.Email, true),
+	}
+	identityController.LinkIdentity(context.Background(), owner.Id, request)
+
+	// WHEN
+	identity, err := identityController.LinkIdentity(context.Background(), otherUser.Id, request)
+
+	// THEN
+	assert.Nil(t, identity)
+	assert.Equal(t, errors.ConflictError.UserIdentityAlreadyLinked, *err)
+}
+
+func TestUnlinkLastIdentityOfAccountWithoutPassword(t *testing.T) {
+	/*
+		GIVEN: A user without password and a single linked identity
+		WHEN:  UnlinkIdentity is called
+		THEN:  The identity is kept since it is the only way to log in
+	*/
+	// GIVEN
+	identityController, _, db := controllerTest.NewUserIdentityControllerTestWrapper(t)
+	stub := utilsTest.RegisterOidcProviderStub(t, identityController.EnvSettings, "stub")
+	testUser := factories.NewUserModel(db)
+	db.Model(testUser).Update("password", "")
+	identityController.LinkIdentity(context.Background(), testUser.Id, schemas.LinkUserIdentityRequest{
+		Provider: "stub",
+		Token:    stub.SignIdToken(t, uuid.NewString(), testUser.Email, true),
+	})
+
+	// WHEN
+	err := identityController.UnlinkIdentity(testUser.Id, "stub")
+
+	// THEN
+	assert.Equal(t, errors.BadRequestError.LastLoginMethod, *err)
+	identities, _ := identityController.FetchUserIdentities(testUser.Id)
+	assert.Len(t, identities.Identities, 1)
+}
@@ -88,6 +88,22 @@ func ClearPostgresqlDatabaseTesting(
 		}{
 			// First delete tables with foreign key dependencies
 			{"AuditLog", &model.AuditLog{}}, // Clear audit logs first to avoid FK constraints
+			{"RefreshToken", &model.RefreshToken{}},
+			{"PasswordReset", &model.PasswordReset{}},
+			{"EmailVerification", &model.EmailVerification{}},
+			{"RateLimitCounter", &model.RateLimitCounter{}},
+			{"TwoFactorRecoveryCode", &model.TwoFactorRecoveryCode{}},
+			{"UserRoleAssignment", &model.UserRoleAssignment{}},
+			{"ServiceAccount", &model.ServiceAccount{}},
+			{"UserIdentity", &model.UserIdentity{}},
+			{"LoginSession", &model.LoginSession{}},
+			{"CancellationPolicy", &model.CancellationPolicy{}},
+			{"StandingBookingAttempt", &model.StandingBookingAttempt{}},
+			{"StandingBooking", &model.StandingBooking{}},
+			{"IdempotencyKey", &model.IdempotencyKey{}},
+			{"ReservationStateHistory", &model.ReservationStateHistory{}},
+			{"ProfessionalWorkingHours", &model.ProfessionalWorkingHours{}},
+			{"ProfessionalTimeOff", &model.ProfessionalTimeOff{}},
 			{"Membership", &model.Membership{}},
 			{"CommunityPlan", &model.CommunityPlan{}},
 			{"CommunityService", &model.CommunityService{}},
@@ -95,6 +111,7 @@ func ClearPostgresqlDatabaseTesting(
 			{"ServiceLocal", &model.ServiceLocal{}},
 			{"Reservation", &model.Reservation{}},
 			{"Session", &model.Session{}},
+			{"SessionSeries", &model.SessionSeries{}},
 			{"Onboarding", &model.Onboarding{}},
 			{"Template", &model.Template{}},
 
@@ -176,6 +193,22 @@ func ClearPostgresqlDatabase(
 		}{
 			// First delete tables with foreign key dependencies
 			{"AuditLog", &model.AuditLog{}}, // Clear audit logs first to avoid FK constraints
+			{"RefreshToken", &model.RefreshToken{}},
+			{"PasswordReset", &model.PasswordReset{}},
+			{"EmailVerification", &model.EmailVerification{}},
+			{"RateLimitCounter", &model.RateLimitCounter{}},
+			{"TwoFactorRecoveryCode", &model.TwoFactorRecoveryCode{}},
+			{"UserRoleAssignment", &model.UserRoleAssignment{}},
+			{"ServiceAccount", &model.ServiceAccount{}},
+			{"UserIdentity", &model.UserIdentity{}},
+			{"LoginSession", &model.LoginSession{}},
+			{"CancellationPolicy", &model.CancellationPolicy{}},
+			{"StandingBookingAttempt", &model.StandingBookingAttempt{}},
+			{"StandingBooking", &model.StandingBooking{}},
+			{"IdempotencyKey", &model.IdempotencyKey{}},
+			{"ReservationStateHistory", &model.ReservationStateHistory{}},
+			{"ProfessionalWorkingHours", &model.ProfessionalWorkingHours{}},
+			{"ProfessionalTimeOff", &model.ProfessionalTimeOff{}},
 			{"Membership", &model.Membership{}},
 			{"CommunityPlan", &model.CommunityPlan{}},
 			{"CommunityService", &model.CommunityService{}},
@@ -183,6 +216,7 @@ func ClearPostgresqlDatabase(
 			{"ServiceLocal", &model.ServiceLocal{}},
 			{"Reservation", &model.Reservation{}},
 			{"Session", &model.Session{}},
+			{"SessionSeries", &model.SessionSeries{}},
 			{"Onboarding", &model.Onboarding{}},
 			{"Template", &model.Template{}},
 
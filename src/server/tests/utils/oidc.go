@@ -0,0 +1,115 @@
+package utils_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// Local stand-in of an OpenID Connect provider: serves a JWKS with a generated RSA key and signs
+// ID tokens with it
+type OidcProviderStub struct {
+	Server   *httptest.Server
+	Issuer   string
+	ClientId string
+	keyId    string
+	key      *rsa.PrivateKey
+}
+
+func NewOidcProviderStub(t *testing.T) *OidcProviderStub {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate OIDC stub key: %v", err)
+	}
+
+	stub := &OidcProviderStub{
+		ClientId: "astro-cat-test-client",
+		keyId:    GenerateRandomString(8),
+		key:      key,
+	}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": stub.keyId,
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	stub.Issuer = stub.Server.URL
+	t.Cleanup(stub.Server.Close)
+
+	return stub
+}
+
+// Provider settings that trust this stub
+func (s *OidcProviderStub) Provider(name string) *schemas.OidcProvider {
+	return &schemas.OidcProvider{
+		Name:     name,
+		Issuers:  []string{s.Issuer},
+		ClientId: s.ClientId,
+		JwksUrl:  s.Server.URL,
+	}
+}
+
+// Signs an ID token for the given subject and email, valid for an hour
+func (s *OidcProviderStub) SignIdToken(
+	t *testing.T,
+	subject string,
+	email string,
+	emailVerified bool,
+) string {
+	claims := schemas.OidcIdTokenClaims{
+		Email:         email,
+		EmailVerified: emailVerified,
+		Name:          "Stub User",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.Issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{s.ClientId},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	return s.Sign(t, claims)
+}
+
+// Signs arbitrary claims, e.g. to build expired tokens or tokens of another audience
+func (s *OidcProviderStub) Sign(t *testing.T, claims schemas.OidcIdTokenClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyId
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		t.Fatalf("failed to sign ID token: %v", err)
+	}
+	return signed
+}
+
+// Starts a provider stub and registers it under the given name until the test ends
+func RegisterOidcProviderStub(
+	t *testing.T,
+	envSettings *schemas.EnvSettings,
+	name string,
+) *OidcProviderStub {
+	stub := NewOidcProviderStub(t)
+	if envSettings.OidcProviders == nil {
+		envSettings.OidcProviders = map[string]*schemas.OidcProvider{}
+	}
+	envSettings.OidcProviders[name] = stub.Provider(name)
+	t.Cleanup(func() { delete(envSettings.OidcProviders, name) })
+
+	return stub
+}
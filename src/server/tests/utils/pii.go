@@ -0,0 +1,22 @@
+package utils_test
+
+import (
+	"bytes"
+
+	"onichankimochi.com/astro_cat_backend/src/server/schemas"
+)
+
+// Id of the PII key configured by ConfigurePiiEncryption
+const TestPiiKeyId = "test"
+
+// Enables the encryption of personal data with fixed keys when the environment has none, so tests
+// go through the same encrypted storage as production
+func ConfigurePiiEncryption(envSettings *schemas.EnvSettings) {
+	if len(envSettings.PiiEncryptionKeys) > 0 {
+		return
+	}
+
+	envSettings.PiiEncryptionKeys = map[string][]byte{TestPiiKeyId: bytes.Repeat([]byte{1}, 32)}
+	envSettings.PiiEncryptionKeyId = TestPiiKeyId
+	envSettings.PiiBlindIndexKey = bytes.Repeat([]byte{2}, 32)
+}
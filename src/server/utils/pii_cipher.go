@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Prefix of every value encrypted by a PiiCipher. Values without it are read as plaintext, which
+// keeps the rows written before encryption was enabled readable until they are migrated.
+const piiCiphertextPrefix = "pii:v1:"
+
+// Size in bytes of the key encryption keys and of the data keys
+const piiKeySize = 32
+
+// Envelope encryption of personal data. Every value is sealed with its own random data key, and the
+// data key is sealed with the active key encryption key. The id of that key travels with the value
+// so older keys keep decrypting after a rotation.
+//
+// Encrypted values have the form pii:v1:<key id>:<sealed data key>:<sealed value>, both sealed parts
+// being base64 of an AES-256-GCM nonce followed by the ciphertext.
+type PiiCipher struct {
+	keys          map[string][]byte
+	activeKeyId   string
+	blindIndexKey []byte
+}
+
+// Without keys the cipher is disabled: values are stored and read as plaintext
+func NewPiiCipher(keys map[string][]byte, activeKeyId string, blindIndexKey []byte) (*PiiCipher, error) {
+	for keyId, key := range keys {
+		if keyId == "" || strings.Contains(keyId, ":") {
+			return nil, fmt.Errorf("invalid PII key id %q", keyId)
+		}
+		if len(key) != piiKeySize {
+			return nil, fmt.Errorf("PII key %s must be %d bytes long", keyId, piiKeySize)
+		}
+	}
+	if len(keys) > 0 {
+		if _, ok := keys[activeKeyId]; !ok {
+			return nil, fmt.Errorf("active PII key %q is not configured", activeKeyId)
+		}
+	}
+
+	return &PiiCipher{
+		keys:          keys,
+		activeKeyId:   activeKeyId,
+		blindIndexKey: blindIndexKey,
+	}, nil
+}
+
+func (p *PiiCipher) Enabled() bool {
+	return len(p.keys) > 0
+}
+
+// Encrypts a value with the active key. Empty values are kept empty.
+func (p *PiiCipher) Encrypt(plaintext string) (string, error) {
+	if !p.Enabled() || plaintext == "" {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, piiKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	sealedValue, err := sealAesGcm(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	sealedDataKey, err := sealAesGcm(p.keys[p.activeKeyId], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return piiCiphertextPrefix + p.activeKeyId + ":" +
+		base64.RawStdEncoding.EncodeToString(sealedDataKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealedValue), nil
+}
+
+// Decrypts a value written by Encrypt with any of the configured keys. Plaintext values are
+// returned as they are.
+func (p *PiiCipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, piiCiphertextPrefix) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, piiCiphertextPrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed PII ciphertext")
+	}
+	key, ok := p.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("PII key %s is not configured", parts[0])
+	}
+	sealedDataKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed PII data key: %w", err)
+	}
+	sealedValue, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed PII ciphertext: %w", err)
+	}
+
+	dataKey, err := openAesGcm(key, sealedDataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := openAesGcm(dataKey, sealedValue)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// Whether a stored value is already in the form Encrypt writes today, i.e. empty, encrypted with the
+// active key, or plaintext while the cipher is disabled
+func (p *PiiCipher) IsCurrent(value string) bool {
+	if value == "" {
+		return true
+	}
+	if !p.Enabled() {
+		return !strings.HasPrefix(value, piiCiphertextPrefix)
+	}
+	return strings.HasPrefix(value, piiCiphertextPrefix+p.activeKeyId+":")
+}
+
+// Keyed hash of a value, stored next to its ciphertext so equality lookups still work. The value is
+// normalized first so spacing and letter case do not matter.
+func (p *PiiCipher) BlindIndex(value string) string {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(value), ""))
+	if normalized == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, p.blindIndexKey)
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func sealAesGcm(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAesGcm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openAesGcm(key []byte, sealed []byte) ([]byte, error) {
+	aead, err := newAesGcm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed PII ciphertext")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newAesGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
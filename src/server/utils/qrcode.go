@@ -0,0 +1,550 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// QR code encoder for short payloads such as signed tokens, following ISO/IEC 18004 with byte mode,
+// error correction level M and versions 1 to 15 (up to 412 bytes).
+
+// Returned when a payload does not fit in the largest supported QR code version
+var ErrQrPayloadTooLong = errors.New("payload too long for a QR code")
+
+// Modules of light margin around a QR code, as required by readers
+const qrQuietZone = 4
+
+// Error correction blocks of a version at level M: codewords of error correction per block, then
+// the number of blocks and their data codewords for each of the two groups.
+type qrBlockLayout struct {
+	eccPerBlock  int
+	group1Blocks int
+	group1Data   int
+	group2Blocks int
+	group2Data   int
+}
+
+var qrBlockLayouts = []qrBlockLayout{
+	{10, 1, 16, 0, 0},
+	{16, 1, 28, 0, 0},
+	{26, 1, 44, 0, 0},
+	{18, 2, 32, 0, 0},
+	{24, 2, 43, 0, 0},
+	{16, 4, 27, 0, 0},
+	{18, 4, 31, 0, 0},
+	{22, 2, 38, 2, 39},
+	{22, 3, 36, 2, 37},
+	{26, 4, 43, 1, 44},
+	{30, 1, 50, 4, 51},
+	{22, 6, 36, 2, 37},
+	{22, 8, 37, 1, 38},
+	{24, 4, 40, 5, 41},
+	{24, 5, 41, 5, 42},
+}
+
+var qrAlignmentPositions = [][]int{
+	{},
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+	{6, 30, 54},
+	{6, 32, 58},
+	{6, 34, 62},
+	{6, 26, 46, 66},
+	{6, 26, 48, 70},
+}
+
+// A QR code as a square of modules, true being dark
+type QrCode struct {
+	Size    int
+	modules [][]bool
+}
+
+// Whether the module at a column and row is dark
+func (q *QrCode) IsDark(x int, y int) bool {
+	return q.modules[y][x]
+}
+
+// Encodes a payload in the smallest QR code version that fits it
+func EncodeQrCode(payload []byte) (*QrCode, error) {
+	version := 0
+	for v := 1; v <= len(qrBlockLayouts); v++ {
+		if qrDataBits(payload, v) <= qrDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrQrPayloadTooLong
+	}
+
+	builder := newQrBuilder(version)
+	builder.drawFunctionPatterns()
+	builder.drawCodewords(qrAddErrorCorrection(qrEncodeData(payload, version), version))
+
+	// Keep the mask that leaves the fewest patterns confusing readers
+	bestMask, bestPenalty := 0, -1
+	for mask := range 8 {
+		builder.applyMask(mask)
+		builder.drawFormatBits(mask)
+		if penalty := builder.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		builder.applyMask(mask) // Masks are their own inverse
+	}
+	builder.applyMask(bestMask)
+	builder.drawFormatBits(bestMask)
+
+	return &QrCode{Size: builder.size, modules: builder.modules}, nil
+}
+
+// Renders a QR code as an SVG document with one unit per module
+func (q *QrCode) Svg() string {
+	total := q.Size + 2*qrQuietZone
+
+	var path strings.Builder
+	for y := range q.Size {
+		for x := range q.Size {
+			if q.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+
+	return fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="100%%" height="100%%" fill="#ffffff"/><path d="%s" fill="#000000"/></svg>`,
+		total,
+		total,
+		path.String(),
+	)
+}
+
+// Renders a QR code as a PNG image with the given pixels per module
+func (q *QrCode) Png(scale int) ([]byte, error) {
+	total := (q.Size + 2*qrQuietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, total, total))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for y := range q.Size {
+		for x := range q.Size {
+			if !q.modules[y][x] {
+				continue
+			}
+			for dy := range scale {
+				for dx := range scale {
+					img.SetGray((x+qrQuietZone)*scale+dx, (y+qrQuietZone)*scale+dy, color.Gray{Y: 0})
+				}
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func qrDataCodewords(version int) int {
+	layout := qrBlockLayouts[version-1]
+	return layout.group1Blocks*layout.group1Data + layout.group2Blocks*layout.group2Data
+}
+
+func qrCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func qrDataBits(payload []byte, version int) int {
+	return 4 + qrCountBits(version) + 8*len(payload)
+}
+
+// Encodes a payload in byte mode, padded to the data capacity of a version
+func qrEncodeData(payload []byte, version int) []byte {
+	capacity := qrDataCodewords(version) * 8
+
+	var bits []bool
+	appendBits := func(value int, length int) {
+		for i := length - 1; i >= 0; i-- {
+			bits = append(bits, (value>>i)&1 == 1)
+		}
+	}
+
+	appendBits(0b0100, 4)
+	appendBits(len(payload), qrCountBits(version))
+	for _, b := range payload {
+		appendBits(int(b), 8)
+	}
+	appendBits(0, min(4, capacity-len(bits)))
+	if remainder := len(bits) % 8; remainder != 0 {
+		appendBits(0, 8-remainder)
+	}
+	for padByte := 0xec; len(bits) < capacity; padByte ^= 0xec ^ 0x11 {
+		appendBits(padByte, 8)
+	}
+
+	data := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return data
+}
+
+// Splits data codewords into blocks, adds their Reed-Solomon codewords and interleaves them
+func qrAddErrorCorrection(data []byte, version int) []byte {
+	layout := qrBlockLayouts[version-1]
+	divisor := qrReedSolomonDivisor(layout.eccPerBlock)
+
+	var dataBlocks, eccBlocks [][]byte
+	offset := 0
+	for i := range layout.group1Blocks + layout.group2Blocks {
+		length := layout.group1Data
+		if i >= layout.group1Blocks {
+			length = layout.group2Data
+		}
+		block := data[offset : offset+length]
+		offset += length
+		dataBlocks = append(dataBlocks, block)
+		eccBlocks = append(eccBlocks, qrReedSolomonRemainder(block, divisor))
+	}
+
+	var result []byte
+	for i := range max(layout.group1Data, layout.group2Data) {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := range layout.eccPerBlock {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// Multiplies two elements of GF(2^8) modulo the QR code polynomial x^8 + x^4 + x^3 + x^2 + 1
+func qrGfMultiply(x byte, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11d)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// Coefficients of the Reed-Solomon generator polynomial of a degree, highest first without the
+// leading 1
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for range degree {
+		for j := range degree {
+			result[j] = qrGfMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrGfMultiply(root, 0x02)
+	}
+	return result
+}
+
+func qrReedSolomonRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= qrGfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+type qrBuilder struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newQrBuilder(version int) *qrBuilder {
+	size := version*4 + 17
+	builder := &qrBuilder{version: version, size: size}
+	builder.modules = make([][]bool, size)
+	builder.isFunction = make([][]bool, size)
+	for i := range size {
+		builder.modules[i] = make([]bool, size)
+		builder.isFunction[i] = make([]bool, size)
+	}
+	return builder
+}
+
+func (b *qrBuilder) setFunction(x int, y int, dark bool) {
+	b.modules[y][x] = dark
+	b.isFunction[y][x] = true
+}
+
+// Draws the finder, timing and alignment patterns and reserves the format and version areas
+func (b *qrBuilder) drawFunctionPatterns() {
+	for i := range b.size {
+		b.setFunction(6, i, i%2 == 0)
+		b.setFunction(i, 6, i%2 == 0)
+	}
+
+	for _, center := range [][2]int{{3, 3}, {b.size - 4, 3}, {3, b.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := center[0]+dx, center[1]+dy
+				if x < 0 || x >= b.size || y < 0 || y >= b.size {
+					continue
+				}
+				distance := max(abs(dx), abs(dy))
+				b.setFunction(x, y, distance != 2 && distance != 4)
+			}
+		}
+	}
+
+	positions := qrAlignmentPositions[b.version-1]
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			// Alignment patterns never overlap the finders
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					b.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	b.drawFormatBits(0)
+	b.drawVersionBits()
+}
+
+// Draws both copies of the format information of a mask at level M, and the dark module
+func (b *qrBuilder) drawFormatBits(mask int) {
+	data := mask // Level M is 00
+	remainder := data
+	for range 10 {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		b.setFunction(8, i, bit(i))
+	}
+	b.setFunction(8, 7, bit(6))
+	b.setFunction(8, 8, bit(7))
+	b.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		b.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		b.setFunction(b.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		b.setFunction(8, b.size-15+i, bit(i))
+	}
+	b.setFunction(8, b.size-8, true)
+}
+
+// Draws both copies of the version information, which versions 7 and up carry
+func (b *qrBuilder) drawVersionBits() {
+	if b.version < 7 {
+		return
+	}
+
+	remainder := b.version
+	for range 12 {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1f25)
+	}
+	bits := b.version<<12 | remainder
+
+	for i := range 18 {
+		dark := (bits>>i)&1 == 1
+		near, far := i/3, b.size-11+i%3
+		b.setFunction(far, near, dark)
+		b.setFunction(near, far, dark)
+	}
+}
+
+// Places the codewords in the zigzag order of the standard, skipping function modules
+func (b *qrBuilder) drawCodewords(codewords []byte) {
+	i := 0
+	for right := b.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vertical := range b.size {
+			for j := range 2 {
+				x := right - j
+				y := vertical
+				if upward {
+					y = b.size - 1 - vertical
+				}
+				if b.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				b.modules[y][x] = (codewords[i/8]>>(7-i%8))&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (b *qrBuilder) applyMask(mask int) {
+	for y := range b.size {
+		for x := range b.size {
+			if b.isFunction[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				b.modules[y][x] = !b.modules[y][x]
+			}
+		}
+	}
+}
+
+// Penalty score of the current modules as defined by the standard to pick a mask
+func (b *qrBuilder) penalty() int {
+	result := 0
+
+	// Runs of five or more modules of the same color, in rows and columns
+	for i := range b.size {
+		rowRun, columnRun := 1, 1
+		for j := 1; j < b.size; j++ {
+			if b.modules[i][j] == b.modules[i][j-1] {
+				rowRun++
+			} else {
+				rowRun = 1
+			}
+			if rowRun == 5 {
+				result += 3
+			} else if rowRun > 5 {
+				result++
+			}
+
+			if b.modules[j][i] == b.modules[j-1][i] {
+				columnRun++
+			} else {
+				columnRun = 1
+			}
+			if columnRun == 5 {
+				result += 3
+			} else if columnRun > 5 {
+				result++
+			}
+		}
+	}
+
+	// Blocks of 2x2 modules of the same color
+	for y := 0; y < b.size-1; y++ {
+		for x := 0; x < b.size-1; x++ {
+			shade := b.modules[y][x]
+			if shade == b.modules[y][x+1] && shade == b.modules[y+1][x] && shade == b.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	// Patterns looking like a finder, in rows and columns
+	finderLike := []bool{true, false, true, true, true, false, true}
+	matches := func(get func(k int) bool, start int) bool {
+		for k, dark := range finderLike {
+			if get(start+k) != dark {
+				return false
+			}
+		}
+		return true
+	}
+	lightRun := func(get func(k int) bool, from int, to int) bool {
+		for k := from; k < to; k++ {
+			if k >= 0 && k < b.size && get(k) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := range b.size {
+		row := func(k int) bool { return b.modules[i][k] }
+		column := func(k int) bool { return b.modules[k][i] }
+		for _, get := range []func(k int) bool{row, column} {
+			for start := 0; start+7 <= b.size; start++ {
+				if matches(get, start) && (lightRun(get, start-4, start) || lightRun(get, start+7, start+11)) {
+					result += 40
+				}
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	dark := 0
+	for _, row := range b.modules {
+		for _, module := range row {
+			if module {
+				dark++
+			}
+		}
+	}
+	total := b.size * b.size
+	result += abs(dark*20-total*10) / total * 10
+
+	return result
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format of the dates of UNTIL and EXDATE values
+const recurrenceDateLayout = "20060102"
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Subset of the RRULE of RFC 5545 that session series support: FREQ=WEEKLY with BYDAY, an
+// optional INTERVAL and at most one of UNTIL and COUNT. Weeks start on Monday.
+type RecurrenceRule struct {
+	Interval int
+	Weekdays []time.Weekday
+	// Last day an occurrence can fall on, at midnight UTC
+	Until *time.Time
+	// Number of occurrences, counting the excluded ones as RFC 5545 does
+	Count int
+}
+
+// Parses an RRULE value such as FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10, with or without the RRULE: prefix
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	rule := &RecurrenceRule{Interval: 1}
+
+	frequency := ""
+	for _, part := range strings.Split(value, ";") {
+		name, partValue, ok := strings.Cut(part, "=")
+		if !ok || partValue == "" {
+			return nil, fmt.Errorf("malformed rule part %q", part)
+		}
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			frequency = strings.ToUpper(partValue)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(partValue)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", partValue)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(partValue), ",") {
+				weekday, ok := recurrenceWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY day %q", day)
+				}
+				rule.Weekdays = append(rule.Weekdays, weekday)
+			}
+		case "UNTIL":
+			until, err := parseRecurrenceDate(partValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", partValue)
+			}
+			rule.Until = &until
+		case "COUNT":
+			count, err := strconv.Atoi(partValue)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", partValue)
+			}
+			rule.Count = count
+		case "WKST":
+			if strings.ToUpper(partValue) != "MO" {
+				return nil, fmt.Errorf("unsupported WKST %q", partValue)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", name)
+		}
+	}
+
+	if frequency != "WEEKLY" {
+		return nil, fmt.Errorf("unsupported FREQ %q", frequency)
+	}
+	if len(rule.Weekdays) == 0 {
+		return nil, fmt.Errorf("missing BYDAY")
+	}
+	if rule.Until != nil && rule.Count > 0 {
+		return nil, fmt.Errorf("UNTIL and COUNT cannot be used together")
+	}
+
+	sort.Slice(rule.Weekdays, func(i, j int) bool {
+		return mondayFirst(rule.Weekdays[i]) < mondayFirst(rule.Weekdays[j])
+	})
+	return rule, nil
+}
+
+// Formats the rule back as an RRULE value
+func (r *RecurrenceRule) String() string {
+	days := make([]string, len(r.Weekdays))
+	for i, weekday := range r.Weekdays {
+		for name, day := range recurrenceWeekdays {
+			if day == weekday {
+				days[i] = name
+			}
+		}
+	}
+
+	parts := []string{"FREQ=WEEKLY"}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format(recurrenceDateLayout))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Gets the days of the occurrences of a series starting on `start` that fall within [from, to),
+// leaving out the exceptions. Days are taken and returned at midnight UTC.
+func (r *RecurrenceRule) Occurrences(
+	start time.Time,
+	from time.Time,
+	to time.Time,
+	exceptions []time.Time,
+) []time.Time {
+	start = TruncateToDay(start)
+	from = TruncateToDay(from)
+
+	excluded := map[time.Time]bool{}
+	for _, exception := range exceptions {
+		excluded[TruncateToDay(exception)] = true
+	}
+
+	occurrences := []time.Time{}
+	count := 0
+	for day := start; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if r.Until != nil && day.After(*r.Until) {
+			break
+		}
+		if r.Count > 0 && count >= r.Count {
+			break
+		}
+		if !r.matches(start, day) {
+			continue
+		}
+
+		count++
+		if !day.Before(from) && !excluded[day] {
+			occurrences = append(occurrences, day)
+		}
+	}
+
+	return occurrences
+}
+
+// Gets the rule of the part of a series starting on `start` that comes before `day`
+func (r *RecurrenceRule) EndingBefore(start time.Time, day time.Time) *RecurrenceRule {
+	day = TruncateToDay(day)
+	if r.Count > 0 && len(r.Occurrences(start, start, day, nil)) >= r.Count {
+		return &RecurrenceRule{Interval: r.Interval, Weekdays: r.Weekdays, Count: r.Count}
+	}
+
+	until := day.AddDate(0, 0, -1)
+	if r.Until != nil && r.Until.Before(until) {
+		until = *r.Until
+	}
+
+	return &RecurrenceRule{Interval: r.Interval, Weekdays: r.Weekdays, Until: &until}
+}
+
+// Gets the rule of the part of a series starting on `start` that begins on `day`, so that both
+// parts together make the same occurrences. The weeks of INTERVAL keep counting from `day`, which
+// must be an occurrence for them to stay aligned.
+func (r *RecurrenceRule) StartingOn(start time.Time, day time.Time) *RecurrenceRule {
+	rule := &RecurrenceRule{Interval: r.Interval, Weekdays: r.Weekdays, Until: r.Until}
+	if r.Count > 0 {
+		rule.Count = r.Count - len(r.Occurrences(start, start, TruncateToDay(day), nil))
+		if rule.Count < 1 {
+			rule.Count = 1
+		}
+	}
+
+	return rule
+}
+
+func (r *RecurrenceRule) matches(start time.Time, day time.Time) bool {
+	weeks := int(startOfWeek(day).Sub(startOfWeek(start)).Hours() / 24 / 7)
+	if weeks%r.Interval != 0 {
+		return false
+	}
+
+	for _, weekday := range r.Weekdays {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses an EXDATE value: dates as YYYYMMDD separated by commas
+func ParseRecurrenceDates(value string) ([]time.Time, error) {
+	dates := []time.Time{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		date, err := parseRecurrenceDate(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", part)
+		}
+		dates = append(dates, date)
+	}
+
+	return dates, nil
+}
+
+// Formats dates as an EXDATE value
+func FormatRecurrenceDates(dates []time.Time) string {
+	values := make([]string, len(dates))
+	for i, date := range dates {
+		values[i] = date.Format(recurrenceDateLayout)
+	}
+	return strings.Join(values, ",")
+}
+
+// Gets the day of a time, in its own location, at midnight UTC
+func TruncateToDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Dates may also come as date-times (YYYYMMDDTHHMMSSZ), of which only the day is kept
+func parseRecurrenceDate(value string) (time.Time, error) {
+	if len(value) > len(recurrenceDateLayout) && value[len(recurrenceDateLayout)] == 'T' {
+		value = value[:len(recurrenceDateLayout)]
+	}
+	return time.Parse(recurrenceDateLayout, value)
+}
+
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -mondayFirst(day.Weekday()))
+}
+
+// Position of a weekday in a week starting on Monday
+func mondayFirst(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
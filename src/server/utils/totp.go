@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters of RFC 6238 as expected by common authenticator apps
+const (
+	TotpDigits = 6
+	TotpPeriod = 30 * time.Second
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Generates a random 160 bit TOTP secret encoded as base32
+func GenerateTotpSecret() (string, error) {
+	buffer := make([]byte, 20)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buffer), nil
+}
+
+// Builds the otpauth:// URI that authenticator apps read from a QR code
+func BuildTotpProvisioningUri(issuer string, accountName string, secret string) string {
+	label := url.PathEscape(issuer + ":" + accountName)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", TotpDigits))
+	query.Set("period", fmt.Sprintf("%d", int(TotpPeriod.Seconds())))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Returns the TOTP time step that contains the given time
+func TotpStep(at time.Time) int64 {
+	return at.Unix() / int64(TotpPeriod.Seconds())
+}
+
+// Generates the TOTP code of a secret for the given time step
+func GenerateTotpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation of RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < TotpDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TotpDigits, value%modulo), nil
+}
+
+// Validates a TOTP code allowing the given number of steps of clock drift on each side. Returns
+// the matched time step so callers can reject codes that were already used.
+func ValidateTotpCode(secret string, code string, at time.Time, skew int64) (int64, bool) {
+	if len(code) != TotpDigits {
+		return 0, false
+	}
+
+	currentStep := TotpStep(at)
+	for step := currentStep - skew; step <= currentStep+skew; step++ {
+		expected, err := GenerateTotpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// Generates a human friendly recovery code such as "ABCDE-FGHIJ"
+func GenerateRecoveryCode() (string, error) {
+	buffer := make([]byte, 7)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	encoded := totpEncoding.EncodeToString(buffer)[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// Normalizes a recovery code typed by a user before hashing it
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, " ", "")
+}